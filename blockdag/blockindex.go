@@ -86,6 +86,11 @@ type blockNode struct {
 	// this node.
 	workSum *big.Int
 
+	// dagWork is the total amount of work of the blue set of this node,
+	// which includes the node itself.  It's set when the node is connected
+	// to the dag, and is nil until then.
+	dagWork *big.Int
+
 	// height is parentsMaxHeight + 1
 	height int32
 
@@ -291,6 +291,7 @@ func newFakeChain(params *chaincfg.Params) *BlockDAG {
 		graph:               phantom.NewGraph(),
 		blueSet:             phantom.NewBlueSetCache(),
 		nodeOrder:           make([]*chainhash.Hash, 0),
+		nodeOrderIndex:      make(map[chainhash.Hash]int),
 		warningCaches:       newThresholdCaches(vbNumBits),
 		deploymentCaches:    newThresholdCaches(chaincfg.DefinedDeployments),
 	}
@@ -10,6 +10,7 @@ import (
 	"container/list"
 	"fmt"
 	"math"
+	"math/big"
 	"sort"
 	"sync"
 	"time"
@@ -33,6 +34,15 @@ const (
 
 	// coloring and sorting k form phantom paper
 	coloringK = 3
+
+	// OrderStableDepth is how many generations below the highest block of
+	// the DAG a block has to be for its position in the DAG ordering to be
+	// considered stable.  New blocks are expected to reference parents
+	// within maxGenerationDifference generations of the highest block, so
+	// they're ordered after the blocks below that depth.  Referencing
+	// older parents is only warned about, so this is a policy rather than
+	// a consensus guarantee.
+	OrderStableDepth = maxGenerationDifference
 )
 
 // BlockLocator is used to help locate specific blocks. The locator
@@ -93,9 +103,89 @@ type DAGState struct {
 	MinHeight int32
 	MaxHeight int32
 	BlkCount  uint32
+
+	// Parents are the hashes of the tips that new blocks should reference,
+	// and ParentsHash is the hash of them.  See selectParents.
+	Parents     []chainhash.Hash
+	ParentsHash chainhash.Hash
+}
+
+// selectParents returns the tips that a new block should reference as its
+// parents.  That's all of the tips, unless there are more than maxParents of
+// them, in which case it's the maxParents tips with the most work (see
+// tipWork), with ties broken by hash.  The tips keep their relative order.
+func selectParents(tips []*blockNode, maxParents int32) []*blockNode {
+	if maxParents <= 0 || int32(len(tips)) <= maxParents {
+		return tips
+	}
+
+	work := make(map[*blockNode]*big.Int, len(tips))
+	for _, tip := range tips {
+		work[tip] = tipWork(tip)
+	}
+
+	ranked := make([]*blockNode, len(tips))
+	copy(ranked, tips)
+	sort.Slice(ranked, func(i, j int) bool {
+		if cmp := work[ranked[i]].Cmp(work[ranked[j]]); cmp != 0 {
+			return cmp > 0
+		}
+		return ranked[i].hash.String() < ranked[j].hash.String()
+	})
+
+	chosen := make(map[*blockNode]struct{}, maxParents)
+	for _, tip := range ranked[:maxParents] {
+		chosen[tip] = struct{}{}
+	}
+	parents := make([]*blockNode, 0, maxParents)
+	for _, tip := range tips {
+		if _, ok := chosen[tip]; ok {
+			parents = append(parents, tip)
+		}
+	}
+
+	return parents
+}
+
+// tipWork returns the work a tip is ranked by when selecting parents, which is
+// the work of its blue set that was cached when it was connected.  The blue
+// sets of the tips loaded from the database at startup aren't known until the
+// dag is colored again, so the work of their whole past is used instead.
+func tipWork(tip *blockNode) *big.Int {
+	if tip.dagWork != nil {
+		return tip.dagWork
+	}
+
+	return pastWork(tip)
 }
 
-func newDAGState(tips []*blockNode, blkCount uint32) *DAGState {
+// pastWork returns the sum of the work of the node and of all of the blocks in
+// its past.
+func pastWork(node *blockNode) *big.Int {
+	work := big.NewInt(0)
+	seen := map[*blockNode]struct{}{node: {}}
+	queue := []*blockNode{node}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		work.Add(work, CalcWork(n.bits))
+
+		for _, parent := range n.parents {
+			if _, ok := seen[parent]; ok {
+				continue
+			}
+			seen[parent] = struct{}{}
+			queue = append(queue, parent)
+		}
+	}
+
+	return work
+}
+
+// newDAGState returns a new DAG state for the passed tips, with new blocks
+// referencing at most maxParents of them.
+func newDAGState(tips []*blockNode, blkCount uint32, maxParents int32) *DAGState {
 	tipHashes := make([]chainhash.Hash, len(tips))
 	i := 0
 	var maxHeight int32 = math.MinInt32
@@ -113,12 +203,25 @@ func newDAGState(tips []*blockNode, blkCount uint32) *DAGState {
 
 	hash := generateTipsHash(tips)
 
+	parents := selectParents(tips, maxParents)
+	parentHashes := tipHashes
+	parentsHash := hash
+	if len(parents) != len(tips) {
+		parentHashes = make([]chainhash.Hash, len(parents))
+		for i, parent := range parents {
+			parentHashes[i] = parent.hash
+		}
+		parentsHash = generateTipsHash(parents)
+	}
+
 	return &DAGState{
 		Tips: tipHashes,
 		Hash: *hash,
 		MinHeight: minHeight,
 		MaxHeight: maxHeight,
 		BlkCount: blkCount,
+		Parents: parentHashes,
+		ParentsHash: *parentsHash,
 	}
 }
 
@@ -162,6 +265,23 @@ type BlockDAG struct {
 	blueSet *phantom.BlueSetCache
 	nodeOrder []*chainhash.Hash
 
+	// nodeOrderIndex maps the hash of each block to its position in
+	// nodeOrder.
+	nodeOrderIndex map[chainhash.Hash]int
+
+	// nodeOrderStable is the number of positions at the start of nodeOrder
+	// that are considered stable (see OrderStableDepth).
+	nodeOrderStable int
+
+	// dagBlueSet holds the hashes of the blocks in the blue set of the whole
+	// DAG, as of the ordering in nodeOrder.
+	dagBlueSet map[chainhash.Hash]struct{}
+
+	// reclaimable is the total size of the blocks below the max prune
+	// height, as of the last call to ReclaimableBytes.  It's protected by
+	// the chain lock.
+	reclaimable reclaimableBytes
+
 	// These fields are related to handling of orphan blocks.  They are
 	// protected by a combination of the chain lock and the orphan lock.
 	orphanLock   sync.RWMutex
@@ -323,6 +443,32 @@ func (b *BlockDAG) GetOrphanChildren(parent *chainhash.Hash) []*soterutil.Block
 	return children
 }
 
+// orphanHeight returns the height of an orphan block, and whether it's known.
+// Since the parents of an orphan aren't all known, its height is extracted from
+// its coinbase transaction, unless it was already set on the block.
+func orphanHeight(orphan *orphanBlock) (int32, bool) {
+	height := orphan.block.Height()
+	if height != soterutil.BlockHeightUnknown {
+		return height, true
+	}
+
+	// The height can only be extracted from the scriptSig of the coinbase
+	// transaction when the block header version is 2+.
+	header := &orphan.block.MsgBlock().Header
+	coinbaseTxs := orphan.block.Transactions()
+	if !ShouldHaveSerializedBlockHeight(header) || len(coinbaseTxs) == 0 {
+		return 0, false
+	}
+
+	cbHeight, err := ExtractCoinbaseHeight(coinbaseTxs[0])
+	if err != nil {
+		log.Warnf("Unable to extract height from coinbase tx: %v", err)
+		return 0, false
+	}
+
+	return cbHeight, true
+}
+
 // GetOrphanLocator returns a BlockLocator with a height of (lowest orphan height - 2).
 //
 // -2 is used because locator height means "start at blocks _after_ this height". This makes sense when the desired
@@ -347,24 +493,11 @@ func (b *BlockDAG) GetOrphanLocator(hashes []chainhash.Hash) BlockLocator {
 			continue
 		}
 
-		height := orphan.block.Height()
-		header := &orphan.block.MsgBlock().Header
-		if height == soterutil.BlockHeightUnknown {
-			coinbaseTxs := orphan.block.Transactions()
-			if !ShouldHaveSerializedBlockHeight(header) || len(coinbaseTxs) == 0 {
-				// Orphan height isn't already known, and we can't determine it based on the contents
-				// of the block (because the version is too low or there's no coinbase transactions to examine)
-				continue
-			} else {
-				// If the block header version is 2+, we can try extracting the block height
-				// from the scriptSig of the first coinbase transaction.
-				cbHeight, err := ExtractCoinbaseHeight(coinbaseTxs[0])
-				if err != nil {
-					log.Warnf("Unable to extract height from coinbase tx: %v", err)
-					continue
-				}
-				height = cbHeight
-			}
+		height, ok := orphanHeight(orphan)
+		if !ok {
+			// Orphan height isn't already known, and we can't determine it based on the contents
+			// of the block (because the version is too low or there's no coinbase transactions to examine)
+			continue
 		}
 
 		if !heightSet {
@@ -484,6 +617,79 @@ func (b *BlockDAG) GetOrphans() []*orphanBlock {
 	return orphans
 }
 
+// OrphanInfo describes a block in the orphan pool, and the parents it's
+// waiting on.
+type OrphanInfo struct {
+	// Hash is the hash of the orphan block.
+	Hash chainhash.Hash
+
+	// MissingParents are the hashes of the parents of the block that aren't
+	// in the dag.  A missing parent may itself be in the orphan pool.
+	MissingParents []chainhash.Hash
+
+	// Expiration is when the block is evicted from the orphan pool.
+	Expiration time.Time
+}
+
+// OrphanInfos returns the blocks in the orphan pool, along with the parents
+// each is missing, ordered from the block that expires first.  At most max
+// blocks are returned, unless max is zero or less.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) OrphanInfos(max int) []OrphanInfo {
+	b.orphanLock.RLock()
+	defer b.orphanLock.RUnlock()
+
+	orphans := make([]*orphanBlock, 0, len(b.orphans))
+	for _, orphan := range b.orphans {
+		orphans = append(orphans, orphan)
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		return orphans[i].expiration.Before(orphans[j].expiration)
+	})
+	if max > 0 && len(orphans) > max {
+		orphans = orphans[:max]
+	}
+
+	infos := make([]OrphanInfo, 0, len(orphans))
+	for _, orphan := range orphans {
+		info := OrphanInfo{
+			Hash:       *orphan.block.Hash(),
+			Expiration: orphan.expiration,
+		}
+		for _, parentHash := range orphan.block.MsgBlock().Parents.ParentHashes() {
+			if !b.index.HaveBlock(&parentHash) {
+				info.MissingParents = append(info.MissingParents, parentHash)
+			}
+		}
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+// ClearOrphans removes all the blocks from the orphan pool, and returns how
+// many were removed.  The blocks are requested again if they're announced
+// after their parents arrive.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) ClearOrphans() int {
+	// Take the chain lock, so that orphans aren't being processed while
+	// the pool is cleared.
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	b.orphanLock.Lock()
+	defer b.orphanLock.Unlock()
+
+	count := len(b.orphans)
+	b.orphans = make(map[chainhash.Hash]*orphanBlock)
+	b.prevOrphans = make(map[chainhash.Hash][]*orphanBlock)
+	b.oldestOrphan = nil
+
+	return count
+}
+
 // removeOrphanBlock removes the passed orphan block from the orphan pool and
 // previous orphan index.
 func (b *BlockDAG) removeOrphanBlock(orphan *orphanBlock) {
@@ -905,9 +1111,14 @@ func (b *BlockDAG) connectBlock(node *blockNode, block *soterutil.Block,
 		}
 	}
 
-	dagState := newDAGState(dagTips, curTotalBlks + 1)
+	// The dag state is created once the dag has been colored, so that the
+	// tips can be ranked by the work of their blue sets.
+	var dagState *DAGState
 	newView := NewUtxoViewpoint()
 
+	// The blocks whose color changed as a result of connecting this one.
+	var reordered *ReorderedBlocks
+
 	// Atomically insert info into the database.
 	err = b.db.Update(func(dbTx database.Tx) error {
 		// Update best block state.
@@ -916,11 +1127,6 @@ func (b *BlockDAG) connectBlock(node *blockNode, block *soterutil.Block,
 			return err
 		}
 
-		err = dbPutDAGState(dbTx, dagState)
-		if err != nil {
-			return err
-		}
-
 		// Add the block hash and height to the block index which tracks
 		// the main chain.
 		err = dbPutBlockIndex(dbTx, block.Hash(), node.height)
@@ -951,7 +1157,25 @@ func (b *BlockDAG) connectBlock(node *blockNode, block *soterutil.Block,
 
 		// sort blocks
 		genesisHash := b.dView.Genesis().hash.String()
-		sortOrder := phantom.OrderDAG(b.graph, b.graph.GetNodeById(genesisHash), coloringK, b.blueSet)
+		sortOrder, blueNodes := phantom.OrderAndColorDAG(b.graph, b.graph.GetNodeById(genesisHash), coloringK, b.blueSet)
+
+		// The blue set of the new block won't change as the dag grows,
+		// so its work is accumulated once here.  The same goes for tips
+		// loaded from the database, whose blue sets are only known once
+		// the dag has been colored again.
+		node.dagWork = b.blueSetWork(node)
+		for _, tip := range dagTips {
+			if tip.dagWork == nil {
+				tip.dagWork = b.blueSetWork(tip)
+			}
+		}
+
+		dagState = newDAGState(dagTips, curTotalBlks + 1,
+			b.chainParams.MaxBlockParents)
+		err = dbPutDAGState(dbTx, dagState)
+		if err != nil {
+			return err
+		}
 
 		// array to save sort order
 		sortedHashes := make([]*chainhash.Hash, len(sortOrder))
@@ -982,7 +1206,24 @@ func (b *BlockDAG) connectBlock(node *blockNode, block *soterutil.Block,
 			}
 		}
 
+		dagBlueSet := make(map[chainhash.Hash]struct{}, len(blueNodes))
+		for _, node := range blueNodes {
+			blockHash, err := chainhash.NewHashFromStr(node.GetId())
+			if err != nil {
+				return err
+			}
+			dagBlueSet[*blockHash] = struct{}{}
+		}
+		reordered = reorderedBlocks(sortedHashes, b.nodeOrderIndex,
+			b.dagBlueSet, dagBlueSet)
+
 		b.nodeOrder = sortedHashes
+		b.nodeOrderIndex = make(map[chainhash.Hash]int, len(sortedHashes))
+		for i, hash := range sortedHashes {
+			b.nodeOrderIndex[*hash] = i
+		}
+		b.nodeOrderStable = b.orderStableLength(sortedHashes)
+		b.dagBlueSet = dagBlueSet
 
 		//err = dbPutUtxoView(dbTx, view)
 		err = dbPutUtxoView(dbTx, newView)
@@ -1018,6 +1259,12 @@ func (b *BlockDAG) connectBlock(node *blockNode, block *soterutil.Block,
 	//view.commit()
 	newView.commit()
 
+	// A block that connects below the height the reclaimable bytes were
+	// counted up to is one of the blocks they're the size of.
+	if node.height < b.reclaimable.height {
+		b.reclaimable.bytes += int64(blockSize)
+	}
+
 	// This node is now the end of the best chain.
 	b.dView.AddTip(node)
 
@@ -1041,6 +1288,9 @@ func (b *BlockDAG) connectBlock(node *blockNode, block *soterutil.Block,
 	// updating wallets.
 	b.chainLock.Unlock()
 	b.sendNotification(NTBlockConnected, block)
+	if reordered != nil {
+		b.sendNotification(NTBlockReordered, reordered)
+	}
 	b.chainLock.Lock()
 
 	return nil
@@ -1669,6 +1919,157 @@ func (b *BlockDAG) DAGColoring() []*chainhash.Hash {
 	return nil
 }
 
+// BlueSetSize returns the number of blocks in the blue set of the DAG, based on
+// the last block added (see DAGColoring).  The size is looked up from the
+// cached blue set, so it's cheaper than counting the blocks of DAGColoring.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) BlueSetSize() int {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	latestBlock := b.BestSnapshot().Hash
+	latestNode := b.graph.GetNodeById(latestBlock.String())
+	return b.blueSet.GetBlueSetSize(latestNode)
+}
+
+// NumOrphans returns the number of orphan blocks currently held.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) NumOrphans() int {
+	b.orphanLock.RLock()
+	defer b.orphanLock.RUnlock()
+
+	return len(b.orphans)
+}
+
+// blueHashes returns the set of hashes of the blocks in the blue set of the
+// DAG, based on the last block added.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockDAG) blueHashes() map[chainhash.Hash]struct{} {
+	latestBlock := b.BestSnapshot().Hash
+	latestNode := b.graph.GetNodeById(latestBlock.String())
+	blueNodes := b.blueSet.GetBlueNodes(latestNode)
+
+	hashes := make(map[chainhash.Hash]struct{}, len(blueNodes))
+	for _, node := range blueNodes {
+		hash, err := chainhash.NewHashFromStr(node.GetId())
+		if err != nil {
+			continue
+		}
+		hashes[*hash] = struct{}{}
+	}
+
+	return hashes
+}
+
+// reorderedBlocks returns the blocks of the passed ordering whose color changed
+// between the prev and cur blue sets of the DAG, or nil if there are none.
+// Blocks that weren't part of the prev ordering, like the block that was just
+// connected, have no previous color, so they're never reported.
+func reorderedBlocks(order []*chainhash.Hash, prevOrder map[chainhash.Hash]int,
+	prev, cur map[chainhash.Hash]struct{}) *ReorderedBlocks {
+
+	var reordered ReorderedBlocks
+	for _, hash := range order {
+		if _, known := prevOrder[*hash]; !known {
+			continue
+		}
+
+		_, wasBlue := prev[*hash]
+		_, isBlue := cur[*hash]
+		switch {
+		case isBlue && !wasBlue:
+			reordered.Blue = append(reordered.Blue, *hash)
+		case wasBlue && !isBlue:
+			reordered.Red = append(reordered.Red, *hash)
+		}
+	}
+
+	if len(reordered.Blue) == 0 && len(reordered.Red) == 0 {
+		return nil
+	}
+	return &reordered
+}
+
+// blueSetWork returns the sum of the work of the blocks in the cached blue set
+// of the given node, which includes the node itself.  Zero is returned when
+// the blue set of the node hasn't been cached.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockDAG) blueSetWork(node *blockNode) *big.Int {
+	work := big.NewInt(0)
+	graphNode := b.graph.GetNodeById(node.hash.String())
+	for _, blue := range b.blueSet.GetBlueNodes(graphNode) {
+		hash, err := chainhash.NewHashFromStr(blue.GetId())
+		if err != nil {
+			continue
+		}
+		blueNode := b.index.LookupNode(hash)
+		if blueNode == nil {
+			continue
+		}
+		work.Add(work, CalcWork(blueNode.bits))
+	}
+
+	return work
+}
+
+// nodeDAGWork returns the cumulative work of the DAG as of the given node.  The
+// work accumulated when the node was connected is used when there is one, so
+// it's only summed up from the blue set of the node otherwise.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockDAG) nodeDAGWork(node *blockNode) *big.Int {
+	if node.dagWork != nil {
+		return new(big.Int).Set(node.dagWork)
+	}
+
+	return b.blueSetWork(node)
+}
+
+// DAGWork returns the cumulative work of the DAG.
+//
+// Unlike a chain, where the cumulative work is the sum of the work of the
+// blocks along the best chain, the work of the DAG is the sum of the work of
+// every block in its blue set (see DAGColoring).  Red blocks are part of the
+// DAG, but don't contribute to its work.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) DAGWork() *big.Int {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	node := b.index.LookupNode(&b.BestSnapshot().Hash)
+	if node == nil {
+		return big.NewInt(0)
+	}
+
+	return b.nodeDAGWork(node)
+}
+
+// DAGWorkByHash returns the cumulative work of the DAG as of the block
+// identified by the given hash, or an error if it doesn't exist.  It is the
+// sum of the work of the blocks in the blue set of the block, which includes
+// the block itself.  The blue set of a block is fixed once it connects, so the
+// work of a block doesn't change as the DAG grows.  See DAGWork for details on
+// how work accumulates in the DAG.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) DAGWorkByHash(hash *chainhash.Hash) (*big.Int, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		err := fmt.Errorf("block %s is not known", hash)
+		return nil, err
+	}
+
+	return b.nodeDAGWork(node), nil
+}
+
 // DAGOrdering returns the ordering of the blocks after the DAG is sorted
 func (b *BlockDAG) DAGOrdering() []*chainhash.Hash {
 	b.chainLock.RLock()
@@ -1677,6 +2078,94 @@ func (b *BlockDAG) DAGOrdering() []*chainhash.Hash {
 	return b.nodeOrder
 }
 
+// OrderConfirmations returns the number of blocks that come after the block
+// identified by the given hash in the DAG ordering (see DAGOrdering), which
+// is how deeply the block is buried in the DAG.  The ordering doesn't vouch
+// for blocks outside of the blue set, so -1 is returned for red blocks.  An
+// error is returned if the block isn't in the ordering.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) OrderConfirmations(hash *chainhash.Hash) (int32, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	order, ok := b.nodeOrderIndex[*hash]
+	if !ok {
+		str := fmt.Sprintf("block %s is not in the dag ordering", hash)
+		return 0, errNotInMainChain(str)
+	}
+
+	if _, ok := b.blueHashes()[*hash]; !ok {
+		return -1, nil
+	}
+
+	return int32(len(b.nodeOrder) - order - 1), nil
+}
+
+// orderStableLength returns the number of positions at the start of the passed
+// DAG ordering that are held by blocks at least OrderStableDepth generations
+// below the highest block of the ordering.
+func (b *BlockDAG) orderStableLength(order []*chainhash.Hash) int {
+	heights := make([]int32, len(order))
+	var maxHeight int32
+	for i, hash := range order {
+		node := b.index.LookupNode(hash)
+		if node == nil {
+			return 0
+		}
+		heights[i] = node.height
+		if node.height > maxHeight {
+			maxHeight = node.height
+		}
+	}
+
+	for i, height := range heights {
+		if height > maxHeight-OrderStableDepth {
+			return i
+		}
+	}
+	return len(order)
+}
+
+// DAGOrderPosition describes the block at a position of the DAG ordering.
+type DAGOrderPosition struct {
+	// Hash is the hash of the block at the position.
+	Hash chainhash.Hash
+
+	// Length is the number of blocks in the DAG ordering.
+	Length int32
+
+	// StableLength is the number of positions at the start of the DAG
+	// ordering that are considered stable, meaning that new blocks aren't
+	// expected to change which blocks hold them (see OrderStableDepth).
+	// The positions after it may be taken by other blocks as new blocks
+	// are connected.
+	StableLength int32
+}
+
+// DAGOrderHash returns the block at the given position of the DAG ordering (see
+// DAGOrdering), along with the length of the ordering and how much of it is
+// considered stable, all as of the same state of the DAG.  An error is returned
+// if the position is outside of the ordering.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) DAGOrderHash(order int32) (*DAGOrderPosition, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	if order < 0 || int(order) >= len(b.nodeOrder) {
+		str := fmt.Sprintf("no block at position %d of the dag "+
+			"ordering of length %d", order, len(b.nodeOrder))
+		return nil, errNotInMainChain(str)
+	}
+
+	return &DAGOrderPosition{
+		Hash:         *b.nodeOrder[order],
+		Length:       int32(len(b.nodeOrder)),
+		StableLength: int32(b.nodeOrderStable),
+	}, nil
+}
+
 // HeaderByHash returns the block header identified by the given hash or an
 // error if it doesn't exist. Note that this will return headers from both the
 // main and side chains.
@@ -1690,6 +2179,27 @@ func (b *BlockDAG) HeaderByHash(hash *chainhash.Hash) (wire.BlockHeader, error)
 	return node.Header(), nil
 }
 
+// ParentHashesByHash returns the hashes of the parents of the block identified
+// by the given hash, in the order they appear in the block's parent
+// sub-header, or an error if it doesn't exist.  The genesis block has no
+// parents.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) ParentHashesByHash(hash *chainhash.Hash) ([]chainhash.Hash, error) {
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		err := fmt.Errorf("block %s is not known", hash)
+		return nil, err
+	}
+
+	hashes := make([]chainhash.Hash, len(node.parentMetadata))
+	for i, parent := range node.parentMetadata {
+		hashes[i] = parent.hash
+	}
+
+	return hashes, nil
+}
+
 // MainChainHasBlock returns whether or not the block with the given hash is in
 // the main chain.
 //
@@ -1755,7 +2265,7 @@ func (b *BlockDAG) BlockHeightByHash(hash *chainhash.Hash) (int32, error) {
 }
 
 // BlockHashesByHeight returns the hashes of the blocks at the given height in the
-// main chain.
+// DAG, sorted by their position in the DAG ordering (see DAGOrdering).
 //
 // This function is safe for concurrent access.
 func (b *BlockDAG) BlockHashesByHeight(blockHeight int32) ([]chainhash.Hash, error) {
@@ -1773,9 +2283,151 @@ func (b *BlockDAG) BlockHashesByHeight(blockHeight int32) ([]chainhash.Hash, err
 		i++
 	}
 
+	// Blocks that aren't in the ordering yet are sorted after those that
+	// are, by hash, so that the result is stable.
+	b.chainLock.RLock()
+	sort.Slice(hashes, func(i, j int) bool {
+		orderI, okI := b.nodeOrderIndex[hashes[i]]
+		orderJ, okJ := b.nodeOrderIndex[hashes[j]]
+		switch {
+		case okI && okJ:
+			return orderI < orderJ
+		case okI != okJ:
+			return okI
+		}
+		return hashes[i].String() < hashes[j].String()
+	})
+	b.chainLock.RUnlock()
+
 	return hashes, nil
 }
 
+// DAGPath returns a shortest path of parent links from the block with hash
+// from to its ancestor with hash to, starting with from and ending with to.
+// Each block in the path is a parent of the block before it.  When several
+// paths are the shortest, parents are followed in the order the block
+// references them, so the same path is returned each time.
+//
+// A nil path is returned if to isn't an ancestor of from, and an error is
+// returned if either block isn't in the dag.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) DAGPath(from, to *chainhash.Hash) ([]chainhash.Hash, error) {
+	fromNode := b.index.LookupNode(from)
+	if fromNode == nil || !b.dView.Contains(fromNode) {
+		str := fmt.Sprintf("block %s is not in the main chain", from)
+		return nil, errNotInMainChain(str)
+	}
+	toNode := b.index.LookupNode(to)
+	if toNode == nil || !b.dView.Contains(toNode) {
+		str := fmt.Sprintf("block %s is not in the main chain", to)
+		return nil, errNotInMainChain(str)
+	}
+
+	// Search breadth-first from the block towards the genesis block,
+	// tracking the child each block was reached from.  Ancestors of a block
+	// are lower than it, so there's no need to search past the height of
+	// the block the path ends at.
+	reachedFrom := map[*blockNode]*blockNode{fromNode: nil}
+	queue := []*blockNode{fromNode}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if node == toNode {
+			break
+		}
+		if node.height <= toNode.height {
+			continue
+		}
+
+		for _, parent := range node.parents {
+			if _, seen := reachedFrom[parent]; seen {
+				continue
+			}
+			reachedFrom[parent] = node
+			queue = append(queue, parent)
+		}
+	}
+	if _, reached := reachedFrom[toNode]; !reached {
+		return nil, nil
+	}
+
+	var path []chainhash.Hash
+	for node := toNode; node != nil; node = reachedFrom[node] {
+		path = append(path, node.hash)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, nil
+}
+
+// Anticone returns the anticone of the block with the given hash, which is the
+// blocks of the dag that are neither ancestors nor descendants of it, ordered by
+// height and then hash.  When limit is positive, at most limit hashes are
+// returned.  The size of the whole anticone is returned along with them.
+//
+// An error is returned if the block isn't in the dag.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) Anticone(hash *chainhash.Hash, limit int) ([]chainhash.Hash, int, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil || !b.dView.Contains(node) {
+		str := fmt.Sprintf("block %s is not in the main chain", hash)
+		return nil, 0, errNotInMainChain(str)
+	}
+
+	// Visit the blocks by height, so that the parents of a block are
+	// visited before it.  A block that isn't an ancestor is a descendant
+	// when one of its parents is the block or a descendant of it.
+	past := pastNodes([]*blockNode{node})
+	future := make(map[*blockNode]struct{})
+	var anticone []*blockNode
+	for height := int32(0); height <= b.dView.Height(); height++ {
+		for _, n := range b.dView.NodesByHeight(height) {
+			if _, ok := past[n]; ok {
+				continue
+			}
+
+			descendant := false
+			for _, parent := range n.parents {
+				_, ok := future[parent]
+				if ok || parent == node {
+					descendant = true
+					break
+				}
+			}
+			if descendant {
+				future[n] = struct{}{}
+				continue
+			}
+
+			anticone = append(anticone, n)
+		}
+	}
+
+	sort.Slice(anticone, func(i, j int) bool {
+		if anticone[i].height != anticone[j].height {
+			return anticone[i].height < anticone[j].height
+		}
+		return anticone[i].hash.String() < anticone[j].hash.String()
+	})
+
+	size := len(anticone)
+	if limit > 0 && size > limit {
+		anticone = anticone[:limit]
+	}
+	hashes := make([]chainhash.Hash, 0, len(anticone))
+	for _, n := range anticone {
+		hashes = append(hashes, n.hash)
+	}
+
+	return hashes, size, nil
+}
+
 // HeightRange returns a range of block hashes for the given start and end
 // heights.  It is inclusive of the start height and exclusive of the end
 // height.  The end height will be limited to the current main chain height.
@@ -2211,6 +2863,8 @@ func New(config *Config) (*BlockDAG, error) {
 		dView:               newDAGView(nil),
 		graph:               phantom.NewGraph(),
 		nodeOrder:           make([]*chainhash.Hash, 0),
+		nodeOrderIndex:      make(map[chainhash.Hash]int),
+		dagBlueSet:          make(map[chainhash.Hash]struct{}),
 		blueSet:             phantom.NewBlueSetCache(),
 		orphans:             make(map[chainhash.Hash]*orphanBlock),
 		prevOrphans:         make(map[chainhash.Hash][]*orphanBlock),
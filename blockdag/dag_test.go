@@ -7,6 +7,7 @@ package blockdag
 import (
 	"encoding/binary"
 	"fmt"
+	"github.com/soteria-dag/soterd/blockdag/phantom"
 	"github.com/soteria-dag/soterd/chaincfg"
 	"github.com/soteria-dag/soterd/chaincfg/chainhash"
 	"github.com/soteria-dag/soterd/soterutil"
@@ -39,8 +40,8 @@ func createMsgBlockForTest(height uint32, ts int64, parents []*wire.MsgBlock, tr
 	}
 
 	parentData := make([]*wire.Parent, len(parents))
-	for i, parent := range parents {
-		parentData[i] = &wire.Parent{Hash: parent.BlockHash()}
+	for i, parentHash := range soterutil.CanonicalizeParents(parentHashes) {
+		parentData[i] = &wire.Parent{Hash: *parentHash}
 	}
 
 	var txs = []*wire.MsgTx{coinbaseTx}
@@ -615,6 +616,193 @@ func TestBlockHashesByHeight(t *testing.T) {
 
 }
 
+// TestOrderConfirmations tests that blocks are confirmed by the blocks after
+// them in the dag ordering, and that red blocks aren't confirmed.
+func TestOrderConfirmations(t *testing.T) {
+	dag := newFakeChain(&chaincfg.SimNetParams)
+	now := time.Now().Unix()
+	genesis := chaincfg.SimNetParams.GenesisBlock
+	genesisHash := genesis.BlockHash()
+	dag.graph.AddNodeById(genesisHash.String())
+
+	// Mine more siblings than coloringK allows in a blue anticone, so that
+	// one of them is red, then merge them all into a single tip.
+	siblings := make([]*wire.MsgBlock, coloringK+2)
+	for i := range siblings {
+		siblings[i] = createMsgBlockForTest(1, now-int64(1000-i),
+			[]*wire.MsgBlock{genesis}, nil)
+		hash := siblings[i].BlockHash()
+		dag.graph.AddNodeById(hash.String())
+		dag.graph.AddEdgeById(hash.String(), genesisHash.String())
+	}
+	tip := createMsgBlockForTest(2, now-500, siblings, nil)
+	tipHash := tip.BlockHash()
+	dag.graph.AddNodeById(tipHash.String())
+	for _, sibling := range siblings {
+		dag.graph.AddEdgeById(tipHash.String(), sibling.BlockHash().String())
+	}
+
+	sortOrder := phantom.OrderDAG(dag.graph,
+		dag.graph.GetNodeById(genesisHash.String()), coloringK, dag.blueSet)
+	for i, node := range sortOrder {
+		hash, err := chainhash.NewHashFromStr(node.GetId())
+		if err != nil {
+			t.Fatalf("unable to parse hash %v: %v", node.GetId(), err)
+		}
+		dag.nodeOrder = append(dag.nodeOrder, hash)
+		dag.nodeOrderIndex[*hash] = i
+	}
+	dag.stateSnapshot = &BestState{Hash: tipHash}
+
+	confs, err := dag.OrderConfirmations(&genesisHash)
+	if err != nil {
+		t.Fatalf("OrderConfirmations failed for genesis block: %v", err)
+	}
+	if want := int32(len(sortOrder) - 1); confs != want {
+		t.Errorf("OrderConfirmations returned %d for genesis block, "+
+			"wanted %d", confs, want)
+	}
+
+	confs, err = dag.OrderConfirmations(&tipHash)
+	if err != nil {
+		t.Fatalf("OrderConfirmations failed for tip: %v", err)
+	}
+	if confs != 0 {
+		t.Errorf("OrderConfirmations returned %d for tip, wanted 0", confs)
+	}
+
+	red := 0
+	for _, sibling := range siblings {
+		hash := sibling.BlockHash()
+		confs, err := dag.OrderConfirmations(&hash)
+		if err != nil {
+			t.Fatalf("OrderConfirmations failed for %v: %v", hash, err)
+		}
+
+		switch {
+		case confs == -1:
+			red++
+		case confs != int32(len(sortOrder)-dag.nodeOrderIndex[hash]-1):
+			t.Errorf("OrderConfirmations returned %d for blue block "+
+				"%v at order %d", confs, hash, dag.nodeOrderIndex[hash])
+		}
+	}
+	if red != 1 {
+		t.Errorf("OrderConfirmations returned -1 for %d blocks, wanted 1",
+			red)
+	}
+
+	unknown := chainhash.Hash{0x01}
+	if _, err := dag.OrderConfirmations(&unknown); err == nil {
+		t.Errorf("OrderConfirmations succeeded for unknown block")
+	}
+}
+
+func TestDAGOrderHash(t *testing.T) {
+	dag, teardownFunc, err := chainSetup("dagorderhash",
+		&chaincfg.SimNetParams)
+	if err != nil {
+		t.Errorf("Failed to setup dag instance: %v", err)
+		return
+	}
+	defer teardownFunc()
+
+	// Since we're not dealing with the real block dag, set the coinbase
+	// maturity to 1.
+	dag.TstSetCoinbaseMaturity(1)
+
+	// Mine a chain deep enough for the start of the ordering to be stable.
+	now := time.Now().Unix()
+	numBlocks := OrderStableDepth + 4
+	blocks := make([]*wire.MsgBlock, numBlocks)
+	blocks[0] = chaincfg.SimNetParams.GenesisBlock
+	for i := 1; i < numBlocks; i++ {
+		blocks[i] = createMsgBlockForTest(uint32(i),
+			now-int64((numBlocks-i)*10), []*wire.MsgBlock{blocks[i-1]}, nil)
+		addBlockForTest(dag, blocks[i], t)
+	}
+
+	// orderHashes returns the hashes of the whole ordering, checking that
+	// every position reports the same length and stable length.
+	orderHashes := func() ([]chainhash.Hash, int32) {
+		first, err := dag.DAGOrderHash(0)
+		if err != nil {
+			t.Fatalf("DAGOrderHash failed for position 0: %v", err)
+		}
+		hashes := make([]chainhash.Hash, first.Length)
+		for i := int32(0); i < first.Length; i++ {
+			pos, err := dag.DAGOrderHash(i)
+			if err != nil {
+				t.Fatalf("DAGOrderHash failed for position %d: %v",
+					i, err)
+			}
+			if pos.Length != first.Length ||
+				pos.StableLength != first.StableLength {
+				t.Fatalf("DAGOrderHash position %d reports %+v, "+
+					"position 0 reports %+v", i, pos, first)
+			}
+			hashes[i] = pos.Hash
+		}
+		return hashes, first.StableLength
+	}
+
+	before, stable := orderHashes()
+	if len(before) != numBlocks {
+		t.Fatalf("ordering has length %d, wanted %d", len(before),
+			numBlocks)
+	}
+
+	// The blocks at least OrderStableDepth generations below the highest
+	// block hold the stable positions of a chain.
+	if want := int32(numBlocks - OrderStableDepth); stable != want {
+		t.Fatalf("DAGOrderHash reports stable length %d, wanted %d",
+			stable, want)
+	}
+	for i := 0; i < numBlocks; i++ {
+		if before[i] != blocks[i].BlockHash() {
+			t.Fatalf("position %d of the ordering holds %v, wanted %v",
+				i, before[i], blocks[i].BlockHash())
+		}
+	}
+
+	// Fork the tip, and merge the fork.  The tail of the ordering may be
+	// taken by other blocks, but the stable positions must not change.
+	tip := blocks[numBlocks-1]
+	fork := createMsgBlockForTest(uint32(numBlocks-1), now-5,
+		[]*wire.MsgBlock{blocks[numBlocks-2]}, nil)
+	addBlockForTest(dag, fork, t)
+	merge := createMsgBlockForTest(uint32(numBlocks), now,
+		[]*wire.MsgBlock{tip, fork}, nil)
+	addBlockForTest(dag, merge, t)
+
+	after, afterStable := orderHashes()
+	if len(after) != numBlocks+2 {
+		t.Fatalf("ordering has length %d after the fork, wanted %d",
+			len(after), numBlocks+2)
+	}
+	if afterStable < stable {
+		t.Fatalf("stable length shrank from %d to %d", stable,
+			afterStable)
+	}
+	for i := int32(0); i < stable; i++ {
+		if after[i] != before[i] {
+			t.Fatalf("stable position %d changed from %v to %v", i,
+				before[i], after[i])
+		}
+	}
+	if after[len(after)-1] != merge.BlockHash() {
+		t.Fatalf("the last position holds %v, wanted the merge block %v",
+			after[len(after)-1], merge.BlockHash())
+	}
+
+	for _, order := range []int32{-1, int32(len(after))} {
+		if _, err := dag.DAGOrderHash(order); err == nil {
+			t.Errorf("DAGOrderHash succeeded for position %d of an "+
+				"ordering of length %d", order, len(after))
+		}
+	}
+}
+
 func TestHeightRange(t *testing.T) {
 	dag := newFakeChain(&chaincfg.SimNetParams)
 	now := time.Now().Unix()
@@ -866,4 +1054,399 @@ func TestGraphUpdate(t *testing.T) {
 	if expected != graphStr{
 		t.Errorf("Expected graph to be %s, got %s", expected, graphStr)
 	}
-}
\ No newline at end of file
+}
+// TestOrphanInfos ensures the orphan pool reports the parents each orphan is
+// missing, and that it can be cleared.
+func TestOrphanInfos(t *testing.T) {
+	dag := newFakeChain(&chaincfg.SimNetParams)
+	dag.orphans = make(map[chainhash.Hash]*orphanBlock)
+	dag.prevOrphans = make(map[chainhash.Hash][]*orphanBlock)
+
+	// The second orphan has the genesis block and the first orphan as
+	// parents, so it's only missing the first orphan.
+	genesis := chaincfg.SimNetParams.GenesisBlock
+	orphan1 := soterutil.NewBlock(&BlockOrphan)
+	msgOrphan2 := createMsgBlockForTest(2, time.Now().Unix(),
+		[]*wire.MsgBlock{genesis, &BlockOrphan}, nil)
+	orphan2 := soterutil.NewBlock(msgOrphan2)
+
+	dag.addOrphanBlock(orphan1)
+	dag.addOrphanBlock(orphan2)
+
+	infos := dag.OrphanInfos(0)
+	if len(infos) != 2 {
+		t.Fatalf("OrphanInfos returned %d orphans, want 2", len(infos))
+	}
+	want := map[chainhash.Hash][]chainhash.Hash{
+		*orphan1.Hash(): {orphanParentHash},
+		*orphan2.Hash(): {*orphan1.Hash()},
+	}
+	for _, info := range infos {
+		if !reflect.DeepEqual(info.MissingParents, want[info.Hash]) {
+			t.Errorf("orphan %v has missing parents %v, want %v",
+				info.Hash, info.MissingParents, want[info.Hash])
+		}
+	}
+	if infos[0].Expiration.After(infos[1].Expiration) {
+		t.Errorf("OrphanInfos didn't order orphans by expiration")
+	}
+
+	if infos := dag.OrphanInfos(1); len(infos) != 1 {
+		t.Errorf("OrphanInfos(1) returned %d orphans, want 1", len(infos))
+	}
+
+	if cleared := dag.ClearOrphans(); cleared != 2 {
+		t.Errorf("ClearOrphans removed %d orphans, want 2", cleared)
+	}
+	if dag.NumOrphans() != 0 || dag.IsKnownOrphan(orphan1.Hash()) {
+		t.Errorf("orphan pool isn't empty after ClearOrphans")
+	}
+}
+
+// TestMaxBlockParents ensures new blocks are only offered as many tips as the
+// network allows as parents, and that blocks referencing more are rejected.
+func TestMaxBlockParents(t *testing.T) {
+	params := chaincfg.SimNetParams
+	params.MaxBlockParents = 2
+	dag, teardownFunc, err := chainSetup("maxblockparents", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup dag instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Since we're not dealing with the real block dag, set the coinbase
+	// maturity to 1.
+	dag.TstSetCoinbaseMaturity(1)
+
+	// Create three tips, each a child of the genesis block.
+	now := time.Now().Unix()
+	tips := make([]*wire.MsgBlock, 3)
+	for i := range tips {
+		tips[i] = createMsgBlockForTest(1, now-int64((len(tips)-i)*10),
+			[]*wire.MsgBlock{params.GenesisBlock}, nil)
+		addBlockForTest(dag, tips[i], t)
+	}
+
+	snapshot := dag.DAGSnapshot()
+	if len(snapshot.Tips) != len(tips) {
+		t.Fatalf("dag has %d tips, want %d", len(snapshot.Tips), len(tips))
+	}
+	if len(snapshot.Parents) != int(params.MaxBlockParents) {
+		t.Fatalf("new blocks reference %d tips, want %d",
+			len(snapshot.Parents), params.MaxBlockParents)
+	}
+	tipBlocks := make(map[chainhash.Hash]*wire.MsgBlock, len(tips))
+	for _, tip := range tips {
+		tipBlocks[tip.BlockHash()] = tip
+	}
+	parentHashes := make([]*chainhash.Hash, 0, len(snapshot.Parents))
+	parents := make([]*wire.MsgBlock, 0, len(snapshot.Parents))
+	for i := range snapshot.Parents {
+		parent, ok := tipBlocks[snapshot.Parents[i]]
+		if !ok {
+			t.Fatalf("parent %v isn't a tip", snapshot.Parents[i])
+		}
+		parentHashes = append(parentHashes, &snapshot.Parents[i])
+		parents = append(parents, parent)
+	}
+	if !GenerateTipsHash(parentHashes).IsEqual(&snapshot.ParentsHash) {
+		t.Fatalf("parents hash %v isn't the hash of the parents",
+			snapshot.ParentsHash)
+	}
+
+	// A block referencing all of the tips is rejected.
+	overParented := createMsgBlockForTest(2, now, tips, nil)
+	_, _, err = dag.ProcessBlock(soterutil.NewBlock(overParented), BFNone)
+	if rerr, ok := err.(RuleError); !ok || rerr.ErrorCode != ErrTooManyParents {
+		t.Fatalf("ProcessBlock of a block with %d parents returned %v, "+
+			"want %v", len(tips), err, ErrTooManyParents)
+	}
+
+	// A block referencing the parents offered by the dag is accepted.
+	block := createMsgBlockForTest(2, now, parents, nil)
+	addBlockForTest(dag, block, t)
+	blockHash := block.BlockHash()
+	if !dag.MainChainHasBlock(&blockHash) {
+		t.Fatalf("block with %d parents wasn't added to the dag",
+			len(parents))
+	}
+}
+
+// TestMaxBlockParentsUnequalHeights ensures that when there are more tips than
+// a block may reference, the tips with the most work are offered as parents,
+// even when a tip left out is higher than all of them.
+func TestMaxBlockParentsUnequalHeights(t *testing.T) {
+	params := chaincfg.SimNetParams
+	params.MaxBlockParents = 3
+	dag, teardownFunc, err := chainSetup("maxblockparentsheights", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup dag instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Since we're not dealing with the real block dag, set the coinbase
+	// maturity to 1.
+	dag.TstSetCoinbaseMaturity(1)
+
+	// Create a chain of three blocks on the genesis block, whose tip is the
+	// highest block of the dag, with four blocks of work in its past.
+	now := time.Now().Unix()
+	ts := now - 100
+	parent := params.GenesisBlock
+	for height := uint32(1); height <= 3; height++ {
+		block := createMsgBlockForTest(height, ts,
+			[]*wire.MsgBlock{parent}, nil)
+		addBlockForTest(dag, block, t)
+		parent = block
+		ts++
+	}
+	chainTip := parent.BlockHash()
+
+	// Create as many tips as a block may reference at height 2, each
+	// merging three blocks of height 1, so that each has five blocks of
+	// work in its past.
+	for i := int32(0); i < params.MaxBlockParents; i++ {
+		siblings := make([]*wire.MsgBlock, 3)
+		for j := range siblings {
+			siblings[j] = createMsgBlockForTest(1, ts,
+				[]*wire.MsgBlock{params.GenesisBlock}, nil)
+			addBlockForTest(dag, siblings[j], t)
+			ts++
+		}
+		addBlockForTest(dag, createMsgBlockForTest(2, ts, siblings, nil), t)
+		ts++
+	}
+
+	snapshot := dag.DAGSnapshot()
+	if len(snapshot.Tips) != int(params.MaxBlockParents)+1 {
+		t.Fatalf("dag has %d tips, want %d", len(snapshot.Tips),
+			params.MaxBlockParents+1)
+	}
+	if snapshot.MaxHeight != 3 {
+		t.Fatalf("dag has max height %d, want 3", snapshot.MaxHeight)
+	}
+
+	// The chain tip has the least work, so it's left out, and new blocks
+	// are one higher than the merging tips rather than the chain tip.
+	for i := range snapshot.Parents {
+		if snapshot.Parents[i] == chainTip {
+			t.Fatalf("chain tip %v with the least work is a parent",
+				chainTip)
+		}
+		height, err := dag.BlockHeightByHash(&snapshot.Parents[i])
+		if err != nil {
+			t.Fatalf("BlockHeightByHash: %v", err)
+		}
+		if height != 2 {
+			t.Fatalf("parent %v has height %d, want 2",
+				snapshot.Parents[i], height)
+		}
+	}
+
+	parents := make([]*wire.MsgBlock, 0, len(snapshot.Parents))
+	for i := range snapshot.Parents {
+		block, err := dag.BlockByHash(&snapshot.Parents[i])
+		if err != nil {
+			t.Fatalf("BlockByHash: %v", err)
+		}
+		parents = append(parents, block.MsgBlock())
+	}
+	block := createMsgBlockForTest(3, now, parents, nil)
+	addBlockForTest(dag, block, t)
+	blockHash := block.BlockHash()
+	height, err := dag.BlockHeightByHash(&blockHash)
+	if err != nil {
+		t.Fatalf("BlockHeightByHash: %v", err)
+	}
+	if height != 3 {
+		t.Fatalf("block on the offered parents has height %d, want 3",
+			height)
+	}
+}
+
+// TestParentOrdering ensures blocks are rejected unless they reference their
+// parents in canonical order, without duplicates.
+func TestParentOrdering(t *testing.T) {
+	params := chaincfg.SimNetParams
+	dag, teardownFunc, err := chainSetup("parentordering", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup dag instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Since we're not dealing with the real block dag, set the coinbase
+	// maturity to 1.
+	dag.TstSetCoinbaseMaturity(1)
+
+	now := time.Now().Unix()
+	tips := make([]*wire.MsgBlock, 2)
+	for i := range tips {
+		tips[i] = createMsgBlockForTest(1, now-int64((len(tips)-i)*10),
+			[]*wire.MsgBlock{params.GenesisBlock}, nil)
+		addBlockForTest(dag, tips[i], t)
+	}
+
+	// The parents aren't part of the block header, so changing them
+	// doesn't change the hash of the block.
+	tests := []struct {
+		name  string
+		munge func(parents []*wire.Parent) []*wire.Parent
+	}{
+		{"reversed", func(parents []*wire.Parent) []*wire.Parent {
+			return []*wire.Parent{parents[1], parents[0]}
+		}},
+		{"duplicate", func(parents []*wire.Parent) []*wire.Parent {
+			return append(parents, parents[1])
+		}},
+	}
+	for _, test := range tests {
+		msgBlock := createMsgBlockForTest(2, now, tips, nil)
+		msgBlock.Parents.Parents = test.munge(msgBlock.Parents.Parents)
+		msgBlock.Parents.Size = int32(len(msgBlock.Parents.Parents))
+
+		_, _, err := dag.ProcessBlock(soterutil.NewBlock(msgBlock), BFNone)
+		rerr, ok := err.(RuleError)
+		if !ok || rerr.ErrorCode != ErrParentsNotCanonical {
+			t.Fatalf("ProcessBlock of block with %s parents returned "+
+				"%v, want %v", test.name, err, ErrParentsNotCanonical)
+		}
+	}
+
+	// The block is accepted with its parents in canonical order.
+	addBlockForTest(dag, createMsgBlockForTest(2, now, tips, nil), t)
+}
+
+// TestDAGPath ensures DAGPath returns a shortest path of parent links between
+// a block and its ancestor, and no path to blocks that aren't ancestors.
+func TestDAGPath(t *testing.T) {
+	params := chaincfg.SimNetParams
+	dag, teardownFunc, err := chainSetup("dagpath", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup dag instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Since we're not dealing with the real block dag, set the coinbase
+	// maturity to 1.
+	dag.TstSetCoinbaseMaturity(1)
+
+	// Build the dag
+	//
+	//   genesis <- a <------- c
+	//          \             /
+	//           <- b <- b2 <-
+	now := time.Now().Unix()
+	genesis := params.GenesisBlock
+	a := createMsgBlockForTest(1, now-30, []*wire.MsgBlock{genesis}, nil)
+	b := createMsgBlockForTest(1, now-20, []*wire.MsgBlock{genesis}, nil)
+	b2 := createMsgBlockForTest(2, now-10, []*wire.MsgBlock{b}, nil)
+	c := createMsgBlockForTest(3, now, []*wire.MsgBlock{a, b2}, nil)
+	for _, block := range []*wire.MsgBlock{a, b, b2, c} {
+		addBlockForTest(dag, block, t)
+	}
+
+	tests := []struct {
+		name     string
+		from, to *wire.MsgBlock
+		want     []*wire.MsgBlock
+	}{
+		{"to genesis", c, genesis, []*wire.MsgBlock{c, a, genesis}},
+		{"through a longer branch", c, b, []*wire.MsgBlock{c, b2, b}},
+		{"to itself", c, c, []*wire.MsgBlock{c}},
+		{"not an ancestor", a, b, nil},
+		{"to a descendant", genesis, c, nil},
+	}
+	for _, test := range tests {
+		from, to := test.from.BlockHash(), test.to.BlockHash()
+		path, err := dag.DAGPath(&from, &to)
+		if err != nil {
+			t.Fatalf("DAGPath (%s): %v", test.name, err)
+		}
+		if len(path) != len(test.want) {
+			t.Fatalf("DAGPath (%s): got %v, want %d blocks", test.name,
+				path, len(test.want))
+		}
+		for i, block := range test.want {
+			if path[i] != block.BlockHash() {
+				t.Fatalf("DAGPath (%s): got %v, want %v at %d",
+					test.name, path[i], block.BlockHash(), i)
+			}
+		}
+	}
+
+	unknown := chainhash.DoubleHashH([]byte("unknown block"))
+	cHash := c.BlockHash()
+	if _, err := dag.DAGPath(&cHash, &unknown); err == nil {
+		t.Fatalf("DAGPath to an unknown block didn't return an error")
+	}
+}
+
+// TestAnticone ensures the anticone of a block holds the blocks that are
+// neither its ancestors nor its descendants, ordered by height.
+func TestAnticone(t *testing.T) {
+	params := chaincfg.SimNetParams
+	dag, teardownFunc, err := chainSetup("anticone", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup dag instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Since we're not dealing with the real block dag, set the coinbase
+	// maturity to 1.
+	dag.TstSetCoinbaseMaturity(1)
+
+	// Build the dag
+	//
+	//   genesis <- a <------- c
+	//          \             /
+	//           <- b <- b2 <-
+	now := time.Now().Unix()
+	genesis := params.GenesisBlock
+	a := createMsgBlockForTest(1, now-30, []*wire.MsgBlock{genesis}, nil)
+	b := createMsgBlockForTest(1, now-20, []*wire.MsgBlock{genesis}, nil)
+	b2 := createMsgBlockForTest(2, now-10, []*wire.MsgBlock{b}, nil)
+	c := createMsgBlockForTest(3, now, []*wire.MsgBlock{a, b2}, nil)
+	for _, block := range []*wire.MsgBlock{a, b, b2, c} {
+		addBlockForTest(dag, block, t)
+	}
+
+	tests := []struct {
+		name  string
+		block *wire.MsgBlock
+		limit int
+		want  []*wire.MsgBlock
+		size  int
+	}{
+		{"side branch", a, 0, []*wire.MsgBlock{b, b2}, 2},
+		{"limited", a, 1, []*wire.MsgBlock{b}, 2},
+		{"other branch", b2, 0, []*wire.MsgBlock{a}, 1},
+		{"merge block", c, 0, nil, 0},
+		{"genesis", genesis, 0, nil, 0},
+	}
+	for _, test := range tests {
+		hash := test.block.BlockHash()
+		anticone, size, err := dag.Anticone(&hash, test.limit)
+		if err != nil {
+			t.Fatalf("Anticone (%s): %v", test.name, err)
+		}
+		if size != test.size {
+			t.Fatalf("Anticone (%s): got size %d, want %d", test.name,
+				size, test.size)
+		}
+		if len(anticone) != len(test.want) {
+			t.Fatalf("Anticone (%s): got %v, want %d blocks",
+				test.name, anticone, len(test.want))
+		}
+		for i, block := range test.want {
+			if anticone[i] != block.BlockHash() {
+				t.Fatalf("Anticone (%s): got %v, want %v at %d",
+					test.name, anticone[i], block.BlockHash(), i)
+			}
+		}
+	}
+
+	unknown := chainhash.DoubleHashH([]byte("unknown block"))
+	if _, _, err := dag.Anticone(&unknown, 0); err == nil {
+		t.Fatalf("Anticone of an unknown block didn't return an error")
+	}
+}
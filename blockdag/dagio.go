@@ -1104,7 +1104,7 @@ func (b *BlockDAG) createChainState() error {
 	blockWeight := uint64(GetBlockWeight(genesisBlock))
 	b.stateSnapshot = newBestState(node, blockSize, blockWeight, numTxns,
 		numTxns, time.Unix(node.timestamp, 0))
-	b.dagSnapshot = newDAGState(b.dView.Tips(), 1)
+	b.dagSnapshot = newDAGState(b.dView.Tips(), 1, b.chainParams.MaxBlockParents)
 
 	// Create the initial the database chain state including creating the
 	// necessary index buckets and inserting the genesis block.
@@ -1383,7 +1383,8 @@ func (b *BlockDAG) initChainState() error {
 		numTxns := uint64(len(block.Transactions))
 		b.stateSnapshot = newBestState(tip, blockSize, blockWeight,
 			numTxns, state.totalTxns, tip.CalcPastMedianTime())
-		b.dagSnapshot = newDAGState(b.dView.Tips(), dagState.blkCount)
+		b.dagSnapshot = newDAGState(b.dView.Tips(), dagState.blkCount,
+			b.chainParams.MaxBlockParents)
 
 		return nil
 	})
@@ -221,6 +221,14 @@ const (
 	// current chain tip. This is not a block validation rule, but is required
 	// for block proposals submitted via getblocktemplate RPC.
 	ErrPrevBlockNotBest
+
+	// ErrTooManyParents indicates that the block references more parents
+	// than the maximum allowed by the network.
+	ErrTooManyParents
+
+	// ErrParentsNotCanonical indicates that the block references the same
+	// parent more than once, or that its parents aren't in canonical order.
+	ErrParentsNotCanonical
 )
 
 // Map of ErrorCode values back to their constant names for pretty printing.
@@ -268,6 +276,8 @@ var errorCodeStrings = map[ErrorCode]string{
 	ErrPreviousBlockUnknown:      "ErrPreviousBlockUnknown",
 	ErrInvalidAncestorBlock:      "ErrInvalidAncestorBlock",
 	ErrPrevBlockNotBest:          "ErrPrevBlockNotBest",
+	ErrTooManyParents:            "ErrTooManyParents",
+	ErrParentsNotCanonical:       "ErrParentsNotCanonical",
 }
 
 // String returns the ErrorCode as a human-readable name.
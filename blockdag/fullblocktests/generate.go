@@ -546,9 +546,9 @@ func (g *testGenerator) nextBlock(blockName string, spend *spendableOut, parentN
 	prevHash := blockdag.GenerateTipsHash(tipHashes)
 
 	parentInfo := make([]*wire.Parent, len(tipHashes))
-	for i, parent := range parents {
+	for i, tipHash := range soterutil.CanonicalizeParents(tipHashes) {
 		parentInfo[i] = &wire.Parent{
-			Hash: parent.BlockHash(),
+			Hash: *tipHash,
 		}
 	}
 	//fmt.Printf("parent info: %v\n", parentInfo)
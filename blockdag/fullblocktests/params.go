@@ -119,6 +119,7 @@ var regressionNetParams = &chaincfg.Params{
 	ReduceMinDifficulty:      true,
 	MinDiffReductionTime:     time.Minute * 20, // TargetTimePerBlock * 2
 	GenerateSupported:        true,
+	MaxBlockParents:          8,
 
 	// NOTE(cedric): Commented out to disable checkpoint-related code (JIRA DAG-3)
 	// https://soteria.atlassian.net/browse/DAG-3
@@ -21,6 +21,12 @@ import (
 const (
 	// cfIndexName is the human-readable name for the index.
 	cfIndexName = "committed filter index"
+
+	// cfIndexVersion is the version of the format of the index entries.
+	// Version 1 chains the filter header of a block over the filter headers
+	// of all its parents (see storeFilter).  An index created before the
+	// format was versioned is dropped and created again.
+	cfIndexVersion = 1
 )
 
 // Committed filters come in one flavor currently: basic. They are generated
@@ -87,6 +93,9 @@ var _ Indexer = (*CfIndex)(nil)
 // Ensure the CfIndex type implements the NeedsInputser interface.
 var _ NeedsInputser = (*CfIndex)(nil)
 
+// Ensure the CfIndex type implements the Versioner interface.
+var _ Versioner = (*CfIndex)(nil)
+
 // NeedsInputs signals that the index requires the referenced inputs in order
 // to properly create the index.
 //
@@ -95,6 +104,12 @@ func (idx *CfIndex) NeedsInputs() bool {
 	return true
 }
 
+// Version returns the version of the format of the index entries.  This is
+// part of the Versioner interface.
+func (idx *CfIndex) Version() uint32 {
+	return cfIndexVersion
+}
+
 // Init initializes the hash-based cf index. This is part of the Indexer
 // interface.
 func (idx *CfIndex) Init() error {
@@ -149,7 +164,9 @@ func (idx *CfIndex) Create(dbTx database.Tx) error {
 }
 
 // storeFilter stores a given filter, and performs the steps needed to
-// generate the filter's header.
+// generate the filter's header.  The header chains over the filter headers of
+// the block's parents, not over the block before it in the dag ordering, since
+// the ordering of indexed blocks can change as blocks are added.
 func storeFilter(dbTx database.Tx, block *soterutil.Block, f *gcs.Filter,
 	filterType wire.FilterType) error {
 	if uint8(filterType) > maxFilterType {
@@ -182,43 +199,33 @@ func storeFilter(dbTx database.Tx, block *soterutil.Block, f *gcs.Filter,
 		return err
 	}
 
-	// Then fetch the parent blocks' filter headers.
+	// Then fetch the parent blocks' filter headers, and construct the new
+	// block's filter header from them.  See MakeHeaderForDagFilter for how
+	// the headers chain in the dag.
+	var parentHeaders []chainhash.Hash
 	ph := &block.MsgBlock().Header.PrevBlock
-	if ph.IsEqual(&zeroHash) {
-		prevHeader := &zeroHash
+	if !ph.IsEqual(&zeroHash) {
+		parents := block.MsgBlock().Parents.ParentHashes()
+		parentHeaders = make([]chainhash.Hash, len(parents))
+		for i := range parents {
+			pfh, err := dbFetchFilterIdxEntry(dbTx, hkey, &parents[i])
+			if err != nil {
+				return err
+			}
 
-		fh, err := builder.MakeHeaderForFilter(f, *prevHeader)
-		if err != nil {
-			return err
+			err = parentHeaders[i].SetBytes(pfh)
+			if err != nil {
+				return err
+			}
 		}
-
-		return dbStoreFilterIdxEntry(dbTx, hkey, h, fh[:])
 	}
 
-	for _, parent := range block.MsgBlock().Parents.ParentHashes() {
-		pfh, err := dbFetchFilterIdxEntry(dbTx, hkey, &parent)
-		if err != nil {
-			return err
-		}
-
-		// Construct the new block's filter header, and store it.
-		prevHeader, err := chainhash.NewHash(pfh)
-		if err != nil {
-			return err
-		}
-
-		fh, err := builder.MakeHeaderForFilter(f, *prevHeader)
-		if err != nil {
-			return err
-		}
-
-		err = dbStoreFilterIdxEntry(dbTx, hkey, h, fh[:])
-		if err != nil {
-			return err
-		}
+	fh, err := builder.MakeHeaderForDagFilter(f, parentHeaders)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return dbStoreFilterIdxEntry(dbTx, hkey, h, fh[:])
 }
 
 // ConnectBlock is invoked by the index manager when a new block has been
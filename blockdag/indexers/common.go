@@ -30,6 +30,13 @@ type NeedsInputser interface {
 	NeedsInputs() bool
 }
 
+// Versioner provides a generic interface for an indexer to specify the version
+// of the format of its entries.  An index that was created with a different
+// version is dropped and created again.
+type Versioner interface {
+	Version() uint32
+}
+
 // Indexer provides a generic interface for an indexer that is managed by an
 // index manager such as the Manager type provided by this package.
 type Indexer interface {
@@ -151,6 +151,15 @@ func indexDropKey(idxKey []byte) []byte {
 	return dropKey
 }
 
+// indexVersionKey returns the key for the version of the format an index was
+// created with.
+func indexVersionKey(idxKey []byte) []byte {
+	versionKey := make([]byte, len(idxKey)+1)
+	versionKey[0] = 'v'
+	copy(versionKey[1:], idxKey)
+	return versionKey
+}
+
 // maybeFinishDrops determines if each of the enabled indexes are in the middle
 // of being dropped and finishes dropping them when the are.  This is necessary
 // because dropping and index has to be done in several atomic steps rather than
@@ -201,6 +210,61 @@ func (m *Manager) maybeFinishDrops(interrupt <-chan struct{}) error {
 	return nil
 }
 
+// maybeDropOutdated determines if each of the enabled indexes that specify the
+// version of their format were created with a different version, and drops
+// them when they were, so they're created again with the current version.  An
+// index without a stored version was created before its format was versioned.
+func (m *Manager) maybeDropOutdated(interrupt <-chan struct{}) error {
+	indexVersions := make([]uint32, len(m.enabledIndexes))
+	indexNeedsDrop := make([]bool, len(m.enabledIndexes))
+	err := m.db.View(func(dbTx database.Tx) error {
+		// None of the indexes has been created yet if the index tips
+		// bucket hasn't been created.
+		indexesBucket := dbTx.Metadata().Bucket(indexTipsBucketName)
+		if indexesBucket == nil {
+			return nil
+		}
+
+		for i, indexer := range m.enabledIndexes {
+			versioner, ok := indexer.(Versioner)
+			if !ok || indexesBucket.Get(indexer.Key()) == nil {
+				continue
+			}
+
+			versionKey := indexVersionKey(indexer.Key())
+			if serialized := indexesBucket.Get(versionKey); len(serialized) == 4 {
+				indexVersions[i] = byteOrder.Uint32(serialized)
+			}
+			indexNeedsDrop[i] = indexVersions[i] != versioner.Version()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if interruptRequested(interrupt) {
+		return errInterruptRequested
+	}
+
+	for i, indexer := range m.enabledIndexes {
+		if !indexNeedsDrop[i] {
+			continue
+		}
+
+		log.Infof("Dropping %s created with version %d, to create it "+
+			"again with version %d", indexer.Name(), indexVersions[i],
+			indexer.(Versioner).Version())
+		err := dropIndex(m.db, indexer.Key(), indexer.Name(), interrupt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // maybeCreateIndexes determines if each of the enabled indexes have already
 // been created and creates them if not.
 func (m *Manager) maybeCreateIndexes(dbTx database.Tx) error {
@@ -225,6 +289,18 @@ func (m *Manager) maybeCreateIndexes(dbTx database.Tx) error {
 		if err != nil {
 			return err
 		}
+
+		// Store the version of the format the index is created with,
+		// for indexes that specify it.
+		if versioner, ok := indexer.(Versioner); ok {
+			var serialized [4]byte
+			byteOrder.PutUint32(serialized[:], versioner.Version())
+			err := indexesBucket.Put(indexVersionKey(idxKey),
+				serialized[:])
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -253,6 +329,12 @@ func (m *Manager) Init(chain *blockdag.BlockDAG, interrupt <-chan struct{}) erro
 		return err
 	}
 
+	// Drop the indexes created with an outdated format, so that they're
+	// created again below.
+	if err := m.maybeDropOutdated(interrupt); err != nil {
+		return err
+	}
+
 	// Create the initial state for the indexes as needed.
 	err := m.db.Update(func(dbTx database.Tx) error {
 		// Create the bucket for the current tips as needed.
@@ -685,8 +767,8 @@ func dropIndex(db database.DB, idxKey []byte, idxName string, interrupt <-chan s
 		}
 	}
 
-	// Remove the index tip, index bucket, and in-progress drop flag now
-	// that all index entries have been removed.
+	// Remove the index tip, index version, index bucket, and in-progress
+	// drop flag now that all index entries have been removed.
 	err = db.Update(func(dbTx database.Tx) error {
 		meta := dbTx.Metadata()
 		indexesBucket := meta.Bucket(indexTipsBucketName)
@@ -694,6 +776,10 @@ func dropIndex(db database.DB, idxKey []byte, idxName string, interrupt <-chan s
 			return err
 		}
 
+		if err := indexesBucket.Delete(indexVersionKey(idxKey)); err != nil {
+			return err
+		}
+
 		return indexesBucket.Delete(indexDropKey(idxKey))
 	})
 	if err != nil {
@@ -7,6 +7,8 @@ package blockdag
 
 import (
 	"fmt"
+
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
 )
 
 // NotificationType represents the type of a notification message.
@@ -30,6 +32,11 @@ const (
 	// NTBlockDisconnected indicates the associated block was disconnected
 	// from the main chain.
 	NTBlockDisconnected
+
+	// NTBlockReordered indicates that connecting a block moved previously
+	// connected blocks between the blue and red sets of the DAG.  It's sent
+	// after the NTBlockConnected notification of the block.
+	NTBlockReordered
 )
 
 // notificationTypeStrings is a map of notification types back to their constant
@@ -38,6 +45,7 @@ var notificationTypeStrings = map[NotificationType]string{
 	NTBlockAccepted:     "NTBlockAccepted",
 	NTBlockConnected:    "NTBlockConnected",
 	NTBlockDisconnected: "NTBlockDisconnected",
+	NTBlockReordered:    "NTBlockReordered",
 }
 
 // String returns the NotificationType in human-readable form.
@@ -54,11 +62,24 @@ func (n NotificationType) String() string {
 // 	- NTBlockAccepted:     *soterutil.Block
 // 	- NTBlockConnected:    *soterutil.Block
 // 	- NTBlockDisconnected: *soterutil.Block
+// 	- NTBlockReordered:    *ReorderedBlocks
 type Notification struct {
 	Type NotificationType
 	Data interface{}
 }
 
+// ReorderedBlocks describes the previously connected blocks whose color was
+// changed by the ordering of the DAG after a block was connected.
+type ReorderedBlocks struct {
+	// Blue holds the hashes of the blocks that moved from the red set to
+	// the blue set, in the order of the DAG.
+	Blue []chainhash.Hash
+
+	// Red holds the hashes of the blocks that moved from the blue set to
+	// the red set, in the order of the DAG.
+	Red []chainhash.Hash
+}
+
 // Subscribe to block chain notifications. Registers a callback to be executed
 // when various events take place. See the documentation on Notification and
 // NotificationType for details on the types and contents of notifications.
@@ -59,6 +59,17 @@ func NewBlueSetCache() *BlueSetCache {
 	}
 }
 
+// GetBlueSetSize returns the number of nodes in the cached blue set of the
+// node, or 0 if the node has no cached blue set.
+func (blueset *BlueSetCache) GetBlueSetSize(n *node) int {
+	set, ok := blueset.cache[n]
+	if !ok {
+		return 0
+	}
+
+	return set.size()
+}
+
 func (blueset *BlueSetCache) GetBlueNodes(n *node) []*node {
 
 	set, ok := blueset.cache[n]
@@ -132,6 +143,15 @@ func calculateBlueSet(g *Graph, genesisNode *node, k int, blueSetCache *BlueSetC
 
 // need to create a graph with a virtual node
 func OrderDAG(g *Graph, genesisNode *node, k int, blueSetCache *BlueSetCache) []*node {
+	order, _ := OrderAndColorDAG(g, genesisNode, k, blueSetCache)
+	return order
+}
+
+// OrderAndColorDAG returns the ordering of the nodes of the graph, like
+// OrderDAG, along with the nodes of the blue set the ordering is based on.
+// The blue set is the one of the whole graph, rather than of the past of one of
+// its nodes, so nodes can move in or out of it as nodes are added to the graph.
+func OrderAndColorDAG(g *Graph, genesisNode *node, k int, blueSetCache *BlueSetCache) ([]*node, []*node) {
 
 	g.RLock()
 	defer g.RUnlock()
@@ -190,6 +210,16 @@ func OrderDAG(g *Graph, genesisNode *node, k int, blueSetCache *BlueSetCache) []
 		}
 	}
 
-	return orderingSet.getNodes()
+	// The virtual node is only used to color the graph, and isn't part of
+	// it.
+	blueNodes := make([]*node, 0, blueSet.size())
+	for _, node := range blueSet.elements() {
+		if g.getNodeById(node.GetId()) == nil {
+			continue
+		}
+		blueNodes = append(blueNodes, node)
+	}
+
+	return orderingSet.getNodes(), blueNodes
 }
 
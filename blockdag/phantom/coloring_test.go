@@ -1,7 +1,9 @@
 package phantom
 
 import (
+	"fmt"
 	"reflect"
+	"sort"
 	"testing"
 )
 
@@ -139,6 +141,65 @@ func TestOrderDAG(t *testing.T) {
 	}
 }
 
+func TestOrderAndColorDAG(t *testing.T) {
+	var graph = createGraph()
+	var genesis = graph.GetNodeById("GENESIS")
+
+	var orderedNodes, blueNodes = OrderAndColorDAG(graph, genesis, 3, NewBlueSetCache())
+	var expected = []string{"GENESIS", "B", "C", "D", "F", "E", "H", "I", "K", "J", "M", "L"}
+
+	if !reflect.DeepEqual(expected, getIds(orderedNodes)) {
+		t.Errorf("Incorrect ordering for k = 3. Expecting %v, got %v", expected, getIds(orderedNodes))
+	}
+
+	expected = []string{"B", "C", "D", "F", "GENESIS", "H", "J", "K", "M"}
+	sort.Strings(expected)
+	var blueIds = getIds(blueNodes)
+	sort.Strings(blueIds)
+
+	if !reflect.DeepEqual(expected, blueIds) {
+		t.Errorf("Incorrect blue set for k = 3. Expecting %v, got %v", expected, blueIds)
+	}
+
+	// A chain competing with a longer one is red, until it outgrows it.
+	graph = NewGraph()
+	graph.AddNodeById("GENESIS")
+	genesis = graph.GetNodeById("GENESIS")
+	var blueSetCache = NewBlueSetCache()
+	var isBlue = func(id string) bool {
+		var _, blueNodes = OrderAndColorDAG(graph, genesis, 3, blueSetCache)
+		for _, node := range blueNodes {
+			if node.GetId() == id {
+				return true
+			}
+		}
+		return false
+	}
+	var addChain = func(prefix string, n int) {
+		var parent = "GENESIS"
+		for i := 1; i <= n; i++ {
+			var id = fmt.Sprintf("%s%d", prefix, i)
+			graph.AddNodeById(id)
+			graph.AddEdgeById(id, parent)
+			parent = id
+		}
+	}
+
+	addChain("S", 5)
+	addChain("C", 4)
+	if !isBlue("S1") || isBlue("C1") {
+		t.Errorf("Expected S1 to be blue and C1 red with a shorter competing chain")
+	}
+
+	graph.AddNodeById("C5")
+	graph.AddEdgeById("C5", "C4")
+	graph.AddNodeById("C6")
+	graph.AddEdgeById("C6", "C5")
+	if isBlue("S1") || !isBlue("C1") {
+		t.Errorf("Expected S1 to be red and C1 blue with a longer competing chain")
+	}
+}
+
 func TestFigure4BlueSet(t *testing.T) {
 	var graph = createFigure4DAG()
 	var genesis = graph.GetNodeById("GENESIS")
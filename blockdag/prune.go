@@ -0,0 +1,190 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockdag
+
+import (
+	"fmt"
+
+	"github.com/soteria-dag/soterd/database"
+)
+
+// reclaimableBytes is the total serialized size of the blocks below a height.
+type reclaimableBytes struct {
+	height int32
+	bytes  int64
+}
+
+// minParentHeight returns the minimum height of the parent blocks of a block
+// node, or the height of the node itself when it has no parents.
+func (node *blockNode) minParentHeight() int32 {
+	minHeight := node.height
+	for _, parent := range node.parents {
+		if parent.height < minHeight {
+			minHeight = parent.height
+		}
+	}
+
+	return minHeight
+}
+
+// checkPruneHeight returns an error if pruning the blocks below the given
+// height would break the parent references of the remaining blocks.
+//
+// This function MUST be called with the dag view mutex locked (for reads).
+func (b *BlockDAG) checkPruneHeight(height int32) error {
+	c := b.dView
+	maxHeight := c.height()
+	if height < 0 || height > maxHeight {
+		return fmt.Errorf("prune height %d is out of range, the dag "+
+			"has blocks up to height %d", height, maxHeight)
+	}
+
+	// The tips are the parents of the next blocks, so none of them can be
+	// pruned.
+	for tip := range c.dagTips {
+		if tip.height < height {
+			return fmt.Errorf("pruning below height %d would remove "+
+				"tip %s at height %d", height, tip.hash, tip.height)
+		}
+	}
+
+	// The blocks at the prune height become the base of the dag, so they
+	// may reference pruned parents.  Every block above them must only
+	// reference parents that remain.
+	for h := height + 1; h <= maxHeight; h++ {
+		for _, node := range c.nodesByHeight(h) {
+			for _, parent := range node.parents {
+				if parent.height < height {
+					return fmt.Errorf("pruning below height %d "+
+						"would remove parent %s at height %d of "+
+						"block %s at height %d", height,
+						parent.hash, parent.height, node.hash,
+						node.height)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// CheckPruneHeight returns an error if pruning the blocks below the given
+// height would break the parent references of the blocks that remain.
+//
+// In a chain, pruning below a height leaves the block at that height as the
+// base of the chain.  In a dag a block can reference parents far below its
+// own height, such as when it merges a branch that was mined concurrently, so
+// pruning below a height is refused when:
+//
+//   - a block above the height has a parent below the height
+//   - a dag tip is below the height, since the next blocks will reference it
+//
+// The blocks at the height itself may reference pruned parents, since they
+// become the base of the dag.  Pruning below height 0 prunes nothing.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) CheckPruneHeight(height int32) error {
+	b.dView.mtx.Lock()
+	defer b.dView.mtx.Unlock()
+
+	return b.checkPruneHeight(height)
+}
+
+// MaxPruneHeight returns the highest height that the blocks below could be
+// pruned at, without breaking the parent references of the blocks that
+// remain.  See CheckPruneHeight for how the references are checked.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) MaxPruneHeight() int32 {
+	b.dView.mtx.Lock()
+	defer b.dView.mtx.Unlock()
+
+	c := b.dView
+	maxHeight := c.height()
+	if maxHeight <= 0 {
+		return 0
+	}
+
+	// None of the tips can be pruned.
+	limit := maxHeight
+	for tip := range c.dagTips {
+		if tip.height < limit {
+			limit = tip.height
+		}
+	}
+
+	// Walk down from the max height, tracking the lowest parent referenced
+	// by the blocks above the height being considered.  The first height
+	// at or below the lowest referenced parent is the highest that can be
+	// pruned at.
+	minParent := maxHeight
+	for h := maxHeight; h > 0; h-- {
+		if h <= limit && h <= minParent {
+			return h
+		}
+
+		for _, node := range c.nodesByHeight(h) {
+			if p := node.minParentHeight(); p < minParent {
+				minParent = p
+			}
+		}
+	}
+
+	return 0
+}
+
+// heightBytes returns the total serialized size of the blocks at a height.
+//
+// This function MUST be called with the chain lock held (for reads).
+func (b *BlockDAG) heightBytes(height int32) (int64, error) {
+	b.dView.mtx.Lock()
+	nodes := b.dView.nodesByHeight(height)
+	b.dView.mtx.Unlock()
+
+	var bytes int64
+	err := b.db.View(func(dbTx database.Tx) error {
+		for _, node := range nodes {
+			block, err := dbTx.FetchBlock(&node.hash)
+			if err != nil {
+				return err
+			}
+			bytes += int64(len(block))
+		}
+		return nil
+	})
+	return bytes, err
+}
+
+// ReclaimableBytes returns the max prune height (see MaxPruneHeight), and the
+// total serialized size of the blocks below it.  The total is kept up to date
+// as blocks connect, so only the blocks at the heights that the max prune
+// height moved across since the last call are loaded.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) ReclaimableBytes() (int32, int64, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	maxPruneHeight := b.MaxPruneHeight()
+	r := &b.reclaimable
+	for r.height < maxPruneHeight {
+		bytes, err := b.heightBytes(r.height)
+		if err != nil {
+			return 0, 0, err
+		}
+		r.bytes += bytes
+		r.height++
+	}
+	for r.height > maxPruneHeight {
+		bytes, err := b.heightBytes(r.height - 1)
+		if err != nil {
+			return 0, 0, err
+		}
+		r.bytes -= bytes
+		r.height--
+	}
+
+	return maxPruneHeight, r.bytes, nil
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockdag
+
+import (
+	"testing"
+	"time"
+
+	"github.com/soteria-dag/soterd/chaincfg"
+	"github.com/soteria-dag/soterd/wire"
+)
+
+// TestCheckPruneHeight ensures a prune is refused when it would remove the
+// parent of a remaining block, or a dag tip.
+func TestCheckPruneHeight(t *testing.T) {
+	// 0 <- 1 <- 2 <- 3 <---- 4
+	//  \<- 1a <-------------/
+	block0 := createBlock(nil)
+	block1 := createBlock([]*blockNode{block0})
+	block1a := createBlock([]*blockNode{block0})
+	block2 := createBlock([]*blockNode{block1})
+	block3 := createBlock([]*blockNode{block2})
+	block4 := createBlock([]*blockNode{block3, block1a})
+
+	tests := []struct {
+		name    string
+		tips    []*blockNode // tips of the dag
+		allowed []int32      // heights that can be pruned at
+		refused []int32      // heights that can't be pruned at
+		max     int32        // expected max prune height
+	}{
+		{
+			// 1a is an unmerged tip, so it can't be pruned.
+			name:    "unmerged branch",
+			tips:    []*blockNode{block3, block1a},
+			allowed: []int32{0, 1},
+			refused: []int32{-1, 2, 3, 4},
+			max:     1,
+		},
+		{
+			// 4 references 1a, so everything above height 1
+			// except 4 itself would break the reference.
+			name:    "merged branch",
+			tips:    []*blockNode{block4},
+			allowed: []int32{0, 1, 4},
+			refused: []int32{-1, 2, 3, 5},
+			max:     4,
+		},
+		{
+			name:    "genesis",
+			tips:    []*blockNode{block0},
+			allowed: []int32{0},
+			refused: []int32{1},
+			max:     0,
+		},
+	}
+
+	for _, test := range tests {
+		dag := &BlockDAG{dView: newDAGView(test.tips)}
+
+		for _, height := range test.allowed {
+			if err := dag.CheckPruneHeight(height); err != nil {
+				t.Errorf("%s: CheckPruneHeight(%d) refused: %v",
+					test.name, height, err)
+			}
+		}
+
+		for _, height := range test.refused {
+			if err := dag.CheckPruneHeight(height); err == nil {
+				t.Errorf("%s: CheckPruneHeight(%d) allowed a prune "+
+					"that breaks parent references", test.name,
+					height)
+			}
+		}
+
+		if max := dag.MaxPruneHeight(); max != test.max {
+			t.Errorf("%s: MaxPruneHeight = %d, want %d", test.name,
+				max, test.max)
+		}
+	}
+}
+
+// TestReclaimableBytes ensures the reclaimable bytes stay the size of the
+// blocks below the max prune height, as the max prune height moves up and down
+// and blocks connect below the height they were counted up to.
+func TestReclaimableBytes(t *testing.T) {
+	params := chaincfg.SimNetParams
+	dag, teardownFunc, err := chainSetup("reclaimablebytes", &params)
+	if err != nil {
+		t.Fatalf("Failed to setup dag instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Since we're not dealing with the real block dag, set the coinbase
+	// maturity to 1.
+	dag.TstSetCoinbaseMaturity(1)
+
+	// checkReclaimable compares the reclaimable bytes to the size of the
+	// blocks below the max prune height, loaded one by one.
+	checkReclaimable := func(step string, wantMax int32) {
+		maxPruneHeight, reclaimable, err := dag.ReclaimableBytes()
+		if err != nil {
+			t.Fatalf("%s: ReclaimableBytes: %v", step, err)
+		}
+		if maxPruneHeight != wantMax {
+			t.Fatalf("%s: max prune height %d, want %d", step,
+				maxPruneHeight, wantMax)
+		}
+
+		var want int64
+		for height := int32(0); height < maxPruneHeight; height++ {
+			hashes, err := dag.BlockHashesByHeight(height)
+			if err != nil {
+				t.Fatalf("%s: BlockHashesByHeight: %v", step, err)
+			}
+			for i := range hashes {
+				block, err := dag.BlockByHash(&hashes[i])
+				if err != nil {
+					t.Fatalf("%s: BlockByHash: %v", step, err)
+				}
+				want += int64(block.MsgBlock().SerializeSize())
+			}
+		}
+		if reclaimable != want {
+			t.Fatalf("%s: %d reclaimable bytes, want %d", step,
+				reclaimable, want)
+		}
+	}
+
+	checkReclaimable("genesis", 0)
+
+	ts := time.Now().Unix() - 100
+	parent := params.GenesisBlock
+	for height := uint32(1); height <= 4; height++ {
+		block := createMsgBlockForTest(height, ts,
+			[]*wire.MsgBlock{parent}, nil)
+		addBlockForTest(dag, block, t)
+		parent = block
+		ts++
+	}
+	checkReclaimable("chain", 4)
+
+	// A branch on the genesis block is a tip that can't be pruned, so the
+	// max prune height moves down to it.
+	branch := createMsgBlockForTest(1, ts,
+		[]*wire.MsgBlock{params.GenesisBlock}, nil)
+	addBlockForTest(dag, branch, t)
+	ts++
+	checkReclaimable("branch", 1)
+
+	// Merging the branch moves the max prune height up past it.
+	merge := createMsgBlockForTest(5, ts, []*wire.MsgBlock{parent, branch},
+		nil)
+	addBlockForTest(dag, merge, t)
+	ts++
+	checkReclaimable("merge", 5)
+
+	// A block that connects below the counted height is counted as it
+	// connects, and taken off again as the max prune height moves down to
+	// the branch its tip references.
+	low := createMsgBlockForTest(2, ts, []*wire.MsgBlock{branch}, nil)
+	addBlockForTest(dag, low, t)
+	checkReclaimable("low block", 1)
+}
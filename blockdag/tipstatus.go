@@ -0,0 +1,269 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockdag
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+	"github.com/soteria-dag/soterd/soterutil"
+)
+
+// TipStatus describes how a tip of a branch of blocks known to the node
+// relates to the dag that new blocks build on.
+type TipStatus int
+
+const (
+	// TipActive is the status of a tip of the dag that new blocks reference
+	// as a parent.  Unlike a chain, which has a single active tip, every
+	// tip of the dag is active, unless there are more tips than a block may
+	// reference.
+	TipActive TipStatus = iota
+
+	// TipValidFork is the status of a tip of the dag that was fully
+	// validated, but that new blocks don't reference.  This happens when
+	// there are more tips than a block may reference as parents, and the
+	// tip has less work in its past than the tips that are referenced.
+	TipValidFork
+
+	// TipValidHeaders is the status of a block in the orphan pool that no
+	// other orphan builds on.  The block passed the checks that don't
+	// depend on its parents, like its proof of work, but it isn't part of
+	// the dag because some of its parents are missing.
+	TipValidHeaders
+
+	// TipInvalid is the status of a block that failed validation, and that
+	// no other invalid block builds on.  Blocks building on an invalid
+	// block are rejected, so these blocks are never part of the dag.
+	TipInvalid
+)
+
+// tipStatusStrings is a map of TipStatus values back to their constant names
+// for pretty printing.
+var tipStatusStrings = map[TipStatus]string{
+	TipActive:       "TipActive",
+	TipValidFork:    "TipValidFork",
+	TipValidHeaders: "TipValidHeaders",
+	TipInvalid:      "TipInvalid",
+}
+
+// String returns the TipStatus as a human-readable name.
+func (s TipStatus) String() string {
+	if str := tipStatusStrings[s]; str != "" {
+		return str
+	}
+	return fmt.Sprintf("Unknown TipStatus (%d)", int(s))
+}
+
+// TipInfo describes the tip of a branch of blocks known to the node.
+type TipInfo struct {
+	// Hash is the hash of the tip block.
+	Hash chainhash.Hash
+
+	// Height is the height of the tip block.  It's
+	// soterutil.BlockHeightUnknown for orphan blocks whose height can't be
+	// determined from their coinbase transaction.
+	Height int32
+
+	// BranchLen is the number of generations between the tip and the
+	// nearest of its ancestors in the past of the active tips, which is 0
+	// for active tips.  For orphan blocks, whose ancestors aren't known,
+	// it's the number of generations of orphans ending at the tip, which
+	// is 1 for an orphan whose parents are all missing.
+	BranchLen int32
+
+	// Status describes how the tip relates to the dag.
+	Status TipStatus
+}
+
+// pastNodes returns the set of the passed nodes and all of their ancestors.
+func pastNodes(nodes []*blockNode) map[*blockNode]struct{} {
+	past := make(map[*blockNode]struct{})
+	queue := make([]*blockNode, 0, len(nodes))
+	for _, node := range nodes {
+		if _, ok := past[node]; ok {
+			continue
+		}
+		past[node] = struct{}{}
+		queue = append(queue, node)
+	}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		for _, parent := range n.parents {
+			if _, ok := past[parent]; ok {
+				continue
+			}
+			past[parent] = struct{}{}
+			queue = append(queue, parent)
+		}
+	}
+
+	return past
+}
+
+// branchLen returns the number of generations between the node and the nearest
+// of its ancestors in the given past, or 0 when the node is in the past itself.
+func branchLen(node *blockNode, past map[*blockNode]struct{}) int32 {
+	if _, ok := past[node]; ok {
+		return 0
+	}
+
+	var forkHeight int32
+	seen := map[*blockNode]struct{}{node: {}}
+	queue := []*blockNode{node}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		for _, parent := range n.parents {
+			if _, ok := seen[parent]; ok {
+				continue
+			}
+			seen[parent] = struct{}{}
+
+			if _, ok := past[parent]; ok {
+				if parent.height > forkHeight {
+					forkHeight = parent.height
+				}
+				continue
+			}
+			queue = append(queue, parent)
+		}
+	}
+
+	return node.height - forkHeight
+}
+
+// orphanDepth returns the number of generations of orphans ending at the passed
+// orphan, memoizing the depths it computes in the given map.
+//
+// This function MUST be called with the orphan lock held (for reads).
+func (b *BlockDAG) orphanDepth(orphan *orphanBlock, depths map[chainhash.Hash]int32) int32 {
+	hash := *orphan.block.Hash()
+	if depth, ok := depths[hash]; ok {
+		return depth
+	}
+
+	var parentDepth int32
+	for _, parentHash := range orphan.block.MsgBlock().Parents.ParentHashes() {
+		parent, exists := b.orphans[parentHash]
+		if !exists {
+			continue
+		}
+		if depth := b.orphanDepth(parent, depths); depth > parentDepth {
+			parentDepth = depth
+		}
+	}
+
+	depths[hash] = parentDepth + 1
+	return parentDepth + 1
+}
+
+// TipStatuses returns the tips of the branches of blocks known to the node, and
+// how each relates to the dag, ordered by height and then hash.  These are the
+// tips of the dag, the blocks in the orphan pool that no other orphan builds
+// on, and the blocks that failed validation.  See TipStatus for how the tips
+// are classified.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) TipStatuses() []TipInfo {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	// Tips referenced by new blocks are active, the rest are valid forks.
+	parents := make(map[chainhash.Hash]struct{})
+	for _, hash := range b.DAGSnapshot().Parents {
+		parents[hash] = struct{}{}
+	}
+	dagTips := b.dView.Tips()
+	activeTips := make([]*blockNode, 0, len(parents))
+	for _, tip := range dagTips {
+		if _, ok := parents[tip.hash]; ok {
+			activeTips = append(activeTips, tip)
+		}
+	}
+	past := pastNodes(activeTips)
+
+	tips := make([]TipInfo, 0, len(dagTips))
+	for _, tip := range dagTips {
+		status := TipValidFork
+		if _, ok := parents[tip.hash]; ok {
+			status = TipActive
+		}
+		tips = append(tips, TipInfo{
+			Hash:      tip.hash,
+			Height:    tip.height,
+			BranchLen: branchLen(tip, past),
+			Status:    status,
+		})
+	}
+
+	// Blocks that failed validation are kept in the block index, but never
+	// become part of the dag.  Only report the invalid blocks that no
+	// other invalid block builds on.
+	b.index.RLock()
+	var invalid []*blockNode
+	invalidParents := make(map[*blockNode]struct{})
+	for _, node := range b.index.index {
+		if !node.status.KnownInvalid() {
+			continue
+		}
+		invalid = append(invalid, node)
+		for _, parent := range node.parents {
+			invalidParents[parent] = struct{}{}
+		}
+	}
+	b.index.RUnlock()
+	for _, node := range invalid {
+		if _, ok := invalidParents[node]; ok {
+			continue
+		}
+		tips = append(tips, TipInfo{
+			Hash:      node.hash,
+			Height:    node.height,
+			BranchLen: branchLen(node, past),
+			Status:    TipInvalid,
+		})
+	}
+
+	// Orphans that other orphans build on aren't tips.
+	b.orphanLock.RLock()
+	orphanParents := make(map[chainhash.Hash]struct{})
+	for _, orphan := range b.orphans {
+		for _, parentHash := range orphan.block.MsgBlock().Parents.ParentHashes() {
+			orphanParents[parentHash] = struct{}{}
+		}
+	}
+	depths := make(map[chainhash.Hash]int32)
+	for hash, orphan := range b.orphans {
+		if _, ok := orphanParents[hash]; ok {
+			continue
+		}
+		height, ok := orphanHeight(orphan)
+		if !ok {
+			height = soterutil.BlockHeightUnknown
+		}
+		tips = append(tips, TipInfo{
+			Hash:      hash,
+			Height:    height,
+			BranchLen: b.orphanDepth(orphan, depths),
+			Status:    TipValidHeaders,
+		})
+	}
+	b.orphanLock.RUnlock()
+
+	sort.Slice(tips, func(i, j int) bool {
+		if tips[i].Height != tips[j].Height {
+			return tips[i].Height < tips[j].Height
+		}
+		return tips[i].Hash.String() < tips[j].Hash.String()
+	})
+
+	return tips
+}
@@ -475,6 +475,18 @@ func checkBlockSanity(block *soterutil.Block, powLimit *big.Int, timeSource Medi
 		return err
 	}
 
+	// A block must reference its parents in canonical order, without
+	// duplicates, so that it can only be serialized one way.
+	parentHashes := msgBlock.Parents.ParentHashes()
+	parents := make([]*chainhash.Hash, len(parentHashes))
+	for i := range parentHashes {
+		parents[i] = &parentHashes[i]
+	}
+	if err := soterutil.ValidateParentOrdering(parents); err != nil {
+		str := fmt.Sprintf("block parents are invalid: %v", err)
+		return ruleError(ErrParentsNotCanonical, str)
+	}
+
 	// A block must have at least one transaction.
 	numTx := len(msgBlock.Transactions)
 	if numTx == 0 {
@@ -739,6 +751,14 @@ func (b *BlockDAG) checkBlockHeaderContext(header *wire.BlockHeader, prevNodes [
 //
 // This function MUST be called with the chain state lock held (for writes).
 func (b *BlockDAG) checkBlockContext(block *soterutil.Block, prevNodes []*blockNode, flags BehaviorFlags) error {
+	// A block can't reference more parents than the network allows.
+	maxParents := b.chainParams.MaxBlockParents
+	if maxParents > 0 && int32(len(prevNodes)) > maxParents {
+		str := fmt.Sprintf("block references %d parents, which is more "+
+			"than the max allowed of %d", len(prevNodes), maxParents)
+		return ruleError(ErrTooManyParents, str)
+	}
+
 	// Perform all block header related validation checks.
 	header := &block.MsgBlock().Header
 	err := b.checkBlockHeaderContext(header, prevNodes, flags)
@@ -1272,8 +1292,9 @@ func (b *BlockDAG) checkConnectBlock(node *blockNode, block *soterutil.Block, vi
 }
 
 // CheckConnectBlockTemplate fully validates that connecting the passed block to
-// the main chain does not violate any consensus rules, aside from the proof of
-// work requirement. The block must connect to the current tip of the main chain.
+// the dag does not violate any consensus rules, aside from the proof of work
+// requirement. Every parent of the block must be one of the current tips of the
+// dag, and the previous block of its header must be the hash of those parents.
 //
 // This function is safe for concurrent access.
 func (b *BlockDAG) CheckConnectBlockTemplate(block *soterutil.Block) error {
@@ -1283,14 +1304,29 @@ func (b *BlockDAG) CheckConnectBlockTemplate(block *soterutil.Block) error {
 	// Skip the proof of work check as this is just a block template.
 	flags := BFNoPoWCheck
 
-	// This only checks whether the block can be connected to the tip of the
-	// current chain.
-	tips := b.dView.Tips()
+	// This only checks whether the block can be connected to the current
+	// tips of the dag.
+	currentTips := make(map[chainhash.Hash]*blockNode)
+	for _, tip := range b.dView.Tips() {
+		currentTips[tip.hash] = tip
+	}
+	parentHashes := block.MsgBlock().Parents.ParentHashes()
+	tips := make([]*blockNode, 0, len(parentHashes))
+	for _, parentHash := range parentHashes {
+		tip, exists := currentTips[parentHash]
+		if !exists {
+			str := fmt.Sprintf("parent %v must be one of the "+
+				"current tips", parentHash)
+			return ruleError(ErrPrevBlockNotBest, str)
+		}
+		tips = append(tips, tip)
+	}
 	virtualHash := generateTipsHash(tips)
 	header := block.MsgBlock().Header
 	if *virtualHash != header.PrevBlock {
-		str := fmt.Sprintf("previous block must be the current tips %v, "+
-			"instead got %v", virtualHash, header.PrevBlock)
+		str := fmt.Sprintf("previous block must be the hash of the "+
+			"parent tips %v, instead got %v", virtualHash,
+			header.PrevBlock)
 		return ruleError(ErrPrevBlockNotBest, str)
 	}
 
@@ -1312,3 +1348,77 @@ func (b *BlockDAG) CheckConnectBlockTemplate(block *soterutil.Block) error {
 	newNode := newBlockNode(&header, &block.MsgBlock().Parents, tips)
 	return b.checkConnectBlock(newNode, block, view, nil)
 }
+
+// CheckBlockAcceptance fully validates the passed block the same way that
+// ProcessBlock does, including the checks of its parents and of its position
+// in the dag, without adding it to the dag or the orphan pool.
+//
+// A rule error with the ErrPreviousBlockUnknown code is returned when any of
+// the parents of the block isn't known, which is when ProcessBlock would add
+// the block to the orphan pool instead.  The height of the block is set when
+// all of its parents are known.
+//
+// This function is safe for concurrent access.
+func (b *BlockDAG) CheckBlockAcceptance(block *soterutil.Block) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	flags := BFNone
+	blockHash := block.Hash()
+
+	// The block must not already exist in the dag or the orphan pool.
+	exists, err := b.blockExists(blockHash)
+	if err != nil {
+		return err
+	}
+	if exists {
+		str := fmt.Sprintf("already have block %v", blockHash)
+		return ruleError(ErrDuplicateBlock, str)
+	}
+	if _, exists := b.orphans[*blockHash]; exists {
+		str := fmt.Sprintf("already have block (orphan) %v", blockHash)
+		return ruleError(ErrDuplicateBlock, str)
+	}
+
+	err = checkBlockSanity(block, b.chainParams.PowLimit, b.timeSource, flags)
+	if err != nil {
+		return err
+	}
+
+	// Every parent must be a known block that isn't known to be invalid.
+	parentHashes := block.MsgBlock().Parents.ParentHashes()
+	parentNodes := make([]*blockNode, 0, len(parentHashes))
+	var maxHeight int32
+	for _, parentHash := range parentHashes {
+		parentNode := b.index.LookupNode(&parentHash)
+		if parentNode == nil {
+			str := fmt.Sprintf("block %s parent %s is unknown",
+				blockHash, parentHash)
+			return ruleError(ErrPreviousBlockUnknown, str)
+		}
+		if b.index.NodeStatus(parentNode).KnownInvalid() {
+			str := fmt.Sprintf("block %s parent %s is known to be "+
+				"invalid", blockHash, parentHash)
+			return ruleError(ErrInvalidAncestorBlock, str)
+		}
+		if parentNode.height > maxHeight {
+			maxHeight = parentNode.height
+		}
+		parentNodes = append(parentNodes, parentNode)
+	}
+	block.SetHeight(maxHeight + 1)
+
+	err = b.checkBlockContext(block, parentNodes, flags)
+	if err != nil {
+		return err
+	}
+
+	// Leave the spent txouts entry nil in the state since the information
+	// is not needed and thus extra work can be avoided.
+	view := NewUtxoViewpoint()
+	view.SetBestHash(generateTipsHash(parentNodes))
+
+	header := block.MsgBlock().Header
+	newNode := newBlockNode(&header, &block.MsgBlock().Parents, parentNodes)
+	return b.checkConnectBlock(newNode, block, view, nil)
+}
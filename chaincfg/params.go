@@ -182,6 +182,13 @@ type Params struct {
 	// GenerateSupported specifies whether or not CPU mining is allowed.
 	GenerateSupported bool
 
+	// MaxBlockParents is the maximum number of parents a block may
+	// reference.  Blocks assembled by the node reference at most this many
+	// of the dag tips, and blocks referencing more are rejected.  It must
+	// not exceed the number of parents the wire protocol allows in a
+	// header, which is 8.
+	MaxBlockParents int32
+
 	// NOTE(cedric): Commented out to disable checkpoint-related code (JIRA DAG-3)
 	// https://soteria.atlassian.net/browse/DAG-3
 	//
@@ -258,6 +265,7 @@ var MainNetParams = Params{
 	ReduceMinDifficulty:      false,
 	MinDiffReductionTime:     0,
 	GenerateSupported:        false,
+	MaxBlockParents:          8,
 
 	// NOTE(cedric): Commented out to disable checkpoint-related code (JIRA DAG-3)
 	// https://soteria.atlassian.net/browse/DAG-3
@@ -356,6 +364,7 @@ var RegressionNetParams = Params{
 	ReduceMinDifficulty:      true,
 	MinDiffReductionTime:     time.Minute * 20, // TargetTimePerBlock * 2
 	GenerateSupported:        true,
+	MaxBlockParents:          8,
 
 	// NOTE(cedric): Commented out to disable checkpoint-related code (JIRA DAG-3)
 	// https://soteria.atlassian.net/browse/DAG-3
@@ -436,6 +445,7 @@ var TestNet1Params = Params{
 	ReduceMinDifficulty:      true,
 	MinDiffReductionTime:     time.Minute * 20, // TargetTimePerBlock * 2
 	GenerateSupported:        false,
+	MaxBlockParents:          8,
 
 	// NOTE(cedric): Commented out to disable checkpoint-related code (JIRA DAG-3)
 	// https://soteria.atlassian.net/browse/DAG-3
@@ -531,6 +541,7 @@ var SimNetParams = Params{
 	ReduceMinDifficulty:      true,
 	MinDiffReductionTime:     time.Minute * 20, // TargetTimePerBlock * 2
 	GenerateSupported:        true,
+	MaxBlockParents:          8,
 
 	// NOTE(cedric): Commented out to disable checkpoint-related code (JIRA DAG-3)
 	// https://soteria.atlassian.net/browse/DAG-3
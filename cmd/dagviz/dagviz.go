@@ -5,9 +5,11 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
-	"github.com/soteria-dag/soterd/soterutil"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -17,35 +19,269 @@ import (
 
 	"github.com/soteria-dag/soterd/chaincfg"
 	"github.com/soteria-dag/soterd/integration/rpctest"
+	"github.com/soteria-dag/soterd/rpcclient"
 )
 
-// save bytes to a file descriptor
-func saveHTML(bytes []byte, fh *os.File) error {
-	_, err := fh.Write(bytes)
+// tempFile creates the temporary file saveAtomic writes to.
+var tempFile = ioutil.TempFile
+
+// progress is where dagviz reports what it's doing.  It's discarded with
+// -json, so that the summary is the only output.
+var progress io.Writer = os.Stdout
+
+// runSummary is the summary of a run, which is emitted as a JSON object with
+// -json.
+type runSummary struct {
+	// Output is the file the dag was saved to.  When a file is saved per
+	// step, it's the file of the first step.
+	Output string `json:"output"`
+
+	// Miners is the number of nodes that were run, and Blocks the number
+	// of blocks they mined.  Both are 0 when replaying a dag.
+	Miners int `json:"miners"`
+	Blocks int `json:"blocks"`
+
+	// DagBlocks is the number of blocks in the final dag, including the
+	// genesis block, and Tips the number of tips of the final dag.
+	DagBlocks int `json:"dagblocks"`
+	Tips      int `json:"tips"`
+
+	// RenderSeconds is how long it took to render and save the dag.
+	RenderSeconds float64 `json:"renderseconds"`
+}
+
+// saveAtomic saves bytes to the named file, without leaving a partially
+// written file behind if the write fails.  The bytes are written to a
+// temporary file in the same directory, which is synced and closed before
+// it's renamed to replace the named file.  This way anything watching the
+// file, like a dashboard serving it, only ever sees a complete file.
+func saveAtomic(bytes []byte, name string) error {
+	fh, err := tempFile(filepath.Dir(name), "."+filepath.Base(name)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := fh.Name()
+
+	_, err = fh.Write(bytes)
+	if err == nil {
+		err = fh.Sync()
+	}
+	if closeErr := fh.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpName, 0644)
+	}
 	if err != nil {
+		_ = os.Remove(tmpName)
 		return err
 	}
 
-	err = fh.Close()
+	err = os.Rename(tmpName, name)
 	if err != nil {
+		_ = os.Remove(tmpName)
 		return err
 	}
 
 	return nil
 }
 
+//
+// mineForDuration has the miners mine for the given duration, taking snapshots of the dag at the
+// step interval (when non-zero) and once mining has stopped
+//
+func mineForDuration(miners []*rpctest.Harness, stepInterval int, runDuration int,
+	r *renderer, renderOpts *rpctest.RenderDagsDotOpts) ([][]byte, error) {
+
+	// Start mining on each miner
+	err := runOnMiners("start mining", len(miners), func(ctx context.Context, i int) error {
+		return miners[i].Node.SetGenerate(true, 1)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Recording the stepping process  
+	var stepDots [][]byte
+	stepCount := 0
+	
+	if (stepInterval != 0) {
+		// Stepping if specified
+		timeStart := time.Now() 
+		for {
+			fmt.Fprintln(progress, "Generating Step", stepCount)
+			// Take a snapshot of the dag to render in the output format
+			dot, err := r.snapshot(miners, renderOpts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to take %s snapshot of dag: %s", r.ext, err)
+			}
+			stepDots = append(stepDots, dot)
+			timeNow := time.Now()
+			timeDuration := time.Duration(runDuration) * time.Second
+			time.Sleep(time.Duration(stepInterval) * time.Millisecond)
+			fmt.Fprintf(progress, "Generating for %v\n", timeNow.Sub(timeStart))
+			if (timeNow.Sub(timeStart) > timeDuration) {
+				break
+			}
+			stepCount++
+		}
+	} else {
+		time.Sleep(time.Duration(runDuration) * time.Second)
+	}
+
+	// Stop mining on each node
+	for i, miner := range miners {
+		err := miner.Node.SetGenerate(false, 0)
+		if err != nil {
+			fmt.Fprintf(progress, "failed to stop miner on node %v: %v\n", i, err)
+		}
+	}
+
+	// Finalize the generation 
+	fmt.Fprintln(progress, "Finalizing")
+
+	// Take a snap shot of the final state
+	dot, err := r.snapshot(miners, renderOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take %s snapshot of dag: %s", r.ext, err)
+	}
+	stepDots = append(stepDots, dot)
+
+	return stepDots, nil
+}
+
+//
+// generateFrames has every miner generate the given number of blocks in rounds, taking a snapshot
+// of the dag after each round, so that the dag's growth can be shown as the given number of frames.
+// When blockInterval is non-zero, the blocks are spaced by it instead of being generated as fast as
+// possible.
+//
+func generateFrames(miners []*rpctest.Harness, frames int, blocks int, blockInterval time.Duration,
+	r *renderer, renderOpts *rpctest.RenderDagsDotOpts) ([][]byte, error) {
+
+	var pacer *blockPacer
+	if blockInterval > 0 {
+		pacer = &blockPacer{interval: blockInterval}
+	}
+
+	var frameDots [][]byte
+	for frame, count := range roundBlocks(blocks, frames) {
+		fmt.Fprintln(progress, "Generating Frame", frame)
+
+		err := generateRound(miners, count, pacer)
+		if err != nil {
+			return nil, err
+		}
+
+		// Take a snapshot of the dag to render in the output format
+		dot, err := r.snapshot(miners, renderOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to take %s snapshot of dag: %s", r.ext, err)
+		}
+		frameDots = append(frameDots, dot)
+	}
+
+	return frameDots, nil
+}
+
+// generateRound has each miner generate count blocks. Without a pacer the miners generate their
+// blocks concurrently, as fast as they can: generation is requested from every miner before any of
+// them is waited for, and the remaining waits are given up once generation fails on a miner. With a
+// pacer, the miners take turns generating a block at a time, spaced by the pacer's interval.
+func generateRound(miners []*rpctest.Harness, count int, pacer *blockPacer) error {
+	if pacer == nil {
+		futures := make([]rpcclient.FutureGenerateResult, len(miners))
+		for i, miner := range miners {
+			futures[i] = miner.Node.GenerateAsync(uint32(count))
+		}
+
+		return runOnMiners("generate blocks", len(miners), func(ctx context.Context, i int) error {
+			return waitForMiner(ctx, func() error {
+				_, err := futures[i].Receive()
+				return err
+			})
+		})
+	}
+
+	return pacer.generate(len(miners), count, func(i int) error {
+		_, err := miners[i].Node.Generate(1)
+		if err != nil {
+			return fmt.Errorf("unable to generate block on miner %d: %s", i, err)
+		}
+		return nil
+	})
+}
+
+// blockPacer spaces the generation of blocks by a target interval, so that the miners see each
+// other's blocks before mining on top of them, like the miners of a real network would. Without
+// it, the miners mine on the same tips and the dag is much wider than a real network's.
+type blockPacer struct {
+	interval time.Duration
+
+	// next is when the next block is due. It's zero until the first block is generated.
+	next time.Time
+}
+
+// wait blocks until the next block is due. When a block was generated late, the block after it is
+// due an interval later, so that late blocks aren't made up for with a burst of blocks.
+func (p *blockPacer) wait() {
+	now := time.Now()
+	if p.next.After(now) {
+		time.Sleep(p.next.Sub(now))
+		now = p.next
+	}
+	p.next = now.Add(p.interval)
+}
+
+// generate has the given number of miners take turns generating a block with gen, until each of
+// them has generated count blocks. Each block is generated once it's due.
+func (p *blockPacer) generate(miners int, count int, gen func(miner int) error) error {
+	for i := 0; i < miners*count; i++ {
+		p.wait()
+
+		err := gen(i % miners)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// roundBlocks splits the blocks each miner generates into the given number of rounds. Blocks that
+// don't divide evenly are generated in the first rounds.
+func roundBlocks(blocks int, rounds int) []int {
+	counts := make([]int, rounds)
+	for i := range counts {
+		counts[i] = blocks / rounds
+		if i < blocks % rounds {
+			counts[i]++
+		}
+	}
+
+	return counts
+}
+
 //
 // runNet runs a network of miners, generates some blocks on them, taking snapshots at an interval
-// and renders the dag as html at each interval
+// (or after each round of blocks, when frames is non-zero) and renders the dag at each snapshot
+// using the given renderer. When export is set, the final dag is also exported to it in JSON format.
+// The counts of the summary are those of the first miner's dag, which is the dag that's rendered.
+// blockInterval is the target time in milliseconds between the blocks generated for frames.
 //
 func runNet(minerCount int, blockTime int, 
 			timeSpan int, stepInterval int, 
-			runDuration int, 
-			output string, keepLogs bool) (string, error) {
+			runDuration int, frames int, blocks int, blockInterval int,
+			output string, export string, keepLogs bool, colorByMiner bool,
+			r *renderer) (*runSummary, error) {
 	
 	var miners []*rpctest.Harness
 	var err error
 
+	renderOpts := &rpctest.RenderDagsDotOpts{
+		ColorByMiner: colorByMiner,
+	}
+
 	extraArgs := []string{}
 
 	dagNetParams := chaincfg.SimNetParams
@@ -60,163 +296,237 @@ func runNet(minerCount int, blockTime int,
 		dagNetParams.TargetTimePerBlock = time.Millisecond * time.Duration(blockTime)
 	}
 
+	// NOTE(cedric): We'll call defer on a single anonymous function instead of minerCount times in the below loop
+	defer func() {
+		for _, miner := range miners {
+			_ = (*miner).TearDown()
+		}
+	}()
+
 	// Spawn miners
 	for i := 0; i < minerCount; i++ {
 		miner, err := rpctest.New(&dagNetParams, nil, extraArgs, keepLogs)
 		if err != nil {
-			return "", fmt.Errorf("unable to create mining node %d: %s", i, err)
-		}
-
-		if err := miner.SetUp(false, 0); err != nil {
-			return "", fmt.Errorf("unable to complete mining node %d setup: %s", i, err)
+			return nil, fmt.Errorf("unable to create mining node %d: %s", i, err)
 		}
 
 		if keepLogs {
-			fmt.Printf("miner %d log dir: %s\n", i, miner.LogDir())
+			fmt.Fprintf(progress, "miner %d log dir: %s\n", i, miner.LogDir())
 		}
 
 		miners = append(miners, miner)
 	}
 
-	// NOTE(cedric): We'll call defer on a single anonymous function instead of minerCount times in the above loop
-	defer func() {
-		for _, miner := range miners {
-			_ = (*miner).TearDown()
-		}
-	}()
+	// Start the miners' nodes
+	err = runOnMiners("complete mining node setup", len(miners), func(ctx context.Context, i int) error {
+		return miners[i].SetUp(false, 0)
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	// Connect the nodes to one another
 	err = rpctest.ConnectNodes(miners)
 	if err != nil {
-		return "", fmt.Errorf("unable to connect nodes: %s", err)
+		return nil, fmt.Errorf("unable to connect nodes: %s", err)
 	}
 
-
-	// Start mining on each miner
-	for i, miner := range miners {
-		err := miner.Node.SetGenerate(true, 1)
-		if err != nil {
-			fmt.Printf("failed to start miner on node %v: %v\n", i, err)
-		}
+	startInfo, err := miners[0].Node.GetDagInfo()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get dag info: %s", err)
 	}
 
-	// Recording the stepping process  
+
 	var stepDots [][]byte
-	stepCount := 0
-	
-	if (stepInterval != 0) {
-		// Stepping if specified
-		timeStart := time.Now() 
-		for {
-			fmt.Println("Generating Step", stepCount)
-			// Render the dag in graphviz DOT file format
-			dot, err := rpctest.RenderDagsDot(miners)
-			if err != nil {
-				return "", fmt.Errorf("failed to render dag in graphviz DOT format: %s", err)
-			}
-			stepDots = append(stepDots, dot)
-			timeNow := time.Now()
-			timeDuration := time.Duration(runDuration) * time.Second
-			time.Sleep(time.Duration(stepInterval) * time.Millisecond)
-			fmt.Printf("Generating for %v\n", timeNow.Sub(timeStart))
-			if (timeNow.Sub(timeStart) > timeDuration) {
-				break
-			}
-			stepCount++
-		}
+	if frames > 0 {
+		stepDots, err = generateFrames(miners, frames, blocks,
+			time.Duration(blockInterval)*time.Millisecond, r, renderOpts)
 	} else {
-		time.Sleep(time.Duration(runDuration) * time.Second)
+		stepDots, err = mineForDuration(miners, stepInterval, runDuration, r, renderOpts)
 	}
-
-	// Stop mining on each node
-	for i, miner := range miners {
-		err := miner.Node.SetGenerate(false, 0)
-		if err != nil {
-			fmt.Printf("failed to stop miner on node %v: %v\n", i, err)
-		}
+	if err != nil {
+		return nil, err
 	}
 
-	// Finalize the generation 
-	fmt.Println("Finalizing")
-
-	// Take a snap shot of the final state
-	dot, err := rpctest.RenderDagsDot(miners)
+	endInfo, err := miners[0].Node.GetDagInfo()
 	if err != nil {
-		return "", fmt.Errorf("failed to render dag in graphviz DOT format: %s", err)
+		return nil, fmt.Errorf("unable to get dag info: %s", err)
+	}
+	summary := &runSummary{
+		Miners:    minerCount,
+		Blocks:    int(endInfo.BlkCount - startInfo.BlkCount),
+		DagBlocks: int(endInfo.BlkCount),
+		Tips:      endInfo.TipCount,
 	}
-	stepDots = append(stepDots, dot)
 
-	// Determine where we will save the dag steps
-	var outDir string
+	// Export the final state of the dag, which is gathered the same way as the rendered dag
+	if len(export) > 0 {
+		exportJSON, err := rpctest.ExportDagJSON(miners)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export dag in JSON format: %s", err)
+		}
 
-	if len(output) == 0 {
-		// Create a temporary dir as output path
-		outDir, err = ioutil.TempDir("", "dagviz")
-	} else {
-		info, pathErr := os.Stat(output)
-		if pathErr == nil && info.IsDir() {
-			// output path already exists
-			outDir = output
-		} else {
-			// Create the output path
-			err = os.MkdirAll(output, 0755)
+		err = saveAtomic(exportJSON, export)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save dag export: %s", err)
 		}
+		fmt.Fprintln(progress, "Exported dag to", export)
 	}
+
+	// Determine where we will save the dag steps
+	renderStart := time.Now()
+	outDir, err := outputDir(output)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// Start the rendering process 
-	for step := 0; step <= stepCount; step++ {
-
-		fmt.Println("Rendering Step", step)
-
-		// Render the dag in graphviz DOT file format
-		dot := stepDots[step]
+	// Frames are rendered into a single file, when the output format supports it
+	if frames > 0 && r.frames != nil {
+		fmt.Fprintln(progress, "Rendering", len(stepDots), "Frames")
 
-		// Convert DOT file contents to an SVG image
-		svg, err := soterutil.DotToSvg(dot)
+		contents, err := r.frames(stepDots)
 		if err != nil {
-			return "", fmt.Errorf("failed to convert DOT file to SVG: %s", err)
+			return nil, err
 		}
-		
-		// We're going to embed the SVG image in HTML, so strip out the xml declaration
-		svgEmbed, err := soterutil.StripSvgXmlDecl(svg)
+
+		name := filepath.Join(outDir, "dag." + r.ext)
+		err = saveAtomic(contents, name)
 		if err != nil {
-			return "", fmt.Errorf("failed to strip xml declaration from SVG image: %s", err)
+			return nil, fmt.Errorf("failed to save %s file: %s", r.ext, err)
 		}
-		
-		svgBody, err := soterutil.RenderSvgHTMLFigure(svgEmbed)
 
-		// Render the dag in an HTML document
-		h, err := soterutil.RenderSteppingHTML(svgBody, "dag", step-1, step+1)
+		summary.Output = name
+		summary.RenderSeconds = time.Since(renderStart).Seconds()
+		return summary, nil
+	}
+
+	// Start the rendering process 
+	for step, dot := range stepDots {
+
+		fmt.Fprintln(progress, "Rendering Step", step)
+
+		// Render the dag step in the output format
+		contents, err := r.render(dot, step)
 		if err != nil {
-			return "", fmt.Errorf("failed to render SVG image as HTML: %s", err)
+			return nil, err
 		}
 
-		pattern := "dag_" + strconv.Itoa(step) + ".html"
+		pattern := "dag_" + strconv.Itoa(step) + "." + r.ext
 		name := filepath.Join(outDir, pattern)
-		fh, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+
+		// Save the rendered dag
+		err = saveAtomic(contents, name)
 		if err != nil {
-			return "", fmt.Errorf("failed to create output file-handle: %s", err)
+			return nil, fmt.Errorf("failed to save %s file: %s", r.ext, err)
 		}
+	}
 
-		// Save the HTML document
-		err = saveHTML(h, fh)
-		if err != nil {
-			return "", fmt.Errorf("failed to save HTML file: %s", err)
+	summary.Output = filepath.Join(outDir, "dag_0." + r.ext)
+	summary.RenderSeconds = time.Since(renderStart).Seconds()
+	return summary, nil
+}
+
+//
+// outputDir returns the directory to save the rendered dag in, creating it if it doesn't already exist.
+// When output is empty, a temporary directory is created.
+//
+func outputDir(output string) (string, error) {
+	if len(output) == 0 {
+		// Create a temporary dir as output path
+		return ioutil.TempDir("", "dagviz")
+	}
+
+	info, err := os.Stat(output)
+	if err == nil && info.IsDir() {
+		// output path already exists
+		return output, nil
+	}
+
+	// Create the output path
+	err = os.MkdirAll(output, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	return output, nil
+}
+
+//
+// replayDag renders a dag that was exported with -export, without running any nodes. The dag is
+// rendered the same way as the final snapshot of the run that exported it.
+//
+func replayDag(replay string, output string, colorByMiner bool, r *renderer) (*runSummary, error) {
+	exportJSON, err := ioutil.ReadFile(replay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dag export: %s", err)
+	}
+
+	renderOpts := &rpctest.RenderDagsDotOpts{
+		ColorByMiner: colorByMiner,
+	}
+
+	// Render the dag in the representation the output format converts
+	renderStart := time.Now()
+	dot, err := r.replay(exportJSON, renderOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render dag export %s: %s", replay, err)
+	}
+
+	outDir, err := outputDir(output)
+	if err != nil {
+		return nil, err
+	}
+
+	// Render the dag in the output format
+	contents, err := r.render(dot, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Join(outDir, "dag_0." + r.ext)
+	err = saveAtomic(contents, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save %s file: %s", r.ext, err)
+	}
+
+	// The export was validated while rendering it, so it's only decoded
+	// again to count its blocks and tips.
+	var export rpctest.DagExport
+	err = json.Unmarshal(exportJSON, &export)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dag export: %s", err)
+	}
+	isParent := make(map[string]bool)
+	for _, edge := range export.Edges {
+		isParent[edge.To] = true
+	}
+	summary := &runSummary{
+		Output:        name,
+		DagBlocks:     len(export.Blocks),
+		RenderSeconds: time.Since(renderStart).Seconds(),
+	}
+	for _, block := range export.Blocks {
+		if !isParent[block.Hash] {
+			summary.Tips++
 		}
 	}
 
-	return outDir + "/dag_0.html", nil
+	return summary, nil
 }
 
-func main() {
+//
+// run runs dagviz with the given command line arguments, and returns its exit status. Progress and
+// errors are written to stdout, unless -json is given, in which case only the summary of the run is
+// written to stdout, as a JSON object, and errors are written to stderr.
+//
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
 	var err error
-	var htmlFile string 
+	var summary *runSummary
 
 	var stepping bool
 	var output string
+	var export string
+	var replay string
 	var nodeCount int
 
 	var runDuration int
@@ -225,45 +535,132 @@ func main() {
 
 	var stepInterval int
 
+	var frames int
+	var blocks int
+	var blockInterval int
+
 	var keepLogs bool
+	var colorByMiner bool
 
+	var format string
+	var svgStrip bool
+
+	var jsonSummary bool
 
 	// parsing the command line parameters
-	flag.StringVar(&output, "output", "", "Where to save the rendered dag")
-	flag.BoolVar(&stepping, "stepping", false, "Generating Stepping Results")
+	flags := flag.NewFlagSet("dagviz", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	flags.StringVar(&output, "output", "", "Where to save the rendered dag")
+	flags.StringVar(&export, "export", "", "File to export the final dag to in JSON format, as blocks and the edges to their parents")
+	flags.StringVar(&replay, "replay", "", "File with a dag exported by -export to render, instead of running nodes")
+	flags.BoolVar(&stepping, "stepping", false, "Generating Stepping Results")
+
+	flags.IntVar(&nodeCount, "nodes", 4, "Number of Nodes")
+	flags.IntVar(&runDuration, "duration", 20, "Duration of the Run in seconds")
+	flags.IntVar(&stepInterval, "interval", 100, "Interval in milliseconds between each step")
+
+	flags.IntVar(&frames, "frames", 0, "Number of frames to render while each node generates -blocks blocks, instead of mining for -duration")
+	flags.IntVar(&blocks, "blocks", 50, "Number of blocks each node generates when rendering -frames")
+	flags.IntVar(&blockInterval, "blockinterval", 0, "Target time in milliseconds between blocks generated by the nodes when rendering -frames, or 0 to generate them as fast as possible")
 
-	flag.IntVar(&nodeCount, "nodes", 4, "Number of Nodes")
-	flag.IntVar(&runDuration, "duration", 20, "Duration of the Run in seconds")
-	flag.IntVar(&stepInterval, "interval", 100, "Interval in milliseconds between each step")
+	flags.IntVar(&blockTime, "blocktime", 0, "Changing Mining Block Time in milliseconds")
+	flags.IntVar(&timeSpan, "timespan", 0, "Changing Mining Time Span in seconds")
 
-	flag.IntVar(&blockTime, "blocktime", 0, "Changing Mining Block Time in milliseconds")
-	flag.IntVar(&timeSpan, "timespan", 0, "Changing Mining Time Span in seconds")
+	flags.BoolVar(&keepLogs, "l", false, "Keep logs from soterd nodes")
+	flags.BoolVar(&colorByMiner, "color", true, "Color blocks by the miner that produced them")
 
-	flag.BoolVar(&keepLogs, "l", false, "Keep logs from soterd nodes")
+	flags.StringVar(&format, "format", formatHTML, "Output format of the rendered dag: html, svg, dot, graphml or ascii")
+	flags.BoolVar(&svgStrip, "svgstrip", false, "Strip the xml declaration from svg output, for embedding in other documents")
+
+	flags.BoolVar(&jsonSummary, "json", false, "Only output a summary of the run as a JSON object, instead of progress")
+
+	err = flags.Parse(args)
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if err != nil {
+		return 2
+	}
 
-	flag.Parse()
+	progress = stdout
+	errOutput := stdout
+	if jsonSummary {
+		progress = ioutil.Discard
+		errOutput = stderr
+	}
 
 	// validate params
 	if ((blockTime != 0 || timeSpan != 0) && blockTime > (timeSpan * 1000)) {
-		fmt.Println("Invalid parameters: -blocktime can not be greater than -timespan.")
-		syscall.Exit(1)
+		fmt.Fprintln(errOutput, "Invalid parameters: -blocktime can not be greater than -timespan.")
+		return 1
+	}
+
+	if (frames < 0 || (frames > 0 && (blocks < frames || stepping))) {
+		fmt.Fprintln(errOutput, "Invalid parameters: -frames needs at least as many -blocks as frames, and can't be used with -stepping.")
+		return 1
 	}
 
-	// everything seems alright. Let's run
-	fmt.Printf("Generating dag with %d nodes for %d seconds\n", nodeCount, runDuration)
-	fmt.Printf("Node Profile: block time %d msec, time span %d sec\n", blockTime, timeSpan)
+	if blockInterval < 0 || (blockInterval > 0 && frames == 0) {
+		fmt.Fprintln(errOutput, "Invalid parameters: -blockinterval can't be negative, and can only be used with -frames.")
+		return 1
+	}
+
+	r, err := newRenderer(format, svgStrip)
+	if err != nil {
+		fmt.Fprintln(errOutput, "Invalid parameters:", err)
+		return 1
+	}
 
-	if (stepping) {
-		fmt.Printf("Taking snapshots for %d seconds with %d msec interval\n", runDuration, stepInterval)
-		htmlFile, err = runNet(nodeCount, blockTime, timeSpan, stepInterval, runDuration, output, keepLogs)
+	if len(replay) > 0 && r.replay == nil {
+		fmt.Fprintln(errOutput, "Invalid parameters: -replay can't be used with -format graphml, since exports don't have block timestamps.")
+		return 1
+	}
+
+	if len(replay) > 0 {
+		fmt.Fprintln(progress, "Replaying dag from", replay)
+		summary, err = replayDag(replay, output, colorByMiner, r)
 	} else {
-		htmlFile, err = runNet(nodeCount, blockTime, timeSpan, 0, runDuration, output, keepLogs)
+		// everything seems alright. Let's run
+		if (frames > 0) {
+			fmt.Fprintf(progress, "Generating dag with %d nodes for %d blocks each\n", nodeCount, blocks)
+		} else {
+			fmt.Fprintf(progress, "Generating dag with %d nodes for %d seconds\n", nodeCount, runDuration)
+		}
+		fmt.Fprintf(progress, "Node Profile: block time %d msec, time span %d sec\n", blockTime, timeSpan)
+
+		if (frames > 0) {
+			fmt.Fprintf(progress, "Taking %d snapshots\n", frames)
+			if blockInterval > 0 {
+				fmt.Fprintf(progress, "Generating a block every %d msec\n", blockInterval)
+			}
+			summary, err = runNet(nodeCount, blockTime, timeSpan, 0, runDuration, frames, blocks, blockInterval, output, export, keepLogs, colorByMiner, r)
+		} else if (stepping) {
+			fmt.Fprintf(progress, "Taking snapshots for %d seconds with %d msec interval\n", runDuration, stepInterval)
+			summary, err = runNet(nodeCount, blockTime, timeSpan, stepInterval, runDuration, 0, 0, 0, output, export, keepLogs, colorByMiner, r)
+		} else {
+			summary, err = runNet(nodeCount, blockTime, timeSpan, 0, runDuration, 0, 0, 0, output, export, keepLogs, colorByMiner, r)
+		}
 	}
 
 	if err != nil {
-		fmt.Println(err)
-		syscall.Exit(1)
+		fmt.Fprintln(errOutput, err)
+		return 1
 	}
 
-	fmt.Println("Saved dag to", htmlFile)
+	if jsonSummary {
+		err = json.NewEncoder(stdout).Encode(summary)
+		if err != nil {
+			fmt.Fprintln(errOutput, "failed to write summary:", err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintln(progress, "Saved dag to", summary.Output)
+	return 0
+}
+
+func main() {
+	syscall.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
 }
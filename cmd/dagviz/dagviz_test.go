@@ -0,0 +1,336 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/soteria-dag/soterd/soterutil"
+)
+
+// TestSaveAtomic tests that saveAtomic replaces the contents of a file, and
+// leaves the original file untouched when the write fails.
+func TestSaveAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dagviz")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "dag_0.html")
+	original := []byte("original")
+	if err := ioutil.WriteFile(name, original, 0644); err != nil {
+		t.Fatalf("unable to write original file: %v", err)
+	}
+
+	// Writes to a read-only temp file fail, simulating a failed write.
+	defer func() { tempFile = ioutil.TempFile }()
+	tempFile = func(dir, pattern string) (*os.File, error) {
+		fh, err := ioutil.TempFile(dir, pattern)
+		if err != nil {
+			return nil, err
+		}
+		fh.Close()
+		return os.Open(fh.Name())
+	}
+
+	if err := saveAtomic([]byte("replacement"), name); err == nil {
+		t.Fatalf("saveAtomic succeeded with a failing write")
+	}
+
+	contents, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("unable to read original file: %v", err)
+	}
+	if !bytes.Equal(contents, original) {
+		t.Fatalf("original file was modified by failed save: got %q, "+
+			"want %q", contents, original)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unable to read temp dir: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("failed save left %d files behind, want 1", len(files))
+	}
+
+	// A successful save replaces the file.
+	tempFile = ioutil.TempFile
+	replacement := []byte("replacement")
+	if err := saveAtomic(replacement, name); err != nil {
+		t.Fatalf("saveAtomic failed: %v", err)
+	}
+
+	contents, err = ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("unable to read saved file: %v", err)
+	}
+	if !bytes.Equal(contents, replacement) {
+		t.Fatalf("saved file has unexpected contents: got %q, want %q",
+			contents, replacement)
+	}
+}
+
+// TestRoundBlocks tests that the blocks generated for frames are split into
+// one round per frame.
+func TestRoundBlocks(t *testing.T) {
+	tests := []struct {
+		blocks int
+		frames int
+		want   []int
+	}{
+		{50, 1, []int{50}},
+		{50, 5, []int{10, 10, 10, 10, 10}},
+		{50, 4, []int{13, 13, 12, 12}},
+		{3, 3, []int{1, 1, 1}},
+	}
+
+	for i, test := range tests {
+		got := roundBlocks(test.blocks, test.frames)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("roundBlocks #%d: got %v, want %v", i, got, test.want)
+		}
+	}
+}
+
+// TestBlockPacer tests that a pacer has the miners take turns generating
+// blocks, and spaces the blocks by its interval.
+func TestBlockPacer(t *testing.T) {
+	const miners = 3
+	const count = 2
+	const interval = 20 * time.Millisecond
+
+	var got []int
+	pacer := &blockPacer{interval: interval}
+	start := time.Now()
+	err := pacer.generate(miners, count, func(miner int) error {
+		got = append(got, miner)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+
+	// The first block is generated right away, and each block after it an
+	// interval later.
+	elapsed := time.Since(start)
+	if want := (miners*count - 1) * interval; elapsed < want {
+		t.Fatalf("generated %d blocks in %v, want at least %v",
+			miners*count, elapsed, want)
+	}
+	if want := []int{0, 1, 2, 0, 1, 2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("blocks were generated by miners %v, want %v", got, want)
+	}
+
+	// Generating stops at the first failure.
+	got = nil
+	err = pacer.generate(miners, count, func(miner int) error {
+		got = append(got, miner)
+		if miner == 1 {
+			return errors.New("generate failed")
+		}
+		return nil
+	})
+	if err == nil || len(got) != 2 {
+		t.Fatalf("generate returned %v after generating %d blocks, want "+
+			"an error after 2", err, len(got))
+	}
+}
+
+// TestRenderHTMLFrames tests that every frame is rendered into the html
+// output.
+func TestRenderHTMLFrames(t *testing.T) {
+	if _, found := soterutil.Which("dot"); !found {
+		t.Skip("graphviz dot command not found")
+	}
+
+	const numFrames = 3
+	dot := []byte("digraph dag {\n\"a\" -> \"b\";\n}\n")
+	dots := make([][]byte, numFrames)
+	for i := range dots {
+		dots[i] = dot
+	}
+
+	h, err := renderHTMLFrames(dots)
+	if err != nil {
+		t.Fatalf("renderHTMLFrames failed: %v", err)
+	}
+
+	if n := bytes.Count(h, []byte(`class="frame"`)); n != numFrames {
+		t.Fatalf("renderHTMLFrames rendered %d frames, want %d", n,
+			numFrames)
+	}
+}
+
+// TestReplayDag tests that a dag exported to JSON is rendered again from the
+// export, and that an export referencing unknown blocks is rejected.
+func TestReplayDag(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dagviz")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	const genesis = "0000000000000000000000000000000000000000000000000000000000000001"
+	const child = "0000000000000000000000000000000000000000000000000000000000000002"
+	export := `{"blocks":[` +
+		`{"hash":"` + genesis + `","height":0,"parents":[],"miner":0,"isblue":true},` +
+		`{"hash":"` + child + `","height":1,"parents":["` + genesis + `"],"miner":-1,"isblue":false}],` +
+		`"edges":[{"from":"` + child + `","to":"` + genesis + `"}]}`
+	exportFile := filepath.Join(dir, "dag.json")
+	if err := ioutil.WriteFile(exportFile, []byte(export), 0644); err != nil {
+		t.Fatalf("unable to write export: %v", err)
+	}
+
+	r, err := newRenderer(formatDOT, false)
+	if err != nil {
+		t.Fatalf("newRenderer failed: %v", err)
+	}
+
+	output := filepath.Join(dir, "out")
+	summary, err := replayDag(exportFile, output, false, r)
+	if err != nil {
+		t.Fatalf("replayDag failed: %v", err)
+	}
+	if summary.Output != filepath.Join(output, "dag_0.dot") {
+		t.Fatalf("replayDag saved dag to %s, want %s", summary.Output,
+			filepath.Join(output, "dag_0.dot"))
+	}
+
+	dot, err := ioutil.ReadFile(summary.Output)
+	if err != nil {
+		t.Fatalf("unable to read replayed dag: %v", err)
+	}
+	if !bytes.Contains(dot, []byte("n1 -> n0;")) {
+		t.Fatalf("replayed dag is missing the edge to the parent:\n%s", dot)
+	}
+
+	// The ascii format draws a node glyph for each block, and an edge
+	// connector for the parent link.
+	asciiRenderer, err := newRenderer(formatASCII, false)
+	if err != nil {
+		t.Fatalf("newRenderer failed: %v", err)
+	}
+	summary, err = replayDag(exportFile, output, false, asciiRenderer)
+	if err != nil {
+		t.Fatalf("replayDag failed: %v", err)
+	}
+	text, err := ioutil.ReadFile(summary.Output)
+	if err != nil {
+		t.Fatalf("unable to read replayed dag: %v", err)
+	}
+	if bytes.Count(text, []byte("*")) != 2 ||
+		bytes.Count(text, []byte("+")) != 1 {
+		t.Fatalf("replayed dag should have 2 blocks and 1 parent link:\n%s",
+			text)
+	}
+
+	// An export with a parent that isn't in the dag is rejected.
+	bad := filepath.Join(dir, "bad.json")
+	badExport := `{"blocks":[{"hash":"` + child + `","height":1,"parents":["` +
+		genesis + `"],"miner":-1,"isblue":false}],"edges":[{"from":"` +
+		child + `","to":"` + genesis + `"}]}`
+	if err := ioutil.WriteFile(bad, []byte(badExport), 0644); err != nil {
+		t.Fatalf("unable to write export: %v", err)
+	}
+	if _, err := replayDag(bad, output, false, r); err == nil {
+		t.Fatalf("replayDag succeeded with an unknown parent")
+	}
+}
+
+// TestRunJSON tests that -json replaces the progress of a run with a JSON
+// summary of it.
+func TestRunJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dagviz")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	defer func() { progress = os.Stdout }()
+
+	// The genesis block has two children, which are the tips of the dag.
+	const genesis = "0000000000000000000000000000000000000000000000000000000000000001"
+	const child1 = "0000000000000000000000000000000000000000000000000000000000000002"
+	const child2 = "0000000000000000000000000000000000000000000000000000000000000003"
+	export := `{"blocks":[` +
+		`{"hash":"` + genesis + `","height":0,"parents":[],"miner":0,"isblue":true},` +
+		`{"hash":"` + child1 + `","height":1,"parents":["` + genesis + `"],"miner":0,"isblue":true},` +
+		`{"hash":"` + child2 + `","height":1,"parents":["` + genesis + `"],"miner":1,"isblue":true}],` +
+		`"edges":[{"from":"` + child1 + `","to":"` + genesis + `"},` +
+		`{"from":"` + child2 + `","to":"` + genesis + `"}]}`
+	exportFile := filepath.Join(dir, "dag.json")
+	if err := ioutil.WriteFile(exportFile, []byte(export), 0644); err != nil {
+		t.Fatalf("unable to write export: %v", err)
+	}
+
+	output := filepath.Join(dir, "out")
+	args := []string{"-replay", exportFile, "-format", formatDOT, "-output",
+		output}
+
+	var stdout, stderr bytes.Buffer
+	if status := run(args, &stdout, &stderr); status != 0 {
+		t.Fatalf("run exited with status %d: %s", status, stderr.String())
+	}
+	if !bytes.Contains(stdout.Bytes(), []byte("Saved dag to")) {
+		t.Fatalf("run didn't report where the dag was saved: %q",
+			stdout.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if status := run(append(args, "-json"), &stdout, &stderr); status != 0 {
+		t.Fatalf("run -json exited with status %d: %s", status,
+			stderr.String())
+	}
+
+	// The output is only the summary, so it decodes as a single object.
+	var summary runSummary
+	decoder := json.NewDecoder(&stdout)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&summary); err != nil {
+		t.Fatalf("run -json output isn't a summary: %v", err)
+	}
+	if decoder.More() {
+		t.Fatalf("run -json wrote more than the summary")
+	}
+
+	want := runSummary{
+		Output:    filepath.Join(output, "dag_0.dot"),
+		DagBlocks: 3,
+		Tips:      2,
+	}
+	if summary.RenderSeconds < 0 {
+		t.Fatalf("summary has negative render duration %v",
+			summary.RenderSeconds)
+	}
+	summary.RenderSeconds = 0
+	if summary != want {
+		t.Fatalf("got summary %+v, want %+v", summary, want)
+	}
+	if _, err := os.Stat(summary.Output); err != nil {
+		t.Fatalf("summary output %s wasn't saved: %v", summary.Output, err)
+	}
+
+	// Errors aren't mixed into the summary output.
+	stdout.Reset()
+	stderr.Reset()
+	args = []string{"-json", "-replay", filepath.Join(dir, "missing.json")}
+	if status := run(args, &stdout, &stderr); status == 0 {
+		t.Fatalf("run -json succeeded with a missing export")
+	}
+	if stdout.Len() != 0 || stderr.Len() == 0 {
+		t.Fatalf("run -json wrote error %q to stdout and %q to stderr",
+			stdout.String(), stderr.String())
+	}
+}
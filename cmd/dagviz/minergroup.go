@@ -0,0 +1,141 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// errMinerCanceled is the status of a miner whose operation wasn't started, or
+// wasn't waited for, because the operation had already failed on another
+// miner.
+var errMinerCanceled = errors.New("canceled after failure on another miner")
+
+// minerStatus is the outcome of an operation on a single miner.
+type minerStatus struct {
+	// Miner is the index of the miner in the network.
+	Miner int
+
+	// Err is nil if the operation succeeded on the miner.
+	Err error
+}
+
+// minersError is returned when an operation fails on one or more miners of
+// the network. It records the status of the operation on every miner, so
+// that partial success isn't lost.
+type minersError struct {
+	// Op describes the operation that was run on the miners.
+	Op string
+
+	// Statuses holds the status of the operation for each miner, ordered
+	// by miner index.
+	Statuses []minerStatus
+}
+
+// Succeeded returns the indexes of the miners the operation succeeded on.
+func (e *minersError) Succeeded() []int {
+	var miners []int
+	for _, s := range e.Statuses {
+		if s.Err == nil {
+			miners = append(miners, s.Miner)
+		}
+	}
+
+	return miners
+}
+
+// Failed returns the statuses of the miners the operation didn't succeed on.
+func (e *minersError) Failed() []minerStatus {
+	var failed []minerStatus
+	for _, s := range e.Statuses {
+		if s.Err != nil {
+			failed = append(failed, s)
+		}
+	}
+
+	return failed
+}
+
+// Error satisfies the error interface and lists the status of each miner.
+func (e *minersError) Error() string {
+	statuses := make([]string, 0, len(e.Statuses))
+	for _, s := range e.Statuses {
+		if s.Err == nil {
+			statuses = append(statuses, fmt.Sprintf("miner %d: ok", s.Miner))
+		} else {
+			statuses = append(statuses, fmt.Sprintf("miner %d: %s", s.Miner, s.Err))
+		}
+	}
+
+	return fmt.Sprintf("unable to %s on %d of %d miners (%s)", e.Op,
+		len(e.Failed()), len(e.Statuses), strings.Join(statuses, "; "))
+}
+
+// runOnMiners runs fn on each of the given number of miners concurrently,
+// and waits for all of them to return. The context passed to fn is canceled
+// once fn fails on a miner, so that miners still running fn can give up
+// waiting, and fn isn't started on miners that haven't started it yet.
+//
+// A *minersError describing the status of each miner is returned if fn
+// failed on any of them.
+func runOnMiners(op string, miners int, fn func(ctx context.Context, miner int) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	statuses := make([]minerStatus, miners)
+
+	for i := 0; i < miners; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			statuses[i].Miner = i
+
+			if ctx.Err() != nil {
+				statuses[i].Err = errMinerCanceled
+				return
+			}
+
+			err := fn(ctx, i)
+			if err != nil {
+				statuses[i].Err = err
+				cancel()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, s := range statuses {
+		if s.Err != nil {
+			return &minersError{Op: op, Statuses: statuses}
+		}
+	}
+
+	return nil
+}
+
+// waitForMiner waits for receive to return the result of an operation on a
+// miner, giving up with errMinerCanceled once ctx is canceled. The operation
+// isn't aborted when the wait is given up; receive is left to return once it
+// completes, or once the miner is torn down.
+func waitForMiner(ctx context.Context, receive func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- receive()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errMinerCanceled
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestMinersError tests that minersError reports which miners an operation
+// succeeded and failed on.
+func TestMinersError(t *testing.T) {
+	errGenerate := errors.New("generate failed")
+	e := &minersError{
+		Op: "generate blocks",
+		Statuses: []minerStatus{
+			{Miner: 0},
+			{Miner: 1, Err: errGenerate},
+			{Miner: 2},
+			{Miner: 3, Err: errMinerCanceled},
+		},
+	}
+
+	wantSucceeded := []int{0, 2}
+	if got := e.Succeeded(); !reflect.DeepEqual(got, wantSucceeded) {
+		t.Errorf("Succeeded: got %v, want %v", got, wantSucceeded)
+	}
+
+	wantFailed := []minerStatus{
+		{Miner: 1, Err: errGenerate},
+		{Miner: 3, Err: errMinerCanceled},
+	}
+	if got := e.Failed(); !reflect.DeepEqual(got, wantFailed) {
+		t.Errorf("Failed: got %v, want %v", got, wantFailed)
+	}
+
+	wantErr := "unable to generate blocks on 2 of 4 miners (miner 0: ok; " +
+		"miner 1: generate failed; miner 2: ok; miner 3: canceled " +
+		"after failure on another miner)"
+	if got := e.Error(); got != wantErr {
+		t.Errorf("Error: got %q, want %q", got, wantErr)
+	}
+
+	// Without failures there's nothing to list as failed.
+	ok := &minersError{Op: "start mining", Statuses: []minerStatus{{Miner: 0}}}
+	if got := ok.Failed(); got != nil {
+		t.Errorf("Failed with no failures: got %v, want nil", got)
+	}
+}
+
+// TestRunOnMiners tests that runOnMiners returns the status of every miner,
+// and that the waits still running when a miner fails are canceled.
+func TestRunOnMiners(t *testing.T) {
+	err := runOnMiners("start mining", 3, func(ctx context.Context, miner int) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("runOnMiners with no failures: unexpected error %v", err)
+	}
+
+	// Miner 1 fails, while the others wait on a result that never arrives
+	// until their wait is canceled.
+	errGenerate := errors.New("generate failed")
+	never := make(chan struct{})
+	defer close(never)
+	err = runOnMiners("generate blocks", 3, func(ctx context.Context, miner int) error {
+		if miner == 1 {
+			return errGenerate
+		}
+		return waitForMiner(ctx, func() error {
+			<-never
+			return nil
+		})
+	})
+
+	mErr, ok := err.(*minersError)
+	if !ok {
+		t.Fatalf("runOnMiners with a failure: got error %v (%T), want "+
+			"*minersError", err, err)
+	}
+	if mErr.Op != "generate blocks" {
+		t.Errorf("Op: got %q, want %q", mErr.Op, "generate blocks")
+	}
+
+	want := []minerStatus{
+		{Miner: 0, Err: errMinerCanceled},
+		{Miner: 1, Err: errGenerate},
+		{Miner: 2, Err: errMinerCanceled},
+	}
+	if !reflect.DeepEqual(mErr.Statuses, want) {
+		t.Errorf("Statuses: got %v, want %v", mErr.Statuses, want)
+	}
+}
+
+// TestWaitForMiner tests that waitForMiner returns the result it waits for,
+// and gives up once its context is canceled.
+func TestWaitForMiner(t *testing.T) {
+	errReceive := errors.New("receive failed")
+	err := waitForMiner(context.Background(), func() error {
+		return errReceive
+	})
+	if err != errReceive {
+		t.Errorf("waitForMiner: got error %v, want %v", err, errReceive)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	never := make(chan struct{})
+	defer close(never)
+	err = waitForMiner(ctx, func() error {
+		<-never
+		return nil
+	})
+	if err != errMinerCanceled {
+		t.Errorf("waitForMiner with canceled context: got error %v, "+
+			"want %v", err, errMinerCanceled)
+	}
+}
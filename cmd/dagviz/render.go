@@ -0,0 +1,203 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/soteria-dag/soterd/integration/rpctest"
+	"github.com/soteria-dag/soterd/soterutil"
+)
+
+const (
+	// formatHTML renders each step as an HTML document with links to the
+	// previous and next steps.
+	formatHTML = "html"
+
+	// formatSVG renders each step as an SVG image.
+	formatSVG = "svg"
+
+	// formatDOT saves each step in graphviz DOT file format, as-is.
+	formatDOT = "dot"
+
+	// formatGraphML saves each step in GraphML format, with the metadata
+	// of the blocks as node attributes.
+	formatGraphML = "graphml"
+
+	// formatASCII saves each step as text, drawn like git log --graph, for
+	// viewing in a terminal.
+	formatASCII = "ascii"
+)
+
+// renderer converts a snapshot of a dag step into the contents of the file
+// saved for that step.
+type renderer struct {
+	// ext is the file extension used for the rendered files.
+	ext string
+
+	// snapshot returns the representation of the miners' dag that render
+	// converts.  It's the graphviz DOT representation, except for formats
+	// that can't be rendered from it.
+	snapshot func(miners []*rpctest.Harness, opts *rpctest.RenderDagsDotOpts) ([]byte, error)
+
+	// replay returns the representation that render converts for a dag
+	// exported with -export.  It's nil for formats that can't be rendered
+	// from an export.
+	replay func(exportJSON []byte, opts *rpctest.RenderDagsDotOpts) ([]byte, error)
+
+	// render returns the file contents for the given step.
+	render func(dot []byte, step int) ([]byte, error)
+
+	// frames, when set, returns the contents of a single file that shows
+	// all of the given frames.  Formats without it save a file per frame.
+	frames func(dots [][]byte) ([]byte, error)
+}
+
+// htmlFigure renders the dag as an SVG image for embedding in HTML.
+func htmlFigure(dot []byte) ([]byte, error) {
+	// Convert DOT file contents to an SVG image
+	svg, err := soterutil.DotToSvg(dot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert DOT file to SVG: %s", err)
+	}
+
+	// We're going to embed the SVG image in HTML, so strip out the xml declaration
+	svgEmbed, err := soterutil.StripSvgXmlDecl(svg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to strip xml declaration from SVG image: %s", err)
+	}
+
+	return svgEmbed, nil
+}
+
+// renderHTML renders the dag step as an HTML document with stepping links.
+func renderHTML(dot []byte, step int) ([]byte, error) {
+	svgEmbed, err := htmlFigure(dot)
+	if err != nil {
+		return nil, err
+	}
+
+	svgBody, err := soterutil.RenderSvgHTMLFigure(svgEmbed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render SVG image as HTML figure: %s", err)
+	}
+
+	// Render the dag in an HTML document
+	h, err := soterutil.RenderSteppingHTML(svgBody, "dag", step-1, step+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render SVG image as HTML: %s", err)
+	}
+
+	return h, nil
+}
+
+// renderHTMLFrames renders the dag frames as a single HTML document, with a
+// slider for moving between the frames.
+func renderHTMLFrames(dots [][]byte) ([]byte, error) {
+	frames := make([][]byte, 0, len(dots))
+	for i, dot := range dots {
+		svgEmbed, err := htmlFigure(dot)
+		if err != nil {
+			return nil, err
+		}
+
+		frame, err := soterutil.RenderSvgHTMLFrame(svgEmbed, i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render SVG image as HTML frame: %s", err)
+		}
+		frames = append(frames, frame)
+	}
+
+	h, err := soterutil.RenderFramesHTML(frames, "dag")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render frames as HTML: %s", err)
+	}
+
+	return h, nil
+}
+
+// svgRenderer returns a function that renders the dag step as an SVG image.
+// When stripXMLDecl is true, the xml declaration is stripped from the image
+// so that it can be embedded in another document.
+func svgRenderer(stripXMLDecl bool) func(dot []byte, step int) ([]byte, error) {
+	return func(dot []byte, step int) ([]byte, error) {
+		// Convert DOT file contents to an SVG image
+		svg, err := soterutil.DotToSvg(dot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert DOT file to SVG: %s", err)
+		}
+
+		if !stripXMLDecl {
+			return svg, nil
+		}
+
+		svg, err = soterutil.StripSvgXmlDecl(svg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to strip xml declaration from SVG image: %s", err)
+		}
+
+		return svg, nil
+	}
+}
+
+// renderDOT returns the dag step in graphviz DOT file format, as-is.
+func renderDOT(dot []byte, step int) ([]byte, error) {
+	return dot, nil
+}
+
+// renderGraphML returns the dag step in GraphML format, as-is.
+func renderGraphML(graphML []byte, step int) ([]byte, error) {
+	return graphML, nil
+}
+
+// snapshotGraphML returns the miners' dag in GraphML format.  The blocks
+// always carry the index of the node that created them, so the options are
+// ignored.
+func snapshotGraphML(miners []*rpctest.Harness, opts *rpctest.RenderDagsDotOpts) ([]byte, error) {
+	return rpctest.RenderDagsGraphML(miners)
+}
+
+// renderASCII returns the dag step as text, as-is.
+func renderASCII(text []byte, step int) ([]byte, error) {
+	return text, nil
+}
+
+// snapshotASCII returns the miners' dag as text.  Text isn't colored, so the
+// options are ignored.
+func snapshotASCII(miners []*rpctest.Harness, opts *rpctest.RenderDagsDotOpts) ([]byte, error) {
+	return rpctest.RenderDagsASCII(miners)
+}
+
+// replayASCII returns an exported dag as text.  Text isn't colored, so the
+// options are ignored.
+func replayASCII(exportJSON []byte, opts *rpctest.RenderDagsDotOpts) ([]byte, error) {
+	return rpctest.RenderDagExportASCII(exportJSON)
+}
+
+// newRenderer returns the renderer for the given output format.
+func newRenderer(format string, stripXMLDecl bool) (*renderer, error) {
+	switch format {
+	case formatHTML:
+		return &renderer{ext: "html", snapshot: rpctest.RenderDagsDotWithOpts,
+			replay: rpctest.RenderDagExportDot, render: renderHTML,
+			frames: renderHTMLFrames}, nil
+	case formatSVG:
+		return &renderer{ext: "svg", snapshot: rpctest.RenderDagsDotWithOpts,
+			replay: rpctest.RenderDagExportDot,
+			render: svgRenderer(stripXMLDecl)}, nil
+	case formatDOT:
+		return &renderer{ext: "dot", snapshot: rpctest.RenderDagsDotWithOpts,
+			replay: rpctest.RenderDagExportDot, render: renderDOT}, nil
+	case formatGraphML:
+		return &renderer{ext: "graphml", snapshot: snapshotGraphML,
+			render: renderGraphML}, nil
+	case formatASCII:
+		return &renderer{ext: "txt", snapshot: snapshotASCII,
+			replay: replayASCII, render: renderASCII}, nil
+	}
+
+	return nil, fmt.Errorf("unknown output format %q, must be one of %s, %s, %s, %s or %s",
+		format, formatHTML, formatSVG, formatDOT, formatGraphML, formatASCII)
+}
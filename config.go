@@ -154,9 +154,14 @@ type config struct {
 	FreeTxRelayLimit   float64       `long:"limitfreerelay" description:"Limit relay of transactions with no transaction fee to the given amount in thousands of bytes per minute"`
 	NoRelayPriority    bool          `long:"norelaypriority" description:"Do not require free or low-fee transactions to have high priority for relaying"`
 	TrickleInterval    time.Duration `long:"trickleinterval" description:"Minimum time between attempts to send new inventory to a connected peer"`
+	MaxInvRate         float64       `long:"maxinvrate" description:"Maximum number of inv messages per second to accept from a peer -- 0 disables the limit"`
+	MaxInvBurst        int           `long:"maxinvburst" description:"Maximum number of inv messages to accept from a peer at once, before maxinvrate applies"`
+	MaxGetDataRate     float64       `long:"maxgetdatarate" description:"Maximum number of getdata messages per second to accept from a peer -- 0 disables the limit"`
+	MaxGetDataBurst    int           `long:"maxgetdataburst" description:"Maximum number of getdata messages to accept from a peer at once, before maxgetdatarate applies"`
 	MaxOrphanTxs       int           `long:"maxorphantx" description:"Max number of orphan transactions to keep in memory"`
 	Generate           bool          `long:"generate" description:"Generate (mine) soter tokens using the CPU"`
 	MiningAddrs        []string      `long:"miningaddr" description:"Add the specified payment address to the list of addresses to use for generated blocks -- At least one address is required if the generate option is set"`
+	MiningSeed         int64         `long:"miningseed" description:"Seed the CPU miner and give generated blocks the earliest valid timestamp, so that generating blocks on the same blocks again gives identical blocks -- For testing only"`
 	BlockMinSize       uint32        `long:"blockminsize" description:"Mininum block size in bytes to be used when creating a block"`
 	BlockMaxSize       uint32        `long:"blockmaxsize" description:"Maximum block size in bytes to be used when creating a block"`
 	BlockMinWeight     uint32        `long:"blockminweight" description:"Mininum block weight to be used when creating a block"`
@@ -174,6 +179,7 @@ type config struct {
 	DropAddrIndex      bool          `long:"dropaddrindex" description:"Deletes the address-based transaction index from the database on start up and then exits."`
 	RelayNonStd        bool          `long:"relaynonstd" description:"Relay non-standard transactions regardless of the default settings for the active network."`
 	RejectNonStd       bool          `long:"rejectnonstd" description:"Reject non-standard transactions regardless of the default settings for the active network."`
+	MaxOpReturnSize    int           `long:"maxopreturnsize" description:"Maximum number of bytes of data an OP_RETURN output may carry for the transaction to be relayed as standard"`
 	lookup             func(string) ([]net.IP, error)
 	oniondial          func(string, string, time.Duration) (net.Conn, error)
 	dial               func(string, string, time.Duration) (net.Conn, error)
@@ -436,12 +442,17 @@ func loadConfig() (*config, []string, error) {
 		MinRelayTxFee:        mempool.DefaultMinRelayTxFee.ToSOTO(),
 		FreeTxRelayLimit:     defaultFreeTxRelayLimit,
 		TrickleInterval:      defaultTrickleInterval,
+		MaxInvRate:           peer.DefaultRelayLimits.Inv.Rate,
+		MaxInvBurst:          peer.DefaultRelayLimits.Inv.Burst,
+		MaxGetDataRate:       peer.DefaultRelayLimits.GetData.Rate,
+		MaxGetDataBurst:      peer.DefaultRelayLimits.GetData.Burst,
 		BlockMinSize:         defaultBlockMinSize,
 		BlockMaxSize:         defaultBlockMaxSize,
 		BlockMinWeight:       defaultBlockMinWeight,
 		BlockMaxWeight:       defaultBlockMaxWeight,
 		BlockPrioritySize:    mempool.DefaultBlockPrioritySize,
 		MaxOrphanTxs:         defaultMaxOrphanTransactions,
+		MaxOpReturnSize:      mempool.DefaultMaxOpReturnSize,
 		SigCacheMaxSize:      defaultSigCacheMaxSize,
 		Generate:             defaultGenerate,
 		TxIndex:              defaultTxIndex,
@@ -790,6 +801,18 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// The relay limits can't be negative.
+	if cfg.MaxInvRate < 0 || cfg.MaxInvBurst < 0 || cfg.MaxGetDataRate < 0 ||
+		cfg.MaxGetDataBurst < 0 {
+
+		str := "%s: The maxinvrate, maxinvburst, maxgetdatarate and " +
+			"maxgetdataburst options may not be negative"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Limit the max block size to a sane value.
 	if cfg.BlockMaxSize < blockMaxSizeMin || cfg.BlockMaxSize >
 		blockMaxSizeMax {
@@ -826,6 +849,16 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// The max OP_RETURN size may not be negative.
+	if cfg.MaxOpReturnSize < 0 {
+		str := "%s: The maxopreturnsize option may not be less than 0 " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.MaxOpReturnSize)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Limit the block priority and minimum block sizes to max block size.
 	cfg.BlockPrioritySize = minUint32(cfg.BlockPrioritySize, cfg.BlockMaxSize)
 	cfg.BlockMinSize = minUint32(cfg.BlockMinSize, cfg.BlockMaxSize)
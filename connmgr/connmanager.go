@@ -234,7 +234,7 @@ func (cm *ConnManager) connHandler() {
 		pending = make(map[uint64]*ConnReq)
 
 		// conns represents the set of all actively connected peers.
-		conns = make(map[uint64]*ConnReq, cm.cfg.TargetOutbound)
+		conns = make(map[uint64]*ConnReq, cm.targetOutbound())
 	)
 
 out:
@@ -323,7 +323,7 @@ out:
 				// re added to the pending map, so that
 				// subsequent processing of connections and
 				// failures do not ignore the request.
-				if uint32(len(conns)) < cm.cfg.TargetOutbound ||
+				if uint32(len(conns)) < cm.targetOutbound() ||
 					connReq.Permanent {
 
 					connReq.updateState(ConnPending)
@@ -529,7 +529,29 @@ func (cm *ConnManager) Start() {
 		}
 	}
 
-	for i := atomic.LoadUint64(&cm.connReqCount); i < uint64(cm.cfg.TargetOutbound); i++ {
+	for i := atomic.LoadUint64(&cm.connReqCount); i < uint64(cm.targetOutbound()); i++ {
+		go cm.NewConnReq()
+	}
+}
+
+// targetOutbound returns the number of outbound network connections to
+// maintain.
+func (cm *ConnManager) targetOutbound() uint32 {
+	return atomic.LoadUint32(&cm.cfg.TargetOutbound)
+}
+
+// SetTargetOutbound changes the number of outbound network connections to
+// maintain.  When the target is raised, new connections are requested to make
+// up the difference.  Lowering the target doesn't close any connections, but
+// connections that are closed afterwards aren't retried while there are at
+// least as many connections as the target, so callers should disconnect the
+// excess connections themselves.
+func (cm *ConnManager) SetTargetOutbound(target uint32) {
+	prev := atomic.SwapUint32(&cm.cfg.TargetOutbound, target)
+	if atomic.LoadInt32(&cm.start) == 0 {
+		return
+	}
+	for i := prev; i < target; i++ {
 		go cm.NewConnReq()
 	}
 }
@@ -217,6 +217,63 @@ func TestTargetOutbound(t *testing.T) {
 	cmgr.Stop()
 }
 
+// TestSetTargetOutbound tests that raising the target number of outbound
+// connections makes the connection manager connect to more peers, and that
+// lowering it stops connections from being replaced when they're closed.
+func TestSetTargetOutbound(t *testing.T) {
+	connected := make(chan *ConnReq)
+	disconnected := make(chan *ConnReq)
+	cmgr, err := New(&Config{
+		TargetOutbound: 2,
+		Dial:           mockDialer,
+		GetNewAddress: func() (net.Addr, error) {
+			return &net.TCPAddr{
+				IP:   net.ParseIP("127.0.0.1"),
+				Port: 18555,
+			}, nil
+		},
+		OnConnection: func(c *ConnReq, conn net.Conn) {
+			connected <- c
+		},
+		OnDisconnection: func(c *ConnReq) {
+			disconnected <- c
+		},
+	})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	cmgr.Start()
+	conns := make([]*ConnReq, 0, 4)
+	for i := 0; i < 2; i++ {
+		conns = append(conns, <-connected)
+	}
+
+	cmgr.SetTargetOutbound(4)
+	for i := 0; i < 2; i++ {
+		conns = append(conns, <-connected)
+	}
+	select {
+	case c := <-connected:
+		t.Fatalf("raised target outbound: got unexpected connection - %v",
+			c.Addr)
+	case <-time.After(time.Millisecond):
+		break
+	}
+
+	// Closing a connection above the lowered target doesn't replace it.
+	cmgr.SetTargetOutbound(1)
+	cmgr.Disconnect(conns[0].ID())
+	<-disconnected
+	select {
+	case c := <-connected:
+		t.Fatalf("lowered target outbound: got unexpected connection - %v",
+			c.Addr)
+	case <-time.After(time.Millisecond):
+		break
+	}
+	cmgr.Stop()
+}
+
 // TestRetryPermanent tests that permanent connection requests are retried.
 //
 // We make a permanent connection request using Connect, disconnect it using
@@ -99,6 +99,14 @@ Application Options:
                             minute (15)
       --norelaypriority     Do not require free or low-fee transactions to have
                             high priority for relaying
+      --maxinvrate=         Maximum number of inv messages per second to accept
+                            from a peer -- 0 disables the limit (100)
+      --maxinvburst=        Maximum number of inv messages to accept from a peer
+                            at once, before maxinvrate applies (1000)
+      --maxgetdatarate=     Maximum number of getdata messages per second to
+                            accept from a peer -- 0 disables the limit (100)
+      --maxgetdataburst=    Maximum number of getdata messages to accept from a
+                            peer at once, before maxgetdatarate applies (1000)
       --maxorphantx=        Max number of orphan transactions to keep in memory
                             (100)
       --generate            Generate (mine) soter tokens using the CPU
@@ -106,6 +114,10 @@ Application Options:
                             addresses to use for generated blocks -- At least
                             one address is required if the generate option is
                             set
+      --miningseed=         Seed the CPU miner and give generated blocks the
+                            earliest valid timestamp, so that generating blocks
+                            on the same blocks again gives identical blocks --
+                            For testing only
       --blockminsize=       Mininum block size in bytes to be used when creating
                             a block
       --blockmaxsize=       Maximum block size in bytes to be used when creating
@@ -121,6 +133,9 @@ Application Options:
                             default settings for the active network.
       --rejectnonstd        Reject non-standard transactions regardless of the
                             default settings for the active network.
+      --maxopreturnsize=    Maximum number of bytes of data an OP_RETURN output
+                            may carry for the transaction to be relayed as
+                            standard (80)
 
 Help Options:
   -h, --help           Show this help message
@@ -16,6 +16,7 @@ import (
 	"github.com/soteria-dag/soterd/chaincfg/chainhash"
 	"github.com/soteria-dag/soterd/integration/rpctest"
 	"github.com/soteria-dag/soterd/rpcclient"
+	"github.com/soteria-dag/soterd/soterjson"
 	"github.com/soteria-dag/soterd/soterutil"
 )
 
@@ -30,7 +31,7 @@ func findMissingBlocks(nodeNum int, aNode *rpctest.Harness, expectedBlocks map[s
 
 	foundBlocks := make(map[string]int)
 	for height := int32(0); height <= tips.MaxHeight; height++ {
-		hashes, err := aNode.Node.GetBlockHash(int64(height))
+		hashes, err := aNode.Node.GetDagBlockHashes(height)
 		if err != nil {
 			return missing, err
 		}
@@ -429,4 +430,1137 @@ func TestDAGBlock(t *testing.T) {
 	if err != nil {
 		t.Fatalf(err.Error())
 	}
-}
\ No newline at end of file
+}
+
+func TestGetBlocksByHeightRange(t *testing.T) {
+	var miners []*rpctest.Harness
+	for i := 0; i < 2; i++ {
+		miner, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+		if err != nil {
+			t.Fatalf("unable to create mining node %v: %v", i, err)
+		}
+
+		if err := miner.SetUp(false, 0); err != nil {
+			t.Fatalf("unable to complete mining node %v setup: %v", i, err)
+		}
+
+		miners = append(miners, miner)
+	}
+	defer func() {
+		for _, miner := range miners {
+			_ = miner.TearDown()
+		}
+	}()
+
+	// Mine a block on each miner before connecting them, so that the dag
+	// has two sibling blocks at height 1.
+	siblings := make(map[chainhash.Hash]bool)
+	var siblingHashes []*chainhash.Hash
+	for i, miner := range miners {
+		hashes, err := miner.Node.Generate(1)
+		if err != nil {
+			t.Fatalf("miner %v failed to generate block: %v", i, err)
+		}
+		siblings[*hashes[0]] = true
+		siblingHashes = append(siblingHashes, hashes[0])
+	}
+
+	if err := rpctest.ConnectNodes(miners); err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+
+	// The miners are at the same height, so generate a block on each in
+	// turn to trigger sync of the siblings.
+	for i, miner := range miners {
+		if _, err := miner.Node.Generate(1); err != nil {
+			t.Fatalf("miner %v failed to generate block: %v", i, err)
+		}
+	}
+	for _, hash := range siblingHashes {
+		if err := miners[0].WaitForBlock(hash, time.Second*10); err != nil {
+			t.Fatalf("sibling block didn't sync to miner 0: %v", err)
+		}
+	}
+
+	// Fetch the range with the miner's regular client, as well as with a
+	// batch client.
+	rpcConf := miners[0].RPCConfig()
+	batch, err := rpcclient.NewBatch(&rpcConf)
+	if err != nil {
+		t.Fatalf("unable to create batch client: %v", err)
+	}
+	defer batch.Shutdown()
+
+	clients := map[string]*rpcclient.Client{
+		"websocket": miners[0].Node,
+		"batch":     batch,
+	}
+	for name, client := range clients {
+		heights, err := client.GetBlocksByHeightRange(0, 1)
+		if err != nil {
+			t.Fatalf("%s GetBlocksByHeightRange failed: %v", name, err)
+		}
+		if len(heights) != 2 {
+			t.Fatalf("%s GetBlocksByHeightRange returned %d heights, "+
+				"wanted 2", name, len(heights))
+		}
+
+		for i, hb := range heights {
+			if hb.Height != int32(i) {
+				t.Fatalf("%s GetBlocksByHeightRange result %d has "+
+					"height %d", name, i, hb.Height)
+			}
+		}
+
+		genesis := heights[0].Blocks
+		if len(genesis) != 1 ||
+			genesis[0].BlockHash() != *chaincfg.SimNetParams.GenesisHash {
+			t.Fatalf("%s GetBlocksByHeightRange: wrong blocks at "+
+				"height 0: %v", name, genesis)
+		}
+
+		got := heights[1].Blocks
+		if len(got) != len(siblings) {
+			t.Fatalf("%s GetBlocksByHeightRange returned %d blocks at "+
+				"height 1, wanted %d", name, len(got), len(siblings))
+		}
+		for _, block := range got {
+			if hash := block.BlockHash(); !siblings[hash] {
+				t.Fatalf("%s GetBlocksByHeightRange returned "+
+					"unexpected block %v at height 1", name, hash)
+			}
+		}
+	}
+
+	// An inverted range is rejected.
+	if _, err := miners[0].Node.GetBlocksByHeightRange(1, 0); err == nil {
+		t.Fatalf("GetBlocksByHeightRange succeeded for an inverted range")
+	}
+}
+
+// TestGetBlockParents mines a block on top of multiple dag tips, and checks
+// that all of its parents are returned by GetBlockParents.
+func TestGetBlockParents(t *testing.T) {
+	var miners []*rpctest.Harness
+	for i := 0; i < 2; i++ {
+		miner, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+		if err != nil {
+			t.Fatalf("unable to create mining node %v: %v", i, err)
+		}
+
+		if err := miner.SetUp(false, 0); err != nil {
+			t.Fatalf("unable to complete mining node %v setup: %v", i, err)
+		}
+
+		miners = append(miners, miner)
+	}
+	defer func() {
+		for _, miner := range miners {
+			_ = miner.TearDown()
+		}
+	}()
+
+	// Mine a block on each miner before connecting them, so that the dag
+	// has multiple tips once they sync.
+	var generated []*chainhash.Hash
+	for i, miner := range miners {
+		hashes, err := miner.Node.Generate(1)
+		if err != nil {
+			t.Fatalf("miner %v failed to generate block: %v", i, err)
+		}
+		generated = append(generated, hashes[0])
+	}
+
+	if err := rpctest.ConnectNodes(miners); err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+
+	// The miners are at the same height, so generate a block on each in
+	// turn to trigger sync.
+	for i, miner := range miners {
+		hashes, err := miner.Node.Generate(1)
+		if err != nil {
+			t.Fatalf("miner %v failed to generate block: %v", i, err)
+		}
+		generated = append(generated, hashes[0])
+	}
+	for _, hash := range generated {
+		if err := miners[0].WaitForBlock(hash, time.Second*10); err != nil {
+			t.Fatalf("block didn't sync to miner 0: %v", err)
+		}
+	}
+
+	// The genesis block has no parents.
+	parents, err := miners[0].Node.GetBlockParents(chaincfg.SimNetParams.GenesisHash)
+	if err != nil {
+		t.Fatalf("GetBlockParents failed for genesis block: %v", err)
+	}
+	if len(parents) != 0 {
+		t.Fatalf("GetBlockParents returned %d parents for genesis block, "+
+			"wanted 0", len(parents))
+	}
+
+	tips, err := miners[0].Node.GetDAGTips()
+	if err != nil {
+		t.Fatalf("GetDAGTips failed: %v", err)
+	}
+	if len(tips.Tips) < 2 {
+		t.Fatalf("miner 0 has %d dag tips, wanted at least 2: %v",
+			len(tips.Tips), tips.Tips)
+	}
+	expected := make(map[string]bool)
+	for _, tip := range tips.Tips {
+		expected[tip] = true
+	}
+
+	// A new block on miner 0 should reference every tip as a parent.
+	hashes, err := miners[0].Node.Generate(1)
+	if err != nil {
+		t.Fatalf("miner 0 failed to generate block: %v", err)
+	}
+
+	parents, err = miners[0].Node.GetBlockParents(hashes[0])
+	if err != nil {
+		t.Fatalf("GetBlockParents failed: %v", err)
+	}
+	if len(parents) != len(expected) {
+		t.Fatalf("GetBlockParents returned %d parents, wanted %d: %v",
+			len(parents), len(expected), parents)
+	}
+	for _, parent := range parents {
+		if !expected[parent.String()] {
+			t.Fatalf("GetBlockParents returned unexpected parent %v",
+				parent)
+		}
+	}
+
+	// The parents should match those in the block's parent sub-header.
+	block, err := miners[0].Node.GetBlock(hashes[0])
+	if err != nil {
+		t.Fatalf("GetBlock failed: %v", err)
+	}
+	if len(block.Parents.Parents) != len(parents) {
+		t.Fatalf("block has %d parents, GetBlockParents returned %d",
+			len(block.Parents.Parents), len(parents))
+	}
+	for i, parent := range block.Parents.Parents {
+		if parent.Hash != *parents[i] {
+			t.Fatalf("parent %d mismatch: block has %v, "+
+				"GetBlockParents returned %v", i, parent.Hash, parents[i])
+		}
+	}
+}
+
+// TestGetDagBlockHashes tests that getdagblockhashes returns every block at a
+// height, and that getblockhash returns the first of them.
+func TestGetDagBlockHashes(t *testing.T) {
+	var miners []*rpctest.Harness
+	for i := 0; i < 2; i++ {
+		miner, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+		if err != nil {
+			t.Fatalf("unable to create mining node %v: %v", i, err)
+		}
+
+		if err := miner.SetUp(false, 0); err != nil {
+			t.Fatalf("unable to complete mining node %v setup: %v", i, err)
+		}
+
+		miners = append(miners, miner)
+	}
+	defer func() {
+		for _, miner := range miners {
+			_ = miner.TearDown()
+		}
+	}()
+
+	// Mine a block on each miner before connecting them, so that there are
+	// two blocks at height 1 once they sync.
+	siblings := make(map[string]bool)
+	var generated []*chainhash.Hash
+	for i, miner := range miners {
+		hashes, err := miner.Node.Generate(1)
+		if err != nil {
+			t.Fatalf("miner %v failed to generate block: %v", i, err)
+		}
+		siblings[hashes[0].String()] = true
+		generated = append(generated, hashes[0])
+	}
+
+	if err := rpctest.ConnectNodes(miners); err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+
+	// The miners are at the same height, so generate a block on each in
+	// turn to trigger sync.
+	for i, miner := range miners {
+		hashes, err := miner.Node.Generate(1)
+		if err != nil {
+			t.Fatalf("miner %v failed to generate block: %v", i, err)
+		}
+		generated = append(generated, hashes[0])
+	}
+	for _, hash := range generated {
+		if err := miners[0].WaitForBlock(hash, time.Second*10); err != nil {
+			t.Fatalf("block didn't sync to miner 0: %v", err)
+		}
+	}
+
+	hashes, err := miners[0].Node.GetDagBlockHashes(1)
+	if err != nil {
+		t.Fatalf("GetDagBlockHashes failed: %v", err)
+	}
+	if len(hashes) != len(siblings) {
+		t.Fatalf("GetDagBlockHashes returned %d hashes, wanted %d: %v",
+			len(hashes), len(siblings), hashes)
+	}
+	for _, hash := range hashes {
+		if !siblings[hash.String()] {
+			t.Fatalf("GetDagBlockHashes returned unexpected hash %v", hash)
+		}
+	}
+
+	hash, err := miners[0].Node.GetBlockHash(1)
+	if err != nil {
+		t.Fatalf("GetBlockHash failed: %v", err)
+	}
+	if !hash.IsEqual(hashes[0]) {
+		t.Fatalf("GetBlockHash returned %v, wanted first block in dag "+
+			"ordering %v", hash, hashes[0])
+	}
+
+	if _, err := miners[0].Node.GetDagBlockHashes(1000); err == nil {
+		t.Fatalf("GetDagBlockHashes for invalid height succeeded when " +
+			"it should have failed")
+	}
+}
+
+// TestCompareDags tests that CompareDags reports the blocks unique to each of
+// two diverged nodes, and that the diff is empty once they sync.
+func TestCompareDags(t *testing.T) {
+	var miners []*rpctest.Harness
+	for i := 0; i < 2; i++ {
+		miner, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+		if err != nil {
+			t.Fatalf("unable to create mining node %v: %v", i, err)
+		}
+
+		if err := miner.SetUp(false, 0); err != nil {
+			t.Fatalf("unable to complete mining node %v setup: %v", i, err)
+		}
+
+		miners = append(miners, miner)
+	}
+	defer func() {
+		for _, miner := range miners {
+			_ = miner.TearDown()
+		}
+	}()
+
+	diff, err := rpctest.CompareDags(miners[0], miners[1])
+	if err != nil {
+		t.Fatalf("CompareDags failed: %v", err)
+	}
+	if !diff.Equal() {
+		t.Fatalf("fresh nodes have different dags: %v", diff)
+	}
+
+	// Diverge the miners by mining blocks on each while they're
+	// disconnected.
+	var generated [][]*chainhash.Hash
+	for i, miner := range miners {
+		hashes, err := miner.Node.Generate(uint32(i + 1))
+		if err != nil {
+			t.Fatalf("miner %v failed to generate blocks: %v", i, err)
+		}
+		generated = append(generated, hashes)
+	}
+
+	diff, err = rpctest.CompareDags(miners[0], miners[1])
+	if err != nil {
+		t.Fatalf("CompareDags failed: %v", err)
+	}
+	if diff.Equal() {
+		t.Fatalf("diverged nodes have equal dags")
+	}
+	for i, unique := range [][]*chainhash.Hash{diff.OnlyA, diff.OnlyB} {
+		if len(unique) != len(generated[i]) {
+			t.Fatalf("diff has %d blocks unique to miner %d, wanted "+
+				"%d: %v", len(unique), i, len(generated[i]), diff)
+		}
+
+		want := make(map[chainhash.Hash]bool)
+		for _, hash := range generated[i] {
+			want[*hash] = true
+		}
+		for _, hash := range unique {
+			if !want[*hash] {
+				t.Fatalf("diff has unexpected block %v unique to "+
+					"miner %d", hash, i)
+			}
+		}
+	}
+
+	// Once the miners sync, the diff should be empty again.
+	if err := rpctest.ConnectNodes(miners); err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+	for i, miner := range miners {
+		if _, err := miner.Node.Generate(1); err != nil {
+			t.Fatalf("miner %v failed to generate block: %v", i, err)
+		}
+	}
+	if err := rpctest.WaitForDAG(miners, time.Second*30); err != nil {
+		t.Fatalf("miners didn't sync: %v", err)
+	}
+
+	diff, err = rpctest.CompareDags(miners[0], miners[1])
+	if err != nil {
+		t.Fatalf("CompareDags failed: %v", err)
+	}
+	if !diff.Equal() {
+		t.Fatalf("synced nodes have different dags: %v", diff)
+	}
+}
+
+// TestWaitForConvergence tests that WaitForConvergence reports the nodes whose
+// dags differ from the first node, and returns once connected nodes have
+// synced the blocks mined on each of them.
+func TestWaitForConvergence(t *testing.T) {
+	const numMiners = 3
+
+	var miners []*rpctest.Harness
+	for i := 0; i < numMiners; i++ {
+		miner, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+		if err != nil {
+			t.Fatalf("unable to create mining node %v: %v", i, err)
+		}
+
+		if err := miner.SetUp(false, 0); err != nil {
+			t.Fatalf("unable to complete mining node %v setup: %v", i, err)
+		}
+
+		miners = append(miners, miner)
+	}
+	defer func() {
+		for _, miner := range miners {
+			_ = miner.TearDown()
+		}
+	}()
+
+	if err := rpctest.WaitForConvergence(miners, time.Second); err != nil {
+		t.Fatalf("fresh nodes didn't converge: %v", err)
+	}
+
+	// Mine on all but the first node while they're disconnected, so
+	// that every other node disagrees with it.
+	for i, miner := range miners[1:] {
+		if _, err := miner.Node.Generate(1); err != nil {
+			t.Fatalf("miner %v failed to generate block: %v", i+1, err)
+		}
+	}
+
+	err := rpctest.WaitForConvergence(miners, time.Second)
+	convErr, ok := err.(*rpctest.ConvergenceError)
+	if !ok {
+		t.Fatalf("WaitForConvergence of diverged nodes returned %v, "+
+			"wanted a *ConvergenceError", err)
+	}
+	for i := 1; i < numMiners; i++ {
+		diff, exists := convErr.Diffs[i]
+		if !exists {
+			t.Fatalf("WaitForConvergence didn't report node %d: %v",
+				i, err)
+		}
+		if len(diff.OnlyA) != 0 || len(diff.OnlyB) != 1 {
+			t.Fatalf("diff of node %d has %d blocks only in node 0 "+
+				"and %d only in node %d, wanted 0 and 1", i,
+				len(diff.OnlyA), len(diff.OnlyB), i)
+		}
+	}
+
+	// Once connected, the nodes converge on the blocks mined on each of
+	// them.
+	if err := rpctest.ConnectNodes(miners); err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+	var generated []*chainhash.Hash
+	for i, miner := range miners {
+		hashes, err := miner.Node.Generate(1)
+		if err != nil {
+			t.Fatalf("miner %v failed to generate block: %v", i, err)
+		}
+		generated = append(generated, hashes...)
+	}
+	if err := rpctest.WaitForConvergence(miners, time.Second*30); err != nil {
+		t.Fatalf("miners didn't converge: %v", err)
+	}
+
+	for i, miner := range miners {
+		for _, hash := range generated {
+			if _, err := miner.Node.GetBlock(hash); err != nil {
+				t.Fatalf("miner %d is missing block %v after "+
+					"converging: %v", i, hash, err)
+			}
+		}
+	}
+}
+
+// TestGetTxConfirmations tests that transactions in blue blocks are confirmed
+// by the blocks after them in the dag ordering, and that transactions in red
+// blocks aren't confirmed.
+func TestGetTxConfirmations(t *testing.T) {
+	// Mining more blocks at the same height than a blue block may have in
+	// its anticone ensures that at least one of them is red.
+	const numMiners = 5
+
+	var miners []*rpctest.Harness
+	for i := 0; i < numMiners; i++ {
+		miner, err := rpctest.New(&chaincfg.SimNetParams, nil,
+			[]string{"--txindex"}, false)
+		if err != nil {
+			t.Fatalf("unable to create mining node %v: %v", i, err)
+		}
+
+		if err := miner.SetUp(false, 0); err != nil {
+			t.Fatalf("unable to complete mining node %v setup: %v", i, err)
+		}
+
+		miners = append(miners, miner)
+	}
+	defer func() {
+		for _, miner := range miners {
+			_ = miner.TearDown()
+		}
+	}()
+
+	// Mine a block on each miner before connecting them, so that the blocks
+	// at height 1 are in each other's anticone once they sync.
+	var siblings []*chainhash.Hash
+	var generated []*chainhash.Hash
+	for i, miner := range miners {
+		hashes, err := miner.Node.Generate(1)
+		if err != nil {
+			t.Fatalf("miner %v failed to generate block: %v", i, err)
+		}
+		siblings = append(siblings, hashes[0])
+		generated = append(generated, hashes[0])
+	}
+
+	if err := rpctest.ConnectNodes(miners); err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+
+	// The miners are at the same height, so generate a block on each in
+	// turn to trigger sync.
+	for i, miner := range miners {
+		hashes, err := miner.Node.Generate(1)
+		if err != nil {
+			t.Fatalf("miner %v failed to generate block: %v", i, err)
+		}
+		generated = append(generated, hashes[0])
+	}
+	for _, hash := range generated {
+		if err := miners[0].WaitForBlock(hash, time.Second*10); err != nil {
+			t.Fatalf("block didn't sync to miner 0: %v", err)
+		}
+	}
+
+	coloring, err := miners[0].Node.GetDAGColoring()
+	if err != nil {
+		t.Fatalf("GetDAGColoring failed: %v", err)
+	}
+	colors := make(map[string]*soterjson.GetDAGColoringResult)
+	for _, c := range coloring {
+		colors[c.Hash] = c
+	}
+
+	var blue, red int
+	for _, hash := range siblings {
+		color, ok := colors[hash.String()]
+		if !ok {
+			t.Fatalf("block %v isn't in the dag coloring", hash)
+		}
+
+		block, err := miners[0].Node.GetBlock(hash)
+		if err != nil {
+			t.Fatalf("unable to get block %v: %v", hash, err)
+		}
+		coinbase := block.Transactions[0].TxHash()
+
+		confs, err := miners[0].Node.GetTxConfirmations(&coinbase)
+		if err != nil {
+			t.Fatalf("GetTxConfirmations failed for %v: %v", coinbase, err)
+		}
+
+		want := int64(-1)
+		if color.IsBlue {
+			want = int64(len(coloring) - color.Order - 1)
+			blue++
+		} else {
+			red++
+		}
+		if confs != want {
+			t.Fatalf("GetTxConfirmations returned %d for tx %v in block "+
+				"%v (blue %v), wanted %d", confs, coinbase, hash,
+				color.IsBlue, want)
+		}
+	}
+	if blue == 0 || red == 0 {
+		t.Fatalf("expected blue and red blocks at height 1, got %d blue "+
+			"and %d red", blue, red)
+	}
+}
+
+// TestGetDagInfo tests that getdaginfo reports a single tip at genesis, and
+// more tips once sibling blocks are mined.
+func TestGetDagInfo(t *testing.T) {
+	var miners []*rpctest.Harness
+	for i := 0; i < 2; i++ {
+		miner, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+		if err != nil {
+			t.Fatalf("unable to create mining node %v: %v", i, err)
+		}
+
+		if err := miner.SetUp(false, 0); err != nil {
+			t.Fatalf("unable to complete mining node %v setup: %v", i, err)
+		}
+
+		miners = append(miners, miner)
+	}
+	defer func() {
+		for _, miner := range miners {
+			_ = miner.TearDown()
+		}
+	}()
+
+	info, err := miners[0].Node.GetDagInfo()
+	if err != nil {
+		t.Fatalf("GetDagInfo failed: %v", err)
+	}
+	if info.TipCount != 1 || info.BlkCount != 1 || info.MaxHeight != 0 {
+		t.Fatalf("wrong dag info at genesis: %+v", info)
+	}
+
+	// Mine one block on the first miner and two on the second before
+	// connecting them, so that the first miner syncs the second miner's
+	// blocks alongside its own.
+	var generated []*chainhash.Hash
+	for i, miner := range miners {
+		hashes, err := miner.Node.Generate(uint32(i + 1))
+		if err != nil {
+			t.Fatalf("miner %v failed to generate blocks: %v", i, err)
+		}
+		generated = append(generated, hashes...)
+	}
+
+	info, err = miners[0].Node.GetDagInfo()
+	if err != nil {
+		t.Fatalf("GetDagInfo failed: %v", err)
+	}
+	if info.TipCount != 1 {
+		t.Fatalf("GetDagInfo reported %d tips for a chain, wanted 1",
+			info.TipCount)
+	}
+
+	if err := rpctest.ConnectNodes(miners); err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+	for _, hash := range generated {
+		if err := miners[0].WaitForBlock(hash, time.Second*10); err != nil {
+			t.Fatalf("block didn't sync to miner 0: %v", err)
+		}
+	}
+
+	info, err = miners[0].Node.GetDagInfo()
+	if err != nil {
+		t.Fatalf("GetDagInfo failed: %v", err)
+	}
+	wantBlocks := uint32(len(generated) + 1)
+	if info.TipCount != 2 {
+		t.Fatalf("GetDagInfo reported %d tips, wanted 2", info.TipCount)
+	}
+	if info.BlkCount != wantBlocks {
+		t.Fatalf("GetDagInfo reported %d blocks, wanted %d",
+			info.BlkCount, wantBlocks)
+	}
+	if info.MaxHeight != 2 {
+		t.Fatalf("GetDagInfo reported max height %d, wanted 2",
+			info.MaxHeight)
+	}
+	if info.BlueSetSize < 1 || info.BlueSetSize > int(wantBlocks) {
+		t.Fatalf("GetDagInfo reported a blue set of %d blocks, for a "+
+			"dag of %d blocks", info.BlueSetSize, wantBlocks)
+	}
+	if info.OrphanCount != 0 {
+		t.Fatalf("GetDagInfo reported %d orphans, wanted 0",
+			info.OrphanCount)
+	}
+}
+
+// TestDagPaging tests that the tips of a dag with more tips than the page size,
+// and the blocks of a height range with more blocks than the page size, can be
+// paged through and reassembled into the full set.
+func TestDagPaging(t *testing.T) {
+	const numMiners = 4
+	var miners []*rpctest.Harness
+	for i := 0; i < numMiners; i++ {
+		miner, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+		if err != nil {
+			t.Fatalf("unable to create mining node %v: %v", i, err)
+		}
+
+		if err := miner.SetUp(false, 0); err != nil {
+			t.Fatalf("unable to complete mining node %v setup: %v", i, err)
+		}
+
+		miners = append(miners, miner)
+	}
+	defer func() {
+		for _, miner := range miners {
+			_ = miner.TearDown()
+		}
+	}()
+
+	// Mine a chain of a different length on each miner before connecting
+	// them, so that the first miner syncs a tip from every miner.
+	var generated []*chainhash.Hash
+	for i, miner := range miners {
+		hashes, err := miner.Node.Generate(uint32(i + 1))
+		if err != nil {
+			t.Fatalf("miner %v failed to generate blocks: %v", i, err)
+		}
+		generated = append(generated, hashes...)
+	}
+
+	if err := rpctest.ConnectNodes(miners); err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+	for _, hash := range generated {
+		if err := miners[0].WaitForBlock(hash, time.Second*10); err != nil {
+			t.Fatalf("block didn't sync to miner 0: %v", err)
+		}
+	}
+
+	node := miners[0].Node
+	all, err := node.GetDAGTips()
+	if err != nil {
+		t.Fatalf("GetDAGTips failed: %v", err)
+	}
+	if len(all.Tips) != numMiners || all.TipCount != numMiners ||
+		all.NextOffset != 0 {
+		t.Fatalf("GetDAGTips returned %d of %d tips with next offset "+
+			"%d, wanted all %d tips", len(all.Tips), all.TipCount,
+			all.NextOffset, numMiners)
+	}
+
+	// Page through the tips, and make sure the pages add up to the full
+	// set of tips, in the same order.
+	const tipsPerPage = 3
+	var paged []string
+	offset := int32(0)
+	for pages := 0; ; pages++ {
+		if pages > numMiners {
+			t.Fatalf("GetDAGTipsPage didn't reach the last page")
+		}
+
+		page, err := node.GetDAGTipsPage(offset, tipsPerPage)
+		if err != nil {
+			t.Fatalf("GetDAGTipsPage(%d, %d) failed: %v", offset,
+				tipsPerPage, err)
+		}
+		if len(page.Tips) > tipsPerPage ||
+			len(page.TipInfo) != len(page.Tips) {
+			t.Fatalf("GetDAGTipsPage(%d, %d) returned %d tips and %d "+
+				"tip infos", offset, tipsPerPage, len(page.Tips),
+				len(page.TipInfo))
+		}
+		if page.Hash != all.Hash || page.TipCount != all.TipCount {
+			t.Fatalf("GetDAGTipsPage(%d, %d) describes a different dag "+
+				"than GetDAGTips", offset, tipsPerPage)
+		}
+
+		paged = append(paged, page.Tips...)
+		if page.NextOffset == 0 {
+			break
+		}
+		offset = page.NextOffset
+	}
+	if len(paged) != len(all.Tips) {
+		t.Fatalf("paged through %d tips, wanted %d", len(paged),
+			len(all.Tips))
+	}
+	for i := range paged {
+		if paged[i] != all.Tips[i] {
+			t.Fatalf("paged tip %d is %s, wanted %s", i, paged[i],
+				all.Tips[i])
+		}
+	}
+
+	// An offset past the last tip returns an empty last page.
+	page, err := node.GetDAGTipsPage(numMiners+1, tipsPerPage)
+	if err != nil {
+		t.Fatalf("GetDAGTipsPage past the last tip failed: %v", err)
+	}
+	if len(page.Tips) != 0 || page.NextOffset != 0 {
+		t.Fatalf("GetDAGTipsPage past the last tip returned %d tips "+
+			"and next offset %d", len(page.Tips), page.NextOffset)
+	}
+
+	// Page through the blocks of the whole dag, and make sure the pages add
+	// up to the blocks returned by GetBlocksByHeightRange.
+	const blocksPerPage = 4
+	want, err := node.GetBlocksByHeightRange(0, all.MaxHeight)
+	if err != nil {
+		t.Fatalf("GetBlocksByHeightRange failed: %v", err)
+	}
+	var heights []rpcclient.HeightBlocks
+	start := int32(0)
+	for start != -1 {
+		page, next, err := node.GetBlocksByHeightRangePage(start,
+			all.MaxHeight, blocksPerPage)
+		if err != nil {
+			t.Fatalf("GetBlocksByHeightRangePage(%d) failed: %v",
+				start, err)
+		}
+		if len(page) == 0 || page[0].Height != start {
+			t.Fatalf("GetBlocksByHeightRangePage(%d) didn't start "+
+				"at height %d", start, start)
+		}
+
+		count := 0
+		for _, hb := range page {
+			count += len(hb.Blocks)
+		}
+		if count > blocksPerPage && len(page) > 1 {
+			t.Fatalf("GetBlocksByHeightRangePage(%d) returned %d "+
+				"blocks over %d heights, for a limit of %d", start,
+				count, len(page), blocksPerPage)
+		}
+
+		heights = append(heights, page...)
+		start = next
+	}
+	if len(heights) != len(want) {
+		t.Fatalf("paged through %d heights, wanted %d", len(heights),
+			len(want))
+	}
+	for i := range want {
+		if heights[i].Height != want[i].Height ||
+			len(heights[i].Blocks) != len(want[i].Blocks) {
+			t.Fatalf("paged height %d has %d blocks, wanted height "+
+				"%d with %d blocks", heights[i].Height,
+				len(heights[i].Blocks), want[i].Height,
+				len(want[i].Blocks))
+		}
+		for j := range want[i].Blocks {
+			if heights[i].Blocks[j].BlockHash() !=
+				want[i].Blocks[j].BlockHash() {
+				t.Fatalf("paged block %d at height %d doesn't "+
+					"match", j, want[i].Height)
+			}
+		}
+	}
+}
+
+// TestGenerateWithParents tests that generatewithparents creates a block that
+// references exactly the given tips, and rejects parents that aren't tips.
+func TestGenerateWithParents(t *testing.T) {
+	const numMiners = 3
+	var miners []*rpctest.Harness
+	for i := 0; i < numMiners; i++ {
+		miner, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+		if err != nil {
+			t.Fatalf("unable to create mining node %v: %v", i, err)
+		}
+
+		if err := miner.SetUp(false, 0); err != nil {
+			t.Fatalf("unable to complete mining node %v setup: %v", i, err)
+		}
+
+		miners = append(miners, miner)
+	}
+	defer func() {
+		for _, miner := range miners {
+			_ = miner.TearDown()
+		}
+	}()
+
+	// Mine a block on each miner before connecting them, so that the first
+	// miner ends up with a tip from every miner.
+	var tips []*chainhash.Hash
+	for i, miner := range miners {
+		hashes, err := miner.Node.Generate(1)
+		if err != nil {
+			t.Fatalf("miner %v failed to generate a block: %v", i, err)
+		}
+		tips = append(tips, hashes[0])
+	}
+
+	if err := rpctest.ConnectNodes(miners); err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+	for _, hash := range tips {
+		if err := miners[0].WaitForBlock(hash, time.Second*10); err != nil {
+			t.Fatalf("block didn't sync to miner 0: %v", err)
+		}
+	}
+
+	// Force a block that references only two of the three tips.
+	node := miners[0].Node
+	parents := []*chainhash.Hash{tips[1], tips[2]}
+	hashes, err := node.GenerateWithParents(1, parents)
+	if err != nil {
+		t.Fatalf("GenerateWithParents failed: %v", err)
+	}
+	if len(hashes) != 1 {
+		t.Fatalf("GenerateWithParents returned %d hashes, wanted 1",
+			len(hashes))
+	}
+
+	block, err := node.GetBlock(hashes[0])
+	if err != nil {
+		t.Fatalf("GetBlock failed: %v", err)
+	}
+	got := block.Parents.ParentHashes()
+	if len(got) != len(parents) {
+		t.Fatalf("generated block has %d parents, wanted %d", len(got),
+			len(parents))
+	}
+	for _, parent := range parents {
+		found := false
+		for _, hash := range got {
+			if hash == *parent {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("generated block doesn't reference parent %v",
+				parent)
+		}
+	}
+
+	// The tip that wasn't referenced remains a tip, next to the generated
+	// block.
+	dagTips, err := node.GetDAGTips()
+	if err != nil {
+		t.Fatalf("GetDAGTips failed: %v", err)
+	}
+	wantTips := map[string]bool{
+		tips[0].String():   true,
+		hashes[0].String(): true,
+	}
+	if len(dagTips.Tips) != len(wantTips) {
+		t.Fatalf("dag has tips %v, wanted %v", dagTips.Tips, wantTips)
+	}
+	for _, tip := range dagTips.Tips {
+		if !wantTips[tip] {
+			t.Fatalf("dag has tips %v, wanted %v", dagTips.Tips,
+				wantTips)
+		}
+	}
+
+	// Parents that are no longer tips, or that aren't in the dag, are
+	// rejected.
+	unknown := chainhash.DoubleHashH([]byte("unknown parent"))
+	invalid := [][]*chainhash.Hash{
+		{tips[1]},
+		{tips[0], &unknown},
+		{tips[0], tips[0]},
+		{},
+	}
+	for _, parents := range invalid {
+		_, err := node.GenerateWithParents(1, parents)
+		if err == nil {
+			t.Fatalf("GenerateWithParents(%v) didn't return an error",
+				parents)
+		}
+	}
+}
+
+// TestCheckPruneDag tests that prunes which would remove a parent of a
+// remaining block, or a dag tip, are refused.
+func TestCheckPruneDag(t *testing.T) {
+	var miners []*rpctest.Harness
+	for i := 0; i < 2; i++ {
+		miner, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+		if err != nil {
+			t.Fatalf("unable to create mining node %v: %v", i, err)
+		}
+
+		if err := miner.SetUp(false, 0); err != nil {
+			t.Fatalf("unable to complete mining node %v setup: %v", i, err)
+		}
+
+		miners = append(miners, miner)
+	}
+	defer func() {
+		for _, miner := range miners {
+			_ = miner.TearDown()
+		}
+	}()
+
+	// isRefused returns true if err is the error returned for prunes that
+	// break parent references.
+	isRefused := func(err error) bool {
+		rpcErr, ok := err.(*soterjson.RPCError)
+		return ok && rpcErr.Code == soterjson.ErrRPCInvalidParameter
+	}
+
+	// Mine a short branch on the first miner and a longer one on the
+	// second, so that the first miner ends up with a tip at height 1 and
+	// another at height 4 once they're connected.
+	var generated []*chainhash.Hash
+	for i, miner := range miners {
+		hashes, err := miner.Node.Generate(uint32(i*3 + 1))
+		if err != nil {
+			t.Fatalf("miner %v failed to generate blocks: %v", i, err)
+		}
+		generated = append(generated, hashes...)
+	}
+	if err := rpctest.ConnectNodes(miners); err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+	for _, hash := range generated {
+		if err := miners[0].WaitForBlock(hash, time.Second*10); err != nil {
+			t.Fatalf("block didn't sync to miner 0: %v", err)
+		}
+	}
+
+	node := miners[0].Node
+	if err := node.CheckPruneDag(0); err != nil {
+		t.Fatalf("CheckPruneDag(0) failed: %v", err)
+	}
+	if err := node.CheckPruneDag(1); err != nil {
+		t.Fatalf("CheckPruneDag(1) failed: %v", err)
+	}
+
+	// The tip at height 1 can't be pruned.
+	if err := node.CheckPruneDag(3); !isRefused(err) {
+		t.Fatalf("CheckPruneDag didn't refuse pruning a tip: %v", err)
+	}
+	info, err := node.GetPruneInfo()
+	if err != nil {
+		t.Fatalf("GetPruneInfo failed: %v", err)
+	}
+	if info.Pruned || info.PruneHeight != 0 || info.MaxPruneHeight != 1 {
+		t.Fatalf("wrong prune info with a tip at height 1: %+v", info)
+	}
+	if info.ReclaimableBytes <= 0 {
+		t.Fatalf("GetPruneInfo reported %d reclaimable bytes, wanted "+
+			"the size of the genesis block", info.ReclaimableBytes)
+	}
+
+	// Merge the branches. The new block at height 5 references the block
+	// at height 1, so pruning below heights 2 to 4 would remove it.
+	if _, err := node.Generate(1); err != nil {
+		t.Fatalf("unable to generate merging block: %v", err)
+	}
+	for height := int32(2); height <= 4; height++ {
+		if err := node.CheckPruneDag(height); !isRefused(err) {
+			t.Fatalf("CheckPruneDag(%d) didn't refuse removing a "+
+				"referenced parent: %v", height, err)
+		}
+	}
+	if err := node.CheckPruneDag(5); err != nil {
+		t.Fatalf("CheckPruneDag(5) failed: %v", err)
+	}
+
+	prevReclaimable := info.ReclaimableBytes
+	info, err = node.GetPruneInfo()
+	if err != nil {
+		t.Fatalf("GetPruneInfo failed: %v", err)
+	}
+	if info.MaxPruneHeight != 5 {
+		t.Fatalf("GetPruneInfo reported max prune height %d after "+
+			"merging, wanted 5", info.MaxPruneHeight)
+	}
+	if info.ReclaimableBytes <= prevReclaimable {
+		t.Fatalf("GetPruneInfo reported %d reclaimable bytes after "+
+			"merging, wanted more than %d", info.ReclaimableBytes,
+			prevReclaimable)
+	}
+
+	// Heights past the max height of the dag are refused.
+	if err := node.CheckPruneDag(6); !isRefused(err) {
+		t.Fatalf("CheckPruneDag didn't refuse a height past the dag: %v",
+			err)
+	}
+}
+
+// TestGetOrphanBlocks tests that a block delivered before its parent is
+// reported as an orphan waiting on the parent, and that the orphan pool can be
+// cleared.
+func TestGetOrphanBlocks(t *testing.T) {
+	var miners []*rpctest.Harness
+	for i := 0; i < 2; i++ {
+		miner, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+		if err != nil {
+			t.Fatalf("unable to create mining node %v: %v", i, err)
+		}
+
+		if err := miner.SetUp(false, 0); err != nil {
+			t.Fatalf("unable to complete mining node %v setup: %v", i, err)
+		}
+
+		miners = append(miners, miner)
+	}
+	defer func() {
+		for _, miner := range miners {
+			_ = miner.TearDown()
+		}
+	}()
+
+	// Mine a parent and child on the first miner, and deliver only the
+	// child to the second.
+	hashes, err := miners[0].Node.Generate(2)
+	if err != nil {
+		t.Fatalf("unable to generate blocks: %v", err)
+	}
+	parent, child := hashes[0], hashes[1]
+	block, err := miners[0].Node.GetBlock(child)
+	if err != nil {
+		t.Fatalf("unable to get block %v: %v", child, err)
+	}
+
+	node := miners[1].Node
+	if err := node.SubmitBlock(soterutil.NewBlock(block), nil); err != nil {
+		t.Fatalf("unable to submit child block: %v", err)
+	}
+
+	orphans, err := node.GetOrphanBlocks()
+	if err != nil {
+		t.Fatalf("GetOrphanBlocks failed: %v", err)
+	}
+	if orphans.Total != 1 || len(orphans.Orphans) != 1 {
+		t.Fatalf("GetOrphanBlocks returned %+v, wanted a single orphan",
+			orphans)
+	}
+	orphan := orphans.Orphans[0]
+	if orphan.Hash != child.String() {
+		t.Fatalf("orphan has hash %v, wanted %v", orphan.Hash, child)
+	}
+	if len(orphan.MissingParents) != 1 ||
+		orphan.MissingParents[0] != parent.String() {
+
+		t.Fatalf("orphan has missing parents %v, wanted %v",
+			orphan.MissingParents, parent)
+	}
+
+	cleared, err := node.ClearOrphans()
+	if err != nil {
+		t.Fatalf("ClearOrphans failed: %v", err)
+	}
+	if cleared != 1 {
+		t.Fatalf("ClearOrphans removed %d orphans, wanted 1", cleared)
+	}
+
+	orphans, err = node.GetOrphanBlocks()
+	if err != nil {
+		t.Fatalf("GetOrphanBlocks failed: %v", err)
+	}
+	if orphans.Total != 0 || len(orphans.Orphans) != 0 {
+		t.Fatalf("GetOrphanBlocks returned %+v after ClearOrphans, "+
+			"wanted no orphans", orphans)
+	}
+}
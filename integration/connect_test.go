@@ -11,6 +11,7 @@ import (
 
 	"github.com/soteria-dag/soterd/chaincfg"
 	"github.com/soteria-dag/soterd/integration/rpctest"
+	"github.com/soteria-dag/soterd/rpcclient"
 )
 
 func TestConnection(t *testing.T) {
@@ -177,4 +178,194 @@ func TestGetListenAddrs(t *testing.T) {
 	if !match {
 		t.Fatalf("listen address %s not found in getlistenaddrs response %s", m.P2PAddress(), resp.P2P)
 	}
-}
\ No newline at end of file
+}
+
+// newConnectMiners creates and sets up minerCount miners, without connecting them to one another.
+// The returned function tears the miners down.
+func newConnectMiners(t *testing.T, minerCount int) ([]*rpctest.Harness, func()) {
+	var miners []*rpctest.Harness
+
+	// Set to debug or trace to produce more logging output from miners
+	extraArgs := []string{
+		//"--debuglevel=debug",
+	}
+
+	tearDown := func() {
+		for _, miner := range miners {
+			_ = (*miner).TearDown()
+		}
+	}
+
+	for i := 0; i < minerCount; i++ {
+		miner, err := rpctest.New(&chaincfg.SimNetParams, nil, extraArgs, false)
+		if err != nil {
+			tearDown()
+			t.Fatalf("unable to create mining node %v: %v", i, err)
+		}
+
+		if err := miner.SetUp(false, 0); err != nil {
+			tearDown()
+			t.Fatalf("unable to complete mining node %v setup: %v", i, err)
+		}
+
+		miners = append(miners, miner)
+	}
+
+	return miners, tearDown
+}
+
+// assertPeerCount fails the test if the miner doesn't have the expected number of peers
+func assertPeerCount(t *testing.T, i int, miner *rpctest.Harness, expected int) {
+	peers, err := miner.Node.GetPeerInfo()
+	if err != nil {
+		t.Fatalf("failed to call getpeerinfo on miner %d: %s", i, err)
+	}
+
+	if len(peers) != expected {
+		t.Fatalf("miner %d has %d peers, expected %d", i, len(peers), expected)
+	}
+}
+
+func TestConnectionRing(t *testing.T) {
+	miners, tearDown := newConnectMiners(t, 4)
+	defer tearDown()
+
+	err := rpctest.ConnectNodesRing(miners)
+	if err != nil {
+		t.Fatalf("unable to connect nodes in a ring: %v", err)
+	}
+
+	// Each miner should only be connected to its neighbours in the ring
+	for i, miner := range miners {
+		assertPeerCount(t, i, miner, 2)
+	}
+}
+
+func TestConnectionStar(t *testing.T) {
+	miners, tearDown := newConnectMiners(t, 4)
+	defer tearDown()
+
+	center := miners[0]
+	others := miners[1:]
+
+	err := rpctest.ConnectNodesStar(center, others)
+	if err != nil {
+		t.Fatalf("unable to connect nodes in a star: %v", err)
+	}
+
+	// The center miner should be connected to every other miner, and the other miners only to the center
+	assertPeerCount(t, 0, center, len(others))
+	for i, miner := range others {
+		assertPeerCount(t, i+1, miner, 1)
+	}
+}
+
+func TestPartitionNetwork(t *testing.T) {
+	miners, tearDown := newConnectMiners(t, 4)
+	defer tearDown()
+
+	err := rpctest.ConnectNodes(miners)
+	if err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+
+	groups := [][]*rpctest.Harness{
+		{miners[0], miners[1]},
+		{miners[2], miners[3]},
+	}
+	err = rpctest.PartitionNetwork(groups)
+	if err != nil {
+		t.Fatalf("unable to partition network: %v", err)
+	}
+
+	// Each miner should only be connected to the other miner in its group
+	for i, miner := range miners {
+		assertPeerCount(t, i, miner, 1)
+	}
+
+	for _, group := range groups {
+		connected, err := rpctest.IsConnected(group[0], group[1])
+		if err != nil {
+			t.Fatalf("Couldn't determine if %v was connected to %v", group[0], group[1])
+		}
+		if connected {
+			continue
+		}
+
+		connected, err = rpctest.IsConnected(group[1], group[0])
+		if err != nil {
+			t.Fatalf("Couldn't determine if %v was connected to %v", group[1], group[0])
+		}
+		if !connected {
+			t.Fatalf("node %v isn't connected to %v", group[1], group[0])
+		}
+	}
+}
+
+// waitForConnectionCount fails the test if the miner's connection count doesn't reach the expected value in time
+func waitForConnectionCount(t *testing.T, i int, miner *rpctest.Harness, expected int64) {
+	var count int64
+	var err error
+	for start := time.Now(); time.Since(start) < time.Second*10; time.Sleep(time.Millisecond * 100) {
+		count, err = miner.Node.GetConnectionCount()
+		if err != nil {
+			t.Fatalf("failed to call getconnectioncount on miner %d: %s", i, err)
+		}
+		if count == expected {
+			return
+		}
+	}
+
+	t.Fatalf("miner %d has %d connections, expected %d", i, count, expected)
+}
+
+func TestAddDisconnectNode(t *testing.T) {
+	miners, tearDown := newConnectMiners(t, 2)
+	defer tearDown()
+
+	for i, miner := range miners {
+		waitForConnectionCount(t, i, miner, 0)
+	}
+	addr := miners[1].P2PAddress()
+
+	// A one-time connection is non-persistent, so it's dropped with DisconnectNode
+	err := miners[0].Node.AddNode(addr, rpcclient.ANOneTry)
+	if err != nil {
+		t.Fatalf("unable to add one-time connection: %v", err)
+	}
+	for i, miner := range miners {
+		waitForConnectionCount(t, i, miner, 1)
+	}
+
+	err = miners[0].Node.DisconnectNode(addr)
+	if err != nil {
+		t.Fatalf("unable to disconnect node: %v", err)
+	}
+	for i, miner := range miners {
+		waitForConnectionCount(t, i, miner, 0)
+	}
+
+	// A persistent connection is dropped by removing it
+	err = miners[0].Node.AddNode(addr, rpcclient.ANAdd)
+	if err != nil {
+		t.Fatalf("unable to add persistent connection: %v", err)
+	}
+	for i, miner := range miners {
+		waitForConnectionCount(t, i, miner, 1)
+	}
+
+	err = miners[0].Node.AddNode(addr, rpcclient.ANRemove)
+	if err != nil {
+		t.Fatalf("unable to remove persistent connection: %v", err)
+	}
+	for i, miner := range miners {
+		waitForConnectionCount(t, i, miner, 0)
+	}
+
+	// Unknown commands are rejected by the client
+	err = miners[0].Node.AddNode(addr, rpcclient.AddNodeCommand("connect"))
+	if err != rpcclient.ErrInvalidAddNodeCommand {
+		t.Fatalf("AddNode with an invalid command returned %v, expected %v",
+			err, rpcclient.ErrInvalidAddNodeCommand)
+	}
+}
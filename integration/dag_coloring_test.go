@@ -12,7 +12,9 @@ package integration
 import (
 	"github.com/soteria-dag/soterd/chaincfg"
 	"github.com/soteria-dag/soterd/integration/rpctest"
+	"github.com/soteria-dag/soterd/soterjson"
 	"testing"
+	"time"
 )
 
 // TestGetDAGColoring tests getcoloring RPC call
@@ -51,5 +53,81 @@ func TestGetDAGColoring(t *testing.T) {
 			t.Fatalf("Expecting block %v to be blue", dagNode.Hash)
 		}
 
+		if dagNode.Order != i {
+			t.Fatalf("Expecting block %v to have order %d, got %d", dagNode.Hash, i, dagNode.Order)
+		}
+
+	}
+}
+
+// TestDAGColoringConverges tests that connected miners agree on the dag
+// coloring and ordering once their dags are in sync.
+func TestDAGColoringConverges(t *testing.T) {
+	var miners []*rpctest.Harness
+	for i := 0; i < 2; i++ {
+		miner, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+		if err != nil {
+			t.Fatalf("unable to create mining node %d: %v", i, err)
+		}
+		if err := miner.SetUp(false, 0); err != nil {
+			t.Fatalf("unable to complete mining node %d setup: %v", i, err)
+		}
+		miners = append(miners, miner)
+	}
+	defer func() {
+		for _, miner := range miners {
+			_ = miner.TearDown()
+		}
+	}()
+
+	// Mine on each miner before connecting them, so that the dag has
+	// parallel blocks to order.
+	for i, miner := range miners {
+		if _, err := miner.Node.Generate(2); err != nil {
+			t.Fatalf("miner %d failed to generate blocks: %v", i, err)
+		}
+	}
+
+	if err := rpctest.ConnectNodes(miners); err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+
+	// Generate a block on each miner in turn to trigger sync, since
+	// they're at the same height.
+	for i, miner := range miners {
+		if _, err := miner.Node.Generate(1); err != nil {
+			t.Fatalf("miner %d failed to generate block: %v", i, err)
+		}
+	}
+	if err := rpctest.WaitForDAG(miners, time.Second*20); err != nil {
+		t.Fatalf("miners didn't sync dag: %v", err)
+	}
+
+	var colorings [][]*soterjson.GetDAGColoringResult
+	for i, miner := range miners {
+		coloring, err := miner.Node.GetDAGColoring()
+		if err != nil {
+			t.Fatalf("miner %d failed to get dag coloring: %v", i, err)
+		}
+		colorings = append(colorings, coloring)
+	}
+
+	// Genesis, two blocks from each miner, and the blocks mined to
+	// trigger sync.
+	want := colorings[0]
+	if len(want) != 7 {
+		t.Fatalf("miner 0 dag coloring has %d blocks, wanted 7", len(want))
+	}
+	for i, coloring := range colorings[1:] {
+		if len(coloring) != len(want) {
+			t.Fatalf("miner %d dag coloring has %d blocks, miner 0 has %d",
+				i+1, len(coloring), len(want))
+		}
+		for j, block := range coloring {
+			if *block != *want[j] {
+				t.Fatalf("miner %d dag coloring differs from miner 0 at "+
+					"order %d: got %+v, wanted %+v", i+1, j, block, want[j])
+			}
+		}
 	}
 }
@@ -28,7 +28,7 @@ func countDAGBlocks(node *rpctest.Harness) (int, error) {
 	}
 
 	for height := int32(0); height <= tips.MaxHeight; height++ {
-		hashes, err := node.Node.GetBlockHash(int64(height))
+		hashes, err := node.Node.GetDagBlockHashes(height)
 		if err != nil {
 			return count, err
 		}
@@ -144,4 +144,88 @@ func TestDAGGen(t *testing.T) {
 		}
 		t.Fatalf(err.Error())
 	}
-}
\ No newline at end of file
+}
+// TestFastBlockInterval tests that miners with a tiny target time per block
+// produce a dag with many blocks sharing the same height.
+func TestFastBlockInterval(t *testing.T) {
+	var miners []*rpctest.Harness
+
+	// Number of miners to spawn
+	minerCount := 4
+	// Number of blocks to generate on each miner
+	blockCount := 20
+	// Minimum number of heights expected to have sibling blocks
+	minSiblingHeights := 3
+
+	opts := &rpctest.HarnessOpts{
+		TargetTimePerBlock: time.Millisecond * 10,
+	}
+	defer func() {
+		for _, miner := range miners {
+			_ = (*miner).TearDown()
+		}
+	}()
+
+	for i := 0; i < minerCount; i++ {
+		miner, err := rpctest.NewWithOpts(&chaincfg.SimNetParams, nil, nil, opts)
+		if err != nil {
+			t.Fatalf("unable to create mining node %v: %v", i, err)
+		}
+
+		if err := miner.SetUp(false, 0); err != nil {
+			t.Fatalf("unable to complete mining node %v setup: %v", i, err)
+		}
+
+		if miner.ActiveNet.TargetTimePerBlock != opts.TargetTimePerBlock {
+			t.Fatalf("miner %v has target time per block %v, wanted %v",
+				i, miner.ActiveNet.TargetTimePerBlock,
+				opts.TargetTimePerBlock)
+		}
+
+		miners = append(miners, miner)
+	}
+
+	err := rpctest.ConnectNodes(miners)
+	if err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+
+	// Generate blocks on all the miners at once, so that they race to mine
+	// at the same heights.
+	var futures []rpcclient.FutureGenerateResult
+	for _, miner := range miners {
+		futures = append(futures, miner.Node.GenerateAsync(uint32(blockCount)))
+	}
+	for i, future := range futures {
+		if _, err := future.Receive(); err != nil {
+			t.Fatalf("failed to wait for blocks to generate on node %v: %v", i, err)
+		}
+	}
+
+	if err := rpctest.WaitForDAG(miners, time.Minute); err != nil {
+		t.Fatalf("miners didn't sync: %v", err)
+	}
+
+	tips, err := miners[0].Node.GetDAGTips()
+	if err != nil {
+		t.Fatalf("unable to get dag tips: %v", err)
+	}
+
+	siblingHeights := 0
+	for height := int32(1); height <= tips.MaxHeight; height++ {
+		hashes, err := miners[0].Node.GetDagBlockHashes(height)
+		if err != nil {
+			t.Fatalf("unable to get blocks at height %v: %v", height, err)
+		}
+
+		if len(hashes) > 1 {
+			siblingHeights++
+		}
+	}
+
+	t.Logf("found %v heights with sibling blocks, of %v", siblingHeights, tips.MaxHeight)
+	if siblingHeights < minSiblingHeights {
+		t.Fatalf("found %v heights with sibling blocks, wanted at least %v",
+			siblingHeights, minSiblingHeights)
+	}
+}
@@ -10,14 +10,27 @@ package integration
 
 import (
 	"bytes"
+	"context"
+	"encoding/hex"
 	"fmt"
+	"math/big"
 	"os"
 	"runtime/debug"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/soteria-dag/soterd/blockdag"
 	"github.com/soteria-dag/soterd/chaincfg"
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
 	"github.com/soteria-dag/soterd/integration/rpctest"
+	"github.com/soteria-dag/soterd/mempool"
+	"github.com/soteria-dag/soterd/rpcclient"
+	"github.com/soteria-dag/soterd/soterec"
+	"github.com/soteria-dag/soterd/soterjson"
 	"github.com/soteria-dag/soterd/soterutil"
+	"github.com/soteria-dag/soterd/txscript"
+	"github.com/soteria-dag/soterd/wire"
 )
 
 func testGetBestBlock(r *rpctest.Harness, t *testing.T) {
@@ -86,6 +99,29 @@ func testGetDAGTips(r *rpctest.Harness, t *testing.T) {
 			t.Fatalf("Missing tip %v from generated block parents %v", tipHashString, newBlock.Parents.ParentHashes())
 		}
 	}
+
+	// The tip info should describe the same tips, sorted by height then hash.
+	tipInfo, err := r.Node.GetDAGTipInfo()
+	if err != nil {
+		t.Fatalf("Call to `getdagtips` failed: %v", err)
+	}
+	if len(tipInfo) == 0 {
+		t.Fatalf("No tip info returned by `getdagtips`")
+	}
+	for i, tip := range tipInfo {
+		if len(tip.Work) == 0 {
+			t.Fatalf("Tip %v is missing work", tip.Hash)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := tipInfo[i-1]
+		if prev.Height > tip.Height ||
+			(prev.Height == tip.Height && prev.Hash >= tip.Hash) {
+			t.Fatalf("Tip info not sorted by height then hash: %v "+
+				"before %v", prev, tip)
+		}
+	}
 }
 
 func testGetBlockCount(r *rpctest.Harness, t *testing.T) {
@@ -110,6 +146,130 @@ func testGetBlockCount(r *rpctest.Harness, t *testing.T) {
 	}
 }
 
+func testGenerateAndConfirm(r *rpctest.Harness, t *testing.T) {
+	currentCount, err := r.Node.GetBlockCount()
+	if err != nil {
+		t.Fatalf("Unable to get block count: %v", err)
+	}
+
+	hashes, err := r.Node.GenerateAndConfirm(2, time.Second*30)
+	if err != nil {
+		t.Fatalf("Unable to generate and confirm blocks: %v", err)
+	}
+	if len(hashes) != 2 {
+		t.Fatalf("Wrong number of generated block hashes. Got %v, "+
+			"wanted 2", len(hashes))
+	}
+
+	// The count should already include the generated blocks.
+	newCount, err := r.Node.GetBlockCount()
+	if err != nil {
+		t.Fatalf("Unable to get block count: %v", err)
+	}
+	if newCount < currentCount+2 {
+		t.Fatalf("Block count incorrect. Got %v should be at least %v",
+			newCount, currentCount+2)
+	}
+}
+
+func testGetMempoolInfo(r *rpctest.Harness, t *testing.T) {
+	before, err := r.Node.GetMempoolInfo()
+	if err != nil {
+		t.Fatalf("Call to `getmempoolinfo` failed: %v", err)
+	}
+
+	txid, err := spendCoinbase(r, t, soterutil.Amount(1000))
+	if err != nil {
+		t.Fatalf("Unable to send transaction: %v", err)
+	}
+
+	after, err := r.Node.GetMempoolInfo()
+	if err != nil {
+		t.Fatalf("Call to `getmempoolinfo` failed: %v", err)
+	}
+	if after.Size != before.Size+1 {
+		t.Fatalf("Mempool size incorrect after sending tx %v. Got %v, "+
+			"wanted %v", txid, after.Size, before.Size+1)
+	}
+	if after.Bytes <= before.Bytes {
+		t.Fatalf("Mempool bytes didn't grow after sending tx %v. Got %v, "+
+			"had %v", txid, after.Bytes, before.Bytes)
+	}
+	wantFee := mempool.DefaultMinRelayTxFee.ToSOTO()
+	if after.MinFee != wantFee {
+		t.Fatalf("Mempool min fee incorrect. Got %v, wanted %v",
+			after.MinFee, wantFee)
+	}
+}
+
+func testGetRawMempoolVerbose(r *rpctest.Harness, t *testing.T) {
+	txid, err := spendCoinbase(r, t, soterutil.Amount(1000))
+	if err != nil {
+		t.Fatalf("Unable to send transaction: %v", err)
+	}
+
+	entries, err := r.Node.GetRawMempoolVerbose()
+	if err != nil {
+		t.Fatalf("Call to `getrawmempool` (verbose) failed: %v", err)
+	}
+	entry, ok := entries[txid.String()]
+	if !ok {
+		t.Fatalf("Sent tx %v missing from verbose mempool results", txid)
+	}
+	if entry.Size <= 0 {
+		t.Fatalf("Verbose mempool entry for tx %v has invalid size %v",
+			txid, entry.Size)
+	}
+	if entry.Fee <= 0 {
+		t.Fatalf("Verbose mempool entry for tx %v has invalid fee %v",
+			txid, entry.Fee)
+	}
+	if entry.Time <= 0 {
+		t.Fatalf("Verbose mempool entry for tx %v has invalid time %v",
+			txid, entry.Time)
+	}
+
+	// The non-verbose form should list the same transaction.
+	hashes, err := r.Node.GetRawMempool()
+	if err != nil {
+		t.Fatalf("Call to `getrawmempool` failed: %v", err)
+	}
+	found := false
+	for _, hash := range hashes {
+		if hash.IsEqual(txid) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatalf("Sent tx %v missing from mempool hashes", txid)
+	}
+}
+
+func testWaitForBlock(r *rpctest.Harness, t *testing.T) {
+	hashes, err := r.Node.Generate(1)
+	if err != nil {
+		t.Fatalf("Unable to generate block: %v", err)
+	}
+
+	err = r.WaitForBlock(hashes[0], time.Second*10)
+	if err != nil {
+		t.Fatalf("WaitForBlock failed for generated block %v: %v",
+			hashes[0], err)
+	}
+
+	// Once a child is generated the block is no longer a tip, but is still
+	// part of the dag.
+	if _, err := r.Node.Generate(1); err != nil {
+		t.Fatalf("Unable to generate block: %v", err)
+	}
+	err = r.WaitForBlock(hashes[0], time.Second*10)
+	if err != nil {
+		t.Fatalf("WaitForBlock failed for buried block %v: %v",
+			hashes[0], err)
+	}
+}
+
 func testGetBlockHash(r *rpctest.Harness, t *testing.T) {
 	// Create a new block connecting to the current tip.
 	generatedBlockHashes, err := r.Node.Generate(1)
@@ -127,22 +287,15 @@ func testGetBlockHash(r *rpctest.Harness, t *testing.T) {
 		t.Fatalf("call to getbestblock cailed: %v", err)
 	}
 
-	blockHashes, err := r.Node.GetBlockHash(int64(bestHeight))
+	blockHash, err := r.Node.GetBlockHash(int64(bestHeight))
 	if err != nil {
 		t.Fatalf("Call to `getblockhash` failed: %v", err)
 	}
 
-	// Block hashes should match newly created block.
-	foundHash := false
-	for _, blockHash := range blockHashes {
-		if bytes.Equal(generatedBlockHashes[0][:], blockHash[:]) {
-			foundHash = true
-			break
-		}
-	}
-	if !foundHash {
-		t.Fatalf("Generated hash not found. Wanted %v in "+
-			"hashes %v", generatedBlockHashes[0][:], blockHashes)
+	// Block hash should match newly created block.
+	if !bytes.Equal(generatedBlockHashes[0][:], blockHash[:]) {
+		t.Fatalf("Block hashes do not match. Returned hash %v, wanted "+
+			"hash %v", blockHash, generatedBlockHashes[0][:])
 	}
 }
 
@@ -161,75 +314,2006 @@ func testRenderDag(r *rpctest.Harness, t *testing.T) {
 	}
 }
 
-var rpcTestCases = []rpctest.HarnessTestCase{
-	testGetBestBlock,
-	testGetBlockCount,
-	testGetBlockHash,
-	testGetDAGTips,
-	testRenderDag,
-}
-
-var primaryHarness *rpctest.Harness
+func testBatchGetBlockCount(r *rpctest.Harness, t *testing.T) {
+	const numRequests = 50
 
-func TestMain(m *testing.M) {
-	var err error
+	wantCount, err := r.Node.GetBlockCount()
+	if err != nil {
+		t.Fatalf("Unable to get block count: %v", err)
+	}
 
-	// In order to properly test scenarios on as if we were on mainnet,
-	// ensure that non-standard transactions aren't accepted into the
-	// mempool or relayed.
-	soterdCfg := []string{"--rejectnonstd"}
-	primaryHarness, err = rpctest.New(&chaincfg.SimNetParams, nil, soterdCfg, false)
+	rpcConf := r.RPCConfig()
+	batch, err := rpcclient.NewBatch(&rpcConf)
 	if err != nil {
-		fmt.Println("unable to create primary harness: ", err)
-		os.Exit(1)
+		t.Fatalf("Unable to create batch client: %v", err)
 	}
+	defer batch.Shutdown()
 
-	// Initialize the primary mining node with a chain of length 125,
-	// providing 25 mature coinbases to allow spending from for testing
-	// purposes.
-	if err := primaryHarness.SetUp(true, 25); err != nil {
-		fmt.Println("unable to setup test chain: ", err)
+	// Queue the requests, including one which will fail, to ensure errors
+	// are only returned for the failing request.
+	futures := make([]rpcclient.FutureGetBlockCountResult, 0, numRequests)
+	for i := 0; i < numRequests; i++ {
+		futures = append(futures, batch.GetBlockCountAsync())
+	}
+	badFuture := batch.GetBlockHashAsync(-1)
 
-		// Even though the harness was not fully setup, it still needs
-		// to be torn down to ensure all resources such as temp
-		// directories are cleaned up.  The error is intentionally
-		// ignored since this is already an error path and nothing else
-		// could be done about it anyways.
-		_ = primaryHarness.TearDown()
-		os.Exit(1)
+	if err := batch.Send(); err != nil {
+		t.Fatalf("Unable to send batch: %v", err)
 	}
 
-	exitCode := m.Run()
+	for i, f := range futures {
+		count, err := f.Receive()
+		if err != nil {
+			t.Fatalf("Batched `getblockcount` #%d failed: %v", i, err)
+		}
+		if count != wantCount {
+			t.Fatalf("Batched `getblockcount` #%d incorrect. Got %v "+
+				"should be %v", i, count, wantCount)
+		}
+	}
 
-	// Clean up any active harnesses that are still currently running.This
-	// includes removing all temporary directories, and shutting down any
-	// created processes.
-	if err := rpctest.TearDownAll(); err != nil {
-		fmt.Println("unable to tear down all harnesses: ", err)
-		os.Exit(1)
+	if _, err := badFuture.Receive(); err == nil {
+		t.Fatalf("Batched `getblockhash` for invalid height " +
+			"succeeded when it should have failed")
 	}
 
-	os.Exit(exitCode)
+	// Sending an empty batch should be a no-op.
+	if err := batch.Send(); err != nil {
+		t.Fatalf("Unable to send empty batch: %v", err)
+	}
 }
 
-func TestRpcServer(t *testing.T) {
-	var currentTestNum int
-	defer func() {
-		// If one of the integration tests caused a panic within the main
-		// goroutine, then tear down all the harnesses in order to avoid
-		// any leaked soterd processes.
-		if r := recover(); r != nil {
-			fmt.Println("recovering from test panic: ", r)
-			if err := rpctest.TearDownAll(); err != nil {
-				fmt.Println("unable to tear down all harnesses: ", err)
+func testGetDagWork(r *rpctest.Harness, t *testing.T) {
+	prevWork, err := r.Node.GetDagWork()
+	if err != nil {
+		t.Fatalf("Call to `getdagtips` failed: %v", err)
+	}
+
+	// The cumulative work of the dag should strictly increase with each
+	// block mined, since every block mined on top of the only tip is blue.
+	var firstHash *chainhash.Hash
+	var firstDagWork string
+	for i := 0; i < 3; i++ {
+		generatedBlockHashes, err := r.Node.Generate(1)
+		if err != nil {
+			t.Fatalf("Unable to generate block: %v", err)
+		}
+
+		work, err := r.Node.GetDagWork()
+		if err != nil {
+			t.Fatalf("Call to `getdagtips` failed: %v", err)
+		}
+		if work.Cmp(prevWork) <= 0 {
+			t.Fatalf("Dag work didn't increase after mining a block: "+
+				"got %v, previously %v", work, prevWork)
+		}
+
+		block, err := r.Node.GetBlockVerbose(generatedBlockHashes[0])
+		if err != nil {
+			t.Fatalf("Call to `getblock` failed: %v", err)
+		}
+
+		// The dag work should have increased by the new block's work,
+		// and the block's cumulative work should match the dag's.
+		blockWork, ok := new(big.Int).SetString(block.Work, 16)
+		if !ok {
+			t.Fatalf("Invalid block work %q", block.Work)
+		}
+		if diff := new(big.Int).Sub(work, prevWork); diff.Cmp(blockWork) != 0 {
+			t.Fatalf("Dag work increased by %v, wanted block work %v",
+				diff, blockWork)
+		}
+		dagWork, ok := new(big.Int).SetString(block.DagWork, 16)
+		if !ok {
+			t.Fatalf("Invalid block dag work %q", block.DagWork)
+		}
+		if dagWork.Cmp(work) != 0 {
+			t.Fatalf("Block dag work %v doesn't match dag work %v",
+				dagWork, work)
+		}
+
+		if firstHash == nil {
+			firstHash = generatedBlockHashes[0]
+			firstDagWork = block.DagWork
+		}
+		prevWork = work
+	}
+
+	// The dag work of a block is fixed once it connects, so it shouldn't
+	// change as more blocks are mined on top of it.
+	block, err := r.Node.GetBlockVerbose(firstHash)
+	if err != nil {
+		t.Fatalf("Call to `getblock` failed: %v", err)
+	}
+	if block.DagWork != firstDagWork {
+		t.Fatalf("Block dag work changed as the dag grew: got %v, "+
+			"previously %v", block.DagWork, firstDagWork)
+	}
+}
+
+func testEstimateFee(r *rpctest.Harness, t *testing.T) {
+	addr, err := r.NewAddress()
+	if err != nil {
+		t.Fatalf("unable to get new address: %v", err)
+	}
+	addrScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("unable to generate pkscript to addr: %v", err)
+	}
+
+	// Mine transactions at varying fee rates, so the estimator has data
+	// for the next few blocks.
+	feeRates := []soterutil.Amount{10, 50, 100}
+	for i := 0; i < 3; i++ {
+		for _, feeRate := range feeRates {
+			output := wire.NewTxOut(1000, addrScript)
+			_, err := r.SendOutputs([]*wire.TxOut{output}, feeRate)
+			if err != nil {
+				t.Fatalf("Unable to send transaction: %v", err)
 			}
-			t.Fatalf("test #%v panicked: %s", currentTestNum, debug.Stack())
 		}
-	}()
+		if _, err := r.Node.Generate(1); err != nil {
+			t.Fatalf("Unable to generate block: %v", err)
+		}
+	}
 
-	for _, testCase := range rpcTestCases {
-		testCase(primaryHarness, t)
+	// Allowing more blocks for a transaction to be mined shouldn't raise
+	// the estimated fee.
+	prevFee, err := r.Node.EstimateFee(1)
+	if err != nil {
+		t.Fatalf("Call to `estimatefee` failed: %v", err)
+	}
+	if prevFee <= 0 {
+		t.Fatalf("estimatefee 1 returned %v, expected an estimate", prevFee)
+	}
+	for numBlocks := int64(2); numBlocks <= 10; numBlocks++ {
+		fee, err := r.Node.EstimateFee(numBlocks)
+		if err != nil {
+			t.Fatalf("Call to `estimatefee` failed: %v", err)
+		}
+		if fee > prevFee {
+			t.Fatalf("estimatefee %d returned %v, higher than %v "+
+				"for %d blocks", numBlocks, fee, prevFee,
+				numBlocks-1)
+		}
+		prevFee = fee
+	}
 
-		currentTestNum++
+	estimate, err := r.Node.EstimateSmartFee(2)
+	if err != nil {
+		t.Fatalf("Call to `estimatesmartfee` failed: %v", err)
+	}
+	if estimate.FeeRate == nil || *estimate.FeeRate <= 0 {
+		t.Fatalf("estimatesmartfee returned no estimate, errors: %v",
+			estimate.Errors)
+	}
+	if estimate.Blocks < 2 {
+		t.Fatalf("estimatesmartfee estimate is for %d blocks, below "+
+			"the target of 2", estimate.Blocks)
+	}
+}
+
+func testSignVerifyMessage(r *rpctest.Harness, t *testing.T) {
+	privKey, err := soterec.NewPrivateKey(soterec.S256())
+	if err != nil {
+		t.Fatalf("unable to create private key: %v", err)
+	}
+	wif, err := soterutil.NewWIF(privKey, r.ActiveNet, true)
+	if err != nil {
+		t.Fatalf("unable to encode private key: %v", err)
+	}
+	pubKey, err := soterutil.NewAddressPubKey(wif.SerializePubKey(),
+		r.ActiveNet)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	addr := pubKey.AddressPubKeyHash()
+
+	const message = "soterd signed message test"
+	sig, err := r.Node.SignMessageWithPrivKey(wif.String(), message)
+	if err != nil {
+		t.Fatalf("Call to `signmessagewithprivkey` failed: %v", err)
+	}
+
+	verified, err := r.Node.VerifyMessage(addr, sig, message)
+	if err != nil {
+		t.Fatalf("Call to `verifymessage` failed: %v", err)
+	}
+	if !verified {
+		t.Fatalf("signature %s of message %q didn't verify", sig, message)
+	}
+
+	// A tampered message must not verify against the signature.
+	verified, err = r.Node.VerifyMessage(addr, sig, message+"!")
+	if err != nil {
+		t.Fatalf("Call to `verifymessage` failed: %v", err)
+	}
+	if verified {
+		t.Fatalf("signature %s verified for a tampered message", sig)
+	}
+
+	// A malformed signature is rejected before it's sent.
+	if _, err := r.Node.VerifyMessage(addr, "not base64!", message); err == nil {
+		t.Fatalf("verifymessage accepted a malformed signature")
+	}
+
+	// A key that isn't in wallet import format can't sign.
+	if _, err := r.Node.SignMessageWithPrivKey("invalid", message); err == nil {
+		t.Fatalf("signmessagewithprivkey accepted an invalid private key")
+	}
+}
+
+func testBlockAcceptance(r *rpctest.Harness, t *testing.T) {
+	bestHash, bestHeight, err := r.Node.GetBestBlock()
+	if err != nil {
+		t.Fatalf("Call to `getbestblock` failed: %v", err)
+	}
+	mBlock, err := r.Node.GetBlock(bestHash)
+	if err != nil {
+		t.Fatalf("Call to `getblock` failed: %v", err)
+	}
+	bestBlock := soterutil.NewBlock(mBlock)
+	bestBlock.SetHeight(bestHeight)
+
+	addr, err := r.NewAddress()
+	if err != nil {
+		t.Fatalf("unable to get new address: %v", err)
+	}
+
+	// Create a valid block on top of the best block, and a child of that
+	// block, without submitting either of them.
+	prevHash := blockdag.GenerateTipsHash([]*chainhash.Hash{bestHash})
+	block, err := rpctest.CreateBlock(bestBlock, prevHash, nil,
+		rpctest.BlockVersion, time.Time{}, addr, nil, r.ActiveNet)
+	if err != nil {
+		t.Fatalf("unable to create block: %v", err)
+	}
+	prevHash = blockdag.GenerateTipsHash([]*chainhash.Hash{block.Hash()})
+	child, err := rpctest.CreateBlock(block, prevHash, nil,
+		rpctest.BlockVersion, time.Time{}, addr, nil, r.ActiveNet)
+	if err != nil {
+		t.Fatalf("unable to create child block: %v", err)
+	}
+
+	blockCount, err := r.Node.GetBlockCount()
+	if err != nil {
+		t.Fatalf("Call to `getblockcount` failed: %v", err)
+	}
+
+	result, err := r.Node.TestBlockAcceptance(block.MsgBlock())
+	if err != nil {
+		t.Fatalf("Call to `testblockacceptance` failed: %v", err)
+	}
+	if result.Status != soterjson.BlockAcceptanceAccepted ||
+		result.Hash != block.Hash().String() ||
+		result.Height != bestHeight+1 {
+		t.Fatalf("testblockacceptance returned %+v for a valid block at "+
+			"height %d", result, bestHeight+1)
+	}
+
+	// The child's parent isn't known, so it would be an orphan.
+	result, err = r.Node.TestBlockAcceptance(child.MsgBlock())
+	if err != nil {
+		t.Fatalf("Call to `testblockacceptance` failed: %v", err)
+	}
+	if result.Status != soterjson.BlockAcceptanceOrphan ||
+		result.Reason == "" {
+		t.Fatalf("testblockacceptance returned %+v for a block with an "+
+			"unknown parent", result)
+	}
+
+	// Testing the blocks mustn't have added them to the dag.
+	count, err := r.Node.GetBlockCount()
+	if err != nil {
+		t.Fatalf("Call to `getblockcount` failed: %v", err)
+	}
+	if count != blockCount {
+		t.Fatalf("block count changed from %d to %d after "+
+			"testblockacceptance", blockCount, count)
+	}
+
+	// Once the block is submitted, testing it again reports a duplicate.
+	if err := r.Node.SubmitBlock(block, nil); err != nil {
+		t.Fatalf("Call to `submitblock` failed: %v", err)
+	}
+	result, err = r.Node.TestBlockAcceptance(block.MsgBlock())
+	if err != nil {
+		t.Fatalf("Call to `testblockacceptance` failed: %v", err)
+	}
+	if result.Status != soterjson.BlockAcceptanceRejected {
+		t.Fatalf("testblockacceptance returned %+v for a submitted "+
+			"block", result)
+	}
+}
+
+func testGetRelayPolicy(r *rpctest.Harness, t *testing.T) {
+	policy, err := r.Node.GetRelayPolicy()
+	if err != nil {
+		t.Fatalf("Call to `getrelaypolicy` failed: %v", err)
+	}
+
+	// The primary harness rejects non-standard transactions, and uses the
+	// default OP_RETURN size limit.
+	if policy.AcceptNonStd {
+		t.Fatalf("getrelaypolicy reports non-standard transactions are " +
+			"accepted by a node started with --rejectnonstd")
+	}
+	if policy.MaxOpReturnSize != mempool.DefaultMaxOpReturnSize {
+		t.Fatalf("getrelaypolicy reports a max OP_RETURN size of %d, "+
+			"want %d", policy.MaxOpReturnSize,
+			mempool.DefaultMaxOpReturnSize)
+	}
+}
+
+// testGenerateIdempotent tests that generateidempotent requests repeated with
+// the same token generate the blocks once.
+func testGenerateIdempotent(r *rpctest.Harness, t *testing.T) {
+	const numBlocks = 5
+	startCount, err := r.Node.GetBlockCount()
+	if err != nil {
+		t.Fatalf("unable to get block count: %v", err)
+	}
+
+	// The retry is sent while the blocks of the first request are still
+	// being generated, and another one after they're generated.
+	first := r.Node.GenerateIdempotentAsync("job-1", numBlocks)
+	retry := r.Node.GenerateIdempotentAsync("job-1", numBlocks)
+	hashes, err := first.Receive()
+	if err != nil {
+		t.Fatalf("GenerateIdempotent: %v", err)
+	}
+	if len(hashes) != numBlocks {
+		t.Fatalf("GenerateIdempotent returned %d blocks, want %d",
+			len(hashes), numBlocks)
+	}
+	retryHashes, err := retry.Receive()
+	if err != nil {
+		t.Fatalf("GenerateIdempotent retry: %v", err)
+	}
+	laterHashes, err := r.Node.GenerateIdempotent("job-1", numBlocks)
+	if err != nil {
+		t.Fatalf("GenerateIdempotent later retry: %v", err)
+	}
+	if fmt.Sprint(retryHashes) != fmt.Sprint(hashes) ||
+		fmt.Sprint(laterHashes) != fmt.Sprint(hashes) {
+
+		t.Fatalf("GenerateIdempotent retries returned %v and %v, want "+
+			"%v", retryHashes, laterHashes, hashes)
+	}
+
+	count, err := r.Node.GetBlockCount()
+	if err != nil {
+		t.Fatalf("unable to get block count: %v", err)
+	}
+	if count != startCount+numBlocks {
+		t.Fatalf("block count is %d after generating with the same "+
+			"token three times, want %d", count, startCount+numBlocks)
+	}
+
+	// The token can't be used for a different number of blocks.
+	_, err = r.Node.GenerateIdempotent("job-1", numBlocks+1)
+	if rpcErr, ok := err.(*soterjson.RPCError); !ok ||
+		rpcErr.Code != soterjson.ErrRPCInvalidParameter {
+
+		t.Fatalf("GenerateIdempotent with a reused token returned %v, "+
+			"want error code %d", err, soterjson.ErrRPCInvalidParameter)
+	}
+
+	// Another token generates more blocks.
+	otherHashes, err := r.Node.GenerateIdempotent("job-2", 1)
+	if err != nil {
+		t.Fatalf("GenerateIdempotent with another token: %v", err)
+	}
+	if len(otherHashes) != 1 || otherHashes[0].IsEqual(hashes[0]) {
+		t.Fatalf("GenerateIdempotent with another token returned %v",
+			otherHashes)
+	}
+}
+
+func testWaitForBlockTimeout(r *rpctest.Harness, t *testing.T) {
+	// No block will ever have this hash, so the wait should time out.
+	missing := chainhash.DoubleHashH([]byte("block that never arrives"))
+	defer func(interval time.Duration) {
+		r.BlockPollInterval = interval
+	}(r.BlockPollInterval)
+	r.BlockPollInterval = time.Millisecond * 20
+	timeout := time.Millisecond * 250
+
+	start := time.Now()
+	err := r.WaitForBlock(&missing, timeout)
+	if err == nil {
+		t.Fatalf("WaitForBlock returned for block %v that doesn't exist",
+			missing)
+	}
+	elapsed := time.Since(start)
+	if elapsed < timeout {
+		t.Fatalf("WaitForBlock returned after %v, before the %v timeout: %v",
+			elapsed, timeout, err)
+	}
+	if elapsed > time.Second*5 {
+		t.Fatalf("WaitForBlock took %v to time out", elapsed)
+	}
+}
+
+// testGetDagHashesPerSec tests that getdaghashps estimates a positive hash
+// rate for mined blocks, which is no more than the work of the window spread
+// over a single second.
+func testGetDagHashesPerSec(r *rpctest.Harness, t *testing.T) {
+	const blockCount = 30
+	const window = 20
+
+	hashes, err := r.Node.Generate(blockCount)
+	if err != nil {
+		t.Fatalf("unable to generate blocks: %v", err)
+	}
+
+	header, err := r.Node.GetBlockHeader(hashes[len(hashes)-1])
+	if err != nil {
+		t.Fatalf("unable to get block header: %v", err)
+	}
+	maxWork := new(big.Int).Mul(blockdag.CalcWork(header.Bits),
+		big.NewInt(window))
+
+	hashesPerSec, err := r.Node.GetDagHashesPerSec(window, -1)
+	if err != nil {
+		t.Fatalf("GetDagHashesPerSec failed: %v", err)
+	}
+	if hashesPerSec <= 0 {
+		t.Fatalf("GetDagHashesPerSec estimated %d hashes per second, "+
+			"wanted a positive estimate", hashesPerSec)
+	}
+	if big.NewInt(hashesPerSec).Cmp(maxWork) > 0 {
+		t.Fatalf("GetDagHashesPerSec estimated %d hashes per second, "+
+			"which is more than the %v work of the window",
+			hashesPerSec, maxWork)
+	}
+
+	// There's no estimate for heights past the max height of the dag.
+	tips, err := r.Node.GetDAGTips()
+	if err != nil {
+		t.Fatalf("Call to `getdagtips` failed: %v", err)
+	}
+	hashesPerSec, err = r.Node.GetDagHashesPerSec(window, tips.MaxHeight+1)
+	if err != nil {
+		t.Fatalf("GetDagHashesPerSec failed: %v", err)
+	}
+	if hashesPerSec != 0 {
+		t.Fatalf("GetDagHashesPerSec estimated %d hashes per second "+
+			"past the max height, wanted 0", hashesPerSec)
+	}
+}
+
+func testDecodeDagHeader(r *rpctest.Harness, t *testing.T) {
+	// A header with two parents.
+	parents := []*chainhash.Hash{{0x01}, {0x02}}
+	header := wire.NewBlockHeader(1, blockdag.GenerateTipsHash(parents),
+		&chainhash.Hash{0x03}, 0x207fffff, 7)
+	header.Timestamp = time.Unix(1543949845, 0)
+	subHeader := wire.ParentSubHeader{
+		Version: 1,
+		Size:    int32(len(parents)),
+	}
+	for _, parent := range parents {
+		subHeader.Parents = append(subHeader.Parents,
+			&wire.Parent{Hash: *parent})
+	}
+	var buf bytes.Buffer
+	if err := header.Serialize(&buf); err != nil {
+		t.Fatalf("unable to serialize header: %v", err)
+	}
+	if err := subHeader.Serialize(&buf); err != nil {
+		t.Fatalf("unable to serialize parents: %v", err)
+	}
+	headerHex := hex.EncodeToString(buf.Bytes())
+
+	decoded, err := r.Node.DecodeDagHeader(headerHex)
+	if err != nil {
+		t.Fatalf("Call to `decodedagheader` failed: %v", err)
+	}
+	if decoded.Hash != header.BlockHash().String() {
+		t.Fatalf("decodedagheader returned hash %s, want %s",
+			decoded.Hash, header.BlockHash())
+	}
+	if len(decoded.Parents) != len(parents) {
+		t.Fatalf("decodedagheader returned %d parents, want %d",
+			len(decoded.Parents), len(parents))
+	}
+	for i, parent := range parents {
+		if decoded.Parents[i] != parent.String() {
+			t.Fatalf("decodedagheader returned parent %d %s, want %s",
+				i, decoded.Parents[i], parent)
+		}
+	}
+	if decoded.PreviousHash != header.PrevBlock.String() ||
+		decoded.MerkleRoot != header.MerkleRoot.String() ||
+		decoded.Version != 1 || decoded.Time != 1543949845 ||
+		decoded.Bits != "207fffff" || decoded.Nonce != 7 ||
+		decoded.ParentVersion != 1 {
+
+		t.Fatalf("decodedagheader returned %+v for header %+v",
+			decoded, header)
+	}
+
+	// The hex of a whole block decodes to its header and parents.
+	hashes, err := r.Node.Generate(1)
+	if err != nil {
+		t.Fatalf("unable to generate block: %v", err)
+	}
+	block, err := r.Node.GetBlock(hashes[0])
+	if err != nil {
+		t.Fatalf("unable to get block: %v", err)
+	}
+	buf.Reset()
+	if err := block.Serialize(&buf); err != nil {
+		t.Fatalf("unable to serialize block: %v", err)
+	}
+	decoded, err = r.Node.DecodeDagHeader(hex.EncodeToString(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Call to `decodedagheader` failed: %v", err)
+	}
+	if decoded.Hash != hashes[0].String() {
+		t.Fatalf("decodedagheader returned hash %s for block %s",
+			decoded.Hash, hashes[0])
+	}
+	if len(decoded.Parents) != len(block.Parents.Parents) {
+		t.Fatalf("decodedagheader returned parents %v for block %s with "+
+			"%d parents", decoded.Parents, hashes[0],
+			len(block.Parents.Parents))
+	}
+	for i, parent := range block.Parents.Parents {
+		if decoded.Parents[i] != parent.Hash.String() {
+			t.Fatalf("decodedagheader returned parent %d %s for "+
+				"block %s, want %s", i, decoded.Parents[i],
+				hashes[0], parent.Hash)
+		}
+	}
+
+	// A header without its parents can't be decoded.
+	_, err = r.Node.DecodeDagHeader(headerHex[:160])
+	if rpcErr, ok := err.(*soterjson.RPCError); !ok ||
+		rpcErr.Code != soterjson.ErrRPCDeserialization {
+
+		t.Fatalf("decodedagheader of a header without parents returned "+
+			"%v, want error code %d", err, soterjson.ErrRPCDeserialization)
+	}
+}
+
+var rpcTestCases = []rpctest.HarnessTestCase{
+	testGetBestBlock,
+	testGetBlockCount,
+	testGenerateAndConfirm,
+	testGetMempoolInfo,
+	testGetRawMempoolVerbose,
+	testWaitForBlock,
+	testGetBlockHash,
+	testGetDAGTips,
+	testGetDagWork,
+	testRenderDag,
+	testBatchGetBlockCount,
+	testEstimateFee,
+	testSignVerifyMessage,
+	testBlockAcceptance,
+	testGetRelayPolicy,
+	testGenerateIdempotent,
+	testWaitForBlockTimeout,
+	testGetDagHashesPerSec,
+	testDecodeDagHeader,
+}
+
+var primaryHarness *rpctest.Harness
+
+func TestMain(m *testing.M) {
+	var err error
+
+	// In order to properly test scenarios on as if we were on mainnet,
+	// ensure that non-standard transactions aren't accepted into the
+	// mempool or relayed.
+	soterdCfg := []string{"--rejectnonstd"}
+	primaryHarness, err = rpctest.New(&chaincfg.SimNetParams, nil, soterdCfg, false)
+	if err != nil {
+		fmt.Println("unable to create primary harness: ", err)
+		os.Exit(1)
+	}
+
+	// Initialize the primary mining node with a chain of length 125,
+	// providing 25 mature coinbases to allow spending from for testing
+	// purposes.
+	if err := primaryHarness.SetUp(true, 25); err != nil {
+		fmt.Println("unable to setup test chain: ", err)
+
+		// Even though the harness was not fully setup, it still needs
+		// to be torn down to ensure all resources such as temp
+		// directories are cleaned up.  The error is intentionally
+		// ignored since this is already an error path and nothing else
+		// could be done about it anyways.
+		_ = primaryHarness.TearDown()
+		os.Exit(1)
+	}
+
+	exitCode := m.Run()
+
+	// Clean up any active harnesses that are still currently running.This
+	// includes removing all temporary directories, and shutting down any
+	// created processes.
+	if err := rpctest.TearDownAll(); err != nil {
+		fmt.Println("unable to tear down all harnesses: ", err)
+		os.Exit(1)
+	}
+
+	os.Exit(exitCode)
+}
+
+func TestRpcServer(t *testing.T) {
+	var currentTestNum int
+	defer func() {
+		// If one of the integration tests caused a panic within the main
+		// goroutine, then tear down all the harnesses in order to avoid
+		// any leaked soterd processes.
+		if r := recover(); r != nil {
+			fmt.Println("recovering from test panic: ", r)
+			if err := rpctest.TearDownAll(); err != nil {
+				fmt.Println("unable to tear down all harnesses: ", err)
+			}
+			t.Fatalf("test #%v panicked: %s", currentTestNum, debug.Stack())
+		}
+	}()
+
+	for _, testCase := range rpcTestCases {
+		testCase(primaryHarness, t)
+
+		currentTestNum++
+	}
+}
+
+func TestGetDAGTipsGenesis(t *testing.T) {
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create soterd node: %s", err)
+	}
+	if err := r.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete soterd node setup: %s", err)
+	}
+
+	defer r.TearDown()
+
+	// A dag with only the genesis block has a single tip, the genesis block.
+	tipInfo, err := r.Node.GetDAGTipInfo()
+	if err != nil {
+		t.Fatalf("Call to `getdagtips` failed: %v", err)
+	}
+	if len(tipInfo) != 1 {
+		t.Fatalf("Wrong number of tips for genesis-only dag. Got %d, "+
+			"wanted 1", len(tipInfo))
+	}
+
+	genesisHash := chaincfg.SimNetParams.GenesisHash.String()
+	if tipInfo[0].Hash != genesisHash {
+		t.Fatalf("Wrong tip for genesis-only dag. Got %v, wanted %v",
+			tipInfo[0].Hash, genesisHash)
+	}
+	if tipInfo[0].Height != 0 {
+		t.Fatalf("Wrong tip height for genesis-only dag. Got %d, "+
+			"wanted 0", tipInfo[0].Height)
+	}
+}
+
+func TestRegTestHarness(t *testing.T) {
+	regTest, err := rpctest.New(&chaincfg.RegressionNetParams, nil, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create regtest soterd node: %s", err)
+	}
+	if err := regTest.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete regtest soterd node setup: %s", err)
+	}
+
+	defer regTest.TearDown()
+
+	// A harness on another net, running alongside the regtest one, shouldn't collide with it.
+	simNet, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create simnet soterd node: %s", err)
+	}
+	if err := simNet.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete simnet soterd node setup: %s", err)
+	}
+
+	defer simNet.TearDown()
+
+	nets := []struct {
+		h      *rpctest.Harness
+		params *chaincfg.Params
+	}{
+		{regTest, &chaincfg.RegressionNetParams},
+		{simNet, &chaincfg.SimNetParams},
+	}
+
+	for _, net := range nets {
+		info, err := net.h.Node.GetBlockChainInfo()
+		if err != nil {
+			t.Fatalf("Call to `getblockchaininfo` failed: %v", err)
+		}
+		if info.Chain != net.params.Name {
+			t.Fatalf("Wrong chain name. Got %v, wanted %v",
+				info.Chain, net.params.Name)
+		}
+
+		tipInfo, err := net.h.Node.GetDAGTipInfo()
+		if err != nil {
+			t.Fatalf("Call to `getdagtips` failed: %v", err)
+		}
+		genesisHash := net.params.GenesisHash.String()
+		if len(tipInfo) != 1 || tipInfo[0].Hash != genesisHash {
+			t.Fatalf("Wrong tips for %v genesis-only dag. Got %v, "+
+				"wanted %v", net.params.Name, tipInfo, genesisHash)
+		}
+	}
+}
+
+func TestGenerateAndConfirmTimeout(t *testing.T) {
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create soterd node: %s", err)
+	}
+	if err := r.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete soterd node setup: %s", err)
+	}
+
+	defer r.TearDown()
+
+	// The node is kept busy generating far more blocks than it can in the
+	// timeout, so the generate call is still blocked when the timeout elapses.
+	start := time.Now()
+	_, err = r.Node.GenerateAndConfirm(10000, time.Millisecond*100)
+	if err != rpcclient.ErrGenerateTimeout {
+		t.Fatalf("GenerateAndConfirm: unexpected error. Got %v, "+
+			"wanted %v", err, rpcclient.ErrGenerateTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second*5 {
+		t.Fatalf("GenerateAndConfirm took %v to time out", elapsed)
+	}
+}
+
+func TestGenerateCtxCancel(t *testing.T) {
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create soterd node: %s", err)
+	}
+	if err := r.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete soterd node setup: %s", err)
+	}
+
+	defer r.TearDown()
+
+	rpcConf := r.RPCConfig()
+	rpcConf.HTTPPostMode = true
+	postClient, err := rpcclient.New(&rpcConf, nil)
+	if err != nil {
+		t.Fatalf("unable to create HTTP POST client: %v", err)
+	}
+	defer postClient.Shutdown()
+
+	// The node is asked to generate far more blocks than it can before the
+	// context times out, so the calls are still in-flight when they're
+	// cancelled. Both the websocket and HTTP POST transports are tested.
+	clients := []struct {
+		name   string
+		client *rpcclient.Client
+	}{
+		{"websocket", r.Node},
+		{"HTTP POST", postClient},
+	}
+	for _, c := range clients {
+		ctx, cancel := context.WithTimeout(context.Background(),
+			time.Millisecond*100)
+
+		start := time.Now()
+		_, err := c.client.GenerateCtx(ctx, 10000)
+		cancel()
+		if err != context.DeadlineExceeded {
+			t.Fatalf("%s GenerateCtx: unexpected error. Got %v, "+
+				"wanted %v", c.name, err, context.DeadlineExceeded)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second*5 {
+			t.Fatalf("%s GenerateCtx took %v to be cancelled", c.name,
+				elapsed)
+		}
+	}
+
+	// The websocket client can still be used once the abandoned request
+	// is answered.
+	if _, err := r.Node.GetBlockCount(); err != nil {
+		t.Fatalf("Unable to get block count after cancellation: %v", err)
+	}
+}
+
+func TestNotifyDagTips(t *testing.T) {
+	// Record the order in which the block connected and dag tip handlers
+	// are invoked.
+	type dagEvent struct {
+		connected *chainhash.Hash
+		added     []*chainhash.Hash
+		removed   []*chainhash.Hash
+	}
+	events := make(chan dagEvent, 10)
+	handlers := &rpcclient.NotificationHandlers{
+		OnBlockConnected: func(hash *chainhash.Hash, height int32, t time.Time) {
+			events <- dagEvent{connected: hash}
+		},
+		OnDagTipsChanged: func(added, removed []*chainhash.Hash) {
+			events <- dagEvent{added: added, removed: removed}
+		},
+	}
+
+	r, err := rpctest.New(&chaincfg.SimNetParams, handlers, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create soterd node: %s", err)
+	}
+	if err := r.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete soterd node setup: %s", err)
+	}
+
+	defer r.TearDown()
+
+	if err := r.Node.NotifyDagTips(); err != nil {
+		t.Fatalf("Call to `notifydagtips` failed: %v", err)
+	}
+
+	hashes, err := r.Node.Generate(1)
+	if err != nil {
+		t.Fatalf("Unable to generate block: %v", err)
+	}
+	blockHash := hashes[0]
+
+	nextEvent := func() dagEvent {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(time.Second * 10):
+			t.Fatalf("timeout waiting for notification of block %v",
+				blockHash)
+		}
+		return dagEvent{}
+	}
+
+	// The block connected notification comes first.
+	e := nextEvent()
+	if e.connected == nil || !e.connected.IsEqual(blockHash) {
+		t.Fatalf("Expected block connected notification for %v first, "+
+			"got %+v", blockHash, e)
+	}
+
+	// The new block replaces the genesis block as the only tip.
+	e = nextEvent()
+	if e.connected != nil {
+		t.Fatalf("Expected dag tips changed notification, got block "+
+			"connected notification for %v", e.connected)
+	}
+	if len(e.added) != 1 || !e.added[0].IsEqual(blockHash) {
+		t.Fatalf("Wrong added tips. Got %v, wanted [%v]", e.added,
+			blockHash)
+	}
+	genesisHash := chaincfg.SimNetParams.GenesisHash
+	if len(e.removed) != 1 || !e.removed[0].IsEqual(genesisHash) {
+		t.Fatalf("Wrong removed tips. Got %v, wanted [%v]", e.removed,
+			genesisHash)
+	}
+}
+
+func TestClientReconnect(t *testing.T) {
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create soterd node: %s", err)
+	}
+	if err := r.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete soterd node setup: %s", err)
+	}
+
+	defer r.TearDown()
+
+	reconnected := make(chan struct{}, 1)
+	connected := make(chan *chainhash.Hash, 10)
+	handlers := &rpcclient.NotificationHandlers{
+		OnClientReconnected: func() {
+			reconnected <- struct{}{}
+		},
+		OnBlockConnected: func(hash *chainhash.Hash, height int32, t time.Time) {
+			connected <- hash
+		},
+	}
+
+	// Unlike the harness' own client, this one reconnects when the node
+	// goes away.
+	rpcConf := r.RPCConfig()
+	rpcConf.DisableAutoReconnect = false
+	rpcConf.ReconnectInterval = time.Millisecond * 100
+	rpcConf.MaxReconnectInterval = time.Millisecond * 500
+	client, err := rpcclient.New(&rpcConf, handlers)
+	if err != nil {
+		t.Fatalf("unable to create rpc client: %v", err)
+	}
+	defer client.Shutdown()
+
+	if err := client.NotifyBlocks(); err != nil {
+		t.Fatalf("Call to `notifyblocks` failed: %v", err)
+	}
+
+	// Kill and restart the node mid-session.
+	if err := r.Restart(); err != nil {
+		t.Fatalf("unable to restart soterd node: %v", err)
+	}
+
+	select {
+	case <-reconnected:
+	case <-time.After(time.Second * 30):
+		t.Fatalf("timeout waiting for client to reconnect")
+	}
+
+	// The client is usable again, and its block notifications were
+	// re-registered with the restarted node.
+	hashes, err := client.Generate(1)
+	if err != nil {
+		t.Fatalf("Unable to generate block after reconnect: %v", err)
+	}
+	select {
+	case hash := <-connected:
+		if !hash.IsEqual(hashes[0]) {
+			t.Fatalf("Block connected notification for wrong block. "+
+				"Got %v, wanted %v", hash, hashes[0])
+		}
+	case <-time.After(time.Second * 10):
+		t.Fatalf("timeout waiting for block connected notification "+
+			"after reconnect")
+	}
+}
+
+func TestNotifyBlockReordered(t *testing.T) {
+	type reorderEvent struct {
+		blue []*chainhash.Hash
+		red  []*chainhash.Hash
+	}
+	events := make(chan reorderEvent, 20)
+	handlers := &rpcclient.NotificationHandlers{
+		OnBlockReordered: func(blue, red []*chainhash.Hash) {
+			events <- reorderEvent{blue: blue, red: red}
+		},
+	}
+
+	r, err := rpctest.New(&chaincfg.SimNetParams, handlers, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create soterd node: %s", err)
+	}
+	if err := r.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete soterd node setup: %s", err)
+	}
+
+	defer r.TearDown()
+
+	if err := r.Node.NotifyBlocks(); err != nil {
+		t.Fatalf("Call to `notifyblocks` failed: %v", err)
+	}
+
+	// submitChain submits a chain of n blocks built on the genesis block,
+	// paying to a new address so that chains don't share blocks.
+	submitChain := func(n int) []*chainhash.Hash {
+		addr, err := r.NewAddress()
+		if err != nil {
+			t.Fatalf("unable to generate new address: %v", err)
+		}
+
+		var prevBlock *soterutil.Block
+		prevHash := chaincfg.SimNetParams.GenesisHash
+		hashes := make([]*chainhash.Hash, 0, n)
+		for i := 0; i < n; i++ {
+			tipsHash := blockdag.GenerateTipsHash([]*chainhash.Hash{prevHash})
+			block, err := rpctest.CreateBlock(prevBlock, tipsHash, nil,
+				rpctest.BlockVersion, time.Time{}, addr, nil,
+				r.ActiveNet)
+			if err != nil {
+				t.Fatalf("unable to create block: %v", err)
+			}
+			reason, err := r.SubmitBlock(block)
+			if err != nil {
+				t.Fatalf("unable to submit block: %v", err)
+			}
+			if reason != "" {
+				t.Fatalf("block %v was rejected: %v", block.Hash(),
+					reason)
+			}
+
+			block.SetHeight(int32(i + 1))
+			prevBlock = block
+			prevHash = block.Hash()
+			hashes = append(hashes, block.Hash())
+		}
+		return hashes
+	}
+
+	// With a coloring k of 3, a chain of 5 blocks is colored blue while it's
+	// the only one.  A competing chain is colored red once it's more than
+	// 3 blocks long, until it outgrows the first chain, at which point the
+	// first chain is colored red and the competing chain blue again.
+	first := submitChain(5)
+	second := submitChain(6)
+
+	// Track the last color each block was reported to have.
+	colors := make(map[chainhash.Hash]string)
+	reordered := func() bool {
+		for _, hash := range first {
+			if colors[*hash] != "red" {
+				return false
+			}
+		}
+		return colors[*second[0]] == "blue"
+	}
+	for !reordered() {
+		select {
+		case e := <-events:
+			for _, hash := range e.blue {
+				colors[*hash] = "blue"
+			}
+			for _, hash := range e.red {
+				colors[*hash] = "red"
+			}
+		case <-time.After(time.Second * 10):
+			t.Fatalf("timeout waiting for block reordered "+
+				"notifications, got colors %v", colors)
+		}
+	}
+}
+
+func TestMaxBlockParents(t *testing.T) {
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create soterd node: %s", err)
+	}
+	if err := r.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete soterd node setup: %s", err)
+	}
+
+	defer r.TearDown()
+
+	dagInfo, err := r.Node.GetDagInfo()
+	if err != nil {
+		t.Fatalf("Call to `getdaginfo` failed: %v", err)
+	}
+	maxParents := chaincfg.SimNetParams.MaxBlockParents
+	if dagInfo.MaxBlockParents != maxParents {
+		t.Fatalf("getdaginfo reported max block parents %d, want %d",
+			dagInfo.MaxBlockParents, maxParents)
+	}
+
+	// Submit more sibling blocks on the genesis block than a block may
+	// reference, paying to a new address each so that they're distinct.
+	tipsHash := blockdag.GenerateTipsHash(
+		[]*chainhash.Hash{chaincfg.SimNetParams.GenesisHash})
+	for i := int32(0); i <= maxParents; i++ {
+		addr, err := r.NewAddress()
+		if err != nil {
+			t.Fatalf("unable to generate new address: %v", err)
+		}
+		block, err := rpctest.CreateBlock(nil, tipsHash, nil,
+			rpctest.BlockVersion, time.Time{}, addr, nil, r.ActiveNet)
+		if err != nil {
+			t.Fatalf("unable to create block: %v", err)
+		}
+		reason, err := r.SubmitBlock(block)
+		if err != nil {
+			t.Fatalf("unable to submit block: %v", err)
+		}
+		if reason != "" {
+			t.Fatalf("block %v was rejected: %v", block.Hash(), reason)
+		}
+	}
+
+	tips, err := r.Node.GetDAGTips()
+	if err != nil {
+		t.Fatalf("Call to `getdagtips` failed: %v", err)
+	}
+	if int32(len(tips.Tips)) <= maxParents {
+		t.Fatalf("dag has %d tips, want more than %d", len(tips.Tips),
+			maxParents)
+	}
+
+	// A generated block should only reference as many of the tips as are
+	// allowed.
+	hashes, err := r.Node.Generate(1)
+	if err != nil {
+		t.Fatalf("Call to `generate` failed: %v", err)
+	}
+	block, err := r.Node.GetBlock(hashes[0])
+	if err != nil {
+		t.Fatalf("Call to `getblock` failed: %v", err)
+	}
+	if len(block.Parents.Parents) != int(maxParents) {
+		t.Fatalf("generated block has %d parents, want %d",
+			len(block.Parents.Parents), maxParents)
+	}
+}
+
+// TestMaxBlockParentsUnequalHeights tests that a block generated on a dag whose
+// highest tip isn't one of the tips offered as parents is one higher than its
+// parents, rather than the highest tip.
+func TestMaxBlockParentsUnequalHeights(t *testing.T) {
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create soterd node: %s", err)
+	}
+	if err := r.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete soterd node setup: %s", err)
+	}
+
+	defer r.TearDown()
+
+	// A chain of three blocks, whose tip is the highest block of the dag
+	// and has the least work in its past.
+	chain, err := r.Node.Generate(3)
+	if err != nil {
+		t.Fatalf("Call to `generate` failed: %v", err)
+	}
+	chainTip := chain[len(chain)-1]
+
+	// As many tips as a block may reference at height 2, each merging three
+	// blocks on the genesis block, so that they have more work in their
+	// past than the chain tip.
+	tipsHash := blockdag.GenerateTipsHash(
+		[]*chainhash.Hash{chaincfg.SimNetParams.GenesisHash})
+	maxParents := chaincfg.SimNetParams.MaxBlockParents
+	for i := int32(0); i < maxParents; i++ {
+		siblings := make([]*chainhash.Hash, 0, 3)
+		for j := 0; j < 3; j++ {
+			addr, err := r.NewAddress()
+			if err != nil {
+				t.Fatalf("unable to generate new address: %v", err)
+			}
+			block, err := rpctest.CreateBlock(nil, tipsHash, nil,
+				rpctest.BlockVersion, time.Time{}, addr, nil,
+				r.ActiveNet)
+			if err != nil {
+				t.Fatalf("unable to create block: %v", err)
+			}
+			reason, err := r.SubmitBlock(block)
+			if err != nil {
+				t.Fatalf("unable to submit block: %v", err)
+			}
+			if reason != "" {
+				t.Fatalf("block %v was rejected: %v", block.Hash(),
+					reason)
+			}
+			siblings = append(siblings, block.Hash())
+		}
+		_, err := r.Node.GenerateWithParents(1, siblings)
+		if err != nil {
+			t.Fatalf("Call to `generatewithparents` failed: %v", err)
+		}
+	}
+
+	hashes, err := r.Node.Generate(1)
+	if err != nil {
+		t.Fatalf("Call to `generate` failed: %v", err)
+	}
+	block, err := r.Node.GetBlock(hashes[0])
+	if err != nil {
+		t.Fatalf("Call to `getblock` failed: %v", err)
+	}
+	if block.Parents.IsParent(chainTip) {
+		t.Fatalf("generated block references the chain tip %v, which has "+
+			"the least work", chainTip)
+	}
+	header, err := r.Node.GetBlockHeaderVerbose(hashes[0])
+	if err != nil {
+		t.Fatalf("Call to `getblockheader` failed: %v", err)
+	}
+	if header.Height != 3 {
+		t.Fatalf("generated block has height %d, want 3", header.Height)
+	}
+}
+
+func TestGetDagPath(t *testing.T) {
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create soterd node: %s", err)
+	}
+	if err := r.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete soterd node setup: %s", err)
+	}
+
+	defer r.TearDown()
+
+	// submitBlock submits a block with the given parent, paying to a new
+	// address so that siblings are distinct blocks.
+	submitBlock := func(parent *soterutil.Block) *soterutil.Block {
+		addr, err := r.NewAddress()
+		if err != nil {
+			t.Fatalf("unable to generate new address: %v", err)
+		}
+
+		parentHash := chaincfg.SimNetParams.GenesisHash
+		if parent != nil {
+			parentHash = parent.Hash()
+		}
+		tipsHash := blockdag.GenerateTipsHash([]*chainhash.Hash{parentHash})
+		block, err := rpctest.CreateBlock(parent, tipsHash, nil,
+			rpctest.BlockVersion, time.Time{}, addr, nil, r.ActiveNet)
+		if err != nil {
+			t.Fatalf("unable to create block: %v", err)
+		}
+		reason, err := r.SubmitBlock(block)
+		if err != nil {
+			t.Fatalf("unable to submit block: %v", err)
+		}
+		if reason != "" {
+			t.Fatalf("block %v was rejected: %v", block.Hash(), reason)
+		}
+		return block
+	}
+
+	// Build the dag
+	//
+	//   genesis <- a <------- c
+	//          \             /
+	//           <- b <- b2 <-
+	//
+	// so that c reaches the genesis block through a, and b through b2.
+	genesis := chaincfg.SimNetParams.GenesisHash
+	a := submitBlock(nil)
+	b := submitBlock(nil)
+	b2 := submitBlock(b)
+	hashes, err := r.Node.GenerateWithParents(1,
+		[]*chainhash.Hash{a.Hash(), b2.Hash()})
+	if err != nil {
+		t.Fatalf("Call to `generatewithparents` failed: %v", err)
+	}
+	c := hashes[0]
+
+	tests := []struct {
+		name     string
+		from, to *chainhash.Hash
+		length   int
+	}{
+		{"to genesis", c, genesis, 3},
+		{"through a longer branch", c, b.Hash(), 3},
+		{"to parent", c, a.Hash(), 2},
+		{"to itself", c, c, 1},
+		{"not an ancestor", a.Hash(), b.Hash(), 0},
+		{"to a descendant", genesis, c, 0},
+	}
+	for _, test := range tests {
+		path, err := r.Node.GetDagPath(test.from, test.to)
+		if err != nil {
+			t.Fatalf("%s: Call to `getdagpath` failed: %v", test.name,
+				err)
+		}
+		if len(path) != test.length {
+			t.Fatalf("%s: got path %v, want one of length %d",
+				test.name, path, test.length)
+		}
+		if test.length == 0 {
+			continue
+		}
+
+		if !path[0].IsEqual(test.from) ||
+			!path[len(path)-1].IsEqual(test.to) {
+			t.Fatalf("%s: path %v doesn't go from %v to %v",
+				test.name, path, test.from, test.to)
+		}
+		for i := 1; i < len(path); i++ {
+			block, err := r.Node.GetBlock(path[i-1])
+			if err != nil {
+				t.Fatalf("Call to `getblock` failed: %v", err)
+			}
+			if !block.Parents.IsParent(path[i]) {
+				t.Fatalf("%s: %v in path %v isn't a parent of %v",
+					test.name, path[i], path, path[i-1])
+			}
+		}
+	}
+
+	// Blocks that aren't in the dag are reported as not found.
+	unknown := chainhash.DoubleHashH([]byte("unknown block"))
+	if _, err := r.Node.GetDagPath(c, &unknown); err == nil {
+		t.Fatalf("getdagpath to an unknown block didn't return an error")
+	}
+}
+
+func TestGetBlockTemplate(t *testing.T) {
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create soterd node: %s", err)
+	}
+	if err := r.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete soterd node setup: %s", err)
+	}
+
+	defer r.TearDown()
+
+	// Submit sibling blocks on the genesis block, so that the template has
+	// more than one tip to reference.
+	tipsHash := blockdag.GenerateTipsHash(
+		[]*chainhash.Hash{chaincfg.SimNetParams.GenesisHash})
+	for i := 0; i < 2; i++ {
+		addr, err := r.NewAddress()
+		if err != nil {
+			t.Fatalf("unable to generate new address: %v", err)
+		}
+		block, err := rpctest.CreateBlock(nil, tipsHash, nil,
+			rpctest.BlockVersion, time.Time{}, addr, nil, r.ActiveNet)
+		if err != nil {
+			t.Fatalf("unable to create block: %v", err)
+		}
+		if err := r.Node.SubmitBlock(block, nil); err != nil {
+			t.Fatalf("Call to `submitblock` failed: %v", err)
+		}
+	}
+
+	tips, err := r.Node.GetDAGTips()
+	if err != nil {
+		t.Fatalf("Call to `getdagtips` failed: %v", err)
+	}
+
+	template, err := r.Node.GetBlockTemplate(&soterjson.TemplateRequest{
+		Capabilities: []string{"coinbasetxn"},
+	})
+	if err != nil {
+		t.Fatalf("Call to `getblocktemplate` failed: %v", err)
+	}
+
+	hasParents := false
+	for _, capability := range template.Capabilities {
+		if capability == "parents" {
+			hasParents = true
+		}
+	}
+	if !hasParents {
+		t.Fatalf("template capabilities %v don't include parents",
+			template.Capabilities)
+	}
+
+	// The template should reference all of the current tips as parents.
+	if len(template.Parents) != len(tips.Tips) {
+		t.Fatalf("template has parents %v, want tips %v",
+			template.Parents, tips.Tips)
+	}
+	isTip := make(map[string]bool)
+	for _, tip := range tips.Tips {
+		isTip[tip] = true
+	}
+	parents := make([]*chainhash.Hash, 0, len(template.Parents))
+	for _, parent := range template.Parents {
+		if !isTip[parent] {
+			t.Fatalf("template parent %v isn't a tip of %v", parent,
+				tips.Tips)
+		}
+		hash, err := chainhash.NewHashFromStr(parent)
+		if err != nil {
+			t.Fatalf("template parent %v isn't a hash: %v", parent, err)
+		}
+		parents = append(parents, hash)
+	}
+	if blockdag.GenerateTipsHash(parents).String() != template.PreviousHash {
+		t.Fatalf("template previous hash %v isn't the tips hash of its "+
+			"parents %v", template.PreviousHash, template.Parents)
+	}
+
+	// Build a block from the template, the way an external miner would,
+	// and solve it.
+	coinbaseBytes, err := hex.DecodeString(template.CoinbaseTxn.Data)
+	if err != nil {
+		t.Fatalf("unable to decode template coinbase: %v", err)
+	}
+	var coinbase wire.MsgTx
+	if err := coinbase.Deserialize(bytes.NewReader(coinbaseBytes)); err != nil {
+		t.Fatalf("unable to deserialize template coinbase: %v", err)
+	}
+	bits, err := strconv.ParseUint(template.Bits, 16, 32)
+	if err != nil {
+		t.Fatalf("template bits %v aren't valid: %v", template.Bits, err)
+	}
+	prevHash, err := chainhash.NewHashFromStr(template.PreviousHash)
+	if err != nil {
+		t.Fatalf("template previous hash %v isn't a hash: %v",
+			template.PreviousHash, err)
+	}
+
+	merkles := blockdag.BuildMerkleTreeStore(
+		[]*soterutil.Tx{soterutil.NewTx(&coinbase)}, false)
+	msgBlock := wire.NewMsgBlock(wire.NewBlockHeader(template.Version,
+		prevHash, merkles[len(merkles)-1], uint32(bits), 0))
+	msgBlock.Header.Timestamp = time.Unix(template.CurTime, 0)
+	msgBlock.Parents.Version = template.ParentVersion
+	msgBlock.Parents.Size = int32(len(parents))
+	for _, parent := range parents {
+		msgBlock.Parents.Parents = append(msgBlock.Parents.Parents,
+			&wire.Parent{Hash: *parent})
+	}
+	if err := msgBlock.AddTransaction(&coinbase); err != nil {
+		t.Fatalf("unable to add coinbase to block: %v", err)
+	}
+
+	target := blockdag.CompactToBig(uint32(bits))
+	for {
+		hash := msgBlock.Header.BlockHash()
+		if blockdag.HashToBig(&hash).Cmp(target) <= 0 {
+			break
+		}
+		msgBlock.Header.Nonce++
+	}
+
+	block := soterutil.NewBlock(msgBlock)
+	if err := r.Node.SubmitBlock(block, nil); err != nil {
+		t.Fatalf("Call to `submitblock` failed: %v", err)
+	}
+
+	tips, err = r.Node.GetDAGTips()
+	if err != nil {
+		t.Fatalf("Call to `getdagtips` failed: %v", err)
+	}
+	if len(tips.Tips) != 1 || tips.Tips[0] != block.Hash().String() {
+		t.Fatalf("dag tips are %v after submitting block %v", tips.Tips,
+			block.Hash())
+	}
+}
+
+func TestGetDAGTipStatus(t *testing.T) {
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create soterd node: %s", err)
+	}
+	if err := r.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete soterd node setup: %s", err)
+	}
+
+	defer r.TearDown()
+
+	// createBlock creates a block with the given parent, or the genesis
+	// block when it's nil, paying to a new address so that siblings are
+	// distinct blocks.
+	createBlock := func(parent *soterutil.Block) *soterutil.Block {
+		addr, err := r.NewAddress()
+		if err != nil {
+			t.Fatalf("unable to generate new address: %v", err)
+		}
+
+		parentHash := chaincfg.SimNetParams.GenesisHash
+		if parent != nil {
+			parentHash = parent.Hash()
+		}
+		tipsHash := blockdag.GenerateTipsHash([]*chainhash.Hash{parentHash})
+		block, err := rpctest.CreateBlock(parent, tipsHash, nil,
+			rpctest.BlockVersion, time.Time{}, addr, nil, r.ActiveNet)
+		if err != nil {
+			t.Fatalf("unable to create block: %v", err)
+		}
+		return block
+	}
+
+	// Submit more sibling blocks on the genesis block than a block may
+	// reference, so that one of them is a valid fork that new blocks
+	// don't build on.
+	maxParents := chaincfg.SimNetParams.MaxBlockParents
+	for i := int32(0); i <= maxParents; i++ {
+		block := createBlock(nil)
+		reason, err := r.SubmitBlock(block)
+		if err != nil {
+			t.Fatalf("unable to submit block: %v", err)
+		}
+		if reason != "" {
+			t.Fatalf("block %v was rejected: %v", block.Hash(), reason)
+		}
+	}
+
+	// Submit a block whose parent the node doesn't know about, so that it's
+	// held as an orphan.
+	missing := createBlock(nil)
+	orphan := createBlock(missing)
+	if _, err := r.SubmitBlock(orphan); err != nil {
+		t.Fatalf("unable to submit orphan block: %v", err)
+	}
+
+	tips, err := r.Node.GetDAGTipStatus()
+	if err != nil {
+		t.Fatalf("Call to `getdagtipstatus` failed: %v", err)
+	}
+
+	statuses := make(map[string]soterjson.GetDAGTipStatusResult)
+	counts := make(map[string]int32)
+	for _, tip := range tips {
+		statuses[tip.Hash] = tip
+		counts[tip.Status]++
+	}
+	if counts[soterjson.DAGTipStatusActive] != maxParents {
+		t.Fatalf("got %d active tips, want %d: %+v",
+			counts[soterjson.DAGTipStatusActive], maxParents, tips)
+	}
+	if counts[soterjson.DAGTipStatusValidFork] != 1 {
+		t.Fatalf("got %d valid-fork tips, want 1: %+v",
+			counts[soterjson.DAGTipStatusValidFork], tips)
+	}
+
+	var fork soterjson.GetDAGTipStatusResult
+	for _, tip := range tips {
+		if tip.Status == soterjson.DAGTipStatusValidFork {
+			fork = tip
+		}
+	}
+	if fork.Height != 1 || fork.BranchLen != 1 {
+		t.Fatalf("valid fork %+v should have height 1 and branch length 1",
+			fork)
+	}
+
+	orphanTip, ok := statuses[orphan.Hash().String()]
+	if !ok {
+		t.Fatalf("orphan %v isn't one of the tips %+v", orphan.Hash(), tips)
+	}
+	if orphanTip.Status != soterjson.DAGTipStatusValidHeaders ||
+		orphanTip.BranchLen != 1 {
+		t.Fatalf("orphan tip %+v should be valid-headers with branch "+
+			"length 1", orphanTip)
+	}
+	if orphanTip.Status == fork.Status {
+		t.Fatalf("orphan and valid fork have the same status %q",
+			fork.Status)
+	}
+}
+
+func TestNotifyNewTransactions(t *testing.T) {
+	type txEvent struct {
+		hash   *chainhash.Hash
+		amount soterutil.Amount
+		rawTx  *soterjson.TxRawResult
+	}
+	events := make(chan txEvent, 10)
+	handlers := &rpcclient.NotificationHandlers{
+		OnTxAccepted: func(hash *chainhash.Hash, amount soterutil.Amount) {
+			events <- txEvent{hash: hash, amount: amount}
+		},
+		OnTxAcceptedVerbose: func(rawTx *soterjson.TxRawResult) {
+			events <- txEvent{rawTx: rawTx}
+		},
+	}
+
+	// Each of the transactions spends a mature coinbase output.
+	r, err := rpctest.New(&chaincfg.SimNetParams, handlers, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create soterd node: %s", err)
+	}
+	if err := r.SetUp(true, 2); err != nil {
+		t.Fatalf("unable to complete soterd node setup: %s", err)
+	}
+
+	defer r.TearDown()
+
+	addr, err := r.NewAddress()
+	if err != nil {
+		t.Fatalf("unable to get new address: %v", err)
+	}
+	addrScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("unable to generate pkscript to addr: %v", err)
+	}
+
+	// sendTx submits a new transaction to the node's mempool, and waits for
+	// the notification of its acceptance.
+	sendTx := func() (*wire.MsgTx, txEvent) {
+		output := wire.NewTxOut(1000, addrScript)
+		tx, err := r.CreateTransaction([]*wire.TxOut{output}, 10, true)
+		if err != nil {
+			t.Fatalf("unable to create transaction: %v", err)
+		}
+		if _, err := r.Node.SendRawTransaction(tx, true); err != nil {
+			t.Fatalf("unable to send transaction: %v", err)
+		}
+
+		select {
+		case e := <-events:
+			return tx, e
+		case <-time.After(time.Second * 10):
+			t.Fatalf("timeout waiting for notification of transaction "+
+				"%v", tx.TxHash())
+		}
+		return nil, txEvent{}
+	}
+
+	if err := r.Node.NotifyNewTransactions(false); err != nil {
+		t.Fatalf("Call to `notifynewtransactions` failed: %v", err)
+	}
+
+	// The notification has the hash of the transaction and the total value
+	// of its outputs.
+	tx, e := sendTx()
+	txHash := tx.TxHash()
+	var amount int64
+	for _, txOut := range tx.TxOut {
+		amount += txOut.Value
+	}
+	if e.hash == nil || !e.hash.IsEqual(&txHash) {
+		t.Fatalf("Expected tx accepted notification for %v, got %+v",
+			txHash, e)
+	}
+	if e.amount != soterutil.Amount(amount) {
+		t.Fatalf("tx accepted notification has amount %v, want %v",
+			e.amount, soterutil.Amount(amount))
+	}
+
+	// Registering again with the verbose flag switches to verbose
+	// notifications, which have the whole transaction.
+	if err := r.Node.NotifyNewTransactions(true); err != nil {
+		t.Fatalf("Call to `notifynewtransactions` failed: %v", err)
+	}
+
+	tx, e = sendTx()
+	txHash = tx.TxHash()
+	if e.rawTx == nil || e.rawTx.Txid != txHash.String() {
+		t.Fatalf("Expected verbose tx accepted notification for %v, "+
+			"got %+v", txHash, e)
+	}
+	if int(e.rawTx.Size) != tx.SerializeSize() ||
+		len(e.rawTx.Vout) != len(tx.TxOut) {
+
+		t.Fatalf("verbose tx accepted notification has size %d and %d "+
+			"outputs, want %d and %d", e.rawTx.Size,
+			len(e.rawTx.Vout), tx.SerializeSize(), len(tx.TxOut))
+	}
+}
+
+func TestResubmitTransaction(t *testing.T) {
+	// Confirmed transactions are only found with the transaction index.
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, []string{"--txindex"},
+		false)
+	if err != nil {
+		t.Fatalf("unable to create soterd node: %s", err)
+	}
+	if err := r.SetUp(true, 1); err != nil {
+		t.Fatalf("unable to complete soterd node setup: %s", err)
+	}
+
+	defer r.TearDown()
+
+	addr, err := r.NewAddress()
+	if err != nil {
+		t.Fatalf("unable to get new address: %v", err)
+	}
+	addrScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("unable to generate pkscript to addr: %v", err)
+	}
+	output := wire.NewTxOut(1000, addrScript)
+	tx, err := r.CreateTransaction([]*wire.TxOut{output}, 10, true)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	txHash := tx.TxHash()
+
+	status, err := r.Node.GetTransactionStatus(&txHash)
+	if err != nil {
+		t.Fatalf("Call to `gettransactionstatus` failed: %v", err)
+	}
+	if status != soterjson.TxStatusUnknown {
+		t.Fatalf("unsent transaction has status %q, want %q", status,
+			soterjson.TxStatusUnknown)
+	}
+
+	// Resubmitting a transaction the node doesn't have yet submits it
+	// like sendrawtransaction does.
+	result, err := r.Node.ResubmitTransaction(tx)
+	if err != nil {
+		t.Fatalf("Call to `resubmittransaction` failed: %v", err)
+	}
+	if result.TxID != txHash.String() || result.Rebroadcast {
+		t.Fatalf("resubmittransaction of a new transaction returned %+v",
+			result)
+	}
+
+	status, err = r.Node.GetTransactionStatus(&txHash)
+	if err != nil {
+		t.Fatalf("Call to `gettransactionstatus` failed: %v", err)
+	}
+	if status != soterjson.TxStatusMempool {
+		t.Fatalf("sent transaction has status %q, want %q", status,
+			soterjson.TxStatusMempool)
+	}
+
+	// Transactions in the memory pool are rebroadcast, instead of being
+	// rejected as duplicates like sendrawtransaction does.
+	if _, err := r.Node.SendRawTransaction(tx, true); err == nil {
+		t.Fatalf("sendrawtransaction accepted a transaction that's " +
+			"already in the memory pool")
+	}
+	result, err = r.Node.ResubmitTransaction(tx)
+	if err != nil {
+		t.Fatalf("Call to `resubmittransaction` failed for a transaction "+
+			"in the memory pool: %v", err)
+	}
+	if result.TxID != txHash.String() || !result.Rebroadcast {
+		t.Fatalf("resubmittransaction of a transaction in the memory "+
+			"pool returned %+v", result)
+	}
+
+	// Once the transaction is in a block, resubmitting it is an error.
+	if _, err := r.Node.Generate(1); err != nil {
+		t.Fatalf("unable to generate block: %v", err)
+	}
+	status, err = r.Node.GetTransactionStatus(&txHash)
+	if err != nil {
+		t.Fatalf("Call to `gettransactionstatus` failed: %v", err)
+	}
+	if status != soterjson.TxStatusConfirmed {
+		t.Fatalf("mined transaction has status %q, want %q", status,
+			soterjson.TxStatusConfirmed)
+	}
+
+	_, err = r.Node.ResubmitTransaction(tx)
+	rpcErr, ok := err.(*soterjson.RPCError)
+	if !ok || rpcErr.Code != soterjson.ErrRPCVerifyAlreadyInDAG {
+		t.Fatalf("resubmittransaction of a mined transaction returned "+
+			"%v, want error code %d", err,
+			soterjson.ErrRPCVerifyAlreadyInDAG)
+	}
+}
+
+// TestSetMaxPeers tests that lowering the inbound peer limit below the number
+// of connected inbound peers disconnects the excess peers, and keeps them from
+// connecting again.
+func TestSetMaxPeers(t *testing.T) {
+	var nodes []*rpctest.Harness
+	for i := 0; i < 3; i++ {
+		r, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+		if err != nil {
+			t.Fatalf("unable to create soterd node: %s", err)
+		}
+		if err := r.SetUp(false, 0); err != nil {
+			t.Fatalf("unable to complete soterd node setup: %s", err)
+		}
+		defer r.TearDown()
+		nodes = append(nodes, r)
+	}
+	center := nodes[0]
+
+	// Both of the other nodes connect to the center node, so it has two
+	// inbound peers.
+	if err := rpctest.ConnectNodesStar(center, nodes[1:]); err != nil {
+		t.Fatalf("unable to connect nodes: %v", err)
+	}
+	limits, err := center.Node.GetPeerLimits()
+	if err != nil {
+		t.Fatalf("Call to `getpeerlimits` failed: %v", err)
+	}
+	if limits.Inbound != 2 || limits.Outbound != 0 {
+		t.Fatalf("center node has %d inbound and %d outbound peers, "+
+			"want 2 and 0", limits.Inbound, limits.Outbound)
+	}
+	if limits.MaxInbound < 2 {
+		t.Fatalf("center node has max inbound peers %d, want at least 2",
+			limits.MaxInbound)
+	}
+
+	err = center.Node.SetMaxPeers(1, int(limits.MaxOutbound))
+	if err != nil {
+		t.Fatalf("Call to `setmaxpeers` failed: %v", err)
+	}
+
+	// The excess peer is dropped.
+	deadline := time.Now().Add(time.Second * 10)
+	for {
+		limits, err = center.Node.GetPeerLimits()
+		if err != nil {
+			t.Fatalf("Call to `getpeerlimits` failed: %v", err)
+		}
+		if limits.Inbound == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("center node still has %d inbound peers after "+
+				"lowering the limit to 1", limits.Inbound)
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+	if limits.MaxInbound != 1 {
+		t.Fatalf("center node has max inbound peers %d, want 1",
+			limits.MaxInbound)
+	}
+
+	// The dropped peer keeps trying to reconnect, since it added the center
+	// node as a persistent peer, but it isn't let in.
+	time.Sleep(time.Second * 2)
+	limits, err = center.Node.GetPeerLimits()
+	if err != nil {
+		t.Fatalf("Call to `getpeerlimits` failed: %v", err)
+	}
+	if limits.Inbound != 1 {
+		t.Fatalf("center node has %d inbound peers with the limit at 1",
+			limits.Inbound)
+	}
+
+	// Negative limits are rejected.
+	err = center.Node.SetMaxPeers(-1, int(limits.MaxOutbound))
+	if rpcErr, ok := err.(*soterjson.RPCError); !ok ||
+		rpcErr.Code != soterjson.ErrRPCInvalidParameter {
+
+		t.Fatalf("setmaxpeers with a negative limit returned %v, want "+
+			"error code %d", err, soterjson.ErrRPCInvalidParameter)
+	}
+}
+
+func TestGetMiningInfo(t *testing.T) {
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create soterd node: %s", err)
+	}
+	if err := r.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete soterd node setup: %s", err)
+	}
+
+	defer r.TearDown()
+
+	// Submit sibling blocks on the genesis block, so that the dag has more
+	// than one tip.
+	const siblings = 2
+	tipsHash := blockdag.GenerateTipsHash(
+		[]*chainhash.Hash{chaincfg.SimNetParams.GenesisHash})
+	for i := 0; i < siblings; i++ {
+		addr, err := r.NewAddress()
+		if err != nil {
+			t.Fatalf("unable to generate new address: %v", err)
+		}
+		block, err := rpctest.CreateBlock(nil, tipsHash, nil,
+			rpctest.BlockVersion, time.Time{}, addr, nil, r.ActiveNet)
+		if err != nil {
+			t.Fatalf("unable to create block: %v", err)
+		}
+		reason, err := r.SubmitBlock(block)
+		if err != nil {
+			t.Fatalf("unable to submit block: %v", err)
+		}
+		if reason != "" {
+			t.Fatalf("block %v was rejected: %v", block.Hash(), reason)
+		}
+	}
+
+	info, err := r.Node.GetMiningInfo()
+	if err != nil {
+		t.Fatalf("Call to `getmininginfo` failed: %v", err)
+	}
+	tips, err := r.Node.GetDAGTips()
+	if err != nil {
+		t.Fatalf("Call to `getdagtips` failed: %v", err)
+	}
+
+	if info.TipCount != tips.TipCount || info.TipCount != siblings {
+		t.Fatalf("getmininginfo reports %d tips, getdagtips reports %d, "+
+			"want %d", info.TipCount, tips.TipCount, siblings)
+	}
+	if info.MaxHeight != tips.MaxHeight {
+		t.Fatalf("getmininginfo reports max height %d, getdagtips "+
+			"reports %d", info.MaxHeight, tips.MaxHeight)
+	}
+	if info.Blocks != int64(tips.BlkCount) {
+		t.Fatalf("getmininginfo reports %d blocks, getdagtips reports %d",
+			info.Blocks, tips.BlkCount)
+	}
+	if info.OrderLength < 1 || int64(info.OrderLength) > info.Blocks {
+		t.Fatalf("getmininginfo reports ordering length %d for %d blocks",
+			info.OrderLength, info.Blocks)
+	}
+
+	// The next block references tips of the dag.
+	tipSet := make(map[string]struct{})
+	for _, tip := range tips.Tips {
+		tipSet[tip] = struct{}{}
+	}
+	if len(info.Parents) == 0 || len(info.Parents) > len(tips.Tips) {
+		t.Fatalf("getmininginfo reports %d parents for %d tips",
+			len(info.Parents), len(tips.Tips))
+	}
+	for _, parent := range info.Parents {
+		if _, ok := tipSet[parent]; !ok {
+			t.Fatalf("parent %s reported by getmininginfo isn't a tip",
+				parent)
+		}
+	}
+}
+
+func TestGetAnticone(t *testing.T) {
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create soterd node: %s", err)
+	}
+	if err := r.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to complete soterd node setup: %s", err)
+	}
+
+	defer r.TearDown()
+
+	// submitBlock submits a block with the given parent, paying to a new
+	// address so that siblings are distinct blocks.
+	submitBlock := func(parent *soterutil.Block) *soterutil.Block {
+		addr, err := r.NewAddress()
+		if err != nil {
+			t.Fatalf("unable to generate new address: %v", err)
+		}
+
+		parentHash := chaincfg.SimNetParams.GenesisHash
+		if parent != nil {
+			parentHash = parent.Hash()
+		}
+		tipsHash := blockdag.GenerateTipsHash([]*chainhash.Hash{parentHash})
+		block, err := rpctest.CreateBlock(parent, tipsHash, nil,
+			rpctest.BlockVersion, time.Time{}, addr, nil, r.ActiveNet)
+		if err != nil {
+			t.Fatalf("unable to create block: %v", err)
+		}
+		reason, err := r.SubmitBlock(block)
+		if err != nil {
+			t.Fatalf("unable to submit block: %v", err)
+		}
+		if reason != "" {
+			t.Fatalf("block %v was rejected: %v", block.Hash(), reason)
+		}
+		return block
+	}
+
+	// Build the dag
+	//
+	//   genesis <- a <------- c
+	//          \             /
+	//           <- b <- b2 <-
+	//
+	// so that the branches through a and b are in each other's anticone,
+	// and the genesis block and c are ordered with every block.
+	genesis := chaincfg.SimNetParams.GenesisHash
+	a := submitBlock(nil)
+	b := submitBlock(nil)
+	b2 := submitBlock(b)
+	hashes, err := r.Node.GenerateWithParents(1,
+		[]*chainhash.Hash{a.Hash(), b2.Hash()})
+	if err != nil {
+		t.Fatalf("Call to `generatewithparents` failed: %v", err)
+	}
+	c := hashes[0]
+
+	tests := []struct {
+		name  string
+		block *chainhash.Hash
+		want  []*chainhash.Hash
+	}{
+		{"short branch", a.Hash(), []*chainhash.Hash{b.Hash(), b2.Hash()}},
+		{"long branch root", b.Hash(), []*chainhash.Hash{a.Hash()}},
+		{"long branch tip", b2.Hash(), []*chainhash.Hash{a.Hash()}},
+		{"merge block", c, nil},
+		{"genesis", genesis, nil},
+	}
+	for _, test := range tests {
+		result, err := r.Node.GetAnticone(test.block)
+		if err != nil {
+			t.Fatalf("%s: Call to `getanticone` failed: %v", test.name,
+				err)
+		}
+		if result.Hash != test.block.String() {
+			t.Fatalf("%s: got anticone of %v, want %v", test.name,
+				result.Hash, test.block)
+		}
+		if int(result.Size) != len(test.want) || result.Truncated {
+			t.Fatalf("%s: got size %d (truncated %v), want %d",
+				test.name, result.Size, result.Truncated,
+				len(test.want))
+		}
+		if len(result.Anticone) != len(test.want) {
+			t.Fatalf("%s: got anticone %v, want %v", test.name,
+				result.Anticone, test.want)
+		}
+		for i, hash := range test.want {
+			if result.Anticone[i] != hash.String() {
+				t.Fatalf("%s: got anticone %v, want %v", test.name,
+					result.Anticone, test.want)
+			}
+		}
+	}
+
+	// Blocks that aren't in the dag are reported as not found.
+	unknown := chainhash.DoubleHashH([]byte("unknown block"))
+	_, err = r.Node.GetAnticone(&unknown)
+	rpcErr, ok := err.(*soterjson.RPCError)
+	if !ok || rpcErr.Code != soterjson.ErrRPCBlockNotFound {
+		t.Fatalf("getanticone of an unknown block returned %v, want "+
+			"error code %d", err, soterjson.ErrRPCBlockNotFound)
 	}
 }
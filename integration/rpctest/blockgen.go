@@ -7,6 +7,7 @@ package rpctest
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"runtime"
@@ -223,3 +224,92 @@ func CreateBlock(prevBlock *soterutil.Block, prevHash *chainhash.Hash, inclusion
 	utilBlock.SetHeight(blockHeight)
 	return utilBlock, nil
 }
+
+// withGenesis returns a copy of the params, with the genesis block replaced by
+// the given block.  The proof of work limit of the copy is set from the bits
+// of the genesis block, so that the blocks after it are mined at the same
+// difficulty.
+func withGenesis(params *chaincfg.Params, genesis *wire.MsgBlock) *chaincfg.Params {
+	custom := *params
+	genesisHash := genesis.BlockHash()
+	custom.GenesisBlock = genesis
+	custom.GenesisHash = &genesisHash
+	custom.PowLimitBits = genesis.Header.Bits
+	custom.PowLimit = blockdag.CompactToBig(genesis.Header.Bits)
+
+	return &custom
+}
+
+// withBlockTiming returns a copy of the params, with the target time per block
+// and the proof of work limit replaced by the given values when they're
+// non-zero.
+//
+// The target timespan is scaled with the target time per block, so the number
+// of blocks between difficulty retargets stays the same.  The minimum
+// difficulty reduction time is kept at twice the target time per block, like
+// the params of the test networks.
+func withBlockTiming(params *chaincfg.Params, targetTimePerBlock time.Duration,
+	powLimitBits uint32) (*chaincfg.Params, error) {
+
+	custom := *params
+	if targetTimePerBlock != 0 {
+		// The dag measures the target time per block in milliseconds,
+		// so anything shorter would leave it dividing by zero.
+		if targetTimePerBlock < time.Millisecond {
+			return nil, fmt.Errorf("target time per block %v is "+
+				"shorter than the minimum of %v",
+				targetTimePerBlock, time.Millisecond)
+		}
+
+		blocksPerRetarget := params.TargetTimespan / params.TargetTimePerBlock
+		custom.TargetTimespan = targetTimePerBlock * blocksPerRetarget
+		custom.TargetTimePerBlock = targetTimePerBlock
+		custom.MinDiffReductionTime = targetTimePerBlock * 2
+	}
+	if powLimitBits != 0 {
+		custom.PowLimitBits = powLimitBits
+		custom.PowLimit = blockdag.CompactToBig(powLimitBits)
+	}
+
+	return &custom, nil
+}
+
+// validateGenesis returns an error if the genesis block of the params isn't
+// internally consistent.  The merkle root of the genesis block must match its
+// transactions, the genesis hash of the params must be the hash of the block,
+// and its difficulty can't be below the proof of work limit.
+func validateGenesis(params *chaincfg.Params) error {
+	genesis := params.GenesisBlock
+	if genesis == nil {
+		return errors.New("params have no genesis block")
+	}
+	if len(genesis.Transactions) == 0 {
+		return errors.New("genesis block has no transactions")
+	}
+
+	txns := make([]*soterutil.Tx, 0, len(genesis.Transactions))
+	for _, tx := range genesis.Transactions {
+		txns = append(txns, soterutil.NewTx(tx))
+	}
+	merkles := blockdag.BuildMerkleTreeStore(txns, false)
+	merkleRoot := merkles[len(merkles)-1]
+	if !genesis.Header.MerkleRoot.IsEqual(merkleRoot) {
+		return fmt.Errorf("genesis block merkle root %v doesn't match "+
+			"its transactions, which have merkle root %v",
+			genesis.Header.MerkleRoot, merkleRoot)
+	}
+
+	genesisHash := genesis.BlockHash()
+	if params.GenesisHash == nil || !params.GenesisHash.IsEqual(&genesisHash) {
+		return fmt.Errorf("genesis hash %v of params doesn't match the "+
+			"genesis block hash %v", params.GenesisHash, genesisHash)
+	}
+
+	target := blockdag.CompactToBig(genesis.Header.Bits)
+	if target.Sign() <= 0 || target.Cmp(params.PowLimit) > 0 {
+		return fmt.Errorf("genesis block target %064x is outside the "+
+			"proof of work limit %064x", target, params.PowLimit)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpctest
+
+import (
+	"sync"
+)
+
+// DefaultLogBufferSize is the size in bytes of the buffer holding the captured
+// output of a node, when HarnessOpts.LogBufferSize isn't set.
+const DefaultLogBufferSize = 1024 * 1024
+
+// logBuffer is a ring buffer which holds the last size bytes written to it.
+// Older bytes are overwritten once the buffer is full.
+//
+// NOTE: A logBuffer is safe for concurrent access, since the stdout and stderr
+// of a node are written to it from different goroutines.
+type logBuffer struct {
+	mtx  sync.Mutex
+	buf  []byte
+	next int
+	full bool
+}
+
+// newLogBuffer returns a logBuffer which holds up to size bytes.
+func newLogBuffer(size int) *logBuffer {
+	if size <= 0 {
+		size = DefaultLogBufferSize
+	}
+
+	return &logBuffer{
+		buf: make([]byte, size),
+	}
+}
+
+// Write satisfies the io.Writer interface.  Writes never fail, but only the
+// last bytes of writes larger than the buffer are kept.
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	n := len(p)
+	if n >= len(b.buf) {
+		copy(b.buf, p[n-len(b.buf):])
+		b.next = 0
+		b.full = true
+		return n, nil
+	}
+
+	copied := copy(b.buf[b.next:], p)
+	if copied < n {
+		copy(b.buf, p[copied:])
+		b.full = true
+	}
+	b.next = (b.next + n) % len(b.buf)
+	if b.next == 0 {
+		b.full = true
+	}
+
+	return n, nil
+}
+
+// Bytes returns a copy of the contents of the buffer, oldest bytes first.
+func (b *logBuffer) Bytes() []byte {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if !b.full {
+		return append([]byte(nil), b.buf[:b.next]...)
+	}
+
+	out := make([]byte, 0, len(b.buf))
+	out = append(out, b.buf[b.next:]...)
+	return append(out, b.buf[:b.next]...)
+}
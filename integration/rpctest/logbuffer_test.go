@@ -0,0 +1,88 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// This file is ignored during the regular tests due to the following build tag.
+// +build rpctest
+
+package rpctest
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/soteria-dag/soterd/chaincfg"
+)
+
+// TestLogBuffer tests that the log buffer keeps the most recent bytes written
+// to it.
+func TestLogBuffer(t *testing.T) {
+	tests := []struct {
+		writes []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"abc"}, "abc"},
+		{[]string{"abc", "defgh"}, "abcdefgh"},
+		// Filling the buffer exactly.
+		{[]string{"abcd", "efgh"}, "abcdefgh"},
+		// Wrapping around.
+		{[]string{"abcdef", "ghij"}, "cdefghij"},
+		{[]string{"abcdefgh", "ij", "klm"}, "fghijklm"},
+		// Writes larger than the buffer.
+		{[]string{"abc", "0123456789"}, "23456789"},
+	}
+
+	for i, test := range tests {
+		b := newLogBuffer(8)
+		for _, w := range test.writes {
+			n, err := b.Write([]byte(w))
+			if err != nil || n != len(w) {
+				t.Fatalf("#%d: Write returned %d, %v", i, n, err)
+			}
+		}
+
+		got := b.Bytes()
+		if !bytes.Equal(got, []byte(test.want)) {
+			t.Errorf("#%d: got %q, want %q", i, got, test.want)
+		}
+	}
+}
+
+// TestCaptureLogs tests that the output of a node is captured when the
+// harness is created with CaptureLogs set.
+func TestCaptureLogs(t *testing.T) {
+	h, err := NewWithOpts(&chaincfg.SimNetParams, nil, nil,
+		&HarnessOpts{CaptureLogs: true})
+	if err != nil {
+		t.Fatalf("unable to create harness: %v", err)
+	}
+	if err := h.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to set up harness: %v", err)
+	}
+	defer h.TearDown()
+
+	// Blocks submitted over rpc are logged by the node.
+	block, err := h.GenerateAndSubmitBlock(nil, -1, time.Time{})
+	if err != nil {
+		t.Fatalf("unable to submit block: %v", err)
+	}
+	want := []byte("Accepted block " + block.Hash().String() +
+		" via submitblock")
+
+	// The log line may take a moment to be written by the node.
+	deadline := time.Now().Add(time.Second * 10)
+	for !bytes.Contains(h.Logs(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("captured logs don't contain %q:\n%s", want,
+				h.Logs())
+		}
+		time.Sleep(time.Millisecond * 50)
+	}
+
+	// Harnesses don't capture logs by default.
+	if logs := mainHarness.Logs(); logs != nil {
+		t.Fatalf("logs captured without CaptureLogs: %q", logs)
+	}
+}
@@ -171,6 +171,19 @@ func (m *memWallet) SyncedHeight() int32 {
 	return m.currentHeight
 }
 
+// skipToHeight marks the wallet as synced to the passed height, if it's behind
+// it, without ingesting the blocks up to the height.
+//
+// This function is safe for concurrent access.
+func (m *memWallet) skipToHeight(height int32) {
+	m.Lock()
+	defer m.Unlock()
+
+	if height > m.currentHeight {
+		m.currentHeight = height
+	}
+}
+
 // SetRPCClient saves the passed rpc connection to soterd as the wallet's
 // personal rpc connection.
 func (m *memWallet) SetRPCClient(rpcClient *rpcclient.Client) {
@@ -7,6 +7,7 @@ package rpctest
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -36,6 +37,11 @@ type nodeConfig struct {
 	prefix     string
 	// Whether to keep logs generated by node
 	keepLogs   bool
+	// Whether the data directory was given by the caller, in which case
+	// it's kept
+	keepDataDir bool
+	// Where the stdout and stderr of the node are written, if anywhere
+	output io.Writer
 
 	exe          string
 	endpoint     string
@@ -44,8 +50,10 @@ type nodeConfig struct {
 	certificates []byte
 }
 
-// newConfig returns a newConfig with all default values.
-func newConfig(prefix, certFile, keyFile string, extra []string, keepLogs bool) (*nodeConfig, error) {
+// newConfig returns a newConfig with all default values.  If dataDir is empty,
+// a temporary data directory is created for the node, and removed by cleanup().
+// Otherwise the node uses the given data directory, which is kept.
+func newConfig(prefix, certFile, keyFile, dataDir string, extra []string, keepLogs bool) (*nodeConfig, error) {
 	soterdPath, err := soterdExecutablePath()
 	if err != nil {
 		soterdPath = "soterd"
@@ -64,6 +72,10 @@ func newConfig(prefix, certFile, keyFile string, extra []string, keepLogs bool)
 		certFile:  certFile,
 		keyFile:   keyFile,
 	}
+	if dataDir != "" {
+		a.dataDir = dataDir
+		a.keepDataDir = true
+	}
 	if err := a.setDefaults(); err != nil {
 		return nil, err
 	}
@@ -72,13 +84,15 @@ func newConfig(prefix, certFile, keyFile string, extra []string, keepLogs bool)
 
 // setDefaults sets the default values of the config. It also creates the
 // temporary data, and log directories which must be cleaned up with a call to
-// cleanup().
+// cleanup().  The data directory is only created if none was given.
 func (n *nodeConfig) setDefaults() error {
-	datadir, err := ioutil.TempDir("", n.prefix+"-data")
-	if err != nil {
-		return err
+	if n.dataDir == "" {
+		datadir, err := ioutil.TempDir("", n.prefix+"-data")
+		if err != nil {
+			return err
+		}
+		n.dataDir = datadir
 	}
-	n.dataDir = datadir
 	logdir, err := ioutil.TempDir("", n.prefix+"-logs")
 	if err != nil {
 		return err
@@ -142,7 +156,12 @@ func (n *nodeConfig) arguments() []string {
 
 // command returns the exec.Cmd which will be used to start the soterd process.
 func (n *nodeConfig) command() *exec.Cmd {
-	return exec.Command(n.exe, n.arguments()...)
+	cmd := exec.Command(n.exe, n.arguments()...)
+	if n.output != nil {
+		cmd.Stdout = n.output
+		cmd.Stderr = n.output
+	}
+	return cmd
 }
 
 // rpcConnConfig returns the rpc connection config that can be used to connect
@@ -163,13 +182,17 @@ func (n *nodeConfig) String() string {
 	return n.prefix
 }
 
-// cleanup removes the tmp data and log directories.
+// cleanup removes the tmp data and log directories.  A data directory given by
+// the caller is kept.
 func (n *nodeConfig) cleanup() error {
 	dirs := []string{
-		n.dataDir,
 		n.netCfgFile,
 	}
 
+	if !n.keepDataDir {
+		dirs = append(dirs, n.dataDir)
+	}
+
 	if !n.keepLogs {
 		dirs = append(dirs, n.logDir)
 	}
@@ -192,6 +215,10 @@ type node struct {
 	pidFile string
 
 	dataDir string
+
+	// ports are the listening ports reserved for the node in the port
+	// pool.
+	ports []int
 }
 
 // newNode creates a new node instance according to the passed config. dataDir
@@ -211,6 +238,9 @@ func newNode(config *nodeConfig, dataDir string) (*node, error) {
 // test case, or panic, it is important that the process be stopped via stop(),
 // otherwise, it will persist unless explicitly killed.
 func (n *node) start() error {
+	// Stop holding the node's reserved ports, so that it can bind to them.
+	portPool.handOff(n.ports...)
+
 	if err := n.cmd.Start(); err != nil {
 		return err
 	}
@@ -243,12 +273,44 @@ func (n *node) stop() error {
 		return nil
 	}
 	defer n.cmd.Wait()
+	return n.interrupt()
+}
+
+// interrupt signals the running soterd process to shut down, without waiting
+// for it to exit.  On windows, interrupt is not supported, so a kill signal is
+// used instead.
+func (n *node) interrupt() error {
 	if runtime.GOOS == "windows" {
 		return n.cmd.Process.Signal(os.Kill)
 	}
 	return n.cmd.Process.Signal(os.Interrupt)
 }
 
+// waitOrKill waits up to the timeout for the soterd process to exit after it was
+// asked to shut down, and kills it if it's still running once the timeout
+// elapses.  It returns whether the process exited on its own.
+func (n *node) waitOrKill(timeout time.Duration) bool {
+	if n.cmd == nil || n.cmd.Process == nil {
+		return true
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		n.cmd.Wait()
+		close(exited)
+	}()
+
+	select {
+	case <-exited:
+		return true
+	case <-time.After(timeout):
+	}
+
+	n.cmd.Process.Kill()
+	<-exited
+	return false
+}
+
 // cleanup cleanups process and args files. The file housing the pid of the
 // created process will be deleted, as well as any directories created by the
 // process.
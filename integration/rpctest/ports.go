@@ -0,0 +1,154 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpctest
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// portAllocator hands out ports for the listeners of test nodes, so that
+// harnesses created concurrently never choose the same ports.  A port is
+// reserved by binding a listener to it, which is held until the node the
+// port was reserved for starts, so that other processes can't take the port
+// in the meantime.
+type portAllocator struct {
+	// The min port is inclusive while the max port is exclusive.
+	minPort int
+	maxPort int
+
+	// next is the port the allocator tries next.
+	next int
+
+	// held contains the listeners of reserved ports whose node hasn't
+	// started yet.
+	held map[int]net.Listener
+
+	// reserved contains every reserved port until it is released.
+	reserved map[int]struct{}
+
+	sync.Mutex
+}
+
+// newPortAllocator returns a portAllocator that hands out ports from the
+// given range.
+func newPortAllocator(minPort, maxPort int) *portAllocator {
+	a := &portAllocator{
+		held:     make(map[int]net.Listener),
+		reserved: make(map[int]struct{}),
+	}
+	a.setRange(minPort, maxPort)
+	return a
+}
+
+// setRange sets the range of ports the allocator hands out.  Allocation
+// starts at an offset into the range based on the process ID, in order to
+// allow multiple processes to run in parallel without trying the same ports.
+//
+// This function MUST be called with the allocator lock held (for writes).
+func (a *portAllocator) setRange(minPort, maxPort int) {
+	a.minPort = minPort
+	a.maxPort = maxPort
+	a.next = minPort + ((20 * processID) % (maxPort - minPort))
+}
+
+// reserve returns a free port, which stays reserved until it is released.
+//
+// This function is safe for concurrent access.
+func (a *portAllocator) reserve() (int, error) {
+	a.Lock()
+	defer a.Unlock()
+
+	for i := 0; i < a.maxPort-a.minPort; i++ {
+		port := a.next
+		a.next++
+		if a.next >= a.maxPort {
+			a.next = a.minPort
+		}
+
+		if _, ok := a.reserved[port]; ok {
+			continue
+		}
+
+		// The port is in use by another process when it can't be bound.
+		addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			continue
+		}
+
+		a.held[port] = listener
+		a.reserved[port] = struct{}{}
+		return port, nil
+	}
+
+	return 0, fmt.Errorf("no free ports in range [%d, %d)", a.minPort,
+		a.maxPort)
+}
+
+// handOff closes the listeners held for the given ports, so that a node can
+// bind to them.  The ports stay reserved until they are released.
+//
+// This function is safe for concurrent access.
+func (a *portAllocator) handOff(ports ...int) {
+	a.Lock()
+	defer a.Unlock()
+
+	for _, port := range ports {
+		if listener, ok := a.held[port]; ok {
+			_ = listener.Close()
+			delete(a.held, port)
+		}
+	}
+}
+
+// release frees the given ports, so that they can be reserved again.
+//
+// This function is safe for concurrent access.
+func (a *portAllocator) release(ports ...int) {
+	a.Lock()
+	defer a.Unlock()
+
+	for _, port := range ports {
+		if listener, ok := a.held[port]; ok {
+			_ = listener.Close()
+			delete(a.held, port)
+		}
+		delete(a.reserved, port)
+	}
+}
+
+// isReserved returns whether the given port is currently reserved.
+//
+// This function is safe for concurrent access.
+func (a *portAllocator) isReserved(port int) bool {
+	a.Lock()
+	defer a.Unlock()
+
+	_, ok := a.reserved[port]
+	return ok
+}
+
+// SetPortRange sets the range of ports that harnesses created afterwards use
+// for their p2p and rpc listeners.  The min port is inclusive while the max
+// port is exclusive.  Ports reserved by existing harnesses stay reserved
+// until the harnesses are torn down.
+//
+// This is useful in environments that restrict the ports tests may bind to.
+//
+// This function is safe for concurrent access.
+func SetPortRange(minPort, maxPort int) error {
+	if minPort <= 0 || maxPort > 65536 || minPort >= maxPort {
+		return fmt.Errorf("invalid port range [%d, %d)", minPort, maxPort)
+	}
+
+	portPool.Lock()
+	defer portPool.Unlock()
+
+	portPool.setRange(minPort, maxPort)
+	return nil
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// This file is ignored during the regular tests due to the following build tag.
+// +build rpctest
+
+package rpctest
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/soteria-dag/soterd/chaincfg"
+)
+
+// TestPortAllocator tests reserving, handing off and releasing ports.
+func TestPortAllocator(t *testing.T) {
+	a := newPortAllocator(minPort, maxPort)
+
+	p1, err := a.reserve()
+	if err != nil {
+		t.Fatalf("unable to reserve port: %v", err)
+	}
+	p2, err := a.reserve()
+	if err != nil {
+		t.Fatalf("unable to reserve port: %v", err)
+	}
+	if p1 == p2 {
+		t.Fatalf("the same port %d was reserved twice", p1)
+	}
+
+	// A held port can't be bound by anything else.
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(p1))
+	if l, err := net.Listen("tcp", addr); err == nil {
+		l.Close()
+		t.Fatalf("able to bind held port %d", p1)
+	}
+
+	// Once handed off, the port can be bound, but stays reserved.
+	a.handOff(p1)
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("unable to bind handed off port %d: %v", p1, err)
+	}
+	l.Close()
+	if !a.isReserved(p1) {
+		t.Fatalf("handed off port %d is no longer reserved", p1)
+	}
+
+	a.release(p1, p2)
+	if a.isReserved(p1) || a.isReserved(p2) {
+		t.Fatalf("released ports are still reserved")
+	}
+
+	// Ports that are in use by something else are skipped.
+	b := newPortAllocator(p2, p2+2)
+	l, err = net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(p2)))
+	if err != nil {
+		t.Fatalf("unable to bind port %d: %v", p2, err)
+	}
+	defer l.Close()
+	b.next = p2
+	port, err := b.reserve()
+	if err != nil {
+		t.Fatalf("unable to reserve port: %v", err)
+	}
+	defer b.release(port)
+	if port != p2+1 {
+		t.Fatalf("expected port %d to be reserved, got %d", p2+1, port)
+	}
+	if _, err := b.reserve(); err == nil {
+		t.Fatalf("reserved a port from an exhausted range")
+	}
+}
+
+// TestSetPortRange tests that invalid port ranges are rejected.
+func TestSetPortRange(t *testing.T) {
+	tests := []struct {
+		min, max int
+		valid    bool
+	}{
+		{minPort, maxPort, true},
+		{20000, 20001, true},
+		{0, 20000, false},
+		{20000, 20000, false},
+		{20000, 10000, false},
+		{20000, 70000, false},
+	}
+
+	defer SetPortRange(minPort, maxPort)
+	for i, test := range tests {
+		err := SetPortRange(test.min, test.max)
+		if test.valid && err != nil {
+			t.Errorf("SetPortRange #%d unexpected error: %v", i, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("SetPortRange #%d accepted invalid range "+
+				"[%d, %d)", i, test.min, test.max)
+		}
+	}
+}
+
+// TestConcurrentHarnesses creates harnesses concurrently, and checks that
+// none of them share ports.
+func TestConcurrentHarnesses(t *testing.T) {
+	const numHarnesses = 20
+
+	var wg sync.WaitGroup
+	harnesses := make([]*Harness, numHarnesses)
+	errs := make([]error, numHarnesses)
+	for i := 0; i < numHarnesses; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			h, err := New(&chaincfg.SimNetParams, nil, nil, false)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			harnesses[i] = h
+			errs[i] = h.SetUp(false, 0)
+		}(i)
+	}
+	wg.Wait()
+
+	var ports []int
+	defer func() {
+		for _, h := range harnesses {
+			if h != nil {
+				_ = h.TearDown()
+			}
+		}
+
+		// Tearing down the harnesses should release their ports.
+		for _, port := range ports {
+			if portPool.isReserved(port) {
+				t.Errorf("port %d still reserved after teardown", port)
+			}
+		}
+	}()
+
+	seen := make(map[int]int)
+	for i, h := range harnesses {
+		if errs[i] != nil {
+			t.Fatalf("unable to set up harness %d: %v", i, errs[i])
+		}
+
+		for _, port := range h.node.ports {
+			if j, ok := seen[port]; ok {
+				t.Fatalf("harnesses %d and %d share port %d", j, i,
+					port)
+			}
+			seen[port] = i
+			ports = append(ports, port)
+		}
+
+		if _, err := h.Node.GetBlockCount(); err != nil {
+			t.Fatalf("unable to query harness %d: %v", i, err)
+		}
+	}
+}
@@ -6,12 +6,16 @@
 package rpctest
 
 import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -19,22 +23,29 @@ import (
 	"github.com/soteria-dag/soterd/chaincfg"
 	"github.com/soteria-dag/soterd/chaincfg/chainhash"
 	"github.com/soteria-dag/soterd/rpcclient"
+	"github.com/soteria-dag/soterd/soterjson"
 	"github.com/soteria-dag/soterd/soterutil"
 	"github.com/soteria-dag/soterd/wire"
 )
 
 const (
-	// These constants define the minimum and maximum p2p and rpc port
-	// numbers used by a test harness.  The min port is inclusive while the
-	// max port is exclusive.
-	minPeerPort = 10000
-	maxPeerPort = 35000
-	minRPCPort  = maxPeerPort
-	maxRPCPort  = 60000
+	// These constants define the default minimum and maximum p2p and rpc
+	// port numbers used by a test harness.  The min port is inclusive while
+	// the max port is exclusive.  See SetPortRange to change them.
+	minPort = 10000
+	maxPort = 60000
 
 	// BlockVersion is the default block version used when generating
 	// blocks.
 	BlockVersion = 4
+
+	// DefaultBlockPollInterval is how often WaitForBlock polls the node when
+	// the harness doesn't set a BlockPollInterval.
+	DefaultBlockPollInterval = time.Millisecond * 100
+
+	// DefaultRPCTimeout is the request timeout of the harness RPC client
+	// when HarnessOpts.RPCTimeout isn't set.
+	DefaultRPCTimeout = time.Minute * 5
 )
 
 var (
@@ -51,6 +62,10 @@ var (
 	// running or simply due to the stars aligning on the process IDs.
 	processID = os.Getpid()
 
+	// portPool reserves the p2p and rpc ports of test harnesses, so that
+	// concurrently created harnesses don't collide.
+	portPool = newPortAllocator(minPort, maxPort)
+
 	// testInstances is a private package-level slice used to keep track of
 	// all active test harnesses. This global can be used to perform
 	// various "joins", shutdown several active harnesses after a test,
@@ -61,6 +76,28 @@ var (
 	harnessStateMtx sync.RWMutex
 )
 
+var (
+	// SetUpRetryInterval is how long NewWithRetry waits before its second
+	// attempt at creating a harness.  The wait doubles after each further
+	// failed attempt, up to MaxSetUpRetryInterval.
+	SetUpRetryInterval = time.Millisecond * 250
+
+	// MaxSetUpRetryInterval is the longest NewWithRetry waits between
+	// attempts at creating a harness.
+	MaxSetUpRetryInterval = time.Second * 5
+
+	// ErrShutdownTimeout is returned by TearDownGraceful when the node
+	// didn't shut down within the timeout, and was killed.
+	ErrShutdownTimeout = errors.New("node didn't shut down within the " +
+		"timeout, and was killed")
+
+	// harnessSetUp sets up the harnesses created by NewWithRetry.  It's a
+	// variable so that tests can inject failures.
+	harnessSetUp = func(h *Harness) error {
+		return h.SetUp(false, 0)
+	}
+)
+
 // HarnessTestCase represents a test-case which utilizes an instance of the
 // Harness to exercise functionality.
 type HarnessTestCase func(r *Harness, t *testing.T)
@@ -79,28 +116,141 @@ type Harness struct {
 	// to.
 	ActiveNet *chaincfg.Params
 
+	// BlockPollInterval is how often WaitForBlock polls the node. When it
+	// is zero, DefaultBlockPollInterval is used.
+	BlockPollInterval time.Duration
+
 	Node     *rpcclient.Client
 	node     *node
 	handlers *rpcclient.NotificationHandlers
 
 	wallet *memWallet
 
+	// logs holds the captured output of the node, when
+	// HarnessOpts.CaptureLogs is set.
+	logs *logBuffer
+
 	testNodeDir    string
 	maxConnRetries int
 	nodeNum        int
 
+	// rpcTimeout is the request timeout of the RPC client, or zero when
+	// requests wait for their reply indefinitely.
+	rpcTimeout time.Duration
+
 	sync.Mutex
 }
 
+// HarnessOpts are options that control how NewWithOpts creates a harness.
+type HarnessOpts struct {
+	// KeepLogs keeps the log directory of the node after the harness is
+	// torn down.
+	KeepLogs bool
+
+	// CaptureLogs captures the stdout and stderr of the node in memory,
+	// where they can be read with Logs.
+	CaptureLogs bool
+
+	// LogBufferSize is the maximum number of bytes of output captured when
+	// CaptureLogs is set.  Once the limit is reached, the oldest output is
+	// dropped.  When it is zero, DefaultLogBufferSize is used.
+	LogBufferSize int
+
+	// Genesis replaces the genesis block of the params the harness is
+	// created with.  The proof of work limit of the params is set from the
+	// bits of the block, so blocks after it are mined at its difficulty.
+	// The harness' ActiveNet is the modified copy of the params.
+	Genesis *wire.MsgBlock
+
+	// TargetTimePerBlock replaces the target time per block of the
+	// params, so that tests can mine blocks in rapid succession without
+	// the difficulty rising to slow them down.  It can't be shorter than
+	// a millisecond.  When it is zero, the target time of the params is
+	// kept.
+	TargetTimePerBlock time.Duration
+
+	// PowLimitBits replaces the proof of work limit of the params, which
+	// is the minimum difficulty blocks are mined at, in compact form.
+	// The genesis block must be at least as difficult, so a harder limit
+	// than the params' needs a Genesis mined at it.  When it is zero, the
+	// limit of the params is kept.
+	PowLimitBits uint32
+
+	// RPCTimeout is how long requests of the harness RPC client wait for a
+	// reply before failing with rpcclient.ErrRequestTimeout.  The client
+	// also pings the node at half the timeout, so a node that stopped
+	// responding is disconnected instead of leaving calls hanging.  When
+	// it is zero, DefaultRPCTimeout is used, and when it is negative,
+	// requests wait for their reply indefinitely.
+	RPCTimeout time.Duration
+
+	// MinerSeed seeds the mining of the node, so that blocks generated on
+	// the same blocks are identical across runs.  The miner chooses its
+	// extra nonces from the seed, blocks are given the earliest timestamp
+	// the consensus rules allow, and the wallet keys, including the
+	// mining address, are derived from the seed instead of the harness
+	// number.  This is for reproducing tests that depend on block hashes
+	// or on the order of blocks, and the node shouldn't be used for
+	// anything else.  When it is zero, mining isn't seeded.
+	MinerSeed int64
+
+	// DataDir is the data directory of the node.  Pointing a new harness
+	// at the data directory of a harness that was torn down starts the
+	// node with the blocks of the earlier one, for testing restarts and
+	// recovery.  The directory must not be in use by another node, and it
+	// isn't removed when the harness is torn down.  When it is empty, a
+	// temporary data directory is created for the node, and removed when
+	// the harness is torn down.
+	//
+	// The harness wallet doesn't track the outputs of blocks that were in
+	// the data directory before the harness was set up.
+	DataDir string
+}
+
 // New creates and initializes new instance of the rpc test harness.
 // Optionally, websocket handlers and a specified configuration may be passed.
 // In the case that a nil config is passed, a default configuration will be
 // used.
 //
+// The activeNet params may be those of any of the supported chain networks, or
+// a modified copy of them. The node is started on the network of the params,
+// and the modified settings are applied to it through a netcfg file.
+//
 // NOTE: This function is safe for concurrent access.
 func New(activeNet *chaincfg.Params, handlers *rpcclient.NotificationHandlers,
 	extraArgs []string, keepLogs bool) (*Harness, error) {
 
+	return NewWithOpts(activeNet, handlers, extraArgs, &HarnessOpts{
+		KeepLogs: keepLogs,
+	})
+}
+
+// NewWithOpts is like New, but the harness is created according to the
+// options.  A nil opts is the same as the zero HarnessOpts.
+//
+// NOTE: This function is safe for concurrent access.
+func NewWithOpts(activeNet *chaincfg.Params, handlers *rpcclient.NotificationHandlers,
+	extraArgs []string, opts *HarnessOpts) (*Harness, error) {
+
+	if opts == nil {
+		opts = &HarnessOpts{}
+	}
+
+	if opts.Genesis != nil {
+		activeNet = withGenesis(activeNet, opts.Genesis)
+	}
+	if opts.TargetTimePerBlock != 0 || opts.PowLimitBits != 0 {
+		var err error
+		activeNet, err = withBlockTiming(activeNet,
+			opts.TargetTimePerBlock, opts.PowLimitBits)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := validateGenesis(activeNet); err != nil {
+		return nil, err
+	}
+
 	harnessStateMtx.Lock()
 	defer harnessStateMtx.Unlock()
 
@@ -137,7 +287,13 @@ func New(activeNet *chaincfg.Params, handlers *rpcclient.NotificationHandlers,
 		return nil, err
 	}
 
-	wallet, err := newMemWallet(activeNet, uint32(numTestInstances))
+	walletID := uint32(numTestInstances)
+	if opts.MinerSeed != 0 {
+		walletID = uint32(opts.MinerSeed)
+		extraArgs = append(extraArgs,
+			fmt.Sprintf("--miningseed=%d", opts.MinerSeed))
+	}
+	wallet, err := newMemWallet(activeNet, walletID)
 	if err != nil {
 		return nil, err
 	}
@@ -145,11 +301,18 @@ func New(activeNet *chaincfg.Params, handlers *rpcclient.NotificationHandlers,
 	miningAddr := fmt.Sprintf("--miningaddr=%s", wallet.coinbaseAddr)
 	extraArgs = append(extraArgs, miningAddr)
 
-	config, err := newConfig("rpctest", certFile, keyFile, extraArgs, keepLogs)
+	config, err := newConfig("rpctest", certFile, keyFile, opts.DataDir,
+		extraArgs, opts.KeepLogs)
 	if err != nil {
 		return nil, err
 	}
 
+	var logs *logBuffer
+	if opts.CaptureLogs {
+		logs = newLogBuffer(opts.LogBufferSize)
+		config.output = logs
+	}
+
 	// Generate a netCfgFile, for applying custom chaincfg.Params values on the node.
 	netCfg, err := ioutil.TempFile("", config.prefix + "-netCfg*.ini")
 	if err != nil {
@@ -159,6 +322,8 @@ func New(activeNet *chaincfg.Params, handlers *rpcclient.NotificationHandlers,
 	config.netCfgFile = netCfg.Name()
 	err = soterutil.WriteNetCfg(netCfg, activeNet)
 	if err != nil {
+		_ = netCfg.Close()
+		_ = os.Remove(config.netCfgFile)
 		return nil, err
 	}
 
@@ -172,13 +337,19 @@ func New(activeNet *chaincfg.Params, handlers *rpcclient.NotificationHandlers,
 	config.extra = append(config.extra, fmt.Sprintf("--netcfgfile=\"%s\"", config.netCfgFile))
 
 	// Generate p2p+rpc listening addresses.
-	config.listen, config.rpcListen = generateListeningAddresses()
+	var ports []int
+	config.listen, config.rpcListen, ports, err = generateListeningAddresses()
+	if err != nil {
+		return nil, err
+	}
 
 	// Create the testing node bounded to the simnet.
 	node, err := newNode(config, nodeTestData)
 	if err != nil {
+		portPool.release(ports...)
 		return nil, err
 	}
+	node.ports = ports
 
 	nodeNum := numTestInstances
 	numTestInstances++
@@ -211,14 +382,24 @@ func New(activeNet *chaincfg.Params, handlers *rpcclient.NotificationHandlers,
 		handlers.OnFilteredBlockDisconnected = wallet.UnwindBlock
 	}
 
+	rpcTimeout := opts.RPCTimeout
+	switch {
+	case rpcTimeout == 0:
+		rpcTimeout = DefaultRPCTimeout
+	case rpcTimeout < 0:
+		rpcTimeout = 0
+	}
+
 	h := &Harness{
 		handlers:       handlers,
 		node:           node,
 		maxConnRetries: 20,
+		rpcTimeout:     rpcTimeout,
 		testNodeDir:    nodeTestData,
 		ActiveNet:      activeNet,
 		nodeNum:        nodeNum,
 		wallet:         wallet,
+		logs:           logs,
 	}
 
 	// Track this newly created test instance within the package level
@@ -228,6 +409,78 @@ func New(activeNet *chaincfg.Params, handlers *rpcclient.NotificationHandlers,
 	return h, nil
 }
 
+// NewWithRetry creates a new instance of the rpc test harness and sets it up,
+// retrying up to the given number of attempts when either step fails.  This
+// guards against transient failures such as port collisions with other
+// processes.  The harness of a failed attempt is torn down to free its ports
+// and directories before the next attempt, and attempts are spaced out with
+// an exponential backoff starting at SetUpRetryInterval.
+//
+// The harness is set up without a test chain, as with SetUp(false, 0).  See
+// New for details on the other parameters.
+//
+// NOTE: This function must not be called concurrently with SetUp or TearDown.
+func NewWithRetry(activeNet *chaincfg.Params, handlers *rpcclient.NotificationHandlers,
+	extraArgs []string, attempts int) (*Harness, error) {
+
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	interval := SetUpRetryInterval
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+			interval *= 2
+			if interval > MaxSetUpRetryInterval {
+				interval = MaxSetUpRetryInterval
+			}
+		}
+
+		// New wraps the wallet callbacks of the handlers and appends to
+		// the extra args, so each attempt is given its own copy of them.
+		var attemptHandlers *rpcclient.NotificationHandlers
+		if handlers != nil {
+			handlersCopy := *handlers
+			attemptHandlers = &handlersCopy
+		}
+		args := append([]string(nil), extraArgs...)
+
+		var h *Harness
+		h, err = New(activeNet, attemptHandlers, args, false)
+		if err != nil {
+			continue
+		}
+
+		err = harnessSetUp(h)
+		if err == nil {
+			return h, nil
+		}
+
+		// The error from tearing down is ignored, since the set up error
+		// is more useful to the caller.
+		_ = h.TearDown()
+	}
+
+	return nil, fmt.Errorf("unable to set up harness after %d attempts: %v",
+		attempts, err)
+}
+
+// Logs returns the most recent output of the node, up to the
+// HarnessOpts.LogBufferSize limit.  It returns nil unless the harness was
+// created with HarnessOpts.CaptureLogs set.  The output of the node is kept
+// across restarts.
+//
+// This function is safe for concurrent access.
+func (h *Harness) Logs() []byte {
+	if h.logs == nil {
+		return nil
+	}
+
+	return h.logs.Bytes()
+}
+
 // LogDir returns the logDir used by the node
 func (h *Harness) LogDir() string {
 	return h.node.config.logDir
@@ -252,18 +505,18 @@ func (h *Harness) SetUp(createTestChain bool, numMatureOutputs uint32) error {
 
 	h.wallet.Start()
 
-	// Filter transactions that pay to the coinbase associated with the
-	// wallet.
-	filterAddrs := []soterutil.Address{h.wallet.coinbaseAddr}
-	if err := h.Node.LoadTxFilter(true, filterAddrs, nil); err != nil {
+	if err := h.registerWalletNtfns(); err != nil {
 		return err
 	}
 
-	// Ensure soterd properly dispatches our registered call-back for each new
-	// block. Otherwise, the memWallet won't function properly.
-	if err := h.Node.NotifyBlocks(); err != nil {
+	// A node started on an existing data directory already has blocks,
+	// which the wallet isn't notified of, so the wallet starts out synced
+	// to them.
+	_, height, err := h.Node.GetBestBlock()
+	if err != nil {
 		return err
 	}
+	h.wallet.skipToHeight(height)
 
 	// Create a test chain with the desired number of mature coinbase
 	// outputs.
@@ -278,7 +531,7 @@ func (h *Harness) SetUp(createTestChain bool, numMatureOutputs uint32) error {
 
 	// Block until the wallet has fully synced up to the tip of the main
 	// chain.
-	_, height, err := h.Node.GetBestBlock()
+	_, height, err = h.Node.GetBestBlock()
 	if err != nil {
 		return err
 	}
@@ -294,6 +547,51 @@ func (h *Harness) SetUp(createTestChain bool, numMatureOutputs uint32) error {
 	return nil
 }
 
+// registerWalletNtfns registers the notifications the in-memory wallet relies on
+// with the harness' rpc client.
+func (h *Harness) registerWalletNtfns() error {
+	// Filter transactions that pay to the coinbase associated with the
+	// wallet.
+	filterAddrs := []soterutil.Address{h.wallet.coinbaseAddr}
+	if err := h.Node.LoadTxFilter(true, filterAddrs, nil); err != nil {
+		return err
+	}
+
+	// Ensure soterd properly dispatches our registered call-back for each new
+	// block. Otherwise, the memWallet won't function properly.
+	return h.Node.NotifyBlocks()
+}
+
+// Restart stops the running soterd process and starts it again, keeping its
+// data directory and listening addresses.  The harness' rpc client is
+// reconnected to the restarted process, but other clients connected to it are
+// disconnected, and peer connections to other nodes are dropped.
+//
+// NOTE: This method is not concurrent safe with the other harness methods.
+func (h *Harness) Restart() error {
+	if h.Node != nil {
+		h.Node.Shutdown()
+		h.Node.WaitForShutdown()
+	}
+
+	if err := h.node.stop(); err != nil {
+		return err
+	}
+
+	// An exec.Cmd can only be started once, so create a new one for the
+	// restarted process.
+	h.node.cmd = h.node.config.command()
+	if err := h.node.start(); err != nil {
+		return err
+	}
+
+	if err := h.connectRPCClient(); err != nil {
+		return err
+	}
+
+	return h.registerWalletNtfns()
+}
+
 // tearDown stops the running rpc test instance.  All created processes are
 // killed, and temporary directories removed.
 //
@@ -307,6 +605,16 @@ func (h *Harness) tearDown() error {
 		return err
 	}
 
+	return h.release()
+}
+
+// release frees the ports and the test directory of the harness once its node
+// has exited, and stops tracking the harness.
+//
+// This function MUST be called with the harness state mutex held (for writes).
+func (h *Harness) release() error {
+	portPool.release(h.node.ports...)
+
 	if err := os.RemoveAll(h.testNodeDir); err != nil {
 		return err
 	}
@@ -328,6 +636,60 @@ func (h *Harness) TearDown() error {
 	return h.tearDown()
 }
 
+// TearDownGraceful stops the running rpc test instance like TearDown, but asks
+// the node to shut down with the stop RPC, and waits up to the timeout for it to
+// exit, so that it closes its database cleanly.  This is for tests that reuse
+// the data directory of the node (see HarnessOpts.DataDir).  The node is sent an
+// interrupt instead if the stop RPC fails.  If the node is still running once
+// the timeout elapses, it's killed, and ErrShutdownTimeout is returned after
+// the harness is torn down.
+//
+// NOTE: This method and SetUp should always be called from the same goroutine
+// as they are not concurrent safe.
+func (h *Harness) TearDownGraceful(timeout time.Duration) error {
+	harnessStateMtx.Lock()
+	defer harnessStateMtx.Unlock()
+
+	// A node that stopped responding doesn't reply to the stop RPC, so
+	// the request is given up on at the deadline too.
+	deadline := time.Now().Add(timeout)
+	if h.node.cmd != nil && h.node.cmd.Process != nil {
+		var stopped bool
+		if h.Node != nil {
+			result := make(chan error, 1)
+			go func() {
+				result <- h.Node.Stop()
+			}()
+			select {
+			case err := <-result:
+				stopped = err == nil
+			case <-time.After(timeout):
+			}
+		}
+		if !stopped {
+			// The process may have exited already, in which case
+			// there's nothing to interrupt.
+			_ = h.node.interrupt()
+		}
+	}
+	if h.Node != nil {
+		h.Node.Shutdown()
+	}
+
+	exited := h.node.waitOrKill(time.Until(deadline))
+	if err := h.node.cleanup(); err != nil {
+		return err
+	}
+	if err := h.release(); err != nil {
+		return err
+	}
+
+	if !exited {
+		return ErrShutdownTimeout
+	}
+	return nil
+}
+
 // connectRPCClient attempts to establish an RPC connection to the created soterd
 // process belonging to this Harness instance. If the initial connection
 // attempt fails, this function will retry h.maxConnRetries times, backing off
@@ -338,7 +700,7 @@ func (h *Harness) connectRPCClient() error {
 	var client *rpcclient.Client
 	var err error
 
-	rpcConf := h.node.config.rpcConnConfig()
+	rpcConf := h.rpcConnConfig()
 	for i := 0; i < h.maxConnRetries; i++ {
 		if client, err = rpcclient.New(&rpcConf, h.handlers); err != nil {
 			time.Sleep(time.Duration(i) * 50 * time.Millisecond)
@@ -356,6 +718,15 @@ func (h *Harness) connectRPCClient() error {
 	return nil
 }
 
+// rpcConnConfig returns the config of the RPC client for the node, with the
+// harness' request timeout and keepalive pings.
+func (h *Harness) rpcConnConfig() rpcclient.ConnConfig {
+	rpcConf := h.node.config.rpcConnConfig()
+	rpcConf.RequestTimeout = h.rpcTimeout
+	rpcConf.PingInterval = h.rpcTimeout / 2
+	return rpcConf
+}
+
 // NewAddress returns a fresh address spendable by the Harness' internal
 // wallet.
 //
@@ -434,6 +805,59 @@ func (h *Harness) P2PAddress() string {
 	return h.node.config.listen
 }
 
+// hasBlock returns whether the node knows about the block with the given
+// hash, either as one of its dag tips or as a block buried beneath them.
+func (h *Harness) hasBlock(hash *chainhash.Hash) (bool, error) {
+	tips, err := h.Node.GetDAGTips()
+	if err != nil {
+		return false, err
+	}
+	for _, tip := range tips.Tips {
+		if tip == hash.String() {
+			return true, nil
+		}
+	}
+
+	// The block may already have children, in which case it's no longer a
+	// tip but is still part of the dag.
+	_, err = h.Node.GetBlockHeader(hash)
+	if err == nil {
+		return true, nil
+	}
+	if rpcErr, ok := err.(*soterjson.RPCError); ok &&
+		rpcErr.Code == soterjson.ErrRPCBlockNotFound {
+		return false, nil
+	}
+	return false, err
+}
+
+// WaitForBlock blocks until the block with the given hash is part of the
+// node's dag, polling the node every BlockPollInterval. An error is returned
+// if the block hasn't arrived before the timeout elapses.
+func (h *Harness) WaitForBlock(hash *chainhash.Hash, timeout time.Duration) error {
+	interval := h.BlockPollInterval
+	if interval <= 0 {
+		interval = DefaultBlockPollInterval
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		found, err := h.hasBlock(hash)
+		if err != nil {
+			return err
+		}
+		if found {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for block %v on node %s",
+				hash, h.P2PAddress())
+		}
+		time.Sleep(interval)
+	}
+}
+
 // GenerateAndSubmitBlock creates a block whose contents include the passed
 // transactions and submits it to the running simnet node. For generating
 // blocks with only a coinbase tx, callers can simply pass nil instead of
@@ -510,23 +934,63 @@ func (h *Harness) GenerateAndSubmitBlockWithCustomCoinbaseOutputs(
 	return newBlock, nil
 }
 
+// SubmitBlock submits the passed block to the running simnet node using the
+// submitblock RPC.  The block is serialized with the same encoding the node
+// uses for blocks on the wire.  An empty reason is returned if the node accepted
+// the block, otherwise the reason the node gave for rejecting it is returned.
+// The returned error is only set if the block couldn't be submitted.
+//
+// This function is safe for concurrent access.
+func (h *Harness) SubmitBlock(block *soterutil.Block) (string, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	blockBytes, err := block.Bytes()
+	if err != nil {
+		return "", err
+	}
+	param, err := json.Marshal(hex.EncodeToString(blockBytes))
+	if err != nil {
+		return "", err
+	}
+
+	// The node replies with null when it accepts the block, and with the
+	// reason for the rejection otherwise.
+	reply, err := h.Node.RawRequest("submitblock", []json.RawMessage{param})
+	if err != nil {
+		return "", err
+	}
+	var result *string
+	if err := json.Unmarshal(reply, &result); err != nil {
+		return "", err
+	}
+	if result == nil {
+		return "", nil
+	}
+
+	return strings.TrimPrefix(*result, "rejected: "), nil
+}
+
 // generateListeningAddresses returns two strings representing listening
-// addresses designated for the current rpc test. If there haven't been any
-// test instances created, the default ports are used. Otherwise, in order to
-// support multiple test nodes running at once, the p2p and rpc port are
-// incremented after each initialization.
-func generateListeningAddresses() (string, string) {
+// addresses designated for the current rpc test, along with the ports they
+// use.  The ports are reserved with the port pool, so that no other harness
+// is given them until they are released.
+func generateListeningAddresses() (string, string, []int, error) {
 	localhost := "127.0.0.1"
 
-	portString := func(minPort, maxPort int) string {
-		port := minPort + numTestInstances + ((20 * processID) %
-			(maxPort - minPort))
-		return strconv.Itoa(port)
+	p2pPort, err := portPool.reserve()
+	if err != nil {
+		return "", "", nil, err
+	}
+	rpcPort, err := portPool.reserve()
+	if err != nil {
+		portPool.release(p2pPort)
+		return "", "", nil, err
 	}
 
-	p2p := net.JoinHostPort(localhost, portString(minPeerPort, maxPeerPort))
-	rpc := net.JoinHostPort(localhost, portString(minRPCPort, maxRPCPort))
-	return p2p, rpc
+	p2p := net.JoinHostPort(localhost, strconv.Itoa(p2pPort))
+	rpc := net.JoinHostPort(localhost, strconv.Itoa(rpcPort))
+	return p2p, rpc, []int{p2pPort, rpcPort}, nil
 }
 
 // baseDir is the directory path of the temp directory for all rpctest files.
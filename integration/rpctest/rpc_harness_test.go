@@ -9,13 +9,18 @@
 package rpctest
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/soteria-dag/soterd/blockdag"
 	"github.com/soteria-dag/soterd/chaincfg"
 	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+	"github.com/soteria-dag/soterd/rpcclient"
 	"github.com/soteria-dag/soterd/txscript"
 	"github.com/soteria-dag/soterd/wire"
 	"github.com/soteria-dag/soterd/soterutil"
@@ -467,6 +472,85 @@ func testGenerateAndSubmitBlockWithCustomCoinbaseOutputs(r *Harness,
 	}
 }
 
+func testSubmitBlock(r *Harness, t *testing.T) {
+	addr, err := r.NewAddress()
+	if err != nil {
+		t.Fatalf("unable to generate new address: %v", err)
+	}
+
+	// createBlock builds a block on top of the current best block of the
+	// harness, without submitting it.
+	createBlock := func() *soterutil.Block {
+		bestHash, bestHeight, err := r.Node.GetBestBlock()
+		if err != nil {
+			t.Fatalf("unable to get best block: %v", err)
+		}
+		mBlock, err := r.Node.GetBlock(bestHash)
+		if err != nil {
+			t.Fatalf("unable to get block %v: %v", bestHash, err)
+		}
+		prevBlock := soterutil.NewBlock(mBlock)
+		prevBlock.SetHeight(bestHeight)
+
+		prevHash := blockdag.GenerateTipsHash([]*chainhash.Hash{bestHash})
+		block, err := CreateBlock(prevBlock, prevHash, nil, BlockVersion,
+			time.Time{}, addr, nil, r.ActiveNet)
+		if err != nil {
+			t.Fatalf("unable to create block: %v", err)
+		}
+		return block
+	}
+
+	// isTip returns true if the block is one of the tips of the harness's
+	// dag.
+	isTip := func(hash *chainhash.Hash) bool {
+		tips, err := r.Node.GetDAGTips()
+		if err != nil {
+			t.Fatalf("unable to get dag tips: %v", err)
+		}
+		for _, tip := range tips.Tips {
+			if tip == hash.String() {
+				return true
+			}
+		}
+		return false
+	}
+
+	// A well-formed block should be accepted, and become a tip of the dag.
+	block := createBlock()
+	reason, err := r.SubmitBlock(block)
+	if err != nil {
+		t.Fatalf("unable to submit block: %v", err)
+	}
+	if reason != "" {
+		t.Fatalf("block %v was rejected: %v", block.Hash(), reason)
+	}
+	if !isTip(block.Hash()) {
+		t.Fatalf("accepted block %v isn't a tip of the dag", block.Hash())
+	}
+
+	// A block with a second coinbase transaction should be rejected, and
+	// not become part of the dag.
+	malformed := createBlock().MsgBlock()
+	coinbase := malformed.Transactions[0].Copy()
+	coinbase.TxIn[0].SignatureScript = append(
+		coinbase.TxIn[0].SignatureScript, 0x00)
+	if err := malformed.AddTransaction(coinbase); err != nil {
+		t.Fatalf("unable to add transaction: %v", err)
+	}
+	block = soterutil.NewBlock(malformed)
+	reason, err = r.SubmitBlock(block)
+	if err != nil {
+		t.Fatalf("unable to submit block: %v", err)
+	}
+	if reason == "" {
+		t.Fatalf("malformed block %v wasn't rejected", block.Hash())
+	}
+	if isTip(block.Hash()) {
+		t.Fatalf("rejected block %v is a tip of the dag", block.Hash())
+	}
+}
+
 func testMemWalletReorg(r *Harness, t *testing.T) {
 	// Create a fresh harness, we'll be using the main harness to force a
 	// re-org on this local harness.
@@ -555,6 +639,7 @@ var harnessTestCases = []HarnessTestCase{
 	testJoinMempools, // Depends on results of testJoinBlocks
 	testGenerateAndSubmitBlock,
 	testGenerateAndSubmitBlockWithCustomCoinbaseOutputs,
+	testSubmitBlock,
 	testMemWalletReorg,
 	testMemWalletLockedOutputs,
 }
@@ -629,3 +714,358 @@ func TestHarness(t *testing.T) {
 
 	testTearDownAll(t)
 }
+
+func TestNewWithRetry(t *testing.T) {
+	oldSetUp := harnessSetUp
+	oldInterval := SetUpRetryInterval
+	defer func() {
+		harnessSetUp = oldSetUp
+		SetUpRetryInterval = oldInterval
+	}()
+	SetUpRetryInterval = time.Millisecond * 10
+
+	// Fail the first set up, as if the harness' ports were taken.
+	var attempts int
+	harnessSetUp = func(h *Harness) error {
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("injected set up failure")
+		}
+		return oldSetUp(h)
+	}
+
+	numInitialHarnesses := len(ActiveHarnesses())
+	harness, err := NewWithRetry(&chaincfg.SimNetParams, nil, nil, 3)
+	if err != nil {
+		t.Fatalf("NewWithRetry failed: %v", err)
+	}
+	defer harness.TearDown()
+
+	if attempts != 2 {
+		t.Fatalf("expected 2 set up attempts, got %d", attempts)
+	}
+
+	// The harness of the failed attempt should have been torn down.
+	if len(ActiveHarnesses()) != numInitialHarnesses+1 {
+		t.Fatalf("expected %d active harnesses, got %d",
+			numInitialHarnesses+1, len(ActiveHarnesses()))
+	}
+
+	if _, err := harness.Node.GetBlockCount(); err != nil {
+		t.Fatalf("unable to query recovered harness: %v", err)
+	}
+
+	// When every attempt fails, the last error is returned.
+	attempts = 0
+	harnessSetUp = func(h *Harness) error {
+		attempts++
+		return fmt.Errorf("injected set up failure")
+	}
+	if _, err := NewWithRetry(&chaincfg.SimNetParams, nil, nil, 2); err == nil {
+		t.Fatalf("NewWithRetry succeeded when every set up failed")
+	}
+	if attempts != 2 {
+		t.Fatalf("expected 2 set up attempts, got %d", attempts)
+	}
+	if len(ActiveHarnesses()) != numInitialHarnesses+1 {
+		t.Fatalf("failed harnesses weren't torn down")
+	}
+}
+
+// customGenesis returns a copy of the simnet genesis block with a different
+// timestamp, so that it has a different hash.
+func customGenesis(t *testing.T) *wire.MsgBlock {
+	var buf bytes.Buffer
+	if err := chaincfg.SimNetParams.GenesisBlock.Serialize(&buf); err != nil {
+		t.Fatalf("unable to serialize genesis block: %v", err)
+	}
+	var genesis wire.MsgBlock
+	if err := genesis.Deserialize(&buf); err != nil {
+		t.Fatalf("unable to deserialize genesis block: %v", err)
+	}
+	genesis.Header.Timestamp = time.Unix(1500000000, 0)
+
+	return &genesis
+}
+
+func TestCustomGenesis(t *testing.T) {
+	genesis := customGenesis(t)
+	wantHash := genesis.BlockHash()
+	if wantHash.IsEqual(chaincfg.SimNetParams.GenesisHash) {
+		t.Fatalf("custom genesis has the same hash as the simnet genesis")
+	}
+
+	harness, err := NewWithOpts(&chaincfg.SimNetParams, nil, nil,
+		&HarnessOpts{Genesis: genesis})
+	if err != nil {
+		t.Fatalf("unable to create harness: %v", err)
+	}
+	if err := harness.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to set up harness: %v", err)
+	}
+	defer harness.TearDown()
+
+	if !harness.ActiveNet.GenesisHash.IsEqual(&wantHash) {
+		t.Fatalf("harness params have genesis hash %v, wanted %v",
+			harness.ActiveNet.GenesisHash, wantHash)
+	}
+
+	// The node should be running on top of the custom genesis.
+	hash, err := harness.Node.GetBlockHash(0)
+	if err != nil {
+		t.Fatalf("getblockhash 0 failed: %v", err)
+	}
+	if !hash.IsEqual(&wantHash) {
+		t.Fatalf("getblockhash 0 returned %v, wanted %v", hash, wantHash)
+	}
+
+	// Blocks can be mined on top of it.
+	if _, err := harness.Node.Generate(1); err != nil {
+		t.Fatalf("unable to generate block on custom genesis: %v", err)
+	}
+
+	// A genesis block which doesn't match its transactions is rejected.
+	bad := customGenesis(t)
+	bad.Header.MerkleRoot = chainhash.Hash{}
+	if _, err := NewWithOpts(&chaincfg.SimNetParams, nil, nil,
+		&HarnessOpts{Genesis: bad}); err == nil {
+		t.Fatalf("harness created with inconsistent genesis block")
+	}
+
+	// So are params whose genesis hash isn't the hash of the block.
+	badParams := chaincfg.SimNetParams
+	badParams.GenesisBlock = genesis
+	if _, err := New(&badParams, nil, nil, false); err == nil {
+		t.Fatalf("harness created with mismatched genesis hash")
+	}
+}
+
+// seededBlocks generates blocks on a harness with the given miner seed, and
+// returns their hashes.
+func seededBlocks(t *testing.T, seed int64, numBlocks uint32) []*chainhash.Hash {
+	harness, err := NewWithOpts(&chaincfg.SimNetParams, nil, nil,
+		&HarnessOpts{MinerSeed: seed})
+	if err != nil {
+		t.Fatalf("unable to create harness: %v", err)
+	}
+	if err := harness.SetUp(false, 0); err != nil {
+		t.Fatalf("unable to set up harness: %v", err)
+	}
+	defer harness.TearDown()
+
+	hashes, err := harness.Node.Generate(numBlocks)
+	if err != nil {
+		t.Fatalf("unable to generate blocks: %v", err)
+	}
+	return hashes
+}
+
+func TestMinerSeed(t *testing.T) {
+	const numBlocks = 5
+
+	// Generating blocks again with the same seed gives the same blocks.
+	first := seededBlocks(t, 1, numBlocks)
+	second := seededBlocks(t, 1, numBlocks)
+	if len(first) != numBlocks || len(second) != numBlocks {
+		t.Fatalf("generated %d and %d blocks, wanted %d", len(first),
+			len(second), numBlocks)
+	}
+	for i := range first {
+		if !first[i].IsEqual(second[i]) {
+			t.Fatalf("block %d of the second run is %v, wanted %v", i,
+				second[i], first[i])
+		}
+	}
+
+	// A different seed gives different blocks.
+	other := seededBlocks(t, 2, 1)
+	if other[0].IsEqual(first[0]) {
+		t.Fatalf("blocks generated with different seeds are identical")
+	}
+}
+
+// blackHole returns a listener which accepts connections and never replies on
+// them, like a node which has hung.
+func blackHole(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, conn := range conns {
+				conn.Close()
+			}
+		}()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	return listener
+}
+
+func TestRPCTimeout(t *testing.T) {
+	listener := blackHole(t)
+	defer listener.Close()
+
+	const timeout = time.Millisecond * 500
+	const deadline = timeout * 10
+	h := &Harness{
+		ActiveNet: &chaincfg.SimNetParams,
+		node: &node{config: &nodeConfig{
+			rpcListen: listener.Addr().String(),
+			rpcUser:   "user",
+			rpcPass:   "pass",
+		}},
+		maxConnRetries: 1,
+		rpcTimeout:     timeout,
+	}
+
+	// Connecting to a node which never completes the websocket handshake
+	// fails.
+	start := time.Now()
+	if err := h.connectRPCClient(); err == nil {
+		t.Fatalf("connected to a node which never replies")
+	}
+	if elapsed := time.Since(start); elapsed > deadline {
+		t.Fatalf("connecting took %v, wanted less than %v", elapsed,
+			deadline)
+	}
+
+	// Calls to a node which never replies fail within the deadline.
+	rpcConf := h.rpcConnConfig()
+	rpcConf.HTTPPostMode = true
+	rpcConf.DisableTLS = true
+	client, err := rpcclient.New(&rpcConf, nil)
+	if err != nil {
+		t.Fatalf("unable to create client: %v", err)
+	}
+	defer client.Shutdown()
+
+	start = time.Now()
+	if _, err := client.GetBlockCount(); err != rpcclient.ErrRequestTimeout {
+		t.Fatalf("getblockcount: unexpected error. Got %v, wanted %v",
+			err, rpcclient.ErrRequestTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > deadline {
+		t.Fatalf("getblockcount took %v, wanted less than %v", elapsed,
+			deadline)
+	}
+}
+
+// dataDirHarness creates and sets up a harness using the given data directory.
+func dataDirHarness(t *testing.T, dataDir string) *Harness {
+	harness, err := NewWithOpts(&chaincfg.SimNetParams, nil, nil,
+		&HarnessOpts{DataDir: dataDir})
+	if err != nil {
+		t.Fatalf("unable to create harness: %v", err)
+	}
+	if err := harness.SetUp(false, 0); err != nil {
+		harness.TearDown()
+		t.Fatalf("unable to set up harness: %v", err)
+	}
+	return harness
+}
+
+func TestDataDir(t *testing.T) {
+	const numBlocks = 3
+
+	dataDir, err := ioutil.TempDir("", "rpctest-datadir")
+	if err != nil {
+		t.Fatalf("unable to create data directory: %v", err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	harness := dataDirHarness(t, dataDir)
+	hashes, err := harness.Node.Generate(numBlocks)
+	if err != nil {
+		harness.TearDown()
+		t.Fatalf("unable to generate blocks: %v", err)
+	}
+	if err := harness.TearDown(); err != nil {
+		t.Fatalf("unable to tear down harness: %v", err)
+	}
+
+	// The data directory was given by the test, so it's kept.
+	if _, err := os.Stat(dataDir); err != nil {
+		t.Fatalf("data directory removed by teardown: %v", err)
+	}
+
+	// A new harness on the same data directory has the blocks.
+	harness = dataDirHarness(t, dataDir)
+	defer harness.TearDown()
+
+	for _, hash := range hashes {
+		if _, err := harness.Node.GetBlock(hash); err != nil {
+			t.Fatalf("block %v is missing after restart: %v", hash,
+				err)
+		}
+	}
+
+	// Blocks can be generated on top of them.
+	if _, err := harness.Node.Generate(1); err != nil {
+		t.Fatalf("unable to generate block after restart: %v", err)
+	}
+
+	// A harness with its own data directory removes it on teardown.
+	isolated, err := New(&chaincfg.SimNetParams, nil, nil, false)
+	if err != nil {
+		t.Fatalf("unable to create harness: %v", err)
+	}
+	isolatedDir := isolated.node.config.dataDir
+	if err := isolated.TearDown(); err != nil {
+		t.Fatalf("unable to tear down harness: %v", err)
+	}
+	if _, err := os.Stat(isolatedDir); !os.IsNotExist(err) {
+		t.Fatalf("temporary data directory %s kept after teardown",
+			isolatedDir)
+	}
+}
+
+func TestTearDownGraceful(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "rpctest-datadir")
+	if err != nil {
+		t.Fatalf("unable to create data directory: %v", err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	harness := dataDirHarness(t, dataDir)
+	hashes, err := harness.Node.Generate(5)
+	if err != nil {
+		harness.TearDown()
+		t.Fatalf("unable to generate blocks: %v", err)
+	}
+	if err := harness.TearDownGraceful(time.Minute); err != nil {
+		t.Fatalf("unable to tear down harness gracefully: %v", err)
+	}
+
+	// The database was closed cleanly, so reopening it doesn't repair it.
+	harness, err = NewWithOpts(&chaincfg.SimNetParams, nil, nil,
+		&HarnessOpts{DataDir: dataDir, CaptureLogs: true})
+	if err != nil {
+		t.Fatalf("unable to create harness: %v", err)
+	}
+	if err := harness.SetUp(false, 0); err != nil {
+		harness.TearDown()
+		t.Fatalf("unable to set up harness: %v", err)
+	}
+	defer harness.TearDown()
+
+	if bytes.Contains(harness.Logs(), []byte("unclean shutdown")) {
+		t.Fatalf("database was repaired after a graceful teardown:\n%s",
+			harness.Logs())
+	}
+	for _, hash := range hashes {
+		if _, err := harness.Node.GetBlock(hash); err != nil {
+			t.Fatalf("block %v is missing after restart: %v", hash,
+				err)
+		}
+	}
+}
@@ -7,11 +7,14 @@ package rpctest
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/soteria-dag/soterd/soterutil"
 	"github.com/wcharczuk/go-chart"
 	"io/ioutil"
 	"reflect"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/soteria-dag/soterd/chaincfg/chainhash"
@@ -72,19 +75,6 @@ func colorPicker (v int) string {
 }
 
 
-// We'll provide renderDagsDot with a function for picking style of the blocks.
-//
-// The stylePicker should return a string in the graphviz format:
-// "filled" if input is true, or 
-// "filled, dashed" if otherwise
-func stylePicker (v bool) string {
-
-	if (v) {
-		return "filled"
-	} 	
-	return "filled, dashed"
-}
-
 // keys returns the keys for the map of integers
 func keys(m map[int32]int) []int32 {
 	k := make([]int32, 0)
@@ -157,7 +147,7 @@ func CountDAGBlocks(node *Harness) (int, float64, error) {
 	}
 
 	for height := int32(0); height <= tips.MaxHeight; height++ {
-		hashes, err := node.Node.GetBlockHash(int64(height))
+		hashes, err := node.Node.GetDagBlockHashes(height)
 		if err != nil {
 			return count, meanInterval(keys(mpbHeights)), err
 		}
@@ -186,7 +176,7 @@ func DumpDAG(i int, node *Harness) error {
 	}
 
 	for height := int32(0); height <= tips.MaxHeight; height++ {
-		hashes, err := node.Node.GetBlockHash(int64(height))
+		hashes, err := node.Node.GetDagBlockHashes(height)
 		if err != nil {
 			return err
 		}
@@ -204,25 +194,115 @@ func DumpDAG(i int, node *Harness) error {
 	return nil
 }
 
+// RenderDagsDotOpts are options that control how RenderDagsDotWithOpts
+// renders a dag.
+type RenderDagsDotOpts struct {
+	// ColorByMiner colors each block according to the index of the node
+	// that created it.  Blocks whose creator is unknown are not colored.
+	ColorByMiner bool
+
+	// Palette overrides the colors used when ColorByMiner is set.  Each
+	// color is a string in the graphviz #rrggbb format, and the color for
+	// a node is picked by its index, wrapping around when there are more
+	// nodes than colors.  When empty, a deterministic default palette is
+	// used.
+	Palette []string
+
+	// MergeNodes renders the dags of all of the nodes merged into a single
+	// graph, instead of the dag of the first node, so that blocks which
+	// haven't reached the first node yet are rendered too.  Blocks the
+	// nodes share are rendered once, and their tooltip lists the nodes
+	// that have them.  See soterutil.MergeDagViews.
+	MergeNodes bool
+}
+
+// DefaultRenderDagsDotOpts returns the options used by RenderDagsDot.
+func DefaultRenderDagsDotOpts() *RenderDagsDotOpts {
+	return &RenderDagsDotOpts{
+		ColorByMiner: true,
+	}
+}
+
+// minerColor returns the color to use for blocks created by the node with the
+// given index.
+func (o *RenderDagsDotOpts) minerColor(creator int) string {
+	if len(o.Palette) > 0 {
+		return o.Palette[creator%len(o.Palette)]
+	}
+	return colorPicker(creator)
+}
+
+// dotMinerColor returns the function soterutil.DagToDot colors blocks with, which is nil when blocks aren't
+// colored by miner.
+func (o *RenderDagsDotOpts) dotMinerColor() func(int) string {
+	if !o.ColorByMiner {
+		return nil
+	}
+	return o.minerColor
+}
+
 // RenderDagsDot returns a representation of the dag in graphviz DOT file format.
 //
 // RenderDagsDot makes use of the "dot" command, which is a part of the "graphviz" suite of software.
 // http://graphviz.org/
+//
+// Blocks are colored by the node that created them. See RenderDagsDotWithOpts for more control over rendering.
 func RenderDagsDot(nodes []*Harness) ([]byte, error) {
-	var dot bytes.Buffer
-	// How many characters of a hash string to use for the 'label' of a block in the graph
-	smallHashLen := 7
+	return RenderDagsDotWithOpts(nodes, DefaultRenderDagsDotOpts())
+}
 
-	// Map blocks to the nodes that created them. This will be used to color blocks in dag
-	blockCreator := make(map[string]int)
-	for i, n := range nodes {
-		resp, err := n.Node.GetBlockMetrics()
+// RenderDagsDotWithOpts returns a representation of the dag in graphviz DOT file format, rendered according to
+// the given options. The dag of the first node is rendered, unless opts.MergeNodes is set, and block metrics from
+// all nodes are used to determine which node created each block.
+func RenderDagsDotWithOpts(nodes []*Harness, opts *RenderDagsDotOpts) ([]byte, error) {
+	if opts == nil {
+		opts = DefaultRenderDagsDotOpts()
+	}
+
+	dag, blockCreator, blockcoloring, err := fetchDag(nodes, opts.ColorByMiner)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	if !opts.MergeNodes {
+		return dagToDot(dag, blockCreator, blockcoloring, opts)
+	}
+
+	// The view of each node is colored by its own dag coloring, since the nodes may not agree on it yet.
+	views := make([][]soterutil.DotBlock, 0, len(nodes))
+	views = append(views, exportDotBlocks(dagToExport(dag, blockCreator, blockcoloring)))
+	for _, node := range nodes[1:] {
+		dag, _, blockcoloring, err := fetchDag([]*Harness{node}, false)
 		if err != nil {
-			continue
+			return []byte{}, err
 		}
+		views = append(views, exportDotBlocks(dagToExport(dag, blockCreator, blockcoloring)))
+	}
+
+	blocks, err := soterutil.MergeDagViews(views)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return soterutil.DagToDot(blocks, opts.dotMinerColor())
+}
+
+// fetchDag returns the dag of the first node as the blocks at each height, along with a map of block hashes to
+// whether they are blue in the dag coloring. When withCreators is set, block metrics from all nodes are used to map
+// block hashes to the index of the node that created them.
+func fetchDag(nodes []*Harness, withCreators bool) ([][]*wire.MsgBlock, map[string]int, map[string]bool, error) {
+	// Map blocks to the nodes that created them. This will be used to color blocks in dag
+	blockCreator := make(map[string]int)
+	if withCreators {
+		for i, n := range nodes {
+			resp, err := n.Node.GetBlockMetrics()
+			if err != nil {
+				continue
+			}
 
-		for _, hash := range resp.BlkHashes {
-			blockCreator[hash] = i
+			for _, hash := range resp.BlkHashes {
+				blockCreator[hash] = i
+			}
 		}
 	}
 
@@ -230,28 +310,26 @@ func RenderDagsDot(nodes []*Harness) ([]byte, error) {
 	node := nodes[0]
 	tips, err := node.Node.GetDAGTips()
 	if err != nil {
-		return dot.Bytes(), err
+		return nil, nil, nil, err
 	}
 
 	dag := make([][]*wire.MsgBlock, 0)
-	blockIndex := make(map[string]*wire.MsgBlock)
 
 	// Index all the blocks
 	for height := int32(0); height <= tips.MaxHeight; height++ {
 		blocks := make([]*wire.MsgBlock, 0)
 
-		hashes, err := node.Node.GetBlockHash(int64(height))
+		hashes, err := node.Node.GetDagBlockHashes(height)
 		if err != nil {
-			return dot.Bytes(), err
+			return nil, nil, nil, err
 		}
 
 		for _, hash := range hashes {
 			block, err := node.Node.GetBlock(hash)
 			if err != nil {
-				return dot.Bytes(), err
+				return nil, nil, nil, err
 			}
 
-			blockIndex[block.BlockHash().String()] = block
 			blocks = append(blocks, block)
 		}
 
@@ -261,7 +339,7 @@ func RenderDagsDot(nodes []*Harness) ([]byte, error) {
 	// Build a map of Block coloring Results 
 	dagcoloring, err := node.Node.GetDAGColoring()
 	if err != nil {
-		return dot.Bytes(), err
+		return nil, nil, nil, err
 	}
 	blockcoloring := make(map[string]bool)
 	for _, dagNode := range dagcoloring {
@@ -270,72 +348,359 @@ func RenderDagsDot(nodes []*Harness) ([]byte, error) {
 		blockcoloring[hash] = coloring
 	}
 
-	// Express dag in DOT file format
+	return dag, blockCreator, blockcoloring, nil
+}
+
+// DagExportBlock is a block of a dag exported by ExportDagJSON.
+type DagExportBlock struct {
+	Hash    string   `json:"hash"`
+	Height  int32    `json:"height"`
+	Parents []string `json:"parents"`
+	// Miner is the index of the node that created the block, or -1 if it's unknown.
+	Miner  int  `json:"miner"`
+	IsBlue bool `json:"isblue"`
+}
+
+// DagExportEdge is a parent reference of a dag exported by ExportDagJSON, from a block to one of its parents.
+type DagExportEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
 
-	// graphIndex tracks block hash -> graph node number, which is used to connect parent-child blocks together.
-	graphIndex := make(map[string]int)
-	// n keeps track of the 'node' number in graph file language
-	n := 0
+// DagExport is a dag exported by ExportDagJSON.
+type DagExport struct {
+	Blocks []DagExportBlock `json:"blocks"`
+	Edges  []DagExportEdge  `json:"edges"`
+}
 
-	// Specify that this graph is directed, and set the ID to 'dag'
-	_, err = fmt.Fprintln(&dot, "digraph dag {")
+// ExportDagJSON returns the dag in JSON format, as its blocks and the edges between them, for analysis in other
+// tools. The dag is gathered the same way as RenderDagsDot gathers it, so that the export is consistent with the
+// rendered dag: the dag of the first node is exported, and block metrics from all nodes are used to determine which
+// node created each block.
+func ExportDagJSON(nodes []*Harness) ([]byte, error) {
+	dag, blockCreator, blockcoloring, err := fetchDag(nodes, true)
 	if err != nil {
-		return dot.Bytes(), err
+		return []byte{}, err
+	}
+
+	return dagToJSON(dag, blockCreator, blockcoloring)
+}
+
+// dagToJSON expresses the dag in the JSON format of ExportDagJSON. The arguments are the same as for dagToDot.
+func dagToJSON(dag [][]*wire.MsgBlock, blockCreator map[string]int, blockcoloring map[string]bool) ([]byte, error) {
+	return json.Marshal(dagToExport(dag, blockCreator, blockcoloring))
+}
+
+// dagToExport returns the dag as a DagExport. The arguments are the same as for dagToDot.
+func dagToExport(dag [][]*wire.MsgBlock, blockCreator map[string]int, blockcoloring map[string]bool) *DagExport {
+	export := DagExport{
+		Blocks: make([]DagExportBlock, 0),
+		Edges:  make([]DagExportEdge, 0),
 	}
 
-	// Create a node in the graph for each block
 	for height, blocks := range dag {
 		for _, block := range blocks {
 			hash := block.BlockHash().String()
-			smallHashIndex := len(hash) - smallHashLen
-			graphIndex[hash] = n
 
-			// determine the coloring of the block and fetch the style string: default, "filled" or "filled,dashed"
-			dagcoloring := blockcoloring[hash]
-			style := stylePicker(dagcoloring)
+			miner, exists := blockCreator[hash]
+			if !exists {
+				miner = -1
+			}
 
-			creator, exists := blockCreator[hash]
+			parents := make([]string, 0, len(block.Parents.Parents))
+			for _, parent := range block.Parents.Parents {
+				parentHash := parent.Hash.String()
+				parents = append(parents, parentHash)
+				export.Edges = append(export.Edges, DagExportEdge{From: hash, To: parentHash})
+			}
 
-			var err error
-			if exists {
-				// color this block based on which miner created it
+			export.Blocks = append(export.Blocks, DagExportBlock{
+				Hash:    hash,
+				Height:  int32(height),
+				Parents: parents,
+				Miner:   miner,
+				IsBlue:  blockcoloring[hash],
+			})
+		}
+	}
 
-				color := colorPicker(creator)
-				_, err = fmt.Fprintf(&dot, "n%d [label=\"%s\", tooltip=\"node %d height %d hash %s\", fillcolor=\"%s\", style=\"%s\"];\n",
-					n, hash[smallHashIndex:], creator, height, hash, color, style)
-			} else {
-				// No color for this block
-				_, err = fmt.Fprintf(&dot, "n%d [label=\"%s\", tooltip=\"height %d hash %s\", style=\"%s\"];\n",
-					n, hash[smallHashIndex:], height, hash, style)
+	return &export
+}
+
+// RenderDagExportDot returns a representation of a dag exported by ExportDagJSON in graphviz DOT file format, the
+// same as RenderDagsDotWithOpts would have returned for the dag with the options. This way a recorded dag can be
+// rendered again without running any nodes.
+//
+// If opts is nil, DefaultRenderDagsDotOpts is used. An error is returned if the export isn't valid JSON, if a block
+// references a parent that isn't in the export, or if the edges don't match the parents of the blocks.
+func RenderDagExportDot(exportJSON []byte, opts *RenderDagsDotOpts) ([]byte, error) {
+	if opts == nil {
+		opts = DefaultRenderDagsDotOpts()
+	}
+
+	export, err := parseDagExport(exportJSON)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	dot, err := exportToDot(export, opts)
+	if err != nil {
+		return []byte{}, fmt.Errorf("invalid dag export: %s", err)
+	}
+
+	return dot, nil
+}
+
+// parseDagExport decodes a dag exported by ExportDagJSON, returning an error if the export isn't valid JSON or if
+// the edges don't match the parents of the blocks.
+func parseDagExport(exportJSON []byte) (*DagExport, error) {
+	var export DagExport
+	err := json.Unmarshal(exportJSON, &export)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dag export: %s", err)
+	}
+
+	// The edges are redundant with the parents of the blocks, so make sure they agree.
+	edges := make(map[DagExportEdge]int)
+	for _, edge := range export.Edges {
+		edges[edge]++
+	}
+	for _, block := range export.Blocks {
+		for _, parent := range block.Parents {
+			edge := DagExportEdge{From: block.Hash, To: parent}
+			if edges[edge] == 0 {
+				return nil, fmt.Errorf("invalid dag export: no edge from block %s to parent %s",
+					block.Hash, parent)
 			}
-			if err != nil {
-				return dot.Bytes(), err
+			edges[edge]--
+		}
+	}
+	for edge, count := range edges {
+		if count > 0 {
+			return nil, fmt.Errorf("invalid dag export: edge from %s to %s isn't a parent reference",
+				edge.From, edge.To)
+		}
+	}
+
+	return &export, nil
+}
+
+// exportToDot expresses an exported dag in DOT file format.
+func exportToDot(export *DagExport, opts *RenderDagsDotOpts) ([]byte, error) {
+	return soterutil.DagToDot(exportDotBlocks(export), opts.dotMinerColor())
+}
+
+// exportDotBlocks returns the blocks of an exported dag, for rendering with soterutil.DagToDot.
+func exportDotBlocks(export *DagExport) []soterutil.DotBlock {
+	blocks := make([]soterutil.DotBlock, 0, len(export.Blocks))
+	for _, block := range export.Blocks {
+		blocks = append(blocks, soterutil.DotBlock{
+			Hash:    block.Hash,
+			Height:  block.Height,
+			Parents: block.Parents,
+			Miner:   block.Miner,
+			IsBlue:  block.IsBlue,
+		})
+	}
+
+	return blocks
+}
+
+// dagToDot expresses the dag in DOT file format. The dag is given as the blocks at each height, blockCreator maps
+// block hashes to the index of the node that created them, and blockcoloring maps block hashes to whether they are
+// blue in the dag coloring.
+func dagToDot(dag [][]*wire.MsgBlock, blockCreator map[string]int, blockcoloring map[string]bool,
+	opts *RenderDagsDotOpts) ([]byte, error) {
+	return exportToDot(dagToExport(dag, blockCreator, blockcoloring), opts)
+}
+
+// RenderDagSubgraph returns a representation of the neighborhood of a block in the node's dag in graphviz DOT file
+// format, for bug reports that focus on one block. The neighborhood is the root block, its ancestors up to depth
+// parent links away, and its descendants up to depth child links away. Other blocks, like the siblings of the root,
+// aren't rendered, and neither are the parent references to blocks outside of the neighborhood.
+//
+// The depth is clamped to the bounds of the dag: a depth larger than the height of the dag renders all of the
+// ancestors and descendants of the root, and a negative depth renders the root alone. An error is returned if the
+// root isn't in the dag.
+func (h *Harness) RenderDagSubgraph(root *chainhash.Hash, depth int) ([]byte, error) {
+	dag, blockCreator, blockcoloring, err := fetchDag([]*Harness{h}, true)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	export, err := subgraphExport(dagToExport(dag, blockCreator, blockcoloring), root.String(), depth)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return exportToDot(export, DefaultRenderDagsDotOpts())
+}
+
+// subgraphExport returns the part of an exported dag within depth hops of the root block, following parent links to
+// its ancestors and child links to its descendants. See RenderDagSubgraph for how the depth is clamped.
+func subgraphExport(export *DagExport, root string, depth int) (*DagExport, error) {
+	index := make(map[string]*DagExportBlock, len(export.Blocks))
+	children := make(map[string][]string)
+	var maxHeight int32
+	for i := range export.Blocks {
+		block := &export.Blocks[i]
+		index[block.Hash] = block
+		for _, parent := range block.Parents {
+			children[parent] = append(children[parent], block.Hash)
+		}
+		if block.Height > maxHeight {
+			maxHeight = block.Height
+		}
+	}
+	if _, exists := index[root]; !exists {
+		return nil, fmt.Errorf("block %s isn't in the dag", root)
+	}
+
+	// A path through the dag is at most as long as the dag is high.
+	if depth > int(maxHeight) {
+		depth = int(maxHeight)
+	}
+	if depth < 0 {
+		depth = 0
+	}
+
+	// Walk up the parent links and down the child links separately, so that only ancestors and descendants of the
+	// root are included.
+	included := map[string]struct{}{root: {}}
+	walk := func(links func(hash string) []string) {
+		seen := map[string]struct{}{root: {}}
+		frontier := []string{root}
+		for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+			next := make([]string, 0)
+			for _, hash := range frontier {
+				for _, linked := range links(hash) {
+					if _, ok := seen[linked]; ok {
+						continue
+					}
+					seen[linked] = struct{}{}
+					included[linked] = struct{}{}
+					next = append(next, linked)
+				}
 			}
+			frontier = next
+		}
+	}
+	walk(func(hash string) []string {
+		if block, exists := index[hash]; exists {
+			return block.Parents
+		}
+		return nil
+	})
+	walk(func(hash string) []string {
+		return children[hash]
+	})
 
-			n++
+	sub := DagExport{
+		Blocks: make([]DagExportBlock, 0, len(included)),
+		Edges:  make([]DagExportEdge, 0),
+	}
+	for _, block := range export.Blocks {
+		if _, ok := included[block.Hash]; !ok {
+			continue
 		}
+
+		parents := make([]string, 0, len(block.Parents))
+		for _, parent := range block.Parents {
+			if _, ok := included[parent]; !ok {
+				continue
+			}
+			parents = append(parents, parent)
+			sub.Edges = append(sub.Edges, DagExportEdge{From: block.Hash, To: parent})
+		}
+		block.Parents = parents
+		sub.Blocks = append(sub.Blocks, block)
 	}
 
-	// Connect the nodes in the graph together
-	for _, blocks := range dag {
-		for _, block := range blocks {
-			blockN := graphIndex[block.BlockHash().String()]
+	return &sub, nil
+}
 
-			for _, parent := range block.Parents.Parents {
-				parentN := graphIndex[parent.Hash.String()]
+// RenderDagsGraphML returns a representation of the dag in GraphML format, for analysis tools that need the
+// metadata of the blocks. The dag is gathered the same way as RenderDagsDot gathers it: the dag of the first node is
+// rendered, and block metrics from all nodes are used to determine which node created each block.
+func RenderDagsGraphML(nodes []*Harness) ([]byte, error) {
+	dag, blockCreator, _, err := fetchDag(nodes, true)
+	if err != nil {
+		return []byte{}, err
+	}
 
-				_, err := fmt.Fprintf(&dot, "n%d -> n%d;\n", blockN, parentN)
-				if err != nil {
-					return dot.Bytes(), err
-				}
+	return dagToGraphML(dag, blockCreator)
+}
+
+// dagToGraphML expresses the dag in GraphML format. The arguments are the same as for dagToDot.
+func dagToGraphML(dag [][]*wire.MsgBlock, blockCreator map[string]int) ([]byte, error) {
+	blocks := make([]soterutil.GraphMLBlock, 0)
+	for height, heightBlocks := range dag {
+		for _, block := range heightBlocks {
+			hash := block.BlockHash().String()
+
+			miner, exists := blockCreator[hash]
+			if !exists {
+				miner = -1
+			}
+
+			parents := make([]string, 0, len(block.Parents.Parents))
+			for _, parent := range block.Parents.Parents {
+				parents = append(parents, parent.Hash.String())
 			}
+
+			blocks = append(blocks, soterutil.GraphMLBlock{
+				Hash:      hash,
+				Height:    int32(height),
+				Parents:   parents,
+				Miner:     miner,
+				Timestamp: block.Header.Timestamp,
+			})
 		}
 	}
 
-	// Close the graph statement list
-	dot.WriteString("}")
+	return soterutil.RenderDagGraphML(blocks)
+}
 
-	return dot.Bytes(), nil
+// RenderDagsASCII returns a text representation of the dag, for debugging in a terminal where graphviz isn't
+// available. The dag of the first node is rendered. See soterutil.RenderDagASCII for how the dag is drawn.
+func RenderDagsASCII(nodes []*Harness) ([]byte, error) {
+	dag, _, blockcoloring, err := fetchDag(nodes, false)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return exportToASCII(dagToExport(dag, nil, blockcoloring))
+}
+
+// RenderDagExportASCII returns a text representation of a dag exported by ExportDagJSON, the same as RenderDagsASCII
+// would have returned for the dag. An error is returned if the export isn't valid, the same as for
+// RenderDagExportDot.
+func RenderDagExportASCII(exportJSON []byte) ([]byte, error) {
+	export, err := parseDagExport(exportJSON)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	text, err := exportToASCII(export)
+	if err != nil {
+		return []byte{}, fmt.Errorf("invalid dag export: %s", err)
+	}
+
+	return text, nil
+}
+
+// exportToASCII expresses an exported dag as text.
+func exportToASCII(export *DagExport) ([]byte, error) {
+	blocks := make([]soterutil.ASCIIBlock, 0, len(export.Blocks))
+	for _, block := range export.Blocks {
+		blocks = append(blocks, soterutil.ASCIIBlock{
+			Hash:    block.Hash,
+			Height:  block.Height,
+			Parents: block.Parents,
+		})
+	}
+
+	return soterutil.RenderDagASCII(blocks)
 }
 
 // SaveDagHTML save an HTML document containing an svg image of the node's dag
@@ -597,7 +962,7 @@ func CompareDAG(nodes []*Harness) error {
 
 	dag := [][]*chainhash.Hash{}
 	for height := int32(0); height <= tips.MaxHeight; height++ {
-		hashes, err := targetNode.Node.GetBlockHash(int64(height))
+		hashes, err := targetNode.Node.GetDagBlockHashes(height)
 		if err != nil {
 			return err
 		}
@@ -612,7 +977,7 @@ func CompareDAG(nodes []*Harness) error {
 		}
 
 		for height, hashes := range dag {
-			otherHashes, err := node.Node.GetBlockHash(int64(height))
+			otherHashes, err := node.Node.GetDagBlockHashes(int32(height))
 			if err != nil {
 				return err
 			}
@@ -679,6 +1044,160 @@ func CompareDAG(nodes []*Harness) error {
 	return nil
 }
 
+// DagDiff is the difference between the dags of two nodes, as returned by
+// CompareDags.
+type DagDiff struct {
+	// OnlyA are the hashes of the blocks that only the first node has,
+	// which are missing from the second node.
+	OnlyA []*chainhash.Hash
+
+	// OnlyB are the hashes of the blocks that only the second node has,
+	// which are missing from the first node.
+	OnlyB []*chainhash.Hash
+}
+
+// Equal returns true if both nodes have the same set of blocks.
+func (d *DagDiff) Equal() bool {
+	return len(d.OnlyA) == 0 && len(d.OnlyB) == 0
+}
+
+// String returns a summary of the hashes unique to each node.
+func (d *DagDiff) String() string {
+	return fmt.Sprintf("only in a: %v, only in b: %v", d.OnlyA, d.OnlyB)
+}
+
+// dagHashes returns the hashes of all the blocks in the dag of a node.
+func dagHashes(node *Harness) (map[chainhash.Hash]struct{}, error) {
+	tips, err := node.Node.GetDAGTips()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[chainhash.Hash]struct{})
+	for height := int32(0); height <= tips.MaxHeight; height++ {
+		heightHashes, err := node.Node.GetDagBlockHashes(height)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, hash := range heightHashes {
+			hashes[*hash] = struct{}{}
+		}
+	}
+
+	return hashes, nil
+}
+
+// diffHashes returns the hashes in a which aren't in b, sorted so that the
+// diff of the same dags is always the same.
+func diffHashes(a, b map[chainhash.Hash]struct{}) []*chainhash.Hash {
+	var diff []*chainhash.Hash
+	for hash := range a {
+		if _, exists := b[hash]; !exists {
+			hash := hash
+			diff = append(diff, &hash)
+		}
+	}
+
+	sort.Slice(diff, func(i, j int) bool {
+		return bytes.Compare(diff[i][:], diff[j][:]) < 0
+	})
+
+	return diff
+}
+
+// CompareDags returns the difference between the set of blocks in the dags of
+// two nodes.  Unlike CompareDAG, which stops at the first difference, every
+// block unique to either node is reported.
+func CompareDags(a, b *Harness) (*DagDiff, error) {
+	hashesA, err := dagHashes(a)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch dag of node %v: %v", a.P2PAddress(), err)
+	}
+
+	hashesB, err := dagHashes(b)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch dag of node %v: %v", b.P2PAddress(), err)
+	}
+
+	diff := &DagDiff{
+		OnlyA: diffHashes(hashesA, hashesB),
+		OnlyB: diffHashes(hashesB, hashesA),
+	}
+
+	return diff, nil
+}
+
+// ConvergenceError is returned by WaitForConvergence when the nodes still have
+// different dags once the timeout expires.
+type ConvergenceError struct {
+	// Diffs are the differences between the dag of the first node and the
+	// dags of the nodes that disagree with it, by the index of the node.
+	Diffs map[int]*DagDiff
+}
+
+// Error returns the differences of the dags of the nodes that disagree with the
+// first node, ordered by the index of the node.
+func (e *ConvergenceError) Error() string {
+	indexes := make([]int, 0, len(e.Diffs))
+	for i := range e.Diffs {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	var buf bytes.Buffer
+	buf.WriteString("nodes didn't converge to the same dag")
+	for _, i := range indexes {
+		fmt.Fprintf(&buf, "; node 0 vs node %d: %v", i, e.Diffs[i])
+	}
+
+	return buf.String()
+}
+
+// WaitForConvergence waits up to timeout for all of the nodes to have the same
+// set of blocks in their dag.  If they haven't converged by then, a
+// *ConvergenceError with the differences between the dag of the first node and
+// the dags of the nodes that disagree with it is returned.
+func WaitForConvergence(miners []*Harness, timeout time.Duration) error {
+	pollInterval := time.Duration(time.Millisecond * 500)
+	waitThreshold := time.Now().Add(timeout)
+
+	if len(miners) < 2 {
+		return nil
+	}
+
+	for {
+		first, err := dagHashes(miners[0])
+		if err != nil {
+			return fmt.Errorf("unable to fetch dag of node 0: %v", err)
+		}
+
+		diffs := make(map[int]*DagDiff)
+		for i, miner := range miners[1:] {
+			hashes, err := dagHashes(miner)
+			if err != nil {
+				return fmt.Errorf("unable to fetch dag of node %d: %v", i+1, err)
+			}
+
+			diff := &DagDiff{
+				OnlyA: diffHashes(first, hashes),
+				OnlyB: diffHashes(hashes, first),
+			}
+			if !diff.Equal() {
+				diffs[i+1] = diff
+			}
+		}
+
+		if len(diffs) == 0 {
+			return nil
+		} else if time.Now().Before(waitThreshold) {
+			time.Sleep(pollInterval)
+		} else {
+			return &ConvergenceError{Diffs: diffs}
+		}
+	}
+}
+
 // IsConnected returns true if 'from' node is connected to 'to' node
 func IsConnected(from *Harness, to *Harness) (bool, error) {
 	toAddr := to.P2PAddress()
@@ -728,6 +1247,29 @@ func ConnectNode(from *Harness, to *Harness) error {
 	return nil
 }
 
+// connectPair establishes a peer-to-peer connection from the "from" harness
+// to the "to" harness, unless a connection has already been established from
+// either end.
+func connectPair(from *Harness, to *Harness) error {
+	connected, err := IsConnected(from, to)
+	if err != nil {
+		return err
+	}
+	if connected {
+		return nil
+	}
+
+	connected, err = IsConnected(to, from)
+	if err != nil {
+		return err
+	}
+	if connected {
+		return nil
+	}
+
+	return ConnectNode(from, to)
+}
+
 // ConnectNodes connects all the nodes to one another
 func ConnectNodes(nodes []*Harness) error {
 	for i, node := range nodes {
@@ -736,27 +1278,168 @@ func ConnectNodes(nodes []*Harness) error {
 				continue
 			}
 
-			// Skip nodes that already have a connection established from either end
-			connected, err := IsConnected(peer, node)
+			err := connectPair(peer, node)
 			if err != nil {
 				return err
 			}
-			if connected {
-				continue
-			}
+		}
+	}
 
-			connected, err = IsConnected(node, peer)
+	return nil
+}
+
+// ConnectNodesRing connects the nodes in a ring, where each node is connected
+// to the nodes before and after it in the slice, and the last node is
+// connected to the first.
+func ConnectNodesRing(nodes []*Harness) error {
+	if len(nodes) < 2 {
+		return nil
+	}
+
+	for i, node := range nodes {
+		next := (i + 1) % len(nodes)
+		err := connectPair(node, nodes[next])
+		if err != nil {
+			return fmt.Errorf("unable to connect node %d to node %d: %s", i, next, err)
+		}
+	}
+
+	return nil
+}
+
+// ConnectNodesStar connects each of the other nodes to the center node, and
+// to no other node.
+func ConnectNodesStar(center *Harness, others []*Harness) error {
+	for i, node := range others {
+		err := connectPair(node, center)
+		if err != nil {
+			return fmt.Errorf("unable to connect node %d to center node: %s", i, err)
+		}
+	}
+
+	return nil
+}
+
+// linkWaitTimeout is how long DisconnectNodes waits for the connections
+// between two nodes to be gone from their getpeerinfo results.
+const linkWaitTimeout = time.Second * 10
+
+// outboundPeers returns the peers which the "from" node has established an
+// outbound connection to the "to" node with.
+func outboundPeers(from *Harness, to *Harness) ([]soterjson.GetPeerInfoResult, error) {
+	toAddr := to.P2PAddress()
+
+	fromPeers, err := from.Node.GetPeerInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	var peers []soterjson.GetPeerInfoResult
+	for _, peerInfo := range fromPeers {
+		if peerInfo.Addr == toAddr {
+			peers = append(peers, peerInfo)
+		}
+	}
+
+	return peers, nil
+}
+
+// hasPeerAddr returns true if the node has a peer with the given address.
+func hasPeerAddr(node *Harness, addr string) (bool, error) {
+	peers, err := node.Node.GetPeerInfo()
+	if err != nil {
+		return false, err
+	}
+
+	for _, peerInfo := range peers {
+		if peerInfo.Addr == addr {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// disconnectOutbound severs the connections the "from" node has established to
+// the "to" node, and waits until getpeerinfo on both nodes stops listing them.
+func disconnectOutbound(from *Harness, to *Harness) error {
+	peers, err := outboundPeers(from, to)
+	if err != nil {
+		return err
+	}
+	if len(peers) == 0 {
+		return nil
+	}
+
+	// Connections made with ConnectNode are persistent, and need to be removed
+	// rather than disconnected so that they aren't re-established.
+	err = from.Node.Node(soterjson.NRemove, to.P2PAddress(), nil)
+	if err != nil {
+		for _, peerInfo := range peers {
+			id := strconv.Itoa(int(peerInfo.ID))
+			err := from.Node.Node(soterjson.NDisconnect, id, nil)
 			if err != nil {
 				return err
 			}
-			if connected {
-				continue
+		}
+	}
+
+	// Block until neither end of the connections lists them. The "to" node
+	// sees the connection as coming from the local address of the "from" node.
+	deadline := time.Now().Add(linkWaitTimeout)
+	for _, peerInfo := range peers {
+		for {
+			connected, err := hasPeerAddr(from, peerInfo.Addr)
+			if err != nil {
+				return err
 			}
 
-			err = ConnectNode(peer, node)
+			inbound, err := hasPeerAddr(to, peerInfo.AddrLocal)
 			if err != nil {
 				return err
 			}
+
+			if !connected && !inbound {
+				break
+			}
+
+			if time.Now().After(deadline) {
+				return fmt.Errorf("connection from %s to %s still exists after %s",
+					peerInfo.AddrLocal, peerInfo.Addr, linkWaitTimeout)
+			}
+			time.Sleep(time.Millisecond * 100)
+		}
+	}
+
+	return nil
+}
+
+// DisconnectNodes severs the peer-to-peer connections between node a and node
+// b, established from either end. It returns once getpeerinfo on both nodes no
+// longer lists the connections.
+func DisconnectNodes(a *Harness, b *Harness) error {
+	err := disconnectOutbound(a, b)
+	if err != nil {
+		return err
+	}
+
+	return disconnectOutbound(b, a)
+}
+
+// PartitionNetwork severs the connections between nodes in different groups,
+// leaving the connections between nodes in the same group intact.
+func PartitionNetwork(groups [][]*Harness) error {
+	for i, group := range groups {
+		for _, other := range groups[i+1:] {
+			for _, a := range group {
+				for _, b := range other {
+					err := DisconnectNodes(a, b)
+					if err != nil {
+						return fmt.Errorf("unable to disconnect %s from %s: %s",
+							a.P2PAddress(), b.P2PAddress(), err)
+					}
+				}
+			}
 		}
 	}
 
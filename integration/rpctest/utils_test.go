@@ -0,0 +1,354 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// This file is ignored during the regular tests due to the following build tag.
+// +build rpctest
+
+package rpctest
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+	"github.com/soteria-dag/soterd/wire"
+)
+
+// testDag returns a small dag, where each block at height > 0 has all of the
+// blocks at the previous height as parents, along with a map of which miner
+// created each block.
+func testDag(miners int) ([][]*wire.MsgBlock, map[string]int) {
+	dag := make([][]*wire.MsgBlock, 0)
+	blockCreator := make(map[string]int)
+
+	var prevHeight []*wire.MsgBlock
+	nonce := uint32(0)
+	for height := 0; height < 3; height++ {
+		blocks := make([]*wire.MsgBlock, 0)
+		for m := 0; m < miners; m++ {
+			header := wire.NewBlockHeader(1, &chainhash.Hash{},
+				&chainhash.Hash{}, 0, nonce)
+			nonce++
+			block := wire.NewMsgBlock(header)
+			for _, parent := range prevHeight {
+				block.Parents.Parents = append(block.Parents.Parents,
+					&wire.Parent{Hash: parent.BlockHash()})
+			}
+			block.Parents.Size = int32(len(block.Parents.Parents))
+
+			blockCreator[block.BlockHash().String()] = m
+			blocks = append(blocks, block)
+		}
+		dag = append(dag, blocks)
+		prevHeight = blocks
+	}
+
+	return dag, blockCreator
+}
+
+// fillColors returns the set of fillcolor attributes found in the dot output,
+// mapped to the miner node written in the tooltip of the block.
+func fillColors(t *testing.T, dot []byte) map[string]string {
+	re := regexp.MustCompile(`tooltip="node (\d+) [^"]*", fillcolor="([^"]+)"`)
+	colors := make(map[string]string)
+	for _, m := range re.FindAllSubmatch(dot, -1) {
+		miner, color := string(m[1]), string(m[2])
+		if prev, ok := colors[miner]; ok && prev != color {
+			t.Fatalf("miner %s has multiple colors %s and %s",
+				miner, prev, color)
+		}
+		colors[miner] = color
+	}
+	return colors
+}
+
+// TestDagToDotColorByMiner ensures blocks are colored by the miner that
+// created them when requested.
+func TestDagToDotColorByMiner(t *testing.T) {
+	const miners = 3
+	dag, blockCreator := testDag(miners)
+	blockColoring := make(map[string]bool)
+
+	tests := []struct {
+		name string
+		opts *RenderDagsDotOpts
+	}{
+		{"default palette", DefaultRenderDagsDotOpts()},
+		{"palette override", &RenderDagsDotOpts{
+			ColorByMiner: true,
+			Palette:      []string{"#ff0000", "#00ff00", "#0000ff"},
+		}},
+	}
+
+	for _, test := range tests {
+		dot, err := dagToDot(dag, blockCreator, blockColoring, test.opts)
+		if err != nil {
+			t.Fatalf("%s: dagToDot failed: %v", test.name, err)
+		}
+
+		colors := fillColors(t, dot)
+		if len(colors) != miners {
+			t.Fatalf("%s: wrong number of colored miners - got %d, "+
+				"want %d", test.name, len(colors), miners)
+		}
+
+		distinct := make(map[string]struct{})
+		for _, color := range colors {
+			distinct[color] = struct{}{}
+		}
+		if len(distinct) != miners {
+			t.Fatalf("%s: miners don't have distinct colors: %v",
+				test.name, colors)
+		}
+
+		for i, color := range test.opts.Palette {
+			miner := string('0' + rune(i))
+			if colors[miner] != color {
+				t.Fatalf("%s: wrong color for miner %s - got %s, "+
+					"want %s", test.name, miner, colors[miner],
+					color)
+			}
+		}
+	}
+
+	// Ensure no colors are used when coloring by miner is off.
+	opts := &RenderDagsDotOpts{ColorByMiner: false}
+	dot, err := dagToDot(dag, blockCreator, blockColoring, opts)
+	if err != nil {
+		t.Fatalf("dagToDot failed: %v", err)
+	}
+	if regexp.MustCompile(`fillcolor=`).Match(dot) {
+		t.Fatalf("dot output contains fillcolor when coloring by " +
+			"miner is off:\n%s", dot)
+	}
+}
+
+// TestDagToJSON ensures the exported dag has an edge for every parent
+// reference, and parses back to the blocks it was exported from.
+func TestDagToJSON(t *testing.T) {
+	const miners = 3
+	dag, blockCreator := testDag(miners)
+	blockColoring := make(map[string]bool)
+
+	out, err := dagToJSON(dag, blockCreator, blockColoring)
+	if err != nil {
+		t.Fatalf("dagToJSON failed: %v", err)
+	}
+
+	var export DagExport
+	if err := json.Unmarshal(out, &export); err != nil {
+		t.Fatalf("unable to parse exported dag: %v", err)
+	}
+
+	numBlocks, numParents := 0, 0
+	for _, blocks := range dag {
+		for _, block := range blocks {
+			numBlocks++
+			numParents += len(block.Parents.Parents)
+		}
+	}
+	if len(export.Blocks) != numBlocks {
+		t.Fatalf("wrong number of exported blocks - got %d, want %d",
+			len(export.Blocks), numBlocks)
+	}
+	if len(export.Edges) != numParents {
+		t.Fatalf("wrong number of exported edges - got %d, want %d",
+			len(export.Edges), numParents)
+	}
+
+	edges := make(map[DagExportEdge]struct{})
+	for _, edge := range export.Edges {
+		edges[edge] = struct{}{}
+	}
+	for _, block := range export.Blocks {
+		if block.Miner != blockCreator[block.Hash] {
+			t.Fatalf("wrong miner for block %s - got %d, want %d",
+				block.Hash, block.Miner, blockCreator[block.Hash])
+		}
+		for _, parent := range block.Parents {
+			edge := DagExportEdge{From: block.Hash, To: parent}
+			if _, ok := edges[edge]; !ok {
+				t.Fatalf("no edge for parent %s of block %s",
+					parent, block.Hash)
+			}
+		}
+	}
+}
+
+// TestRenderDagExportDot ensures rendering an exported dag produces the same
+// DOT output as rendering the dag it was exported from, and that invalid
+// exports are rejected.
+func TestRenderDagExportDot(t *testing.T) {
+	const miners = 3
+	dag, blockCreator := testDag(miners)
+	blockColoring := map[string]bool{
+		dag[0][0].BlockHash().String(): true,
+		dag[1][1].BlockHash().String(): true,
+	}
+
+	out, err := dagToJSON(dag, blockCreator, blockColoring)
+	if err != nil {
+		t.Fatalf("dagToJSON failed: %v", err)
+	}
+
+	for _, opts := range []*RenderDagsDotOpts{
+		DefaultRenderDagsDotOpts(),
+		{ColorByMiner: false},
+	} {
+		want, err := dagToDot(dag, blockCreator, blockColoring, opts)
+		if err != nil {
+			t.Fatalf("dagToDot failed: %v", err)
+		}
+
+		got, err := RenderDagExportDot(out, opts)
+		if err != nil {
+			t.Fatalf("RenderDagExportDot failed: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("wrong dot output for ColorByMiner %v - got:\n%s\nwant:\n%s",
+				opts.ColorByMiner, got, want)
+		}
+	}
+
+	// Ensure exports that reference blocks that aren't in the export are
+	// rejected.
+	var export DagExport
+	if err := json.Unmarshal(out, &export); err != nil {
+		t.Fatalf("unable to parse exported dag: %v", err)
+	}
+	missing := chainhash.Hash{0x01}
+
+	badParent := export
+	badParent.Blocks = append([]DagExportBlock{}, export.Blocks...)
+	last := &badParent.Blocks[len(badParent.Blocks)-1]
+	last.Parents = append(append([]string{}, last.Parents...), missing.String())
+	badParent.Edges = append(append([]DagExportEdge{}, export.Edges...),
+		DagExportEdge{From: last.Hash, To: missing.String()})
+
+	badEdge := export
+	badEdge.Edges = append(append([]DagExportEdge{}, export.Edges...),
+		DagExportEdge{From: missing.String(), To: export.Blocks[0].Hash})
+
+	tests := []struct {
+		name   string
+		export DagExport
+	}{
+		{"unknown parent", badParent},
+		{"edge without parent reference", badEdge},
+	}
+
+	for _, test := range tests {
+		b, err := json.Marshal(test.export)
+		if err != nil {
+			t.Fatalf("%s: unable to marshal export: %v", test.name, err)
+		}
+		if _, err := RenderDagExportDot(b, nil); err == nil {
+			t.Fatalf("%s: RenderDagExportDot didn't return an error",
+				test.name)
+		}
+	}
+
+	if _, err := RenderDagExportDot([]byte("{"), nil); err == nil {
+		t.Fatalf("RenderDagExportDot didn't return an error for invalid JSON")
+	}
+}
+
+// TestSubgraphExport ensures the subgraph around a block only contains the
+// ancestors and descendants of the block within the requested number of hops.
+func TestSubgraphExport(t *testing.T) {
+	// The dag is a chain of blocks a0 to a5, with a block b3 forking off
+	// of a2, and a block c5 merging a4 and b3.
+	hash := func(name string) string {
+		return chainhash.HashH([]byte(name)).String()
+	}
+	blocks := []struct {
+		name    string
+		height  int32
+		parents []string
+	}{
+		{"a0", 0, nil},
+		{"a1", 1, []string{"a0"}},
+		{"a2", 2, []string{"a1"}},
+		{"a3", 3, []string{"a2"}},
+		{"b3", 3, []string{"a2"}},
+		{"a4", 4, []string{"a3"}},
+		{"a5", 5, []string{"a4"}},
+		{"c5", 5, []string{"a4", "b3"}},
+	}
+	var export DagExport
+	for _, block := range blocks {
+		parents := make([]string, 0, len(block.parents))
+		for _, parent := range block.parents {
+			parents = append(parents, hash(parent))
+			export.Edges = append(export.Edges, DagExportEdge{
+				From: hash(block.name),
+				To:   hash(parent),
+			})
+		}
+		export.Blocks = append(export.Blocks, DagExportBlock{
+			Hash:    hash(block.name),
+			Height:  block.height,
+			Parents: parents,
+			Miner:   -1,
+		})
+	}
+
+	tests := []struct {
+		name  string
+		root  string
+		depth int
+		want  []string
+	}{
+		{"root only", "a3", 0, []string{"a3"}},
+		{"negative depth", "a3", -1, []string{"a3"}},
+		{"one hop", "a3", 1, []string{"a2", "a3", "a4"}},
+		{"two hops", "a3", 2,
+			[]string{"a1", "a2", "a3", "a4", "a5", "c5"}},
+		{"fork", "b3", 1, []string{"a2", "b3", "c5"}},
+		{"depth past bounds", "a3", 100,
+			[]string{"a0", "a1", "a2", "a3", "a4", "a5", "c5"}},
+		{"genesis", "a0", 100,
+			[]string{"a0", "a1", "a2", "a3", "b3", "a4", "a5", "c5"}},
+	}
+
+	for _, test := range tests {
+		sub, err := subgraphExport(&export, hash(test.root), test.depth)
+		if err != nil {
+			t.Fatalf("%s: subgraphExport failed: %v", test.name, err)
+		}
+
+		want := make(map[string]struct{})
+		for _, name := range test.want {
+			want[hash(name)] = struct{}{}
+		}
+		got := make(map[string]struct{})
+		for _, block := range sub.Blocks {
+			if _, ok := want[block.Hash]; !ok {
+				t.Fatalf("%s: block %s isn't within %d hops of %s",
+					test.name, block.Hash, test.depth, test.root)
+			}
+			got[block.Hash] = struct{}{}
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s: wrong number of blocks - got %d, want %d",
+				test.name, len(got), len(want))
+		}
+
+		// The subgraph has to be renderable on its own.
+		b, err := json.Marshal(sub)
+		if err != nil {
+			t.Fatalf("%s: unable to marshal subgraph: %v", test.name, err)
+		}
+		if _, err := RenderDagExportDot(b, nil); err != nil {
+			t.Fatalf("%s: unable to render subgraph: %v", test.name, err)
+		}
+	}
+
+	if _, err := subgraphExport(&export, hash("x"), 1); err == nil {
+		t.Fatalf("subgraphExport didn't return an error for a block " +
+			"that isn't in the dag")
+	}
+}
@@ -53,6 +53,10 @@ var (
 	// EstimateFeeDatabaseKey is the key that we use to
 	// store the fee estimator in the database.
 	EstimateFeeDatabaseKey = []byte("estimatefee")
+
+	// ErrInsufficientFeeData is returned by EstimateFee when fewer blocks
+	// than the minimum have been registered with the fee estimator.
+	ErrInsufficientFeeData = errors.New("not enough blocks have been observed")
 )
 
 // nanoSoterPerByte is number with units of nanoSoters per byte.
@@ -98,11 +102,12 @@ type observedTransaction struct {
 	// The fee per byte of the transaction in nanoSoters.
 	feeRate nanoSoterPerByte
 
-	// The block height when it was observed.
+	// The number of blocks registered when it was observed.
 	observed int32
 
-	// The height of the block in which it was mined.
-	// If the transaction has not yet been mined, it is zero.
+	// The number of blocks registered when the block in which it was mined
+	// was registered.  If the transaction has not yet been mined, it is
+	// miningdag.UnminedHeight.
 	mined int32
 }
 
@@ -149,6 +154,12 @@ func (rb *registeredBlock) serialize(w io.Writer, txs map[*observedTransaction]u
 
 // FeeEstimator manages the data necessary to create
 // fee estimations. It is safe for concurrent access.
+//
+// A dag can have several blocks at the same height, so the number of blocks
+// it took to confirm a transaction isn't measured by block height.  Instead it
+// is the depth in the dag ordering, which is the number of blocks registered
+// with the fee estimator from when the transaction was observed until the
+// block that mined it.
 type FeeEstimator struct {
 	maxRollback uint32
 	binSize     int32
@@ -161,10 +172,11 @@ type FeeEstimator struct {
 	// estimator before it will provide answers.
 	minRegisteredBlocks uint32
 
-	// The last known height.
+	// The highest height of the blocks that have been registered.
 	lastKnownHeight int32
 
-	// The number of blocks that have been registered.
+	// The number of blocks that have been registered.  It is the position
+	// in the dag ordering that depths are measured from.
 	numBlocksRegistered uint32
 
 	mtx      sync.RWMutex
@@ -212,7 +224,7 @@ func (ef *FeeEstimator) ObserveTransaction(t *TxDesc) {
 		ef.observed[hash] = &observedTransaction{
 			hash:     hash,
 			feeRate:  NewNanoSoterPerByte(soterutil.Amount(t.Fee), size),
-			observed: t.Height,
+			observed: int32(ef.numBlocksRegistered),
 			mined:    miningdag.UnminedHeight,
 		}
 	}
@@ -226,15 +238,17 @@ func (ef *FeeEstimator) RegisterBlock(block *soterutil.Block) error {
 	// The previous sorted list is invalid, so delete it.
 	ef.cached = nil
 
-	height := block.Height()
-	if height != ef.lastKnownHeight+1 && ef.lastKnownHeight != miningdag.UnminedHeight {
-		return fmt.Errorf("intermediate block not recorded; current height is %d; new height is %d",
-			ef.lastKnownHeight, height)
+	// Update the last known height.  Blocks mined in parallel can have
+	// the same height, or be registered after higher blocks, so the height
+	// isn't required to increase.
+	if ef.lastKnownHeight == miningdag.UnminedHeight ||
+		block.Height() > ef.lastKnownHeight {
+		ef.lastKnownHeight = block.Height()
 	}
 
-	// Update the last known height.
-	ef.lastKnownHeight = height
+	// The block's position in the dag ordering.
 	ef.numBlocksRegistered++
+	order := int32(ef.numBlocksRegistered)
 
 	// Randomly order txs in block.
 	transactions := make(map[*soterutil.Tx]struct{})
@@ -263,7 +277,7 @@ func (ef *FeeEstimator) RegisterBlock(block *soterutil.Block) error {
 		}
 
 		// Put the observed tx in the oppropriate bin.
-		blocksToConfirm := height - o.observed - 1
+		blocksToConfirm := order - o.observed - 1
 
 		// This shouldn't happen if the fee estimator works correctly,
 		// but return an error if it does.
@@ -283,7 +297,7 @@ func (ef *FeeEstimator) RegisterBlock(block *soterutil.Block) error {
 			continue
 		}
 
-		o.mined = height
+		o.mined = order
 
 		replacementCounts[blocksToConfirm]++
 
@@ -306,7 +320,7 @@ func (ef *FeeEstimator) RegisterBlock(block *soterutil.Block) error {
 
 	// Go through the mempool for txs that have been in too long.
 	for hash, o := range ef.observed {
-		if o.mined == miningdag.UnminedHeight && height-o.observed >= estimateFeeDepth {
+		if o.mined == miningdag.UnminedHeight && order-o.observed >= estimateFeeDepth {
 			delete(ef.observed, hash)
 		}
 	}
@@ -325,7 +339,8 @@ func (ef *FeeEstimator) RegisterBlock(block *soterutil.Block) error {
 	return nil
 }
 
-// LastKnownHeight returns the height of the last block which was registered.
+// LastKnownHeight returns the highest height of the blocks which were
+// registered.
 func (ef *FeeEstimator) LastKnownHeight() int32 {
 	ef.mtx.Lock()
 	defer ef.mtx.Unlock()
@@ -403,7 +418,7 @@ func (ef *FeeEstimator) rollback() {
 
 			prev := bin[counter]
 
-			if prev.mined == ef.lastKnownHeight {
+			if prev.mined == int32(ef.numBlocksRegistered) {
 				prev.mined = miningdag.UnminedHeight
 
 				bin[counter] = o
@@ -429,7 +444,7 @@ func (ef *FeeEstimator) rollback() {
 
 			prev := ef.bin[i][j]
 
-			if prev.mined == ef.lastKnownHeight {
+			if prev.mined == int32(ef.numBlocksRegistered) {
 				prev.mined = miningdag.UnminedHeight
 
 				newBin := append(ef.bin[i][0:j], ef.bin[i][j+1:l]...)
@@ -447,9 +462,10 @@ func (ef *FeeEstimator) rollback() {
 
 	ef.dropped = ef.dropped[0:last]
 
-	// The number of blocks the fee estimator has seen is decrimented.
+	// The number of blocks the fee estimator has seen is decrimented.  The
+	// last known height is left as is, since the other registered blocks
+	// can be at the same height.
 	ef.numBlocksRegistered--
-	ef.lastKnownHeight--
 }
 
 // estimateFeeSet is a set of txs that can that is sorted
@@ -544,7 +560,8 @@ func (ef *FeeEstimator) estimates() []nanoSoterPerByte {
 }
 
 // EstimateFee estimates the fee per byte to have a tx confirmed a given
-// number of blocks from now.
+// number of blocks from now, measured by depth in the dag ordering.  A zero
+// estimate means no transactions have been confirmed within that depth.
 func (ef *FeeEstimator) EstimateFee(numBlocks uint32) (SotoPerKilobyte, error) {
 	ef.mtx.Lock()
 	defer ef.mtx.Unlock()
@@ -552,7 +569,7 @@ func (ef *FeeEstimator) EstimateFee(numBlocks uint32) (SotoPerKilobyte, error) {
 	// If the number of registered blocks is below the minimum, return
 	// an error.
 	if ef.numBlocksRegistered < ef.minRegisteredBlocks {
-		return -1, errors.New("not enough blocks have been observed")
+		return -1, ErrInsufficientFeeData
 	}
 
 	if numBlocks == 0 {
@@ -577,7 +594,10 @@ func (ef *FeeEstimator) EstimateFee(numBlocks uint32) (SotoPerKilobyte, error) {
 // we use a version number. If the version number changes, it does not make
 // sense to try to upgrade a previous version to a new version. Instead, just
 // start fee estimation over.
-const estimateFeeSaveVersion = 1
+//
+// Version 2 measures the observed and mined fields of transactions by position
+// in the dag ordering instead of block height.
+const estimateFeeSaveVersion = 2
 
 func deserializeRegisteredBlock(r io.Reader, txs map[uint32]*observedTransaction) (*registeredBlock, error) {
 	var lenTransactions uint32
@@ -322,6 +322,47 @@ func (eft *estimateFeeTester) round(txHistory [][]*TxDesc,
 }
 
 // TestEstimateFeeRollback tests the rollback function, which undoes the
+// TestEstimateFeeDagOrdering tests that the fee estimator measures the blocks
+// to confirm a transaction by depth in the dag ordering, so blocks at the same
+// height are counted.
+func TestEstimateFeeDagOrdering(t *testing.T) {
+	ef := newTestFeeEstimator(5, 3, 1)
+	eft := estimateFeeTester{ef: ef, t: t}
+
+	tx := eft.testTx(1000000)
+	ef.ObserveTransaction(tx)
+
+	// Two blocks which were mined in parallel, at the same height.
+	for i, txs := range [][]*wire.MsgTx{{}, {tx.Tx.MsgTx()}} {
+		block := soterutil.NewBlock(&wire.MsgBlock{Transactions: txs})
+		block.SetHeight(1)
+		if err := ef.RegisterBlock(block); err != nil {
+			t.Fatalf("RegisterBlock #%d: %v", i, err)
+		}
+	}
+
+	if height := ef.LastKnownHeight(); height != 1 {
+		t.Errorf("LastKnownHeight: got %d, want 1", height)
+	}
+
+	// The transaction was confirmed by the second block after it was
+	// observed, so it's in the bin for 2 blocks.
+	o := ef.observed[*tx.Tx.Hash()]
+	if len(ef.bin[1]) != 1 || ef.bin[1][0] != o {
+		t.Fatalf("transaction not binned at depth 2, bins: %v", ef.bin)
+	}
+
+	expected := expectedFeePerKilobyte(tx)
+	estimated, err := ef.EstimateFee(2)
+	if err != nil {
+		t.Fatalf("EstimateFee: %v", err)
+	}
+	if estimated != expected {
+		t.Errorf("Estimate fee error: expected %f; got %f", expected,
+			estimated)
+	}
+}
+
 // effect of a adding a new block.
 func TestEstimateFeeRollback(t *testing.T) {
 	txPerRound := uint32(7)
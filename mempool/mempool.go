@@ -112,6 +112,11 @@ type Policy struct {
 	// Otherwise, all non-standard transactions will be rejected.
 	AcceptNonStd bool
 
+	// MaxOpReturnSize is the maximum number of bytes of data a null data
+	// (OP_RETURN) output may carry for the transaction to be standard.
+	// It has no effect when AcceptNonStd is set.
+	MaxOpReturnSize int
+
 	// FreeTxRelayLimit defines the given amount in thousands of bytes
 	// per minute that transactions with no fee are rate limited to.
 	FreeTxRelayLimit float64
@@ -631,6 +636,24 @@ func (mp *TxPool) FetchTransaction(txHash *chainhash.Hash) (*soterutil.Tx, error
 	return nil, fmt.Errorf("transaction is not in the pool")
 }
 
+// FetchTxDesc returns the descriptor of the requested transaction from the
+// transaction pool.  This only fetches from the main transaction pool and does
+// not include orphans.  The descriptor is to be treated as read only.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) FetchTxDesc(txHash *chainhash.Hash) (*TxDesc, error) {
+	// Protect concurrent access.
+	mp.mtx.RLock()
+	txDesc, exists := mp.pool[*txHash]
+	mp.mtx.RUnlock()
+
+	if exists {
+		return txDesc, nil
+	}
+
+	return nil, fmt.Errorf("transaction is not in the pool")
+}
+
 // maybeAcceptTransaction is the internal function which implements the public
 // MaybeAcceptTransaction.  See the comment for MaybeAcceptTransaction for
 // more details.
@@ -697,7 +720,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *soterutil.Tx, isNew, rateLimit, rej
 	if !mp.cfg.Policy.AcceptNonStd {
 		err = checkTransactionStandard(tx, nextBlockHeight,
 			medianTimePast, mp.cfg.Policy.MinRelayTxFee,
-			mp.cfg.Policy.MaxTxVersion)
+			mp.cfg.Policy.MaxTxVersion, mp.cfg.Policy.MaxOpReturnSize)
 		if err != nil {
 			// Attempt to extract a reject code from the error so
 			// it can be retained.  When not possible, fall back to
@@ -1217,6 +1240,13 @@ func (mp *TxPool) RawMempoolVerbose() map[string]*soterjson.GetRawMempoolVerbose
 	return result
 }
 
+// Policy returns the policy the mempool accepts transactions with.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) Policy() Policy {
+	return mp.cfg.Policy
+}
+
 // LastUpdated returns the last time a transaction was added to or removed from
 // the main pool.  It does not include the orphan pool.
 //
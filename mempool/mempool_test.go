@@ -6,6 +6,7 @@
 package mempool
 
 import (
+	"bytes"
 	"encoding/hex"
 	"reflect"
 	"runtime"
@@ -317,6 +318,7 @@ func newPoolHarness(chainParams *chaincfg.Params) (*poolHarness, []spendableOutp
 				MaxSigOpCostPerTx:    blockdag.MaxBlockSigOpsCost / 4,
 				MinRelayTxFee:        1000, // 1 nanoSoter per byte
 				MaxTxVersion:         1,
+				MaxOpReturnSize:      DefaultMaxOpReturnSize,
 			},
 			ChainParams:      chainParams,
 			FetchUtxoView:    chain.FetchUtxoView,
@@ -867,3 +869,110 @@ func TestCheckSpend(t *testing.T) {
 		t.Fatalf("Unexpeced spend found in pool: %v", spend)
 	}
 }
+
+// TestRelayPolicy ensures that non-standard transactions are only accepted
+// when the policy accepts them, and that null data outputs may carry as much
+// data as the policy allows.
+func TestRelayPolicy(t *testing.T) {
+	t.Parallel()
+
+	largeData := bytes.Repeat([]byte{0x01}, DefaultMaxOpReturnSize+1)
+	largeNullData, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_RETURN).AddData(largeData).Script()
+	if err != nil {
+		t.Fatalf("unable to create large null data script: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		pkScript        []byte
+		acceptNonStd    bool
+		maxOpReturnSize int
+		accepted        bool
+	}{
+		{
+			name:            "large null data with default policy",
+			pkScript:        largeNullData,
+			maxOpReturnSize: DefaultMaxOpReturnSize,
+			accepted:        false,
+		},
+		{
+			name:            "large null data with raised limit",
+			pkScript:        largeNullData,
+			maxOpReturnSize: len(largeData),
+			accepted:        true,
+		},
+		{
+			name:            "large null data accepting non-standard",
+			pkScript:        largeNullData,
+			acceptNonStd:    true,
+			maxOpReturnSize: DefaultMaxOpReturnSize,
+			accepted:        true,
+		},
+		{
+			name:            "non-standard script with default policy",
+			pkScript:        []byte{txscript.OP_TRUE},
+			maxOpReturnSize: DefaultMaxOpReturnSize,
+			accepted:        false,
+		},
+		{
+			name:            "non-standard script accepting non-standard",
+			pkScript:        []byte{txscript.OP_TRUE},
+			acceptNonStd:    true,
+			maxOpReturnSize: DefaultMaxOpReturnSize,
+			accepted:        true,
+		},
+	}
+
+	for _, test := range tests {
+		harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatalf("unable to create test pool: %v", err)
+		}
+		harness.txPool.cfg.Policy.AcceptNonStd = test.acceptNonStd
+		harness.txPool.cfg.Policy.MaxOpReturnSize = test.maxOpReturnSize
+
+		// Spend the output of the harness to its payment script, along
+		// with an output with the script under test.
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: outputs[0].outPoint,
+			Sequence:         wire.MaxTxInSequenceNum,
+		})
+		tx.AddTxOut(&wire.TxOut{
+			PkScript: harness.payScript,
+			Value:    int64(outputs[0].amount) - 1000,
+		})
+		tx.AddTxOut(&wire.TxOut{
+			PkScript: test.pkScript,
+			Value:    1000,
+		})
+		sigScript, err := txscript.SignatureScript(tx, 0,
+			harness.payScript, txscript.SigHashAll, harness.signKey,
+			true)
+		if err != nil {
+			t.Fatalf("unable to sign transaction: %v", err)
+		}
+		tx.TxIn[0].SignatureScript = sigScript
+
+		_, err = harness.txPool.ProcessTransaction(soterutil.NewTx(tx),
+			false, false, 0)
+		if test.accepted && err != nil {
+			t.Errorf("%s: transaction was rejected: %v", test.name,
+				err)
+			continue
+		}
+		if !test.accepted {
+			if err == nil {
+				t.Errorf("%s: transaction was accepted", test.name)
+				continue
+			}
+			code, _ := extractRejectCode(err)
+			if code != wire.RejectNonstandard {
+				t.Errorf("%s: transaction was rejected with %v, "+
+					"want %v: %v", test.name, code,
+					wire.RejectNonstandard, err)
+			}
+		}
+	}
+}
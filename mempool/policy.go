@@ -54,6 +54,11 @@ const (
 	// in a multi-signature transaction output script for it to be
 	// considered standard.
 	maxStandardMultiSigKeys = 3
+
+	// DefaultMaxOpReturnSize is the default maximum number of bytes of
+	// data a null data (OP_RETURN) output script may carry for it to be
+	// considered standard.
+	DefaultMaxOpReturnSize = txscript.MaxDataCarrierSize
 )
 
 // calcMinRequiredTxRelayFee returns the minimum transaction fee required for a
@@ -275,10 +280,11 @@ func isDust(txOut *wire.TxOut, minRelayTxFee soterutil.Amount) bool {
 // "sane" transaction such as having a version in the supported range, being
 // finalized, conforming to more stringent size constraints, having scripts
 // of recognized forms, and not containing "dust" outputs (those that are
-// so small it costs more to process them than they are worth).
+// so small it costs more to process them than they are worth).  Null data
+// outputs may carry at most maxOpReturnSize bytes of data.
 func checkTransactionStandard(tx *soterutil.Tx, height int32,
 	medianTimePast time.Time, minRelayTxFee soterutil.Amount,
-	maxTxVersion int32) error {
+	maxTxVersion int32, maxOpReturnSize int) error {
 
 	// The transaction must be a currently supported version.
 	msgTx := tx.MsgTx()
@@ -333,7 +339,21 @@ func checkTransactionStandard(tx *soterutil.Tx, height int32,
 	// be "dust" (except when the script is a null data script).
 	numNullDataOutputs := 0
 	for i, txOut := range msgTx.TxOut {
+		// The amount of data null data scripts may carry is set by the
+		// policy rather than by the script classification, so that it
+		// can be raised above the default.
 		scriptClass := txscript.GetScriptClass(txOut.PkScript)
+		if size, ok := txscript.NullDataSize(txOut.PkScript); ok {
+			if size > maxOpReturnSize {
+				str := fmt.Sprintf("transaction output %d: null "+
+					"data script carries %d bytes, which is "+
+					"more than the max allowed %d bytes", i,
+					size, maxOpReturnSize)
+				return txRuleError(wire.RejectNonstandard, str)
+			}
+			scriptClass = txscript.NullDataTy
+		}
+
 		err := checkPkScriptStandard(txOut.PkScript, scriptClass)
 		if err != nil {
 			// Attempt to extract a reject code from the error so
@@ -306,6 +306,20 @@ func TestCheckTransactionStandard(t *testing.T) {
 		Value:    100000000, // 1 SOTO
 		PkScript: dummyPkScript,
 	}
+	maxNullData, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_RETURN).
+		AddData(bytes.Repeat([]byte{0x01}, DefaultMaxOpReturnSize)).
+		Script()
+	if err != nil {
+		t.Fatalf("NewScriptBuilder: unexpected error: %v", err)
+	}
+	largeNullData, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_RETURN).
+		AddData(bytes.Repeat([]byte{0x01}, DefaultMaxOpReturnSize+1)).
+		Script()
+	if err != nil {
+		t.Fatalf("NewScriptBuilder: unexpected error: %v", err)
+	}
 
 	tests := []struct {
 		name       string
@@ -465,13 +479,43 @@ func TestCheckTransactionStandard(t *testing.T) {
 			height:     300000,
 			isStandard: true,
 		},
+		{
+			name: "Nulldata output with the max allowed data",
+			tx: wire.MsgTx{
+				Version: 1,
+				TxIn:    []*wire.TxIn{&dummyTxIn},
+				TxOut: []*wire.TxOut{{
+					Value:    0,
+					PkScript: maxNullData,
+				}},
+				LockTime: 0,
+			},
+			height:     300000,
+			isStandard: true,
+		},
+		{
+			name: "Nulldata output with more than the max allowed data",
+			tx: wire.MsgTx{
+				Version: 1,
+				TxIn:    []*wire.TxIn{&dummyTxIn},
+				TxOut: []*wire.TxOut{{
+					Value:    0,
+					PkScript: largeNullData,
+				}},
+				LockTime: 0,
+			},
+			height:     300000,
+			isStandard: false,
+			code:       wire.RejectNonstandard,
+		},
 	}
 
 	pastMedianTime := time.Now()
 	for _, test := range tests {
 		// Ensure standardness is as expected.
 		err := checkTransactionStandard(soterutil.NewTx(&test.tx),
-			test.height, pastMedianTime, DefaultMinRelayTxFee, 1)
+			test.height, pastMedianTime, DefaultMinRelayTxFee, 1,
+			DefaultMaxOpReturnSize)
 		if err == nil && test.isStandard {
 			// Test passes since function returned standard for a
 			// transaction which is intended to be standard.
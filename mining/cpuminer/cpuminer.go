@@ -82,6 +82,12 @@ type Config struct {
 	// not current since any solved blocks would be on a side chain and and
 	// up orphaned anyways.
 	IsCurrent func() bool
+
+	// Seed seeds the randomness the miner uses to choose the payment
+	// address and extra nonce of blocks, so that mining the same block
+	// templates again gives identical blocks.  When it is zero, the miner
+	// isn't seeded.  This is only intended for testing.
+	Seed int64
 }
 
 // CPUMiner provides facilities for solving blocks (mining) using the CPU in
@@ -109,6 +115,12 @@ type CPUMiner struct {
 
 	speedMonitorQuit  chan struct{}
 	quit              chan struct{}
+
+	// rand is the source of randomness of a miner with a seed, or nil
+	// when the miner isn't seeded.  randMtx protects it, since workers
+	// use it concurrently.
+	rand    *rand.Rand
+	randMtx sync.Mutex
 }
 
 // speedMonitor handles tracking the number of hashes per second the mining
@@ -155,9 +167,32 @@ out:
 	log.Tracef("CPU miner speed monitor done")
 }
 
+// isStale returns whether work on the passed block is stale.  A block that
+// references the tips chosen by the dag is stale once that choice changes, while a
+// block that was forced to reference a set of parents is only stale once one of
+// those parents stops being a tip.
+func (m *CPUMiner) isStale(msgBlock *wire.MsgBlock, forcedParents bool) bool {
+	snapshot := m.g.DAGSnapshot()
+	if !forcedParents {
+		return !msgBlock.Header.PrevBlock.IsEqual(&snapshot.ParentsHash)
+	}
+
+	tips := make(map[chainhash.Hash]struct{}, len(snapshot.Tips))
+	for _, tip := range snapshot.Tips {
+		tips[tip] = struct{}{}
+	}
+	for _, parent := range msgBlock.Parents.ParentHashes() {
+		if _, exists := tips[parent]; !exists {
+			return true
+		}
+	}
+
+	return false
+}
+
 // submitBlock submits the passed block to network after ensuring it passes all
 // of the consensus validation rules.
-func (m *CPUMiner) submitBlock(block *soterutil.Block) bool {
+func (m *CPUMiner) submitBlock(block *soterutil.Block, forcedParents bool) bool {
 	m.submitBlockLock.Lock()
 	defer m.submitBlockLock.Unlock()
 
@@ -167,7 +202,7 @@ func (m *CPUMiner) submitBlock(block *soterutil.Block) bool {
 	// a new block, but the check only happens periodically, so it is
 	// possible a block was found and submitted in between.
 	msgBlock := block.MsgBlock()
-	if !msgBlock.Header.PrevBlock.IsEqual(&m.g.DAGSnapshot().Hash) {
+	if m.isStale(msgBlock, forcedParents) {
 		log.Debugf("Block submitted via CPU miner with previous "+
 			"block %s is stale", msgBlock.Header.PrevBlock)
 		return false
@@ -200,6 +235,33 @@ func (m *CPUMiner) submitBlock(block *soterutil.Block) bool {
 	return true
 }
 
+// extraNonceOffset returns a random extra nonce offset for a worker to start
+// solving a block template from.  It comes from the seeded randomness of the
+// miner when it has a seed.
+func (m *CPUMiner) extraNonceOffset() (uint64, error) {
+	if m.rand == nil {
+		return wire.RandomUint64()
+	}
+
+	m.randMtx.Lock()
+	defer m.randMtx.Unlock()
+	return m.rand.Uint64(), nil
+}
+
+// miningAddr returns one of the payment addresses of the miner at random, to
+// use for a generated block.  It comes from the seeded randomness of the miner
+// when it has a seed.
+func (m *CPUMiner) miningAddr() soterutil.Address {
+	if m.rand == nil {
+		rand.Seed(time.Now().UnixNano())
+		return m.cfg.MiningAddrs[rand.Intn(len(m.cfg.MiningAddrs))]
+	}
+
+	m.randMtx.Lock()
+	defer m.randMtx.Unlock()
+	return m.cfg.MiningAddrs[m.rand.Intn(len(m.cfg.MiningAddrs))]
+}
+
 // solveBlock attempts to find some combination of a nonce, extra nonce, and
 // current timestamp which makes the passed block hash to a value less than the
 // target difficulty.  The timestamp is updated periodically and the passed
@@ -209,12 +271,14 @@ func (m *CPUMiner) submitBlock(block *soterutil.Block) bool {
 // This function will return early with false when conditions that trigger a
 // stale block such as a new block showing up or periodically when there are
 // new transactions and enough time has elapsed without finding a solution.
+// When forcedParents is true, only the parents referenced by the block becoming
+// stale triggers an early return; see isStale.
 func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
-	ticker *time.Ticker, quit chan struct{}) bool {
+	ticker *time.Ticker, quit chan struct{}, forcedParents bool) bool {
 
 	// Choose a random extra nonce offset for this block template and
 	// worker.
-	enOffset, err := wire.RandomUint64()
+	enOffset, err := m.extraNonceOffset()
 	if err != nil {
 		log.Errorf("Unexpected error while generating random "+
 			"extra nonce offset: %v", err)
@@ -252,7 +316,7 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
 				hashesCompleted = 0
 
 				// The current block is stale if tips have changed.
-				if !header.PrevBlock.IsEqual(&m.g.DAGSnapshot().Hash) {
+				if m.isStale(msgBlock, forcedParents) {
 					return false
 				}
 
@@ -342,8 +406,7 @@ out:
 		}
 
 		// Choose a payment address at random.
-		rand.Seed(time.Now().UnixNano())
-		payToAddr := m.cfg.MiningAddrs[rand.Intn(len(m.cfg.MiningAddrs))]
+		payToAddr := m.miningAddr()
 
 		// Create a new block template using the available transactions
 		// in the memory pool as a source of transactions to potentially
@@ -361,9 +424,9 @@ out:
 		// with false when conditions that trigger a stale block, so
 		// a new block template can be generated.  When the return is
 		// true a solution was found, so submit the solved block.
-		if m.solveBlock(template.Block, curHeight+1, ticker, quit) {
+		if m.solveBlock(template.Block, template.Height, ticker, quit, false) {
 			block := soterutil.NewBlock(template.Block)
-			accepted := m.submitBlock(block)
+			accepted := m.submitBlock(block, false)
 			if accepted {
 				m.SolveTimes <- time.Since(startMine)
 				m.SolveCount <- struct{}{}
@@ -563,6 +626,31 @@ func (m *CPUMiner) NumWorkers() int32 {
 // generating a new block template.  When a block is solved, it is submitted.
 // The function returns a list of the hashes of generated blocks.
 func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
+	return m.generateNBlocks(n, nil)
+}
+
+// GenerateNBlocksWithParents generates the requested number of blocks like
+// GenerateNBlocks, except that the first block references the passed parents
+// instead of all of the current tips of the dag.  Each following block
+// references only the block generated before it, so that the shape of the dag
+// is fully determined by the caller.
+//
+// Each of the parents must be one of the current tips of the dag.  An error is
+// returned if they aren't, or if one of them stops being a tip before the first
+// block is generated.
+func (m *CPUMiner) GenerateNBlocksWithParents(n uint32, parents []*chainhash.Hash) ([]*chainhash.Hash, error) {
+	if len(parents) == 0 {
+		return nil, errors.New("at least one parent must be given")
+	}
+
+	return m.generateNBlocks(n, parents)
+}
+
+// generateNBlocks generates the requested number of blocks.  When parents is
+// nil the blocks reference all of the current tips of the dag, otherwise the
+// first block references the passed parents and each following block
+// references the block generated before it.
+func (m *CPUMiner) generateNBlocks(n uint32, parents []*chainhash.Hash) ([]*chainhash.Hash, error) {
 	m.Lock()
 
 	// Respond with an error if server is already mining.
@@ -581,8 +669,20 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
 
 	m.Unlock()
 
+	// stop shuts down the speed monitor and marks the miner as idle once
+	// generation is over.
+	stop := func() {
+		m.Lock()
+		close(m.speedMonitorQuit)
+		m.wg.Wait()
+		m.started = false
+		m.discreteMining = false
+		m.Unlock()
+	}
+
 	log.Tracef("Generating %d blocks", n)
 
+	forcedParents := parents != nil
 	i := uint32(0)
 	blockHashes := make([]*chainhash.Hash, n)
 
@@ -607,31 +707,45 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
 		// be changing and this would otherwise end up building a new block
 		// template on a block that is in the process of becoming stale.
 		m.submitBlockLock.Lock()
-		curHeight := m.g.DAGSnapshot().MaxHeight //m.g.BestSnapshot().Height
 
 		// Choose a payment address at random.
-		rand.Seed(time.Now().UnixNano())
-		payToAddr := m.cfg.MiningAddrs[rand.Intn(len(m.cfg.MiningAddrs))]
+		payToAddr := m.miningAddr()
 
 		// Create a new block template using the available transactions
 		// in the memory pool as a source of transactions to potentially
 		// include in the block.
-		template, err := m.g.NewBlockTemplate(payToAddr)
+		var template *miningdag.BlockTemplate
+		var err error
+		if forcedParents {
+			template, err = m.g.NewBlockTemplateWithParents(payToAddr,
+				parents)
+		} else {
+			template, err = m.g.NewBlockTemplate(payToAddr)
+		}
 		m.submitBlockLock.Unlock()
 		if err != nil {
 			errStr := fmt.Sprintf("Failed to create new block "+
 				"template: %v", err)
 			log.Errorf(errStr)
+
+			// There's no point in retrying when the forced parents
+			// can't be used, because they won't become tips again.
+			if forcedParents {
+				stop()
+				return nil, errors.New(errStr)
+			}
 			continue
 		}
 
 		// Attempt to solve the block.  The function will exit early
 		// with false when conditions that trigger a stale block, so
 		// a new block template can be generated.  When the return is
-		// true a solution was found, so submit the solved block.
-		if m.solveBlock(template.Block, curHeight+1, ticker, nil) {
+		// true a solution was found, so submit the solved block.  The
+		// block is one higher than the highest of its parents, which
+		// isn't necessarily the highest block of the dag.
+		if m.solveBlock(template.Block, template.Height, ticker, nil, forcedParents) {
 			block := soterutil.NewBlock(template.Block)
-			accepted := m.submitBlock(block)
+			accepted := m.submitBlock(block, forcedParents)
 			if accepted {
 				m.SolveTimes <- time.Since(startMine)
 				m.SolveCount <- struct{}{}
@@ -644,16 +758,20 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
 				startMine = time.Now()
 			}
 
+			// The next block builds on this one when the parents
+			// are forced.
+			if forcedParents {
+				if !accepted {
+					continue
+				}
+				parents = []*chainhash.Hash{block.Hash()}
+			}
+
 			blockHashes[i] = block.Hash()
 			i++
 			if i == n {
 				log.Tracef("Generated %d blocks", i)
-				m.Lock()
-				close(m.speedMonitorQuit)
-				m.wg.Wait()
-				m.started = false
-				m.discreteMining = false
-				m.Unlock()
+				stop()
 				return blockHashes, nil
 			}
 		}
@@ -664,6 +782,11 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
 // Use Start to begin the mining process.  See the documentation for CPUMiner
 // type for more details.
 func New(cfg *Config) *CPUMiner {
+	var seeded *rand.Rand
+	if cfg.Seed != 0 {
+		seeded = rand.New(rand.NewSource(cfg.Seed))
+	}
+
 	return &CPUMiner{
 		g:                 cfg.BlockTemplateGenerator,
 		cfg:               *cfg,
@@ -674,5 +797,6 @@ func New(cfg *Config) *CPUMiner {
 		SolveCount:        make(chan struct{}),
 		SolveTimes:        make(chan time.Duration),
 		SolveHashes:       make(chan string),
+		rand:              seeded,
 	}
 }
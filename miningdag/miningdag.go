@@ -8,6 +8,7 @@ package miningdag
 import (
 	"bytes"
 	"container/heap"
+	"errors"
 	"fmt"
 	"time"
 
@@ -342,6 +343,32 @@ func medianAdjustedTime(chainState *blockdag.BestState, timeSource blockdag.Medi
 	return newTimestamp
 }
 
+// earliestTimeSource is a blockdag.MedianTimeSource whose adjusted time is
+// always before the median time of the chain, so that block templates use the
+// earliest timestamp allowed by the consensus rules.
+type earliestTimeSource struct {
+	blockdag.MedianTimeSource
+}
+
+// AdjustedTime returns the zero time.
+//
+// This is part of the blockdag.MedianTimeSource interface implementation.
+func (s earliestTimeSource) AdjustedTime() time.Time {
+	return time.Time{}
+}
+
+// NewEarliestTimeSource returns a time source for NewBlkTmplGenerator that
+// makes the timestamps of block templates independent of the current time.
+// Each template is given the earliest timestamp allowed by the consensus
+// rules, so templates built on the same blocks are identical.  Time samples
+// are passed on to the given time source.
+//
+// This is only intended for testing, since the blocks won't follow the current
+// time, and transactions locked until a time are never included in templates.
+func NewEarliestTimeSource(timeSource blockdag.MedianTimeSource) blockdag.MedianTimeSource {
+	return earliestTimeSource{timeSource}
+}
+
 // BlkTmplGenerator provides a type that can be used to generate block templates
 // based on a given mining policy and source of transactions to choose from.
 // It also houses additional state required in order to ensure the templates
@@ -442,11 +469,68 @@ func NewBlkTmplGenerator(policy *Policy, params *chaincfg.Params,
 //  |  <= policy.BlockMinSize)          |   |
 //   -----------------------------------  --
 func (g *BlkTmplGenerator) NewBlockTemplate(payToAddress soterutil.Address) (*BlockTemplate, error) {
+	return g.newBlockTemplate(payToAddress, nil)
+}
+
+// NewBlockTemplateWithParents returns a new block template like
+// NewBlockTemplate, except that the block references the passed parents
+// instead of the tips chosen by the dag.  An error is returned if no parents
+// are passed, if there are more than the network allows, or if any of them
+// isn't one of the current tips.
+func (g *BlkTmplGenerator) NewBlockTemplateWithParents(payToAddress soterutil.Address, parents []*chainhash.Hash) (*BlockTemplate, error) {
+	if len(parents) == 0 {
+		return nil, errors.New("a block template needs at least one parent")
+	}
+	maxParents := g.chainParams.MaxBlockParents
+	if maxParents > 0 && int32(len(parents)) > maxParents {
+		return nil, fmt.Errorf("a block template can't have more than "+
+			"%d parents", maxParents)
+	}
+
+	return g.newBlockTemplate(payToAddress, parents)
+}
+
+// newBlockTemplate returns a new block template that references the passed
+// parents, or the tips chosen by the dag for new blocks if parents is nil.
+// That's all of the current tips, unless there are more than MaxBlockParents
+// of them.  See the documentation of NewBlockTemplate for details.
+func (g *BlkTmplGenerator) newBlockTemplate(payToAddress soterutil.Address, parents []*chainhash.Hash) (*BlockTemplate, error) {
 	// Extend the most recently known best block.
 	best := g.chain.BestSnapshot()
 	snapshot := g.chain.DAGSnapshot()
 
-	nextBlockHeight := snapshot.MaxHeight + 1
+	parentHashes := snapshot.Parents
+	if parents != nil {
+		// Each of the passed parents must be a current tip.
+		tips := make(map[chainhash.Hash]struct{}, len(snapshot.Tips))
+		for _, tip := range snapshot.Tips {
+			tips[tip] = struct{}{}
+		}
+
+		parentHashes = make([]chainhash.Hash, 0, len(parents))
+		for _, parent := range parents {
+			if _, exists := tips[*parent]; !exists {
+				return nil, fmt.Errorf("parent %v isn't one of the "+
+					"current tips", parent)
+			}
+			parentHashes = append(parentHashes, *parent)
+		}
+	}
+
+	// The block is one higher than the highest of its parents.  That isn't
+	// necessarily the highest tip of the dag, since the tallest tip is left
+	// out when there are more tips than a block may reference.
+	var maxHeight int32
+	for i := range parentHashes {
+		height, err := g.chain.BlockHeightByHash(&parentHashes[i])
+		if err != nil {
+			return nil, err
+		}
+		if height > maxHeight {
+			maxHeight = height
+		}
+	}
+	nextBlockHeight := maxHeight + 1
 
 	// Create a standard coinbase transaction paying to the provided
 	// address.  NOTE: The coinbase value will be updated to include the
@@ -863,8 +947,16 @@ mempoolLoop:
 		return nil, err
 	}
 
-	// PrevBlock in msgBlock.Header should be the hash of the DAG tips
-	prevHash := snapshot.Hash
+	// PrevBlock in msgBlock.Header should be the hash of the DAG tips that
+	// the block references.
+	prevHash := snapshot.ParentsHash
+	if parents != nil {
+		hashes := make([]*chainhash.Hash, len(parentHashes))
+		for i := range parentHashes {
+			hashes[i] = &parentHashes[i]
+		}
+		prevHash = *blockdag.GenerateTipsHash(hashes)
+	}
 
 	// Create a new block ready to be solved.
 	merkles := blockdag.BuildMerkleTreeStore(blockTxns, false)
@@ -883,17 +975,23 @@ mempoolLoop:
 		return nil, err
 	}
 
-	var parents []*wire.Parent
-	for _, hash := range snapshot.Tips {
-		parents = append(parents, &wire.Parent{
-			Hash: hash,
+	// Parents are referenced in canonical order, so that the block
+	// serializes the same way no matter the order the tips were given in.
+	hashes := make([]*chainhash.Hash, len(parentHashes))
+	for i := range parentHashes {
+		hashes[i] = &parentHashes[i]
+	}
+	var blockParents []*wire.Parent
+	for _, hash := range soterutil.CanonicalizeParents(hashes) {
+		blockParents = append(blockParents, &wire.Parent{
+			Hash: *hash,
 		})
 	}
 
 	msgBlock.Parents = wire.ParentSubHeader{
 		Version: nextParentVersion,
-		Size: int32(len(snapshot.Tips)),
-		Parents: parents,
+		Size: int32(len(parentHashes)),
+		Parents: blockParents,
 	}
 
 	for _, tx := range blockTxns {
@@ -69,6 +69,13 @@ type invMsg struct {
 	peer *peerpkg.Peer
 }
 
+// notFoundMsg packages a soter notfound message and the peer it came from
+// together so the block handler has access to that information.
+type notFoundMsg struct {
+	notFound *wire.MsgNotFound
+	peer     *peerpkg.Peer
+}
+
 // headersMsg packages a soter headers message and the peer it came from
 // together so the block handler has access to that information.
 type headersMsg struct {
@@ -899,6 +906,10 @@ func filterSupportedInv(inventory []*wire.InvVect) []*wire.InvVect {
 		case wire.InvTypeTx:
 		case wire.InvTypeWitnessBlock:
 		case wire.InvTypeWitnessTx:
+		case wire.InvTypeDagBlock:
+			// Dag block inventory is handled the same as block
+			// inventory.
+			iv.Type = wire.InvTypeBlock
 		default:
 			continue
 		}
@@ -1181,6 +1192,35 @@ func (sm *SyncManager) handleInvMsg(imsg *invMsg) {
 	sm.reqOrphanParents(peer)
 }
 
+// handleNotFoundMsg handles notfound messages from peers, which list the
+// inventory we requested that the peer couldn't provide.  The requests are no
+// longer tracked, instead of waiting for them to expire, so the inventory can
+// be requested from the next peer that announces it.
+func (sm *SyncManager) handleNotFoundMsg(nfmsg *notFoundMsg) {
+	peer := nfmsg.peer
+	state, exists := sm.peerStates[peer]
+	if !exists {
+		log.Warnf("Received notfound message from unknown peer %s", peer)
+		return
+	}
+
+	for _, iv := range filterSupportedInv(nfmsg.notFound.InvList) {
+		switch iv.Type {
+		case wire.InvTypeBlock, wire.InvTypeWitnessBlock:
+			if _, exists := state.requestedBlocks[iv.Hash]; exists {
+				delete(state.requestedBlocks, iv.Hash)
+				delete(sm.requestedBlocks, iv.Hash)
+			}
+
+		case wire.InvTypeTx, wire.InvTypeWitnessTx:
+			if _, exists := state.requestedTxns[iv.Hash]; exists {
+				delete(state.requestedTxns, iv.Hash)
+				delete(sm.requestedTxns, iv.Hash)
+			}
+		}
+	}
+}
+
 // reqOrphanChildren sends a getblocks message to the peer, for blocks between the height of the orphan parent to
 // each child orphan block.
 func (sm *SyncManager) reqOrphanChildren(peer *peerpkg.Peer, parent *soterutil.Block) {
@@ -1352,6 +1392,9 @@ out:
 			case *headersMsg:
 				sm.handleHeadersMsg(msg)
 
+			case *notFoundMsg:
+				sm.handleNotFoundMsg(msg)
+
 			case *donePeerMsg:
 				sm.handleDonePeerMsg(msg.peer)
 
@@ -1713,6 +1756,18 @@ func (sm *SyncManager) QueueHeaders(headers *wire.MsgHeaders, peer *peerpkg.Peer
 	sm.msgChan <- &headersMsg{headers: headers, peer: peer}
 }
 
+// QueueNotFound adds the passed notfound message and peer to the block handling
+// queue.
+func (sm *SyncManager) QueueNotFound(notFound *wire.MsgNotFound, peer *peerpkg.Peer) {
+	// No channel handling here because peers do not need to block on
+	// notfound messages.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		return
+	}
+
+	sm.msgChan <- &notFoundMsg{notFound: notFound, peer: peer}
+}
+
 // DonePeer informs the blockmanager that a peer has disconnected.
 func (sm *SyncManager) DonePeer(peer *peerpkg.Peer) {
 	// Ignore if we are shutting down.
@@ -96,6 +96,8 @@ func invSummary(invList []*wire.InvVect) string {
 			return fmt.Sprintf("witness block %s", iv.Hash)
 		case wire.InvTypeBlock:
 			return fmt.Sprintf("block %s", iv.Hash)
+		case wire.InvTypeDagBlock:
+			return fmt.Sprintf("dag block %s", iv.Hash)
 		case wire.InvTypeWitnessTx:
 			return fmt.Sprintf("witness tx %s", iv.Hash)
 		case wire.InvTypeTx:
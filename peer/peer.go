@@ -30,7 +30,7 @@ import (
 
 const (
 	// MaxProtocolVersion is the max protocol version the peer supports.
-	MaxProtocolVersion = wire.FeeFilterVersion
+	MaxProtocolVersion = wire.CompressedBlockVersion
 
 	// DefaultTrickleInterval is the min time between attempts to send an
 	// inv message to a peer.
@@ -281,15 +281,11 @@ type Config struct {
 	// TrickleInterval is the duration of the ticker which trickles down the
 	// inventory to a peer.
 	TrickleInterval time.Duration
-}
 
-// minUint32 is a helper function to return the minimum of two uint32s.
-// This avoids a math import and the need to cast to floats.
-func minUint32(a, b uint32) uint32 {
-	if a < b {
-		return a
-	}
-	return b
+	// RelayLimits specifies how fast the remote peer may send relay
+	// messages.  Messages over the limits are dropped.  This field can be
+	// omitted in which case relay messages aren't limited.
+	RelayLimits *RelayLimits
 }
 
 // newNetAddress attempts to extract the IP address and port from the passed
@@ -456,6 +452,10 @@ type Peer struct {
 
 	wireEncoding wire.MessageEncoding
 
+	// relayLimiter is only used from the inHandler, and is nil when relay
+	// messages aren't limited.
+	relayLimiter *relayLimiter
+
 	knownInventory           *mruInventoryMap
 	prevGetBlocksMtx         sync.Mutex
 	prevGetBlocksStartHeight *int32
@@ -476,6 +476,12 @@ type Peer struct {
 	lastPingTime       time.Time // Time we sent last ping.
 	lastPingMicros     int64     // Time for last ping to return.
 
+	// pendingPings maps the nonces of pings sent by Ping to the channels
+	// the arrival time of their pongs is delivered on.  It is protected by
+	// the pingMtx mutex.
+	pingMtx      sync.Mutex
+	pendingPings map[uint64]chan time.Time
+
 	stallControl  chan stallControlMsg
 	outputQueue   chan outMsg
 	sendQueue     chan outMsg
@@ -669,6 +675,48 @@ func (p *Peer) LastPingMicros() int64 {
 	return lastPingMicros
 }
 
+// Ping sends a ping with a random nonce to the peer, and returns the
+// round-trip time once the pong with the same nonce arrives.  Pongs with other
+// nonces, such as the replies to the periodic pings, are ignored.  An error is
+// returned if the peer doesn't reply to pings with pongs, if no matching pong
+// arrives within the timeout, or if the peer disconnects first.
+//
+// This function is safe for concurrent access.
+func (p *Peer) Ping(timeout time.Duration) (time.Duration, error) {
+	if p.ProtocolVersion() <= wire.BIP0031Version {
+		return 0, fmt.Errorf("peer %s doesn't reply to pings", p)
+	}
+
+	nonce, err := wire.RandomUint64()
+	if err != nil {
+		return 0, err
+	}
+
+	pong := make(chan time.Time, 1)
+	p.pingMtx.Lock()
+	p.pendingPings[nonce] = pong
+	p.pingMtx.Unlock()
+	defer func() {
+		p.pingMtx.Lock()
+		delete(p.pendingPings, nonce)
+		p.pingMtx.Unlock()
+	}()
+
+	sent := time.Now()
+	p.QueueMessage(wire.NewMsgPing(nonce), nil)
+
+	select {
+	case received := <-pong:
+		return received.Sub(sent), nil
+
+	case <-time.After(timeout):
+		return 0, fmt.Errorf("no pong from peer %s within %v", p, timeout)
+
+	case <-p.quit:
+		return 0, fmt.Errorf("peer %s disconnected", p)
+	}
+}
+
 // VersionKnown returns the whether or not the version of a peer is known
 // locally.
 //
@@ -1015,9 +1063,10 @@ func (p *Peer) handlePingMsg(msg *wire.MsgPing) {
 }
 
 // handlePongMsg is invoked when a peer receives a pong soter message.  It
-// updates the ping statistics as required for recent clients (protocol
-// version > BIP0031Version).  There is no effect for older clients or when a
-// ping was not previously sent.
+// resolves a Ping waiting for the nonce of the pong, and updates the ping
+// statistics as required for recent clients (protocol version >
+// BIP0031Version).  There is no effect for older clients or when a ping was
+// not previously sent.
 func (p *Peer) handlePongMsg(msg *wire.MsgPong) {
 	// Arguably we could use a buffered channel here sending data
 	// in a fifo manner whenever we send a ping, or a list keeping track of
@@ -1027,6 +1076,17 @@ func (p *Peer) handlePongMsg(msg *wire.MsgPong) {
 	// without large usage of the ping rpc call since we ping infrequently
 	// enough that if they overlap we would have timed out the peer.
 	if p.ProtocolVersion() > wire.BIP0031Version {
+		// Deliver the arrival time to a Ping waiting for this nonce.
+		// Pongs for nonces nobody is waiting for are ignored.
+		p.pingMtx.Lock()
+		if pong, ok := p.pendingPings[msg.Nonce]; ok {
+			select {
+			case pong <- time.Now():
+			default:
+			}
+		}
+		p.pingMtx.Unlock()
+
 		p.statsMtx.Lock()
 		if p.lastPingNonce != 0 && msg.Nonce == p.lastPingNonce {
 			p.lastPingMicros = time.Since(p.lastPingTime).Nanoseconds()
@@ -1077,6 +1137,13 @@ func (p *Peer) writeMessage(msg wire.Message, enc wire.MessageEncoding) error {
 		return nil
 	}
 
+	// Compress block messages for peers that negotiated a protocol version
+	// supporting it.
+	if _, ok := msg.(*wire.MsgBlock); ok &&
+		p.ProtocolVersion() >= wire.CompressedBlockVersion {
+		enc |= wire.CompressedEncoding
+	}
+
 	// Use closures to log expensive operations so they are only run when
 	// the logging level requires it.
 	log.Debugf("%v", newLogClosure(func() string {
@@ -1400,6 +1467,14 @@ out:
 		atomic.StoreInt64(&p.lastRecv, time.Now().Unix())
 		p.stallControl <- stallControlMsg{sccReceiveMessage, rmsg}
 
+		// Drop relay messages from peers sending them faster than allowed.
+		if p.relayLimiter != nil && !p.relayLimiter.allow(rmsg.Command(), time.Now()) {
+			log.Debugf("Peer %s exceeded the %s relay limit -- dropping "+
+				"message", p, rmsg.Command())
+			idleTimer.Reset(idleTimeout)
+			continue
+		}
+
 		// Handle each supported message type.
 		p.stallControl <- stallControlMsg{sccHandlerStart, rmsg}
 		switch msg := rmsg.(type) {
@@ -1649,7 +1724,8 @@ out:
 				// out immediately, sipping the inv trickle
 				// queue.
 				if iv.Type == wire.InvTypeBlock ||
-					iv.Type == wire.InvTypeWitnessBlock {
+					iv.Type == wire.InvTypeWitnessBlock ||
+					iv.Type == wire.InvTypeDagBlock {
 
 					invMsg := wire.NewMsgInvSizeHint(1)
 					invMsg.AddInvVect(iv)
@@ -1952,7 +2028,7 @@ func (p *Peer) handleRemoteVersionMsg(msg *wire.MsgVersion) error {
 	// NOTE: If minAcceptableProtocolVersion is raised to be higher than
 	// wire.RejectVersion, this should send a reject packet before
 	// disconnecting.
-	if uint32(msg.ProtocolVersion) < minAcceptableProtocolVersion {
+	if msg.AdvertisedVersion() < minAcceptableProtocolVersion {
 		reason := fmt.Sprintf("protocol version must be %d or greater",
 			minAcceptableProtocolVersion)
 		return errors.New(reason)
@@ -1968,8 +2044,9 @@ func (p *Peer) handleRemoteVersionMsg(msg *wire.MsgVersion) error {
 
 	// Negotiate the protocol version.
 	p.flagsMtx.Lock()
-	p.advertisedProtoVer = uint32(msg.ProtocolVersion)
-	p.protocolVersion = minUint32(p.protocolVersion, p.advertisedProtoVer)
+	p.advertisedProtoVer = msg.AdvertisedVersion()
+	p.protocolVersion = wire.NegotiatedVersion(p.protocolVersion,
+		p.advertisedProtoVer)
 	p.versionKnown = true
 	log.Debugf("Negotiated protocol version %d for peer %s",
 		p.protocolVersion, p)
@@ -2000,6 +2077,12 @@ func (p *Peer) handleRemoteVersionMsg(msg *wire.MsgVersion) error {
 		p.wireEncoding = wire.WitnessEncoding
 	}
 
+	// Peers that negotiated a protocol version supporting compressed
+	// blocks send block messages compressed, so decode them accordingly.
+	if p.ProtocolVersion() >= wire.CompressedBlockVersion {
+		p.wireEncoding |= wire.CompressedEncoding
+	}
+
 	return nil
 }
 
@@ -2254,6 +2337,7 @@ func newPeerBase(origCfg *Config, inbound bool) *Peer {
 		inbound:         inbound,
 		wireEncoding:    wire.BaseEncoding,
 		knownInventory:  newMruInventoryMap(maxKnownInventory),
+		pendingPings:    make(map[uint64]chan time.Time),
 		stallControl:    make(chan stallControlMsg, 1), // nonblocking sync
 		outputQueue:     make(chan outMsg, outputBufferSize),
 		sendQueue:       make(chan outMsg, 1),   // nonblocking sync
@@ -2267,6 +2351,9 @@ func newPeerBase(origCfg *Config, inbound bool) *Peer {
 		services:        cfg.Services,
 		protocolVersion: cfg.ProtocolVersion,
 	}
+	if cfg.RelayLimits != nil {
+		p.relayLimiter = newRelayLimiter(cfg.RelayLimits, time.Now())
+	}
 	return &p
 }
 
@@ -1076,6 +1076,129 @@ func TestIncompatibleUAVersion(t *testing.T) {
 	}
 }
 
+// TestPing tests that Ping resolves with the round-trip time of the pong with
+// the nonce of its ping, ignoring a pong with a different nonce.
+func TestPing(t *testing.T) {
+	peerCfg := &peer.Config{
+		UserAgentName:     "peer",
+		UserAgentVersion: semver.Version{Major:1, Minor: 0, Patch: 0}, // User agent version to advertise.
+		UserAgentComments: []string{"comment"},
+		ChainParams:       &chaincfg.MainNetParams,
+		Services:          0,
+		TrickleInterval:   time.Second * 10,
+	}
+
+	localNA := wire.NewNetAddressIPPort(
+		net.ParseIP("10.0.0.1"),
+		uint16(8333),
+		wire.SFNodeNetwork,
+	)
+	remoteNA := wire.NewNetAddressIPPort(
+		net.ParseIP("10.0.0.2"),
+		uint16(8333),
+		wire.SFNodeNetwork,
+	)
+	localConn, remoteConn := pipe(
+		&conn{laddr: "10.0.0.1:8333", raddr: "10.0.0.2:8333"},
+		&conn{laddr: "10.0.0.2:8333", raddr: "10.0.0.1:8333"},
+	)
+	genHash := peerCfg.ChainParams.GenesisHash.CloneBytes32()
+
+	p, err := peer.NewOutboundPeer(peerCfg, "10.0.0.1:8333")
+	if err != nil {
+		t.Fatalf("NewOutboundPeer: unexpected err - %v\n", err)
+	}
+	p.AssociateConnection(localConn)
+	defer p.Disconnect()
+
+	// Read outbound messages to peer into a channel
+	outboundMessages := make(chan wire.Message, 10)
+	go func() {
+		for {
+			_, msg, _, err := wire.ReadMessageN(
+				remoteConn,
+				p.ProtocolVersion(),
+				peerCfg.ChainParams.Net,
+			)
+			if err != nil {
+				close(outboundMessages)
+				return
+			}
+
+			outboundMessages <- msg
+		}
+	}()
+
+	writeMsg := func(msg wire.Message) {
+		_, err := wire.WriteMessageN(remoteConn.Writer, msg,
+			wire.ProtocolVersion, peerCfg.ChainParams.Net)
+		if err != nil {
+			t.Fatalf("wire.WriteMessageN: unexpected err - %v\n", err)
+		}
+	}
+
+	// Complete the handshake as the remote peer.
+	select {
+	case msg := <-outboundMessages:
+		if _, ok := msg.(*wire.MsgVersion); !ok {
+			t.Fatalf("Expected version message, got [%s]", msg.Command())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Peer did not send version message")
+	}
+	writeMsg(wire.NewMsgVersion(remoteNA, localNA, 0, 0, &genHash))
+	writeMsg(wire.NewMsgVerAck())
+
+	type pingResult struct {
+		rtt time.Duration
+		err error
+	}
+	result := make(chan pingResult, 1)
+	go func() {
+		rtt, err := p.Ping(time.Second * 5)
+		result <- pingResult{rtt, err}
+	}()
+
+	// Wait for the ping, skipping any other messages sent after the
+	// handshake.
+	var ping *wire.MsgPing
+	for ping == nil {
+		select {
+		case msg, ok := <-outboundMessages:
+			if !ok {
+				t.Fatal("Peer disconnected before sending ping")
+			}
+			ping, _ = msg.(*wire.MsgPing)
+		case <-time.After(time.Second):
+			t.Fatal("Peer did not send ping message")
+		}
+	}
+
+	// A pong with a different nonce is ignored.
+	writeMsg(wire.NewMsgPong(ping.Nonce + 1))
+	select {
+	case res := <-result:
+		t.Fatalf("Ping resolved by a pong with the wrong nonce: %v, %v",
+			res.rtt, res.err)
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	// The pong with the nonce of the ping resolves it.
+	writeMsg(wire.NewMsgPong(ping.Nonce))
+	select {
+	case res := <-result:
+		if res.err != nil {
+			t.Fatalf("Ping: unexpected err - %v", res.err)
+		}
+		if res.rtt < time.Millisecond*100 {
+			t.Fatalf("Ping returned round-trip time %v, sooner than "+
+				"the matching pong was sent", res.rtt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Ping wasn't resolved by the pong with its nonce")
+	}
+}
+
 func init() {
 	// Allow self connection when running the tests.
 	peer.TstAllowSelfConns()
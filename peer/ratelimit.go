@@ -0,0 +1,142 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package peer
+
+import (
+	"time"
+
+	"github.com/soteria-dag/soterd/wire"
+)
+
+// RelayLimit is the limit on how fast a peer may send a single relay message
+// command.  Rate is the sustained number of messages allowed per second, and
+// Burst is the number of messages that can be received at once before the rate
+// applies.  A Rate of 0 means the command isn't limited.
+type RelayLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RelayLimits houses the limits on how fast a peer may send each of the relay
+// message commands (see wire.IsRelayCommand).  Messages over the limit are
+// dropped without being handled.
+type RelayLimits struct {
+	Inv     RelayLimit
+	GetData RelayLimit
+	Block   RelayLimit
+	Tx      RelayLimit
+}
+
+// DefaultRelayLimits are the default relay limits.  Inv and getdata messages
+// are limited, since a peer can cause us to do work for each of them.  Block
+// and tx messages aren't limited, since blocks are usually sent in response
+// to our own requests, and transactions are limited by the mempool policy.
+var DefaultRelayLimits = RelayLimits{
+	Inv:     RelayLimit{Rate: 100, Burst: 1000},
+	GetData: RelayLimit{Rate: 100, Burst: 1000},
+}
+
+// forCommand returns the limit for the relay message command.  Commands which
+// aren't relay commands have no limit.
+func (l *RelayLimits) forCommand(cmd string) RelayLimit {
+	switch cmd {
+	case wire.CmdInv:
+		return l.Inv
+	case wire.CmdGetData:
+		return l.GetData
+	case wire.CmdBlock:
+		return l.Block
+	case wire.CmdTx:
+		return l.Tx
+	}
+
+	return RelayLimit{}
+}
+
+// tokenBucket is a token bucket rate limiter.  The bucket holds up to burst
+// tokens, and is refilled at rate tokens per second.  Each allowed message
+// takes a token from the bucket.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a full token bucket for the limit.
+func newTokenBucket(limit RelayLimit, now time.Time) *tokenBucket {
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+// allow refills the bucket for the time passed since it was last used, and
+// returns whether there is a token available for a message at the given time.
+// The token is taken from the bucket when there is.
+func (b *tokenBucket) allow(now time.Time) bool {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// relayLimiter limits how fast a peer may send relay messages, with a token
+// bucket per limited command.
+//
+// NOTE: A relayLimiter isn't safe for concurrent access.  It's only used from
+// the peer's inHandler.
+type relayLimiter struct {
+	buckets map[string]*tokenBucket
+}
+
+// newRelayLimiter returns a relay limiter enforcing the limits.
+func newRelayLimiter(limits *RelayLimits, now time.Time) *relayLimiter {
+	l := relayLimiter{
+		buckets: make(map[string]*tokenBucket),
+	}
+
+	for _, cmd := range []string{wire.CmdInv, wire.CmdGetData, wire.CmdBlock, wire.CmdTx} {
+		limit := limits.forCommand(cmd)
+		if limit.Rate <= 0 {
+			continue
+		}
+		l.buckets[cmd] = newTokenBucket(limit, now)
+	}
+
+	return &l
+}
+
+// allow returns whether a message with the command received at the given time
+// is within the limits.  Commands without a limit are always allowed.
+func (l *relayLimiter) allow(cmd string, now time.Time) bool {
+	if !wire.IsRelayCommand(cmd) {
+		return true
+	}
+
+	b, ok := l.buckets[cmd]
+	if !ok {
+		return true
+	}
+
+	return b.allow(now)
+}
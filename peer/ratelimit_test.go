@@ -0,0 +1,112 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package peer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/soteria-dag/soterd/wire"
+)
+
+// TestTokenBucket tests the token bucket refill and burst math.
+func TestTokenBucket(t *testing.T) {
+	start := time.Unix(1000, 0)
+	b := newTokenBucket(RelayLimit{Rate: 2, Burst: 3}, start)
+
+	// The bucket starts full, so a burst is allowed at once.
+	for i := 0; i < 3; i++ {
+		if !b.allow(start) {
+			t.Fatalf("message %d of the burst wasn't allowed", i)
+		}
+	}
+	if b.allow(start) {
+		t.Fatalf("message past the burst was allowed")
+	}
+
+	// Half a second at 2 tokens per second refills a single token.
+	now := start.Add(500 * time.Millisecond)
+	if !b.allow(now) {
+		t.Fatalf("message after refill wasn't allowed")
+	}
+	if b.allow(now) {
+		t.Fatalf("second message after partial refill was allowed")
+	}
+
+	// Partial tokens accumulate across refills.
+	now = now.Add(250 * time.Millisecond)
+	if b.allow(now) {
+		t.Fatalf("message allowed with half a token")
+	}
+	now = now.Add(250 * time.Millisecond)
+	if !b.allow(now) {
+		t.Fatalf("message not allowed after partial tokens accumulated")
+	}
+
+	// The bucket never holds more than the burst.
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if !b.allow(now) {
+			t.Fatalf("message %d of the refilled burst wasn't allowed", i)
+		}
+	}
+	if b.allow(now) {
+		t.Fatalf("bucket refilled past the burst")
+	}
+
+	// Time going backwards doesn't add tokens.
+	if b.allow(start) {
+		t.Fatalf("message allowed after time went backwards")
+	}
+
+	// A burst below 1 still allows a message at a time.
+	b = newTokenBucket(RelayLimit{Rate: 1}, start)
+	if !b.allow(start) {
+		t.Fatalf("message not allowed with a 0 burst")
+	}
+	if b.allow(start) {
+		t.Fatalf("second message allowed with a 0 burst")
+	}
+}
+
+// TestRelayLimiter tests that the relay limiter only limits the relay
+// commands with a limit.
+func TestRelayLimiter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	limits := RelayLimits{
+		Inv: RelayLimit{Rate: 1, Burst: 2},
+		Tx:  RelayLimit{Rate: 0, Burst: 2},
+	}
+	l := newRelayLimiter(&limits, now)
+
+	tests := []struct {
+		cmd  string
+		want []bool
+	}{
+		// Limited relay command.
+		{wire.CmdInv, []bool{true, true, false, false}},
+		// Relay commands without a rate aren't limited.
+		{wire.CmdTx, []bool{true, true, true, true}},
+		{wire.CmdGetData, []bool{true, true, true, true}},
+		// Other commands are never limited.
+		{wire.CmdPing, []bool{true, true, true, true}},
+	}
+
+	for _, test := range tests {
+		for i, want := range test.want {
+			got := l.allow(test.cmd, now)
+			if got != want {
+				t.Errorf("allow %s #%d: got %v, want %v", test.cmd,
+					i, got, want)
+			}
+		}
+	}
+
+	// Limits are per command, so the limited inv command refills
+	// without affecting the others.
+	if !l.allow(wire.CmdInv, now.Add(time.Second)) {
+		t.Errorf("inv not allowed after refill")
+	}
+}
@@ -156,6 +156,24 @@ func (cm *rpcConnManager) ConnectedCount() int32 {
 	return cm.server.ConnectedCount()
 }
 
+// PeerLimits returns the maximum number of inbound and outbound peers, along
+// with the number of peers currently connected.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) PeerLimits() peerLimits {
+	return cm.server.PeerLimits()
+}
+
+// SetPeerLimits changes the maximum number of inbound and outbound peers,
+// disconnecting peers over the new limits.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) SetPeerLimits(maxInbound, maxOutbound int) {
+	cm.server.SetPeerLimits(maxInbound, maxOutbound)
+}
+
 // NetTotals returns the sum of all bytes received and sent across the network
 // for all peers.
 //
@@ -10,12 +10,33 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 
 	"github.com/soteria-dag/soterd/chaincfg/chainhash"
 	"github.com/soteria-dag/soterd/soterjson"
 	"github.com/soteria-dag/soterd/wire"
 )
 
+var (
+	// ErrRawMempoolNotVerbose is returned by GetRawMempoolVerbose when the
+	// server replies with the non-verbose array of transaction hashes.
+	ErrRawMempoolNotVerbose = errors.New("getrawmempool returned an array " +
+		"of transaction hashes instead of verbose results")
+
+	// ErrRawMempoolVerbose is returned by GetRawMempool when the server
+	// replies with verbose results instead of an array of transaction
+	// hashes.
+	ErrRawMempoolVerbose = errors.New("getrawmempool returned verbose " +
+		"results instead of an array of transaction hashes")
+)
+
+// isJSONArray returns whether the given JSON value is an array.
+func isJSONArray(res []byte) bool {
+	trimmed := bytes.TrimLeft(res, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
 // FutureGetBestBlockHashResult is a future promise to deliver the result of a
 // GetBestBlockAsync RPC invocation (or an applicable error).
 type FutureGetBestBlockHashResult chan *response
@@ -293,31 +314,20 @@ func (c *Client) GetBlockChainInfo() (*soterjson.GetBlockChainInfoResult, error)
 type FutureGetBlockHashResult chan *response
 
 // Receive waits for the response promised by the future and returns the hash of
-// the block in the best block chain at the given height.
-func (r FutureGetBlockHashResult) Receive() ([]*chainhash.Hash, error) {
+// the first block in the dag ordering at the given height.
+func (r FutureGetBlockHashResult) Receive() (*chainhash.Hash, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
 		return nil, err
 	}
 
 	// Unmarshal the result as a string-encoded sha.
-	var txHashStrings []string
-	err = json.Unmarshal(res, &txHashStrings)
+	var txHashStr string
+	err = json.Unmarshal(res, &txHashStr)
 	if err != nil {
 		return nil, err
 	}
-
-	var hashes []*chainhash.Hash
-	for _, hashString := range txHashStrings {
-		hash, err := chainhash.NewHashFromStr(hashString)
-		if err != nil {
-			return nil, err
-		}
-
-		hashes = append(hashes, hash)
-	}
-
-	return hashes, nil
+	return chainhash.NewHashFromStr(txHashStr)
 }
 
 // GetBlockHashAsync returns an instance of a type that can be used to get the
@@ -330,12 +340,153 @@ func (c *Client) GetBlockHashAsync(blockHeight int64) FutureGetBlockHashResult {
 	return c.sendCmd(cmd)
 }
 
-// GetBlockHash returns the hash of the block in the best block chain at the
-// given height.
-func (c *Client) GetBlockHash(blockHeight int64) ([]*chainhash.Hash, error) {
+// GetBlockHash returns the hash of the first block in the dag ordering at the
+// given height.  Use GetDagBlockHashes to get all blocks at the height.
+func (c *Client) GetBlockHash(blockHeight int64) (*chainhash.Hash, error) {
 	return c.GetBlockHashAsync(blockHeight).Receive()
 }
 
+// HeightBlocks holds the blocks of the dag at a single height.
+type HeightBlocks struct {
+	Height int32
+	Blocks []*wire.MsgBlock
+}
+
+// sendIfBatch sends the queued requests when the client was created with
+// NewBatch, so that their futures can be received.  It does nothing for
+// other clients, whose requests are sent as they're issued.
+func (c *Client) sendIfBatch() error {
+	if !c.batch {
+		return nil
+	}
+	return c.Send()
+}
+
+// GetBlocksByHeightRange returns the blocks of the dag at each height from
+// start to end, inclusive, ordered by height.  Since several blocks of a dag
+// can share a height, the blocks are grouped per height.
+//
+// The getdagblockhashes and getblock requests for the range are issued together,
+// rather than waiting for each reply in turn.  When the client was created
+// with NewBatch they're sent as batches, which also sends any requests the
+// caller had already queued.
+func (c *Client) GetBlocksByHeightRange(start, end int32) ([]HeightBlocks, error) {
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("invalid height range %d to %d", start, end)
+	}
+
+	hashes, err := c.getHashesByHeightRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.getHeightBlocks(start, hashes)
+}
+
+// GetBlocksByHeightRangePage returns a page of the blocks of the dag from
+// start to end, inclusive, grouped per height like GetBlocksByHeightRange.
+// The page holds the blocks of as many whole heights from start as fit in limit
+// blocks, so that large ranges can be fetched without holding them in memory at
+// once.  A height with more than limit blocks is returned on a page of its own.
+//
+// The returned cursor is the height to start the next page from, or -1 when
+// the page reaches end.
+func (c *Client) GetBlocksByHeightRangePage(start, end int32, limit int) ([]HeightBlocks, int32, error) {
+	if start < 0 || end < start {
+		return nil, -1, fmt.Errorf("invalid height range %d to %d", start, end)
+	}
+	if limit <= 0 {
+		return nil, -1, fmt.Errorf("invalid page limit %d", limit)
+	}
+
+	// Every height of the dag has at least one block, so a page never
+	// spans more than limit heights.
+	last := end
+	if int64(last)-int64(start) >= int64(limit) {
+		last = start + int32(limit) - 1
+	}
+	hashes, err := c.getHashesByHeightRange(start, last)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	count := len(hashes[0])
+	heights := 1
+	for heights < len(hashes) && count+len(hashes[heights]) <= limit {
+		count += len(hashes[heights])
+		heights++
+	}
+
+	blocks, err := c.getHeightBlocks(start, hashes[:heights])
+	if err != nil {
+		return nil, -1, err
+	}
+
+	next := start + int32(heights)
+	if next > end {
+		next = -1
+	}
+	return blocks, next, nil
+}
+
+// getHashesByHeightRange returns the hashes of the blocks at each height from
+// start to end, inclusive.  The requests are issued together, and sent as a
+// batch when the client was created with NewBatch.
+func (c *Client) getHashesByHeightRange(start, end int32) ([][]*chainhash.Hash, error) {
+	// Request the hashes of the blocks at every height in the range.
+	hashFutures := make([]FutureGetDagBlockHashesResult, 0, end-start+1)
+	for height := start; height <= end; height++ {
+		hashFutures = append(hashFutures, c.GetDagBlockHashesAsync(height))
+	}
+	if err := c.sendIfBatch(); err != nil {
+		return nil, err
+	}
+
+	hashes := make([][]*chainhash.Hash, 0, len(hashFutures))
+	for i, f := range hashFutures {
+		heightHashes, err := f.Receive()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get block hashes at "+
+				"height %d: %w", start+int32(i), err)
+		}
+		hashes = append(hashes, heightHashes)
+	}
+
+	return hashes, nil
+}
+
+// getHeightBlocks returns the blocks with the given hashes, which are the
+// hashes of the blocks at each height from start.  The requests are issued
+// together, and sent as a batch when the client was created with NewBatch.
+func (c *Client) getHeightBlocks(start int32, hashes [][]*chainhash.Hash) ([]HeightBlocks, error) {
+	// Request the blocks for all of the hashes.
+	blockFutures := make([][]FutureGetBlockResult, len(hashes))
+	for i, heightHashes := range hashes {
+		for _, hash := range heightHashes {
+			blockFutures[i] = append(blockFutures[i], c.GetBlockAsync(hash))
+		}
+	}
+	if err := c.sendIfBatch(); err != nil {
+		return nil, err
+	}
+
+	result := make([]HeightBlocks, len(hashes))
+	for i, futures := range blockFutures {
+		result[i].Height = start + int32(i)
+		result[i].Blocks = make([]*wire.MsgBlock, 0, len(futures))
+		for j, f := range futures {
+			block, err := f.Receive()
+			if err != nil {
+				return nil, fmt.Errorf("unable to get block %v: %w",
+					hashes[i][j], err)
+			}
+			result[i].Blocks = append(result[i].Blocks, block)
+		}
+	}
+
+	return result, nil
+}
+
 // FutureGetBlockHeaderResult is a future promise to deliver the result of a
 // GetBlockHeaderAsync RPC invocation (or an applicable error).
 type FutureGetBlockHeaderResult chan *response
@@ -477,6 +628,45 @@ func (c *Client) GetMempoolEntry(txHash string) (*soterjson.GetMempoolEntryResul
 	return c.GetMempoolEntryAsync(txHash).Receive()
 }
 
+// FutureGetMempoolInfoResult is a future promise to deliver the result of a
+// GetMempoolInfoAsync RPC invocation (or an applicable error).
+type FutureGetMempoolInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns a data
+// structure with the size, byte count and minimum fee of the memory pool.
+func (r FutureGetMempoolInfoResult) Receive() (*soterjson.GetMempoolInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal the result as a getmempoolinfo result object.
+	var mempoolInfo soterjson.GetMempoolInfoResult
+	err = json.Unmarshal(res, &mempoolInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mempoolInfo, nil
+}
+
+// GetMempoolInfoAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetMempoolInfo for the blocking version and more details.
+func (c *Client) GetMempoolInfoAsync() FutureGetMempoolInfoResult {
+	cmd := soterjson.NewGetMempoolInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetMempoolInfo returns a data structure with the number of transactions in
+// the memory pool, their total size in bytes, and the minimum fee required for
+// a transaction to be accepted into it.
+func (c *Client) GetMempoolInfo() (*soterjson.GetMempoolInfoResult, error) {
+	return c.GetMempoolInfoAsync().Receive()
+}
+
 // FutureGetRawMempoolResult is a future promise to deliver the result of a
 // GetRawMempoolAsync RPC invocation (or an applicable error).
 type FutureGetRawMempoolResult chan *response
@@ -489,6 +679,12 @@ func (r FutureGetRawMempoolResult) Receive() ([]*chainhash.Hash, error) {
 		return nil, err
 	}
 
+	// The server replies with an object when verbose results were
+	// requested, so make the mismatch clear to the caller.
+	if !isJSONArray(res) {
+		return nil, ErrRawMempoolVerbose
+	}
+
 	// Unmarshal the result as an array of strings.
 	var txHashStrs []string
 	err = json.Unmarshal(res, &txHashStrs)
@@ -540,6 +736,12 @@ func (r FutureGetRawMempoolVerboseResult) Receive() (map[string]soterjson.GetRaw
 		return nil, err
 	}
 
+	// The server replies with an array of transaction hashes when verbose
+	// results weren't requested, so make the mismatch clear to the caller.
+	if isJSONArray(res) {
+		return nil, ErrRawMempoolNotVerbose
+	}
+
 	// Unmarshal the result as a map of strings (tx shas) to their detailed
 	// results.
 	var mempoolItems map[string]soterjson.GetRawMempoolVerboseResult
@@ -601,11 +803,53 @@ func (c *Client) EstimateFeeAsync(numBlocks int64) FutureEstimateFeeResult {
 	return c.sendCmd(cmd)
 }
 
-// EstimateFee provides an estimated fee  in soter tokens per kilobyte.
+// EstimateFee provides an estimated fee  in soter tokens per kilobyte.  -1 is
+// returned when the server doesn't have enough data for an estimate.
 func (c *Client) EstimateFee(numBlocks int64) (float64, error) {
 	return c.EstimateFeeAsync(numBlocks).Receive()
 }
 
+// FutureEstimateSmartFeeResult is a future promise to deliver the result of a
+// EstimateSmartFeeAsync RPC invocation (or an applicable error).
+type FutureEstimateSmartFeeResult chan *response
+
+// Receive waits for the response promised by the future and returns the fee
+// estimate provided by the server.
+func (r FutureEstimateSmartFeeResult) Receive() (*soterjson.EstimateSmartFeeResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an estimatesmartfee result object.
+	var estimate soterjson.EstimateSmartFeeResult
+	err = json.Unmarshal(res, &estimate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &estimate, nil
+}
+
+// EstimateSmartFeeAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See EstimateSmartFee for the blocking version and more details.
+func (c *Client) EstimateSmartFeeAsync(confTarget int64) FutureEstimateSmartFeeResult {
+	cmd := soterjson.NewEstimateSmartFeeCmd(confTarget)
+	return c.sendCmd(cmd)
+}
+
+// EstimateSmartFee provides an estimated fee in soter tokens per kilobyte for
+// a transaction to be mined within confTarget blocks.  When the server doesn't
+// have enough data for the target, the estimate is for the first higher number
+// of blocks that it has data for, which is given by the Blocks field of the
+// result.  FeeRate is nil if there isn't enough data for any estimate.
+func (c *Client) EstimateSmartFee(confTarget int64) (*soterjson.EstimateSmartFeeResult, error) {
+	return c.EstimateSmartFeeAsync(confTarget).Receive()
+}
+
 // FutureVerifyChainResult is a future promise to deliver the result of a
 // VerifyChainAsync, VerifyChainLevelAsyncRPC, or VerifyChainBlocksAsync
 // invocation (or an applicable error).
@@ -0,0 +1,105 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+	"github.com/soteria-dag/soterd/soterjson"
+)
+
+// abandonOnDone returns a channel which delivers the reply from responseChan,
+// or the context's error if the context is done first.  When the context is
+// done, the request with the id is abandoned:
+//
+//   - It's removed from the client's pending requests, so a late reply over the
+//     websocket connection is ignored, and the request isn't resent when the
+//     client reconnects.
+//   - An in-flight HTTP POST request is cancelled along with the context.
+//   - A request queued on a batch client is still sent by Send, but its reply
+//     is ignored.
+//
+// responseChan is returned as is for contexts which are never done.
+func (c *Client) abandonOnDone(ctx context.Context, id uint64, responseChan chan *response) chan *response {
+	if ctx.Done() == nil {
+		return responseChan
+	}
+
+	ctxChan := make(chan *response, 1)
+	go func() {
+		select {
+		case r := <-responseChan:
+			ctxChan <- r
+
+		case <-ctx.Done():
+			c.removeRequest(id)
+			ctxChan <- &response{err: ctx.Err()}
+		}
+	}()
+
+	return ctxChan
+}
+
+// withRequestTimeout returns a context which is done when the client's
+// RequestTimeout passes, along with the function to release it.  The context
+// is returned as is, with a nil function, when the client has no request
+// timeout.
+func (c *Client) withRequestTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.config.RequestTimeout <= 0 {
+		return ctx, nil
+	}
+
+	return context.WithTimeout(ctx, c.config.RequestTimeout)
+}
+
+// timeoutResponse returns a channel which delivers the reply from
+// responseChan, after releasing the request context with cancel.  When the
+// request failed because the client's request timeout passed, rather than
+// because the caller's context is done, ErrRequestTimeout is delivered
+// instead of the error.
+func timeoutResponse(ctx, reqCtx context.Context, cancel context.CancelFunc,
+	responseChan chan *response) chan *response {
+
+	timeoutChan := make(chan *response, 1)
+	go func() {
+		r := <-responseChan
+		timedOut := reqCtx.Err() == context.DeadlineExceeded
+		cancel()
+		if r.err != nil && timedOut && ctx.Err() == nil {
+			r = &response{err: ErrRequestTimeout}
+		}
+		timeoutChan <- r
+	}()
+
+	return timeoutChan
+}
+
+// CallCtx sends the command to the server and waits for the reply, returning
+// the raw JSON result.  The command must be one of the soterjson command types
+// registered with soterjson.RegisterCmd.
+//
+// If the context is done before the reply is received, the request is
+// abandoned and the context's error is returned.  The id of an abandoned
+// request isn't reused, and a late reply to it is ignored.
+func (c *Client) CallCtx(ctx context.Context, cmd interface{}) (json.RawMessage, error) {
+	return receiveFuture(c.sendCmdCtx(ctx, cmd))
+}
+
+// GetBlockCountCtx is like GetBlockCount, but the request is abandoned when the
+// context is done (see CallCtx).
+func (c *Client) GetBlockCountCtx(ctx context.Context) (int64, error) {
+	cmd := soterjson.NewGetBlockCountCmd()
+	return FutureGetBlockCountResult(c.sendCmdCtx(ctx, cmd)).Receive()
+}
+
+// GenerateCtx is like Generate, but the request is abandoned when the context
+// is done (see CallCtx).  The server isn't told to stop generating blocks, so
+// blocks may still be generated after the request is abandoned.
+func (c *Client) GenerateCtx(ctx context.Context, numBlocks uint32) ([]*chainhash.Hash, error) {
+	cmd := soterjson.NewGenerateCmd(numBlocks)
+	return FutureGenerateResult(c.sendCmdCtx(ctx, cmd)).Receive()
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCallCtxCancel tests that an HTTP POST request is cancelled when its
+// context is done, and that the client can still be used afterwards.
+func TestCallCtxCancel(t *testing.T) {
+	// The server stalls on the first request, until the client cancels it.
+	cancelled := make(chan struct{})
+	first := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The closed connection is only noticed once the body is read.
+		ioutil.ReadAll(r.Body)
+		if first {
+			first = false
+			select {
+			case <-r.Context().Done():
+				close(cancelled)
+			case <-time.After(time.Second * 10):
+			}
+			return
+		}
+		w.Write([]byte(`{"result":5,"error":null,"id":2}`))
+	}))
+	defer server.Close()
+
+	client, err := New(&ConnConfig{
+		Host:         strings.TrimPrefix(server.URL, "http://"),
+		DisableTLS:   true,
+		HTTPPostMode: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("unable to create client: %v", err)
+	}
+	defer client.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		time.Millisecond*100)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.GetBlockCountCtx(ctx)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("GetBlockCountCtx: unexpected error. Got %v, wanted %v",
+			err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second*5 {
+		t.Fatalf("GetBlockCountCtx took %v to be cancelled", elapsed)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second * 5):
+		t.Fatalf("HTTP request wasn't cancelled with its context")
+	}
+
+	// Requests with a context which is already done aren't sent.
+	if _, err := client.GetBlockCountCtx(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("GetBlockCountCtx with done context: unexpected "+
+			"error. Got %v, wanted %v", err, context.DeadlineExceeded)
+	}
+
+	count, err := client.GetBlockCountCtx(context.Background())
+	if err != nil {
+		t.Fatalf("GetBlockCountCtx failed after cancellation: %v", err)
+	}
+	if count != 5 {
+		t.Fatalf("GetBlockCountCtx returned %d, want 5", count)
+	}
+}
+
+// TestRequestTimeout tests that a request to a server which never replies
+// fails with ErrRequestTimeout once the client's RequestTimeout passes.
+func TestRequestTimeout(t *testing.T) {
+	// The server never replies, until the client gives up on the request.
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	client, err := New(&ConnConfig{
+		Host:           strings.TrimPrefix(server.URL, "http://"),
+		DisableTLS:     true,
+		HTTPPostMode:   true,
+		RequestTimeout: time.Millisecond * 100,
+	}, nil)
+	if err != nil {
+		t.Fatalf("unable to create client: %v", err)
+	}
+	defer client.Shutdown()
+
+	start := time.Now()
+	_, err = client.GetBlockCount()
+	if err != ErrRequestTimeout {
+		t.Fatalf("GetBlockCount: unexpected error. Got %v, wanted %v",
+			err, ErrRequestTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second*5 {
+		t.Fatalf("GetBlockCount took %v to time out", elapsed)
+	}
+
+	// A caller's context which is done first still gets its own error.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.GetBlockCountCtx(ctx); err != context.Canceled {
+		t.Fatalf("GetBlockCountCtx: unexpected error. Got %v, wanted %v",
+			err, context.Canceled)
+	}
+}
@@ -6,7 +6,10 @@ package rpcclient
 
 import (
 	"encoding/json"
+	"fmt"
+	"math/big"
 
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
 	"github.com/soteria-dag/soterd/soterjson"
 )
 
@@ -35,15 +38,615 @@ func (r FutureGetDAGTipsResult) Receive() (*soterjson.GetDAGTipsResult, error) {
 //
 // See GetDAGTips for the blocking version and more details.
 func (c *Client) GetDAGTipsAsync() FutureGetDAGTipsResult {
-	cmd := soterjson.NewGetDAGTipsCmd()
+	cmd := soterjson.NewGetDAGTipsCmd(nil, nil)
 	return c.sendCmd(cmd)
 }
 
-// GetDAGTips returns information about the tip of the block DAG
+// GetDAGTips returns information about the tip of the block DAG.  The server
+// returns at most its maximum page size of tips, with NextOffset set when there
+// are more of them.  Use GetDAGTipsPage to page through a larger set of tips.
 func (c *Client) GetDAGTips() (*soterjson.GetDAGTipsResult, error) {
 	return c.GetDAGTipsAsync().Receive()
 }
 
+// GetDAGTipsPageAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetDAGTipsPage for the blocking version and more details.
+func (c *Client) GetDAGTipsPageAsync(offset, limit int32) FutureGetDAGTipsResult {
+	cmd := soterjson.NewGetDAGTipsCmd(&offset, &limit)
+	return c.sendCmd(cmd)
+}
+
+// GetDAGTipsPage returns information about the tip of the block DAG, with at
+// most limit of the tips starting at offset, sorted by height and then hash.
+// A limit of 0 returns as many tips as the server allows.
+//
+// The NextOffset of the result is the offset to request the next page with, or
+// 0 once the last page has been returned.  Pages are taken from the tips at the
+// time of each call, so the Hash of the result can be compared between pages
+// to detect a change of the tips while paging.
+func (c *Client) GetDAGTipsPage(offset, limit int32) (*soterjson.GetDAGTipsResult, error) {
+	return c.GetDAGTipsPageAsync(offset, limit).Receive()
+}
+
+// FutureGetDagHashesPerSecResult is a future promise to deliver the result of
+// a GetDagHashesPerSecAsync RPC invocation (or an applicable error).
+type FutureGetDagHashesPerSecResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// estimated network hashes per second over the window of the dag ordering.
+func (r FutureGetDagHashesPerSecResult) Receive() (int64, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return -1, err
+	}
+
+	// Unmarshal result as an int64.
+	var result int64
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	return result, nil
+}
+
+// GetDagHashesPerSecAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetDagHashesPerSec for the blocking version and more details.
+func (c *Client) GetDagHashesPerSecAsync(blocks, height int32) FutureGetDagHashesPerSecResult {
+	cmd := soterjson.NewGetDagHashPSCmd(&blocks, &height)
+	return c.sendCmd(cmd)
+}
+
+// GetDagHashesPerSec returns the estimated network hashes per second over the
+// last blocks in the dag ordering with a height of at most the given height.
+// Blocks at the same height are included in the order of the dag ordering.
+//
+// Pass -1 for blocks to use the number of blocks in a difficulty retarget
+// interval, and -1 for height to end the window at the max height of the dag.
+func (c *Client) GetDagHashesPerSec(blocks, height int32) (int64, error) {
+	return c.GetDagHashesPerSecAsync(blocks, height).Receive()
+}
+
+// FutureGetDagInfoResult is a promise to deliver the result of a
+// GetDagInfoAsync RPC invocation (or an applicable error).
+type FutureGetDagInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// summary of the dag provided by the server.
+func (r FutureGetDagInfoResult) Receive() (*soterjson.GetDagInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var info soterjson.GetDagInfoResult
+	if err := json.Unmarshal(res, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetDagInfoAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetDagInfo for the blocking version and more details.
+func (c *Client) GetDagInfoAsync() FutureGetDagInfoResult {
+	cmd := soterjson.NewGetDagInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetDagInfo returns a summary of the block DAG: the number of tips and blocks,
+// the max height, the size of the blue set and the number of orphan blocks.
+func (c *Client) GetDagInfo() (*soterjson.GetDagInfoResult, error) {
+	return c.GetDagInfoAsync().Receive()
+}
+
+// FutureGetDagOrderHashResult is a promise to deliver the result of a
+// GetDagOrderHashAsync RPC invocation (or an applicable error).
+type FutureGetDagOrderHashResult chan *response
+
+// Receive waits for the response promised by the future and returns the block
+// at the requested position of the dag ordering.
+func (r FutureGetDagOrderHashResult) Receive() (*soterjson.GetDagOrderHashResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var pos soterjson.GetDagOrderHashResult
+	if err := json.Unmarshal(res, &pos); err != nil {
+		return nil, err
+	}
+	return &pos, nil
+}
+
+// GetDagOrderHashAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetDagOrderHash for the blocking version and more details.
+func (c *Client) GetDagOrderHashAsync(index int32) FutureGetDagOrderHashResult {
+	cmd := soterjson.NewGetDagOrderHashCmd(index)
+	return c.sendCmd(cmd)
+}
+
+// GetDagOrderHash returns the hash of the block at the given position of the
+// linear ordering of the dag, along with the length of the ordering.
+//
+// New blocks may change which blocks hold the positions at the end of the
+// ordering, so indexers should only rely on positions reported as stable,
+// which are held by blocks deep enough in the dag that new blocks aren't
+// expected to be ordered before them.
+func (c *Client) GetDagOrderHash(index int32) (*soterjson.GetDagOrderHashResult, error) {
+	return c.GetDagOrderHashAsync(index).Receive()
+}
+
+// FutureGetDAGTipInfoResult is a promise to deliver the result of a
+// GetDAGTipInfoAsync RPC invocation (or an applicable error).
+type FutureGetDAGTipInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns the hash,
+// height and work of each dag tip, sorted by height and then hash.
+func (r FutureGetDAGTipInfoResult) Receive() ([]soterjson.DAGTip, error) {
+	dagSnapshot, err := FutureGetDAGTipsResult(r).Receive()
+	if err != nil {
+		return nil, err
+	}
+
+	return dagSnapshot.TipInfo, nil
+}
+
+// GetDAGTipInfoAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetDAGTipInfo for the blocking version and more details.
+func (c *Client) GetDAGTipInfoAsync() FutureGetDAGTipInfoResult {
+	cmd := soterjson.NewGetDAGTipsCmd(nil, nil)
+	return c.sendCmd(cmd)
+}
+
+// GetDAGTipInfo returns the hash, height and work of each tip of the block DAG,
+// sorted by height and then hash.
+func (c *Client) GetDAGTipInfo() ([]soterjson.DAGTip, error) {
+	return c.GetDAGTipInfoAsync().Receive()
+}
+
+// FutureGetDagWorkResult is a promise to deliver the result of a
+// GetDagWorkAsync RPC invocation (or an applicable error).
+type FutureGetDagWorkResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// cumulative work of the block DAG.
+func (r FutureGetDagWorkResult) Receive() (*big.Int, error) {
+	dagSnapshot, err := FutureGetDAGTipsResult(r).Receive()
+	if err != nil {
+		return nil, err
+	}
+
+	work, ok := new(big.Int).SetString(dagSnapshot.DagWork, 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid dag work %q", dagSnapshot.DagWork)
+	}
+
+	return work, nil
+}
+
+// GetDagWorkAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetDagWork for the blocking version and more details.
+func (c *Client) GetDagWorkAsync() FutureGetDagWorkResult {
+	cmd := soterjson.NewGetDAGTipsCmd(nil, nil)
+	return c.sendCmd(cmd)
+}
+
+// GetDagWork returns the cumulative work of the block DAG.
+//
+// Rather than following a single chain, the DAG accumulates work across its
+// blue set: the total is the sum of the proof-of-work of every blue block,
+// and red blocks don't contribute to it.  See the Work and DagWork fields of
+// the GetBlockVerbose result for the work of an individual block.
+func (c *Client) GetDagWork() (*big.Int, error) {
+	return c.GetDagWorkAsync().Receive()
+}
+
+// FutureGetDagPathResult is a future promise to deliver the result of a
+// GetDagPathAsync RPC invocation (or an applicable error).
+type FutureGetDagPathResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// hashes of the blocks in the path.
+func (r FutureGetDagPathResult) Receive() ([]*chainhash.Hash, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal the result as an array of string-encoded hashes.
+	var hashStrings []string
+	err = json.Unmarshal(res, &hashStrings)
+	if err != nil {
+		return nil, err
+	}
+	if len(hashStrings) == 0 {
+		return nil, nil
+	}
+
+	hashes := make([]*chainhash.Hash, 0, len(hashStrings))
+	for _, hashString := range hashStrings {
+		hash, err := chainhash.NewHashFromStr(hashString)
+		if err != nil {
+			return nil, err
+		}
+
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil
+}
+
+// GetDagPathAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetDagPath for the blocking version and more details.
+func (c *Client) GetDagPathAsync(from, to *chainhash.Hash) FutureGetDagPathResult {
+	fromStr := ""
+	if from != nil {
+		fromStr = from.String()
+	}
+	toStr := ""
+	if to != nil {
+		toStr = to.String()
+	}
+
+	cmd := soterjson.NewGetDagPathCmd(fromStr, toStr)
+	return c.sendCmd(cmd)
+}
+
+// GetDagPath returns a shortest path of parent links from the block with hash
+// from to its ancestor with hash to.  The path starts with from and ends with
+// to, and each block in it is a parent of the block before it.  A nil path is
+// returned if to isn't an ancestor of from.
+func (c *Client) GetDagPath(from, to *chainhash.Hash) ([]*chainhash.Hash, error) {
+	return c.GetDagPathAsync(from, to).Receive()
+}
+
+// FutureGetAnticoneResult is a future promise to deliver the result of a
+// GetAnticoneAsync RPC invocation (or an applicable error).
+type FutureGetAnticoneResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// anticone of the block.
+func (r FutureGetAnticoneResult) Receive() (*soterjson.GetAnticoneResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result soterjson.GetAnticoneResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAnticoneAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAnticone for the blocking version and more details.
+func (c *Client) GetAnticoneAsync(hash *chainhash.Hash) FutureGetAnticoneResult {
+	hashStr := ""
+	if hash != nil {
+		hashStr = hash.String()
+	}
+
+	cmd := soterjson.NewGetAnticoneCmd(hashStr, nil)
+	return c.sendCmd(cmd)
+}
+
+// GetAnticone returns the anticone of the block with the given hash, which is
+// the blocks of the dag that are neither ancestors nor descendants of it,
+// sorted by height and then hash.  The server returns at most 1000 hashes, and
+// sets the Truncated field of the result when the anticone holds more blocks
+// than that.
+func (c *Client) GetAnticone(hash *chainhash.Hash) (*soterjson.GetAnticoneResult, error) {
+	return c.GetAnticoneAsync(hash).Receive()
+}
+
+// FutureGetDagBlockHashesResult is a future promise to deliver the result of a
+// GetDagBlockHashesAsync RPC invocation (or an applicable error).
+type FutureGetDagBlockHashesResult chan *response
+
+// Receive waits for the response promised by the future and returns the hashes
+// of all blocks at the given height, sorted by the dag ordering.
+func (r FutureGetDagBlockHashesResult) Receive() ([]*chainhash.Hash, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal the result as an array of string-encoded hashes.
+	var hashStrings []string
+	err = json.Unmarshal(res, &hashStrings)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]*chainhash.Hash, 0, len(hashStrings))
+	for _, hashString := range hashStrings {
+		hash, err := chainhash.NewHashFromStr(hashString)
+		if err != nil {
+			return nil, err
+		}
+
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil
+}
+
+// GetDagBlockHashesAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetDagBlockHashes for the blocking version and more details.
+func (c *Client) GetDagBlockHashesAsync(height int32) FutureGetDagBlockHashesResult {
+	cmd := soterjson.NewGetDagBlockHashesCmd(height)
+	return c.sendCmd(cmd)
+}
+
+// GetDagBlockHashes returns the hashes of all blocks at the given height of
+// the dag, sorted by the dag ordering.
+func (c *Client) GetDagBlockHashes(height int32) ([]*chainhash.Hash, error) {
+	return c.GetDagBlockHashesAsync(height).Receive()
+}
+
+// FutureGetTransactionStatusResult is a future promise to deliver the result of
+// a GetTransactionStatusAsync RPC invocation (or an applicable error).
+type FutureGetTransactionStatusResult chan *response
+
+// Receive waits for the response promised by the future and returns the status
+// of the transaction.
+func (r FutureGetTransactionStatusResult) Receive() (string, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return "", err
+	}
+
+	var status string
+	err = json.Unmarshal(res, &status)
+	if err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// GetTransactionStatusAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetTransactionStatus for the blocking version and more details.
+func (c *Client) GetTransactionStatusAsync(txHash *chainhash.Hash) FutureGetTransactionStatusResult {
+	hash := ""
+	if txHash != nil {
+		hash = txHash.String()
+	}
+
+	cmd := soterjson.NewGetTransactionStatusCmd(hash)
+	return c.sendCmd(cmd)
+}
+
+// GetTransactionStatus returns whether the transaction is in a block of the
+// dag, in the memory pool, or unknown to the server, as one of
+// soterjson.TxStatusConfirmed, soterjson.TxStatusMempool or
+// soterjson.TxStatusUnknown.
+//
+// Looking up transactions that aren't in the memory pool requires the server
+// to have the transaction index enabled.
+func (c *Client) GetTransactionStatus(txHash *chainhash.Hash) (string, error) {
+	return c.GetTransactionStatusAsync(txHash).Receive()
+}
+
+// FutureGetTxConfirmationsResult is a future promise to deliver the result of
+// a GetTxConfirmationsAsync RPC invocation (or an applicable error).
+type FutureGetTxConfirmationsResult chan *response
+
+// Receive waits for the response promised by the future and returns the number
+// of blocks after the block containing the transaction in the dag ordering.
+func (r FutureGetTxConfirmationsResult) Receive() (int64, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return 0, err
+	}
+
+	var confirmations int64
+	err = json.Unmarshal(res, &confirmations)
+	if err != nil {
+		return 0, err
+	}
+	return confirmations, nil
+}
+
+// GetTxConfirmationsAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetTxConfirmations for the blocking version and more details.
+func (c *Client) GetTxConfirmationsAsync(txHash *chainhash.Hash) FutureGetTxConfirmationsResult {
+	hash := ""
+	if txHash != nil {
+		hash = txHash.String()
+	}
+
+	cmd := soterjson.NewGetTxConfirmationsCmd(hash)
+	return c.sendCmd(cmd)
+}
+
+// GetTxConfirmations returns how deeply the transaction is buried in the dag,
+// as the number of blocks that come after the block containing it in the dag
+// ordering.  -1 is returned when the transaction is still in the memory pool,
+// or is in a red block, which the ordering doesn't confirm.
+//
+// Looking up transactions that aren't in the memory pool requires the server
+// to have the transaction index enabled.
+func (c *Client) GetTxConfirmations(txHash *chainhash.Hash) (int64, error) {
+	return c.GetTxConfirmationsAsync(txHash).Receive()
+}
+
+// FutureGetPruneInfoResult is a future promise to deliver the result of a
+// GetPruneInfoAsync RPC invocation (or an applicable error).
+type FutureGetPruneInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// pruning state of the server.
+func (r FutureGetPruneInfoResult) Receive() (*soterjson.GetPruneInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var info soterjson.GetPruneInfoResult
+	if err := json.Unmarshal(res, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetPruneInfoAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetPruneInfo for the blocking version and more details.
+func (c *Client) GetPruneInfoAsync() FutureGetPruneInfoResult {
+	cmd := soterjson.NewGetPruneInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetPruneInfo returns whether the server prunes blocks, the height of the
+// lowest block it retains, and how many bytes of blocks could be reclaimed by
+// pruning at the highest height that keeps the parents of the remaining blocks.
+func (c *Client) GetPruneInfo() (*soterjson.GetPruneInfoResult, error) {
+	return c.GetPruneInfoAsync().Receive()
+}
+
+// FutureCheckPruneDagResult is a future promise to deliver the result of a
+// CheckPruneDagAsync RPC invocation (or an applicable error).
+type FutureCheckPruneDagResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// reason the prune was refused, if any.
+func (r FutureCheckPruneDagResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// CheckPruneDagAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See CheckPruneDag for the blocking version and more details.
+func (c *Client) CheckPruneDagAsync(height int32) FutureCheckPruneDagResult {
+	cmd := soterjson.NewCheckPruneDagCmd(height)
+	return c.sendCmd(cmd)
+}
+
+// CheckPruneDag asks the server whether the blocks below the given height
+// could be pruned.  Nothing is removed, and there's no call that prunes the
+// dag: the block database stores blocks in append-only flat files with no way
+// to remove one, and connecting a block re-reads the blocks of the whole dag
+// ordering to rebuild the utxo view.
+//
+// The server refuses the prune when it would remove a parent of a block that
+// remains, which happens when a block above the height references a parent
+// below it, or when a dag tip is below the height.  GetPruneInfo reports the
+// highest height that can be pruned at.
+func (c *Client) CheckPruneDag(height int32) error {
+	return c.CheckPruneDagAsync(height).Receive()
+}
+
+// FutureGetOrphanBlocksResult is a future promise to deliver the result of a
+// GetOrphanBlocksAsync RPC invocation (or an applicable error).
+type FutureGetOrphanBlocksResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// blocks in the orphan pool of the server.
+func (r FutureGetOrphanBlocksResult) Receive() (*soterjson.GetOrphanBlocksResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans soterjson.GetOrphanBlocksResult
+	if err := json.Unmarshal(res, &orphans); err != nil {
+		return nil, err
+	}
+	return &orphans, nil
+}
+
+// GetOrphanBlocksAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetOrphanBlocks for the blocking version and more details.
+func (c *Client) GetOrphanBlocksAsync() FutureGetOrphanBlocksResult {
+	cmd := soterjson.NewGetOrphanBlocksCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetOrphanBlocks returns the blocks in the orphan pool of the server, along
+// with the parents each is missing.  The server returns a limited number of
+// orphans, so the Total of the result may be larger than the number returned.
+func (c *Client) GetOrphanBlocks() (*soterjson.GetOrphanBlocksResult, error) {
+	return c.GetOrphanBlocksAsync().Receive()
+}
+
+// FutureClearOrphansResult is a future promise to deliver the result of a
+// ClearOrphansAsync RPC invocation (or an applicable error).
+type FutureClearOrphansResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// number of orphan blocks removed.
+func (r FutureClearOrphansResult) Receive() (int, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := json.Unmarshal(res, &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ClearOrphansAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See ClearOrphans for the blocking version and more details.
+func (c *Client) ClearOrphansAsync() FutureClearOrphansResult {
+	cmd := soterjson.NewClearOrphansCmd()
+	return c.sendCmd(cmd)
+}
+
+// ClearOrphans asks the server to remove all the blocks from its orphan pool,
+// and returns how many were removed.
+func (c *Client) ClearOrphans() (int, error) {
+	return c.ClearOrphansAsync().Receive()
+}
+
 // FutureRenderDagResult is a promise to deliver the result of a RenderDagAsync RPC invocation (or error).
 type FutureRenderDagResult chan *response
 
@@ -74,6 +677,43 @@ func (c *Client) RenderDag() (*soterjson.RenderDagResult, error) {
 	return c.RenderDagAsync().Receive()
 }
 
+// FutureGetDAGTipStatusResult is a promise to deliver the result of a
+// GetDAGTipStatusAsync RPC invocation (or an applicable error).
+type FutureGetDAGTipStatusResult chan *response
+
+// Receive waits for the response promised by the future and returns the tips
+// of the branches of blocks known to the server, along with their status.
+func (r FutureGetDAGTipStatusResult) Receive() ([]soterjson.GetDAGTipStatusResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var tips []soterjson.GetDAGTipStatusResult
+	if err := json.Unmarshal(res, &tips); err != nil {
+		return nil, err
+	}
+	return tips, nil
+}
+
+// GetDAGTipStatusAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetDAGTipStatus for the blocking version and more details.
+func (c *Client) GetDAGTipStatusAsync() FutureGetDAGTipStatusResult {
+	cmd := soterjson.NewGetDAGTipStatusCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetDAGTipStatus returns the tips of the branches of blocks known to the
+// server, sorted by height and then hash.  Each tip is classified as active,
+// valid-fork, valid-headers or invalid (see the soterjson.DAGTipStatus
+// constants), which helps diagnose branches that are stuck or invalid.
+func (c *Client) GetDAGTipStatus() ([]soterjson.GetDAGTipStatusResult, error) {
+	return c.GetDAGTipStatusAsync().Receive()
+}
+
 // FutureGetDAGColoringResult is a promise to deliver the result of a GetDAGColoringAsync RPC invocation (or error).
 type FutureGetDAGColoringResult chan *response
 
@@ -100,4 +740,86 @@ func (c *Client) GetDAGColoringAsync() FutureGetDAGColoringResult {
 // GetDAGColoring returns the coloring of the block DAG
 func (c *Client) GetDAGColoring() ([]*soterjson.GetDAGColoringResult, error) {
 	return c.GetDAGColoringAsync().Receive()
-}
\ No newline at end of file
+}
+
+// FutureGetBlockParentsResult is a promise to deliver the result of a
+// GetBlockParentsAsync RPC invocation (or an applicable error).
+type FutureGetBlockParentsResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// hashes of the dag parents of the requested block.
+func (r FutureGetBlockParentsResult) Receive() ([]*chainhash.Hash, error) {
+	bh, err := FutureGetBlockHeaderVerboseResult(r).Receive()
+	if err != nil {
+		return nil, err
+	}
+
+	parents := make([]*chainhash.Hash, 0, len(bh.Parents))
+	for _, hashStr := range bh.Parents {
+		hash, err := chainhash.NewHashFromStr(hashStr)
+		if err != nil {
+			return nil, err
+		}
+		parents = append(parents, hash)
+	}
+
+	return parents, nil
+}
+
+// GetBlockParentsAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetBlockParents for the blocking version and more details.
+func (c *Client) GetBlockParentsAsync(blockHash *chainhash.Hash) FutureGetBlockParentsResult {
+	hash := ""
+	if blockHash != nil {
+		hash = blockHash.String()
+	}
+
+	cmd := soterjson.NewGetBlockHeaderCmd(hash, soterjson.Bool(true))
+	return c.sendCmd(cmd)
+}
+
+// GetBlockParents returns the hashes of all dag parents of the block with the
+// given hash.  The genesis block has no parents.
+func (c *Client) GetBlockParents(blockHash *chainhash.Hash) ([]*chainhash.Hash, error) {
+	return c.GetBlockParentsAsync(blockHash).Receive()
+}
+
+// FutureDecodeDagHeaderResult is a future promise to deliver the result of a
+// DecodeDagHeaderAsync RPC invocation (or an applicable error).
+type FutureDecodeDagHeaderResult chan *response
+
+// Receive waits for the response promised by the future and returns
+// information about the decoded dag block header.
+func (r FutureDecodeDagHeaderResult) Receive() (*soterjson.DecodeDagHeaderResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var header soterjson.DecodeDagHeaderResult
+	if err := json.Unmarshal(res, &header); err != nil {
+		return nil, err
+	}
+	return &header, nil
+}
+
+// DecodeDagHeaderAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See DecodeDagHeader for the blocking version and more details.
+func (c *Client) DecodeDagHeaderAsync(hexHeader string) FutureDecodeDagHeaderResult {
+	cmd := soterjson.NewDecodeDagHeaderCmd(hexHeader)
+	return c.sendCmd(cmd)
+}
+
+// DecodeDagHeader returns the fields of a serialized, hex-encoded dag block
+// header, which is the block header followed by the parents sub-header, along
+// with the hash of the block.  The hex of a serialized block can be passed too,
+// since it starts with the dag block header.
+func (c *Client) DecodeDagHeader(hexHeader string) (*soterjson.DecodeDagHeaderResult, error) {
+	return c.DecodeDagHeaderAsync(hexHeader).Receive()
+}
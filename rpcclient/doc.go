@@ -54,6 +54,15 @@ the Receive method on the returned instance will either return the result
 immediately if it has already arrived, or block until it has.  This is useful
 since it provides the caller with greater control over concurrency.
 
+Batch Requests
+
+A client created with NewBatch queues the commands issued through its
+asynchronous API instead of sending them right away.  Calling Send issues every
+queued command to the server in a single HTTP POST request, and resolves the
+returned futures with their individual replies.  An error for one command in
+the batch is only returned by that command's future.  This is useful when
+issuing many independent commands, since it avoids a round trip per command.
+
 Notifications
 
 The first important part of notifications is to realize that they will only
@@ -83,12 +92,26 @@ additional RPCs must be issued an a completely decoupled manner.
 Automatic Reconnection
 
 By default, when running in websockets mode, this client will automatically
-keep trying to reconnect to the RPC server should the connection be lost.  There
-is a back-off in between each connection attempt until it reaches one try per
-minute.  Once a connection is re-established, all previously registered
-notifications are automatically re-registered and any in-flight commands are
-re-issued.  This means from the caller's perspective, the request simply takes
-longer to complete.
+keep trying to reconnect to the RPC server should the connection be lost, for
+example when the server restarts.  There is a back-off in between each
+connection attempt until it reaches one try per minute.  The back-off can be
+tuned with the ReconnectInterval and MaxReconnectInterval fields of the
+connection config.  Once a connection is re-established, all previously
+registered notifications are automatically re-registered and any in-flight
+commands are re-issued.  This means from the caller's perspective, the request
+simply takes longer to complete.  The OnClientReconnected notification handler
+is invoked once this is done, so that callers can re-establish any state the
+client doesn't track, such as transaction filters loaded with LoadTxFilter.
+
+Notifications are delivered at most once across a reconnect.  Any events that
+happen on the server while the client is disconnected, or before the
+notifications are re-registered, are not sent to the client, so callers which
+can't miss events should resynchronize from OnClientReconnected, e.g. by
+comparing the dag tips with the last ones they saw.  In-flight commands, on the
+other hand, are re-issued at least once: a command which the server processed
+before the connection was lost, but whose reply was never received, is sent
+again.  Commands which aren't idempotent, such as generate, may therefore take
+effect twice.
 
 The caller may invoke the Shutdown method on the client to force the client
 to cease reconnect attempts and return ErrClientShutdown for all outstanding
@@ -127,7 +150,11 @@ There are 3 categories of errors that will be returned throughout this package:
     networks
 
 The first category of errors are typically one of ErrInvalidAuth,
-ErrInvalidEndpoint, ErrClientDisconnect, or ErrClientShutdown.
+ErrInvalidEndpoint, ErrClientDisconnect, or ErrClientShutdown.  Failures to
+reach the server, or replies that aren't JSON-RPC responses, are returned as a
+*TransportError, which carries the HTTP status code of the reply, if any.
+Requests that don't complete within their timeout return a *TimeoutError, such
+as ErrRequestTimeout.
 
 NOTE: The ErrClientDisconnect will not be returned unless the
 DisableAutoReconnect flag is set since the client automatically handles
@@ -138,12 +165,15 @@ the type can vary, but usually will be best handled by simply showing/logging
 it.
 
 The third category of errors, that is errors returned by the server, can be
-detected by type asserting the error in a *soterjson.RPCError.  For example, to
-detect if a command is unimplemented by the remote RPC server:
+detected by type asserting the error in a *RPCError, which is the same type as
+*soterjson.RPCError and carries the JSON-RPC error code of the reply.  The
+errors can also be detected with errors.As, including when they're wrapped.  For
+example, to detect if a command is unimplemented by the remote RPC server:
 
   amount, err := client.GetBalance("")
   if err != nil {
-  	if jerr, ok := err.(*soterjson.RPCError); ok {
+  	var jerr *rpcclient.RPCError
+  	if errors.As(err, &jerr) {
   		switch jerr.Code {
   		case soterjson.ErrRPCUnimplemented:
   			// Handle not implemented error
@@ -0,0 +1,90 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"fmt"
+
+	"github.com/soteria-dag/soterd/soterjson"
+)
+
+// RPCError is an error returned by the RPC server, carrying the JSON-RPC error
+// code and message of its reply.  It's the same type as soterjson.RPCError, so
+// errors returned by the server can be detected with a type assertion to either
+// name, or with errors.As:
+//
+//	var rpcErr *rpcclient.RPCError
+//	if errors.As(err, &rpcErr) && rpcErr.Code == soterjson.ErrRPCBlockNotFound {
+//		// Handle the missing block
+//	}
+type RPCError = soterjson.RPCError
+
+// TransportError is an error that happened while talking to the RPC server,
+// when no JSON-RPC reply was received for a request.  This happens when the
+// server can't be reached, the connection fails, or the server replies with
+// something other than a JSON-RPC response, like an HTTP error page.  The
+// request may or may not have been processed by the server.  A request
+// cancelled along with its context fails with the context's error instead.
+type TransportError struct {
+	// StatusCode is the HTTP status code of the reply, or 0 when there was
+	// no HTTP reply.
+	StatusCode int
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error returns the message of the underlying error.
+//
+// This is part of the error interface.
+func (e *TransportError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error, so that it can be examined with
+// errors.Is and errors.As.
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// RequestTooLargeError is an error returned for a request that wasn't sent,
+// because it's larger than the MaxRequestSize of the client's ConnConfig.
+type RequestTooLargeError struct {
+	// Method is the method of the request, or "batch" for a batch.
+	Method string
+
+	// Size is the size of the marshalled request in bytes, and MaxSize the
+	// limit it exceeds.
+	Size    int
+	MaxSize int
+}
+
+// Error returns a message with the size of the request and the limit.
+//
+// This is part of the error interface.
+func (e *RequestTooLargeError) Error() string {
+	return fmt.Sprintf("%s request of %d bytes exceeds the maximum "+
+		"request size of %d bytes", e.Method, e.Size, e.MaxSize)
+}
+
+// TimeoutError is an error to describe the condition where a request, or an
+// operation waiting on the RPC server, didn't complete within its timeout.
+// ErrRequestTimeout and ErrGenerateTimeout are TimeoutErrors.
+type TimeoutError struct {
+	msg string
+}
+
+// Error returns the message of the timeout.
+//
+// This is part of the error interface.
+func (e *TimeoutError) Error() string {
+	return e.msg
+}
+
+// Timeout returns true, like the Timeout method of net.Error, so that callers
+// can detect timeouts with an interface assertion too.
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+	"github.com/soteria-dag/soterd/soterjson"
+)
+
+// newPostClient returns an HTTP POST client for the server at the url.
+func newPostClient(t *testing.T, url string) *Client {
+	client, err := New(&ConnConfig{
+		Host:         strings.TrimPrefix(url, "http://"),
+		User:         "user",
+		Pass:         "pass",
+		DisableTLS:   true,
+		HTTPPostMode: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return client
+}
+
+// TestRPCError tests that an error returned by the server surfaces as an
+// RPCError carrying the code of the reply.
+func TestRPCError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+
+		var req struct {
+			ID uint64 `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Write([]byte(fmt.Sprintf(`{"result":null,"error":{"code":%d,`+
+			`"message":"Block not found"},"id":%d}`,
+			soterjson.ErrRPCBlockNotFound, req.ID)))
+	}))
+	defer server.Close()
+
+	client := newPostClient(t, server.URL)
+	defer client.Shutdown()
+
+	_, err := client.GetBlock(&chainhash.Hash{})
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("GetBlock of a missing block returned %v (%T), want "+
+			"an RPCError", err, err)
+	}
+	if rpcErr.Code != soterjson.ErrRPCBlockNotFound ||
+		rpcErr.Message != "Block not found" {
+
+		t.Fatalf("GetBlock of a missing block returned code %d and "+
+			"message %q, want %d and %q", rpcErr.Code, rpcErr.Message,
+			soterjson.ErrRPCBlockNotFound, "Block not found")
+	}
+
+	// Errors from the server aren't transport errors.
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		t.Fatalf("GetBlock of a missing block returned a TransportError")
+	}
+}
+
+// TestTransportError tests that failing to reach the server, and replies that
+// aren't JSON-RPC responses, surface as TransportErrors.
+func TestTransportError(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		statusCode int
+	}{
+		{
+			name:       "dead server",
+			statusCode: 0,
+		},
+		{
+			name: "error page",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "<html>bad gateway</html>",
+					http.StatusBadGateway)
+			},
+			statusCode: http.StatusBadGateway,
+		},
+	}
+
+	for _, test := range tests {
+		// The dead server is closed before the request is sent.
+		server := httptest.NewServer(test.handler)
+		if test.handler == nil {
+			server.Close()
+		}
+		client := newPostClient(t, server.URL)
+
+		_, err := client.GetBlockCount()
+		client.Shutdown()
+		server.Close()
+
+		var transportErr *TransportError
+		if !errors.As(err, &transportErr) {
+			t.Errorf("%s: GetBlockCount returned %v (%T), want a "+
+				"TransportError", test.name, err, err)
+			continue
+		}
+		if transportErr.StatusCode != test.statusCode {
+			t.Errorf("%s: TransportError has status code %d, want %d",
+				test.name, transportErr.StatusCode, test.statusCode)
+		}
+		if transportErr.Unwrap() == nil {
+			t.Errorf("%s: TransportError has no underlying error",
+				test.name)
+		}
+
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) {
+			t.Errorf("%s: GetBlockCount returned an RPCError",
+				test.name)
+		}
+	}
+}
+
+// TestTimeoutError tests that the timeout errors of the package are
+// TimeoutErrors.
+func TestTimeoutError(t *testing.T) {
+	for _, err := range []error{ErrRequestTimeout, ErrGenerateTimeout} {
+		var timeoutErr *TimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Errorf("%v isn't a TimeoutError", err)
+			continue
+		}
+		if !timeoutErr.Timeout() {
+			t.Errorf("%v isn't a timeout", err)
+		}
+	}
+
+	// Wrapped timeouts are still detected.
+	err := fmt.Errorf("unable to get block count: %w", ErrRequestTimeout)
+	if !errors.Is(err, ErrRequestTimeout) {
+		t.Errorf("wrapped %v isn't ErrRequestTimeout", err)
+	}
+}
@@ -472,3 +472,47 @@ func (c *Client) VersionAsync() FutureVersionResult {
 func (c *Client) Version() (map[string]soterjson.VersionResult, error) {
 	return c.VersionAsync().Receive()
 }
+
+// FutureGetRelayPolicyResult is a future promise to deliver the result of a
+// GetRelayPolicyAsync RPC invocation (or an applicable error).
+type FutureGetRelayPolicyResult chan *response
+
+// Receive waits for the response promised by the future and returns the relay
+// policy of the server.
+//
+// NOTE: This is a soterd extension.
+func (r FutureGetRelayPolicyResult) Receive() (*soterjson.GetRelayPolicyResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy soterjson.GetRelayPolicyResult
+	err = json.Unmarshal(res, &policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// GetRelayPolicyAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetRelayPolicy for the blocking version and more details.
+//
+// NOTE: This is a soterd extension.
+func (c *Client) GetRelayPolicyAsync() FutureGetRelayPolicyResult {
+	cmd := soterjson.NewGetRelayPolicyCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetRelayPolicy returns the policy the server accepts transactions into its
+// memory pool and relays them with, such as whether non-standard transactions
+// are accepted and how much data OP_RETURN outputs may carry.
+//
+// NOTE: This is a soterd extension.
+func (c *Client) GetRelayPolicy() (*soterjson.GetRelayPolicyResult, error) {
+	return c.GetRelayPolicyAsync().Receive()
+}
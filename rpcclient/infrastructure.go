@@ -8,6 +8,7 @@ package rpcclient
 import (
 	"bytes"
 	"container/list"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
@@ -70,6 +71,29 @@ var (
 	// client having already connected to the RPC server.
 	ErrClientAlreadyConnected = errors.New("websocket client has already " +
 		"connected")
+
+	// ErrNotBatchClient is an error to describe the condition of calling
+	// a Client method intended for a batch client when the client was not
+	// created with NewBatch.
+	ErrNotBatchClient = errors.New("client is not configured for batch " +
+		"requests")
+
+	// ErrRequestTimeout is an error to describe the condition where a
+	// request was abandoned because no reply was received within the
+	// RequestTimeout of the client.
+	ErrRequestTimeout error = &TimeoutError{msg: "no reply was received " +
+		"within the request timeout"}
+
+	// ErrBatchNoReply is an error to describe the condition where the RPC
+	// server replied to a batch request, but the reply didn't contain a
+	// response for one of the requests in the batch.
+	ErrBatchNoReply = errors.New("no reply for request in batch")
+
+	// ErrUnixSocketHTTPOnly is an error to describe the condition where a
+	// websocket client is configured to connect to a Unix socket, which is
+	// only supported in HTTP POST mode.
+	ErrUnixSocketHTTPOnly = errors.New("unix socket connections are " +
+		"only supported in HTTP POST mode")
 )
 
 const (
@@ -81,9 +105,14 @@ const (
 	// channel can queue before blocking.
 	sendPostBufferSize = 100
 
-	// connectionRetryInterval is the amount of time to wait in between
-	// retries when automatically reconnecting to an RPC server.
+	// connectionRetryInterval is the default amount of time to wait in
+	// between retries when automatically reconnecting to an RPC server.
 	connectionRetryInterval = time.Second * 5
+
+	// maxConnectionRetryInterval is the default maximum amount of time to
+	// wait in between retries when automatically reconnecting to an RPC
+	// server.
+	maxConnectionRetryInterval = time.Minute
 )
 
 // sendPostDetails houses an HTTP POST request to send to an RPC server as well
@@ -102,6 +131,15 @@ type jsonRequest struct {
 	cmd            interface{}
 	marshalledJSON []byte
 	responseChan   chan *response
+
+	// batch indicates that marshalledJSON holds a JSON array of requests,
+	// and that the raw reply should be delivered to responseChan without
+	// being interpreted.
+	batch bool
+
+	// ctx is the context the request was sent with, if any.  HTTP POST
+	// requests are cancelled when it's done.
+	ctx context.Context
 }
 
 // Client represents a Soter RPC client which allows easy access to the
@@ -116,6 +154,10 @@ type jsonRequest struct {
 // result of the invocation at some future time.  Invoking the Receive method on
 // the returned future will block until the result is available if it's not
 // already.
+//
+// A Client is safe for concurrent use by multiple goroutines.  Each request is
+// given a unique id, and its reply is only ever delivered to the future
+// returned for that request.
 type Client struct {
 	id uint64 // atomic, so must stay 64-bit aligned
 
@@ -145,6 +187,12 @@ type Client struct {
 	requestMap  map[uint64]*list.Element
 	requestList *list.List
 
+	// batch indicates the client was created with NewBatch, in which case
+	// commands are queued in batchList until Send is called.
+	batch     bool
+	batchLock sync.Mutex
+	batchList *list.List
+
 	// Notifications.
 	ntfnHandlers  *NotificationHandlers
 	ntfnStateLock sync.Mutex
@@ -242,6 +290,9 @@ func (c *Client) trackRegisteredNtfns(cmd interface{}) {
 	case *soterjson.NotifyBlocksCmd:
 		c.ntfnState.notifyBlocks = true
 
+	case *soterjson.NotifyDagTipsCmd:
+		c.ntfnState.notifyDagTips = true
+
 	case *soterjson.NotifyNewTransactionsCmd:
 		if bcmd.Verbose != nil && *bcmd.Verbose {
 			c.ntfnState.notifyNewTxVerbose = true
@@ -399,6 +450,14 @@ func (c *Client) shouldLogReadError(err error) bool {
 // wsInHandler handles all incoming messages for the websocket connection
 // associated with the client.  It must be run as a goroutine.
 func (c *Client) wsInHandler() {
+	// Each pong from the server extends the read deadline.
+	if c.config.PingInterval > 0 {
+		pongDeadline := 2 * c.config.PingInterval
+		c.wsConn.SetPongHandler(func(string) error {
+			return c.wsConn.SetReadDeadline(time.Now().Add(pongDeadline))
+		})
+	}
+
 out:
 	for {
 		// Break out of the loop once the shutdown channel has been
@@ -410,6 +469,13 @@ out:
 		default:
 		}
 
+		// When pinging the server, a connection that hasn't received
+		// anything for two ping intervals is treated as lost.
+		if c.config.PingInterval > 0 {
+			c.wsConn.SetReadDeadline(time.Now().Add(
+				2 * c.config.PingInterval))
+		}
+
 		_, msg, err := c.wsConn.ReadMessage()
 		if err != nil {
 			// Log the error if it's not due to disconnecting.
@@ -442,6 +508,15 @@ func (c *Client) disconnectChan() <-chan struct{} {
 // uses a buffered channel to serialize output messages while allowing the
 // sender to continue running asynchronously.  It must be run as a goroutine.
 func (c *Client) wsOutHandler() {
+	// Ping the server periodically when configured to, so that a server
+	// which stopped responding is noticed by wsInHandler.
+	var pingChan <-chan time.Time
+	if c.config.PingInterval > 0 {
+		ticker := time.NewTicker(c.config.PingInterval)
+		defer ticker.Stop()
+		pingChan = ticker.C
+	}
+
 out:
 	for {
 		// Send any messages ready for send until the client is
@@ -454,6 +529,15 @@ out:
 				break out
 			}
 
+		case <-pingChan:
+			deadline := time.Now().Add(c.config.PingInterval)
+			err := c.wsConn.WriteControl(websocket.PingMessage, nil,
+				deadline)
+			if err != nil {
+				c.Disconnect()
+				break out
+			}
+
 		case <-c.disconnectChan():
 			break out
 		}
@@ -514,6 +598,14 @@ func (c *Client) reregisterNtfns() error {
 		}
 	}
 
+	// Reregister notifydagtips if needed.
+	if stateCopy.notifyDagTips {
+		log.Debugf("Reregistering [notifydagtips]")
+		if err := c.NotifyDagTips(); err != nil {
+			return err
+		}
+	}
+
 	// Reregister notifynewtransactions if needed.
 	if stateCopy.notifyNewTx || stateCopy.notifyNewTxVerbose {
 		log.Debugf("Reregistering [notifynewtransactions] (verbose=%v)",
@@ -563,14 +655,15 @@ var ignoreResends = map[string]struct{}{
 
 // resendRequests resends any requests that had not completed when the client
 // disconnected.  It is intended to be called once the client has reconnected as
-// a separate goroutine.
-func (c *Client) resendRequests() {
+// a separate goroutine.  It returns whether the notification state was
+// re-established and all of the requests were resent.
+func (c *Client) resendRequests() bool {
 	// Set the notification state back up.  If anything goes wrong,
 	// disconnect the client.
 	if err := c.reregisterNtfns(); err != nil {
 		log.Warnf("Unable to re-establish notification state: %v", err)
 		c.Disconnect()
-		return
+		return false
 	}
 
 	// Since it's possible to block on send and more requests might be
@@ -600,13 +693,15 @@ func (c *Client) resendRequests() {
 		// Stop resending commands if the client disconnected again
 		// since the next reconnect will handle them.
 		if c.Disconnected() {
-			return
+			return false
 		}
 
 		log.Tracef("Sending command [%s] with id %d", jReq.method,
 			jReq.id)
 		c.sendMessage(jReq.marshalledJSON)
 	}
+
+	return true
 }
 
 // wsReconnectHandler listens for client disconnects and automatically tries
@@ -644,13 +739,9 @@ out:
 					c.config.Host, err)
 
 				// Scale the retry interval by the number of
-				// retries so there is a backoff up to a max
-				// of 1 minute.
-				scaledInterval := connectionRetryInterval.Nanoseconds() * c.retryCount
-				scaledDuration := time.Duration(scaledInterval)
-				if scaledDuration > time.Minute {
-					scaledDuration = time.Minute
-				}
+				// retries so there is a backoff up to the
+				// configured max.
+				scaledDuration := c.config.retryBackoff(c.retryCount)
 				log.Infof("Retrying connection to %s in "+
 					"%s", c.config.Host, scaledDuration)
 				time.Sleep(scaledDuration)
@@ -661,11 +752,11 @@ out:
 				c.config.Host)
 
 			// Reset the connection state and signal the reconnect
-			// has happened.
+			// has happened.  The connection is swapped under the
+			// mutex since doDisconnect reads it concurrently.
+			c.mtx.Lock()
 			c.wsConn = wsConn
 			c.retryCount = 0
-
-			c.mtx.Lock()
 			c.disconnect = make(chan struct{})
 			c.disconnected = false
 			c.mtx.Unlock()
@@ -676,7 +767,15 @@ out:
 
 			// Reissue pending requests in another goroutine since
 			// the send can block.
-			go c.resendRequests()
+			go func() {
+				if !c.resendRequests() {
+					return
+				}
+				if c.ntfnHandlers != nil &&
+					c.ntfnHandlers.OnClientReconnected != nil {
+					c.ntfnHandlers.OnClientReconnected()
+				}
+			}()
 
 			// Break out of the reconnect loop back to wait for
 			// disconnect again.
@@ -687,6 +786,18 @@ out:
 	log.Tracef("RPC client reconnect handler done for %s", c.config.Host)
 }
 
+// postError returns the error for an HTTP POST request which failed before
+// its reply was read.  A request cancelled along with its context fails with
+// the context's error, the same as a request abandoned before it's sent, rather
+// than with the transport error the cancellation caused.
+func postError(jReq *jsonRequest, statusCode int, err error) error {
+	if jReq.ctx != nil && jReq.ctx.Err() != nil {
+		return jReq.ctx.Err()
+	}
+
+	return &TransportError{StatusCode: statusCode, Err: err}
+}
+
 // handleSendPostMessage handles performing the passed HTTP request, reading the
 // result, unmarshalling it, and delivering the unmarshalled result to the
 // provided response channel.
@@ -695,29 +806,62 @@ func (c *Client) handleSendPostMessage(details *sendPostDetails) {
 	log.Tracef("Sending command [%s] with id %d", jReq.method, jReq.id)
 	httpResponse, err := c.httpClient.Do(details.httpRequest)
 	if err != nil {
-		jReq.responseChan <- &response{err: err}
+		jReq.responseChan <- &response{err: postError(jReq, 0, err)}
 		return
 	}
 
+	// transportError delivers a TransportError for the reply.
+	transportError := func(err error) {
+		jReq.responseChan <- &response{err: &TransportError{
+			StatusCode: httpResponse.StatusCode,
+			Err:        err,
+		}}
+	}
+
 	// Read the raw bytes and close the response.
 	respBytes, err := ioutil.ReadAll(httpResponse.Body)
 	httpResponse.Body.Close()
 	if err != nil {
 		err = fmt.Errorf("error reading json reply: %v", err)
-		jReq.responseChan <- &response{err: err}
+		jReq.responseChan <- &response{
+			err: postError(jReq, httpResponse.StatusCode, err),
+		}
+		return
+	}
+
+	// Batch replies are an array of responses, which are matched up with
+	// the requests of the batch by Send.
+	if jReq.batch {
+		if httpResponse.StatusCode != http.StatusOK {
+			transportError(fmt.Errorf("status code: %d, response: %q",
+				httpResponse.StatusCode, string(respBytes)))
+			return
+		}
+		jReq.responseChan <- &response{result: respBytes}
 		return
 	}
 
 	// Try to unmarshal the response as a regular JSON-RPC response.
-	var resp rawResponse
+	var resp struct {
+		ID *float64 `json:"id"`
+		rawResponse
+	}
 	err = json.Unmarshal(respBytes, &resp)
 	if err != nil {
 		// When the response itself isn't a valid JSON-RPC response
 		// return an error which includes the HTTP status code and raw
 		// response bytes.
-		err = fmt.Errorf("status code: %d, response: %q",
-			httpResponse.StatusCode, string(respBytes))
-		jReq.responseChan <- &response{err: err}
+		transportError(fmt.Errorf("status code: %d, response: %q",
+			httpResponse.StatusCode, string(respBytes)))
+		return
+	}
+
+	// Make sure the reply is for this request, so a misbehaving server or
+	// proxy can't hand one caller the result meant for another.  Servers
+	// may reply with a null id when they couldn't parse the request.
+	if resp.ID != nil && *resp.ID != float64(jReq.id) {
+		transportError(fmt.Errorf("response id %v doesn't match "+
+			"request id %d", *resp.ID, jReq.id))
 		return
 	}
 
@@ -771,12 +915,19 @@ func (c *Client) sendPostRequest(httpReq *http.Request, jReq *jsonRequest) {
 	select {
 	case <-c.shutdown:
 		jReq.responseChan <- &response{result: nil, err: ErrClientShutdown}
+		return
 	default:
 	}
 
-	c.sendPostChan <- &sendPostDetails{
+	// The send handler stops reading the channel on shutdown, so don't block
+	// forever if the client shuts down while the channel is full.
+	select {
+	case c.sendPostChan <- &sendPostDetails{
 		jsonRequest: jReq,
 		httpRequest: httpReq,
+	}:
+	case <-c.shutdown:
+		jReq.responseChan <- &response{result: nil, err: ErrClientShutdown}
 	}
 }
 
@@ -807,17 +958,25 @@ func receiveFuture(f chan *response) ([]byte, error) {
 func (c *Client) sendPost(jReq *jsonRequest) {
 	// Generate a request to the configured RPC server.
 	protocol := "http"
-	if !c.config.DisableTLS {
+	if !c.config.DisableTLS && c.config.UnixSocket == "" {
 		protocol = "https"
 	}
-	url := protocol + "://" + c.config.Host
+	host := c.config.Host
+	if c.config.UnixSocket != "" {
+		// The HTTP client dials the socket, so the host only names the
+		// server in the request.
+		host = "localhost"
+	}
+	url := protocol + "://" + host
 	bodyReader := bytes.NewReader(jReq.marshalledJSON)
 	httpReq, err := http.NewRequest("POST", url, bodyReader)
 	if err != nil {
 		jReq.responseChan <- &response{result: nil, err: err}
 		return
 	}
-	httpReq.Close = true
+	if jReq.ctx != nil {
+		httpReq = httpReq.WithContext(jReq.ctx)
+	}
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	// Configure basic access authorization.
@@ -866,6 +1025,17 @@ func (c *Client) sendRequest(jReq *jsonRequest) {
 // future.  It handles both websocket and HTTP POST mode depending on the
 // configuration of the client.
 func (c *Client) sendCmd(cmd interface{}) chan *response {
+	return c.sendCmdCtx(context.Background(), cmd)
+}
+
+// sendCmdCtx is like sendCmd, but the request is abandoned when the context is
+// done before the reply is received.  In that case the context's error is
+// delivered on the returned channel (see CallCtx).
+func (c *Client) sendCmdCtx(ctx context.Context, cmd interface{}) chan *response {
+	if err := ctx.Err(); err != nil {
+		return newFutureError(err)
+	}
+
 	// Get the method associated with the command.
 	method, err := soterjson.CmdMethod(cmd)
 	if err != nil {
@@ -879,18 +1049,47 @@ func (c *Client) sendCmd(cmd interface{}) chan *response {
 		return newFutureError(err)
 	}
 
-	// Generate the request and send it along with a channel to respond on.
-	responseChan := make(chan *response, 1)
-	jReq := &jsonRequest{
+	return c.sendJSONRequestCtx(ctx, &jsonRequest{
 		id:             id,
 		method:         method,
 		cmd:            cmd,
 		marshalledJSON: marshalledJSON,
-		responseChan:   responseChan,
+	})
+}
+
+// sendJSONRequestCtx sends the marshalled request to the server, the same way
+// for registered commands and raw requests, and returns a response channel on
+// which the reply will be delivered.  The request is abandoned when the
+// context is done before the reply is received (see sendCmdCtx).
+func (c *Client) sendJSONRequestCtx(ctx context.Context, jReq *jsonRequest) chan *response {
+	err := c.config.checkRequestSize(jReq.method, len(jReq.marshalledJSON))
+	if err != nil {
+		return newFutureError(err)
 	}
-	c.sendRequest(jReq)
 
-	return responseChan
+	// Send the request along with a channel to respond on.
+	reqCtx, cancel := c.withRequestTimeout(ctx)
+	responseChan := make(chan *response, 1)
+	jReq.responseChan = responseChan
+	if reqCtx.Done() != nil {
+		jReq.ctx = reqCtx
+	}
+	responseChan = c.logRequest(jReq)
+
+	// Batch clients queue the request until Send is called.
+	if c.batch {
+		c.batchLock.Lock()
+		c.batchList.PushBack(jReq)
+		c.batchLock.Unlock()
+	} else {
+		c.sendRequest(jReq)
+	}
+
+	responseChan = c.abandonOnDone(reqCtx, jReq.id, responseChan)
+	if cancel == nil {
+		return responseChan
+	}
+	return timeoutResponse(ctx, reqCtx, cancel, responseChan)
 }
 
 // sendCmdAndWait sends the passed command to the associated server, waits
@@ -1056,6 +1255,12 @@ type ConnConfig struct {
 	// to.
 	Host string
 
+	// UnixSocket is the path of a Unix domain socket the RPC server
+	// listens on.  When it is set, requests are sent over the socket, and
+	// Host, DisableTLS, the TLS certificates and the proxy settings have
+	// no effect.  It is only supported in HTTP POST mode.
+	UnixSocket string
+
 	// Endpoint is the websocket endpoint on the RPC server.  This is
 	// typically "ws".
 	Endpoint string
@@ -1077,6 +1282,21 @@ type ConnConfig struct {
 	// is true.
 	Certificates []byte
 
+	// ClientCert and ClientKey are the bytes for a PEM-encoded certificate
+	// chain and private key, which the client presents to RPC servers that
+	// require TLS client authentication.  ClientCertFile and ClientKeyFile
+	// may be set to the paths of PEM-encoded files instead.  A certificate
+	// and a key must be set together, and they have no effect if the
+	// DisableTLS parameter is true.
+	ClientCert []byte
+	ClientKey  []byte
+
+	// ClientCertFile and ClientKeyFile are the paths to files with the
+	// PEM-encoded client certificate chain and private key.  They're read
+	// each time the client connects.  See ClientCert and ClientKey.
+	ClientCertFile string
+	ClientKeyFile  string
+
 	// Proxy specifies to connect through a SOCKS 5 proxy server.  It may
 	// be an empty string if a proxy is not required.
 	Proxy string
@@ -1095,6 +1315,16 @@ type ConnConfig struct {
 	// try to reconnect to the server when it has been disconnected.
 	DisableAutoReconnect bool
 
+	// ReconnectInterval is the amount of time to wait after the first
+	// failed connection attempt.  The wait is scaled by the number of
+	// failed attempts for each attempt after that.  When zero, a default
+	// of 5 seconds is used.
+	ReconnectInterval time.Duration
+
+	// MaxReconnectInterval is the maximum amount of time to wait in between
+	// connection attempts.  When zero, a default of 1 minute is used.
+	MaxReconnectInterval time.Duration
+
 	// DisableConnectOnNew specifies that a websocket client connection
 	// should not be tried when creating the client with New.  Instead, the
 	// client is created and returned unconnected, and Connect must be
@@ -1109,22 +1339,197 @@ type ConnConfig struct {
 	// flag can be set to true to use basic HTTP POST requests instead.
 	HTTPPostMode bool
 
+	// MaxIdleConnsPerHost is the maximum number of idle connections an
+	// HTTP POST client keeps open to the server, for reuse by later
+	// requests.  Reusing connections saves setting up a new connection,
+	// and its TLS session, for every request.  When zero, connections
+	// aren't reused, and each request is sent over a new connection that's
+	// closed once the reply arrives.
+	MaxIdleConnsPerHost int
+
+	// MaxIdleConns is the maximum number of idle connections an HTTP POST
+	// client keeps open in total.  When zero, it's only limited by
+	// MaxIdleConnsPerHost.  It has no effect unless MaxIdleConnsPerHost is
+	// set.
+	MaxIdleConns int
+
+	// IdleConnTimeout is how long an idle connection is kept open before
+	// the client closes it.  When zero, idle connections are kept open
+	// until the server closes them.  It has no effect unless
+	// MaxIdleConnsPerHost is set.
+	IdleConnTimeout time.Duration
+
+	// DisableCompression specifies that an HTTP POST client shouldn't ask
+	// the server to compress its replies.  By default, requests carry an
+	// Accept-Encoding: gzip header, and gzip-compressed replies are
+	// decompressed transparently, which saves bandwidth for large replies
+	// like full blocks.  Servers that don't support compression reply
+	// uncompressed, which works the same way.
+	DisableCompression bool
+
 	// EnableBCInfoHacks is an option provided to enable compatibility hacks
 	// when connecting to blockchain.info RPC server
 	EnableBCInfoHacks bool
+
+	// Logger is an optional logger for the requests sent by the client,
+	// and the responses to them.  When nil, requests aren't logged.
+	Logger RequestLogger
+
+	// RedactParams is an optional function for redacting sensitive params
+	// before requests are passed to the Logger.  When nil,
+	// DefaultRedactParams is used.
+	RedactParams RedactParamsFunc
+
+	// RequestTimeout is how long a request waits for its reply, and how
+	// long a websocket connection waits for the server's handshake.  A
+	// request without a reply in time is abandoned the same way as when
+	// the context passed to CallCtx is done, and ErrRequestTimeout is
+	// returned.  When zero, requests wait for their reply indefinitely.
+	RequestTimeout time.Duration
+
+	// PingInterval is how often a websocket client pings the server to
+	// check that the connection is alive.  When nothing is received from
+	// the server for two intervals, the connection is treated as lost and
+	// closed.  When zero, the server isn't pinged.
+	PingInterval time.Duration
+
+	// MaxRequestSize is the maximum size in bytes of a marshalled request,
+	// like a submitblock request carrying a large block.  A larger request
+	// isn't sent, and fails with a *RequestTooLargeError instead of being
+	// cut off or rejected by the server or a proxy in front of it.  For
+	// batch clients, the limit applies to the whole batch.  When zero,
+	// requests aren't limited.
+	//
+	// Requests of any size are sent whole: HTTP POST bodies are sent with
+	// their Content-Length, and websocket requests as a single message.
+	MaxRequestSize int
+}
+
+// checkRequestSize returns a *RequestTooLargeError if a marshalled request of
+// the given method and size is larger than the MaxRequestSize of the
+// configuration.
+func (config *ConnConfig) checkRequestSize(method string, size int) error {
+	if config.MaxRequestSize > 0 && size > config.MaxRequestSize {
+		return &RequestTooLargeError{
+			Method:  method,
+			Size:    size,
+			MaxSize: config.MaxRequestSize,
+		}
+	}
+	return nil
+}
+
+// retryBackoff returns how long to wait before the next connection attempt,
+// after the given number of failed attempts.
+func (config *ConnConfig) retryBackoff(retries int64) time.Duration {
+	interval := config.ReconnectInterval
+	if interval <= 0 {
+		interval = connectionRetryInterval
+	}
+	maxInterval := config.MaxReconnectInterval
+	if maxInterval <= 0 {
+		maxInterval = maxConnectionRetryInterval
+	}
+
+	// Compare against the max before multiplying, so that a large number of
+	// retries can't overflow the backoff.
+	if retries <= 0 {
+		retries = 1
+	}
+	if interval >= maxInterval || retries >= int64(maxInterval/interval) {
+		return maxInterval
+	}
+	return interval * time.Duration(retries)
+}
+
+// clientCertificate returns the TLS client certificate of the configuration,
+// or nil when the configuration doesn't have one.  An error is returned when
+// the certificate or key is missing or can't be read, or when the key doesn't
+// match the certificate.
+func (config *ConnConfig) clientCertificate() (*tls.Certificate, error) {
+	certPEM, keyPEM := config.ClientCert, config.ClientKey
+	if len(certPEM) > 0 && config.ClientCertFile != "" {
+		return nil, errors.New("only one of the TLS client certificate " +
+			"and client certificate file may be set")
+	}
+	if len(keyPEM) > 0 && config.ClientKeyFile != "" {
+		return nil, errors.New("only one of the TLS client key and " +
+			"client key file may be set")
+	}
+
+	if config.ClientCertFile != "" {
+		var err error
+		certPEM, err = ioutil.ReadFile(config.ClientCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read TLS client "+
+				"certificate: %v", err)
+		}
+	}
+	if config.ClientKeyFile != "" {
+		var err error
+		keyPEM, err = ioutil.ReadFile(config.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read TLS client key: %v",
+				err)
+		}
+	}
+
+	switch {
+	case len(certPEM) == 0 && len(keyPEM) == 0:
+		return nil, nil
+	case len(certPEM) == 0:
+		return nil, errors.New("TLS client key set without a client " +
+			"certificate")
+	case len(keyPEM) == 0:
+		return nil, errors.New("TLS client certificate set without a " +
+			"client key")
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS client certificate: %v", err)
+	}
+
+	return &cert, nil
 }
 
 // newHTTPClient returns a new http client that is configured according to the
-// proxy and TLS settings in the associated connection configuration.
+// proxy, TLS and connection reuse settings in the associated connection
+// configuration.  The client is shared by all the requests of a Client, so
+// that idle connections can be reused.
 func newHTTPClient(config *ConnConfig) (*http.Client, error) {
+	// Connections are only kept open for reuse when the configuration has
+	// room for idle connections.  Unless compression is disabled, the
+	// transport asks for gzip-compressed replies, and decompresses them
+	// before they're read.
+	transport := &http.Transport{
+		DisableKeepAlives:   config.MaxIdleConnsPerHost <= 0,
+		MaxIdleConns:        config.MaxIdleConns,
+		MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
+		IdleConnTimeout:     config.IdleConnTimeout,
+		DisableCompression:  config.DisableCompression,
+	}
+
+	// Dial the Unix socket instead of the host when there is one, without
+	// TLS or a proxy.
+	if config.UnixSocket != "" {
+		var dialer net.Dialer
+		transport.DialContext = func(ctx context.Context, _, _ string) (
+			net.Conn, error) {
+
+			return dialer.DialContext(ctx, "unix", config.UnixSocket)
+		}
+
+		return &http.Client{Transport: transport}, nil
+	}
+
 	// Set proxy function if there is a proxy configured.
-	var proxyFunc func(*http.Request) (*url.URL, error)
 	if config.Proxy != "" {
 		proxyURL, err := url.Parse(config.Proxy)
 		if err != nil {
 			return nil, err
 		}
-		proxyFunc = http.ProxyURL(proxyURL)
+		transport.Proxy = http.ProxyURL(proxyURL)
 	}
 
 	// Configure TLS if needed.
@@ -1137,21 +1542,30 @@ func newHTTPClient(config *ConnConfig) (*http.Client, error) {
 				RootCAs: pool,
 			}
 		}
-	}
 
-	client := http.Client{
-		Transport: &http.Transport{
-			Proxy:           proxyFunc,
-			TLSClientConfig: tlsConfig,
-		},
+		clientCert, err := config.clientCertificate()
+		if err != nil {
+			return nil, err
+		}
+		if clientCert != nil {
+			if tlsConfig == nil {
+				tlsConfig = &tls.Config{}
+			}
+			tlsConfig.Certificates = []tls.Certificate{*clientCert}
+		}
 	}
+	transport.TLSClientConfig = tlsConfig
 
-	return &client, nil
+	return &http.Client{Transport: transport}, nil
 }
 
 // dial opens a websocket connection using the passed connection configuration
 // details.
 func dial(config *ConnConfig) (*websocket.Conn, error) {
+	if config.UnixSocket != "" {
+		return nil, ErrUnixSocketHTTPOnly
+	}
+
 	// Setup TLS if not disabled.
 	var tlsConfig *tls.Config
 	var scheme = "ws"
@@ -1164,12 +1578,23 @@ func dial(config *ConnConfig) (*websocket.Conn, error) {
 			pool.AppendCertsFromPEM(config.Certificates)
 			tlsConfig.RootCAs = pool
 		}
+
+		clientCert, err := config.clientCertificate()
+		if err != nil {
+			return nil, err
+		}
+		if clientCert != nil {
+			tlsConfig.Certificates = []tls.Certificate{*clientCert}
+		}
 		scheme = "wss"
 	}
 
 	// Create a websocket dialer that will be used to make the connection.
 	// It is modified by the proxy setting below as needed.
-	dialer := websocket.Dialer{TLSClientConfig: tlsConfig}
+	dialer := websocket.Dialer{
+		TLSClientConfig:  tlsConfig,
+		HandshakeTimeout: config.RequestTimeout,
+	}
 
 	// Setup the proxy if one is configured.
 	if config.Proxy != "" {
@@ -1193,7 +1618,7 @@ func dial(config *ConnConfig) (*websocket.Conn, error) {
 	wsConn, resp, err := dialer.Dial(url, requestHeader)
 	if err != nil {
 		if err != websocket.ErrBadHandshake || resp == nil {
-			return nil, err
+			return nil, &TransportError{Err: err}
 		}
 
 		// Detect HTTP authentication error status codes.
@@ -1211,7 +1636,10 @@ func dial(config *ConnConfig) (*websocket.Conn, error) {
 
 		// Return the status text from the server if none of the special
 		// cases above apply.
-		return nil, errors.New(resp.Status)
+		return nil, &TransportError{
+			StatusCode: resp.StatusCode,
+			Err:        errors.New(resp.Status),
+		}
 	}
 	return wsConn, nil
 }
@@ -1221,6 +1649,17 @@ func dial(config *ConnConfig) (*websocket.Conn, error) {
 // interested in receiving notifications and will be ignored if the
 // configuration is set to run in HTTP POST mode.
 func New(config *ConnConfig, ntfnHandlers *NotificationHandlers) (*Client, error) {
+	// Check the TLS client certificate and the transport up front, since a
+	// websocket client may not connect until later.
+	if config.UnixSocket != "" && !config.HTTPPostMode {
+		return nil, ErrUnixSocketHTTPOnly
+	}
+	if !config.DisableTLS && config.UnixSocket == "" {
+		if _, err := config.clientCertificate(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Either open a websocket connection or create an HTTP client depending
 	// on the HTTP POST mode.  Also, set the notification handlers to nil
 	// when running in HTTP POST mode.
@@ -1277,6 +1716,161 @@ func New(config *ConnConfig, ntfnHandlers *NotificationHandlers) (*Client, error
 	return client, nil
 }
 
+// NewBatch creates a new RPC client for issuing batches of commands, based on
+// the provided connection configuration details.  Batch clients always run in
+// HTTP POST mode.
+//
+// Commands issued through the asynchronous API of a batch client are not sent
+// right away.  Instead they are queued until Send is called, which issues all
+// of the queued commands to the server in a single HTTP POST request carrying a
+// JSON array of request objects.  The futures returned when the commands were
+// queued are then resolved with the matching reply from the server.  Using the
+// synchronous API on a batch client will block until Send is called from
+// another goroutine, so typically only the asynchronous API is used.
+//
+// A batch client is safe for concurrent access.  Commands queued while a Send
+// is in progress are not part of that batch, and will be issued by the next
+// call to Send.
+func NewBatch(config *ConnConfig) (*Client, error) {
+	batchConfig := *config
+	batchConfig.HTTPPostMode = true
+
+	client, err := New(&batchConfig, nil)
+	if err != nil {
+		return nil, err
+	}
+	client.batch = true
+	client.batchList = list.New()
+
+	return client, nil
+}
+
+// rawBatchResponse is a partially-unmarshaled JSON-RPC response that is part of
+// the reply to a batch request.
+type rawBatchResponse struct {
+	ID *float64 `json:"id"`
+	rawResponse
+}
+
+// Send issues all of the commands queued on a batch client to the server in a
+// single request, and delivers the reply for each command to the future that
+// was returned when it was queued.  Replies are correlated with commands by
+// their id, so the server may reply in any order.
+//
+// An error returned by the server for an individual command is delivered to
+// that command's future only, without affecting the other commands in the
+// batch.  The error returned by Send itself reflects problems with the batch
+// request as a whole, such as connection or parsing errors, in which case the
+// same error is also delivered to all of the futures of the batch.
+//
+// This method will error if the client was not created with NewBatch.
+func (c *Client) Send() error {
+	if !c.batch {
+		return ErrNotBatchClient
+	}
+
+	// Take the queued requests, so that any requests queued from here on
+	// are part of the next batch.
+	c.batchLock.Lock()
+	requests := make([]*jsonRequest, 0, c.batchList.Len())
+	for e := c.batchList.Front(); e != nil; e = e.Next() {
+		requests = append(requests, e.Value.(*jsonRequest))
+	}
+	c.batchList.Init()
+	c.batchLock.Unlock()
+
+	// Nothing to do when there are no queued requests.
+	if len(requests) == 0 {
+		return nil
+	}
+
+	// failAll delivers the passed error to every request in the batch.
+	failAll := func(err error) error {
+		for _, jReq := range requests {
+			jReq.responseChan <- &response{err: err}
+		}
+		return err
+	}
+
+	// Marshal the requests as a JSON array.
+	marshalledRequests := make([]json.RawMessage, 0, len(requests))
+	for _, jReq := range requests {
+		marshalledRequests = append(marshalledRequests,
+			jReq.marshalledJSON)
+	}
+	marshalledJSON, err := json.Marshal(marshalledRequests)
+	if err != nil {
+		return failAll(err)
+	}
+	err = c.config.checkRequestSize("batch", len(marshalledJSON))
+	if err != nil {
+		return failAll(err)
+	}
+
+	// Send the batch and wait for the reply.
+	batchReq := &jsonRequest{
+		id:             c.NextID(),
+		method:         "batch",
+		marshalledJSON: marshalledJSON,
+		responseChan:   make(chan *response, 1),
+		batch:          true,
+	}
+	c.sendPost(batchReq)
+	reply, err := receiveFuture(batchReq.responseChan)
+	if err != nil {
+		return failAll(err)
+	}
+
+	var responses []rawBatchResponse
+	err = json.Unmarshal(reply, &responses)
+	if err != nil {
+		// The server may reply with a single error object when the
+		// batch as a whole couldn't be processed.
+		var resp rawResponse
+		if jerr := json.Unmarshal(reply, &resp); jerr == nil &&
+			resp.Error != nil {
+			return failAll(resp.Error)
+		}
+		return failAll(fmt.Errorf("invalid batch reply: %q",
+			string(reply)))
+	}
+
+	// Deliver each response to the request with the matching id.
+	pending := make(map[uint64]*jsonRequest, len(requests))
+	for _, jReq := range requests {
+		pending[jReq.id] = jReq
+	}
+	for _, resp := range responses {
+		if resp.ID == nil || *resp.ID < 0 ||
+			*resp.ID != math.Trunc(*resp.ID) {
+			log.Warn("Malformed batch response: invalid identifier")
+			continue
+		}
+		id := uint64(*resp.ID)
+		jReq, ok := pending[id]
+		if !ok {
+			log.Warnf("Received unexpected batch reply: %s (id %d)",
+				resp.Result, id)
+			continue
+		}
+		delete(pending, id)
+
+		result, err := resp.result()
+		jReq.responseChan <- &response{result: result, err: err}
+	}
+
+	// Any requests the server didn't reply to get an error, so callers
+	// waiting on them don't block forever.  Iterate over the requests
+	// rather than the map so the order errors are delivered in is stable.
+	for _, jReq := range requests {
+		if _, ok := pending[jReq.id]; ok {
+			jReq.responseChan <- &response{err: ErrBatchNoReply}
+		}
+	}
+
+	return nil
+}
+
 // Connect establishes the initial websocket connection.  This is necessary when
 // a client was created after setting the DisableConnectOnNew field of the
 // Config struct.
@@ -1300,18 +1894,13 @@ func (c *Client) Connect(tries int) error {
 	}
 
 	// Begin connection attempts.  Increase the backoff after each failed
-	// attempt, up to a maximum of one minute.
+	// attempt, up to the configured maximum.
 	var err error
-	var backoff time.Duration
 	for i := 0; tries == 0 || i < tries; i++ {
 		var wsConn *websocket.Conn
 		wsConn, err = dial(c.config)
 		if err != nil {
-			backoff = connectionRetryInterval * time.Duration(i+1)
-			if backoff > time.Minute {
-				backoff = time.Minute
-			}
-			time.Sleep(backoff)
+			time.Sleep(c.config.retryBackoff(int64(i + 1)))
 			continue
 		}
 
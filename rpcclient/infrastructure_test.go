@@ -0,0 +1,499 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/websocket"
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+	"github.com/soteria-dag/soterd/soterutil"
+	"github.com/soteria-dag/soterd/wire"
+)
+
+// idHandler returns a JSON-RPC handler which replies to every request with
+// its own id as the result, offset by idOffset in the id of the reply.
+func idHandler(idOffset int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID int `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Write([]byte(fmt.Sprintf(`{"result":%d,"error":null,"id":%d}`,
+			req.ID, req.ID+idOffset)))
+	}
+}
+
+// heightHash returns the hash the blockHashReply replies give for a height.
+func heightHash(height int64) chainhash.Hash {
+	var hash chainhash.Hash
+	binary.LittleEndian.PutUint64(hash[:], uint64(height))
+	return hash
+}
+
+// blockHashReply returns the reply to the given getblockhash request, which
+// has the heightHash of the requested height as its result.
+func blockHashReply(msg []byte) []byte {
+	var req struct {
+		ID     int     `json:"id"`
+		Params []int64 `json:"params"`
+	}
+	json.Unmarshal(msg, &req)
+
+	var height int64
+	if len(req.Params) > 0 {
+		height = req.Params[0]
+	}
+	hash := heightHash(height)
+	return []byte(fmt.Sprintf(`{"result":"%s","error":null,"id":%d}`,
+		hash.String(), req.ID))
+}
+
+// blockHashHandler is an HTTP POST JSON-RPC handler which replies to
+// getblockhash requests with blockHashReply.
+func blockHashHandler(w http.ResponseWriter, r *http.Request) {
+	msg, _ := ioutil.ReadAll(r.Body)
+	w.Write(blockHashReply(msg))
+}
+
+// reorderingWSHandler returns a websocket JSON-RPC handler which replies to
+// getblockhash requests with blockHashReply. The replies are held back until
+// batchSize requests have been received, and are then sent in the reverse
+// order of the requests.
+func reorderingWSHandler(batchSize int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r, nil, 0, 0)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		replies := make([][]byte, 0, batchSize)
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			replies = append(replies, blockHashReply(msg))
+			if len(replies) < batchSize {
+				continue
+			}
+
+			for i := len(replies) - 1; i >= 0; i-- {
+				err := conn.WriteMessage(websocket.TextMessage,
+					replies[i])
+				if err != nil {
+					return
+				}
+			}
+			replies = replies[:0]
+		}
+	}
+}
+
+// TestConcurrentAsync tests that concurrent async requests from many
+// goroutines each receive the reply to their own request, both over HTTP POST
+// and over a websocket whose replies arrive in a different order than the
+// requests were sent in.
+func TestConcurrentAsync(t *testing.T) {
+	const numRequests = 100
+	tests := []struct {
+		name         string
+		handler      http.HandlerFunc
+		httpPostMode bool
+	}{
+		{"http post", blockHashHandler, true},
+		{"websocket", reorderingWSHandler(numRequests), false},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(test.handler)
+
+		client, err := New(&ConnConfig{
+			Host:         strings.TrimPrefix(server.URL, "http://"),
+			Endpoint:     "ws",
+			User:         "user",
+			Pass:         "pass",
+			DisableTLS:   true,
+			HTTPPostMode: test.httpPostMode,
+		}, nil)
+		if err != nil {
+			server.Close()
+			t.Fatalf("%s: New: %v", test.name, err)
+		}
+
+		// Each request is for a different height, so a reply to another
+		// request has the wrong hash for it.
+		var wg sync.WaitGroup
+		errs := make(chan error, numRequests)
+		for i := 0; i < numRequests; i++ {
+			wg.Add(1)
+			go func(height int64) {
+				defer wg.Done()
+				hash, err := client.GetBlockHashAsync(height).Receive()
+				if err != nil {
+					errs <- err
+					return
+				}
+				if want := heightHash(height); *hash != want {
+					errs <- fmt.Errorf("request for height %d "+
+						"received hash %v, want %v", height,
+						hash, want)
+				}
+			}(int64(i))
+		}
+		wg.Wait()
+		close(errs)
+		client.Shutdown()
+		server.Close()
+
+		for err := range errs {
+			t.Errorf("%s: GetBlockHashAsync: %v", test.name, err)
+		}
+	}
+}
+
+// TestMismatchedReply tests that an HTTP POST reply for a different request
+// is rejected.
+func TestMismatchedReply(t *testing.T) {
+	server := httptest.NewServer(idHandler(1))
+	defer server.Close()
+
+	client, err := New(&ConnConfig{
+		Host:         strings.TrimPrefix(server.URL, "http://"),
+		User:         "user",
+		Pass:         "pass",
+		DisableTLS:   true,
+		HTTPPostMode: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer client.Shutdown()
+
+	if count, err := client.GetBlockCount(); err == nil {
+		t.Fatalf("GetBlockCount accepted the reply to another request, "+
+			"got %d", count)
+	}
+}
+
+// TestUnixSocket tests that an HTTP POST client sends its requests over a Unix
+// socket, and that a websocket client can't be configured to use one.
+func TestUnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpcclient")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "rpc.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	server := &http.Server{Handler: idHandler(0)}
+	go server.Serve(listener)
+	defer server.Close()
+
+	// TLS is ignored for the socket, so it doesn't need to be disabled.
+	client, err := New(&ConnConfig{
+		UnixSocket:   socket,
+		User:         "user",
+		Pass:         "pass",
+		HTTPPostMode: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer client.Shutdown()
+
+	if _, err := client.GetBlockCount(); err != nil {
+		t.Fatalf("GetBlockCount: %v", err)
+	}
+
+	_, err = New(&ConnConfig{
+		UnixSocket:          socket,
+		DisableConnectOnNew: true,
+	}, nil)
+	if err != ErrUnixSocketHTTPOnly {
+		t.Fatalf("New websocket client: unexpected error. Got %v, "+
+			"wanted %v", err, ErrUnixSocketHTTPOnly)
+	}
+}
+
+// newConnCountingServer returns a started test server replying to requests
+// with idHandler, along with a counter of the connections made to it.
+func newConnCountingServer() (*httptest.Server, *int32) {
+	var conns int32
+	server := httptest.NewUnstartedServer(idHandler(0))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	return server, &conns
+}
+
+// TestConnectionReuse tests that an HTTP POST client only reuses connections
+// when it's configured to keep idle connections.
+func TestConnectionReuse(t *testing.T) {
+	const numRequests = 5
+	tests := []struct {
+		name                string
+		maxIdleConnsPerHost int
+		wantConns           int32
+	}{
+		{"default", 0, numRequests},
+		{"reused", 1, 1},
+	}
+
+	for _, test := range tests {
+		server, conns := newConnCountingServer()
+
+		client, err := New(&ConnConfig{
+			Host:                strings.TrimPrefix(server.URL, "http://"),
+			User:                "user",
+			Pass:                "pass",
+			DisableTLS:          true,
+			HTTPPostMode:        true,
+			MaxIdleConnsPerHost: test.maxIdleConnsPerHost,
+			IdleConnTimeout:     time.Minute,
+		}, nil)
+		if err != nil {
+			t.Fatalf("%s: New: %v", test.name, err)
+		}
+
+		for i := 0; i < numRequests; i++ {
+			if _, err := client.GetBlockCount(); err != nil {
+				t.Fatalf("%s: GetBlockCount: %v", test.name, err)
+			}
+		}
+		client.Shutdown()
+		server.Close()
+
+		if got := atomic.LoadInt32(conns); got != test.wantConns {
+			t.Errorf("%s: %d requests made %d connections, want %d",
+				test.name, numRequests, got, test.wantConns)
+		}
+	}
+}
+
+// TestCompression tests that an HTTP POST client asks for gzip-compressed
+// replies unless compression is disabled, that compressed replies are
+// decompressed transparently, and that uncompressed replies still work.
+func TestCompression(t *testing.T) {
+	const blockCount = 123
+	tests := []struct {
+		name               string
+		disableCompression bool
+		serverGzip         bool
+		wantAccept         bool
+	}{
+		{"compressed", false, true, true},
+		{"uncompressed server", false, false, true},
+		{"disabled", true, true, false},
+	}
+
+	for _, test := range tests {
+		var accepted int32
+		server := httptest.NewServer(http.HandlerFunc(func(
+			w http.ResponseWriter, r *http.Request) {
+
+			var req struct {
+				ID int `json:"id"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			reply := fmt.Sprintf(`{"result":%d,"error":null,"id":%d}`,
+				blockCount, req.ID)
+
+			acceptGzip := strings.Contains(
+				r.Header.Get("Accept-Encoding"), "gzip")
+			if acceptGzip {
+				atomic.StoreInt32(&accepted, 1)
+			}
+			if !acceptGzip || !test.serverGzip {
+				w.Write([]byte(reply))
+				return
+			}
+
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			gz.Write([]byte(reply))
+			gz.Close()
+		}))
+
+		client, err := New(&ConnConfig{
+			Host:               strings.TrimPrefix(server.URL, "http://"),
+			User:               "user",
+			Pass:               "pass",
+			DisableTLS:         true,
+			HTTPPostMode:       true,
+			DisableCompression: test.disableCompression,
+		}, nil)
+		if err != nil {
+			t.Fatalf("%s: New: %v", test.name, err)
+		}
+
+		count, err := client.GetBlockCount()
+		client.Shutdown()
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: GetBlockCount: %v", test.name, err)
+		}
+		if count != blockCount {
+			t.Fatalf("%s: GetBlockCount returned %d, want %d",
+				test.name, count, blockCount)
+		}
+		if got := atomic.LoadInt32(&accepted) == 1; got != test.wantAccept {
+			t.Fatalf("%s: request asked for gzip: %v, want %v",
+				test.name, got, test.wantAccept)
+		}
+	}
+}
+
+// TestMaxRequestSize tests that requests up to the maximum request size are
+// sent whole, and that larger requests fail without being sent.
+func TestMaxRequestSize(t *testing.T) {
+	var received int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+
+		body, _ := ioutil.ReadAll(r.Body)
+		atomic.StoreInt64(&received, int64(len(body)))
+
+		var req struct {
+			ID int `json:"id"`
+		}
+		json.Unmarshal(body, &req)
+		w.Write([]byte(fmt.Sprintf(`{"result":null,"error":null,"id":%d}`,
+			req.ID)))
+	}))
+	defer server.Close()
+
+	// A block with a large signature script, which is hex-encoded to
+	// twice its serialized size in the request.
+	msgBlock := wire.NewMsgBlock(&wire.BlockHeader{})
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, make([]byte, 200000), nil))
+	msgBlock.AddTransaction(tx)
+	block := soterutil.NewBlock(msgBlock)
+	blockHex := 2 * msgBlock.SerializeSize()
+
+	tests := []struct {
+		name    string
+		maxSize int
+		sent    bool
+	}{
+		{"unlimited", 0, true},
+		{"under the limit", blockHex + 1000, true},
+		{"over the limit", blockHex / 2, false},
+	}
+
+	for _, test := range tests {
+		atomic.StoreInt64(&received, 0)
+		client, err := New(&ConnConfig{
+			Host:           strings.TrimPrefix(server.URL, "http://"),
+			User:           "user",
+			Pass:           "pass",
+			DisableTLS:     true,
+			HTTPPostMode:   true,
+			MaxRequestSize: test.maxSize,
+		}, nil)
+		if err != nil {
+			t.Fatalf("%s: New: %v", test.name, err)
+		}
+
+		err = client.SubmitBlock(block, nil)
+		client.Shutdown()
+
+		got := atomic.LoadInt64(&received)
+		if test.sent {
+			if err != nil {
+				t.Fatalf("%s: SubmitBlock: %v", test.name, err)
+			}
+			if got < int64(blockHex) {
+				t.Fatalf("%s: server received %d bytes, want at "+
+					"least %d", test.name, got, blockHex)
+			}
+			continue
+		}
+
+		var sizeErr *RequestTooLargeError
+		if !errors.As(err, &sizeErr) {
+			t.Fatalf("%s: SubmitBlock returned %v (%T), want a "+
+				"RequestTooLargeError", test.name, err, err)
+		}
+		if sizeErr.Method != "submitblock" ||
+			sizeErr.MaxSize != test.maxSize ||
+			sizeErr.Size <= blockHex {
+
+			t.Fatalf("%s: got error for %s request of %d bytes over "+
+				"%d, want a submitblock request of more than %d "+
+				"bytes over %d", test.name, sizeErr.Method,
+				sizeErr.Size, sizeErr.MaxSize, blockHex,
+				test.maxSize)
+		}
+		if got != 0 {
+			t.Fatalf("%s: server received %d bytes of a request "+
+				"over the limit", test.name, got)
+		}
+	}
+}
+
+// BenchmarkHTTPPost measures the throughput of HTTP POST requests with and
+// without connection reuse.
+func BenchmarkHTTPPost(b *testing.B) {
+	benchmarks := []struct {
+		name                string
+		maxIdleConnsPerHost int
+	}{
+		{"new connections", 0},
+		{"reused connections", 1},
+	}
+
+	for _, bench := range benchmarks {
+		b.Run(bench.name, func(b *testing.B) {
+			server := httptest.NewServer(idHandler(0))
+			defer server.Close()
+
+			client, err := New(&ConnConfig{
+				Host:                strings.TrimPrefix(server.URL, "http://"),
+				User:                "user",
+				Pass:                "pass",
+				DisableTLS:          true,
+				HTTPPostMode:        true,
+				MaxIdleConnsPerHost: bench.maxIdleConnsPerHost,
+			}, nil)
+			if err != nil {
+				b.Fatalf("New: %v", err)
+			}
+			defer client.Shutdown()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := client.GetBlockCount(); err != nil {
+					b.Fatalf("GetBlockCount: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -6,13 +6,16 @@
 package rpcclient
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/soteria-dag/soterd/soterjson"
 	"github.com/soteria-dag/soterd/chaincfg/chainhash"
 	"github.com/soteria-dag/soterd/soterutil"
+	"github.com/soteria-dag/soterd/wire"
 )
 
 // FutureGenerateResult is a future promise to deliver the result of a
@@ -62,6 +65,128 @@ func (c *Client) Generate(numBlocks uint32) ([]*chainhash.Hash, error) {
 	return c.GenerateAsync(numBlocks).Receive()
 }
 
+// GenerateWithParentsAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GenerateWithParents for the blocking version and more details.
+func (c *Client) GenerateWithParentsAsync(numBlocks uint32, parents []*chainhash.Hash) FutureGenerateResult {
+	parentStrs := make([]string, len(parents))
+	for i, parent := range parents {
+		parentStrs[i] = parent.String()
+	}
+
+	cmd := soterjson.NewGenerateWithParentsCmd(numBlocks, parentStrs)
+	return c.sendCmd(cmd)
+}
+
+// GenerateWithParents generates numBlocks blocks and returns their hashes. The
+// first block references exactly the given parents, each of which must be a
+// current tip of the dag, and each following block references only the block
+// generated before it.
+//
+// NOTE: This is a soterd extension.
+func (c *Client) GenerateWithParents(numBlocks uint32, parents []*chainhash.Hash) ([]*chainhash.Hash, error) {
+	return c.GenerateWithParentsAsync(numBlocks, parents).Receive()
+}
+
+// GenerateIdempotentAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GenerateIdempotent for the blocking version and more details.
+func (c *Client) GenerateIdempotentAsync(token string, numBlocks uint32) FutureGenerateResult {
+	cmd := soterjson.NewGenerateIdempotentCmd(token, numBlocks)
+	return c.sendCmd(cmd)
+}
+
+// GenerateIdempotent generates numBlocks blocks and returns their hashes, like
+// Generate, unless the token was already used.  The server remembers the blocks
+// generated for a token for 10 minutes, and returns them for repeated requests
+// with the same token, waiting for them if they're still being generated.  This
+// makes it safe to retry a request that timed out, without generating the
+// blocks twice.
+//
+// Using a token that was already used for a different number of blocks returns
+// an error.  Requests that fail aren't remembered, so they can be retried with
+// the same token.
+//
+// NOTE: This is a soterd extension.
+func (c *Client) GenerateIdempotent(token string, numBlocks uint32) ([]*chainhash.Hash, error) {
+	return c.GenerateIdempotentAsync(token, numBlocks).Receive()
+}
+
+var (
+	// ErrGenerateTimeout is an error to describe the condition where
+	// GenerateAndConfirm didn't see the generated blocks before its
+	// timeout elapsed.
+	ErrGenerateTimeout error = &TimeoutError{msg: "timeout waiting for " +
+		"generated blocks"}
+)
+
+// generateConfirmInterval is how often GenerateAndConfirm polls the block
+// count of the node.
+const generateConfirmInterval = time.Millisecond * 50
+
+// GenerateAndConfirm generates numBlocks blocks, and waits until the node's
+// block count includes them. It returns the hashes of the generated blocks.
+//
+// ErrGenerateTimeout is returned if the blocks weren't generated and counted
+// by the node within the timeout.  The node keeps generating the blocks after
+// the timeout, so retrying the call can generate more blocks than intended; see
+// GenerateIdempotent for generating blocks in a way that's safe to retry.
+func (c *Client) GenerateAndConfirm(numBlocks uint32, timeout time.Duration) ([]*chainhash.Hash, error) {
+	deadline := time.After(timeout)
+
+	startCount, err := c.GetBlockCount()
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait for the generate call to return, unless generating the blocks
+	// takes longer than the timeout.
+	type generateResult struct {
+		hashes []*chainhash.Hash
+		err    error
+	}
+	generated := make(chan generateResult, 1)
+	future := c.GenerateAsync(numBlocks)
+	go func() {
+		hashes, err := future.Receive()
+		generated <- generateResult{hashes: hashes, err: err}
+	}()
+
+	var hashes []*chainhash.Hash
+	select {
+	case r := <-generated:
+		if r.err != nil {
+			return nil, r.err
+		}
+		hashes = r.hashes
+	case <-deadline:
+		return nil, ErrGenerateTimeout
+	}
+
+	// Poll the block count until it includes the generated blocks.
+	ticker := time.NewTicker(generateConfirmInterval)
+	defer ticker.Stop()
+	for {
+		count, err := c.GetBlockCount()
+		if err != nil {
+			return nil, err
+		}
+		if count >= startCount+int64(len(hashes)) {
+			return hashes, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			return nil, ErrGenerateTimeout
+		}
+	}
+}
+
 // FutureGetGenerateResult is a future promise to deliver the result of a
 // GetGenerateAsync RPC invocation (or an applicable error).
 type FutureGetGenerateResult chan *response
@@ -226,7 +351,11 @@ func (c *Client) GetMiningInfoAsync() FutureGetMiningInfoResult {
 	return c.sendCmd(cmd)
 }
 
-// GetMiningInfo returns mining information.
+// GetMiningInfo returns mining information.  Along with the difficulty, the
+// estimated network hashes per second and the number of transactions in the
+// mempool, the result describes the dag that the next block builds on: its
+// maximum height, the length of its ordering, its number of tips and the tips
+// that the next block should reference as parents.
 func (c *Client) GetMiningInfo() (*soterjson.GetMiningInfoResult, error) {
 	return c.GetMiningInfoAsync().Receive()
 }
@@ -444,4 +573,94 @@ func (c *Client) SubmitBlock(block *soterutil.Block, options *soterjson.SubmitBl
 	return c.SubmitBlockAsync(block, options).Receive()
 }
 
-// TODO(davec): Implement GetBlockTemplate
+// FutureTestBlockAcceptanceResult is a future promise to deliver the result of
+// a TestBlockAcceptanceAsync RPC invocation (or an applicable error).
+type FutureTestBlockAcceptanceResult chan *response
+
+// Receive waits for the response promised by the future and returns whether
+// the block would be accepted, rejected, or orphaned, along with the reason.
+func (r FutureTestBlockAcceptanceResult) Receive() (*soterjson.TestBlockAcceptanceResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result soterjson.TestBlockAcceptanceResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// TestBlockAcceptanceAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See TestBlockAcceptance for the blocking version and more details.
+func (c *Client) TestBlockAcceptanceAsync(block *wire.MsgBlock) FutureTestBlockAcceptanceResult {
+	var buf bytes.Buffer
+	if err := block.Serialize(&buf); err != nil {
+		return newFutureError(err)
+	}
+
+	cmd := soterjson.NewTestBlockAcceptanceCmd(hex.EncodeToString(buf.Bytes()))
+	return c.sendCmd(cmd)
+}
+
+// TestBlockAcceptance fully validates the block as if it was submitted, without
+// adding it to the dag, and returns whether it would be accepted, rejected, or
+// added to the orphan pool because some of its parents are unknown.
+//
+// NOTE: This is a soterd extension.
+func (c *Client) TestBlockAcceptance(block *wire.MsgBlock) (*soterjson.TestBlockAcceptanceResult, error) {
+	return c.TestBlockAcceptanceAsync(block).Receive()
+}
+
+// FutureGetBlockTemplateResult is a future promise to deliver the result of a
+// GetBlockTemplateAsync RPC invocation (or an applicable error).
+type FutureGetBlockTemplateResult chan *response
+
+// Receive waits for the response promised by the future and returns the block
+// template.
+func (r FutureGetBlockTemplateResult) Receive() (*soterjson.GetBlockTemplateResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result soterjson.GetBlockTemplateResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetBlockTemplateAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetBlockTemplate for the blocking version and more details.
+func (c *Client) GetBlockTemplateAsync(req *soterjson.TemplateRequest) FutureGetBlockTemplateResult {
+	cmd := soterjson.NewGetBlockTemplateCmd(req)
+	return c.sendCmd(cmd)
+}
+
+// GetBlockTemplate returns a block template for an external miner to solve,
+// according to the capabilities of the miner in the request.  A nil request
+// is the same as a request without capabilities, which gets a template with
+// a coinbase value rather than a coinbase transaction.
+//
+// The template lists the dag tips the block must reference as its parents, in
+// the order they must appear in the block, and the previous block hash of the
+// template is the tips hash of those parents.  Solved blocks are submitted
+// with SubmitBlock.
+//
+// Only template requests are supported; block proposals don't return a
+// template.
+func (c *Client) GetBlockTemplate(req *soterjson.TemplateRequest) (*soterjson.GetBlockTemplateResult, error) {
+	return c.GetBlockTemplateAsync(req).Receive()
+}
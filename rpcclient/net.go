@@ -7,6 +7,7 @@ package rpcclient
 
 import (
 	"encoding/json"
+	"errors"
 
 	"github.com/soteria-dag/soterd/soterjson"
 )
@@ -34,6 +35,22 @@ func (cmd AddNodeCommand) String() string {
 	return string(cmd)
 }
 
+// IsValid returns whether the AddNodeCommand is one of the commands accepted
+// by the AddNode function.
+func (cmd AddNodeCommand) IsValid() bool {
+	switch cmd {
+	case ANAdd, ANRemove, ANOneTry:
+		return true
+	}
+
+	return false
+}
+
+// ErrInvalidAddNodeCommand is an error to describe the condition where
+// AddNode is called with a command other than ANAdd, ANRemove or ANOneTry.
+var ErrInvalidAddNodeCommand = errors.New("addnode command must be one of " +
+	"add, remove or onetry")
+
 // FutureAddNodeResult is a future promise to deliver the result of an
 // AddNodeAsync RPC invocation (or an applicable error).
 type FutureAddNodeResult chan *response
@@ -51,6 +68,10 @@ func (r FutureAddNodeResult) Receive() error {
 //
 // See AddNode for the blocking version and more details.
 func (c *Client) AddNodeAsync(host string, command AddNodeCommand) FutureAddNodeResult {
+	if !command.IsValid() {
+		return newFutureError(ErrInvalidAddNodeCommand)
+	}
+
 	cmd := soterjson.NewAddNodeCmd(host, soterjson.AddNodeSubCmd(command))
 	return c.sendCmd(cmd)
 }
@@ -59,7 +80,9 @@ func (c *Client) AddNodeAsync(host string, command AddNodeCommand) FutureAddNode
 // For example, it can be used to add or a remove a persistent peer, or to do
 // a one time connection to a peer.
 //
-// It may not be used to remove non-persistent peers.
+// It may not be used to remove non-persistent peers, see DisconnectNode for
+// that.  ErrInvalidAddNodeCommand is returned without contacting the server
+// when the command isn't one of ANAdd, ANRemove or ANOneTry.
 func (c *Client) AddNode(host string, command AddNodeCommand) error {
 	return c.AddNodeAsync(host, command).Receive()
 }
@@ -98,6 +121,24 @@ func (c *Client) Node(command soterjson.NodeSubCmd, host string,
 	return c.NodeAsync(command, host, connectSubCmd).Receive()
 }
 
+// DisconnectNodeAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See DisconnectNode for the blocking version and more details.
+func (c *Client) DisconnectNodeAsync(host string) FutureNodeResult {
+	return c.NodeAsync(soterjson.NDisconnect, host, nil)
+}
+
+// DisconnectNode disconnects the non-persistent peer identified by host, which
+// is either its address or its peer id.
+//
+// It may not be used to disconnect persistent peers, which need to be removed
+// with AddNode and ANRemove instead so that they aren't reconnected.
+func (c *Client) DisconnectNode(host string) error {
+	return c.DisconnectNodeAsync(host).Receive()
+}
+
 // FutureGetAddedNodeInfoResult is a future promise to deliver the result of a
 // GetAddedNodeInfoAsync RPC invocation (or an applicable error).
 type FutureGetAddedNodeInfoResult chan *response
@@ -342,6 +383,100 @@ func (c *Client) GetPeerInfo() ([]soterjson.GetPeerInfoResult, error) {
 	return c.GetPeerInfoAsync().Receive()
 }
 
+// FutureGetPeerLimitsResult is a future promise to deliver the result of a
+// GetPeerLimitsAsync RPC invocation (or an applicable error).
+type FutureGetPeerLimitsResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// maximum number of inbound and outbound peers, and the number of peers
+// currently connected.
+func (r FutureGetPeerLimitsResult) Receive() (*soterjson.GetPeerLimitsResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getpeerlimits result object.
+	var limits soterjson.GetPeerLimitsResult
+	err = json.Unmarshal(res, &limits)
+	if err != nil {
+		return nil, err
+	}
+
+	return &limits, nil
+}
+
+// GetPeerLimitsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetPeerLimits for the blocking version and more details.
+func (c *Client) GetPeerLimitsAsync() FutureGetPeerLimitsResult {
+	cmd := soterjson.NewGetPeerLimitsCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetPeerLimits returns the maximum number of inbound and outbound peers, and
+// the number of peers currently connected.
+func (c *Client) GetPeerLimits() (*soterjson.GetPeerLimitsResult, error) {
+	return c.GetPeerLimitsAsync().Receive()
+}
+
+// FutureSetMaxPeersResult is a future promise to deliver the result of a
+// SetMaxPeersAsync RPC invocation (or an applicable error).
+type FutureSetMaxPeersResult chan *response
+
+// Receive waits for the response promised by the future and returns an error if
+// any occurred when changing the peer limits.
+func (r FutureSetMaxPeersResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// SetMaxPeersAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See SetMaxPeers for the blocking version and more details.
+func (c *Client) SetMaxPeersAsync(inbound, outbound int) FutureSetMaxPeersResult {
+	cmd := soterjson.NewSetMaxPeersCmd(int32(inbound), int32(outbound))
+	return c.sendCmd(cmd)
+}
+
+// SetMaxPeers changes the maximum number of inbound and outbound peers of the
+// server.  When a limit is lowered below the number of connected peers, the
+// server disconnects the most recently connected peers.
+func (c *Client) SetMaxPeers(inbound, outbound int) error {
+	return c.SetMaxPeersAsync(inbound, outbound).Receive()
+}
+
+// FutureStopResult is a future promise to deliver the result of a StopAsync RPC
+// invocation (or an applicable error).
+type FutureStopResult chan *response
+
+// Receive waits for the response promised by the future and returns an error if
+// the server couldn't be asked to stop.
+func (r FutureStopResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// StopAsync returns an instance of a type that can be used to get the result of
+// the RPC at some future time by invoking the Receive function on the returned
+// instance.
+//
+// See Stop for the blocking version and more details.
+func (c *Client) StopAsync() FutureStopResult {
+	cmd := soterjson.NewStopCmd()
+	return c.sendCmd(cmd)
+}
+
+// Stop asks the server to shut down.  The server replies before shutting down,
+// so the connection to it is lost shortly after Stop returns.
+func (c *Client) Stop() error {
+	return c.StopAsync().Receive()
+}
+
 // FutureGetNetTotalsResult is a future promise to deliver the result of a
 // GetNetTotalsAsync RPC invocation (or an applicable error).
 type FutureGetNetTotalsResult chan *response
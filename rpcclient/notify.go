@@ -34,6 +34,7 @@ var (
 // reconnect.
 type notificationState struct {
 	notifyBlocks       bool
+	notifyDagTips      bool
 	notifyNewTx        bool
 	notifyNewTxVerbose bool
 	notifyReceived     map[string]struct{}
@@ -44,6 +45,7 @@ type notificationState struct {
 func (s *notificationState) Copy() *notificationState {
 	var stateCopy notificationState
 	stateCopy.notifyBlocks = s.notifyBlocks
+	stateCopy.notifyDagTips = s.notifyDagTips
 	stateCopy.notifyNewTx = s.notifyNewTx
 	stateCopy.notifyNewTxVerbose = s.notifyNewTxVerbose
 	stateCopy.notifyReceived = make(map[string]struct{})
@@ -91,6 +93,16 @@ type NotificationHandlers struct {
 	// notification handlers, and is safe for blocking client requests.
 	OnClientConnected func()
 
+	// OnClientReconnected is invoked after the client has automatically
+	// reconnected to the RPC server, once the notifications it registered
+	// for have been re-registered and any requests which hadn't completed
+	// have been reissued.  Registrations which aren't tracked by the
+	// client, such as transaction filters loaded with LoadTxFilter, must be
+	// re-established by the caller from this callback.  This callback is
+	// run async with the rest of the notification handlers, and is safe for
+	// blocking client requests.
+	OnClientReconnected func()
+
 	// OnBlockConnected is invoked when a block is connected to the longest
 	// (best) chain.  It will only be invoked if a preceding call to
 	// NotifyBlocks has been made to register for the notification and the
@@ -122,6 +134,34 @@ type NotificationHandlers struct {
 	// OnBlockDisconnected: it receives the block's height and header.
 	OnFilteredBlockDisconnected func(height int32, header *wire.BlockHeader)
 
+	// OnBlockReordered is invoked when connecting a block moves blocks that
+	// were already in the dag between its blue and red sets, with the
+	// hashes of the blocks that became blue and of those that became red.
+	// It's the dag equivalent of a chain reorganization, and callers
+	// should re-evaluate the confirmations of transactions in the blocks.
+	// It will only be invoked if a preceding call to NotifyBlocks has been
+	// made to register for the notification and the function is non-nil.
+	//
+	// The handler is invoked at most once per connected block, after the
+	// OnBlockConnected and OnFilteredBlockConnected callbacks of that
+	// block.  While many blocks are mined concurrently, competing parts of
+	// the dag can overtake each other several times as they grow, so the
+	// same blocks may be reported as moving back and forth between the
+	// sets, as often as once per connected block.
+	OnBlockReordered func(blue, red []*chainhash.Hash)
+
+	// OnDagTipsChanged is invoked when the set of dag tips changes, with the
+	// hashes of the tips that were added and removed.  It will only be
+	// invoked if a preceding call to NotifyDagTips has been made to
+	// register for the notification and the function is non-nil.
+	//
+	// When also registered with NotifyBlocks, the OnBlockConnected and
+	// OnFilteredBlockConnected callbacks for a block are invoked before the
+	// OnDagTipsChanged callback for the tip change it caused.  Tip changes
+	// from blocks connected in quick succession may be delivered as a
+	// single callback.
+	OnDagTipsChanged func(added, removed []*chainhash.Hash)
+
 	// OnRecvTx is invoked when a transaction that receives funds to a
 	// registered address is received into the memory pool and also
 	// connected to the longest (best) chain.  It will only be invoked if a
@@ -291,6 +331,40 @@ func (c *Client) handleNotification(ntfn *rawNotification) {
 		c.ntfnHandlers.OnFilteredBlockDisconnected(blockHeight,
 			blockHeader)
 
+	// OnBlockReordered
+	case soterjson.BlockReorderedNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnBlockReordered == nil {
+			return
+		}
+
+		blue, red, err := parseBlockReorderedParams(ntfn.Params)
+		if err != nil {
+			log.Warnf("Received invalid block reordered "+
+				"notification: %v", err)
+			return
+		}
+
+		c.ntfnHandlers.OnBlockReordered(blue, red)
+
+	// OnDagTipsChanged
+	case soterjson.DagTipsChangedNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnDagTipsChanged == nil {
+			return
+		}
+
+		added, removed, err := parseDagTipsChangedParams(ntfn.Params)
+		if err != nil {
+			log.Warnf("Received invalid dag tips changed "+
+				"notification: %v", err)
+			return
+		}
+
+		c.ntfnHandlers.OnDagTipsChanged(added, removed)
+
 	// OnRecvTx
 	case soterjson.RecvTxNtfnMethod:
 		// Ignore the notification if the client is not interested in
@@ -526,6 +600,73 @@ func parseChainNtfnParams(params []json.RawMessage) (*chainhash.Hash,
 	return blockHash, blockHeight, blockTime, nil
 }
 
+// parseDagTipsChangedParams parses out the added and removed tip hashes from
+// the parameters of a dagtipschanged notification.
+//
+// NOTE: This is a soterd extension and requires a websocket connection.
+func parseDagTipsChangedParams(params []json.RawMessage) ([]*chainhash.Hash,
+	[]*chainhash.Hash, error) {
+
+	if len(params) != 2 {
+		return nil, nil, wrongNumParams(len(params))
+	}
+
+	added, err := parseHashesParam(params[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	removed, err := parseHashesParam(params[1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return added, removed, nil
+}
+
+// parseBlockReorderedParams parses out the hashes of the blocks that became
+// blue and red from the parameters of a blockreordered notification.
+//
+// NOTE: This is a soterd extension and requires a websocket connection.
+func parseBlockReorderedParams(params []json.RawMessage) ([]*chainhash.Hash,
+	[]*chainhash.Hash, error) {
+
+	if len(params) != 2 {
+		return nil, nil, wrongNumParams(len(params))
+	}
+
+	blue, err := parseHashesParam(params[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	red, err := parseHashesParam(params[1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return blue, red, nil
+}
+
+// parseHashesParam parses out an array of hashes from a notification
+// parameter.
+func parseHashesParam(param json.RawMessage) ([]*chainhash.Hash, error) {
+	var hashStrs []string
+	err := json.Unmarshal(param, &hashStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]*chainhash.Hash, 0, len(hashStrs))
+	for _, hashStr := range hashStrs {
+		hash, err := chainhash.NewHashFromStr(hashStr)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil
+}
+
 // parseFilteredBlockConnectedParams parses out the parameters included in a
 // filteredblockconnected notification.
 //
@@ -890,19 +1031,68 @@ func (c *Client) NotifyBlocksAsync() FutureNotifyBlocksResult {
 }
 
 // NotifyBlocks registers the client to receive notifications when blocks are
-// connected and disconnected from the main chain.  The notifications are
-// delivered to the notification handlers associated with the client.  Calling
-// this function has no effect if there are no notification handlers and will
-// result in an error if the client is configured to run in HTTP POST mode.
+// connected and disconnected from the main chain, or reordered in the dag.  The
+// notifications are delivered to the notification handlers associated with the
+// client.  Calling this function has no effect if there are no notification
+// handlers and will result in an error if the client is configured to run in
+// HTTP POST mode.
 //
 // The notifications delivered as a result of this call will be via one of
-// OnBlockConnected or OnBlockDisconnected.
+// OnBlockConnected, OnBlockDisconnected or OnBlockReordered.
 //
 // NOTE: This is a soterd extension and requires a websocket connection.
 func (c *Client) NotifyBlocks() error {
 	return c.NotifyBlocksAsync().Receive()
 }
 
+// FutureNotifyDagTipsResult is a future promise to deliver the result of a
+// NotifyDagTipsAsync RPC invocation (or an applicable error).
+type FutureNotifyDagTipsResult chan *response
+
+// Receive waits for the response promised by the future and returns an error
+// if the registration was not successful.
+func (r FutureNotifyDagTipsResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// NotifyDagTipsAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See NotifyDagTips for the blocking version and more details.
+//
+// NOTE: This is a soterd extension and requires a websocket connection.
+func (c *Client) NotifyDagTipsAsync() FutureNotifyDagTipsResult {
+	// Not supported in HTTP POST mode.
+	if c.config.HTTPPostMode {
+		return newFutureError(ErrWebsocketsRequired)
+	}
+
+	// Ignore the notification if the client is not interested in
+	// notifications.
+	if c.ntfnHandlers == nil {
+		return newNilFutureResult()
+	}
+
+	cmd := soterjson.NewNotifyDagTipsCmd()
+	return c.sendCmd(cmd)
+}
+
+// NotifyDagTips registers the client to receive notifications when the set of
+// dag tips changes.  The notifications are delivered to the notification
+// handlers associated with the client.  Calling this function has no effect
+// if there are no notification handlers and will result in an error if the
+// client is configured to run in HTTP POST mode.
+//
+// The notifications delivered as a result of this call will be via
+// OnDagTipsChanged.
+//
+// NOTE: This is a soterd extension and requires a websocket connection.
+func (c *Client) NotifyDagTips() error {
+	return c.NotifyDagTipsAsync().Receive()
+}
+
 // FutureNotifySpentResult is a future promise to deliver the result of a
 // NotifySpentAsync RPC invocation (or an applicable error).
 //
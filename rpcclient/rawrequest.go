@@ -6,6 +6,7 @@
 package rpcclient
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 
@@ -55,18 +56,13 @@ func (c *Client) RawRequestAsync(method string, params []json.RawMessage) Future
 		return newFutureError(err)
 	}
 
-	// Generate the request and send it along with a channel to respond on.
-	responseChan := make(chan *response, 1)
-	jReq := &jsonRequest{
+	// The request is sent the same way as registered commands, so it shares
+	// their transport, authentication, request timeout and batching.
+	return c.sendJSONRequestCtx(context.Background(), &jsonRequest{
 		id:             id,
 		method:         method,
-		cmd:            nil,
 		marshalledJSON: marshalledJSON,
-		responseChan:   responseChan,
-	}
-	c.sendRequest(jReq)
-
-	return responseChan
+	})
 }
 
 // RawRequest allows the caller to send a raw or custom request to the server.
@@ -74,6 +70,9 @@ func (c *Client) RawRequestAsync(method string, params []json.RawMessage) Future
 // requests that are not handled by this client package, or to proxy partially
 // unmarshaled requests to another JSON-RPC server if a request cannot be
 // handled directly.
+//
+// The result is returned as-is, without being unmarshalled, so methods the
+// server gained after this package was written can still be called.
 func (c *Client) RawRequest(method string, params []json.RawMessage) (json.RawMessage, error) {
 	return c.RawRequestAsync(method, params).Receive()
 }
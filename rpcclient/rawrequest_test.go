@@ -0,0 +1,105 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestRawRequest tests that a method issued with RawRequest is sent the same
+// way as the typed method, and that its raw result is returned.
+func TestRawRequest(t *testing.T) {
+	const blockCount = 123
+
+	// The server replies to getblockcount with the block count, and to
+	// other methods with an error, after checking the authentication.
+	var methodsLock sync.Mutex
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+
+		var req struct {
+			ID     uint64 `json:"id"`
+			Method string `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		methodsLock.Lock()
+		methods = append(methods, req.Method)
+		methodsLock.Unlock()
+
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "user" || pass != "pass" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if req.Method != "getblockcount" {
+			w.Write([]byte(fmt.Sprintf(`{"result":null,"error":`+
+				`{"code":-32601,"message":"Method not found"},"id":%d}`,
+				req.ID)))
+			return
+		}
+		w.Write([]byte(fmt.Sprintf(`{"result":%d,"error":null,"id":%d}`,
+			blockCount, req.ID)))
+	}))
+	defer server.Close()
+
+	client, err := New(&ConnConfig{
+		Host:         strings.TrimPrefix(server.URL, "http://"),
+		User:         "user",
+		Pass:         "pass",
+		DisableTLS:   true,
+		HTTPPostMode: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer client.Shutdown()
+
+	count, err := client.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount: %v", err)
+	}
+
+	raw, err := client.RawRequest("getblockcount", nil)
+	if err != nil {
+		t.Fatalf("RawRequest: %v", err)
+	}
+	var rawCount int64
+	if err := json.Unmarshal(raw, &rawCount); err != nil {
+		t.Fatalf("RawRequest result %s isn't a block count: %v", raw, err)
+	}
+	if rawCount != count || count != blockCount {
+		t.Fatalf("RawRequest returned block count %d, GetBlockCount "+
+			"returned %d, want %d", rawCount, count, blockCount)
+	}
+
+	// The async variant delivers the same result.
+	raw, err = client.RawRequestAsync("getblockcount", nil).Receive()
+	if err != nil {
+		t.Fatalf("RawRequestAsync: %v", err)
+	}
+	if string(raw) != fmt.Sprint(blockCount) {
+		t.Fatalf("RawRequestAsync returned %s, want %d", raw, blockCount)
+	}
+
+	// Errors from the server are returned as RPC errors.
+	if _, err := client.RawRequest("notamethod", nil); err == nil {
+		t.Fatalf("RawRequest of an unknown method didn't return an error")
+	}
+
+	want := []string{"getblockcount", "getblockcount", "getblockcount",
+		"notamethod"}
+	methodsLock.Lock()
+	defer methodsLock.Unlock()
+	if fmt.Sprint(methods) != fmt.Sprint(want) {
+		t.Fatalf("server received methods %v, want %v", methods, want)
+	}
+}
@@ -307,6 +307,63 @@ func (c *Client) SendRawTransaction(tx *wire.MsgTx, allowHighFees bool) (*chainh
 	return c.SendRawTransactionAsync(tx, allowHighFees).Receive()
 }
 
+// FutureResubmitTransactionResult is a future promise to deliver the result
+// of a ResubmitTransactionAsync RPC invocation (or an applicable error).
+type FutureResubmitTransactionResult chan *response
+
+// Receive waits for the response promised by the future and returns the result
+// of resubmitting the encoded transaction to the server.
+func (r FutureResubmitTransactionResult) Receive() (*soterjson.ResubmitTransactionResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result soterjson.ResubmitTransactionResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ResubmitTransactionAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See ResubmitTransaction for the blocking version and more details.
+//
+// NOTE: This is a soterd extension.
+func (c *Client) ResubmitTransactionAsync(tx *wire.MsgTx) FutureResubmitTransactionResult {
+	txHex := ""
+	if tx != nil {
+		// Serialize the transaction and convert to hex string.
+		buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+		if err := tx.Serialize(buf); err != nil {
+			return newFutureError(err)
+		}
+		txHex = hex.EncodeToString(buf.Bytes())
+	}
+
+	cmd := soterjson.NewResubmitTransactionCmd(txHex)
+	return c.sendCmd(cmd)
+}
+
+// ResubmitTransaction submits the encoded transaction to the server which will
+// then relay it to the network, like SendRawTransaction does.  Unlike
+// SendRawTransaction, a transaction that's already in the server's memory pool
+// is relayed again, which helps a transaction that's stuck in the memory pools
+// of other nodes to reach miners.  The result reports whether the transaction
+// was already in the memory pool, and how many peers it was queued to be
+// relayed to.
+//
+// An error is returned when the transaction is already in a block of the dag.
+//
+// NOTE: This is a soterd extension.
+func (c *Client) ResubmitTransaction(tx *wire.MsgTx) (*soterjson.ResubmitTransactionResult, error) {
+	return c.ResubmitTransactionAsync(tx).Receive()
+}
+
 // FutureSignRawTransactionResult is a future promise to deliver the result
 // of one of the SignRawTransactionAsync family of RPC invocations (or an
 // applicable error).
@@ -0,0 +1,103 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/soteria-dag/soterd/soterjson"
+)
+
+// RequestLogger is implemented by callers who want to log the requests sent
+// by a client, and the responses to them.  It's set with the Logger field of
+// ConnConfig.
+//
+// The params passed to the callbacks are the JSON-RPC parameters of the
+// request, after they've been redacted (see ConnConfig.RedactParams).  The
+// callbacks are invoked from the client's goroutines, so they must be safe for
+// concurrent use and shouldn't block.
+type RequestLogger interface {
+	// LogRequest is invoked when a request is sent to the server.
+	LogRequest(method string, params []json.RawMessage)
+
+	// LogResponse is invoked when the response to a request is received.
+	// The latency is the time since the request was sent, and err is the
+	// error returned to the caller, if any.
+	LogResponse(method string, params []json.RawMessage, latency time.Duration, err error)
+}
+
+// RedactParamsFunc returns the params of a request to pass to a RequestLogger.
+// It's used to keep sensitive params like passphrases and private keys out of
+// the logs.
+type RedactParamsFunc func(method string, params []json.RawMessage) []json.RawMessage
+
+// redactedParam replaces params which have been redacted.
+var redactedParam = json.RawMessage(`"<redacted>"`)
+
+// sensitiveMethods are the methods with params which are redacted by
+// DefaultRedactParams.
+var sensitiveMethods = map[string]struct{}{
+	"createencryptedwallet":  {},
+	"encryptwallet":          {},
+	"importprivkey":          {},
+	"signrawtransaction":     {},
+	"walletpassphrase":       {},
+	"walletpassphrasechange": {},
+}
+
+// DefaultRedactParams is the RedactParamsFunc used when ConnConfig doesn't
+// specify one.  It redacts all params of the wallet methods which take
+// passphrases or private keys.
+func DefaultRedactParams(method string, params []json.RawMessage) []json.RawMessage {
+	if _, ok := sensitiveMethods[method]; !ok {
+		return params
+	}
+
+	redacted := make([]json.RawMessage, len(params))
+	for i := range params {
+		redacted[i] = redactedParam
+	}
+
+	return redacted
+}
+
+// logRequest logs the request with the client's RequestLogger, and arranges
+// for the response to be logged once it's received.  The returned channel is
+// where the response is delivered to the caller.  When the client doesn't
+// have a RequestLogger, the request's response channel is returned as is.
+func (c *Client) logRequest(jReq *jsonRequest) chan *response {
+	logger := c.config.Logger
+	if logger == nil {
+		return jReq.responseChan
+	}
+
+	var params []json.RawMessage
+	var req soterjson.Request
+	if err := json.Unmarshal(jReq.marshalledJSON, &req); err == nil {
+		params = req.Params
+	}
+	redact := c.config.RedactParams
+	if redact == nil {
+		redact = DefaultRedactParams
+	}
+	params = redact(jReq.method, params)
+
+	// The response is delivered to an intermediate channel, so that it can
+	// be logged before it's passed on to the caller.
+	responseChan := jReq.responseChan
+	logChan := make(chan *response, 1)
+	jReq.responseChan = logChan
+
+	start := time.Now()
+	logger.LogRequest(jReq.method, params)
+	go func() {
+		r := <-logChan
+		logger.LogResponse(jReq.method, params, time.Since(start), r.err)
+		responseChan <- r
+	}()
+
+	return responseChan
+}
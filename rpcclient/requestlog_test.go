@@ -0,0 +1,166 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// loggedRequest is a request captured by testLogger.
+type loggedRequest struct {
+	method   string
+	params   []json.RawMessage
+	response bool
+	err      error
+}
+
+// testLogger is a RequestLogger which captures the logged requests.
+type testLogger struct {
+	mtx    sync.Mutex
+	logged []loggedRequest
+}
+
+// LogRequest satisfies the RequestLogger interface.
+func (l *testLogger) LogRequest(method string, params []json.RawMessage) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.logged = append(l.logged, loggedRequest{method: method, params: params})
+}
+
+// LogResponse satisfies the RequestLogger interface.
+func (l *testLogger) LogResponse(method string, params []json.RawMessage, latency time.Duration, err error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.logged = append(l.logged, loggedRequest{method: method, params: params,
+		response: true, err: err})
+}
+
+// testHandler returns a JSON-RPC handler which replies to every request with
+// the given result.
+func testHandler(result string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID interface{} `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		id, _ := json.Marshal(req.ID)
+		w.Write([]byte(`{"result":` + result + `,"error":null,"id":` +
+			string(id) + `}`))
+	}
+}
+
+// newTestServer returns a JSON-RPC server which replies to every request with
+// the given result, and an HTTP POST mode client config for it.
+func newTestServer(result string) (*httptest.Server, *ConnConfig) {
+	server := httptest.NewServer(testHandler(result))
+
+	config := &ConnConfig{
+		Host:         strings.TrimPrefix(server.URL, "http://"),
+		User:         "user",
+		Pass:         "pass",
+		DisableTLS:   true,
+		HTTPPostMode: true,
+	}
+
+	return server, config
+}
+
+// TestRequestLogger tests that requests and their responses are passed to the
+// client's RequestLogger.
+func TestRequestLogger(t *testing.T) {
+	server, config := newTestServer("5")
+	defer server.Close()
+
+	logger := &testLogger{}
+	config.Logger = logger
+	client, err := New(config, nil)
+	if err != nil {
+		t.Fatalf("unable to create client: %v", err)
+	}
+	defer client.Shutdown()
+
+	count, err := client.GetBlockCount()
+	if err != nil {
+		t.Fatalf("getblockcount failed: %v", err)
+	}
+	if count != 5 {
+		t.Fatalf("getblockcount returned %d, want 5", count)
+	}
+
+	logger.mtx.Lock()
+	defer logger.mtx.Unlock()
+	if len(logger.logged) != 2 {
+		t.Fatalf("got %d logged entries, want 2", len(logger.logged))
+	}
+	for i, want := range []bool{false, true} {
+		entry := logger.logged[i]
+		if entry.method != "getblockcount" {
+			t.Errorf("entry %d has method %q, want getblockcount", i,
+				entry.method)
+		}
+		if entry.response != want {
+			t.Errorf("entry %d response is %v, want %v", i,
+				entry.response, want)
+		}
+		if entry.err != nil {
+			t.Errorf("entry %d has unexpected error: %v", i, entry.err)
+		}
+	}
+}
+
+// TestRequestLoggerRedaction tests that sensitive params are redacted before
+// they're logged.
+func TestRequestLoggerRedaction(t *testing.T) {
+	server, config := newTestServer("null")
+	defer server.Close()
+
+	logger := &testLogger{}
+	config.Logger = logger
+	client, err := New(config, nil)
+	if err != nil {
+		t.Fatalf("unable to create client: %v", err)
+	}
+	defer client.Shutdown()
+
+	// The default redaction hides the passphrase.
+	if err := client.WalletPassphrase("secret", 60); err != nil {
+		t.Fatalf("walletpassphrase failed: %v", err)
+	}
+
+	// A custom redaction function replaces the default.
+	client.config.RedactParams = func(method string, params []json.RawMessage) []json.RawMessage {
+		return nil
+	}
+	if _, err := client.GetBlockCount(); err != nil {
+		t.Fatalf("getblockcount failed: %v", err)
+	}
+
+	logger.mtx.Lock()
+	defer logger.mtx.Unlock()
+	if len(logger.logged) != 4 {
+		t.Fatalf("got %d logged entries, want 4", len(logger.logged))
+	}
+	for _, entry := range logger.logged {
+		for _, param := range entry.params {
+			if strings.Contains(string(param), "secret") {
+				t.Errorf("%s logged the passphrase: %s",
+					entry.method, param)
+			}
+		}
+	}
+	if n := len(logger.logged[0].params); n != 2 {
+		t.Errorf("walletpassphrase logged %d params, want 2", n)
+	}
+	if logger.logged[2].params != nil {
+		t.Errorf("custom redaction wasn't applied: %v",
+			logger.logged[2].params)
+	}
+}
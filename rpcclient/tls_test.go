@@ -0,0 +1,155 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soteria-dag/soterd/soterutil"
+)
+
+// newTestCertPair returns a new self-signed PEM-encoded certificate and key.
+func newTestCertPair(t *testing.T, org string) ([]byte, []byte) {
+	cert, key, err := soterutil.NewTLSCertPair(org,
+		time.Now().Add(time.Hour), nil)
+	if err != nil {
+		t.Fatalf("unable to create certificate: %v", err)
+	}
+
+	return cert, key
+}
+
+// TestClientCertificate tests that the client presents its TLS client
+// certificate to a server which requires client authentication.
+func TestClientCertificate(t *testing.T) {
+	serverCert, serverKey := newTestCertPair(t, "rpcclient test server")
+	clientCert, clientKey := newTestCertPair(t, "rpcclient test client")
+
+	// Start a server which only accepts the client's certificate.
+	serverPair, err := tls.X509KeyPair(serverCert, serverKey)
+	if err != nil {
+		t.Fatalf("unable to load server certificate: %v", err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(clientCert)
+	server := httptest.NewUnstartedServer(testHandler("5"))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverPair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	newConfig := func() *ConnConfig {
+		return &ConnConfig{
+			Host:         strings.TrimPrefix(server.URL, "https://"),
+			User:         "user",
+			Pass:         "pass",
+			Certificates: serverCert,
+			HTTPPostMode: true,
+		}
+	}
+
+	// getBlockCount issues a request with a client for the config.
+	getBlockCount := func(config *ConnConfig) error {
+		client, err := New(config, nil)
+		if err != nil {
+			t.Fatalf("unable to create client: %v", err)
+		}
+		defer client.Shutdown()
+
+		_, err = client.GetBlockCount()
+		return err
+	}
+
+	// The server rejects clients without a certificate.
+	if err := getBlockCount(newConfig()); err == nil {
+		t.Errorf("request without a client certificate succeeded")
+	}
+
+	// Clients with the certificate are accepted, whether it's given as
+	// PEM bytes or files.
+	config := newConfig()
+	config.ClientCert = clientCert
+	config.ClientKey = clientKey
+	if err := getBlockCount(config); err != nil {
+		t.Errorf("request with a client certificate failed: %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "rpcclient")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "client.cert")
+	keyFile := filepath.Join(dir, "client.key")
+	if err := ioutil.WriteFile(certFile, clientCert, 0600); err != nil {
+		t.Fatalf("unable to write certificate: %v", err)
+	}
+	if err := ioutil.WriteFile(keyFile, clientKey, 0600); err != nil {
+		t.Fatalf("unable to write key: %v", err)
+	}
+	config = newConfig()
+	config.ClientCertFile = certFile
+	config.ClientKeyFile = keyFile
+	if err := getBlockCount(config); err != nil {
+		t.Errorf("request with client certificate files failed: %v",
+			err)
+	}
+}
+
+// TestClientCertificateInvalid tests that invalid TLS client certificate
+// settings are rejected when the client is created.
+func TestClientCertificateInvalid(t *testing.T) {
+	cert, key := newTestCertPair(t, "rpcclient test")
+	_, otherKey := newTestCertPair(t, "rpcclient other")
+
+	tests := []struct {
+		name     string
+		cert     []byte
+		key      []byte
+		certFile string
+		wantErr  string
+	}{
+		{"mismatched key", cert, otherKey, "", "invalid TLS client certificate"},
+		{"missing key", cert, nil, "", "without a client key"},
+		{"missing certificate", nil, key, "", "without a client certificate"},
+		{"missing file", nil, key, "/nonexistent/client.cert", "unable to read"},
+		{"certificate and file", cert, key, "/nonexistent/client.cert", "only one"},
+	}
+
+	for _, test := range tests {
+		for _, httpPostMode := range []bool{true, false} {
+			config := &ConnConfig{
+				Host:                "127.0.0.1:0",
+				HTTPPostMode:        httpPostMode,
+				DisableConnectOnNew: true,
+				ClientCert:          test.cert,
+				ClientKey:           test.key,
+				ClientCertFile:      test.certFile,
+			}
+			client, err := New(config, nil)
+			if err == nil {
+				client.Shutdown()
+				t.Errorf("%s (http post %v): client created", test.name,
+					httpPostMode)
+				continue
+			}
+			if !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("%s (http post %v): got error %q, want %q",
+					test.name, httpPostMode, err, test.wantErr)
+			}
+		}
+	}
+}
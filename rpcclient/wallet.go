@@ -6,7 +6,9 @@
 package rpcclient
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"strconv"
 
 	"github.com/soteria-dag/soterd/soterjson"
@@ -1974,6 +1976,25 @@ func (c *Client) SignMessage(address soterutil.Address, message string) (string,
 	return c.SignMessageAsync(address, message).Receive()
 }
 
+// SignMessageWithPrivKeyAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See SignMessageWithPrivKey for the blocking version and more details.
+func (c *Client) SignMessageWithPrivKeyAsync(wif, message string) FutureSignMessageResult {
+	cmd := soterjson.NewSignMessageWithPrivKey(wif, message)
+	return c.sendCmd(cmd)
+}
+
+// SignMessageWithPrivKey signs a message with the given private key, which is
+// in wallet import format (WIF), and returns the base64-encoded signature.  The
+// signature can be verified against the address of the key with VerifyMessage.
+//
+// Unlike SignMessage, this doesn't need a wallet.
+func (c *Client) SignMessageWithPrivKey(wif, message string) (string, error) {
+	return c.SignMessageWithPrivKeyAsync(wif, message).Receive()
+}
+
 // FutureVerifyMessageResult is a future promise to deliver the result of a
 // VerifyMessageAsync RPC invocation (or an applicable error).
 type FutureVerifyMessageResult chan *response
@@ -2002,12 +2023,19 @@ func (r FutureVerifyMessageResult) Receive() (bool, error) {
 //
 // See VerifyMessage for the blocking version and more details.
 func (c *Client) VerifyMessageAsync(address soterutil.Address, signature, message string) FutureVerifyMessageResult {
+	// Catch malformed signatures before making the request.
+	_, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return newFutureError(fmt.Errorf("malformed base64 signature: %v", err))
+	}
+
 	addr := address.EncodeAddress()
 	cmd := soterjson.NewVerifyMessageCmd(addr, signature, message)
 	return c.sendCmd(cmd)
 }
 
-// VerifyMessage verifies a signed message.
+// VerifyMessage verifies a signed message.  An error is returned without
+// making the request if the signature isn't valid base64.
 //
 // NOTE: This function requires to the wallet to be unlocked.  See the
 // WalletPassphrase function for more details.
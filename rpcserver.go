@@ -23,6 +23,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -77,6 +78,20 @@ const (
 
 	// maxProtocolVersion is the max protocol version the server supports.
 	maxProtocolVersion = 70002
+
+	// maxOrphanBlocksResults is the maximum number of orphan blocks
+	// returned by the getorphanblocks RPC, to bound the size of the
+	// response.
+	maxOrphanBlocksResults = 100
+
+	// maxDAGTipsPerPage is the maximum number of tips returned by a single
+	// getdagtips call.  Callers page through larger tip sets with the
+	// offset and limit parameters.
+	maxDAGTipsPerPage = 1000
+
+	// maxAnticoneResults is the maximum number of hashes returned by a
+	// single getanticone call, to bound the size of the response.
+	maxAnticoneResults = 1000
 )
 
 var (
@@ -102,7 +117,7 @@ var (
 	// block template generated by the getblocktemplate RPC.    It is
 	// declared here to avoid the overhead of creating the slice on every
 	// invocation for constant data.
-	gbtCapabilities = []string{"proposal"}
+	gbtCapabilities = []string{"proposal", "parents"}
 )
 
 // Errors
@@ -129,58 +144,81 @@ type commandHandler func(*rpcServer, interface{}, <-chan struct{}) (interface{},
 // a dependency loop.
 var rpcHandlers map[string]commandHandler
 var rpcHandlersBeforeInit = map[string]commandHandler{
-	"addnode":               handleAddNode,
-	"createrawtransaction":  handleCreateRawTransaction,
-	"debuglevel":            handleDebugLevel,
-	"decoderawtransaction":  handleDecodeRawTransaction,
-	"decodescript":          handleDecodeScript,
-	"estimatefee":        handleEstimateFee,
-	"generate":           handleGenerate,
-	"getaddednodeinfo":   handleGetAddedNodeInfo,
-	"getaddrcache":       handleGetAddrCache,
-	"getbestblock":       handleGetBestBlock,
-	"getbestblockhash":   handleGetBestBlockHash,
-	"getblock":           handleGetBlock,
-	"getblockchaininfo":  handleGetBlockChainInfo,
-	"getblockcount":      handleGetBlockCount,
-	"getblockhash":       handleGetBlockHash,
-	"getblockheader":     handleGetBlockHeader,
-	"getblocktemplate":   handleGetBlockTemplate,
-	"getblockmetrics":    handleGetBlockMetrics,
-	"getcfilter":         handleGetCFilter,
-	"getcfilterheader":   handleGetCFilterHeader,
-	"getconnectioncount": handleGetConnectionCount,
-	"getcurrentnet":      handleGetCurrentNet,
-	"getdagcoloring":     handleGetDAGColoring,
-	"getdagtips":         handleGetDAGTips,
-	"getdifficulty":      handleGetDifficulty,
-	"getgenerate":        handleGetGenerate,
-	"gethashespersec":    handleGetHashesPerSec,
-	"getheaders":         handleGetHeaders,
-	"getinfo":            handleGetInfo,
-	"getlistenaddrs":     handleGetListenAddrs,
-	"getmempoolinfo":     handleGetMempoolInfo,
-	"getmininginfo":         handleGetMiningInfo,
-	"getnettotals":          handleGetNetTotals,
-	"getnetworkhashps":      handleGetNetworkHashPS,
-	"getpeerinfo":           handleGetPeerInfo,
-	"getrawmempool":         handleGetRawMempool,
-	"getrawtransaction":     handleGetRawTransaction,
-	"gettxout":              handleGetTxOut,
-	"help":                  handleHelp,
-	"node":                  handleNode,
-	"ping":                  handlePing,
-	"renderdag":             handleRenderDag,
-	"searchrawtransactions": handleSearchRawTransactions,
-	"sendrawtransaction":    handleSendRawTransaction,
-	"setgenerate":           handleSetGenerate,
-	"stop":                  handleStop,
-	"submitblock":           handleSubmitBlock,
-	"uptime":                handleUptime,
-	"validateaddress":       handleValidateAddress,
-	"verifychain":           handleVerifyChain,
-	"verifymessage":         handleVerifyMessage,
-	"version":               handleVersion,
+	"addnode":                handleAddNode,
+	"checkprunedag":          handleCheckPruneDag,
+	"clearorphans":           handleClearOrphans,
+	"createrawtransaction":   handleCreateRawTransaction,
+	"debuglevel":             handleDebugLevel,
+	"decoderawtransaction":   handleDecodeRawTransaction,
+	"decodescript":           handleDecodeScript,
+	"decodedagheader":        handleDecodeDagHeader,
+	"estimatefee":            handleEstimateFee,
+	"estimatesmartfee":       handleEstimateSmartFee,
+	"generate":               handleGenerate,
+	"generateidempotent":     handleGenerateIdempotent,
+	"generatewithparents":    handleGenerateWithParents,
+	"getaddednodeinfo":       handleGetAddedNodeInfo,
+	"getaddrcache":           handleGetAddrCache,
+	"getanticone":            handleGetAnticone,
+	"getbestblock":           handleGetBestBlock,
+	"getbestblockhash":       handleGetBestBlockHash,
+	"getblock":               handleGetBlock,
+	"getblockchaininfo":      handleGetBlockChainInfo,
+	"getblockcount":          handleGetBlockCount,
+	"getblockhash":           handleGetBlockHash,
+	"getblockheader":         handleGetBlockHeader,
+	"getblocktemplate":       handleGetBlockTemplate,
+	"getblockmetrics":        handleGetBlockMetrics,
+	"getcfilter":             handleGetCFilter,
+	"getcfilterheader":       handleGetCFilterHeader,
+	"getconnectioncount":     handleGetConnectionCount,
+	"getcurrentnet":          handleGetCurrentNet,
+	"getdagblockhashes":      handleGetDagBlockHashes,
+	"getdagcoloring":         handleGetDAGColoring,
+	"getdaghashps":           handleGetDagHashPS,
+	"getdaginfo":             handleGetDagInfo,
+	"getdagorderhash":        handleGetDagOrderHash,
+	"getdagpath":             handleGetDagPath,
+	"getdagtips":             handleGetDAGTips,
+	"getdagtipstatus":        handleGetDAGTipStatus,
+	"getdifficulty":          handleGetDifficulty,
+	"getgenerate":            handleGetGenerate,
+	"gethashespersec":        handleGetHashesPerSec,
+	"getheaders":             handleGetHeaders,
+	"getinfo":                handleGetInfo,
+	"getlistenaddrs":         handleGetListenAddrs,
+	"getmempoolinfo":         handleGetMempoolInfo,
+	"getmininginfo":          handleGetMiningInfo,
+	"getnettotals":           handleGetNetTotals,
+	"getnetworkhashps":       handleGetNetworkHashPS,
+	"getorphanblocks":        handleGetOrphanBlocks,
+	"getpeerinfo":            handleGetPeerInfo,
+	"getpeerlimits":          handleGetPeerLimits,
+	"getpruneinfo":           handleGetPruneInfo,
+	"getrelaypolicy":         handleGetRelayPolicy,
+	"getrawmempool":          handleGetRawMempool,
+	"getrawtransaction":      handleGetRawTransaction,
+	"gettransactionstatus":   handleGetTransactionStatus,
+	"gettxconfirmations":     handleGetTxConfirmations,
+	"gettxout":               handleGetTxOut,
+	"help":                   handleHelp,
+	"node":                   handleNode,
+	"ping":                   handlePing,
+	"renderdag":              handleRenderDag,
+	"resubmittransaction":    handleResubmitTransaction,
+	"searchrawtransactions":  handleSearchRawTransactions,
+	"sendrawtransaction":     handleSendRawTransaction,
+	"setgenerate":            handleSetGenerate,
+	"setmaxpeers":            handleSetMaxPeers,
+	"signmessagewithprivkey": handleSignMessageWithPrivKey,
+	"stop":                   handleStop,
+	"submitblock":            handleSubmitBlock,
+	"testblockacceptance":    handleTestBlockAcceptance,
+	"uptime":                 handleUptime,
+	"validateaddress":        handleValidateAddress,
+	"verifychain":            handleVerifyChain,
+	"verifymessage":          handleVerifyMessage,
+	"version":                handleVersion,
 }
 
 // list of commands that we recognize, but for which soterd has no support because
@@ -259,34 +297,51 @@ var rpcLimited = map[string]struct{}{
 	"help": {},
 
 	// HTTP/S-only commands
-	"createrawtransaction":  {},
-	"decoderawtransaction":  {},
-	"decodescript":          {},
-	"estimatefee":           {},
-	"getbestblock":          {},
-	"getbestblockhash":      {},
-	"getblock":              {},
-	"getblockcount":         {},
-	"getblockhash":          {},
-	"getblockheader":        {},
-	"getcfilter":            {},
-	"getcfilterheader":      {},
-	"getcurrentnet":         {},
-	"getdifficulty":         {},
-	"getheaders":            {},
-	"getinfo":               {},
-	"getnettotals":          {},
-	"getnetworkhashps":      {},
-	"getrawmempool":         {},
-	"getrawtransaction":     {},
-	"gettxout":              {},
-	"searchrawtransactions": {},
-	"sendrawtransaction":    {},
-	"submitblock":           {},
-	"uptime":                {},
-	"validateaddress":       {},
-	"verifymessage":         {},
-	"version":               {},
+	"createrawtransaction":   {},
+	"decoderawtransaction":   {},
+	"decodescript":           {},
+	"decodedagheader":        {},
+	"estimatefee":            {},
+	"estimatesmartfee":       {},
+	"getanticone":            {},
+	"getbestblock":           {},
+	"getbestblockhash":       {},
+	"getblock":               {},
+	"getblockcount":          {},
+	"getblockhash":           {},
+	"getblockheader":         {},
+	"getcfilter":             {},
+	"getcfilterheader":       {},
+	"getcurrentnet":          {},
+	"getdagblockhashes":      {},
+	"getdaghashps":           {},
+	"getdaginfo":             {},
+	"getdagorderhash":        {},
+	"getdagpath":             {},
+	"getdagtipstatus":        {},
+	"getdifficulty":          {},
+	"getheaders":             {},
+	"getinfo":                {},
+	"getnettotals":           {},
+	"getnetworkhashps":       {},
+	"getorphanblocks":        {},
+	"getpruneinfo":           {},
+	"getrelaypolicy":         {},
+	"getrawmempool":          {},
+	"getrawtransaction":      {},
+	"gettransactionstatus":   {},
+	"gettxconfirmations":     {},
+	"gettxout":               {},
+	"resubmittransaction":    {},
+	"searchrawtransactions":  {},
+	"sendrawtransaction":     {},
+	"signmessagewithprivkey": {},
+	"submitblock":            {},
+	"testblockacceptance":    {},
+	"uptime":                 {},
+	"validateaddress":        {},
+	"verifymessage":          {},
+	"version":                {},
 }
 
 // builderScript is a convenience function which is used for hard-coded scripts
@@ -332,6 +387,37 @@ func rpcNoTxInfoError(txHash *chainhash.Hash) *soterjson.RPCError {
 			txHash))
 }
 
+// generateTokenTTL is how long the blocks generated for a generateidempotent
+// token are remembered, counting from when they were generated.  Repeating the
+// request with the same token within this window returns the same blocks,
+// instead of generating more.
+const generateTokenTTL = time.Minute * 10
+
+// generateToken is the state of a generateidempotent request.  The hashes and
+// err fields are set before done is closed.
+type generateToken struct {
+	numBlocks uint32
+	done      chan struct{}
+	hashes    []string
+	err       error
+	expires   time.Time
+}
+
+// generateTokens houses the generateidempotent requests that are in progress
+// or were completed within generateTokenTTL, by their token.
+type generateTokens struct {
+	sync.Mutex
+	tokens map[string]*generateToken
+}
+
+// newGenerateTokens returns a new instance of generateTokens with all internal
+// fields initialized and ready to use.
+func newGenerateTokens() *generateTokens {
+	return &generateTokens{
+		tokens: make(map[string]*generateToken),
+	}
+}
+
 // gbtWorkState houses state that is used in between multiple RPC invocations to
 // getblocktemplate.
 type gbtWorkState struct {
@@ -516,6 +602,30 @@ func messageToHex(msg wire.Message) (string, error) {
 	return hex.EncodeToString(buf.Bytes()), nil
 }
 
+// handleCheckPruneDag implements the checkprunedag command.  It only checks
+// whether the blocks below a height could be pruned, since the block database
+// doesn't support removing blocks.
+func handleCheckPruneDag(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.CheckPruneDagCmd)
+
+	// Refuse prunes that would break the parent references of the blocks
+	// that remain, so that callers learn why a height can't be used.
+	err := s.cfg.Chain.CheckPruneHeight(c.Height)
+	if err != nil {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+
+	return nil, nil
+}
+
+// handleClearOrphans implements the clearorphans command.
+func handleClearOrphans(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	return s.cfg.Chain.ClearOrphans(), nil
+}
+
 // handleCreateRawTransaction handles createrawtransaction commands.
 func handleCreateRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*soterjson.CreateRawTransactionCmd)
@@ -814,6 +924,57 @@ func handleDecodeRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan
 	return txReply, nil
 }
 
+// handleDecodeDagHeader handles decodedagheader commands.  The header is
+// decoded as a dag block starts, with the block header followed by the parents
+// sub-header, so anything following the parents, like the transactions of a
+// block, is ignored.
+func handleDecodeDagHeader(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.DecodeDagHeaderCmd)
+
+	// Deserialize the header and its parents.
+	hexStr := c.HexHeader
+	if len(hexStr)%2 != 0 {
+		hexStr = "0" + hexStr
+	}
+	serializedHeader, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, rpcDecodeHexError(hexStr)
+	}
+	r := bytes.NewReader(serializedHeader)
+	var header wire.BlockHeader
+	if err := header.Deserialize(r); err != nil {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCDeserialization,
+			Message: "Block header decode failed: " + err.Error(),
+		}
+	}
+	var parents wire.ParentSubHeader
+	if err := parents.Deserialize(r); err != nil {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCDeserialization,
+			Message: "Parents sub-header decode failed: " + err.Error(),
+		}
+	}
+
+	parentHashes := make([]string, 0, len(parents.Parents))
+	for _, parent := range parents.Parents {
+		parentHashes = append(parentHashes, parent.Hash.String())
+	}
+
+	return &soterjson.DecodeDagHeaderResult{
+		Hash:          header.BlockHash().String(),
+		Version:       header.Version,
+		VersionHex:    fmt.Sprintf("%08x", header.Version),
+		PreviousHash:  header.PrevBlock.String(),
+		MerkleRoot:    header.MerkleRoot.String(),
+		Time:          header.Timestamp.Unix(),
+		Bits:          strconv.FormatInt(int64(header.Bits), 16),
+		Nonce:         header.Nonce,
+		ParentVersion: parents.Version,
+		Parents:       parentHashes,
+	}, nil
+}
+
 // handleDecodeScript handles decodescript commands.
 func handleDecodeScript(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*soterjson.DecodeScriptCmd)
@@ -876,6 +1037,11 @@ func handleEstimateFee(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 
 	feeRate, err := s.cfg.FeeEstimator.EstimateFee(uint32(c.NumBlocks))
 
+	// Like bitcoin, -1 is returned when there isn't enough data for an
+	// estimate.
+	if err == mempool.ErrInsufficientFeeData || (err == nil && feeRate == 0) {
+		return -1.0, nil
+	}
 	if err != nil {
 		return -1.0, err
 	}
@@ -884,12 +1050,63 @@ func handleEstimateFee(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return float64(feeRate), nil
 }
 
-// handleGenerate handles generate commands.
-func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+// handleEstimateSmartFee handles estimatesmartfee commands.
+//
+// The estimate is for the given number of blocks when there is data for it,
+// otherwise for the first higher number of blocks that there is data for.  The
+// number of blocks the estimate is for is returned along with it.
+func handleEstimateSmartFee(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.EstimateSmartFeeCmd)
+
+	if s.cfg.FeeEstimator == nil {
+		return nil, errors.New("Fee estimation disabled")
+	}
+
+	if c.ConfTarget <= 0 {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCInvalidParameter,
+			Message: "Parameter ConfTarget must be positive",
+		}
+	}
+
+	for numBlocks := c.ConfTarget; ; numBlocks++ {
+		feeRate, err := s.cfg.FeeEstimator.EstimateFee(uint32(numBlocks))
+		if err == nil && feeRate == 0 {
+			continue
+		}
+
+		// The estimator returns an error once the number of blocks is
+		// past the depth it tracks.
+		if err != nil {
+			if err != mempool.ErrInsufficientFeeData &&
+				numBlocks == c.ConfTarget {
+				return nil, &soterjson.RPCError{
+					Code:    soterjson.ErrRPCInvalidParameter,
+					Message: err.Error(),
+				}
+			}
+
+			return &soterjson.EstimateSmartFeeResult{
+				Errors: []string{"Insufficient data or no feerate found"},
+				Blocks: numBlocks,
+			}, nil
+		}
+
+		rate := float64(feeRate)
+		return &soterjson.EstimateSmartFeeResult{
+			FeeRate: &rate,
+			Blocks:  numBlocks,
+		}, nil
+	}
+}
+
+// checkGenerate returns an error if the server can't generate the requested
+// number of blocks for the generate and generatewithparents commands.
+func checkGenerate(s *rpcServer, numBlocks uint32) error {
 	// Respond with an error if there are no addresses to pay the
 	// created blocks to.
 	if len(cfg.miningAddrs) == 0 {
-		return nil, &soterjson.RPCError{
+		return &soterjson.RPCError{
 			Code: soterjson.ErrRPCInternal.Code,
 			Message: "No payment addresses specified " +
 				"via --miningaddr",
@@ -899,7 +1116,7 @@ func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 	// Respond with an error if there's virtually 0 chance of mining a block
 	// with the CPU.
 	if !s.cfg.ChainParams.GenerateSupported {
-		return nil, &soterjson.RPCError{
+		return &soterjson.RPCError{
 			Code: soterjson.ErrRPCDifficulty,
 			Message: fmt.Sprintf("No support for `generate` on "+
 				"the current network, %s, as it's unlikely to "+
@@ -908,16 +1125,25 @@ func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 		}
 	}
 
-	c := cmd.(*soterjson.GenerateCmd)
-
 	// Respond with an error if the client is requesting 0 blocks to be generated.
-	if c.NumBlocks == 0 {
-		return nil, &soterjson.RPCError{
+	if numBlocks == 0 {
+		return &soterjson.RPCError{
 			Code:    soterjson.ErrRPCInternal.Code,
 			Message: "Please request a nonzero number of blocks to generate.",
 		}
 	}
 
+	return nil
+}
+
+// handleGenerate handles generate commands.
+func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.GenerateCmd)
+
+	if err := checkGenerate(s, c.NumBlocks); err != nil {
+		return nil, err
+	}
+
 	// Create a reply
 	reply := make([]string, c.NumBlocks)
 
@@ -938,6 +1164,168 @@ func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 	return reply, nil
 }
 
+// handleGenerateIdempotent handles generateidempotent commands.
+func handleGenerateIdempotent(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.GenerateIdempotentCmd)
+
+	if c.Token == "" {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCInvalidParameter,
+			Message: "Token must not be empty",
+		}
+	}
+	if err := checkGenerate(s, c.NumBlocks); err != nil {
+		return nil, err
+	}
+
+	// Look up the request for the token, forgetting the requests whose
+	// blocks were generated longer than generateTokenTTL ago.  A token
+	// can't be reused for a different number of blocks until it expires.
+	state := s.generateTokens
+	state.Lock()
+	now := time.Now()
+	for token, gt := range state.tokens {
+		if !gt.expires.IsZero() && now.After(gt.expires) {
+			delete(state.tokens, token)
+		}
+	}
+	gt, exists := state.tokens[c.Token]
+	if exists && gt.numBlocks != c.NumBlocks {
+		state.Unlock()
+		return nil, &soterjson.RPCError{
+			Code: soterjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Token %q is already used to "+
+				"generate %d blocks", c.Token, gt.numBlocks),
+		}
+	}
+	if !exists {
+		gt = &generateToken{
+			numBlocks: c.NumBlocks,
+			done:      make(chan struct{}),
+		}
+		state.tokens[c.Token] = gt
+	}
+	state.Unlock()
+
+	// Generate the blocks for a new token.  Failed requests aren't
+	// remembered, so that they can be retried with the same token.
+	if !exists {
+		blockHashes, err := s.cfg.CPUMiner.GenerateNBlocks(c.NumBlocks)
+		state.Lock()
+		if err != nil {
+			gt.err = &soterjson.RPCError{
+				Code:    soterjson.ErrRPCInternal.Code,
+				Message: err.Error(),
+			}
+			delete(state.tokens, c.Token)
+		} else {
+			gt.hashes = make([]string, len(blockHashes))
+			for i, hash := range blockHashes {
+				gt.hashes[i] = hash.String()
+			}
+			gt.expires = time.Now().Add(generateTokenTTL)
+		}
+		state.Unlock()
+		close(gt.done)
+	}
+
+	// Requests repeated while the blocks are being generated wait for
+	// them, which happens when a client retries after timing out.
+	select {
+	case <-gt.done:
+	case <-closeChan:
+		return nil, ErrClientQuit
+	}
+	if gt.err != nil {
+		return nil, gt.err
+	}
+
+	return gt.hashes, nil
+}
+
+// handleGenerateWithParents handles generatewithparents commands.
+func handleGenerateWithParents(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.GenerateWithParentsCmd)
+
+	if err := checkGenerate(s, c.NumBlocks); err != nil {
+		return nil, err
+	}
+
+	if len(c.Parents) == 0 {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCInvalidParameter,
+			Message: "At least one parent must be specified",
+		}
+	}
+
+	// Make sure that each parent is a known block, and one of the current
+	// tips of the dag.
+	tips := make(map[chainhash.Hash]struct{})
+	for _, tip := range s.cfg.Chain.DAGSnapshot().Tips {
+		tips[tip] = struct{}{}
+	}
+	seen := make(map[chainhash.Hash]struct{}, len(c.Parents))
+	parents := make([]*chainhash.Hash, 0, len(c.Parents))
+	for _, parent := range c.Parents {
+		hash, err := chainhash.NewHashFromStr(parent)
+		if err != nil {
+			return nil, rpcDecodeHexError(parent)
+		}
+
+		if _, exists := seen[*hash]; exists {
+			return nil, &soterjson.RPCError{
+				Code:    soterjson.ErrRPCInvalidParameter,
+				Message: fmt.Sprintf("Parent %s is specified more than once", hash),
+			}
+		}
+		seen[*hash] = struct{}{}
+
+		have, err := s.cfg.Chain.HaveBlock(hash)
+		if err != nil {
+			context := "Failed to look up parent block"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		if !have {
+			return nil, &soterjson.RPCError{
+				Code:    soterjson.ErrRPCBlockNotFound,
+				Message: fmt.Sprintf("Parent block %s not found", hash),
+			}
+		}
+
+		if _, exists := tips[*hash]; !exists {
+			return nil, &soterjson.RPCError{
+				Code:    soterjson.ErrRPCInvalidParameter,
+				Message: fmt.Sprintf("Parent block %s is not a dag tip", hash),
+			}
+		}
+
+		parents = append(parents, hash)
+	}
+
+	maxParents := s.cfg.ChainParams.MaxBlockParents
+	if maxParents > 0 && int32(len(parents)) > maxParents {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("At most %d parents can be specified", maxParents),
+		}
+	}
+
+	blockHashes, err := s.cfg.CPUMiner.GenerateNBlocksWithParents(c.NumBlocks, parents)
+	if err != nil {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCInternal.Code,
+			Message: err.Error(),
+		}
+	}
+
+	reply := make([]string, len(blockHashes))
+	for i, hash := range blockHashes {
+		reply[i] = hash.String()
+	}
+
+	return reply, nil
+}
+
 // handleGetAddedNodeInfo handles getaddednodeinfo commands.
 func handleGetAddedNodeInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*soterjson.GetAddedNodeInfoCmd)
@@ -1044,6 +1432,52 @@ func handleGetAddrCache(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 	return result, nil
 }
 
+// handleGetAnticone implements the getanticone command.
+func handleGetAnticone(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.GetAnticoneCmd)
+
+	hash, err := chainhash.NewHashFromStr(c.Hash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Hash)
+	}
+
+	var limit int32
+	if c.Limit != nil {
+		limit = *c.Limit
+	}
+	if limit < 0 {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCInvalidParameter,
+			Message: "Limit must not be negative",
+		}
+	}
+	if limit == 0 || limit > maxAnticoneResults {
+		limit = maxAnticoneResults
+	}
+
+	anticone, size, err := s.cfg.Chain.Anticone(hash, int(limit))
+	if err != nil {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCBlockNotFound,
+			Message: "Block not found",
+		}
+	}
+
+	hashes := make([]string, 0, len(anticone))
+	for i := range anticone {
+		hashes = append(hashes, anticone[i].String())
+	}
+
+	result := &soterjson.GetAnticoneResult{
+		Hash:      c.Hash,
+		Size:      int32(size),
+		Anticone:  hashes,
+		Truncated: len(anticone) < size,
+	}
+
+	return result, nil
+}
+
 // handleGetBestBlock implements the getbestblock command.
 func handleGetBestBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// All other "get block" commands give either the height, the
@@ -1142,6 +1576,12 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 		}
 	}
 
+	dagWork, err := s.cfg.Chain.DAGWorkByHash(hash)
+	if err != nil {
+		context := "Failed to obtain block dag work"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
 	params := s.cfg.ChainParams
 	blockHeader := &blk.MsgBlock().Header
 	blockReply := soterjson.GetBlockVerboseResult{
@@ -1160,6 +1600,8 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 		Bits:          strconv.FormatInt(int64(blockHeader.Bits), 16),
 		Difficulty:    getDifficultyRatio(blockHeader.Bits, params),
 		NextHashes:    nextHashesStrings,
+		Work:          fmt.Sprintf("%064x", blockdag.CalcWork(blockHeader.Bits)),
+		DagWork:       fmt.Sprintf("%064x", dagWork),
 	}
 
 
@@ -1361,12 +1803,27 @@ func handleGetBlockHash(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 		}
 	}
 
-	var hashStrings []string
+	// Blocks at the same height are sorted by the DAG ordering, so the
+	// first hash is the canonical block for the height.
+	return hashes[0].String(), nil
+}
+
+// handleGetDagBlockHashes implements the getdagblockhashes command.
+func handleGetDagBlockHashes(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.GetDagBlockHashesCmd)
+	hashes, err := s.cfg.Chain.BlockHashesByHeight(c.Height)
+	if err != nil {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCOutOfRange,
+			Message: "Block number out of range",
+		}
+	}
+
+	hashStrings := make([]string, 0, len(hashes))
 	for _, hash := range hashes {
 		hashStrings = append(hashStrings, hash.String())
 	}
 
-
 	return hashStrings, nil
 }
 
@@ -1422,6 +1879,17 @@ func handleGetBlockHeader(s *rpcServer, cmd interface{}, closeChan <-chan struct
 		}
 	}
 
+	// Get the hashes of the block's dag parents.
+	parentHashes, err := s.cfg.Chain.ParentHashesByHash(hash)
+	if err != nil {
+		context := "Failed to obtain block parents"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	var parentHashStrings []string
+	for _, parentHash := range parentHashes {
+		parentHashStrings = append(parentHashStrings, parentHash.String())
+	}
+
 	params := s.cfg.ChainParams
 	blockHeaderReply := soterjson.GetBlockHeaderVerboseResult{
 		Hash:          c.Hash,
@@ -1432,6 +1900,7 @@ func handleGetBlockHeader(s *rpcServer, cmd interface{}, closeChan <-chan struct
 		MerkleRoot:    blockHeader.MerkleRoot.String(),
 		NextHashes:    nextHashStrings,
 		PreviousHash:  blockHeader.PrevBlock.String(),
+		Parents:       parentHashStrings,
 		Nonce:         uint64(blockHeader.Nonce),
 		Time:          blockHeader.Timestamp.Unix(),
 		Bits:          strconv.FormatInt(int64(blockHeader.Bits), 16),
@@ -1606,7 +2075,7 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 	var msgBlock *wire.MsgBlock
 	var targetDifficulty string
 	// jenlouie: set latestHash to be latest tips hash instead of latest block hash
-	latestHash := &s.cfg.Chain.DAGSnapshot().Hash
+	latestHash := &s.cfg.Chain.DAGSnapshot().ParentsHash
 	template := state.template
 	if template == nil || state.prevHash == nil ||
 		!state.prevHash.IsEqual(latestHash) ||
@@ -1798,24 +2267,30 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 	//  Omitting CoinbaseTxn -> coinbase, generation
 	targetDifficulty := fmt.Sprintf("%064x", blockdag.CompactToBig(header.Bits))
 	templateID := encodeTemplateID(state.prevHash, state.lastGenerated)
+	parents := make([]string, 0, len(msgBlock.Parents.Parents))
+	for _, parent := range msgBlock.Parents.Parents {
+		parents = append(parents, parent.Hash.String())
+	}
 	reply := soterjson.GetBlockTemplateResult{
-		Bits:         strconv.FormatInt(int64(header.Bits), 16),
-		CurTime:      header.Timestamp.Unix(),
-		Height:       int64(template.Height),
-		PreviousHash: header.PrevBlock.String(),
-		WeightLimit:  blockdag.MaxBlockWeight,
-		SigOpLimit:   blockdag.MaxBlockSigOpsCost,
-		SizeLimit:    wire.MaxBlockPayload,
-		Transactions: transactions,
-		Version:      header.Version,
-		LongPollID:   templateID,
-		SubmitOld:    submitOld,
-		Target:       targetDifficulty,
-		MinTime:      state.minTimestamp.Unix(),
-		MaxTime:      maxTime.Unix(),
-		Mutable:      gbtMutableFields,
-		NonceRange:   gbtNonceRange,
-		Capabilities: gbtCapabilities,
+		Bits:          strconv.FormatInt(int64(header.Bits), 16),
+		CurTime:       header.Timestamp.Unix(),
+		Height:        int64(template.Height),
+		PreviousHash:  header.PrevBlock.String(),
+		Parents:       parents,
+		ParentVersion: msgBlock.Parents.Version,
+		WeightLimit:   blockdag.MaxBlockWeight,
+		SigOpLimit:    blockdag.MaxBlockSigOpsCost,
+		SizeLimit:     wire.MaxBlockPayload,
+		Transactions:  transactions,
+		Version:       header.Version,
+		LongPollID:    templateID,
+		SubmitOld:     submitOld,
+		Target:        targetDifficulty,
+		MinTime:       state.minTimestamp.Unix(),
+		MaxTime:       maxTime.Unix(),
+		Mutable:       gbtMutableFields,
+		NonceRange:    gbtNonceRange,
+		Capabilities:  gbtCapabilities,
 	}
 	// If the generated block template includes transactions with witness
 	// data, then include the witness commitment in the GBT result.
@@ -2141,6 +2616,10 @@ func chainErrToGBTErrString(err error) string {
 		return "bad-prevblk"
 	case blockdag.ErrPrevBlockNotBest:
 		return "inconclusive-not-best-prvblk"
+	case blockdag.ErrTooManyParents:
+		return "bad-parents-count"
+	case blockdag.ErrParentsNotCanonical:
+		return "bad-parents-order"
 	}
 
 	return "rejected: " + err.Error()
@@ -2183,8 +2662,8 @@ func handleGetBlockTemplateProposal(s *rpcServer, request *soterjson.TemplateReq
 	block := soterutil.NewBlock(&msgBlock)
 
 	// Ensure the block is building from the expected set of tips.
-	// In DAG, PrevBlock is hash of tips
-	expectedPrevHash := s.cfg.Chain.DAGSnapshot().Hash
+	// In DAG, PrevBlock is hash of the tips new blocks reference
+	expectedPrevHash := s.cfg.Chain.DAGSnapshot().ParentsHash
 	prevHash := &block.MsgBlock().Header.PrevBlock
 	if !expectedPrevHash.IsEqual(prevHash) {
 		return "bad-prevblk", nil
@@ -2345,6 +2824,7 @@ func handleGetDAGColoring(s *rpcServer, cmd interface{}, closeChan <-chan struct
 		val := &soterjson.GetDAGColoringResult{
 			Hash: hash.String(),
 			IsBlue: isBlue,
+			Order: i,
 		}
 
 		dagOrder[i] = val
@@ -2353,28 +2833,295 @@ func handleGetDAGColoring(s *rpcServer, cmd interface{}, closeChan <-chan struct
 	return dagOrder, nil
 }
 
-// handleGetDAGTips implements the getdagtips command.
-func handleGetDAGTips(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+// handleGetDagHashPS implements the getdaghashps command.
+//
+// Several blocks of the dag can share a height, so rather than using every
+// block in a range of heights like getnetworkhashps, the estimate is made over
+// a window of the DAG ordering.  The window is the last blocks in the ordering
+// whose height is at most the requested height.  Blocks at the same height are
+// included in the order they appear in the DAG ordering, so when the window
+// starts partway through a height only some of the blocks at that height are
+// used.  As with getnetworkhashps, the first block of the window only marks
+// the start of the time span, and its work isn't counted.
+func handleGetDagHashPS(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	// Note: All valid error return paths should return an int64.
+	// Literal zeros are inferred as int, and won't coerce to int64
+	// because the return value is an interface{}.
 
-	snapshot := s.cfg.Chain.DAGSnapshot()
-	var tipHashes []string
-	for _, tip := range snapshot.Tips {
-		tipHashes = append(tipHashes, tip.String())
+	c := cmd.(*soterjson.GetDagHashPSCmd)
+
+	// When the passed height is too high or zero, just return 0 now
+	// since we can't reasonably calculate the number of network hashes
+	// per second from invalid values.  When it's negative, use the max
+	// height of the dag.
+	dagState := s.cfg.Chain.DAGSnapshot()
+	endHeight := int32(-1)
+	if c.Height != nil {
+		endHeight = *c.Height
+	}
+	if endHeight > dagState.MaxHeight || endHeight == 0 {
+		return int64(0), nil
+	}
+	if endHeight < 0 {
+		endHeight = dagState.MaxHeight
 	}
 
-	result := &soterjson.GetDAGTipsResult{
-		Tips: tipHashes,
-		Hash: snapshot.Hash.String(),
-		MinHeight: snapshot.MinHeight,
-		MaxHeight: snapshot.MaxHeight,
-		BlkCount: snapshot.BlkCount,
+	// When the passed number of blocks isn't positive, use the number of
+	// blocks in a retarget interval.
+	numBlocks := int32(120)
+	if c.Blocks != nil {
+		numBlocks = *c.Blocks
+	}
+	if numBlocks <= 0 {
+		numBlocks = int32(s.cfg.ChainParams.TargetTimespan /
+			s.cfg.ChainParams.TargetTimePerBlock)
 	}
-	return result, nil
-}
 
-// handleGetDifficulty implements the getdifficulty command.
-func handleGetDifficulty(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
-	best := s.cfg.Chain.BestSnapshot()
+	// Walk the DAG ordering back from its end, skipping blocks above the end
+	// height, until the window holds the first block and numBlocks more.
+	ordering := s.cfg.Chain.DAGOrdering()
+	window := make([]*chainhash.Hash, 0, numBlocks+1)
+	for i := len(ordering) - 1; i >= 0 && len(window) <= int(numBlocks); i-- {
+		height, err := s.cfg.Chain.BlockHeightByHash(ordering[i])
+		if err != nil {
+			context := "Failed to fetch block height"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		if height > endHeight {
+			continue
+		}
+		window = append(window, ordering[i])
+	}
+	rpcsLog.Debugf("Calculating dag hashes per second over %d blocks "+
+		"ending at height %d", len(window), endHeight)
+
+	// Find the min and max block timestamps as well as calculate the total
+	// amount of work of the blocks in the window.  The window is in reverse
+	// order, so the first block of the window is the last one.
+	var minTimestamp, maxTimestamp time.Time
+	totalWork := big.NewInt(0)
+	for i := len(window) - 1; i >= 0; i-- {
+		header, err := s.cfg.Chain.HeaderByHash(window[i])
+		if err != nil {
+			context := "Failed to fetch block header"
+			return nil, internalRPCError(err.Error(), context)
+		}
+
+		if i == len(window)-1 {
+			minTimestamp = header.Timestamp
+			maxTimestamp = minTimestamp
+			continue
+		}
+
+		totalWork.Add(totalWork, blockdag.CalcWork(header.Bits))
+		if minTimestamp.After(header.Timestamp) {
+			minTimestamp = header.Timestamp
+		}
+		if maxTimestamp.Before(header.Timestamp) {
+			maxTimestamp = header.Timestamp
+		}
+	}
+
+	// Calculate the difference in seconds between the min and max block
+	// timestamps and avoid division by zero in the case where there is no
+	// time difference.
+	timeDiff := int64(maxTimestamp.Sub(minTimestamp) / time.Second)
+	if timeDiff == 0 {
+		return int64(0), nil
+	}
+
+	hashesPerSec := new(big.Int).Div(totalWork, big.NewInt(timeDiff))
+	return hashesPerSec.Int64(), nil
+}
+
+// handleGetDagInfo implements the getdaginfo command.
+func handleGetDagInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	snapshot := s.cfg.Chain.DAGSnapshot()
+	result := &soterjson.GetDagInfoResult{
+		TipCount:        len(snapshot.Tips),
+		BlkCount:        snapshot.BlkCount,
+		MaxHeight:       snapshot.MaxHeight,
+		BlueSetSize:     s.cfg.Chain.BlueSetSize(),
+		OrphanCount:     s.cfg.Chain.NumOrphans(),
+		MaxBlockParents: s.cfg.ChainParams.MaxBlockParents,
+	}
+
+	return result, nil
+}
+
+// handleGetDagOrderHash implements the getdagorderhash command.
+func handleGetDagOrderHash(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.GetDagOrderHashCmd)
+
+	pos, err := s.cfg.Chain.DAGOrderHash(c.Index)
+	if err != nil {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCOutOfRange,
+			Message: "Order index out of range",
+		}
+	}
+
+	result := &soterjson.GetDagOrderHashResult{
+		Hash:         pos.Hash.String(),
+		Index:        c.Index,
+		Length:       pos.Length,
+		StableLength: pos.StableLength,
+		Stable:       c.Index < pos.StableLength,
+	}
+
+	return result, nil
+}
+
+// handleGetDagPath implements the getdagpath command.
+func handleGetDagPath(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.GetDagPathCmd)
+
+	from, err := chainhash.NewHashFromStr(c.From)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.From)
+	}
+	to, err := chainhash.NewHashFromStr(c.To)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.To)
+	}
+
+	path, err := s.cfg.Chain.DAGPath(from, to)
+	if err != nil {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCBlockNotFound,
+			Message: "Block not found",
+		}
+	}
+
+	hashes := make([]string, 0, len(path))
+	for _, hash := range path {
+		hashes = append(hashes, hash.String())
+	}
+
+	return hashes, nil
+}
+
+// handleGetDAGTips implements the getdagtips command.
+func handleGetDAGTips(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.GetDAGTipsCmd)
+
+	var offset, limit int32
+	if c.Offset != nil {
+		offset = *c.Offset
+	}
+	if c.Limit != nil {
+		limit = *c.Limit
+	}
+	if offset < 0 || limit < 0 {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCInvalidParameter,
+			Message: "Offset and limit must not be negative",
+		}
+	}
+	if limit == 0 || limit > maxDAGTipsPerPage {
+		limit = maxDAGTipsPerPage
+	}
+
+	snapshot := s.cfg.Chain.DAGSnapshot()
+	tipInfo := make([]soterjson.DAGTip, 0, len(snapshot.Tips))
+	for i := range snapshot.Tips {
+		tip := &snapshot.Tips[i]
+		height, err := s.cfg.Chain.BlockHeightByHash(tip)
+		if err != nil {
+			context := "Failed to obtain tip height"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		header, err := s.cfg.Chain.HeaderByHash(tip)
+		if err != nil {
+			context := "Failed to fetch tip header"
+			return nil, internalRPCError(err.Error(), context)
+		}
+
+		tipInfo = append(tipInfo, soterjson.DAGTip{
+			Hash:   tip.String(),
+			Height: height,
+			Work:   fmt.Sprintf("%064x", blockdag.CalcWork(header.Bits)),
+		})
+	}
+
+	// Sort the tips by height and then hash, so that the result is stable
+	// between calls.
+	sort.SliceStable(tipInfo, func(i, j int) bool {
+		if tipInfo[i].Height != tipInfo[j].Height {
+			return tipInfo[i].Height < tipInfo[j].Height
+		}
+		return tipInfo[i].Hash < tipInfo[j].Hash
+	})
+
+	// Select the requested page of the tips.
+	tipCount := int32(len(tipInfo))
+	start := offset
+	if start > tipCount {
+		start = tipCount
+	}
+	end := tipCount
+	if end-start > limit {
+		end = start + limit
+	}
+	var nextOffset int32
+	if end < tipCount {
+		nextOffset = end
+	}
+	tipInfo = tipInfo[start:end]
+
+	tipHashes := make([]string, 0, len(tipInfo))
+	for _, tip := range tipInfo {
+		tipHashes = append(tipHashes, tip.Hash)
+	}
+
+	result := &soterjson.GetDAGTipsResult{
+		Tips: tipHashes,
+		Hash: snapshot.Hash.String(),
+		MinHeight: snapshot.MinHeight,
+		MaxHeight: snapshot.MaxHeight,
+		BlkCount: snapshot.BlkCount,
+		TipInfo: tipInfo,
+		DagWork: fmt.Sprintf("%064x", s.cfg.Chain.DAGWork()),
+		TipCount: tipCount,
+		NextOffset: nextOffset,
+	}
+	return result, nil
+}
+
+// handleGetDAGTipStatus implements the getdagtipstatus command.
+func handleGetDAGTipStatus(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	tips := s.cfg.Chain.TipStatuses()
+	result := make([]soterjson.GetDAGTipStatusResult, 0, len(tips))
+	for _, tip := range tips {
+		var status string
+		switch tip.Status {
+		case blockdag.TipActive:
+			status = soterjson.DAGTipStatusActive
+		case blockdag.TipValidFork:
+			status = soterjson.DAGTipStatusValidFork
+		case blockdag.TipValidHeaders:
+			status = soterjson.DAGTipStatusValidHeaders
+		case blockdag.TipInvalid:
+			status = soterjson.DAGTipStatusInvalid
+		default:
+			context := "Failed to classify tip"
+			return nil, internalRPCError(tip.Status.String(), context)
+		}
+
+		result = append(result, soterjson.GetDAGTipStatusResult{
+			Hash:      tip.Hash.String(),
+			Height:    tip.Height,
+			BranchLen: tip.BranchLen,
+			Status:    status,
+		})
+	}
+
+	return result, nil
+}
+
+// handleGetDifficulty implements the getdifficulty command.
+func handleGetDifficulty(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	best := s.cfg.Chain.BestSnapshot()
 	return getDifficultyRatio(best.Bits, s.cfg.ChainParams), nil
 }
 
@@ -2471,8 +3218,9 @@ func handleGetMempoolInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct
 	}
 
 	ret := &soterjson.GetMempoolInfoResult{
-		Size:  int64(len(mempoolTxns)),
-		Bytes: numBytes,
+		Size:   int64(len(mempoolTxns)),
+		Bytes:  numBytes,
+		MinFee: cfg.minRelayTxFee.ToSOTO(),
 	}
 
 	return ret, nil
@@ -2499,6 +3247,10 @@ func handleGetMiningInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 
 	best := s.cfg.Chain.BestSnapshot()
 	dagState := s.cfg.Chain.DAGSnapshot()
+	parents := make([]string, 0, len(dagState.Parents))
+	for _, parent := range dagState.Parents {
+		parents = append(parents, parent.String())
+	}
 	result := soterjson.GetMiningInfoResult{
 		Blocks:             int64(dagState.BlkCount),
 		CurrentBlockSize:   best.BlockSize,
@@ -2511,6 +3263,10 @@ func handleGetMiningInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 		NetworkHashPS:      networkHashesPerSec,
 		PooledTx:           uint64(s.cfg.TxMemPool.Count()),
 		TestNet:            cfg.TestNet1,
+		MaxHeight:          dagState.MaxHeight,
+		OrderLength:        int32(len(s.cfg.Chain.DAGOrdering())),
+		TipCount:           int32(len(dagState.Tips)),
+		Parents:            parents,
 	}
 	return &result, nil
 }
@@ -2623,6 +3379,31 @@ func handleGetNetworkHashPS(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	return hashesPerSec.Int64(), nil
 }
 
+// handleGetOrphanBlocks implements the getorphanblocks command.
+func handleGetOrphanBlocks(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	infos := s.cfg.Chain.OrphanInfos(maxOrphanBlocksResults)
+	orphans := make([]soterjson.OrphanBlock, 0, len(infos))
+	for _, info := range infos {
+		missing := make([]string, 0, len(info.MissingParents))
+		for _, hash := range info.MissingParents {
+			missing = append(missing, hash.String())
+		}
+
+		orphans = append(orphans, soterjson.OrphanBlock{
+			Hash:           info.Hash.String(),
+			MissingParents: missing,
+			Expiration:     info.Expiration.Unix(),
+		})
+	}
+
+	result := &soterjson.GetOrphanBlocksResult{
+		Total:   s.cfg.Chain.NumOrphans(),
+		Orphans: orphans,
+	}
+
+	return result, nil
+}
+
 // handleGetPeerInfo implements the getpeerinfo command.
 func handleGetPeerInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	peers := s.cfg.ConnMgr.ConnectedPeers()
@@ -2662,6 +3443,39 @@ func handleGetPeerInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return infos, nil
 }
 
+// handleGetPeerLimits implements the getpeerlimits command.
+func handleGetPeerLimits(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	limits := s.cfg.ConnMgr.PeerLimits()
+	return &soterjson.GetPeerLimitsResult{
+		MaxInbound:  int32(limits.MaxInbound),
+		MaxOutbound: int32(limits.MaxOutbound),
+		Inbound:     int32(limits.Inbound),
+		Outbound:    int32(limits.Outbound),
+	}, nil
+}
+
+// handleGetPruneInfo implements the getpruneinfo command.
+func handleGetPruneInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	// The block database doesn't support removing blocks, so the node
+	// never prunes and all blocks from genesis are retained.  Report how
+	// much could be reclaimed by pruning at the highest height that keeps
+	// the parent references of the remaining blocks intact.
+	maxPruneHeight, reclaimable, err := s.cfg.Chain.ReclaimableBytes()
+	if err != nil {
+		context := "Failed to fetch block sizes"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	result := &soterjson.GetPruneInfoResult{
+		Pruned:           false,
+		PruneHeight:      0,
+		MaxPruneHeight:   maxPruneHeight,
+		ReclaimableBytes: reclaimable,
+	}
+
+	return result, nil
+}
+
 // handleGetRawMempool implements the getrawmempool command.
 func handleGetRawMempool(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*soterjson.GetRawMempoolCmd)
@@ -2801,6 +3615,98 @@ func handleGetRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan str
 	return *rawTxn, nil
 }
 
+// handleGetRelayPolicy implements the getrelaypolicy command.
+func handleGetRelayPolicy(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	policy := s.cfg.TxMemPool.Policy()
+	result := &soterjson.GetRelayPolicyResult{
+		AcceptNonStd:         policy.AcceptNonStd,
+		MaxOpReturnSize:      policy.MaxOpReturnSize,
+		MaxTxVersion:         policy.MaxTxVersion,
+		MinRelayTxFee:        policy.MinRelayTxFee.ToSOTO(),
+		DisableRelayPriority: policy.DisableRelayPriority,
+	}
+
+	return result, nil
+}
+
+// handleGetTransactionStatus implements the gettransactionstatus command.
+func handleGetTransactionStatus(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.GetTransactionStatusCmd)
+
+	// Convert the provided transaction hash hex to a Hash.
+	txHash, err := chainhash.NewHashFromStr(c.Txid)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Txid)
+	}
+
+	if s.cfg.TxMemPool.IsTransactionInPool(txHash) {
+		return soterjson.TxStatusMempool, nil
+	}
+
+	if s.cfg.TxIndex == nil {
+		return nil, &soterjson.RPCError{
+			Code: soterjson.ErrRPCNoTxInfo,
+			Message: "The transaction index must be " +
+				"enabled to query the blockchain " +
+				"(specify --txindex)",
+		}
+	}
+
+	confirmed, err := txInDAG(s, txHash)
+	if err != nil {
+		context := "Failed to retrieve transaction location"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	if !confirmed {
+		return soterjson.TxStatusUnknown, nil
+	}
+
+	return soterjson.TxStatusConfirmed, nil
+}
+
+// handleGetTxConfirmations implements the gettxconfirmations command.
+func handleGetTxConfirmations(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.GetTxConfirmationsCmd)
+
+	// Convert the provided transaction hash hex to a Hash.
+	txHash, err := chainhash.NewHashFromStr(c.Txid)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Txid)
+	}
+
+	// Transactions in the memory pool aren't in a block yet.
+	if _, err := s.cfg.TxMemPool.FetchTransaction(txHash); err == nil {
+		return int64(-1), nil
+	}
+
+	if s.cfg.TxIndex == nil {
+		return nil, &soterjson.RPCError{
+			Code: soterjson.ErrRPCNoTxInfo,
+			Message: "The transaction index must be " +
+				"enabled to query the blockchain " +
+				"(specify --txindex)",
+		}
+	}
+
+	// Look up the block containing the transaction.
+	blockRegion, err := s.cfg.TxIndex.TxBlockRegion(txHash)
+	if err != nil {
+		context := "Failed to retrieve transaction location"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	if blockRegion == nil {
+		return nil, rpcNoTxInfoError(txHash)
+	}
+
+	confirmations, err := s.cfg.Chain.OrderConfirmations(blockRegion.Hash)
+	if err != nil {
+		context := "Failed to retrieve block order"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	return int64(confirmations), nil
+}
+
 // handleGetTxOut handles gettxout commands.
 func handleGetTxOut(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*soterjson.GetTxOutCmd)
@@ -3197,6 +4103,88 @@ func fetchMempoolTxnsForAddress(s *rpcServer, addr soterutil.Address, numToSkip,
 	return mpTxns[numToSkip:rangeEnd], numToSkip
 }
 
+// txInDAG returns whether the transaction with the hash is in a block of the
+// dag, by looking it up in the transaction index.
+//
+// The transaction index MUST be enabled.
+func txInDAG(s *rpcServer, txHash *chainhash.Hash) (bool, error) {
+	blockRegion, err := s.cfg.TxIndex.TxBlockRegion(txHash)
+	if err != nil {
+		return false, err
+	}
+	return blockRegion != nil, nil
+}
+
+// handleResubmitTransaction implements the resubmittransaction command.  It's
+// like sendrawtransaction, except that a transaction that's already in the
+// memory pool is relayed again, instead of being rejected as a duplicate.
+func handleResubmitTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.ResubmitTransactionCmd)
+	hexStr := c.HexTx
+	if len(hexStr)%2 != 0 {
+		hexStr = "0" + hexStr
+	}
+	serializedTx, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, rpcDecodeHexError(hexStr)
+	}
+	var msgTx wire.MsgTx
+	err = msgTx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCDeserialization,
+			Message: "TX decode failed: " + err.Error(),
+		}
+	}
+	tx := soterutil.NewTx(&msgTx)
+
+	// There's no point in relaying a transaction that's already in the dag.
+	// It's looked up the same way gettransactionstatus looks it up.  The
+	// memory pool doesn't hold transactions that are in the dag, and
+	// without the transaction index it still refuses one with unspent
+	// outputs, the same as for sendrawtransaction.
+	if s.cfg.TxIndex != nil {
+		confirmed, err := txInDAG(s, tx.Hash())
+		if err != nil {
+			context := "Failed to look up transaction in the dag"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		if confirmed {
+			return nil, &soterjson.RPCError{
+				Code: soterjson.ErrRPCVerifyAlreadyInDAG,
+				Message: fmt.Sprintf("Transaction %v is already "+
+					"in the dag", tx.Hash()),
+			}
+		}
+	}
+
+	result := &soterjson.ResubmitTransactionResult{
+		TxID: tx.Hash().String(),
+	}
+
+	// Transactions that aren't in the memory pool yet are processed the
+	// same way sendrawtransaction processes them.  The ones that are
+	// already in it are only relayed again.
+	txD, err := s.cfg.TxMemPool.FetchTxDesc(tx.Hash())
+	if err != nil {
+		if err := acceptRawTransaction(s, tx); err != nil {
+			return nil, err
+		}
+	} else {
+		result.Rebroadcast = true
+		s.cfg.ConnMgr.RelayTransactions([]*mempool.TxDesc{txD})
+
+		iv := wire.NewInvVect(wire.InvTypeTx, tx.Hash(), -1)
+		s.cfg.ConnMgr.AddRebroadcastInventory(iv, txD)
+	}
+
+	// The relay is queued to every connected peer, and each peer skips it
+	// when it already knows about the transaction, so this is the number of
+	// peers it was queued to, rather than sent to.
+	result.Peers = s.cfg.ConnMgr.ConnectedCount()
+	return result, nil
+}
+
 // handleSearchRawTransactions implements the searchrawtransactions command.
 func handleSearchRawTransactions(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// Respond with an error if the address index is not enabled.
@@ -3483,8 +4471,19 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 		}
 	}
 
-	// Use 0 for the tag to represent local node.
 	tx := soterutil.NewTx(&msgTx)
+	if err := acceptRawTransaction(s, tx); err != nil {
+		return nil, err
+	}
+
+	return tx.Hash().String(), nil
+}
+
+// acceptRawTransaction processes a transaction submitted over RPC into the
+// memory pool, and relays it and any orphans it made acceptable to the network.
+// The transaction is also rebroadcast until it makes its way into a block.
+func acceptRawTransaction(s *rpcServer, tx *soterutil.Tx) error {
+	// Use 0 for the tag to represent local node.
 	acceptedTxs, err := s.cfg.TxMemPool.ProcessTransaction(tx, false, false, 0)
 	if err != nil {
 		// When the error is a rule error, it means the transaction was
@@ -3500,7 +4499,7 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 			rpcsLog.Errorf("Failed to process transaction %v: %v",
 				tx.Hash(), err)
 		}
-		return nil, &soterjson.RPCError{
+		return &soterjson.RPCError{
 			Code:    soterjson.ErrRPCDeserialization,
 			Message: "TX rejected: " + err.Error(),
 		}
@@ -3518,7 +4517,7 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 
 		errStr := fmt.Sprintf("transaction %v is not in accepted list",
 			tx.Hash())
-		return nil, internalRPCError(errStr, "")
+		return internalRPCError(errStr, "")
 	}
 
 	// Generate and relay inventory vectors for all newly accepted
@@ -3530,13 +4529,13 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 	// newly accepted transactions.
 	s.NotifyNewTransactions(acceptedTxs)
 
-	// Keep track of all the sendrawtransaction request txns so that they
+	// Keep track of all the transactions submitted over RPC so that they
 	// can be rebroadcast if they don't make their way into a block.
 	txD := acceptedTxs[0]
 	iv := wire.NewInvVect(wire.InvTypeTx, txD.Tx.Hash(), -1)
 	s.cfg.ConnMgr.AddRebroadcastInventory(iv, txD)
 
-	return tx.Hash().String(), nil
+	return nil
 }
 
 // handleSetGenerate implements the setgenerate command.
@@ -3575,6 +4574,70 @@ func handleSetGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return nil, nil
 }
 
+// handleSetMaxPeers implements the setmaxpeers command.
+func handleSetMaxPeers(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.SetMaxPeersCmd)
+
+	if c.Inbound < 0 || c.Outbound < 0 {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCInvalidParameter,
+			Message: "Peer limits must not be negative",
+		}
+	}
+
+	// Peers over the new limits are disconnected by the server.
+	s.cfg.ConnMgr.SetPeerLimits(int(c.Inbound), int(c.Outbound))
+	return nil, nil
+}
+
+// handleSignMessageWithPrivKey implements the signmessagewithprivkey command.
+func handleSignMessageWithPrivKey(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.SignMessageWithPrivKeyCmd)
+
+	wif, err := soterutil.DecodeWIF(c.PrivKey)
+	if err != nil {
+		message := "Invalid private key"
+		switch err {
+		case soterutil.ErrMalformedPrivateKey:
+			message = "Malformed private key"
+		case soterutil.ErrChecksumMismatch:
+			message = "Private key checksum mismatch"
+		}
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCInvalidAddressOrKey,
+			Message: message,
+		}
+	}
+	if !wif.IsForNet(s.cfg.ChainParams) {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCInvalidAddressOrKey,
+			Message: "Private key for wrong network",
+		}
+	}
+
+	sig, err := soterec.SignCompact(soterec.S256(), wif.PrivKey,
+		signedMessageHash(c.Message), wif.CompressPubKey)
+	if err != nil {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCInvalidAddressOrKey,
+			Message: "Sign failed",
+		}
+	}
+
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// signedMessageHash returns the hash of a message that's signed by
+// signmessagewithprivkey and checked by verifymessage.  The message is
+// prefixed with a magic string, the same way Bitcoin Core does it, so that a
+// signed message can't be mistaken for a signed transaction.
+func signedMessageHash(message string) []byte {
+	var buf bytes.Buffer
+	wire.WriteVarString(&buf, 0, "Soter Signed Message:\n")
+	wire.WriteVarString(&buf, 0, message)
+	return chainhash.DoubleHashB(buf.Bytes())
+}
+
 // handleStop implements the stop command.
 func handleStop(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	select {
@@ -3617,6 +4680,57 @@ func handleSubmitBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return nil, nil
 }
 
+// handleTestBlockAcceptance implements the testblockacceptance command.
+func handleTestBlockAcceptance(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*soterjson.TestBlockAcceptanceCmd)
+
+	// Deserialize the block.
+	hexStr := c.HexBlock
+	if len(hexStr)%2 != 0 {
+		hexStr = "0" + c.HexBlock
+	}
+	serializedBlock, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, rpcDecodeHexError(hexStr)
+	}
+
+	block, err := soterutil.NewBlockFromBytes(serializedBlock)
+	if err != nil {
+		return nil, &soterjson.RPCError{
+			Code:    soterjson.ErrRPCDeserialization,
+			Message: "Block decode failed: " + err.Error(),
+		}
+	}
+
+	// Validate the block without adding it to the dag.  Rule violations
+	// are reported in the result, while any other error means the block
+	// couldn't be validated at all.
+	result := &soterjson.TestBlockAcceptanceResult{
+		Hash:   block.Hash().String(),
+		Status: soterjson.BlockAcceptanceAccepted,
+	}
+	err = s.cfg.Chain.CheckBlockAcceptance(block)
+	if err != nil {
+		ruleErr, ok := err.(blockdag.RuleError)
+		if !ok {
+			context := "Failed to validate block"
+			return nil, internalRPCError(err.Error(), context)
+		}
+
+		result.Reason = ruleErr.Description
+		if ruleErr.ErrorCode == blockdag.ErrPreviousBlockUnknown {
+			result.Status = soterjson.BlockAcceptanceOrphan
+		} else {
+			result.Status = soterjson.BlockAcceptanceRejected
+		}
+	}
+	if block.Height() != soterutil.BlockHeightUnknown {
+		result.Height = block.Height()
+	}
+
+	return result, nil
+}
+
 // handleUptime implements the uptime command.
 func handleUptime(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	return time.Now().Unix() - s.cfg.StartupTime, nil
@@ -3726,10 +4840,7 @@ func handleVerifyMessage(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 
 	// Validate the signature - this just shows that it was valid at all.
 	// we will compare it with the key next.
-	var buf bytes.Buffer
-	wire.WriteVarString(&buf, 0, "Soter Signed Message:\n")
-	wire.WriteVarString(&buf, 0, c.Message)
-	expectedMessageHash := chainhash.DoubleHashB(buf.Bytes())
+	expectedMessageHash := signedMessageHash(c.Message)
 	pk, wasCompressed, err := soterec.RecoverCompact(soterec.S256(), sig,
 		expectedMessageHash)
 	if err != nil {
@@ -3784,6 +4895,7 @@ type rpcServer struct {
 	statusLock             sync.RWMutex
 	wg                     sync.WaitGroup
 	gbtWorkState           *gbtWorkState
+	generateTokens         *generateTokens
 	helpCacher             *helpCacher
 	requestProcessShutdown chan struct{}
 	quit                   chan int
@@ -4046,47 +5158,17 @@ func createMarshalledReply(id, result interface{}, replyErr error) ([]byte, erro
 	return soterjson.MarshalResponse(id, result, jsonErr)
 }
 
-// jsonRPCRead handles reading and responding to RPC messages.
-func (s *rpcServer) jsonRPCRead(w http.ResponseWriter, r *http.Request, isAdmin bool) {
-	if atomic.LoadInt32(&s.shutdown) != 0 {
-		return
-	}
-
-	// Read and close the JSON-RPC request body from the caller.
-	body, err := ioutil.ReadAll(r.Body)
-	r.Body.Close()
-	if err != nil {
-		errCode := http.StatusBadRequest
-		http.Error(w, fmt.Sprintf("%d error reading JSON message: %v",
-			errCode, err), errCode)
-		return
-	}
-
-	// Unfortunately, the http server doesn't provide the ability to
-	// change the read deadline for the new connection and having one breaks
-	// long polling.  However, not having a read deadline on the initial
-	// connection would mean clients can connect and idle forever.  Thus,
-	// hijack the connecton from the HTTP server, clear the read deadline,
-	// and handle writing the response manually.
-	hj, ok := w.(http.Hijacker)
-	if !ok {
-		errMsg := "webserver doesn't support hijacking"
-		rpcsLog.Warnf(errMsg)
-		errCode := http.StatusInternalServerError
-		http.Error(w, strconv.Itoa(errCode)+" "+errMsg, errCode)
-		return
-	}
-	conn, buf, err := hj.Hijack()
-	if err != nil {
-		rpcsLog.Warnf("Failed to hijack HTTP connection: %v", err)
-		errCode := http.StatusInternalServerError
-		http.Error(w, strconv.Itoa(errCode)+" "+err.Error(), errCode)
-		return
-	}
-	defer conn.Close()
-	defer buf.Flush()
-	conn.SetReadDeadline(timeZeroVal)
+// isBatchRequest returns whether the passed raw JSON-RPC request body is a batch
+// of requests, which is encoded as a JSON array.
+func isBatchRequest(body []byte) bool {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	return len(body) > 0 && body[0] == '['
+}
 
+// processRequest parses the passed raw JSON-RPC request, runs the command it
+// describes and returns the marshalled reply.  A nil reply is returned for
+// notifications, since they must not be responded to.
+func (s *rpcServer) processRequest(body []byte, isAdmin bool, closeChan <-chan struct{}) ([]byte, error) {
 	// Attempt to parse the raw body into a JSON-RPC request.
 	var responseID interface{}
 	var jsonErr error
@@ -4118,23 +5200,13 @@ func (s *rpcServer) jsonRPCRead(w http.ResponseWriter, r *http.Request, isAdmin
 		// RPC quirks can be enabled by the user to avoid compatibility issues
 		// with software relying on Core's behavior.
 		if request.ID == nil && !(cfg.RPCQuirks && request.Jsonrpc == "") {
-			return
+			return nil, nil
 		}
 
 		// The parse was at least successful enough to have an ID so
 		// set it for the response.
 		responseID = request.ID
 
-		// Setup a close notifier.  Since the connection is hijacked,
-		// the CloseNotifer on the ResponseWriter is not available.
-		closeChan := make(chan struct{}, 1)
-		go func() {
-			_, err := conn.Read(make([]byte, 1))
-			if err != nil {
-				close(closeChan)
-			}
-		}()
-
 		// Check if the user is limited and set error if method unauthorized
 		if !isAdmin {
 			if _, ok := rpcLimited[request.Method]; !ok {
@@ -4158,12 +5230,123 @@ func (s *rpcServer) jsonRPCRead(w http.ResponseWriter, r *http.Request, isAdmin
 	}
 
 	// Marshal the response.
-	msg, err := createMarshalledReply(responseID, result, jsonErr)
+	return createMarshalledReply(responseID, result, jsonErr)
+}
+
+// processBatchRequest handles a batch of JSON-RPC requests, which is encoded as
+// a JSON array of request objects.  Each request is processed independently, so
+// an error in one request doesn't affect the others.  The marshalled reply is a
+// JSON array of the responses, or nil if every request in the batch was a
+// notification.
+func (s *rpcServer) processBatchRequest(body []byte, isAdmin bool, closeChan <-chan struct{}) ([]byte, error) {
+	var batch []json.RawMessage
+	err := json.Unmarshal(body, &batch)
+	if err != nil {
+		jsonErr := &soterjson.RPCError{
+			Code:    soterjson.ErrRPCParse.Code,
+			Message: "Failed to parse request: " + err.Error(),
+		}
+		return createMarshalledReply(nil, nil, jsonErr)
+	}
+	if len(batch) == 0 {
+		jsonErr := &soterjson.RPCError{
+			Code:    soterjson.ErrRPCInvalidRequest.Code,
+			Message: "Empty batch request",
+		}
+		return createMarshalledReply(nil, nil, jsonErr)
+	}
+
+	replies := make([]json.RawMessage, 0, len(batch))
+	for _, request := range batch {
+		reply, err := s.processRequest(request, isAdmin, closeChan)
+		if err != nil {
+			rpcsLog.Errorf("Failed to marshal reply: %v", err)
+			continue
+		}
+
+		// Notifications are not responded to.
+		if reply == nil {
+			continue
+		}
+		replies = append(replies, reply)
+	}
+
+	if len(replies) == 0 {
+		return nil, nil
+	}
+
+	return json.Marshal(replies)
+}
+
+// jsonRPCRead handles reading and responding to RPC messages.
+func (s *rpcServer) jsonRPCRead(w http.ResponseWriter, r *http.Request, isAdmin bool) {
+	if atomic.LoadInt32(&s.shutdown) != 0 {
+		return
+	}
+
+	// Read and close the JSON-RPC request body from the caller.
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		errCode := http.StatusBadRequest
+		http.Error(w, fmt.Sprintf("%d error reading JSON message: %v",
+			errCode, err), errCode)
+		return
+	}
+
+	// Unfortunately, the http server doesn't provide the ability to
+	// change the read deadline for the new connection and having one breaks
+	// long polling.  However, not having a read deadline on the initial
+	// connection would mean clients can connect and idle forever.  Thus,
+	// hijack the connecton from the HTTP server, clear the read deadline,
+	// and handle writing the response manually.
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		errMsg := "webserver doesn't support hijacking"
+		rpcsLog.Warnf(errMsg)
+		errCode := http.StatusInternalServerError
+		http.Error(w, strconv.Itoa(errCode)+" "+errMsg, errCode)
+		return
+	}
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		rpcsLog.Warnf("Failed to hijack HTTP connection: %v", err)
+		errCode := http.StatusInternalServerError
+		http.Error(w, strconv.Itoa(errCode)+" "+err.Error(), errCode)
+		return
+	}
+	defer conn.Close()
+	defer buf.Flush()
+	conn.SetReadDeadline(timeZeroVal)
+
+	// Setup a close notifier.  Since the connection is hijacked,
+	// the CloseNotifer on the ResponseWriter is not available.
+	closeChan := make(chan struct{}, 1)
+	go func() {
+		_, err := conn.Read(make([]byte, 1))
+		if err != nil {
+			close(closeChan)
+		}
+	}()
+
+	// Process the request, which is either a single JSON-RPC request or a
+	// batch of them.
+	var msg []byte
+	if isBatchRequest(body) {
+		msg, err = s.processBatchRequest(body, isAdmin, closeChan)
+	} else {
+		msg, err = s.processRequest(body, isAdmin, closeChan)
+	}
 	if err != nil {
 		rpcsLog.Errorf("Failed to marshal reply: %v", err)
 		return
 	}
 
+	// Notifications are not responded to.
+	if msg == nil {
+		return
+	}
+
 	// Write the response.
 	err = s.writeHTTPResponseHeaders(r, w.Header(), http.StatusOK, buf)
 	if err != nil {
@@ -4340,6 +5523,14 @@ type rpcserverConnManager interface {
 	// ConnectedCount returns the number of currently connected peers.
 	ConnectedCount() int32
 
+	// PeerLimits returns the maximum number of inbound and outbound peers,
+	// along with the number of peers currently connected.
+	PeerLimits() peerLimits
+
+	// SetPeerLimits changes the maximum number of inbound and outbound
+	// peers, disconnecting peers over the new limits.
+	SetPeerLimits(maxInbound, maxOutbound int)
+
 	// NetTotals returns the sum of all bytes received and sent across the
 	// network for all peers.
 	NetTotals() (uint64, uint64)
@@ -4472,6 +5663,7 @@ func newRPCServer(config *rpcserverConfig) (*rpcServer, error) {
 		cfg:                    *config,
 		statusLines:            make(map[int]string),
 		gbtWorkState:           newGbtWorkState(config.TimeSource),
+		generateTokens:         newGenerateTokens(),
 		helpCacher:             newHelpCacher(),
 		requestProcessShutdown: make(chan struct{}),
 		quit:                   make(chan int),
@@ -4518,6 +5710,10 @@ func (s *rpcServer) handleBlockchainNotification(notification *blockdag.Notifica
 		// Notify registered websocket clients of incoming block.
 		s.ntfnMgr.NotifyBlockConnected(block)
 
+		// Notify registered websocket clients of the dag tips the
+		// block changed, after the block itself.
+		s.ntfnMgr.NotifyDagTipsChanged(s.cfg.Chain.DAGSnapshot())
+
 	case blockdag.NTBlockDisconnected:
 		block, ok := notification.Data.(*soterutil.Block)
 		if !ok {
@@ -4527,6 +5723,17 @@ func (s *rpcServer) handleBlockchainNotification(notification *blockdag.Notifica
 
 		// Notify registered websocket clients.
 		s.ntfnMgr.NotifyBlockDisconnected(block)
+
+	case blockdag.NTBlockReordered:
+		reordered, ok := notification.Data.(*blockdag.ReorderedBlocks)
+		if !ok {
+			rpcsLog.Warnf("Chain reordered notification is not a " +
+				"set of reordered blocks.")
+			break
+		}
+
+		// Notify registered websocket clients.
+		s.ntfnMgr.NotifyBlockReordered(reordered)
 	}
 }
 
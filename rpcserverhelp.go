@@ -9,9 +9,11 @@ package main
 import (
 	"errors"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
+	"github.com/soteria-dag/soterd/blockdag"
 	"github.com/soteria-dag/soterd/soterjson"
 )
 
@@ -45,6 +47,16 @@ var helpDescsEnUS = map[string]string{
 	"transactioninput-txid": "The hash of the input transaction",
 	"transactioninput-vout": "The specific output of the input transaction to redeem",
 
+	// CheckPruneDagCmd help.
+	"checkprunedag--synopsis": "Checks whether the blocks below the given height could be pruned, without removing anything. " +
+		"The prune is refused when a block above the height references a parent below it, or when a dag tip is below the height. " +
+		"The block database doesn't support removing blocks, so the node never prunes.",
+	"checkprunedag-height": "The height to check pruning the blocks below at",
+
+	// ClearOrphansCmd help.
+	"clearorphans--synopsis": "Removes all the blocks from the orphan pool.",
+	"clearorphans--result0":  "The number of orphan blocks removed",
+
 	// CreateRawTransactionCmd help.
 	"createrawtransaction--synopsis": "Returns a new transaction spending the provided inputs and sending to the provided addresses.\n" +
 		"The transaction inputs are not signed in the created transaction.\n" +
@@ -116,6 +128,23 @@ var helpDescsEnUS = map[string]string{
 	"decodescript--synopsis": "Returns a JSON object with information about the provided hex-encoded script.",
 	"decodescript-hexscript": "Hex-encoded script",
 
+	// DecodeDagHeaderResult help.
+	"decodedagheaderresult-hash":              "The hash of the block",
+	"decodedagheaderresult-version":           "The block version",
+	"decodedagheaderresult-versionHex":        "The block version in hexadecimal",
+	"decodedagheaderresult-previousblockhash": "The hash of the tips the block references",
+	"decodedagheaderresult-merkleroot":        "Root hash of the merkle tree",
+	"decodedagheaderresult-time":              "The block time in seconds since 1 Jan 1970 GMT",
+	"decodedagheaderresult-bits":              "The bits which represent the block difficulty",
+	"decodedagheaderresult-nonce":             "The block nonce",
+	"decodedagheaderresult-parentversion":     "The version of the parents sub-header",
+	"decodedagheaderresult-parents":           "The hashes of the parents of the block",
+
+	// DecodeDagHeaderCmd help.
+	"decodedagheader--synopsis": "Returns a JSON object representing the provided serialized, hex-encoded dag block header, which is the block header followed by the parents sub-header.\n" +
+		"Data following the parents sub-header, like the transactions of a serialized block, is ignored.",
+	"decodedagheader-hexheader": "Serialized, hex-encoded dag block header",
+
 	// EstimateFeeCmd help.
 	"estimatefee--synopsis": "Estimate the fee per kilobyte in nanoSoter " +
 		"required for a transaction to be mined before a certain number of " +
@@ -123,7 +152,22 @@ var helpDescsEnUS = map[string]string{
 	"estimatefee-numblocks": "The maximum number of blocks which can be " +
 		"generated before the transaction is mined.",
 	"estimatefee--result0": "Estimated fee per kilobyte in nanoSoter for a block to " +
-		"be mined in the next NumBlocks blocks.",
+		"be mined in the next NumBlocks blocks, or -1 if there isn't " +
+		"enough data for an estimate.",
+
+	// EstimateSmartFeeCmd help.
+	"estimatesmartfee--synopsis": "Estimate the fee per kilobyte required for " +
+		"a transaction to be mined within a number of blocks. Blocks are " +
+		"counted by depth in the dag ordering. When there isn't enough data " +
+		"for the target, the estimate is for the first higher number of " +
+		"blocks that there is data for.",
+	"estimatesmartfee-conftarget": "The number of blocks which can be " +
+		"generated before the transaction is mined.",
+
+	// EstimateSmartFeeResult help.
+	"estimatesmartfeeresult-feerate": "Estimated fee per kilobyte, if there is enough data for an estimate",
+	"estimatesmartfeeresult-errors":  "Errors encountered while estimating",
+	"estimatesmartfeeresult-blocks":  "The number of blocks the estimate is for",
 
 	// GenerateCmd help
 	"generate--synopsis": "Generates a set number of blocks (simnet or regtest only) and returns a JSON\n" +
@@ -131,6 +175,24 @@ var helpDescsEnUS = map[string]string{
 	"generate-numblocks": "Number of blocks to generate",
 	"generate--result0":  "The hashes, in order, of blocks generated by the call",
 
+	// GenerateIdempotentCmd help
+	"generateidempotent--synopsis": "Generates a set number of blocks (simnet or regtest only) like generate, unless the\n" +
+		" token was already used within the last 10 minutes, and returns a JSON array of their hashes.\n" +
+		" Repeating a request with the same token returns the blocks generated for the first request, waiting for them\n" +
+		" if they're still being generated, so that retries don't generate more blocks. Using the token for a different\n" +
+		" number of blocks is an error, and failed requests aren't remembered.",
+	"generateidempotent-token":     "A unique identifier of the request, chosen by the client",
+	"generateidempotent-numblocks": "Number of blocks to generate",
+	"generateidempotent--result0":  "The hashes, in order, of blocks generated for the token",
+
+	// GenerateWithParentsCmd help
+	"generatewithparents--synopsis": "Generates a set number of blocks (simnet or regtest only), the first of which\n" +
+		" references the given parents instead of all of the dag tips, and returns a JSON array of their hashes.\n" +
+		" Each following block references only the block generated before it.",
+	"generatewithparents-numblocks": "Number of blocks to generate",
+	"generatewithparents-parents":   "The hashes of the parents of the first generated block, each of which must be a current dag tip, up to the max block parents of the network",
+	"generatewithparents--result0":  "The hashes, in order, of blocks generated by the call",
+
 	// GetAddedNodeInfoResultAddr help.
 	"getaddednodeinforesultaddr-address":   "The ip address for this DNS entry",
 	"getaddednodeinforesultaddr-connected": "The connection 'direction' (inbound/outbound/false)",
@@ -154,6 +216,18 @@ var helpDescsEnUS = map[string]string{
 	// GetAddrCacheResult help.
 	"getaddrcacheresult-addresses": "A list of address strings in ip:port format",
 
+	// GetAnticoneCmd help.
+	"getanticone--synopsis": "Returns the anticone of a block, which is the blocks of the dag that are neither ancestors nor descendants of it. " +
+		"The blocks are sorted by height and then hash.",
+	"getanticone-hash":  "The hash of the block",
+	"getanticone-limit": "The maximum number of hashes to return, or 0 for the server's maximum of 1000 hashes",
+
+	// GetAnticoneResult help.
+	"getanticoneresult-hash":      "The hash of the block",
+	"getanticoneresult-size":      "The number of blocks in the anticone",
+	"getanticoneresult-anticone":  "The hashes of the blocks in the anticone, sorted by height and then hash",
+	"getanticoneresult-truncated": "Whether the anticone holds more blocks than were returned",
+
 	// GetBestBlockResult help.
 	"getbestblockresult-hash":   "Hex-encoded bytes of the best block hash",
 	"getbestblockresult-height": "Height of the best block",
@@ -209,6 +283,18 @@ var helpDescsEnUS = map[string]string{
 	// GetListenAddrsResult help.
 	"getlistenaddrsresult-p2p": "A list of address strings in ip:port format",
 
+	// GetTransactionStatusCmd help.
+	"gettransactionstatus--synopsis": "Returns whether the transaction is in a block of the DAG, in the memory pool, or unknown to the node.\n" +
+		"Transactions in blocks are only found with the transaction index enabled (--txindex).",
+	"gettransactionstatus-txid":     "The hash of the transaction",
+	"gettransactionstatus--result0": "The status of the transaction (confirmed, mempool, or unknown)",
+
+	// GetTxConfirmationsCmd help.
+	"gettxconfirmations--synopsis": "Returns the number of blocks after the block containing the transaction in the DAG ordering.\n" +
+		"The result is -1 when the transaction is in the memory pool, or in a block outside of the blue set of the DAG.",
+	"gettxconfirmations-txid":     "The hash of the transaction",
+	"gettxconfirmations--result0": "The number of blocks after the block containing the transaction in the DAG ordering, or -1 if the transaction isn't in a blue block",
+
 	// SoftForkDescription help.
 	"softforkdescription-reject":  "The current activation status of the softfork",
 	"softforkdescription-version": "The block version that signals enforcement of this softfork",
@@ -264,6 +350,13 @@ var helpDescsEnUS = map[string]string{
 	"getblockverboseresult-strippedsize":      "The size of the block without witness data",
 	"getblockverboseresult-weight":            "The weight of the block",
 	"getblockverboseresult-parents":           "The parents of the block, if any",
+	"getblockverboseresult-work":              "The proof-of-work of the block, in hex",
+	"getblockverboseresult-dagwork":           "The cumulative work of the blue blocks in the past of the block, including the block itself if it is blue, in hex",
+
+	// GetDagBlockHashesCmd help.
+	"getdagblockhashes--synopsis": "Returns the hashes of all blocks at the given height, sorted by the DAG ordering.",
+	"getdagblockhashes-height":    "The block height",
+	"getdagblockhashes--result0":  "The block hashes",
 
 	// GetDAGColoring
 	"getdagcoloring--synopsis": "Returns the current DAG block coloring and order",
@@ -271,9 +364,51 @@ var helpDescsEnUS = map[string]string{
 	// GetDAGColoringResult help
 	"getdagcoloringresult-hash": "Block hash",
 	"getdagcoloringresult-isblue": "True is block is in the blue set of the DAG coloring",
+	"getdagcoloringresult-order": "Index of the block in the DAG ordering, starting from 0 for the genesis block",
+
+	// GetDagHashPSCmd help.
+	"getdaghashps--synopsis": "Returns the estimated network hashes per second over a window of the DAG ordering. The window is the last blocks in the DAG ordering with a height of at most the given height, so blocks at the same height are included in DAG order.",
+	"getdaghashps-blocks":    "The number of blocks in the window, or -1 for the number of blocks in a difficulty retarget interval",
+	"getdaghashps-height":    "Perform estimate ending with this height or -1 for the max height of the DAG",
+	"getdaghashps--result0":  "Estimated hashes per second",
+
+	// GetDagInfoCmd help.
+	"getdaginfo--synopsis": "Returns a summary of the state of the DAG.",
+
+	// GetDagInfoResult help.
+	"getdaginforesult-tipcount":        "The number of dag tips",
+	"getdaginforesult-blkcount":        "The number of blocks in the dag",
+	"getdaginforesult-maxheight":       "The maximum height of the blocks in tips",
+	"getdaginforesult-bluesetsize":     "The number of blocks in the blue set of the dag",
+	"getdaginforesult-orphancount":     "The number of orphan blocks held by the node, which aren't part of the dag yet",
+	"getdaginforesult-maxblockparents": "The maximum number of parents a block may reference on the network",
+
+	// GetDagOrderHashCmd help.
+	"getdagorderhash--synopsis": "Returns the hash of the block at a position of the linear ordering of the dag, and the length of the ordering.\n" +
+		"New blocks may change which blocks hold the positions at the end of the ordering. " +
+		"The positions held by blocks at least " + strconv.Itoa(blockdag.OrderStableDepth) + " generations below the highest block of the dag are considered stable, since new blocks are expected to reference parents within that many generations.",
+	"getdagorderhash-index": "The position in the dag ordering, starting at 0 for the genesis block",
+
+	// GetDagOrderHashResult help.
+	"getdagorderhashresult-hash":         "The hash of the block at the position",
+	"getdagorderhashresult-index":        "The position in the dag ordering",
+	"getdagorderhashresult-length":       "The number of blocks in the dag ordering",
+	"getdagorderhashresult-stablelength": "The number of positions at the start of the dag ordering that are considered stable",
+	"getdagorderhashresult-stable":       "Whether the position is considered stable",
+
+	// GetDagPathCmd help.
+	"getdagpath--synopsis": "Returns a shortest path of parent links from a block to one of its ancestors. " +
+		"Each block in the path is a parent of the block before it, and when several paths are the shortest, " +
+		"parents are followed in the order the block references them.",
+	"getdagpath-from":     "The hash of the block the path starts at",
+	"getdagpath-to":       "The hash of the ancestor the path ends at",
+	"getdagpath--result0": "The hashes of the blocks in the path, including both ends, or an empty array if the second block isn't an ancestor of the first",
 
 	// GetDAGTips
-	"getdagtips--synopsis": "Returns current DAG tip info",
+	"getdagtips--synopsis": "Returns current DAG tip info. The tips are returned in pages, sorted by height and then hash, " +
+		"and the other fields describe the whole dag.",
+	"getdagtips-offset": "The index of the first tip to return",
+	"getdagtips-limit":  "The maximum number of tips to return, or 0 for the server's maximum of 1000 tips",
 
 	// GetDAGTipsResult help.
 	"getdagtipsresult-tips":	"The hashes of the dag tips",
@@ -281,6 +416,29 @@ var helpDescsEnUS = map[string]string{
 	"getdagtipsresult-minheight":	"The minimum height of the blocks in tips",
 	"getdagtipsresult-maxheight":	"The maximum height of the blocks in tips",
 	"getdagtipsresult-blkcount":	"The number of blocks in dag",
+	"getdagtipsresult-tipinfo":	"The details of each dag tip, sorted by height and then hash",
+	"getdagtipsresult-dagwork":	"The cumulative work of the blocks in the blue set of the dag, in hex",
+	"getdagtipsresult-tipcount":	"The number of dag tips, across all pages",
+	"getdagtipsresult-nextoffset":	"The offset of the next page of tips, omitted on the last page",
+
+	// GetDAGTipStatusCmd help.
+	"getdagtipstatus--synopsis": "Returns the tips of the branches of blocks known to the node, sorted by height and then hash, and how each relates to the dag. " +
+		"The tips are the tips of the dag, the orphan blocks no other orphan builds on, and the blocks that failed validation.",
+
+	// GetDAGTipStatusResult help.
+	"getdagtipstatusresult-hash":      "The hash of the tip block",
+	"getdagtipstatusresult-height":    "The height of the tip block, or -1 for an orphan whose height isn't known",
+	"getdagtipstatusresult-branchlen": "The number of generations between the tip and its nearest ancestor in the past of the active tips, or for orphans, the number of generations of orphans ending at the tip",
+	"getdagtipstatusresult-status": "The status of the tip: " +
+		"active (a tip of the dag that new blocks reference), " +
+		"valid-fork (a valid tip of the dag that new blocks don't reference, because there are more tips than a block may reference), " +
+		"valid-headers (an orphan block that passed the checks that don't depend on its parents, but whose parents aren't all known), " +
+		"or invalid (a block that failed validation)",
+
+	// DAGTip help.
+	"dagtip-hash":   "The hash of the tip block",
+	"dagtip-height": "The height of the tip block",
+	"dagtip-work":   "The work represented by the tip block, as a hex string",
 
 	// DAGParent
 	"dagparent-hash":          "The hash of the parent in the DAG",
@@ -292,7 +450,7 @@ var helpDescsEnUS = map[string]string{
 	"getblockcount--result0":  "The current block count",
 
 	// GetBlockHashCmd help.
-	"getblockhash--synopsis": "Returns hash of the block in best block chain at the given height.",
+	"getblockhash--synopsis": "Returns hash of the first block in the DAG ordering at the given height.",
 	"getblockhash-index":     "The block height",
 	"getblockhash--result0":  "The block hash",
 
@@ -317,6 +475,7 @@ var helpDescsEnUS = map[string]string{
 	"getblockheaderverboseresult-difficulty":        "The proof-of-work difficulty as a multiple of the minimum difficulty",
 	"getblockheaderverboseresult-previousblockhash": "The hash of the previous block",
 	"getblockheaderverboseresult-nextblockhashes":   "The hashes of the next blocks (only if there are one or more)",
+	"getblockheaderverboseresult-parents":           "The hashes of the parents of the block (only if there are one or more)",
 
 	// TemplateRequest help.
 	"templaterequest-mode":         "This is 'template', 'proposal', or omitted",
@@ -344,7 +503,9 @@ var helpDescsEnUS = map[string]string{
 	"getblocktemplateresult-bits":                       "Hex-encoded compressed difficulty",
 	"getblocktemplateresult-curtime":                    "Current time as seen by the server (recommended for block time); must fall within mintime/maxtime rules",
 	"getblocktemplateresult-height":                     "Height of the block to be solved",
-	"getblocktemplateresult-previousblockhash":          "Hex-encoded big-endian hash of the previous block",
+	"getblocktemplateresult-previousblockhash":          "Hex-encoded big-endian tips hash of the parents of the block",
+	"getblocktemplateresult-parents":                    "Hex-encoded big-endian hashes of the dag tips the block must reference as its parents, in the order they must appear in the block",
+	"getblocktemplateresult-parentversion":              "The version of the parent sub-header of the block",
 	"getblocktemplateresult-sigoplimit":                 "Number of sigops allowed in blocks ",
 	"getblocktemplateresult-sizelimit":                  "Number of bytes allowed in blocks",
 	"getblocktemplateresult-transactions":               "Array of transactions as JSON objects",
@@ -362,7 +523,7 @@ var helpDescsEnUS = map[string]string{
 	"getblocktemplateresult-mintime":                    "Minimum allowed time",
 	"getblocktemplateresult-mutable":                    "List of mutations the server explicitly allows",
 	"getblocktemplateresult-noncerange":                 "Two concatenated hex-encoded big-endian 32-bit integers which represent the valid ranges of nonces the miner may scan",
-	"getblocktemplateresult-capabilities":               "List of server capabilities including 'proposal' to indicate support for block proposals",
+	"getblocktemplateresult-capabilities":               "List of server capabilities including 'proposal' to indicate support for block proposals, and 'parents' to indicate the template lists the parents of the block",
 	"getblocktemplateresult-reject-reason":              "Reason the proposal was invalid as-is (only applies to proposal responses)",
 	"getblocktemplateresult-default_witness_commitment": "The witness commitment itself. Will be populated if the block has witness data",
 	"getblocktemplateresult-weightlimit":                "The current limit on the max allowed weight of a block",
@@ -451,11 +612,12 @@ var helpDescsEnUS = map[string]string{
 	"getmempoolinfo--synopsis": "Returns memory pool information",
 
 	// GetMempoolInfoResult help.
-	"getmempoolinforesult-bytes": "Size in bytes of the mempool",
-	"getmempoolinforesult-size":  "Number of transactions in the mempool",
+	"getmempoolinforesult-bytes":         "Size in bytes of the mempool",
+	"getmempoolinforesult-size":          "Number of transactions in the mempool",
+	"getmempoolinforesult-mempoolminfee": "Minimum fee in SOTO/kB for a transaction to be accepted into the mempool",
 
 	// GetMiningInfoResult help.
-	"getmininginforesult-blocks":             "Height of the latest best block",
+	"getmininginforesult-blocks":             "Number of blocks in the dag",
 	"getmininginforesult-currentblocksize":   "Size of the latest best block",
 	"getmininginforesult-currentblockweight": "Weight of the latest best block",
 	"getmininginforesult-currentblocktx":     "Number of transactions in the latest best block",
@@ -467,6 +629,10 @@ var helpDescsEnUS = map[string]string{
 	"getmininginforesult-networkhashps":      "Estimated network hashes per second for the most recent blocks",
 	"getmininginforesult-pooledtx":           "Number of transactions in the memory pool",
 	"getmininginforesult-testnet":            "Whether or not server is using testnet",
+	"getmininginforesult-maxheight":          "Height of the highest blocks in the dag",
+	"getmininginforesult-orderlength":        "Number of blocks in the dag ordering",
+	"getmininginforesult-tipcount":           "Number of tips of the dag",
+	"getmininginforesult-parents":            "Hashes of the tips that the next block should reference as parents",
 
 	// GetMiningInfoCmd help.
 	"getmininginfo--synopsis": "Returns a JSON object containing mining-related information.",
@@ -485,6 +651,18 @@ var helpDescsEnUS = map[string]string{
 	"getnettotalsresult-totalbytessent": "Total bytes sent",
 	"getnettotalsresult-timemillis":     "Number of milliseconds since 1 Jan 1970 GMT",
 
+	// GetOrphanBlocksResult help.
+	"getorphanblocksresult-total":   "The number of blocks in the orphan pool",
+	"getorphanblocksresult-orphans": "The orphan blocks, starting from the one that expires first. At most 100 are returned",
+
+	// OrphanBlock help.
+	"orphanblock-hash":           "The hash of the orphan block",
+	"orphanblock-missingparents": "The hashes of the parents of the block that aren't in the dag",
+	"orphanblock-expiration":     "When the block is evicted from the orphan pool, in seconds since 1 Jan 1970 GMT",
+
+	// GetOrphanBlocksCmd help.
+	"getorphanblocks--synopsis": "Returns the blocks in the orphan pool, which are waiting for their parents to arrive.",
+
 	// GetPeerInfoResult help.
 	"getpeerinforesult-id":             "A unique node ID",
 	"getpeerinforesult-addr":           "The ip address and port of the peer",
@@ -511,6 +689,34 @@ var helpDescsEnUS = map[string]string{
 	// GetPeerInfoCmd help.
 	"getpeerinfo--synopsis": "Returns data about each connected network peer as an array of json objects.",
 
+	// GetPeerLimitsCmd help.
+	"getpeerlimits--synopsis": "Returns the maximum number of inbound and outbound peers, and the number of peers currently connected.",
+
+	// GetPeerLimitsResult help.
+	"getpeerlimitsresult-maxinbound":  "The maximum number of inbound peers",
+	"getpeerlimitsresult-maxoutbound": "The maximum number of outbound peers",
+	"getpeerlimitsresult-inbound":     "The number of connected inbound peers",
+	"getpeerlimitsresult-outbound":    "The number of connected outbound peers, including persistent peers",
+
+	// GetPruneInfoResult help.
+	"getpruneinforesult-pruned":           "Whether the node prunes blocks. The block database doesn't support removing blocks, so this is always false",
+	"getpruneinforesult-pruneheight":      "The height of the lowest block retained by the node",
+	"getpruneinforesult-maxpruneheight":   "The highest height that blocks below could be pruned at, without removing parents of the remaining blocks",
+	"getpruneinforesult-reclaimablebytes": "The serialized size in bytes of the blocks below the max prune height",
+
+	// GetPruneInfoCmd help.
+	"getpruneinfo--synopsis": "Returns the pruning state of the node, and how much could be reclaimed by pruning.",
+
+	// GetRelayPolicyResult help.
+	"getrelaypolicyresult-acceptnonstd":         "Whether non-standard transactions are accepted and relayed",
+	"getrelaypolicyresult-maxopreturnsize":      "The maximum number of bytes of data an OP_RETURN output may carry for the transaction to be standard",
+	"getrelaypolicyresult-maxtxversion":         "The highest transaction version that is standard",
+	"getrelaypolicyresult-minrelaytxfee":        "The minimum fee rate in SOTO/kB for a transaction to be considered to have a non-zero fee",
+	"getrelaypolicyresult-disablerelaypriority": "Whether free or low-fee transactions are relayed without needing a high priority",
+
+	// GetRelayPolicyCmd help.
+	"getrelaypolicy--synopsis": "Returns the policy the node accepts transactions into its memory pool and relays them with.",
+
 	// GetRawMempoolVerboseResult help.
 	"getrawmempoolverboseresult-size":             "Transaction size in bytes",
 	"getrawmempoolverboseresult-fee":              "Transaction fee in soter tokens",
@@ -568,6 +774,16 @@ var helpDescsEnUS = map[string]string{
 	// RenderDagResult help.
 	"renderdagresult-dot": "The graphviz DOT file contents",
 
+	// ResubmitTransactionCmd help.
+	"resubmittransaction--synopsis": "Submits the serialized, hex-encoded transaction like sendrawtransaction, except that a transaction that's already in the memory pool is relayed to the network again instead of being rejected.\n" +
+		"Peers that already know about the transaction aren't sent it again. A transaction that's already in a block of the DAG is rejected with an error, found the same way as gettransactionstatus finds it when the transaction index is enabled (--txindex).",
+	"resubmittransaction-hextx": "Serialized, hex-encoded signed transaction",
+
+	// ResubmitTransactionResult help.
+	"resubmittransactionresult-txid":        "The hash of the transaction",
+	"resubmittransactionresult-rebroadcast": "Whether the transaction was already in the memory pool",
+	"resubmittransactionresult-peers":       "The number of connected peers the transaction was queued to be relayed to. Peers that already know about the transaction skip it, and peers don't acknowledge relayed transactions, so they may still reject it",
+
 	// SearchRawTransactionsCmd help.
 	"searchrawtransactions--synopsis": "Returns raw data for transactions involving the passed address.\n" +
 		"Returned transactions are pulled from both the database, and transactions currently in the mempool.\n" +
@@ -596,6 +812,19 @@ var helpDescsEnUS = map[string]string{
 	"setgenerate-generate":     "Use true to enable generation, false to disable it",
 	"setgenerate-genproclimit": "The number of processors (cores) to limit generation to or -1 for default",
 
+	// SetMaxPeersCmd help.
+	"setmaxpeers--synopsis": "Set the maximum number of inbound and outbound peers.\n" +
+		"When a limit is lowered below the number of connected peers, the most recently connected peers are disconnected.\n" +
+		"Persistent peers are never disconnected, but count towards the outbound peers.",
+	"setmaxpeers-inbound":  "The maximum number of inbound peers",
+	"setmaxpeers-outbound": "The maximum number of outbound peers",
+
+	// SignMessageWithPrivKeyCmd help.
+	"signmessagewithprivkey--synopsis": "Sign a message with the private key of an address.",
+	"signmessagewithprivkey-privkey":   "The private key to sign the message with, in wallet import format (WIF)",
+	"signmessagewithprivkey-message":   "The message to sign",
+	"signmessagewithprivkey--result0":  "The base-64 encoded signature of the message",
+
 	// StopCmd help.
 	"stop--synopsis": "Shutdown soterd.",
 	"stop--result0":  "The string 'soterd stopping.'",
@@ -611,6 +840,16 @@ var helpDescsEnUS = map[string]string{
 	"submitblock--condition1": "Block rejected",
 	"submitblock--result1":    "The reason the block was rejected",
 
+	// TestBlockAcceptanceCmd help.
+	"testblockacceptance--synopsis": "Fully validates a serialized, hex-encoded block as if it was submitted, without adding it to the dag or relaying it.",
+	"testblockacceptance-hexblock":  "Serialized, hex-encoded block",
+
+	// TestBlockAcceptanceResult help.
+	"testblockacceptanceresult-hash":   "The hash of the block",
+	"testblockacceptanceresult-status": "Whether the block would be accepted, rejected, or added to the orphan pool because some of its parents are unknown (accepted, rejected or orphan)",
+	"testblockacceptanceresult-reason": "The reason the block would be rejected or orphaned",
+	"testblockacceptanceresult-height": "The height the block would have in the dag, when all of its parents are known",
+
 	// ValidateAddressResult help.
 	"validateaddresschainresult-isvalid": "Whether or not the address is valid",
 	"validateaddresschainresult-address": "The soter address (only when isvalid is true)",
@@ -648,6 +887,12 @@ var helpDescsEnUS = map[string]string{
 	// StopNotifyBlocksCmd help.
 	"stopnotifyblocks--synopsis": "Cancel registered notifications for whenever a block is connected or disconnected from the main (best) chain.",
 
+	// NotifyDagTipsCmd help.
+	"notifydagtips--synopsis": "Request notifications for whenever the set of dag tips changes.",
+
+	// StopNotifyDagTipsCmd help.
+	"stopnotifydagtips--synopsis": "Cancel registered notifications for whenever the set of dag tips changes.",
+
 	// NotifyNewTransactionsCmd help.
 	"notifynewtransactions--synopsis": "Send either a txaccepted or a txacceptedverbose notification when a new transaction is accepted into the mempool.",
 	"notifynewtransactions-verbose":   "Specifies which type of notification to receive. If verbose is true, then the caller receives txacceptedverbose, otherwise the caller receives txaccepted",
@@ -724,64 +969,89 @@ var helpDescsEnUS = map[string]string{
 // This information is used to generate the help.  Each result type must be a
 // pointer to the type (or nil to indicate no return value).
 var rpcResultTypes = map[string][]interface{}{
-	"addnode":               nil,
-	"createrawtransaction":  {(*string)(nil)},
-	"debuglevel":            {(*string)(nil), (*string)(nil)},
-	"decoderawtransaction":  {(*soterjson.TxRawDecodeResult)(nil)},
-	"decodescript":          {(*soterjson.DecodeScriptResult)(nil)},
-	"estimatefee":           {(*float64)(nil)},
-	"generate":              {(*[]string)(nil)},
-	"getaddednodeinfo":      {(*[]string)(nil), (*[]soterjson.GetAddedNodeInfoResult)(nil)},
-	"getaddrcache":          {(*soterjson.GetAddrCacheResult)(nil)},
-	"getbestblock":          {(*soterjson.GetBestBlockResult)(nil)},
-	"getbestblockhash":      {(*string)(nil)},
-	"getblock":              {(*string)(nil), (*soterjson.GetBlockVerboseResult)(nil)},
-	"getblockcount":         {(*int64)(nil)},
-	"getblockhash":          {(*string)(nil)},
-	"getblockheader":        {(*string)(nil), (*soterjson.GetBlockHeaderVerboseResult)(nil)},
-	"getblocktemplate":      {(*soterjson.GetBlockTemplateResult)(nil), (*string)(nil), nil},
-	"getblockmetrics":       {(*soterjson.GetBlockMetricsResult)(nil)},
-	"getblockchaininfo":     {(*soterjson.GetBlockChainInfoResult)(nil)},
-	"getcfilter":            {(*string)(nil)},
-	"getcfilterheader":      {(*string)(nil)},
-	"getconnectioncount":    {(*int32)(nil)},
-	"getcurrentnet":         {(*uint32)(nil)},
-	"getdagcoloring":    	 {(*[]soterjson.GetDAGColoringResult)(nil)},
-	"getdagtips":     		 {(*soterjson.GetDAGTipsResult)(nil)},
-	"getdifficulty":         {(*float64)(nil)},
-	"getgenerate":           {(*bool)(nil)},
-	"gethashespersec":       {(*float64)(nil)},
-	"getheaders":            {(*[]string)(nil)},
-	"getlistenaddrs":        {(*soterjson.GetListenAddrsResult)(nil)},
-	"getinfo":               {(*soterjson.InfoChainResult)(nil)},
-	"getmempoolinfo":        {(*soterjson.GetMempoolInfoResult)(nil)},
-	"getmininginfo":         {(*soterjson.GetMiningInfoResult)(nil)},
-	"getnettotals":          {(*soterjson.GetNetTotalsResult)(nil)},
-	"getnetworkhashps":      {(*int64)(nil)},
-	"getpeerinfo":           {(*[]soterjson.GetPeerInfoResult)(nil)},
-	"getrawmempool":         {(*[]string)(nil), (*soterjson.GetRawMempoolVerboseResult)(nil)},
-	"getrawtransaction":     {(*string)(nil), (*soterjson.TxRawResult)(nil)},
-	"gettxout":              {(*soterjson.GetTxOutResult)(nil)},
-	"node":                  nil,
-	"help":                  {(*string)(nil), (*string)(nil)},
-	"ping":                  nil,
-	"renderdag":             {(*soterjson.RenderDagResult)(nil)},
-	"searchrawtransactions": {(*string)(nil), (*[]soterjson.SearchRawTransactionsResult)(nil)},
-	"sendrawtransaction":    {(*string)(nil)},
-	"setgenerate":           nil,
-	"stop":                  {(*string)(nil)},
-	"submitblock":           {nil, (*string)(nil)},
-	"uptime":                {(*int64)(nil)},
-	"validateaddress":       {(*soterjson.ValidateAddressChainResult)(nil)},
-	"verifychain":           {(*bool)(nil)},
-	"verifymessage":         {(*bool)(nil)},
-	"version":               {(*map[string]soterjson.VersionResult)(nil)},
+	"addnode":                nil,
+	"checkprunedag":          nil,
+	"clearorphans":           {(*int)(nil)},
+	"createrawtransaction":   {(*string)(nil)},
+	"debuglevel":             {(*string)(nil), (*string)(nil)},
+	"decoderawtransaction":   {(*soterjson.TxRawDecodeResult)(nil)},
+	"decodescript":           {(*soterjson.DecodeScriptResult)(nil)},
+	"decodedagheader":        {(*soterjson.DecodeDagHeaderResult)(nil)},
+	"estimatefee":            {(*float64)(nil)},
+	"estimatesmartfee":       {(*soterjson.EstimateSmartFeeResult)(nil)},
+	"generate":               {(*[]string)(nil)},
+	"generateidempotent":     {(*[]string)(nil)},
+	"generatewithparents":    {(*[]string)(nil)},
+	"getaddednodeinfo":       {(*[]string)(nil), (*[]soterjson.GetAddedNodeInfoResult)(nil)},
+	"getaddrcache":           {(*soterjson.GetAddrCacheResult)(nil)},
+	"getanticone":            {(*soterjson.GetAnticoneResult)(nil)},
+	"getbestblock":           {(*soterjson.GetBestBlockResult)(nil)},
+	"getbestblockhash":       {(*string)(nil)},
+	"getblock":               {(*string)(nil), (*soterjson.GetBlockVerboseResult)(nil)},
+	"getblockcount":          {(*int64)(nil)},
+	"getblockhash":           {(*string)(nil)},
+	"getblockheader":         {(*string)(nil), (*soterjson.GetBlockHeaderVerboseResult)(nil)},
+	"getblocktemplate":       {(*soterjson.GetBlockTemplateResult)(nil), (*string)(nil), nil},
+	"getblockmetrics":        {(*soterjson.GetBlockMetricsResult)(nil)},
+	"getblockchaininfo":      {(*soterjson.GetBlockChainInfoResult)(nil)},
+	"getcfilter":             {(*string)(nil)},
+	"getcfilterheader":       {(*string)(nil)},
+	"getconnectioncount":     {(*int32)(nil)},
+	"getcurrentnet":          {(*uint32)(nil)},
+	"getdagblockhashes":      {(*[]string)(nil)},
+	"getdagcoloring":         {(*[]soterjson.GetDAGColoringResult)(nil)},
+	"getdaghashps":           {(*int64)(nil)},
+	"getdaginfo":             {(*soterjson.GetDagInfoResult)(nil)},
+	"getdagorderhash":        {(*soterjson.GetDagOrderHashResult)(nil)},
+	"getdagpath":             {(*[]string)(nil)},
+	"getdagtips":             {(*soterjson.GetDAGTipsResult)(nil)},
+	"getdagtipstatus":        {(*[]soterjson.GetDAGTipStatusResult)(nil)},
+	"getdifficulty":          {(*float64)(nil)},
+	"getgenerate":            {(*bool)(nil)},
+	"gethashespersec":        {(*float64)(nil)},
+	"getheaders":             {(*[]string)(nil)},
+	"getlistenaddrs":         {(*soterjson.GetListenAddrsResult)(nil)},
+	"getinfo":                {(*soterjson.InfoChainResult)(nil)},
+	"getmempoolinfo":         {(*soterjson.GetMempoolInfoResult)(nil)},
+	"getmininginfo":          {(*soterjson.GetMiningInfoResult)(nil)},
+	"getnettotals":           {(*soterjson.GetNetTotalsResult)(nil)},
+	"getnetworkhashps":       {(*int64)(nil)},
+	"getorphanblocks":        {(*soterjson.GetOrphanBlocksResult)(nil)},
+	"getpeerinfo":            {(*[]soterjson.GetPeerInfoResult)(nil)},
+	"getpeerlimits":          {(*soterjson.GetPeerLimitsResult)(nil)},
+	"getpruneinfo":           {(*soterjson.GetPruneInfoResult)(nil)},
+	"getrelaypolicy":         {(*soterjson.GetRelayPolicyResult)(nil)},
+	"getrawmempool":          {(*[]string)(nil), (*soterjson.GetRawMempoolVerboseResult)(nil)},
+	"getrawtransaction":      {(*string)(nil), (*soterjson.TxRawResult)(nil)},
+	"gettransactionstatus":   {(*string)(nil)},
+	"gettxconfirmations":     {(*int64)(nil)},
+	"gettxout":               {(*soterjson.GetTxOutResult)(nil)},
+	"node":                   nil,
+	"help":                   {(*string)(nil), (*string)(nil)},
+	"ping":                   nil,
+	"renderdag":              {(*soterjson.RenderDagResult)(nil)},
+	"resubmittransaction":    {(*soterjson.ResubmitTransactionResult)(nil)},
+	"searchrawtransactions":  {(*string)(nil), (*[]soterjson.SearchRawTransactionsResult)(nil)},
+	"sendrawtransaction":     {(*string)(nil)},
+	"setgenerate":            nil,
+	"setmaxpeers":            nil,
+	"signmessagewithprivkey": {(*string)(nil)},
+	"stop":                   {(*string)(nil)},
+	"submitblock":            {nil, (*string)(nil)},
+	"testblockacceptance":    {(*soterjson.TestBlockAcceptanceResult)(nil)},
+	"uptime":                 {(*int64)(nil)},
+	"validateaddress":        {(*soterjson.ValidateAddressChainResult)(nil)},
+	"verifychain":            {(*bool)(nil)},
+	"verifymessage":          {(*bool)(nil)},
+	"version":                {(*map[string]soterjson.VersionResult)(nil)},
 
 	// Websocket commands.
 	"loadtxfilter":              nil,
 	"session":                   {(*soterjson.SessionResult)(nil)},
 	"notifyblocks":              nil,
 	"stopnotifyblocks":          nil,
+	"notifydagtips":             nil,
+	"stopnotifydagtips":         nil,
 	"notifynewtransactions":     nil,
 	"stopnotifynewtransactions": nil,
 	"notifyreceived":            nil,
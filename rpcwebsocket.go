@@ -68,11 +68,13 @@ var wsHandlersBeforeInit = map[string]wsCommandHandler{
 	"loadtxfilter":              handleLoadTxFilter,
 	"help":                      handleWebsocketHelp,
 	"notifyblocks":              handleNotifyBlocks,
+	"notifydagtips":             handleNotifyDagTips,
 	"notifynewtransactions":     handleNotifyNewTransactions,
 	"notifyreceived":            handleNotifyReceived,
 	"notifyspent":               handleNotifySpent,
 	"session":                   handleSession,
 	"stopnotifyblocks":          handleStopNotifyBlocks,
+	"stopnotifydagtips":         handleStopNotifyDagTips,
 	"stopnotifynewtransactions": handleStopNotifyNewTransactions,
 	"stopnotifyspent":           handleStopNotifySpent,
 	"stopnotifyreceived":        handleStopNotifyReceived,
@@ -228,6 +230,37 @@ func (m *wsNotificationManager) NotifyBlockDisconnected(block *soterutil.Block)
 	}
 }
 
+// NotifyBlockReordered passes the blocks whose color was changed by a block
+// connected to the dag to the notification manager for block notification
+// processing.  It should be called after NotifyBlockConnected for the block.
+func (m *wsNotificationManager) NotifyBlockReordered(reordered *blockdag.ReorderedBlocks) {
+	// As NotifyBlockReordered will be called by the block manager
+	// and the RPC server may no longer be running, use a select
+	// statement to unblock enqueuing the notification once the RPC
+	// server has begun shutting down.
+	select {
+	case m.queueNotification <- (*notificationBlockReordered)(reordered):
+	case <-m.quit:
+	}
+}
+
+// NotifyDagTipsChanged passes the dag state after a block was connected to the
+// notification manager for dag tip notification processing.  It should be
+// called after NotifyBlockConnected for the same block, so that clients
+// receive the block notification before the tip change it caused.
+func (m *wsNotificationManager) NotifyDagTipsChanged(dagState *blockdag.DAGState) {
+	n := &notificationDagTipsChanged{tips: dagState.Tips}
+
+	// As NotifyDagTipsChanged will be called by the block manager
+	// and the RPC server may no longer be running, use a select
+	// statement to unblock enqueuing the notification once the RPC
+	// server has begun shutting down.
+	select {
+	case m.queueNotification <- n:
+	case <-m.quit:
+	}
+}
+
 // NotifyMempoolTx passes a transaction accepted by mempool to the
 // notification manager for transaction notification processing.  If
 // isNew is true, the tx is is a new transaction, rather than one
@@ -449,6 +482,10 @@ func (f *wsClientFilter) removeUnspentOutPoint(op *wire.OutPoint) {
 // Notification types
 type notificationBlockConnected soterutil.Block
 type notificationBlockDisconnected soterutil.Block
+type notificationBlockReordered blockdag.ReorderedBlocks
+type notificationDagTipsChanged struct {
+	tips []chainhash.Hash
+}
 type notificationTxAcceptedByMempool struct {
 	isNew bool
 	tx    *soterutil.Tx
@@ -459,6 +496,8 @@ type notificationRegisterClient wsClient
 type notificationUnregisterClient wsClient
 type notificationRegisterBlocks wsClient
 type notificationUnregisterBlocks wsClient
+type notificationRegisterDagTips wsClient
+type notificationUnregisterDagTips wsClient
 type notificationRegisterNewMempoolTxs wsClient
 type notificationUnregisterNewMempoolTxs wsClient
 type notificationRegisterSpent struct {
@@ -492,10 +531,15 @@ func (m *wsNotificationManager) notificationHandler() {
 	// Where possible, the quit channel is used as the unique id for a client
 	// since it is quite a bit more efficient than using the entire struct.
 	blockNotifications := make(map[chan struct{}]*wsClient)
+	dagTipsNotifications := make(map[chan struct{}]*wsClient)
 	txNotifications := make(map[chan struct{}]*wsClient)
 	watchedOutPoints := make(map[wire.OutPoint]map[chan struct{}]*wsClient)
 	watchedAddrs := make(map[string]map[chan struct{}]*wsClient)
 
+	// The dag tips as of the last processed tip change, used to determine
+	// which tips were added and removed by the next one.
+	dagTips := m.server.cfg.Chain.DAGSnapshot().Tips
+
 out:
 	for {
 		select {
@@ -534,6 +578,22 @@ out:
 						block)
 				}
 
+			case *notificationBlockReordered:
+				if len(blockNotifications) != 0 {
+					m.notifyBlockReordered(blockNotifications,
+						(*blockdag.ReorderedBlocks)(n))
+				}
+
+			case *notificationDagTipsChanged:
+				added, removed := diffDagTips(dagTips, n.tips)
+				dagTips = n.tips
+
+				if len(dagTipsNotifications) != 0 &&
+					(len(added) != 0 || len(removed) != 0) {
+					m.notifyDagTipsChanged(dagTipsNotifications,
+						added, removed)
+				}
+
 			case *notificationTxAcceptedByMempool:
 				if n.isNew && len(txNotifications) != 0 {
 					m.notifyForNewTx(txNotifications, n.tx)
@@ -549,6 +609,14 @@ out:
 				wsc := (*wsClient)(n)
 				delete(blockNotifications, wsc.quit)
 
+			case *notificationRegisterDagTips:
+				wsc := (*wsClient)(n)
+				dagTipsNotifications[wsc.quit] = wsc
+
+			case *notificationUnregisterDagTips:
+				wsc := (*wsClient)(n)
+				delete(dagTipsNotifications, wsc.quit)
+
 			case *notificationRegisterClient:
 				wsc := (*wsClient)(n)
 				clients[wsc.quit] = wsc
@@ -558,6 +626,7 @@ out:
 				// Remove any requests made by the client as well as
 				// the client itself.
 				delete(blockNotifications, wsc.quit)
+				delete(dagTipsNotifications, wsc.quit)
 				delete(txNotifications, wsc.quit)
 				for k := range wsc.spentRequests {
 					op := k
@@ -627,6 +696,18 @@ func (m *wsNotificationManager) UnregisterBlockUpdates(wsc *wsClient) {
 	m.queueNotification <- (*notificationUnregisterBlocks)(wsc)
 }
 
+// RegisterDagTipsUpdates requests dag tip change notifications to the passed
+// websocket client.
+func (m *wsNotificationManager) RegisterDagTipsUpdates(wsc *wsClient) {
+	m.queueNotification <- (*notificationRegisterDagTips)(wsc)
+}
+
+// UnregisterDagTipsUpdates removes dag tip change notifications for the passed
+// websocket client.
+func (m *wsNotificationManager) UnregisterDagTipsUpdates(wsc *wsClient) {
+	m.queueNotification <- (*notificationUnregisterDagTips)(wsc)
+}
+
 // subscribedClients returns the set of all websocket client quit channels that
 // are registered to receive notifications regarding tx, either due to tx
 // spending a watched output or outputting to a watched address.  Matching
@@ -732,6 +813,87 @@ func (*wsNotificationManager) notifyBlockDisconnected(clients map[chan struct{}]
 	}
 }
 
+// notifyBlockReordered notifies websocket clients that have registered for
+// block updates when blocks move between the blue and red sets of the dag.
+func (*wsNotificationManager) notifyBlockReordered(clients map[chan struct{}]*wsClient,
+	reordered *blockdag.ReorderedBlocks) {
+
+	// The notification always carries both lists, even when one of them is
+	// empty.
+	blue := make([]string, 0, len(reordered.Blue))
+	for _, hash := range reordered.Blue {
+		blue = append(blue, hash.String())
+	}
+	red := make([]string, 0, len(reordered.Red))
+	for _, hash := range reordered.Red {
+		red = append(red, hash.String())
+	}
+
+	ntfn := soterjson.NewBlockReorderedNtfn(blue, red)
+	marshalledJSON, err := soterjson.MarshalCmd(nil, ntfn)
+	if err != nil {
+		rpcsLog.Errorf("Failed to marshal block reordered "+
+			"notification: %v", err)
+		return
+	}
+	for _, wsc := range clients {
+		wsc.QueueNotification(marshalledJSON)
+	}
+}
+
+// diffDagTips returns the hashes of the tips in cur that aren't in prev, and
+// of the tips in prev that aren't in cur.
+func diffDagTips(prev, cur []chainhash.Hash) ([]string, []string) {
+	prevSet := make(map[chainhash.Hash]struct{}, len(prev))
+	for _, tip := range prev {
+		prevSet[tip] = struct{}{}
+	}
+	curSet := make(map[chainhash.Hash]struct{}, len(cur))
+	for _, tip := range cur {
+		curSet[tip] = struct{}{}
+	}
+
+	var added, removed []string
+	for _, tip := range cur {
+		if _, ok := prevSet[tip]; !ok {
+			added = append(added, tip.String())
+		}
+	}
+	for _, tip := range prev {
+		if _, ok := curSet[tip]; !ok {
+			removed = append(removed, tip.String())
+		}
+	}
+
+	return added, removed
+}
+
+// notifyDagTipsChanged notifies websocket clients that have registered for
+// dag tip updates when the set of dag tips changes.
+func (*wsNotificationManager) notifyDagTipsChanged(clients map[chan struct{}]*wsClient,
+	added, removed []string) {
+
+	// The notification always carries both lists, even when one of them is
+	// empty.
+	if added == nil {
+		added = []string{}
+	}
+	if removed == nil {
+		removed = []string{}
+	}
+
+	ntfn := soterjson.NewDagTipsChangedNtfn(added, removed)
+	marshalledJSON, err := soterjson.MarshalCmd(nil, ntfn)
+	if err != nil {
+		rpcsLog.Errorf("Failed to marshal dag tips changed "+
+			"notification: %v", err)
+		return
+	}
+	for _, wsc := range clients {
+		wsc.QueueNotification(marshalledJSON)
+	}
+}
+
 // notifyFilteredBlockConnected notifies websocket clients that have registered for
 // block updates when a block is connected to the main chain.
 func (m *wsNotificationManager) notifyFilteredBlockConnected(clients map[chan struct{}]*wsClient,
@@ -1834,6 +1996,13 @@ func handleNotifyBlocks(wsc *wsClient, icmd interface{}) (interface{}, error) {
 	return nil, nil
 }
 
+// handleNotifyDagTips implements the notifydagtips command extension for
+// websocket connections.
+func handleNotifyDagTips(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	wsc.server.ntfnMgr.RegisterDagTipsUpdates(wsc)
+	return nil, nil
+}
+
 // handleSession implements the session command extension for websocket
 // connections.
 func handleSession(wsc *wsClient, icmd interface{}) (interface{}, error) {
@@ -1847,6 +2016,13 @@ func handleStopNotifyBlocks(wsc *wsClient, icmd interface{}) (interface{}, error
 	return nil, nil
 }
 
+// handleStopNotifyDagTips implements the stopnotifydagtips command extension
+// for websocket connections.
+func handleStopNotifyDagTips(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	wsc.server.ntfnMgr.UnregisterDagTipsUpdates(wsc)
+	return nil, nil
+}
+
 // handleNotifySpent implements the notifyspent command extension for
 // websocket connections.
 func handleNotifySpent(wsc *wsClient, icmd interface{}) (interface{}, error) {
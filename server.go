@@ -16,7 +16,7 @@ import (
 	"math"
 	"net"
 	"runtime"
-	// "sort"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -46,7 +46,7 @@ const (
 	// defaultServices describes the default services that are supported by
 	// the server.
 	defaultServices = wire.SFNodeNetwork | wire.SFNodeBloom |
-		wire.SFNodeWitness | wire.SFNodeCF
+		wire.SFNodeWitness | wire.SFNodeCF | wire.SFNodeDag
 
 	// defaultRequiredServices describes the default services that are
 	// required to be supported by outbound peers.
@@ -160,6 +160,13 @@ type peerState struct {
 	persistentPeers map[int32]*serverPeer
 	banned          map[string]time.Time
 	outboundGroups  map[string]int
+	maxInbound      int
+	maxOutbound     int
+
+	// oneTryConnReqs holds the one-try connections requested through the
+	// node RPC that haven't been added as peers yet.  Like persistent
+	// peers, they're only limited by the total number of peers.
+	oneTryConnReqs map[*connmgr.ConnReq]struct{}
 }
 
 // Count returns the count of all known peers.
@@ -168,6 +175,32 @@ func (ps *peerState) Count() int {
 		len(ps.persistentPeers)
 }
 
+// MaxPeers returns the maximum number of total peers.
+func (ps *peerState) MaxPeers() int {
+	return ps.maxInbound + ps.maxOutbound
+}
+
+// defaultPeerLimits returns the maximum number of inbound and outbound peers
+// the server starts with.  The outbound peers get up to defaultTargetOutbound
+// of the --maxpeers slots, and inbound peers get the rest.
+func defaultPeerLimits() (int, int) {
+	maxOutbound := defaultTargetOutbound
+	if cfg.MaxPeers < maxOutbound {
+		maxOutbound = cfg.MaxPeers
+	}
+	return cfg.MaxPeers - maxOutbound, maxOutbound
+}
+
+// peerLimits describes the maximum number of inbound and outbound peers, and
+// the number of peers currently connected.  Outbound peers include the
+// persistent peers.
+type peerLimits struct {
+	MaxInbound  int
+	MaxOutbound int
+	Inbound     int
+	Outbound    int
+}
+
 // forAllOutboundPeers is a helper function that runs closure on all outbound
 // peers known to peerState.
 func (ps *peerState) forAllOutboundPeers(closure func(sp *serverPeer)) {
@@ -625,6 +658,15 @@ func (sp *serverPeer) OnHeaders(_ *peer.Peer, msg *wire.MsgHeaders) {
 	sp.server.syncManager.QueueHeaders(msg, sp.Peer)
 }
 
+// OnNotFound is invoked when a peer receives a notfound soter message, listing
+// the inventory we requested that the peer doesn't have.  The message is passed
+// down to the sync manager, so the inventory can be requested elsewhere.
+func (sp *serverPeer) OnNotFound(_ *peer.Peer, msg *wire.MsgNotFound) {
+	if len(msg.InvList) > 0 {
+		sp.server.syncManager.QueueNotFound(msg, sp.Peer)
+	}
+}
+
 // handleGetData is invoked when a peer receives a getdata soter message and
 // is used to deliver block and transaction information.
 func (sp *serverPeer) OnGetData(_ *peer.Peer, msg *wire.MsgGetData) {
@@ -665,11 +707,11 @@ func (sp *serverPeer) OnGetData(_ *peer.Peer, msg *wire.MsgGetData) {
 			err = sp.server.pushTxMsg(sp, &iv.Hash, c, waitChan, wire.BaseEncoding)
 		case wire.InvTypeWitnessBlock:
 			err = sp.server.pushBlockMsg(sp, &iv.Hash, c, waitChan, wire.WitnessEncoding)
-		case wire.InvTypeBlock:
+		case wire.InvTypeBlock, wire.InvTypeDagBlock:
 			err = sp.server.pushBlockMsg(sp, &iv.Hash, c, waitChan, wire.BaseEncoding)
 		case wire.InvTypeFilteredWitnessBlock:
 			err = sp.server.pushMerkleBlockMsg(sp, &iv.Hash, c, waitChan, wire.WitnessEncoding)
-		case wire.InvTypeFilteredBlock:
+		case wire.InvTypeFilteredBlock, wire.InvTypeFilteredDagBlock:
 			err = sp.server.pushMerkleBlockMsg(sp, &iv.Hash, c, waitChan, wire.BaseEncoding)
 		default:
 			peerLog.Warnf("Unknown type in inventory request %d",
@@ -1685,6 +1727,11 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 		return false
 	}
 
+	// Whether the peer is a one-try connection is looked up before any
+	// checks, so that the request is forgotten even when it's refused.
+	_, oneTry := state.oneTryConnReqs[sp.connReq]
+	delete(state.oneTryConnReqs, sp.connReq)
+
 	// Ignore new peers if we're shutting down.
 	if atomic.LoadInt32(&s.shutdown) != 0 {
 		srvrLog.Infof("New peer %s ignored - server is shutting down", sp)
@@ -1714,15 +1761,33 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 	// TODO: Check for max peers from a single IP.
 
 	// Limit max number of total peers.
-	if state.Count() >= cfg.MaxPeers {
+	if state.Count() >= state.MaxPeers() {
 		srvrLog.Infof("Max peers reached [%d] - disconnecting peer %s",
-			cfg.MaxPeers, sp)
+			state.MaxPeers(), sp)
 		sp.Disconnect()
 		// TODO: how to handle permanent peers here?
 		// they should be rescheduled.
 		return false
 	}
 
+	// Limit max number of inbound and outbound peers.  Persistent peers
+	// and one-try connections count towards the outbound peers, but are
+	// only limited by the total.
+	if sp.Inbound() && len(state.inboundPeers) >= state.maxInbound {
+		srvrLog.Infof("Max inbound peers reached [%d] - disconnecting "+
+			"peer %s", state.maxInbound, sp)
+		sp.Disconnect()
+		return false
+	}
+	if !sp.Inbound() && !sp.persistent && !oneTry && len(state.outboundPeers)+
+		len(state.persistentPeers) >= state.maxOutbound {
+
+		srvrLog.Infof("Max outbound peers reached [%d] - disconnecting "+
+			"peer %s", state.maxOutbound, sp)
+		sp.Disconnect()
+		return false
+	}
+
 	// Add the new peer and start it.
 	srvrLog.Debugf("New peer %s", sp)
 	if sp.Inbound() {
@@ -1911,6 +1976,16 @@ type removeNodeMsg struct {
 	reply chan error
 }
 
+type getPeerLimitsMsg struct {
+	reply chan peerLimits
+}
+
+type setPeerLimitsMsg struct {
+	maxInbound  int
+	maxOutbound int
+	reply       chan struct{}
+}
+
 // handleQuery is the central handler for all queries and commands from other
 // goroutines related to peer state.
 func (s *server) handleQuery(state *peerState, querymsg interface{}) {
@@ -1953,7 +2028,7 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 	case connectNodeMsg:
 		// TODO: duplicate oneshots?
 		// Limit max number of total peers.
-		if state.Count() >= cfg.MaxPeers {
+		if state.Count() >= state.MaxPeers() {
 			msg.reply <- errors.New("max peers reached")
 			return
 		}
@@ -1975,10 +2050,14 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 		}
 
 		// TODO: if too many, nuke a non-perm peer.
-		go s.connManager.Connect(&connmgr.ConnReq{
+		connReq := &connmgr.ConnReq{
 			Addr:      netAddr,
 			Permanent: msg.permanent,
-		})
+		}
+		if !msg.permanent {
+			state.oneTryConnReqs[connReq] = struct{}{}
+		}
+		go s.connManager.Connect(connReq)
 		msg.reply <- nil
 	case removeNodeMsg:
 		found := disconnectPeer(state.persistentPeers, msg.cmp, func(sp *serverPeer) {
@@ -2036,6 +2115,62 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 		}
 
 		msg.reply <- errors.New("peer not found")
+
+	case getPeerLimitsMsg:
+		limits := peerLimits{
+			MaxInbound:  state.maxInbound,
+			MaxOutbound: state.maxOutbound,
+		}
+		for _, sp := range state.inboundPeers {
+			if sp.Connected() {
+				limits.Inbound++
+			}
+		}
+		state.forAllOutboundPeers(func(sp *serverPeer) {
+			if sp.Connected() {
+				limits.Outbound++
+			}
+		})
+		msg.reply <- limits
+
+	case setPeerLimitsMsg:
+		state.maxInbound = msg.maxInbound
+		state.maxOutbound = msg.maxOutbound
+		s.connManager.SetTargetOutbound(uint32(msg.maxOutbound))
+
+		// Drop the peers over the new limits.  The peers are removed
+		// from the peer state once they're done, like any other peer
+		// that disconnects.
+		dropExcessPeers(state.inboundPeers, state.maxInbound)
+		dropExcessPeers(state.outboundPeers,
+			state.maxOutbound-len(state.persistentPeers))
+		msg.reply <- struct{}{}
+	}
+}
+
+// dropExcessPeers disconnects the most recently connected peers in the passed
+// peer list, until no more than limit of its peers are connected.
+func dropExcessPeers(peerList map[int32]*serverPeer, limit int) {
+	peers := make([]*serverPeer, 0, len(peerList))
+	for _, sp := range peerList {
+		if sp.Connected() {
+			peers = append(peers, sp)
+		}
+	}
+	if limit < 0 {
+		limit = 0
+	}
+	if len(peers) <= limit {
+		return
+	}
+
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].ID() < peers[j].ID()
+	})
+	for _, sp := range peers[limit:] {
+		srvrLog.Infof("Peer limit lowered to %d - disconnecting peer %s",
+			limit, sp)
+		sp.Disconnect()
 	}
 }
 
@@ -2073,6 +2208,7 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 			OnBlock:        sp.OnBlock,
 			OnInv:          sp.OnInv,
 			OnHeaders:      sp.OnHeaders,
+			OnNotFound:     sp.OnNotFound,
 			OnGetData:      sp.OnGetData,
 			OnGetBlocks:    sp.OnGetBlocks,
 			OnGetHeaders:   sp.OnGetHeaders,
@@ -2107,6 +2243,10 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 		DisableRelayTx:    cfg.BlocksOnly,
 		ProtocolVersion:   peer.MaxProtocolVersion,
 		TrickleInterval:   cfg.TrickleInterval,
+		RelayLimits: &peer.RelayLimits{
+			Inv:     peer.RelayLimit{Rate: cfg.MaxInvRate, Burst: cfg.MaxInvBurst},
+			GetData: peer.RelayLimit{Rate: cfg.MaxGetDataRate, Burst: cfg.MaxGetDataBurst},
+		},
 	}
 }
 
@@ -2177,12 +2317,16 @@ func (s *server) peerHandler() {
 
 	srvrLog.Tracef("Starting peer handler")
 
+	maxInbound, maxOutbound := defaultPeerLimits()
 	state := &peerState{
 		inboundPeers:    make(map[int32]*serverPeer),
 		persistentPeers: make(map[int32]*serverPeer),
 		outboundPeers:   make(map[int32]*serverPeer),
 		banned:          make(map[string]time.Time),
 		outboundGroups:  make(map[string]int),
+		maxInbound:      maxInbound,
+		maxOutbound:     maxOutbound,
+		oneTryConnReqs:  make(map[*connmgr.ConnReq]struct{}),
 	}
 
 	if !cfg.DisableDNSSeed {
@@ -2305,6 +2449,28 @@ func (s *server) OutboundGroupCount(key string) int {
 	return <-replyChan
 }
 
+// PeerLimits returns the maximum number of inbound and outbound peers, along
+// with the number of peers currently connected.
+func (s *server) PeerLimits() peerLimits {
+	replyChan := make(chan peerLimits)
+	s.query <- getPeerLimitsMsg{reply: replyChan}
+	return <-replyChan
+}
+
+// SetPeerLimits changes the maximum number of inbound and outbound peers.
+// When a limit is lowered below the number of connected peers, the most
+// recently connected peers are disconnected.  Persistent peers aren't
+// disconnected, but count towards the outbound peers.
+func (s *server) SetPeerLimits(maxInbound, maxOutbound int) {
+	replyChan := make(chan struct{})
+	s.query <- setPeerLimitsMsg{
+		maxInbound:  maxInbound,
+		maxOutbound: maxOutbound,
+		reply:       replyChan,
+	}
+	<-replyChan
+}
+
 // AddBytesSent adds the passed number of bytes to the total bytes sent counter
 // for the server.  It is safe for concurrent access.
 func (s *server) AddBytesSent(bytesSent uint64) {
@@ -2810,6 +2976,7 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		Policy: mempool.Policy{
 			DisableRelayPriority: cfg.NoRelayPriority,
 			AcceptNonStd:         cfg.RelayNonStd,
+			MaxOpReturnSize:      cfg.MaxOpReturnSize,
 			FreeTxRelayLimit:     cfg.FreeTxRelayLimit,
 			MaxOrphanTxs:         cfg.MaxOrphanTxs,
 			MaxOrphanTxSize:      defaultMaxOrphanTxSize,
@@ -2861,8 +3028,16 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		BlockPrioritySize: cfg.BlockPrioritySize,
 		TxMinFreeFee:      cfg.minRelayTxFee,
 	}
+	// A mining seed makes generated blocks deterministic, which needs
+	// their timestamps to be independent of the current time as well.
+	templateTimeSource := s.timeSource
+	if cfg.MiningSeed != 0 {
+		srvrLog.Warnf("Mining with seed %d, which is for testing only",
+			cfg.MiningSeed)
+		templateTimeSource = miningdag.NewEarliestTimeSource(s.timeSource)
+	}
 	blockTemplateGenerator := miningdag.NewBlkTmplGenerator(&policy,
-		s.chainParams, s.txMemPool, s.chain, s.timeSource,
+		s.chainParams, s.txMemPool, s.chain, templateTimeSource,
 		s.sigCache, s.hashCache)
 	s.cpuMiner = cpuminer.New(&cpuminer.Config{
 		ChainParams:            chainParams,
@@ -2871,6 +3046,7 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		ProcessBlock:           s.syncManager.ProcessBlock,
 		ConnectedCount:         s.ConnectedCount,
 		IsCurrent:              s.syncManager.IsCurrent,
+		Seed:                   cfg.MiningSeed,
 	})
 
 	// Only setup a function to return new addresses to connect to when
@@ -2905,6 +3081,12 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 					continue
 				}
 
+				// prefer peers that support the dag sync messages,
+				// allowing legacy peers after 20 failed tries.
+				if tries < 20 && !addr.NetAddress().HasService(wire.SFNodeDag) {
+					continue
+				}
+
 				// allow nondefault ports after 50 failed tries.
 				if tries < 50 && fmt.Sprintf("%d", addr.NetAddress().Port) !=
 					activeNetParams.DefaultPort {
@@ -2920,10 +3102,7 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 	}
 
 	// Create a connection manager.
-	targetOutbound := defaultTargetOutbound
-	if cfg.MaxPeers < targetOutbound {
-		targetOutbound = cfg.MaxPeers
-	}
+	_, targetOutbound := defaultPeerLimits()
 	cmgr, err := connmgr.New(&connmgr.Config{
 		Listeners:      listeners,
 		OnAccept:       s.inboundPeerConnected,
@@ -0,0 +1,103 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/soteria-dag/soterd/connmgr"
+	"github.com/soteria-dag/soterd/peer"
+	"github.com/soteria-dag/soterd/soterlog"
+)
+
+// newTestOutboundPeer returns an unconnected outbound serverPeer for the
+// given address, made through the given connection request.  The peer hasn't
+// negotiated a version, so its ID is 0.
+func newTestOutboundPeer(t *testing.T, s *server, addr string, connReq *connmgr.ConnReq) *serverPeer {
+	sp := newServerPeer(s, connReq.Permanent)
+	p, err := peer.NewOutboundPeer(&peer.Config{}, addr)
+	if err != nil {
+		t.Fatalf("unable to create outbound peer %s: %v", addr, err)
+	}
+	sp.Peer = p
+	sp.connReq = connReq
+	return sp
+}
+
+// TestAddPeerOutboundLimit ensures that outbound peers made by the connection
+// manager are limited by the max outbound peers, while one-try connections
+// requested through the node RPC are only limited by the total.
+func TestAddPeerOutboundLimit(t *testing.T) {
+	// The server logs the peers it adds and rejects, and there's no log
+	// rotator for the log backend to write to in tests.
+	defer func(logger soterlog.Logger) { srvrLog = logger }(srvrLog)
+	srvrLog = soterlog.Disabled
+
+	s := &server{}
+	state := &peerState{
+		inboundPeers:    make(map[int32]*serverPeer),
+		persistentPeers: make(map[int32]*serverPeer),
+		outboundPeers:   make(map[int32]*serverPeer),
+		banned:          make(map[string]time.Time),
+		outboundGroups:  make(map[string]int),
+		maxInbound:      2,
+		maxOutbound:     1,
+		oneTryConnReqs:  make(map[*connmgr.ConnReq]struct{}),
+	}
+
+	// Fill the outbound slots with peers from the connection manager.
+	// They're keyed by IDs other than 0, so that the peers being added
+	// don't replace them.
+	for i := 0; i < state.maxOutbound; i++ {
+		addr := fmt.Sprintf("10.0.0.%d:18555", i+1)
+		sp := newTestOutboundPeer(t, s, addr, &connmgr.ConnReq{})
+		state.outboundPeers[int32(i+1)] = sp
+	}
+
+	// Another peer from the connection manager is over the max.
+	sp := newTestOutboundPeer(t, s, "10.0.1.1:18555", &connmgr.ConnReq{})
+	if s.handleAddPeerMsg(state, sp) {
+		t.Fatalf("outbound peer accepted over the max of %d",
+			state.maxOutbound)
+	}
+
+	// A one-try connection is accepted over the max outbound peers, and
+	// the request is forgotten once it's added.
+	oneTry := &connmgr.ConnReq{}
+	state.oneTryConnReqs[oneTry] = struct{}{}
+	sp = newTestOutboundPeer(t, s, "10.0.2.1:18555", oneTry)
+	if !s.handleAddPeerMsg(state, sp) {
+		t.Fatalf("one-try peer refused over the max outbound peers")
+	}
+	if state.outboundPeers[sp.ID()] != sp ||
+		len(state.outboundPeers) != state.maxOutbound+1 {
+
+		t.Fatalf("one-try peer not added to the outbound peers")
+	}
+	if len(state.oneTryConnReqs) != 0 {
+		t.Fatalf("one-try connection request not forgotten after " +
+			"adding the peer")
+	}
+
+	// One-try connections are still limited by the total.
+	for i := 0; state.Count() < state.MaxPeers(); i++ {
+		addr := fmt.Sprintf("10.0.4.%d:18555", i+1)
+		sp := newTestOutboundPeer(t, s, addr, &connmgr.ConnReq{})
+		state.inboundPeers[int32(-i-1)] = sp
+	}
+	oneTry = &connmgr.ConnReq{}
+	state.oneTryConnReqs[oneTry] = struct{}{}
+	sp = newTestOutboundPeer(t, s, "10.0.3.1:18555", oneTry)
+	if s.handleAddPeerMsg(state, sp) {
+		t.Fatalf("one-try peer accepted over the max of %d peers",
+			state.MaxPeers())
+	}
+	if len(state.oneTryConnReqs) != 0 {
+		t.Fatalf("one-try connection request not forgotten after " +
+			"refusing the peer")
+	}
+}
@@ -104,6 +104,17 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"decodescript","params":["00"],"id":1}`,
 			unmarshalled: &soterjson.DecodeScriptCmd{HexScript: "00"},
 		},
+		{
+			name: "estimatesmartfee",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("estimatesmartfee", 6)
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewEstimateSmartFeeCmd(6)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"estimatesmartfee","params":[6],"id":1}`,
+			unmarshalled: &soterjson.EstimateSmartFeeCmd{ConfTarget: 6},
+		},
 		{
 			name: "getaddednodeinfo",
 			newCmd: func() (interface{}, error) {
@@ -949,6 +960,20 @@ func TestChainSvrCmds(t *testing.T) {
 				GenProcLimit: soterjson.Int(6),
 			},
 		},
+		{
+			name: "signmessagewithprivkey",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("signmessagewithprivkey", "5Hue", "Hey")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewSignMessageWithPrivKey("5Hue", "Hey")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"signmessagewithprivkey","params":["5Hue","Hey"],"id":1}`,
+			unmarshalled: &soterjson.SignMessageWithPrivKeyCmd{
+				PrivKey: "5Hue",
+				Message: "Hey",
+			},
+		},
 		{
 			name: "stop",
 			newCmd: func() (interface{}, error) {
@@ -23,6 +23,7 @@ type GetBlockHeaderVerboseResult struct {
 	Difficulty    float64 `json:"difficulty"`
 	PreviousHash  string  `json:"previousblockhash,omitempty"`
 	NextHashes    []string  `json:"nextblockhashes,omitempty"`
+	Parents       []string  `json:"parents,omitempty"`
 }
 
 // GetBlockVerboseResult models the data from the getblock command when the
@@ -47,6 +48,8 @@ type GetBlockVerboseResult struct {
 	PreviousHash  string        `json:"previousblockhash"`
 	NextHashes    []string      `json:"nextblockhashes,omitempty"`
 	Parents       []DAGParent   `json:"parents,omitempty"`
+	Work          string        `json:"work"`
+	DagWork       string        `json:"dagwork"`
 }
 
 // CreateMultiSigResult models the data returned from the createmultisig
@@ -65,6 +68,14 @@ type DecodeScriptResult struct {
 	P2sh      string   `json:"p2sh,omitempty"`
 }
 
+// EstimateSmartFeeResult models the data returned from the estimatesmartfee
+// command.
+type EstimateSmartFeeResult struct {
+	FeeRate *float64 `json:"feerate,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+	Blocks  int64    `json:"blocks"`
+}
+
 // GetAddedNodeInfoResultAddr models the data of the addresses portion of the
 // getaddednodeinfo command.
 type GetAddedNodeInfoResultAddr struct {
@@ -152,6 +163,13 @@ type GetBlockTemplateResult struct {
 	CoinbaseValue *int64                     `json:"coinbasevalue,omitempty"`
 	WorkID        string                     `json:"workid,omitempty"`
 
+	// Parents of the block in the dag, which is a soterd extension.  The
+	// block must reference them in the given order, with a parent
+	// sub-header of the given version.  PreviousHash is the tips hash of
+	// the parents.
+	Parents       []string `json:"parents"`
+	ParentVersion int32    `json:"parentversion"`
+
 	// Witness commitment defined in BIP 0141.
 	DefaultWitnessCommitment string `json:"default_witness_commitment,omitempty"`
 
@@ -197,8 +215,9 @@ type GetMempoolEntryResult struct {
 // GetMempoolInfoResult models the data returned from the getmempoolinfo
 // command.
 type GetMempoolInfoResult struct {
-	Size  int64 `json:"size"`
-	Bytes int64 `json:"bytes"`
+	Size   int64   `json:"size"`
+	Bytes  int64   `json:"bytes"`
+	MinFee float64 `json:"mempoolminfee"`
 }
 
 // NetworksResult models the networks data from the getnetworkinfo command.
@@ -476,6 +495,16 @@ type GetMiningInfoResult struct {
 	NetworkHashPS      int64   `json:"networkhashps"`
 	PooledTx           uint64  `json:"pooledtx"`
 	TestNet            bool    `json:"testnet"`
+
+	// Dag stats, which are a soterd extension.  Blocks is the number of
+	// blocks in the dag, since heights are shared by blocks in a dag, so
+	// MaxHeight and OrderLength, the number of blocks in the dag ordering,
+	// are given too.  TipCount is the number of tips of the dag, and
+	// Parents are the tips that the next block should reference.
+	MaxHeight   int32    `json:"maxheight"`
+	OrderLength int32    `json:"orderlength"`
+	TipCount    int32    `json:"tipcount"`
+	Parents     []string `json:"parents"`
 }
 
 // GetWorkResult models the data from the getwork command.
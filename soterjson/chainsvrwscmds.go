@@ -42,6 +42,24 @@ func NewStopNotifyBlocksCmd() *StopNotifyBlocksCmd {
 	return &StopNotifyBlocksCmd{}
 }
 
+// NotifyDagTipsCmd defines the notifydagtips JSON-RPC command.
+type NotifyDagTipsCmd struct{}
+
+// NewNotifyDagTipsCmd returns a new instance which can be used to issue a
+// notifydagtips JSON-RPC command.
+func NewNotifyDagTipsCmd() *NotifyDagTipsCmd {
+	return &NotifyDagTipsCmd{}
+}
+
+// StopNotifyDagTipsCmd defines the stopnotifydagtips JSON-RPC command.
+type StopNotifyDagTipsCmd struct{}
+
+// NewStopNotifyDagTipsCmd returns a new instance which can be used to issue a
+// stopnotifydagtips JSON-RPC command.
+func NewStopNotifyDagTipsCmd() *StopNotifyDagTipsCmd {
+	return &StopNotifyDagTipsCmd{}
+}
+
 // NotifyNewTransactionsCmd defines the notifynewtransactions JSON-RPC command.
 type NotifyNewTransactionsCmd struct {
 	Verbose *bool `jsonrpcdefault:"false"`
@@ -229,11 +247,13 @@ func init() {
 	MustRegisterCmd("authenticate", (*AuthenticateCmd)(nil), flags)
 	MustRegisterCmd("loadtxfilter", (*LoadTxFilterCmd)(nil), flags)
 	MustRegisterCmd("notifyblocks", (*NotifyBlocksCmd)(nil), flags)
+	MustRegisterCmd("notifydagtips", (*NotifyDagTipsCmd)(nil), flags)
 	MustRegisterCmd("notifynewtransactions", (*NotifyNewTransactionsCmd)(nil), flags)
 	MustRegisterCmd("notifyreceived", (*NotifyReceivedCmd)(nil), flags)
 	MustRegisterCmd("notifyspent", (*NotifySpentCmd)(nil), flags)
 	MustRegisterCmd("session", (*SessionCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyblocks", (*StopNotifyBlocksCmd)(nil), flags)
+	MustRegisterCmd("stopnotifydagtips", (*StopNotifyDagTipsCmd)(nil), flags)
 	MustRegisterCmd("stopnotifynewtransactions", (*StopNotifyNewTransactionsCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyspent", (*StopNotifySpentCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyreceived", (*StopNotifyReceivedCmd)(nil), flags)
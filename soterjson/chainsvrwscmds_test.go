@@ -64,6 +64,28 @@ func TestChainSvrWsCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"stopnotifyblocks","params":[],"id":1}`,
 			unmarshalled: &soterjson.StopNotifyBlocksCmd{},
 		},
+		{
+			name: "notifydagtips",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("notifydagtips")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewNotifyDagTipsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"notifydagtips","params":[],"id":1}`,
+			unmarshalled: &soterjson.NotifyDagTipsCmd{},
+		},
+		{
+			name: "stopnotifydagtips",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("stopnotifydagtips")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewStopNotifyDagTipsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"stopnotifydagtips","params":[],"id":1}`,
+			unmarshalled: &soterjson.StopNotifyDagTipsCmd{},
+		},
 		{
 			name: "notifynewtransactions",
 			newCmd: func() (interface{}, error) {
@@ -32,6 +32,15 @@ const (
 	// disconnected.
 	FilteredBlockDisconnectedNtfnMethod = "filteredblockdisconnected"
 
+	// BlockReorderedNtfnMethod is the method used for notifications from
+	// the chain server that blocks have moved between the blue and red
+	// sets of the dag.
+	BlockReorderedNtfnMethod = "blockreordered"
+
+	// DagTipsChangedNtfnMethod is the method used for notifications from
+	// the chain server that the set of dag tips has changed.
+	DagTipsChangedNtfnMethod = "dagtipschanged"
+
 	// RecvTxNtfnMethod is the legacy, deprecated method used for
 	// notifications from the chain server that a transaction which pays to
 	// a registered address has been processed.
@@ -154,6 +163,36 @@ func NewFilteredBlockDisconnectedNtfn(height int32, header string) *FilteredBloc
 	}
 }
 
+// BlockReorderedNtfn defines the blockreordered JSON-RPC notification.
+type BlockReorderedNtfn struct {
+	Blue []string
+	Red  []string
+}
+
+// NewBlockReorderedNtfn returns a new instance which can be used to issue a
+// blockreordered JSON-RPC notification.
+func NewBlockReorderedNtfn(blue, red []string) *BlockReorderedNtfn {
+	return &BlockReorderedNtfn{
+		Blue: blue,
+		Red:  red,
+	}
+}
+
+// DagTipsChangedNtfn defines the dagtipschanged JSON-RPC notification.
+type DagTipsChangedNtfn struct {
+	Added   []string
+	Removed []string
+}
+
+// NewDagTipsChangedNtfn returns a new instance which can be used to issue a
+// dagtipschanged JSON-RPC notification.
+func NewDagTipsChangedNtfn(added, removed []string) *DagTipsChangedNtfn {
+	return &DagTipsChangedNtfn{
+		Added:   added,
+		Removed: removed,
+	}
+}
+
 // BlockDetails describes details of a tx in a block.
 type BlockDetails struct {
 	Height int32  `json:"height"`
@@ -295,6 +334,8 @@ func init() {
 	MustRegisterCmd(BlockDisconnectedNtfnMethod, (*BlockDisconnectedNtfn)(nil), flags)
 	MustRegisterCmd(FilteredBlockConnectedNtfnMethod, (*FilteredBlockConnectedNtfn)(nil), flags)
 	MustRegisterCmd(FilteredBlockDisconnectedNtfnMethod, (*FilteredBlockDisconnectedNtfn)(nil), flags)
+	MustRegisterCmd(BlockReorderedNtfnMethod, (*BlockReorderedNtfn)(nil), flags)
+	MustRegisterCmd(DagTipsChangedNtfnMethod, (*DagTipsChangedNtfn)(nil), flags)
 	MustRegisterCmd(RecvTxNtfnMethod, (*RecvTxNtfn)(nil), flags)
 	MustRegisterCmd(RedeemingTxNtfnMethod, (*RedeemingTxNtfn)(nil), flags)
 	MustRegisterCmd(RescanFinishedNtfnMethod, (*RescanFinishedNtfn)(nil), flags)
@@ -89,6 +89,34 @@ func TestChainSvrWsNtfns(t *testing.T) {
 				Header: "header",
 			},
 		},
+		{
+			name: "blockreordered",
+			newNtfn: func() (interface{}, error) {
+				return soterjson.NewCmd("blockreordered", []string{"123"}, []string{"456", "789"})
+			},
+			staticNtfn: func() interface{} {
+				return soterjson.NewBlockReorderedNtfn([]string{"123"}, []string{"456", "789"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"blockreordered","params":[["123"],["456","789"]],"id":null}`,
+			unmarshalled: &soterjson.BlockReorderedNtfn{
+				Blue: []string{"123"},
+				Red:  []string{"456", "789"},
+			},
+		},
+		{
+			name: "dagtipschanged",
+			newNtfn: func() (interface{}, error) {
+				return soterjson.NewCmd("dagtipschanged", []string{"123"}, []string{"456", "789"})
+			},
+			staticNtfn: func() interface{} {
+				return soterjson.NewDagTipsChangedNtfn([]string{"123"}, []string{"456", "789"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"dagtipschanged","params":[["123"],["456","789"]],"id":null}`,
+			unmarshalled: &soterjson.DagTipsChangedNtfn{
+				Added:   []string{"123"},
+				Removed: []string{"456", "789"},
+			},
+		},
 		{
 			name: "recvtx",
 			newNtfn: func() (interface{}, error) {
@@ -40,6 +40,7 @@ const (
 	ErrRPCDatabase            RPCErrorCode = -20
 	ErrRPCDeserialization     RPCErrorCode = -22
 	ErrRPCVerify              RPCErrorCode = -25
+	ErrRPCVerifyAlreadyInDAG  RPCErrorCode = -27
 )
 
 // Peer-to-peer client errors.
@@ -60,6 +60,19 @@ func NewDebugLevelCmd(levelSpec string) *DebugLevelCmd {
 	}
 }
 
+// DecodeDagHeaderCmd defines the decodedagheader JSON-RPC command.
+type DecodeDagHeaderCmd struct {
+	HexHeader string
+}
+
+// NewDecodeDagHeaderCmd returns a new instance which can be used to issue a
+// decodedagheader JSON-RPC command.
+func NewDecodeDagHeaderCmd(hexHeader string) *DecodeDagHeaderCmd {
+	return &DecodeDagHeaderCmd{
+		HexHeader: hexHeader,
+	}
+}
+
 // GenerateCmd defines the generate JSON-RPC command.
 type GenerateCmd struct {
 	NumBlocks uint32
@@ -73,6 +86,36 @@ func NewGenerateCmd(numBlocks uint32) *GenerateCmd {
 	}
 }
 
+// GenerateIdempotentCmd defines the generateidempotent JSON-RPC command.
+type GenerateIdempotentCmd struct {
+	Token     string
+	NumBlocks uint32
+}
+
+// NewGenerateIdempotentCmd returns a new instance which can be used to issue a
+// generateidempotent JSON-RPC command.
+func NewGenerateIdempotentCmd(token string, numBlocks uint32) *GenerateIdempotentCmd {
+	return &GenerateIdempotentCmd{
+		Token:     token,
+		NumBlocks: numBlocks,
+	}
+}
+
+// GenerateWithParentsCmd defines the generatewithparents JSON-RPC command.
+type GenerateWithParentsCmd struct {
+	NumBlocks uint32
+	Parents   []string
+}
+
+// NewGenerateWithParentsCmd returns a new instance which can be used to issue a
+// generatewithparents JSON-RPC command.
+func NewGenerateWithParentsCmd(numBlocks uint32, parents []string) *GenerateWithParentsCmd {
+	return &GenerateWithParentsCmd{
+		NumBlocks: numBlocks,
+		Parents:   parents,
+	}
+}
+
 // GetAddrCacheCmd defines the getaddrcache JSON-RPC command.
 type GetAddrCacheCmd struct{}
 
@@ -81,6 +124,24 @@ func NewGetAddrCacheCmd() *GetAddrCacheCmd {
 	return &GetAddrCacheCmd{}
 }
 
+// GetAnticoneCmd defines the getanticone JSON-RPC command.
+type GetAnticoneCmd struct {
+	Hash  string
+	Limit *int32 `jsonrpcdefault:"0"`
+}
+
+// NewGetAnticoneCmd returns a new instance which can be used to issue a
+// getanticone JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetAnticoneCmd(hash string, limit *int32) *GetAnticoneCmd {
+	return &GetAnticoneCmd{
+		Hash:  hash,
+		Limit: limit,
+	}
+}
+
 // GetBestBlockCmd defines the getbestblock JSON-RPC command.
 type GetBestBlockCmd struct{}
 
@@ -91,7 +152,7 @@ func NewGetBestBlockCmd() *GetBestBlockCmd {
 }
 
 // GetBlockMetricsCmd defines the getblockmetrics JSON-RPC command.
-type GetBlockMetricsCmd struct {}
+type GetBlockMetricsCmd struct{}
 
 // NewGetBlockMetricsCmd returns a new instance, which can be used to issue a getblockmetrics JSON-RPC command.
 func NewGetBlockMetricsCmd() *GetBlockMetricsCmd {
@@ -115,13 +176,103 @@ type GetDAGColoringCmd struct{}
 func NewGetDAGColoringCmd() *GetDAGColoringCmd {
 	return &GetDAGColoringCmd{}
 }
+
+// GetDagBlockHashesCmd defines the getdagblockhashes JSON-RPC command.
+type GetDagBlockHashesCmd struct {
+	Height int32
+}
+
+// NewGetDagBlockHashesCmd returns a new instance which can be used to issue a
+// getdagblockhashes JSON-RPC command.
+func NewGetDagBlockHashesCmd(height int32) *GetDagBlockHashesCmd {
+	return &GetDagBlockHashesCmd{
+		Height: height,
+	}
+}
+
+// GetDagHashPSCmd defines the getdaghashps JSON-RPC command.
+type GetDagHashPSCmd struct {
+	Blocks *int32 `jsonrpcdefault:"120"`
+	Height *int32 `jsonrpcdefault:"-1"`
+}
+
+// NewGetDagHashPSCmd returns a new instance which can be used to issue a
+// getdaghashps JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetDagHashPSCmd(numBlocks, height *int32) *GetDagHashPSCmd {
+	return &GetDagHashPSCmd{
+		Blocks: numBlocks,
+		Height: height,
+	}
+}
+
+// GetDagPathCmd defines the getdagpath JSON-RPC command.
+type GetDagPathCmd struct {
+	From string
+	To   string
+}
+
+// NewGetDagPathCmd returns a new instance which can be used to issue a
+// getdagpath JSON-RPC command.
+func NewGetDagPathCmd(from, to string) *GetDagPathCmd {
+	return &GetDagPathCmd{
+		From: from,
+		To:   to,
+	}
+}
+
+// GetDagInfoCmd defines the getdaginfo JSON-RPC command.
+type GetDagInfoCmd struct{}
+
+// NewGetDagInfoCmd returns a new instance which can be used to issue a
+// getdaginfo JSON-RPC command.
+func NewGetDagInfoCmd() *GetDagInfoCmd {
+	return &GetDagInfoCmd{}
+}
+
+// GetDagOrderHashCmd defines the getdagorderhash JSON-RPC command.
+type GetDagOrderHashCmd struct {
+	Index int32
+}
+
+// NewGetDagOrderHashCmd returns a new instance which can be used to issue a
+// getdagorderhash JSON-RPC command.
+func NewGetDagOrderHashCmd(index int32) *GetDagOrderHashCmd {
+	return &GetDagOrderHashCmd{
+		Index: index,
+	}
+}
+
 // GetDAGTipsCmd defines the getdagtips JSON-RPC command.
-type GetDAGTipsCmd struct{}
+//
+// Offset and Limit select a page of the tips.  A Limit of 0 returns as many
+// tips as the server allows in a single response.
+type GetDAGTipsCmd struct {
+	Offset *int32 `jsonrpcdefault:"0"`
+	Limit  *int32 `jsonrpcdefault:"0"`
+}
 
 // NewGetDAGTipsCmd returns a new instance which can be used to issue a
 // getdagtips JSON-RPC command.
-func NewGetDAGTipsCmd() *GetDAGTipsCmd {
-	return &GetDAGTipsCmd{}
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetDAGTipsCmd(offset, limit *int32) *GetDAGTipsCmd {
+	return &GetDAGTipsCmd{
+		Offset: offset,
+		Limit:  limit,
+	}
+}
+
+// GetDAGTipStatusCmd defines the getdagtipstatus JSON-RPC command.
+type GetDAGTipStatusCmd struct{}
+
+// NewGetDAGTipStatusCmd returns a new instance which can be used to issue a
+// getdagtipstatus JSON-RPC command.
+func NewGetDAGTipStatusCmd() *GetDAGTipStatusCmd {
+	return &GetDAGTipStatusCmd{}
 }
 
 // GetHeadersCmd defines the getheaders JSON-RPC command.
@@ -153,6 +304,90 @@ func NewGetListenAddrsCmd() *GetListenAddrsCmd {
 	return &GetListenAddrsCmd{}
 }
 
+// GetPeerLimitsCmd defines the getpeerlimits JSON-RPC command.
+type GetPeerLimitsCmd struct{}
+
+// NewGetPeerLimitsCmd returns a new instance which can be used to issue a
+// getpeerlimits JSON-RPC command.
+func NewGetPeerLimitsCmd() *GetPeerLimitsCmd {
+	return &GetPeerLimitsCmd{}
+}
+
+// GetTransactionStatusCmd defines the gettransactionstatus JSON-RPC command.
+type GetTransactionStatusCmd struct {
+	Txid string
+}
+
+// NewGetTransactionStatusCmd returns a new instance which can be used to issue
+// a gettransactionstatus JSON-RPC command.
+func NewGetTransactionStatusCmd(txHash string) *GetTransactionStatusCmd {
+	return &GetTransactionStatusCmd{
+		Txid: txHash,
+	}
+}
+
+// GetTxConfirmationsCmd defines the gettxconfirmations JSON-RPC command.
+type GetTxConfirmationsCmd struct {
+	Txid string
+}
+
+// NewGetTxConfirmationsCmd returns a new instance which can be used to issue a
+// gettxconfirmations JSON-RPC command.
+func NewGetTxConfirmationsCmd(txHash string) *GetTxConfirmationsCmd {
+	return &GetTxConfirmationsCmd{
+		Txid: txHash,
+	}
+}
+
+// CheckPruneDagCmd defines the checkprunedag JSON-RPC command.
+type CheckPruneDagCmd struct {
+	Height int32
+}
+
+// NewCheckPruneDagCmd returns a new instance which can be used to issue a
+// checkprunedag JSON-RPC command.
+func NewCheckPruneDagCmd(height int32) *CheckPruneDagCmd {
+	return &CheckPruneDagCmd{
+		Height: height,
+	}
+}
+
+// ClearOrphansCmd defines the clearorphans JSON-RPC command.
+type ClearOrphansCmd struct{}
+
+// NewClearOrphansCmd returns a new instance which can be used to issue a
+// clearorphans JSON-RPC command.
+func NewClearOrphansCmd() *ClearOrphansCmd {
+	return &ClearOrphansCmd{}
+}
+
+// GetOrphanBlocksCmd defines the getorphanblocks JSON-RPC command.
+type GetOrphanBlocksCmd struct{}
+
+// NewGetOrphanBlocksCmd returns a new instance which can be used to issue a
+// getorphanblocks JSON-RPC command.
+func NewGetOrphanBlocksCmd() *GetOrphanBlocksCmd {
+	return &GetOrphanBlocksCmd{}
+}
+
+// GetPruneInfoCmd defines the getpruneinfo JSON-RPC command.
+type GetPruneInfoCmd struct{}
+
+// NewGetPruneInfoCmd returns a new instance which can be used to issue a
+// getpruneinfo JSON-RPC command.
+func NewGetPruneInfoCmd() *GetPruneInfoCmd {
+	return &GetPruneInfoCmd{}
+}
+
+// GetRelayPolicyCmd defines the getrelaypolicy JSON-RPC command.
+type GetRelayPolicyCmd struct{}
+
+// NewGetRelayPolicyCmd returns a new instance which can be used to issue a
+// getrelaypolicy JSON-RPC command.
+func NewGetRelayPolicyCmd() *GetRelayPolicyCmd {
+	return &GetRelayPolicyCmd{}
+}
+
 // RenderDagCmd defines the renderdag JSON-RPC command.
 type RenderDagCmd struct{}
 
@@ -161,6 +396,47 @@ func NewRenderDagCmd() *RenderDagCmd {
 	return &RenderDagCmd{}
 }
 
+// ResubmitTransactionCmd defines the resubmittransaction JSON-RPC command.
+type ResubmitTransactionCmd struct {
+	HexTx string
+}
+
+// NewResubmitTransactionCmd returns a new instance which can be used to issue a
+// resubmittransaction JSON-RPC command.
+func NewResubmitTransactionCmd(hexTx string) *ResubmitTransactionCmd {
+	return &ResubmitTransactionCmd{
+		HexTx: hexTx,
+	}
+}
+
+// SetMaxPeersCmd defines the setmaxpeers JSON-RPC command.
+type SetMaxPeersCmd struct {
+	Inbound  int32
+	Outbound int32
+}
+
+// NewSetMaxPeersCmd returns a new instance which can be used to issue a
+// setmaxpeers JSON-RPC command.
+func NewSetMaxPeersCmd(inbound, outbound int32) *SetMaxPeersCmd {
+	return &SetMaxPeersCmd{
+		Inbound:  inbound,
+		Outbound: outbound,
+	}
+}
+
+// TestBlockAcceptanceCmd defines the testblockacceptance JSON-RPC command.
+type TestBlockAcceptanceCmd struct {
+	HexBlock string
+}
+
+// NewTestBlockAcceptanceCmd returns a new instance which can be used to issue a
+// testblockacceptance JSON-RPC command.
+func NewTestBlockAcceptanceCmd(hexBlock string) *TestBlockAcceptanceCmd {
+	return &TestBlockAcceptanceCmd{
+		HexBlock: hexBlock,
+	}
+}
+
 // VersionCmd defines the version JSON-RPC command.
 //
 // NOTE: This is a soterd extension ported from
@@ -178,17 +454,38 @@ func init() {
 	// No special flags for commands in this file.
 	flags := UsageFlag(0)
 
+	MustRegisterCmd("checkprunedag", (*CheckPruneDagCmd)(nil), flags)
+	MustRegisterCmd("clearorphans", (*ClearOrphansCmd)(nil), flags)
 	MustRegisterCmd("debuglevel", (*DebugLevelCmd)(nil), flags)
+	MustRegisterCmd("decodedagheader", (*DecodeDagHeaderCmd)(nil), flags)
 	MustRegisterCmd("node", (*NodeCmd)(nil), flags)
 	MustRegisterCmd("generate", (*GenerateCmd)(nil), flags)
+	MustRegisterCmd("generateidempotent", (*GenerateIdempotentCmd)(nil), flags)
+	MustRegisterCmd("generatewithparents", (*GenerateWithParentsCmd)(nil), flags)
 	MustRegisterCmd("getaddrcache", (*GetAddrCacheCmd)(nil), flags)
+	MustRegisterCmd("getanticone", (*GetAnticoneCmd)(nil), flags)
 	MustRegisterCmd("getbestblock", (*GetBestBlockCmd)(nil), flags)
 	MustRegisterCmd("getblockmetrics", (*GetBlockMetricsCmd)(nil), flags)
 	MustRegisterCmd("getcurrentnet", (*GetCurrentNetCmd)(nil), flags)
+	MustRegisterCmd("getdagblockhashes", (*GetDagBlockHashesCmd)(nil), flags)
 	MustRegisterCmd("getdagcoloring", (*GetDAGColoringCmd)(nil), flags)
+	MustRegisterCmd("getdaghashps", (*GetDagHashPSCmd)(nil), flags)
+	MustRegisterCmd("getdaginfo", (*GetDagInfoCmd)(nil), flags)
+	MustRegisterCmd("getdagorderhash", (*GetDagOrderHashCmd)(nil), flags)
+	MustRegisterCmd("getdagpath", (*GetDagPathCmd)(nil), flags)
 	MustRegisterCmd("getdagtips", (*GetDAGTipsCmd)(nil), flags)
+	MustRegisterCmd("getdagtipstatus", (*GetDAGTipStatusCmd)(nil), flags)
 	MustRegisterCmd("getheaders", (*GetHeadersCmd)(nil), flags)
 	MustRegisterCmd("getlistenaddrs", (*GetListenAddrsCmd)(nil), flags)
+	MustRegisterCmd("getorphanblocks", (*GetOrphanBlocksCmd)(nil), flags)
+	MustRegisterCmd("getpeerlimits", (*GetPeerLimitsCmd)(nil), flags)
+	MustRegisterCmd("getpruneinfo", (*GetPruneInfoCmd)(nil), flags)
+	MustRegisterCmd("getrelaypolicy", (*GetRelayPolicyCmd)(nil), flags)
+	MustRegisterCmd("gettransactionstatus", (*GetTransactionStatusCmd)(nil), flags)
+	MustRegisterCmd("gettxconfirmations", (*GetTxConfirmationsCmd)(nil), flags)
 	MustRegisterCmd("renderdag", (*RenderDagCmd)(nil), flags)
+	MustRegisterCmd("resubmittransaction", (*ResubmitTransactionCmd)(nil), flags)
+	MustRegisterCmd("setmaxpeers", (*SetMaxPeersCmd)(nil), flags)
+	MustRegisterCmd("testblockacceptance", (*TestBlockAcceptanceCmd)(nil), flags)
 	MustRegisterCmd("version", (*VersionCmd)(nil), flags)
 }
@@ -44,6 +44,19 @@ func TestSoterdExtCmds(t *testing.T) {
 				LevelSpec: "trace",
 			},
 		},
+		{
+			name: "decodedagheader",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("decodedagheader", "0100")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewDecodeDagHeaderCmd("0100")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"decodedagheader","params":["0100"],"id":1}`,
+			unmarshalled: &soterjson.DecodeDagHeaderCmd{
+				HexHeader: "0100",
+			},
+		},
 		{
 			name: "node",
 			newCmd: func() (interface{}, error) {
@@ -115,6 +128,34 @@ func TestSoterdExtCmds(t *testing.T) {
 				NumBlocks: 1,
 			},
 		},
+		{
+			name: "generateidempotent",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("generateidempotent", "job-1", 2)
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGenerateIdempotentCmd("job-1", 2)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generateidempotent","params":["job-1",2],"id":1}`,
+			unmarshalled: &soterjson.GenerateIdempotentCmd{
+				Token:     "job-1",
+				NumBlocks: 2,
+			},
+		},
+		{
+			name: "generatewithparents",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("generatewithparents", 1, []string{"123", "456"})
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGenerateWithParentsCmd(1, []string{"123", "456"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generatewithparents","params":[1,["123","456"]],"id":1}`,
+			unmarshalled: &soterjson.GenerateWithParentsCmd{
+				NumBlocks: 1,
+				Parents:   []string{"123", "456"},
+			},
+		},
 		{
 			name: "getbestblock",
 			newCmd: func() (interface{}, error) {
@@ -128,7 +169,7 @@ func TestSoterdExtCmds(t *testing.T) {
 		},
 		{
 			name: "getaddrcache",
-			newCmd: func () (interface{}, error) {
+			newCmd: func() (interface{}, error) {
 				return soterjson.NewCmd("getaddrcache")
 			},
 			staticCmd: func() interface{} {
@@ -137,6 +178,34 @@ func TestSoterdExtCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getaddrcache","params":[],"id":1}`,
 			unmarshalled: &soterjson.GetAddrCacheCmd{},
 		},
+		{
+			name: "getanticone",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("getanticone", "123")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetAnticoneCmd("123", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getanticone","params":["123"],"id":1}`,
+			unmarshalled: &soterjson.GetAnticoneCmd{
+				Hash:  "123",
+				Limit: soterjson.Int32(0),
+			},
+		},
+		{
+			name: "getanticone optional",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("getanticone", "123", 10)
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetAnticoneCmd("123", soterjson.Int32(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getanticone","params":["123",10],"id":1}`,
+			unmarshalled: &soterjson.GetAnticoneCmd{
+				Hash:  "123",
+				Limit: soterjson.Int32(10),
+			},
+		},
 		{
 			name: "getcurrentnet",
 			newCmd: func() (interface{}, error) {
@@ -148,6 +217,193 @@ func TestSoterdExtCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getcurrentnet","params":[],"id":1}`,
 			unmarshalled: &soterjson.GetCurrentNetCmd{},
 		},
+		{
+			name: "getdagblockhashes",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("getdagblockhashes", 123)
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetDagBlockHashesCmd(123)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getdagblockhashes","params":[123],"id":1}`,
+			unmarshalled: &soterjson.GetDagBlockHashesCmd{
+				Height: 123,
+			},
+		},
+		{
+			name: "getdaghashps",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("getdaghashps")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetDagHashPSCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getdaghashps","params":[],"id":1}`,
+			unmarshalled: &soterjson.GetDagHashPSCmd{
+				Blocks: soterjson.Int32(120),
+				Height: soterjson.Int32(-1),
+			},
+		},
+		{
+			name: "getdaghashps optional",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("getdaghashps", 200, 123)
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetDagHashPSCmd(soterjson.Int32(200), soterjson.Int32(123))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getdaghashps","params":[200,123],"id":1}`,
+			unmarshalled: &soterjson.GetDagHashPSCmd{
+				Blocks: soterjson.Int32(200),
+				Height: soterjson.Int32(123),
+			},
+		},
+		{
+			name: "getdaginfo",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("getdaginfo")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetDagInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getdaginfo","params":[],"id":1}`,
+			unmarshalled: &soterjson.GetDagInfoCmd{},
+		},
+		{
+			name: "getdagorderhash",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("getdagorderhash", 12)
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetDagOrderHashCmd(12)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getdagorderhash","params":[12],"id":1}`,
+			unmarshalled: &soterjson.GetDagOrderHashCmd{
+				Index: 12,
+			},
+		},
+		{
+			name: "getdagpath",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("getdagpath", "123", "456")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetDagPathCmd("123", "456")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getdagpath","params":["123","456"],"id":1}`,
+			unmarshalled: &soterjson.GetDagPathCmd{
+				From: "123",
+				To:   "456",
+			},
+		},
+		{
+			name: "getdagtips",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("getdagtips")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetDAGTipsCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getdagtips","params":[],"id":1}`,
+			unmarshalled: &soterjson.GetDAGTipsCmd{
+				Offset: soterjson.Int32(0),
+				Limit:  soterjson.Int32(0),
+			},
+		},
+		{
+			name: "getdagtips optional",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("getdagtips", 100, 50)
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetDAGTipsCmd(soterjson.Int32(100),
+					soterjson.Int32(50))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getdagtips","params":[100,50],"id":1}`,
+			unmarshalled: &soterjson.GetDAGTipsCmd{
+				Offset: soterjson.Int32(100),
+				Limit:  soterjson.Int32(50),
+			},
+		},
+		{
+			name: "getdagtipstatus",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("getdagtipstatus")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetDAGTipStatusCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getdagtipstatus","params":[],"id":1}`,
+			unmarshalled: &soterjson.GetDAGTipStatusCmd{},
+		},
+		{
+			name: "checkprunedag",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("checkprunedag", 123)
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewCheckPruneDagCmd(123)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"checkprunedag","params":[123],"id":1}`,
+			unmarshalled: &soterjson.CheckPruneDagCmd{
+				Height: 123,
+			},
+		},
+		{
+			name: "clearorphans",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("clearorphans")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewClearOrphansCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"clearorphans","params":[],"id":1}`,
+			unmarshalled: &soterjson.ClearOrphansCmd{},
+		},
+		{
+			name: "getorphanblocks",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("getorphanblocks")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetOrphanBlocksCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getorphanblocks","params":[],"id":1}`,
+			unmarshalled: &soterjson.GetOrphanBlocksCmd{},
+		},
+		{
+			name: "getpeerlimits",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("getpeerlimits")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetPeerLimitsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getpeerlimits","params":[],"id":1}`,
+			unmarshalled: &soterjson.GetPeerLimitsCmd{},
+		},
+		{
+			name: "getpruneinfo",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("getpruneinfo")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetPruneInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getpruneinfo","params":[],"id":1}`,
+			unmarshalled: &soterjson.GetPruneInfoCmd{},
+		},
+		{
+			name: "getrelaypolicy",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("getrelaypolicy")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetRelayPolicyCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getrelaypolicy","params":[],"id":1}`,
+			unmarshalled: &soterjson.GetRelayPolicyCmd{},
+		},
 		{
 			name: "getheaders",
 			newCmd: func() (interface{}, error) {
@@ -187,7 +443,72 @@ func TestSoterdExtCmds(t *testing.T) {
 				},
 				HashStop: "000000000000000000ba33b33e1fad70b69e234fc24414dd47113bff38f523f7",
 			},
+		}, {
+			name: "gettransactionstatus",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("gettransactionstatus", "123")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetTransactionStatusCmd("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"gettransactionstatus","params":["123"],"id":1}`,
+			unmarshalled: &soterjson.GetTransactionStatusCmd{
+				Txid: "123",
+			},
+		}, {
+			name: "gettxconfirmations",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("gettxconfirmations", "123")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewGetTxConfirmationsCmd("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"gettxconfirmations","params":["123"],"id":1}`,
+			unmarshalled: &soterjson.GetTxConfirmationsCmd{
+				Txid: "123",
+			},
 		},
+		{
+			name: "resubmittransaction",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("resubmittransaction", "00")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewResubmitTransactionCmd("00")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"resubmittransaction","params":["00"],"id":1}`,
+			unmarshalled: &soterjson.ResubmitTransactionCmd{
+				HexTx: "00",
+			},
+		},
+		{
+			name: "setmaxpeers",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("setmaxpeers", 1, 8)
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewSetMaxPeersCmd(1, 8)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setmaxpeers","params":[1,8],"id":1}`,
+			unmarshalled: &soterjson.SetMaxPeersCmd{
+				Inbound:  1,
+				Outbound: 8,
+			},
+		},
+		{
+			name: "testblockacceptance",
+			newCmd: func() (interface{}, error) {
+				return soterjson.NewCmd("testblockacceptance", "00")
+			},
+			staticCmd: func() interface{} {
+				return soterjson.NewTestBlockAcceptanceCmd("00")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"testblockacceptance","params":["00"],"id":1}`,
+			unmarshalled: &soterjson.TestBlockAcceptanceCmd{
+				HexBlock: "00",
+			},
+		},
+
 		{
 			name: "version",
 			newCmd: func() (interface{}, error) {
@@ -20,24 +20,129 @@ type VersionResult struct {
 	BuildMetadata string `json:"buildmetadata"`
 }
 
+// DecodeDagHeaderResult models the data returned from the decodedagheader
+// command.
+//
+// PreviousHash is the hash of the tips the block references, which takes the
+// place of the previous block hash in the header, and Parents are the hashes of
+// the parents of the block.
+type DecodeDagHeaderResult struct {
+	Hash          string   `json:"hash"`
+	Version       int32    `json:"version"`
+	VersionHex    string   `json:"versionHex"`
+	PreviousHash  string   `json:"previousblockhash"`
+	MerkleRoot    string   `json:"merkleroot"`
+	Time          int64    `json:"time"`
+	Bits          string   `json:"bits"`
+	Nonce         uint32   `json:"nonce"`
+	ParentVersion int32    `json:"parentversion"`
+	Parents       []string `json:"parents"`
+}
+
 // GetAddrCacheResult models the data returned from the getaddrcache RPC command.
 type GetAddrCacheResult struct {
 	Addresses []string `json:"addresses"`
 }
 
+// GetAnticoneResult models the data returned from the getanticone command.
+//
+// Anticone holds the hashes of the blocks that are neither ancestors nor
+// descendants of the block, ordered by height and then hash.  Size is the
+// number of blocks in the whole anticone, and Truncated is set when Anticone
+// was cut short by the limit of the request.
+type GetAnticoneResult struct {
+	Hash      string   `json:"hash"`
+	Size      int32    `json:"size"`
+	Anticone  []string `json:"anticone"`
+	Truncated bool     `json:"truncated"`
+}
+
 // GetDAGColoringResult models the data returned from the getdagcoloring command.
 type GetDAGColoringResult struct {
 	Hash string `json:"hash"`
 	IsBlue bool `json:"isblue"`
+	Order int `json:"order"`
+}
+
+// GetDagInfoResult models the data returned from the getdaginfo command.
+type GetDagInfoResult struct {
+	TipCount        int    `json:"tipcount"`
+	BlkCount        uint32 `json:"blkcount"`
+	MaxHeight       int32  `json:"maxheight"`
+	BlueSetSize     int    `json:"bluesetsize"`
+	OrphanCount     int    `json:"orphancount"`
+	MaxBlockParents int32  `json:"maxblockparents"`
+}
+
+// GetDagOrderHashResult models the data returned from the getdagorderhash
+// command.
+//
+// StableLength is the number of positions at the start of the dag ordering
+// that new blocks aren't expected to change, and Stable is whether the position
+// is one of them.
+type GetDagOrderHashResult struct {
+	Hash         string `json:"hash"`
+	Index        int32  `json:"index"`
+	Length       int32  `json:"length"`
+	StableLength int32  `json:"stablelength"`
+	Stable       bool   `json:"stable"`
+}
+
+// DAGTip models the data of a single dag tip returned from the getdagtips
+// command.
+type DAGTip struct {
+	Hash   string `json:"hash"`
+	Height int32  `json:"height"`
+	Work   string `json:"work"`
 }
 
 // GetDAGTipsResult models the data returned from the getdagtips command.
+//
+// Tips and TipInfo hold a single page of the tips, while the other fields
+// describe the whole dag.  NextOffset is the offset of the next page, or 0
+// when this is the last page.
 type GetDAGTipsResult struct {
 	Tips []string `json:"tips"`
 	Hash	string	`json:"hash"`
 	MinHeight int32 `json:"minheight"`
 	MaxHeight int32 `json:"maxheight"`
 	BlkCount uint32 `json:"blkcount"`
+	TipInfo []DAGTip `json:"tipinfo"`
+	DagWork string `json:"dagwork"`
+	TipCount int32 `json:"tipcount"`
+	NextOffset int32 `json:"nextoffset,omitempty"`
+}
+
+const (
+	// DAGTipStatusActive is the status of a tip of the dag that new blocks
+	// reference as a parent.
+	DAGTipStatusActive = "active"
+
+	// DAGTipStatusValidFork is the status of a valid tip of the dag that
+	// new blocks don't reference, because there are more tips than a block
+	// may reference.
+	DAGTipStatusValidFork = "valid-fork"
+
+	// DAGTipStatusValidHeaders is the status of an orphan block that no
+	// other orphan builds on.  It passed the checks that don't depend on
+	// its parents, but some of its parents are missing.
+	DAGTipStatusValidHeaders = "valid-headers"
+
+	// DAGTipStatusInvalid is the status of a block that failed validation.
+	DAGTipStatusInvalid = "invalid"
+)
+
+// GetDAGTipStatusResult models the data of a single tip returned from the
+// getdagtipstatus command.
+//
+// BranchLen is the number of generations between the tip and its nearest
+// ancestor in the past of the active tips, or, for orphans, the number of
+// generations of orphans ending at the tip.
+type GetDAGTipStatusResult struct {
+	Hash      string `json:"hash"`
+	Height    int32  `json:"height"`
+	BranchLen int32  `json:"branchlen"`
+	Status    string `json:"status"`
 }
 
 // GetBlockMetricsResult models the data returned from the getblockmetrics RPC command.
@@ -52,7 +157,102 @@ type GetListenAddrsResult struct {
 	P2P []string `json:"p2p"`
 }
 
+// OrphanBlock models the data of a single orphan block returned from the
+// getorphanblocks command.
+type OrphanBlock struct {
+	Hash           string   `json:"hash"`
+	MissingParents []string `json:"missingparents"`
+	Expiration     int64    `json:"expiration"`
+}
+
+// GetOrphanBlocksResult models the data returned from the getorphanblocks
+// command.
+type GetOrphanBlocksResult struct {
+	Total   int           `json:"total"`
+	Orphans []OrphanBlock `json:"orphans"`
+}
+
+// GetPeerLimitsResult models the data returned from the getpeerlimits command.
+type GetPeerLimitsResult struct {
+	MaxInbound  int32 `json:"maxinbound"`
+	MaxOutbound int32 `json:"maxoutbound"`
+	Inbound     int32 `json:"inbound"`
+	Outbound    int32 `json:"outbound"`
+}
+
+// GetPruneInfoResult models the data returned from the getpruneinfo command.
+type GetPruneInfoResult struct {
+	Pruned           bool  `json:"pruned"`
+	PruneHeight      int32 `json:"pruneheight"`
+	MaxPruneHeight   int32 `json:"maxpruneheight"`
+	ReclaimableBytes int64 `json:"reclaimablebytes"`
+}
+
+// GetRelayPolicyResult models the data returned from the getrelaypolicy
+// command.
+type GetRelayPolicyResult struct {
+	AcceptNonStd         bool    `json:"acceptnonstd"`
+	MaxOpReturnSize      int     `json:"maxopreturnsize"`
+	MaxTxVersion         int32   `json:"maxtxversion"`
+	MinRelayTxFee        float64 `json:"minrelaytxfee"`
+	DisableRelayPriority bool    `json:"disablerelaypriority"`
+}
+
+const (
+	// TxStatusConfirmed is the status of a transaction that's in a block of
+	// the dag.
+	TxStatusConfirmed = "confirmed"
+
+	// TxStatusMempool is the status of a transaction that's in the memory
+	// pool, waiting to be included in a block.
+	TxStatusMempool = "mempool"
+
+	// TxStatusUnknown is the status of a transaction that the node doesn't
+	// know about.
+	TxStatusUnknown = "unknown"
+)
+
 // RenderDagResult models the data returned from the renderdag RPC call.
 type RenderDagResult struct {
 	Dot string `json:"dot"`
-}
\ No newline at end of file
+}
+// ResubmitTransactionResult models the data returned from the
+// resubmittransaction command.
+//
+// Rebroadcast is whether the transaction was already in the memory pool, and
+// Peers is the number of connected peers its announcement was queued to.  A
+// peer that already knows about the transaction skips the announcement, and
+// peers don't acknowledge announcements, so a transaction may still be
+// rejected by the peers it was queued to.
+type ResubmitTransactionResult struct {
+	TxID        string `json:"txid"`
+	Rebroadcast bool   `json:"rebroadcast"`
+	Peers       int32  `json:"peers"`
+}
+
+const (
+	// BlockAcceptanceAccepted is the status of a block that would be
+	// accepted into the dag.
+	BlockAcceptanceAccepted = "accepted"
+
+	// BlockAcceptanceRejected is the status of a block that would be
+	// rejected.
+	BlockAcceptanceRejected = "rejected"
+
+	// BlockAcceptanceOrphan is the status of a block that would be added to
+	// the orphan pool, because some of its parents aren't known.
+	BlockAcceptanceOrphan = "orphan"
+)
+
+// TestBlockAcceptanceResult models the data returned from the
+// testblockacceptance command.
+//
+// Reason explains why a block would be rejected or orphaned, and Height is the
+// height the block would have in the dag, which is only known when all of its
+// parents are.
+type TestBlockAcceptanceResult struct {
+	Hash   string `json:"hash"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+	Height int32  `json:"height,omitempty"`
+}
@@ -36,6 +36,22 @@ func TestSoterdExtCustomResults(t *testing.T) {
 			},
 			expected: `{"versionstring":"1.0.0","major":1,"minor":0,"patch":0,"prerelease":"pr","buildmetadata":"bm"}`,
 		},
+		{
+			name: "decodedagheaderresult",
+			result: &soterjson.DecodeDagHeaderResult{
+				Hash:          "0a",
+				Version:       1,
+				VersionHex:    "00000001",
+				PreviousHash:  "0b",
+				MerkleRoot:    "0c",
+				Time:          1543949845,
+				Bits:          "207fffff",
+				Nonce:         2,
+				ParentVersion: 1,
+				Parents:       []string{"0d", "0e"},
+			},
+			expected: `{"hash":"0a","version":1,"versionHex":"00000001","previousblockhash":"0b","merkleroot":"0c","time":1543949845,"bits":"207fffff","nonce":2,"parentversion":1,"parents":["0d","0e"]}`,
+		},
 		{
 			name: "getaddrcacheresult",
 			result: &soterjson.GetAddrCacheResult{
@@ -43,6 +59,155 @@ func TestSoterdExtCustomResults(t *testing.T) {
 			},
 			expected: `{"addresses":["127.0.0.1:18555"]}`,
 		},
+		{
+			name: "getanticoneresult",
+			result: &soterjson.GetAnticoneResult{
+				Hash:      "0a",
+				Size:      3,
+				Anticone:  []string{"0b", "0c"},
+				Truncated: true,
+			},
+			expected: `{"hash":"0a","size":3,"anticone":["0b","0c"],"truncated":true}`,
+		},
+		{
+			name: "getdaginforesult",
+			result: &soterjson.GetDagInfoResult{
+				TipCount:        2,
+				BlkCount:        4,
+				MaxHeight:       2,
+				BlueSetSize:     3,
+				OrphanCount:     1,
+				MaxBlockParents: 8,
+			},
+			expected: `{"tipcount":2,"blkcount":4,"maxheight":2,"bluesetsize":3,"orphancount":1,"maxblockparents":8}`,
+		},
+		{
+			name: "getdagorderhashresult",
+			result: &soterjson.GetDagOrderHashResult{
+				Hash:         "0a",
+				Index:        12,
+				Length:       90,
+				StableLength: 20,
+				Stable:       true,
+			},
+			expected: `{"hash":"0a","index":12,"length":90,"stablelength":20,"stable":true}`,
+		},
+		{
+			name: "getorphanblocksresult",
+			result: &soterjson.GetOrphanBlocksResult{
+				Total: 1,
+				Orphans: []soterjson.OrphanBlock{
+					{
+						Hash:           "123",
+						MissingParents: []string{"456"},
+						Expiration:     1543949845,
+					},
+				},
+			},
+			expected: `{"total":1,"orphans":[{"hash":"123","missingparents":["456"],"expiration":1543949845}]}`,
+		},
+		{
+			name: "getpeerlimitsresult",
+			result: &soterjson.GetPeerLimitsResult{
+				MaxInbound:  117,
+				MaxOutbound: 8,
+				Inbound:     2,
+				Outbound:    3,
+			},
+			expected: `{"maxinbound":117,"maxoutbound":8,"inbound":2,"outbound":3}`,
+		},
+		{
+			name: "getpruneinforesult",
+			result: &soterjson.GetPruneInfoResult{
+				Pruned:           false,
+				PruneHeight:      0,
+				MaxPruneHeight:   5,
+				ReclaimableBytes: 1024,
+			},
+			expected: `{"pruned":false,"pruneheight":0,"maxpruneheight":5,"reclaimablebytes":1024}`,
+		},
+		{
+			name: "getrelaypolicyresult",
+			result: &soterjson.GetRelayPolicyResult{
+				AcceptNonStd:         true,
+				MaxOpReturnSize:      80,
+				MaxTxVersion:         2,
+				MinRelayTxFee:        0.00001,
+				DisableRelayPriority: false,
+			},
+			expected: `{"acceptnonstd":true,"maxopreturnsize":80,"maxtxversion":2,"minrelaytxfee":0.00001,"disablerelaypriority":false}`,
+		},
+		{
+			name: "resubmittransactionresult",
+			result: &soterjson.ResubmitTransactionResult{
+				TxID:        "0a",
+				Rebroadcast: true,
+				Peers:       3,
+			},
+			expected: `{"txid":"0a","rebroadcast":true,"peers":3}`,
+		},
+		{
+			name: "getdagtipsresult",
+			result: &soterjson.GetDAGTipsResult{
+				Tips:      []string{"0a", "0b"},
+				Hash:      "0c",
+				MinHeight: 1,
+				MaxHeight: 2,
+				BlkCount:  4,
+				TipInfo: []soterjson.DAGTip{
+					{Hash: "0a", Height: 1, Work: "02"},
+					{Hash: "0b", Height: 2, Work: "02"},
+				},
+				DagWork:  "06",
+				TipCount: 2,
+			},
+			expected: `{"tips":["0a","0b"],"hash":"0c","minheight":1,"maxheight":2,"blkcount":4,"tipinfo":[{"hash":"0a","height":1,"work":"02"},{"hash":"0b","height":2,"work":"02"}],"dagwork":"06","tipcount":2}`,
+		},
+		{
+			name: "getdagtipsresult paged",
+			result: &soterjson.GetDAGTipsResult{
+				Tips:      []string{"0a"},
+				Hash:      "0c",
+				MinHeight: 1,
+				MaxHeight: 2,
+				BlkCount:  4,
+				TipInfo: []soterjson.DAGTip{
+					{Hash: "0a", Height: 1, Work: "02"},
+				},
+				DagWork:    "06",
+				TipCount:   2,
+				NextOffset: 1,
+			},
+			expected: `{"tips":["0a"],"hash":"0c","minheight":1,"maxheight":2,"blkcount":4,"tipinfo":[{"hash":"0a","height":1,"work":"02"}],"dagwork":"06","tipcount":2,"nextoffset":1}`,
+		},
+		{
+			name: "getdagtipstatusresult",
+			result: &soterjson.GetDAGTipStatusResult{
+				Hash:      "0a",
+				Height:    3,
+				BranchLen: 1,
+				Status:    soterjson.DAGTipStatusValidFork,
+			},
+			expected: `{"hash":"0a","height":3,"branchlen":1,"status":"valid-fork"}`,
+		},
+		{
+			name: "testblockacceptanceresult accepted",
+			result: &soterjson.TestBlockAcceptanceResult{
+				Hash:   "0a",
+				Status: soterjson.BlockAcceptanceAccepted,
+				Height: 3,
+			},
+			expected: `{"hash":"0a","status":"accepted","height":3}`,
+		},
+		{
+			name: "testblockacceptanceresult orphan",
+			result: &soterjson.TestBlockAcceptanceResult{
+				Hash:   "0a",
+				Status: soterjson.BlockAcceptanceOrphan,
+				Reason: "parent 0b is unknown",
+			},
+			expected: `{"hash":"0a","status":"orphan","reason":"parent 0b is unknown"}`,
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
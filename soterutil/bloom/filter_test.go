@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+	"github.com/soteria-dag/soterd/txscript"
 	"github.com/soteria-dag/soterd/wire"
 	"github.com/soteria-dag/soterd/soterutil"
 	"github.com/soteria-dag/soterd/soterutil/bloom"
@@ -668,3 +669,50 @@ func TestFilterReload(t *testing.T) {
 		t.Errorf("TestFilterReload Reload test failed")
 	}
 }
+
+// TestFilterLoadMatchesScript ensures a filter sent over the wire in a
+// filterload message, and loaded by the receiver, matches a transaction paying
+// to an output script whose data push was added to the filter.
+func TestFilterLoadMatchesScript(t *testing.T) {
+	pkHash := chainhash.HashB([]byte("output key"))[:20]
+	pkScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_DUP).
+		AddOp(txscript.OP_HASH160).AddData(pkHash).
+		AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		t.Fatalf("unable to build output script: %v", err)
+	}
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{0x01}, 0),
+		nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, pkScript))
+
+	f := bloom.NewFilter(10, 0, 0.000001, wire.BloomUpdateNone)
+	f.Add(pkHash)
+
+	var buf bytes.Buffer
+	err = f.MsgFilterLoad().SotoEncode(&buf, wire.ProtocolVersion,
+		wire.BaseEncoding)
+	if err != nil {
+		t.Fatalf("unable to encode filterload: %v", err)
+	}
+	var msg wire.MsgFilterLoad
+	err = msg.SotoDecode(&buf, wire.ProtocolVersion, wire.BaseEncoding)
+	if err != nil {
+		t.Fatalf("unable to decode filterload: %v", err)
+	}
+
+	loaded := bloom.LoadFilter(&msg)
+	if !loaded.MatchTxAndUpdate(soterutil.NewTx(tx)) {
+		t.Fatalf("loaded filter doesn't match a transaction paying to " +
+			"the output script")
+	}
+
+	other := wire.NewMsgTx(wire.TxVersion)
+	other.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{0x02}, 0),
+		nil, nil))
+	other.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+	if loaded.MatchTxAndUpdate(soterutil.NewTx(other)) {
+		t.Fatalf("loaded filter matches an unrelated transaction")
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package soterutil
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+const (
+	// asciiNode is the glyph drawn for a block.
+	asciiNode = '*'
+
+	// asciiLane is the glyph drawn for a lane leading down to a parent that
+	// hasn't been drawn yet.
+	asciiLane = '|'
+
+	// asciiEdge is the glyph drawn where a parent link of a block joins the
+	// lane leading to the parent.  There's one for each parent link.
+	asciiEdge = '+'
+
+	// asciiOrigin is the glyph drawn below a block whose parent links all
+	// join lanes in other columns.
+	asciiOrigin = '`'
+
+	// asciiLink is the glyph drawn for the horizontal part of links between
+	// columns.
+	asciiLink = '-'
+)
+
+// ASCIIBlock is a block of a dag rendered by RenderDagASCII.
+type ASCIIBlock struct {
+	// Hash is the hash of the block, as a string.
+	Hash string
+
+	// Height is the height of the block in the dag.
+	Height int32
+
+	// Parents are the hashes of the parents of the block.
+	Parents []string
+}
+
+// asciiGraph tracks the lanes of a dag being rendered by RenderDagASCII.  Each
+// lane is a column leading down to a block that hasn't been drawn yet.
+type asciiGraph struct {
+	// lanes are the hashes of the blocks the columns lead to, with an empty
+	// hash for columns that are free.
+	lanes []string
+
+	// rows are the rendered rows, with the label of the block drawn on the
+	// row, if any.
+	rows   [][]byte
+	labels []string
+}
+
+// cells returns a row with a lane glyph in each column that isn't free.
+func (g *asciiGraph) cells() []byte {
+	cells := bytes.Repeat([]byte{' '}, 2*len(g.lanes))
+	for i, hash := range g.lanes {
+		if hash != "" {
+			cells[2*i] = asciiLane
+		}
+	}
+	return cells
+}
+
+// drawLink draws the horizontal part of a link between the columns from and
+// to, leaving the lanes that it crosses drawn.
+func drawLink(cells []byte, from, to int) {
+	if from > to {
+		from, to = to, from
+	}
+	for i := 2*from + 1; i < 2*to; i++ {
+		if cells[i] == ' ' {
+			cells[i] = asciiLink
+		}
+	}
+}
+
+// lane returns the column leading to the given block, or -1 if there's none.
+func (g *asciiGraph) lane(hash string) int {
+	for i, laneHash := range g.lanes {
+		if laneHash == hash {
+			return i
+		}
+	}
+	return -1
+}
+
+// freeLane returns the leftmost free column, adding a column when all of them
+// are taken.
+func (g *asciiGraph) freeLane() int {
+	i := g.lane("")
+	if i < 0 {
+		g.lanes = append(g.lanes, "")
+		i = len(g.lanes) - 1
+	}
+	return i
+}
+
+// addRow adds a rendered row, with the label of the block drawn on it, if any.
+func (g *asciiGraph) addRow(cells []byte, label string) {
+	g.rows = append(g.rows, cells)
+	g.labels = append(g.labels, label)
+}
+
+// draw adds the rows for a block to the graph.
+func (g *asciiGraph) draw(block *ASCIIBlock) {
+	// The block is drawn in the lane leading to it.  Parent links join
+	// the lane of a parent when there already is one, so there's at most
+	// one.  Blocks without children are drawn in a free column.
+	col := g.lane(block.Hash)
+	if col < 0 {
+		col = g.freeLane()
+		g.lanes[col] = block.Hash
+	}
+
+	cells := g.cells()
+	cells[2*col] = asciiNode
+	label := block.Hash
+	if len(label) > dotLabelLen {
+		label = label[:dotLabelLen]
+	}
+	g.addRow(cells, fmt.Sprintf("%s (%d)", label, block.Height))
+
+	// Each parent link joins the lane leading to the parent, which starts
+	// below the block when there's no lane for the parent yet.
+	g.lanes[col] = ""
+	targets := make([]int, 0, len(block.Parents))
+	for _, parent := range block.Parents {
+		target := g.lane(parent)
+		if target < 0 {
+			target = col
+			if g.lanes[col] != "" {
+				target = g.freeLane()
+			}
+			g.lanes[target] = parent
+		}
+		targets = append(targets, target)
+	}
+	if len(targets) > 0 {
+		cells := g.cells()
+		cells[2*col] = asciiOrigin
+		for _, target := range targets {
+			cells[2*target] = asciiEdge
+			drawLink(cells, col, target)
+		}
+		g.addRow(cells, "")
+	}
+
+	// Drop the free columns on the right, so the graph only gets as wide
+	// as it needs to be.
+	for len(g.lanes) > 0 && g.lanes[len(g.lanes)-1] == "" {
+		g.lanes = g.lanes[:len(g.lanes)-1]
+	}
+}
+
+// RenderDagASCII returns a text representation of the dag, for debugging in a
+// terminal where graphviz isn't available.  The graph is drawn like git log
+// --graph, with a row for each block, from the highest blocks down to the
+// lowest ones.  Blocks at the same height are drawn in order of their hash, so
+// the output only depends on the dag, not on the order of the given blocks.
+//
+// Each block is drawn as a '*', labeled with the start of its hash and its
+// height.  Columns drawn with '|' lead down to the parents of the blocks above.
+// Below each block, every one of its parent links is drawn as a '+' where it
+// joins the column leading to the parent, with '-' connecting it to the
+// column of the block, or to a ` when none of the links start straight below
+// the block.
+//
+// An error is returned if a block is given more than once, or if a block has a
+// parent that isn't one of the blocks, or isn't below it.
+func RenderDagASCII(blocks []ASCIIBlock) ([]byte, error) {
+	index := make(map[string]*ASCIIBlock, len(blocks))
+	for i := range blocks {
+		block := &blocks[i]
+		if _, exists := index[block.Hash]; exists {
+			return nil, fmt.Errorf("block %s is in the dag more than once",
+				block.Hash)
+		}
+		index[block.Hash] = block
+	}
+	for _, block := range blocks {
+		for _, parent := range block.Parents {
+			p, exists := index[parent]
+			if !exists {
+				return nil, fmt.Errorf("parent %s of block %s isn't "+
+					"in the dag", parent, block.Hash)
+			}
+			if p.Height >= block.Height {
+				return nil, fmt.Errorf("parent %s of block %s isn't "+
+					"below it", parent, block.Hash)
+			}
+		}
+	}
+
+	order := make([]*ASCIIBlock, 0, len(blocks))
+	for i := range blocks {
+		order = append(order, &blocks[i])
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].Height != order[j].Height {
+			return order[i].Height > order[j].Height
+		}
+		return order[i].Hash < order[j].Hash
+	})
+
+	var g asciiGraph
+	for _, block := range order {
+		g.draw(block)
+	}
+
+	// Line the labels up after the widest row.
+	var width int
+	for _, cells := range g.rows {
+		if len(cells) > width {
+			width = len(cells)
+		}
+	}
+	var out bytes.Buffer
+	for i, cells := range g.rows {
+		if g.labels[i] == "" {
+			out.Write(bytes.TrimRight(cells, " "))
+		} else {
+			out.Write(cells)
+			out.Write(bytes.Repeat([]byte{' '}, width-len(cells)+1))
+			out.WriteString(g.labels[i])
+		}
+		out.WriteByte('\n')
+	}
+
+	return out.Bytes(), nil
+}
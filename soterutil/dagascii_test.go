@@ -0,0 +1,107 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package soterutil_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/soteria-dag/soterd/soterutil"
+)
+
+// TestRenderDagASCII ensures RenderDagASCII draws a node glyph for each block
+// and an edge connector for each parent link, that its output doesn't depend
+// on the order of the blocks, and that it rejects invalid dags.
+func TestRenderDagASCII(t *testing.T) {
+	// Build a dag where each block past the first height has all of the
+	// blocks of the previous height as parents, along with a chain on the
+	// side.
+	const width = 3
+	blocks := []soterutil.ASCIIBlock{{Hash: "genesis", Height: 0}}
+	prevHeight := []string{"genesis"}
+	wantEdges := 0
+	for height := int32(1); height < 4; height++ {
+		hashes := make([]string, 0, width)
+		for m := 0; m < width; m++ {
+			hash := fmt.Sprintf("%02d%02d", height, m)
+			blocks = append(blocks, soterutil.ASCIIBlock{
+				Hash:    hash,
+				Height:  height,
+				Parents: prevHeight,
+			})
+			wantEdges += len(prevHeight)
+			hashes = append(hashes, hash)
+		}
+		prevHeight = hashes
+	}
+	side := "genesis"
+	for height := int32(1); height < 6; height++ {
+		hash := fmt.Sprintf("side%d", height)
+		blocks = append(blocks, soterutil.ASCIIBlock{
+			Hash:    hash,
+			Height:  height,
+			Parents: []string{side},
+		})
+		wantEdges++
+		side = hash
+	}
+
+	out, err := soterutil.RenderDagASCII(blocks)
+	if err != nil {
+		t.Fatalf("RenderDagASCII failed: %v", err)
+	}
+
+	// The labels of the blocks don't contain any glyphs, so they're counted
+	// in the whole output.
+	nodes := bytes.Count(out, []byte("*"))
+	edges := bytes.Count(out, []byte("+"))
+	if nodes != len(blocks) {
+		t.Fatalf("got %d node glyphs, want %d:\n%s", nodes, len(blocks), out)
+	}
+	if edges != wantEdges {
+		t.Fatalf("got %d edge connectors, want %d:\n%s", edges, wantEdges,
+			out)
+	}
+
+	// Each block is labeled with the start of its hash and its height.
+	for _, block := range blocks {
+		label := block.Hash
+		if len(label) > 7 {
+			label = label[:7]
+		}
+		label = fmt.Sprintf("%s (%d)", label, block.Height)
+		if bytes.Count(out, []byte(label)) != 1 {
+			t.Fatalf("block %s isn't labeled %q once:\n%s", block.Hash,
+				label, out)
+		}
+	}
+
+	// The output is the same for the blocks in reverse order.
+	reversed := make([]soterutil.ASCIIBlock, len(blocks))
+	for i, block := range blocks {
+		reversed[len(blocks)-1-i] = block
+	}
+	again, err := soterutil.RenderDagASCII(reversed)
+	if err != nil {
+		t.Fatalf("RenderDagASCII of reversed blocks failed: %v", err)
+	}
+	if !bytes.Equal(out, again) {
+		t.Fatalf("output depends on the order of the blocks:\n%s\n%s",
+			out, again)
+	}
+
+	invalid := map[string][]soterutil.ASCIIBlock{
+		"duplicate block": {blocks[0], blocks[0]},
+		"unknown parent":  {blocks[width+1]},
+		"parent not below": {blocks[0], {Hash: "child", Height: 0,
+			Parents: []string{"genesis"}}},
+	}
+	for name, blocks := range invalid {
+		if _, err := soterutil.RenderDagASCII(blocks); err == nil {
+			t.Fatalf("%s: RenderDagASCII didn't return an error", name)
+		}
+	}
+}
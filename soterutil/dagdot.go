@@ -0,0 +1,264 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package soterutil
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// dotLabelLen is how many characters of a block hash are used for the
+	// label of the block in the graph.
+	dotLabelLen = 7
+
+	// dotParallelMinBlocks is the number of blocks from which DagToDot
+	// renders the graph concurrently.  Smaller graphs render faster without
+	// the overhead of starting goroutines.
+	dotParallelMinBlocks = 2000
+)
+
+// DotBlock is a block of a dag rendered by DagToDot.
+type DotBlock struct {
+	// Hash is the hash of the block, as a string.
+	Hash string
+
+	// Height is the height of the block in the dag.
+	Height int32
+
+	// Parents are the hashes of the parents of the block.
+	Parents []string
+
+	// Miner is the index of the node that created the block, or -1 if it's
+	// unknown.
+	Miner int
+
+	// IsBlue is whether the block is blue in the dag coloring.
+	IsBlue bool
+
+	// Observers are the indexes of the nodes whose dag has the block, in
+	// increasing order, as recorded by MergeDagViews.  It's empty when
+	// they're unknown.
+	Observers []int
+}
+
+// MergeDagViews merges the dags of several nodes into a single dag, so that
+// a network can be rendered as one graph without drawing the blocks the nodes
+// share more than once.  Each view holds the blocks of a node's dag, and the
+// index of a view is the index of its node.
+//
+// Each block is in the merged dag once, with the indexes of the nodes whose
+// view has it as its observers.  The block is otherwise taken from the first
+// view that has it, except that a miner missing from that view is taken from
+// the next view that knows it.  The blocks are ordered by height, and blocks
+// of the same height in the order they're first seen in the views, so a
+// single view is returned in its own order.
+//
+// An error is returned if two views have a block at different heights or with
+// different parents, since they can't be merged into a consistent dag.
+func MergeDagViews(views [][]DotBlock) ([]DotBlock, error) {
+	var merged []DotBlock
+	index := make(map[string]int)
+	for node, view := range views {
+		for _, block := range view {
+			n, exists := index[block.Hash]
+			if !exists {
+				block.Parents = append([]string(nil), block.Parents...)
+				block.Observers = []int{node}
+				index[block.Hash] = len(merged)
+				merged = append(merged, block)
+				continue
+			}
+
+			m := &merged[n]
+			if m.Height != block.Height ||
+				!equalStrings(m.Parents, block.Parents) {
+
+				return nil, fmt.Errorf("block %s differs between "+
+					"the views of nodes %d and %d", block.Hash,
+					m.Observers[0], node)
+			}
+			if m.Miner < 0 {
+				m.Miner = block.Miner
+			}
+			if m.Observers[len(m.Observers)-1] != node {
+				m.Observers = append(m.Observers, node)
+			}
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Height < merged[j].Height
+	})
+
+	return merged, nil
+}
+
+// equalStrings returns whether a and b hold the same strings in the same
+// order.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// DagToDot returns a representation of the dag in graphviz DOT file format.
+// The blocks are added to the graph in the given order, with an edge from each
+// block to each of its parents.  Blue blocks are drawn filled, and the others
+// dashed.
+//
+// When minerColor is non-nil, blocks are filled with the color it returns for
+// the index of the node that created them, in the graphviz #rrggbb format.
+// Blocks whose creator is unknown aren't colored.  Large dags are rendered
+// concurrently, so minerColor must be safe to call from several goroutines.
+//
+// An error is returned if a block is given more than once, or if a block has a
+// parent that isn't one of the blocks.
+func DagToDot(blocks []DotBlock, minerColor func(miner int) string) ([]byte, error) {
+	// graphIndex tracks block hash -> graph node number, which is used to
+	// connect parent-child blocks together.
+	graphIndex := make(map[string]int, len(blocks))
+	for n, block := range blocks {
+		if _, exists := graphIndex[block.Hash]; exists {
+			return nil, fmt.Errorf("block %s is in the dag more than once",
+				block.Hash)
+		}
+		graphIndex[block.Hash] = n
+	}
+	for _, block := range blocks {
+		for _, parent := range block.Parents {
+			if _, exists := graphIndex[parent]; !exists {
+				return nil, fmt.Errorf("parent %s of block %s isn't "+
+					"in the dag", parent, block.Hash)
+			}
+		}
+	}
+
+	workers := 1
+	if len(blocks) >= dotParallelMinBlocks {
+		workers = runtime.NumCPU()
+	}
+
+	return dagToDot(blocks, graphIndex, minerColor, workers), nil
+}
+
+// dagToDot renders the graph of the blocks using the given number of
+// goroutines.  The blocks are split into contiguous ranges, and each goroutine
+// renders the nodes and the edges of its range into buffers of their own.  The
+// node buffers and then the edge buffers are concatenated in the order of the
+// ranges, so the output is the same for any number of goroutines.
+func dagToDot(blocks []DotBlock, graphIndex map[string]int,
+	minerColor func(miner int) string, workers int) []byte {
+
+	if workers > len(blocks) {
+		workers = len(blocks)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	nodes := make([]bytes.Buffer, workers)
+	edges := make([]bytes.Buffer, workers)
+	rangeSize := (len(blocks) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		start := i * rangeSize
+		end := start + rangeSize
+		if end > len(blocks) {
+			end = len(blocks)
+		}
+
+		wg.Add(1)
+		go func(i, start, end int) {
+			defer wg.Done()
+			writeDotNodes(&nodes[i], blocks, start, end, minerColor)
+			writeDotEdges(&edges[i], blocks, start, end, graphIndex)
+		}(i, start, end)
+	}
+	wg.Wait()
+
+	// Specify that this graph is directed, and set the ID to 'dag'
+	var dot bytes.Buffer
+	size := len("digraph dag {\n}")
+	for i := range nodes {
+		size += nodes[i].Len() + edges[i].Len()
+	}
+	dot.Grow(size)
+	dot.WriteString("digraph dag {\n")
+
+	// Create a node in the graph for each block, then connect the nodes
+	// together
+	for i := range nodes {
+		dot.Write(nodes[i].Bytes())
+	}
+	for i := range edges {
+		dot.Write(edges[i].Bytes())
+	}
+
+	// Close the graph statement list
+	dot.WriteString("}")
+
+	return dot.Bytes()
+}
+
+// writeDotNodes writes a node of the graph for each of the blocks from start
+// up to end.
+func writeDotNodes(dot *bytes.Buffer, blocks []DotBlock, start, end int,
+	minerColor func(miner int) string) {
+
+	for n := start; n < end; n++ {
+		block := &blocks[n]
+		label := block.Hash
+		if len(label) > dotLabelLen {
+			label = label[len(label)-dotLabelLen:]
+		}
+
+		style := "filled, dashed"
+		if block.IsBlue {
+			style = "filled"
+		}
+
+		seenBy := ""
+		if len(block.Observers) > 0 {
+			observers := make([]string, 0, len(block.Observers))
+			for _, observer := range block.Observers {
+				observers = append(observers, strconv.Itoa(observer))
+			}
+			seenBy = " seen by nodes " + strings.Join(observers, ",")
+		}
+
+		if block.Miner >= 0 && minerColor != nil {
+			fmt.Fprintf(dot, "n%d [label=\"%s\", tooltip=\"node %d height %d hash %s%s\", fillcolor=\"%s\", style=\"%s\"];\n",
+				n, label, block.Miner, block.Height, block.Hash,
+				seenBy, minerColor(block.Miner), style)
+		} else {
+			fmt.Fprintf(dot, "n%d [label=\"%s\", tooltip=\"height %d hash %s%s\", style=\"%s\"];\n",
+				n, label, block.Height, block.Hash, seenBy, style)
+		}
+	}
+}
+
+// writeDotEdges writes an edge of the graph from each of the blocks from start
+// up to end, to each of its parents.
+func writeDotEdges(dot *bytes.Buffer, blocks []DotBlock, start, end int,
+	graphIndex map[string]int) {
+
+	for n := start; n < end; n++ {
+		for _, parent := range blocks[n].Parents {
+			fmt.Fprintf(dot, "n%d -> n%d;\n", n, graphIndex[parent])
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package soterutil_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/soteria-dag/soterd/soterutil"
+)
+
+// TestDagToDot ensures DagToDot renders the expected graph, and rejects dags
+// with duplicate blocks or unknown parents.
+func TestDagToDot(t *testing.T) {
+	blocks := []soterutil.DotBlock{
+		{Hash: "00aaaaaaa1", Height: 0, Miner: 0, IsBlue: true},
+		{Hash: "00bbbbbbb2", Height: 1, Parents: []string{"00aaaaaaa1"},
+			Miner: -1},
+	}
+	color := func(miner int) string { return "#ff0000" }
+
+	tests := []struct {
+		name       string
+		minerColor func(int) string
+		want       string
+	}{
+		{"uncolored", nil, "digraph dag {\n" +
+			"n0 [label=\"aaaaaa1\", tooltip=\"height 0 hash 00aaaaaaa1\", style=\"filled\"];\n" +
+			"n1 [label=\"bbbbbb2\", tooltip=\"height 1 hash 00bbbbbbb2\", style=\"filled, dashed\"];\n" +
+			"n1 -> n0;\n}"},
+		{"colored", color, "digraph dag {\n" +
+			"n0 [label=\"aaaaaa1\", tooltip=\"node 0 height 0 hash 00aaaaaaa1\", fillcolor=\"#ff0000\", style=\"filled\"];\n" +
+			"n1 [label=\"bbbbbb2\", tooltip=\"height 1 hash 00bbbbbbb2\", style=\"filled, dashed\"];\n" +
+			"n1 -> n0;\n}"},
+	}
+
+	for _, test := range tests {
+		dot, err := soterutil.DagToDot(blocks, test.minerColor)
+		if err != nil {
+			t.Fatalf("%s: DagToDot failed: %v", test.name, err)
+		}
+		if string(dot) != test.want {
+			t.Fatalf("%s: wrong dot output - got:\n%s\nwant:\n%s",
+				test.name, dot, test.want)
+		}
+	}
+
+	invalid := map[string][]soterutil.DotBlock{
+		"duplicate block": {blocks[0], blocks[0]},
+		"unknown parent":  {blocks[1]},
+	}
+	for name, blocks := range invalid {
+		if _, err := soterutil.DagToDot(blocks, nil); err == nil {
+			t.Fatalf("%s: DagToDot didn't return an error", name)
+		}
+	}
+}
+
+// TestMergeDagViews ensures MergeDagViews draws each block once, with the
+// union of the nodes that have it as its observers.
+func TestMergeDagViews(t *testing.T) {
+	genesis := soterutil.DotBlock{Hash: "00", Height: 0, Miner: -1,
+		IsBlue: true}
+	a := soterutil.DotBlock{Hash: "0a", Height: 1, Parents: []string{"00"},
+		Miner: 0, IsBlue: true}
+	b := soterutil.DotBlock{Hash: "0b", Height: 1, Parents: []string{"00"},
+		Miner: -1}
+	c := soterutil.DotBlock{Hash: "0c", Height: 2,
+		Parents: []string{"0a", "0b"}, Miner: 2, IsBlue: true}
+
+	// Node 1 doesn't know who mined b, and node 2 does.  Only node 2 has
+	// seen c, which it mined.
+	bMiner := b
+	bMiner.Miner = 1
+	views := [][]soterutil.DotBlock{
+		{genesis, a, b},
+		{genesis, b, a},
+		{genesis, bMiner, a, c},
+	}
+
+	merged, err := soterutil.MergeDagViews(views)
+	if err != nil {
+		t.Fatalf("MergeDagViews failed: %v", err)
+	}
+
+	want := []struct {
+		hash      string
+		miner     int
+		observers []int
+	}{
+		{"00", -1, []int{0, 1, 2}},
+		{"0a", 0, []int{0, 1, 2}},
+		{"0b", 1, []int{0, 1, 2}},
+		{"0c", 2, []int{2}},
+	}
+	if len(merged) != len(want) {
+		t.Fatalf("got %d merged blocks, want %d", len(merged), len(want))
+	}
+	for i, w := range want {
+		block := merged[i]
+		if block.Hash != w.hash || block.Miner != w.miner ||
+			!reflect.DeepEqual(block.Observers, w.observers) {
+
+			t.Fatalf("merged block %d is %s mined by %d and seen by "+
+				"%v, want %s mined by %d and seen by %v", i,
+				block.Hash, block.Miner, block.Observers, w.hash,
+				w.miner, w.observers)
+		}
+	}
+
+	// The merged dag is consistent, so it renders each block once.
+	if _, err := soterutil.DagToDot(merged, nil); err != nil {
+		t.Fatalf("DagToDot of the merged dag failed: %v", err)
+	}
+
+	// A block that differs between the views can't be merged.
+	conflict := a
+	conflict.Parents = []string{"0b"}
+	conflict.Height = 2
+	_, err = soterutil.MergeDagViews([][]soterutil.DotBlock{
+		{genesis, a}, {genesis, b, conflict},
+	})
+	if err == nil {
+		t.Fatalf("MergeDagViews of conflicting views didn't return " +
+			"an error")
+	}
+}
+
+// syntheticDag returns a dag of n blocks, where each block has the block before
+// it as a parent, and every third block also merges a block from further back.
+func syntheticDag(n int) []soterutil.DotBlock {
+	blocks := make([]soterutil.DotBlock, n)
+	for i := range blocks {
+		blocks[i] = soterutil.DotBlock{
+			Hash:   fmt.Sprintf("%064x", i),
+			Height: int32(i),
+			Miner:  i%5 - 1,
+			IsBlue: i%4 != 0,
+		}
+		if i > 0 {
+			blocks[i].Parents = append(blocks[i].Parents,
+				blocks[i-1].Hash)
+		}
+		if i > 3 && i%3 == 0 {
+			blocks[i].Parents = append(blocks[i].Parents,
+				blocks[i-3].Hash)
+		}
+	}
+	return blocks
+}
+
+// TestDagToDotParallel ensures rendering a dag concurrently produces the same
+// output as rendering it sequentially, for any number of goroutines.
+func TestDagToDotParallel(t *testing.T) {
+	color := func(miner int) string { return fmt.Sprintf("#%06x", miner) }
+
+	for _, size := range []int{0, 1, 7, 2500} {
+		blocks := syntheticDag(size)
+		want := soterutil.TstDagToDot(blocks, color, 1)
+
+		for _, workers := range []int{2, 3, 8, size + 1} {
+			got := soterutil.TstDagToDot(blocks, color, workers)
+			if !bytes.Equal(got, want) {
+				t.Fatalf("%d blocks: output with %d goroutines "+
+					"differs from sequential output", size,
+					workers)
+			}
+		}
+
+		got, err := soterutil.DagToDot(blocks, color)
+		if err != nil {
+			t.Fatalf("%d blocks: DagToDot failed: %v", size, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("%d blocks: DagToDot output differs from "+
+				"sequential output", size)
+		}
+	}
+}
+
+// BenchmarkDagToDot benchmarks rendering a 10k block dag sequentially and
+// concurrently.
+func BenchmarkDagToDot(b *testing.B) {
+	blocks := syntheticDag(10000)
+	color := func(miner int) string { return fmt.Sprintf("#%06x", miner) }
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			soterutil.TstDagToDot(blocks, color, 1)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			soterutil.TstDagToDot(blocks, color, runtime.NumCPU())
+		}
+	})
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package soterutil
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// graphMLNamespace is the XML namespace of GraphML documents.
+	graphMLNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+	// graphMLSchemaLocation is the location of the GraphML schema, given
+	// as the xsi:schemaLocation of GraphML documents.
+	graphMLSchemaLocation = graphMLNamespace +
+		" http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd"
+
+	// xmlSchemaInstanceNamespace is the XML namespace of the
+	// xsi:schemaLocation attribute.
+	xmlSchemaInstanceNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// GraphMLBlock is a block of a dag rendered by RenderDagGraphML.
+type GraphMLBlock struct {
+	// Hash is the hash of the block, as a string.
+	Hash string
+
+	// Height is the height of the block in the dag.
+	Height int32
+
+	// Parents are the hashes of the parents of the block.
+	Parents []string
+
+	// Miner is the index of the node that created the block, or -1 if it's
+	// unknown.
+	Miner int
+
+	// Timestamp is the time the block was created, from its header.
+	Timestamp time.Time
+}
+
+// graphMLKeys are the attributes of the nodes in the graph, declared in the
+// GraphML document.  The miner of a block defaults to -1, so that it's left
+// out for blocks whose creator is unknown.
+var graphMLKeys = []graphMLKey{
+	{ID: "hash", For: "node", Name: "hash", Type: "string"},
+	{ID: "height", For: "node", Name: "height", Type: "int"},
+	{ID: "miner", For: "node", Name: "miner", Type: "int", Default: "-1"},
+	{ID: "timestamp", For: "node", Name: "timestamp", Type: "long"},
+}
+
+// graphML is the root element of a GraphML document.
+type graphML struct {
+	XMLName        xml.Name     `xml:"graphml"`
+	Xmlns          string       `xml:"xmlns,attr"`
+	XmlnsXsi       string       `xml:"xmlns:xsi,attr"`
+	SchemaLocation string       `xml:"xsi:schemaLocation,attr"`
+	Keys           []graphMLKey `xml:"key"`
+	Graph          graphMLGraph `xml:"graph"`
+}
+
+// graphMLKey declares an attribute of the elements of a GraphML graph.
+type graphMLKey struct {
+	ID      string `xml:"id,attr"`
+	For     string `xml:"for,attr"`
+	Name    string `xml:"attr.name,attr"`
+	Type    string `xml:"attr.type,attr"`
+	Default string `xml:"default,omitempty"`
+}
+
+// graphMLGraph is a graph of a GraphML document.
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+// graphMLNode is a node of a GraphML graph, along with its attributes.
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+// graphMLEdge is an edge of a GraphML graph, from the source node to the
+// target node.
+type graphMLEdge struct {
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+// graphMLData is the value of an attribute of a GraphML node.
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// RenderDagGraphML returns a representation of the dag in GraphML format.
+// Each block is a node of the graph, in the given order, with the hash,
+// height, miner and timestamp of the block as attributes.  There's a directed
+// edge from each block to each of its parents.  The miner is left out for
+// blocks whose creator is unknown, and the timestamp is given in seconds since
+// the unix epoch.
+//
+// An error is returned if a block is given more than once, or if a block has a
+// parent that isn't one of the blocks.
+func RenderDagGraphML(blocks []GraphMLBlock) ([]byte, error) {
+	// graphIndex tracks block hash -> graph node number, which is used to
+	// connect parent-child blocks together.
+	graphIndex := make(map[string]int, len(blocks))
+	for n, block := range blocks {
+		if _, exists := graphIndex[block.Hash]; exists {
+			return nil, fmt.Errorf("block %s is in the dag more than once",
+				block.Hash)
+		}
+		graphIndex[block.Hash] = n
+	}
+
+	graph := graphMLGraph{
+		ID:          "dag",
+		EdgeDefault: "directed",
+		Nodes:       make([]graphMLNode, 0, len(blocks)),
+		Edges:       make([]graphMLEdge, 0),
+	}
+	for n, block := range blocks {
+		data := []graphMLData{
+			{Key: "hash", Value: block.Hash},
+			{Key: "height", Value: strconv.FormatInt(int64(block.Height), 10)},
+		}
+		if block.Miner >= 0 {
+			data = append(data, graphMLData{Key: "miner",
+				Value: strconv.Itoa(block.Miner)})
+		}
+		data = append(data, graphMLData{Key: "timestamp",
+			Value: strconv.FormatInt(block.Timestamp.Unix(), 10)})
+
+		graph.Nodes = append(graph.Nodes, graphMLNode{
+			ID:   graphMLNodeID(n),
+			Data: data,
+		})
+
+		for _, parent := range block.Parents {
+			p, exists := graphIndex[parent]
+			if !exists {
+				return nil, fmt.Errorf("parent %s of block %s isn't "+
+					"in the dag", parent, block.Hash)
+			}
+			graph.Edges = append(graph.Edges, graphMLEdge{
+				Source: graphMLNodeID(n),
+				Target: graphMLNodeID(p),
+			})
+		}
+	}
+
+	doc := graphML{
+		Xmlns:          graphMLNamespace,
+		XmlnsXsi:       xmlSchemaInstanceNamespace,
+		SchemaLocation: graphMLSchemaLocation,
+		Keys:           graphMLKeys,
+		Graph:          graph,
+	}
+
+	out, err := xml.MarshalIndent(&doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), out...), nil
+}
+
+// graphMLNodeID returns the id of the graph node with the given number.
+func graphMLNodeID(n int) string {
+	return "n" + strconv.Itoa(n)
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package soterutil_test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/soteria-dag/soterd/soterutil"
+)
+
+// parsedGraphML is the structure of a GraphML document, as parsed by the tests.
+type parsedGraphML struct {
+	XMLName xml.Name `xml:"http://graphml.graphdrawing.org/xmlns graphml"`
+	Keys    []struct {
+		ID   string `xml:"id,attr"`
+		For  string `xml:"for,attr"`
+		Name string `xml:"attr.name,attr"`
+		Type string `xml:"attr.type,attr"`
+	} `xml:"key"`
+	Graphs []struct {
+		ID          string `xml:"id,attr"`
+		EdgeDefault string `xml:"edgedefault,attr"`
+		Nodes       []struct {
+			ID   string `xml:"id,attr"`
+			Data []struct {
+				Key   string `xml:"key,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"data"`
+		} `xml:"node"`
+		Edges []struct {
+			Source string `xml:"source,attr"`
+			Target string `xml:"target,attr"`
+		} `xml:"edge"`
+	} `xml:"graph"`
+}
+
+// TestRenderDagGraphML ensures RenderDagGraphML emits a GraphML document with
+// the required elements, a node for each block and an edge for each parent,
+// and rejects dags with duplicate blocks or unknown parents.
+func TestRenderDagGraphML(t *testing.T) {
+	// Build a dag where each block past the first height has all of the
+	// blocks of the previous height as parents.
+	const width = 3
+	blocks := make([]soterutil.GraphMLBlock, 0)
+	var prevHeight []string
+	wantEdges := 0
+	for height := int32(0); height < 4; height++ {
+		hashes := make([]string, 0, width)
+		for m := 0; m < width; m++ {
+			hash := fmt.Sprintf("%02d%02d", height, m)
+			miner := m
+			if m == width-1 {
+				miner = -1
+			}
+			blocks = append(blocks, soterutil.GraphMLBlock{
+				Hash:      hash,
+				Height:    height,
+				Parents:   prevHeight,
+				Miner:     miner,
+				Timestamp: time.Unix(int64(1500000000+height), 0),
+			})
+			wantEdges += len(prevHeight)
+			hashes = append(hashes, hash)
+		}
+		prevHeight = hashes
+	}
+
+	out, err := soterutil.RenderDagGraphML(blocks)
+	if err != nil {
+		t.Fatalf("RenderDagGraphML failed: %v", err)
+	}
+
+	var doc parsedGraphML
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("output isn't a valid GraphML document: %v\n%s", err, out)
+	}
+
+	keys := make(map[string]string)
+	for _, key := range doc.Keys {
+		if key.For != "node" {
+			t.Fatalf("key %s is for %q, want node", key.ID, key.For)
+		}
+		keys[key.ID] = key.Name
+	}
+	for _, attr := range []string{"hash", "height", "miner", "timestamp"} {
+		if keys[attr] != attr {
+			t.Fatalf("no key declared for the %s attribute", attr)
+		}
+	}
+
+	if len(doc.Graphs) != 1 {
+		t.Fatalf("got %d graphs, want 1", len(doc.Graphs))
+	}
+	graph := doc.Graphs[0]
+	if graph.EdgeDefault != "directed" {
+		t.Fatalf("graph edgedefault is %q, want directed", graph.EdgeDefault)
+	}
+	if len(graph.Nodes) != len(blocks) {
+		t.Fatalf("got %d nodes, want %d", len(graph.Nodes), len(blocks))
+	}
+	if len(graph.Edges) != wantEdges {
+		t.Fatalf("got %d edges, want %d", len(graph.Edges), wantEdges)
+	}
+
+	// Make sure each node has the attributes of its block, and that each
+	// edge goes from a block to one of its parents.
+	hashOf := make(map[string]string)
+	for n, node := range graph.Nodes {
+		attrs := make(map[string]string)
+		for _, data := range node.Data {
+			if _, exists := keys[data.Key]; !exists {
+				t.Fatalf("node %s has undeclared key %s", node.ID,
+					data.Key)
+			}
+			attrs[data.Key] = data.Value
+		}
+
+		block := blocks[n]
+		want := map[string]string{
+			"hash":      block.Hash,
+			"height":    fmt.Sprint(block.Height),
+			"timestamp": fmt.Sprint(block.Timestamp.Unix()),
+		}
+		if block.Miner >= 0 {
+			want["miner"] = fmt.Sprint(block.Miner)
+		}
+		if fmt.Sprint(attrs) != fmt.Sprint(want) {
+			t.Fatalf("node %s has attributes %v, want %v", node.ID,
+				attrs, want)
+		}
+		hashOf[node.ID] = block.Hash
+	}
+
+	edges := make(map[[2]string]bool)
+	for _, edge := range graph.Edges {
+		from, ok := hashOf[edge.Source]
+		if !ok {
+			t.Fatalf("edge from unknown node %s", edge.Source)
+		}
+		to, ok := hashOf[edge.Target]
+		if !ok {
+			t.Fatalf("edge to unknown node %s", edge.Target)
+		}
+		edges[[2]string{from, to}] = true
+	}
+	for _, block := range blocks {
+		for _, parent := range block.Parents {
+			if !edges[[2]string{block.Hash, parent}] {
+				t.Fatalf("no edge from block %s to parent %s",
+					block.Hash, parent)
+			}
+		}
+	}
+
+	invalid := map[string][]soterutil.GraphMLBlock{
+		"duplicate block": {blocks[0], blocks[0]},
+		"unknown parent":  {blocks[width]},
+	}
+	for name, blocks := range invalid {
+		if _, err := soterutil.RenderDagGraphML(blocks); err == nil {
+			t.Fatalf("%s: RenderDagGraphML didn't return an error", name)
+		}
+	}
+}
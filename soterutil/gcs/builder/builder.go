@@ -371,3 +371,39 @@ func MakeHeaderForFilter(filter *gcs.Filter, prevHeader chainhash.Hash) (chainha
 	// above.
 	return chainhash.DoubleHashH(filterTip), nil
 }
+
+// MakeHeaderForDagFilter makes a filter header for the filter of a dag block,
+// given the filter and the filter headers of the block's parents, in the order
+// the parents are listed in the block.
+//
+// In a chain each filter header commits to the header of the previous block,
+// so the headers form a chain.  A dag block can have several parents, so its
+// filter header commits to the headers of all of them, and the headers form the
+// same dag as the blocks:
+//
+//   - A block without parents, like the genesis block, chains from the zero
+//     hash.
+//   - A block with one parent chains from its parent's header, the same as
+//     MakeHeaderForFilter.
+//   - A block with several parents chains from the double-sha256 of the
+//     concatenation of its parents' headers.
+//
+// The headers don't chain in the dag ordering, because the ordering of blocks
+// can change as new blocks are added, which would change the headers of
+// blocks that were already indexed.  The parents of a block never change.
+func MakeHeaderForDagFilter(filter *gcs.Filter, parentHeaders []chainhash.Hash) (chainhash.Hash, error) {
+	var prevHeader chainhash.Hash
+	switch len(parentHeaders) {
+	case 0:
+	case 1:
+		prevHeader = parentHeaders[0]
+	default:
+		parents := make([]byte, 0, len(parentHeaders)*chainhash.HashSize)
+		for _, header := range parentHeaders {
+			parents = append(parents, header[:]...)
+		}
+		prevHeader = chainhash.DoubleHashH(parents)
+	}
+
+	return MakeHeaderForFilter(filter, prevHeader)
+}
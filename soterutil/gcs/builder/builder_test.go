@@ -281,3 +281,99 @@ func BuilderTest(b *builder.GCSBuilder, hash *chainhash.Hash, p uint8,
 		t.Fatal("Filter size increased with duplicate items")
 	}
 }
+
+// TestBuildBasicFilter tests that a basic filter matches the output scripts of
+// a block and the previous output scripts spent by it.
+func TestBuildBasicFilter(t *testing.T) {
+	// A p2pkh output script and the p2sh script of testAddr.
+	outScript, _ := hex.DecodeString("76a914128004ff2fcaf13b2b91eb654b1d" +
+		"c2b674f7ec6188ac")
+	prevScript, _ := hex.DecodeString("a914e9c3dd0c07aac76179ebc76a6c78d" +
+		"4d67c6c160a87")
+	unusedScript, _ := hex.DecodeString("a914000000000000000000000000000" +
+		"000000000000087")
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(5000, outScript))
+	block := wire.NewMsgBlock(wire.NewBlockHeader(1, &chainhash.Hash{},
+		&chainhash.Hash{}, 0, 0))
+	block.AddTransaction(tx)
+
+	f, err := builder.BuildBasicFilter(block, [][]byte{prevScript})
+	if err != nil {
+		t.Fatalf("BuildBasicFilter: %v", err)
+	}
+
+	blockHash := block.BlockHash()
+	key := builder.DeriveKey(&blockHash)
+	for _, script := range [][]byte{outScript, prevScript} {
+		match, err := f.Match(key, script)
+		if err != nil {
+			t.Fatalf("Filter match failed: %v", err)
+		}
+		if !match {
+			t.Fatalf("Filter didn't match script %x", script)
+		}
+	}
+
+	match, err := f.Match(key, unusedScript)
+	if err != nil {
+		t.Fatalf("Filter match failed: %v", err)
+	}
+	if match {
+		t.Logf("False positive match, should be 1 in 2**%d!",
+			builder.DefaultP)
+	}
+}
+
+// TestMakeHeaderForDagFilter tests how filter headers chain from the headers
+// of the parents of a dag block.
+func TestMakeHeaderForDagFilter(t *testing.T) {
+	key, _ := builder.RandomKey()
+	f, err := builder.WithKey(key).AddEntries(contents).Build()
+	if err != nil {
+		t.Fatalf("Filter build failed: %v", err)
+	}
+
+	parent1 := chainhash.HashH([]byte("parent1"))
+	parent2 := chainhash.HashH([]byte("parent2"))
+
+	// Without parents and with one parent, the header is the same as in a
+	// chain.
+	got, err := builder.MakeHeaderForDagFilter(f, nil)
+	if err != nil {
+		t.Fatalf("MakeHeaderForDagFilter: %v", err)
+	}
+	want, _ := builder.MakeHeaderForFilter(f, chainhash.Hash{})
+	if got != want {
+		t.Errorf("header without parents got %v, want %v", got, want)
+	}
+
+	got, err = builder.MakeHeaderForDagFilter(f, []chainhash.Hash{parent1})
+	if err != nil {
+		t.Fatalf("MakeHeaderForDagFilter: %v", err)
+	}
+	want, _ = builder.MakeHeaderForFilter(f, parent1)
+	if got != want {
+		t.Errorf("header with one parent got %v, want %v", got, want)
+	}
+
+	// With several parents, the header commits to all of them in order.
+	got, err = builder.MakeHeaderForDagFilter(f,
+		[]chainhash.Hash{parent1, parent2})
+	if err != nil {
+		t.Fatalf("MakeHeaderForDagFilter: %v", err)
+	}
+	want, _ = builder.MakeHeaderForFilter(f, chainhash.DoubleHashH(
+		append(parent1.CloneBytes(), parent2[:]...)))
+	if got != want {
+		t.Errorf("header with two parents got %v, want %v", got, want)
+	}
+
+	reversed, _ := builder.MakeHeaderForDagFilter(f,
+		[]chainhash.Hash{parent2, parent1})
+	if reversed == got {
+		t.Errorf("header doesn't commit to the order of the parents")
+	}
+}
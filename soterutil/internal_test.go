@@ -113,3 +113,16 @@ func TstAddressSegwitSAddr(addr string) []byte {
 	}
 	return data
 }
+
+// TstDagToDot makes the internal dagToDot function available to the test
+// package, so that the graph can be rendered with a given number of
+// goroutines.
+func TstDagToDot(blocks []DotBlock, minerColor func(miner int) string,
+	workers int) []byte {
+
+	graphIndex := make(map[string]int, len(blocks))
+	for n, block := range blocks {
+		graphIndex[block.Hash] = n
+	}
+	return dagToDot(blocks, graphIndex, minerColor, workers)
+}
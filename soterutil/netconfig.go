@@ -5,6 +5,8 @@
 package soterutil
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
 	"math/big"
 	"os"
@@ -13,6 +15,7 @@ import (
 	"github.com/jessevdk/go-flags"
 
 	"github.com/soteria-dag/soterd/chaincfg"
+	"github.com/soteria-dag/soterd/wire"
 )
 
 const (
@@ -56,15 +59,50 @@ type netCfg struct {
 	Name string `short:"n" long:"name" description:"Name of net params type"`
 	TargetTimespan time.Duration `short:"t" long:"targettimespan" description:"Desired amount of time that should elapse before checking if block difficulty requirement should be changed to maintain desired block generation rate"`
 	TargetTimePerBlock time.Duration `short:"d" long:"targettimeperblock" description:"Desired amount of time to generate each block"`
+	MinDiffReductionTime time.Duration `long:"mindiffreductiontime" description:"Amount of time without a block after which the minimum difficulty is required"`
+	GenesisBlock string `long:"genesisblock" description:"Hex-encoded serialized genesis block"`
+	PowLimit soterBigInt `long:"powlimit" description:"Highest proof of work value a block can have"`
+	PowLimitBits uint32 `long:"powlimitbits" description:"Highest proof of work value a block can have, in compact form"`
 }
 
 // paramsToArgs returns netCfg arg values taken from the provided chaincfg.Param.
 // This is necessary because the go-flags module doesn't support populating flag.Params types directly from structs.
-func paramsToArgs(params *chaincfg.Params) []string {
+func paramsToArgs(params *chaincfg.Params) ([]string, error) {
+	var genesis bytes.Buffer
+	err := params.GenesisBlock.Serialize(&genesis)
+	if err != nil {
+		return nil, err
+	}
+
+	powLimit, err := soterBigInt{bigInt: params.PowLimit}.MarshalFlag()
+	if err != nil {
+		return nil, err
+	}
+
 	return []string{
 		"--name", params.Name,
 		"--targettimespan", params.TargetTimespan.String(),
 		"--targettimeperblock", params.TargetTimePerBlock.String(),
+		"--mindiffreductiontime", params.MinDiffReductionTime.String(),
+		"--genesisblock", hex.EncodeToString(genesis.Bytes()),
+		"--powlimit", powLimit,
+		"--powlimitbits", fmt.Sprintf("%d", params.PowLimitBits),
+	}, nil
+}
+
+// baseNetParams returns the chaincfg.Params that ReadNetCfg applies settings to, for the given net params name.
+func baseNetParams(name string) (*chaincfg.Params, error) {
+	switch name {
+		case "mainnet":
+			return &chaincfg.MainNetParams, nil
+		case "regtest":
+			return &chaincfg.RegressionNetParams, nil
+		case "testnet1":
+			return &chaincfg.TestNet1Params, nil
+		case "simnet":
+			return &chaincfg.SimNetParams, nil
+		default:
+			return nil, fmt.Errorf("ReadNetCfg doesn't know what to do with net name %v", name)
 	}
 }
 
@@ -73,16 +111,31 @@ func paramsToArgs(params *chaincfg.Params) []string {
 //
 // WriteNetCfg writes chaincfg.Param values that we may be modifying during the test run to the open file descriptor, in
 // ini file format.
+//
+// An error is returned if the params' name isn't one that ReadNetCfg knows, or if it names the params of a different
+// network than the params are for. Otherwise the node reading the file would apply the settings to the wrong network.
 func WriteNetCfg(f *os.File, params *chaincfg.Params) error {
+	base, err := baseNetParams(params.Name)
+	if err != nil {
+		return err
+	}
+	if base.Net != params.Net {
+		return fmt.Errorf("net params name %v is for network %v, not %v", params.Name, base.Net, params.Net)
+	}
+
 	// Create a Parser for the options specified in netCfg
 	parser := flags.NewParser(nil, flags.None)
-	_, err := parser.AddGroup(soterNetIniSection, soterNetIniSection, &netCfg{})
+	_, err = parser.AddGroup(soterNetIniSection, soterNetIniSection, &netCfg{})
 	if err != nil {
 		return err
 	}
 
 	// Add the params to the parser
-	_, err = parser.ParseArgs(paramsToArgs(params))
+	args, err := paramsToArgs(params)
+	if err != nil {
+		return err
+	}
+	_, err = parser.ParseArgs(args)
 	if err != nil {
 		return err
 	}
@@ -115,17 +168,9 @@ func ReadNetCfg(name string) (*chaincfg.Params, error) {
 	}
 
 	// Determine which chaincfg.Params to update and return
-	switch cfg.Name {
-		case "mainnet":
-			params = &chaincfg.MainNetParams
-		case "regtest":
-			params = &chaincfg.RegressionNetParams
-		case "testnet1":
-			params = &chaincfg.TestNet1Params
-		case "simnet":
-			params = &chaincfg.SimNetParams
-		default:
-			return params, fmt.Errorf("ReadNetCfg doesn't know what to do with net name %v", cfg.Name)
+	params, err = baseNetParams(cfg.Name)
+	if err != nil {
+		return params, err
 	}
 
 	// Update relevant params
@@ -133,5 +178,33 @@ func ReadNetCfg(name string) (*chaincfg.Params, error) {
 	params.TargetTimespan = cfg.TargetTimespan
 	params.TargetTimePerBlock = cfg.TargetTimePerBlock
 
+	// Files written before the genesis, proof of work limit and minimum difficulty settings were added don't have them,
+	// so the defaults of the params are kept for them.
+	if cfg.GenesisBlock != "" {
+		serialized, err := hex.DecodeString(cfg.GenesisBlock)
+		if err != nil {
+			return params, fmt.Errorf("invalid genesis block: %v", err)
+		}
+
+		var genesis wire.MsgBlock
+		err = genesis.Deserialize(bytes.NewReader(serialized))
+		if err != nil {
+			return params, fmt.Errorf("invalid genesis block: %v", err)
+		}
+
+		genesisHash := genesis.BlockHash()
+		params.GenesisBlock = &genesis
+		params.GenesisHash = &genesisHash
+	}
+	if cfg.PowLimit.bigInt != nil {
+		params.PowLimit = cfg.PowLimit.bigInt
+	}
+	if cfg.PowLimitBits != 0 {
+		params.PowLimitBits = cfg.PowLimitBits
+	}
+	if cfg.MinDiffReductionTime != 0 {
+		params.MinDiffReductionTime = cfg.MinDiffReductionTime
+	}
+
 	return params, nil
 }
\ No newline at end of file
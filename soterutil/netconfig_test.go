@@ -0,0 +1,126 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package soterutil_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/soteria-dag/soterd/blockdag"
+	"github.com/soteria-dag/soterd/chaincfg"
+	"github.com/soteria-dag/soterd/soterutil"
+	"github.com/soteria-dag/soterd/wire"
+)
+
+// TestWriteNetCfgNetMismatch ensures that WriteNetCfg rejects params which
+// ReadNetCfg wouldn't apply to the network the params are for.
+func TestWriteNetCfgNetMismatch(t *testing.T) {
+	unknownName := chaincfg.SimNetParams
+	unknownName.Name = "customnet"
+
+	otherNetName := chaincfg.SimNetParams
+	otherNetName.Name = chaincfg.RegressionNetParams.Name
+
+	tests := []struct {
+		name   string
+		params *chaincfg.Params
+		valid  bool
+	}{
+		{"simnet", &chaincfg.SimNetParams, true},
+		{"regtest", &chaincfg.RegressionNetParams, true},
+		{"unknown name", &unknownName, false},
+		{"name of another network", &otherNetName, false},
+	}
+
+	for _, test := range tests {
+		f, err := ioutil.TempFile("", "netcfg")
+		if err != nil {
+			t.Fatalf("unable to create netcfg file: %v", err)
+		}
+
+		err = soterutil.WriteNetCfg(f, test.params)
+		f.Close()
+		os.Remove(f.Name())
+		if test.valid && err != nil {
+			t.Errorf("WriteNetCfg (%s): unexpected error: %v",
+				test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("WriteNetCfg (%s): expected error", test.name)
+		}
+	}
+}
+
+// TestNetCfgGenesisRoundTrip ensures that a custom genesis block, proof of
+// work limit and block timing written by WriteNetCfg are applied by
+// ReadNetCfg.
+func TestNetCfgGenesisRoundTrip(t *testing.T) {
+	// ReadNetCfg updates the global params, so restore them afterwards.
+	saved := chaincfg.SimNetParams
+	defer func() {
+		chaincfg.SimNetParams = saved
+	}()
+
+	var buf bytes.Buffer
+	if err := saved.GenesisBlock.Serialize(&buf); err != nil {
+		t.Fatalf("unable to serialize genesis block: %v", err)
+	}
+	var genesis wire.MsgBlock
+	if err := genesis.Deserialize(&buf); err != nil {
+		t.Fatalf("unable to deserialize genesis block: %v", err)
+	}
+	genesis.Header.Timestamp = time.Unix(1500000000, 0)
+	genesisHash := genesis.BlockHash()
+
+	params := saved
+	params.GenesisBlock = &genesis
+	params.GenesisHash = &genesisHash
+	params.PowLimitBits = 0x207fff00
+	params.PowLimit = blockdag.CompactToBig(params.PowLimitBits)
+	params.TargetTimePerBlock = time.Millisecond * 100
+	params.MinDiffReductionTime = time.Millisecond * 200
+
+	f, err := ioutil.TempFile("", "netcfg")
+	if err != nil {
+		t.Fatalf("unable to create netcfg file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	err = soterutil.WriteNetCfg(f, &params)
+	f.Close()
+	if err != nil {
+		t.Fatalf("WriteNetCfg: unexpected error: %v", err)
+	}
+
+	read, err := soterutil.ReadNetCfg(f.Name())
+	if err != nil {
+		t.Fatalf("ReadNetCfg: unexpected error: %v", err)
+	}
+	if !read.GenesisHash.IsEqual(&genesisHash) {
+		t.Errorf("genesis hash is %v, want %v", read.GenesisHash,
+			genesisHash)
+	}
+	if got := read.GenesisBlock.BlockHash(); !got.IsEqual(&genesisHash) {
+		t.Errorf("genesis block hash is %v, want %v", got, genesisHash)
+	}
+	if read.PowLimitBits != params.PowLimitBits {
+		t.Errorf("pow limit bits are %x, want %x", read.PowLimitBits,
+			params.PowLimitBits)
+	}
+	if read.PowLimit.Cmp(params.PowLimit) != 0 {
+		t.Errorf("pow limit is %v, want %v", read.PowLimit,
+			params.PowLimit)
+	}
+	if read.TargetTimePerBlock != params.TargetTimePerBlock {
+		t.Errorf("target time per block is %v, want %v",
+			read.TargetTimePerBlock, params.TargetTimePerBlock)
+	}
+	if read.MinDiffReductionTime != params.MinDiffReductionTime {
+		t.Errorf("min difficulty reduction time is %v, want %v",
+			read.MinDiffReductionTime, params.MinDiffReductionTime)
+	}
+}
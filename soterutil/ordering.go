@@ -0,0 +1,58 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package soterutil
+
+import (
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+)
+
+// OrderingRoot returns the root of a merkle tree over the hashes of a dag
+// ordering, such as the one returned by BlockDAG.DAGOrdering.  Two nodes with
+// the same root have the same ordering, so comparing roots is a cheap way to
+// check that their orderings match.
+//
+// The tree is built the same way as the transaction merkle tree of a block:
+//
+//   - The leaves are the hashes, in order.
+//   - Each level is built by hashing the concatenation of pairs of adjacent
+//     nodes with a double sha256, h(left || right).
+//   - When a level has an odd number of nodes, the last node is paired with
+//     itself, h(last || last).
+//   - The root is the only node of the last level.  The root of a single hash
+//     is the hash itself, and the root of an empty ordering is the zero hash.
+//
+// A nil hash is treated as the zero hash.  Because the last node is paired
+// with itself, an ordering ending in a repeated hash, like [a b c c], has the
+// same root as the ordering without the repeat, [a b c].  A dag ordering never
+// contains a block twice, so this doesn't affect comparing orderings.
+func OrderingRoot(orderedHashes []*chainhash.Hash) chainhash.Hash {
+	if len(orderedHashes) == 0 {
+		return chainhash.Hash{}
+	}
+
+	level := make([]chainhash.Hash, len(orderedHashes))
+	for i, hash := range orderedHashes {
+		if hash != nil {
+			level[i] = *hash
+		}
+	}
+
+	var pair [chainhash.HashSize * 2]byte
+	for len(level) > 1 {
+		next := make([]chainhash.Hash, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			right := i + 1
+			if right == len(level) {
+				right = i
+			}
+			copy(pair[:chainhash.HashSize], level[i][:])
+			copy(pair[chainhash.HashSize:], level[right][:])
+			next = append(next, chainhash.DoubleHashH(pair[:]))
+		}
+		level = next
+	}
+
+	return level[0]
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package soterutil_test
+
+import (
+	"testing"
+
+	"github.com/soteria-dag/soterd/blockdag"
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+	"github.com/soteria-dag/soterd/soterutil"
+	"github.com/soteria-dag/soterd/wire"
+)
+
+// TestOrderingRoot tests OrderingRoot against roots built by hand for
+// orderings of various sizes.
+func TestOrderingRoot(t *testing.T) {
+	var leaves [5]chainhash.Hash
+	for i := range leaves {
+		leaves[i] = chainhash.DoubleHashH([]byte{byte(i)})
+	}
+	a, b, c, d, e := &leaves[0], &leaves[1], &leaves[2], &leaves[3], &leaves[4]
+
+	// h returns the parent of two nodes.
+	h := func(left, right chainhash.Hash) chainhash.Hash {
+		return *blockdag.HashMerkleBranches(&left, &right)
+	}
+
+	tests := []struct {
+		name    string
+		ordered []*chainhash.Hash
+		want    chainhash.Hash
+	}{
+		{"empty", nil, chainhash.Hash{}},
+		{"single", []*chainhash.Hash{a}, *a},
+		{"nil hash", []*chainhash.Hash{nil}, chainhash.Hash{}},
+		{"two", []*chainhash.Hash{a, b}, h(*a, *b)},
+		{"three", []*chainhash.Hash{a, b, c},
+			h(h(*a, *b), h(*c, *c))},
+		{"four", []*chainhash.Hash{a, b, c, d},
+			h(h(*a, *b), h(*c, *d))},
+		{"five", []*chainhash.Hash{a, b, c, d, e},
+			h(h(h(*a, *b), h(*c, *d)), h(h(*e, *e), h(*e, *e)))},
+		{"repeated last hash", []*chainhash.Hash{a, b, c, c},
+			h(h(*a, *b), h(*c, *c))},
+	}
+
+	for _, test := range tests {
+		got := soterutil.OrderingRoot(test.ordered)
+		if !got.IsEqual(&test.want) {
+			t.Errorf("OrderingRoot (%s): got %v, want %v", test.name,
+				got, test.want)
+		}
+	}
+
+	// The root depends on the order of the hashes.
+	ab := soterutil.OrderingRoot([]*chainhash.Hash{a, b, c})
+	ba := soterutil.OrderingRoot([]*chainhash.Hash{b, a, c})
+	if ab.IsEqual(&ba) {
+		t.Errorf("OrderingRoot: reordered hashes have the same root %v",
+			ab)
+	}
+
+}
+
+// TestOrderingRootMerkleRoot ensures that OrderingRoot builds the same tree as
+// the transaction merkle tree of a block, for odd and even numbers of leaves.
+func TestOrderingRootMerkleRoot(t *testing.T) {
+	for count := 1; count <= 9; count++ {
+		txs := make([]*soterutil.Tx, 0, count)
+		hashes := make([]*chainhash.Hash, 0, count)
+		for i := 0; i < count; i++ {
+			msgTx := wire.NewMsgTx(wire.TxVersion)
+			msgTx.LockTime = uint32(i)
+			tx := soterutil.NewTx(msgTx)
+			txs = append(txs, tx)
+			hashes = append(hashes, tx.Hash())
+		}
+
+		merkles := blockdag.BuildMerkleTreeStore(txs, false)
+		want := merkles[len(merkles)-1]
+		got := soterutil.OrderingRoot(hashes)
+		if !got.IsEqual(want) {
+			t.Errorf("OrderingRoot (%d hashes): got %v, want merkle "+
+				"root %v", count, got, want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package soterutil
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+)
+
+var (
+	// ErrDuplicateParent describes an error where a block references the
+	// same parent more than once.
+	ErrDuplicateParent = errors.New("duplicate parent")
+
+	// ErrParentsNotCanonical describes an error where the parents of a
+	// block aren't in canonical order.
+	ErrParentsNotCanonical = errors.New("parents aren't in canonical order")
+)
+
+// parentLess returns whether parent a comes before parent b in canonical
+// order.  Parents are ordered lexicographically by their hash as a string,
+// which is the same order the tips hash of a block header is built from.
+// Hashes are displayed byte-reversed, so this compares the bytes of the hashes
+// starting from the last one.
+func parentLess(a, b *chainhash.Hash) bool {
+	for i := chainhash.HashSize - 1; i >= 0; i-- {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// CanonicalizeParents returns the parents of a block in canonical order, which
+// is lexicographic order of their hashes as strings.  Two blocks with the same
+// parents in canonical order serialize the same way.  The given slice isn't
+// modified, and duplicate parents are kept.
+func CanonicalizeParents(parents []*chainhash.Hash) []*chainhash.Hash {
+	canonical := make([]*chainhash.Hash, len(parents))
+	copy(canonical, parents)
+	sort.SliceStable(canonical, func(i, j int) bool {
+		return parentLess(canonical[i], canonical[j])
+	})
+	return canonical
+}
+
+// ValidateParentOrdering returns an error if the parents of a block aren't in
+// the order returned by CanonicalizeParents, or if a parent is given more than
+// once.  A block without parents, like the genesis block, is always valid.
+func ValidateParentOrdering(parents []*chainhash.Hash) error {
+	seen := make(map[chainhash.Hash]struct{}, len(parents))
+	for _, parent := range parents {
+		if _, exists := seen[*parent]; exists {
+			return ErrDuplicateParent
+		}
+		seen[*parent] = struct{}{}
+	}
+
+	for i := 1; i < len(parents); i++ {
+		if !parentLess(parents[i-1], parents[i]) {
+			return ErrParentsNotCanonical
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package soterutil_test
+
+import (
+	"testing"
+
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+	"github.com/soteria-dag/soterd/soterutil"
+)
+
+// TestCanonicalizeParents ensures parents are put in lexicographic order of
+// their hashes as strings, and that ValidateParentOrdering only accepts
+// parents in that order, without duplicates.
+func TestCanonicalizeParents(t *testing.T) {
+	var hashes [4]chainhash.Hash
+	for i := range hashes {
+		hashes[i] = chainhash.DoubleHashH([]byte{byte(i)})
+	}
+	parents := []*chainhash.Hash{&hashes[0], &hashes[1], &hashes[2],
+		&hashes[3]}
+	given := make([]*chainhash.Hash, len(parents))
+	copy(given, parents)
+
+	canonical := soterutil.CanonicalizeParents(parents)
+	if len(canonical) != len(parents) {
+		t.Fatalf("got %d parents, want %d", len(canonical), len(parents))
+	}
+	for i := 1; i < len(canonical); i++ {
+		if canonical[i-1].String() >= canonical[i].String() {
+			t.Fatalf("parents %v aren't in canonical order", canonical)
+		}
+	}
+	for i := range parents {
+		if parents[i] != given[i] {
+			t.Fatalf("CanonicalizeParents modified the given parents")
+		}
+	}
+	if err := soterutil.ValidateParentOrdering(canonical); err != nil {
+		t.Fatalf("ValidateParentOrdering of canonical parents: %v", err)
+	}
+
+	// The canonical order doesn't depend on the order parents are given in.
+	reversed := make([]*chainhash.Hash, len(canonical))
+	for i, parent := range canonical {
+		reversed[len(canonical)-1-i] = parent
+	}
+	again := soterutil.CanonicalizeParents(reversed)
+	for i := range canonical {
+		if !again[i].IsEqual(canonical[i]) {
+			t.Fatalf("canonical order of reversed parents is %v, want %v",
+				again, canonical)
+		}
+	}
+
+	dup := *canonical[1]
+	tests := []struct {
+		name    string
+		parents []*chainhash.Hash
+		want    error
+	}{
+		{"no parents", nil, nil},
+		{"single parent", canonical[:1], nil},
+		{"reversed", reversed, soterutil.ErrParentsNotCanonical},
+		{"adjacent duplicate", []*chainhash.Hash{canonical[0],
+			canonical[1], &dup}, soterutil.ErrDuplicateParent},
+		{"duplicate", []*chainhash.Hash{canonical[1], canonical[0],
+			&dup}, soterutil.ErrDuplicateParent},
+	}
+	for _, test := range tests {
+		err := soterutil.ValidateParentOrdering(test.parents)
+		if err != test.want {
+			t.Errorf("ValidateParentOrdering (%s): got %v, want %v",
+				test.name, err, test.want)
+		}
+	}
+
+	if got := soterutil.CanonicalizeParents(nil); len(got) != 0 {
+		t.Errorf("canonical order of no parents is %v, want none", got)
+	}
+}
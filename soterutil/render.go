@@ -6,11 +6,43 @@ package soterutil
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"html/template"
 	"os/exec"
 )
 
+// MaxPngRenderSize is the maximum size in bytes of a PNG image rendered by
+// DotToPng. Rendering is stopped once the image grows past this size, to avoid
+// runaway renders of huge dags.
+const MaxPngRenderSize = 1024 * 1024 * 32
+
+// limitBuffer is a buffer that refuses writes which would grow it past its
+// limit. A limit of 0 means there is no limit.
+//
+// NOTE: The bytes.Buffer isn't embedded, so that io.Copy can't bypass the limit
+// through bytes.Buffer.ReadFrom.
+type limitBuffer struct {
+	buf      bytes.Buffer
+	limit    int
+	exceeded bool
+}
+
+// Write satisfies the io.Writer interface.
+func (b *limitBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && b.buf.Len()+len(p) > b.limit {
+		b.exceeded = true
+		return 0, fmt.Errorf("rendering is larger than the %d byte limit", b.limit)
+	}
+
+	return b.buf.Write(p)
+}
+
+// Bytes returns the contents of the buffer.
+func (b *limitBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
 // DotToSvg returns a rendering of the graphviz DOT file contents in SVG format
 //
 // This function makes use of the graphviz `dot` command, so graphviz needs to be installed.
@@ -19,10 +51,40 @@ import (
 // NOTE(cedric): If you're embedding the svg file contents in an HTML document, you'll want to use the StripSvgXmlDecl
 // function to strip the xml declaration tag from the svg contents before embedding the svg as a <figure>.
 func DotToSvg(dot []byte) ([]byte, error) {
-	var in, out, stderr bytes.Buffer
+	return DotToSvgContext(context.Background(), dot)
+}
+
+// DotToSvgContext is like DotToSvg, but the `dot` command is killed if the context is done before the rendering
+// completes. In that case the context's error is returned.
+func DotToSvgContext(ctx context.Context, dot []byte) ([]byte, error) {
+	return renderDot(ctx, dot, "svg", 0)
+}
+
+// DotToPng returns a rendering of the graphviz DOT file contents in PNG format
+//
+// This function makes use of the graphviz `dot` command, so graphviz needs to be installed.
+// Graphviz: http://graphviz.org/
+//
+// The rendering is stopped with an error if the image grows larger than MaxPngRenderSize.
+func DotToPng(dot []byte) ([]byte, error) {
+	return DotToPngContext(context.Background(), dot)
+}
+
+// DotToPngContext is like DotToPng, but the `dot` command is killed if the context is done before the rendering
+// completes. In that case the context's error is returned.
+func DotToPngContext(ctx context.Context, dot []byte) ([]byte, error) {
+	return renderDot(ctx, dot, "png", MaxPngRenderSize)
+}
+
+// renderDot returns a rendering of the graphviz DOT file contents in the given output format, using the graphviz
+// `dot` command. If maxSize is greater than 0, the rendering is stopped once it grows larger than maxSize bytes.
+// The `dot` command is killed if ctx is done before the rendering completes.
+func renderDot(ctx context.Context, dot []byte, format string, maxSize int) ([]byte, error) {
+	var in, stderr bytes.Buffer
+	out := limitBuffer{limit: maxSize}
 
 	cmdName := "dot"
-	args := []string{"-Tsvg"}
+	args := []string{"-T" + format}
 
 	// Check if the graphviz "dot" program is available
 	cmdPath, found := Which(cmdName)
@@ -36,11 +98,19 @@ func DotToSvg(dot []byte) ([]byte, error) {
 	}
 
 	// Run the `dot` command and pass the rendered dot file contents to its stdin
-	cmd := exec.Command(cmdPath, args...)
+	cmd := exec.CommandContext(ctx, cmdPath, args...)
 	cmd.Stdin = &in
 	cmd.Stdout = &out
 	cmd.Stderr = &stderr
 	err = cmd.Run()
+	if ctx.Err() != nil {
+		// The `dot` command was killed because the context is done. Run waits for the process, so it has been reaped.
+		return []byte{}, ctx.Err()
+	}
+	if out.exceeded {
+		// The `dot` command fails once we stop reading its output, so report why we stopped instead.
+		return []byte{}, fmt.Errorf("%s rendering is larger than the %d byte limit", format, maxSize)
+	}
 	if err != nil {
 		return out.Bytes(), fmt.Errorf("%s\n%s", stderr.String(), err)
 	}
@@ -155,6 +225,90 @@ func RenderSteppingHTML(body []byte, title string, prev int, next int) ([]byte,
        return render.Bytes(), nil
 }
 
+// RenderSvgHTMLFrame returns an HTML section containing the svg, as the frame
+// with the given index of an animation rendered by RenderFramesHTML.
+func RenderSvgHTMLFrame(svg []byte, frame int) ([]byte, error) {
+	var render bytes.Buffer
+
+	tmpl := `
+<figure class="frame" id="frame_{{ .frame }}"{{ if .frame }} hidden{{ end }}>
+{{ .svg }}
+</figure>
+`
+
+	t, err := template.New("frame").Parse(tmpl)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	data := map[string]interface{}{
+		"svg":   template.HTML(svg),
+		"frame": frame,
+	}
+
+	err = t.Execute(&render, data)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return render.Bytes(), nil
+}
+
+// RenderFramesHTML returns an HTML document containing the frames, which are
+// expected to be rendered by RenderSvgHTMLFrame, with a slider for moving
+// between them.  Only the first frame is shown initially.
+func RenderFramesHTML(frames [][]byte, title string) ([]byte, error) {
+	var render bytes.Buffer
+
+	tmpl := `
+<html>
+	<head>
+		<title>{{ .title }}</title>
+	</head>
+	<body>
+		<input type="range" id="frames" min="0" max="{{ .last }}" value="0">
+		<span id="frame_label">1 / {{ .count }}</span>
+		{{ range .frames }}{{ . }}{{ end }}
+		<script>
+			var slider = document.getElementById("frames");
+			slider.oninput = function() {
+				var frames = document.getElementsByClassName("frame");
+				for (var i = 0; i < frames.length; i++) {
+					frames[i].hidden = (frames[i].id != "frame_" + slider.value);
+				}
+				document.getElementById("frame_label").textContent =
+					(Number(slider.value) + 1) + " / {{ .count }}";
+			};
+		</script>
+	</body>
+</html>
+`
+
+	t, err := template.New("frames").Parse(tmpl)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	bodies := make([]template.HTML, len(frames))
+	for i, frame := range frames {
+		bodies[i] = template.HTML(frame)
+	}
+
+	data := map[string]interface{}{
+		"title":  title,
+		"frames": bodies,
+		"count":  len(frames),
+		"last":   len(frames) - 1,
+	}
+
+	err = t.Execute(&render, data)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return render.Bytes(), nil
+}
+
 // StripSvgXmlDecl is for stripping the xml declaration tag from svg file contents.
 // It's available as a helper command in case you're embedding an svg file in an HTML document.
 func StripSvgXmlDecl(svg []byte) ([]byte, error) {
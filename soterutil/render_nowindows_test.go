@@ -0,0 +1,75 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package soterutil_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/soteria-dag/soterd/soterutil"
+)
+
+// TestDotToSvgContextDeadline ensures that the `dot` command is killed and
+// reaped once the context deadline elapses.
+func TestDotToSvgContextDeadline(t *testing.T) {
+	// Put a `dot` command that never finishes first in the path. It records
+	// its pid before it's replaced by sleep, so that we can check on it.
+	dir, err := ioutil.TempDir("", "dottest")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	pidFile := filepath.Join(dir, "dot.pid")
+	script := "#!/bin/sh\necho $$ > " + pidFile + "\nexec sleep 60\n"
+	err = ioutil.WriteFile(filepath.Join(dir, "dot"), []byte(script), 0755)
+	if err != nil {
+		t.Fatalf("unable to write dot script: %v", err)
+	}
+
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		500*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = soterutil.DotToSvgContext(ctx, []byte("digraph dag {}"))
+	if err != context.DeadlineExceeded {
+		t.Fatalf("DotToSvgContext: unexpected error - got %v, want %v",
+			err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("DotToSvgContext: took %v to return after deadline",
+			elapsed)
+	}
+
+	pidBytes, err := ioutil.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("unable to read dot script pid: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(pidBytes)))
+	if err != nil {
+		t.Fatalf("unable to parse dot script pid: %v", err)
+	}
+
+	// Signal 0 succeeds for a process that hasn't been reaped yet, even if
+	// it has exited.
+	if err := syscall.Kill(pid, 0); err != syscall.ESRCH {
+		t.Fatalf("dot process %d wasn't reaped: kill returned %v", pid,
+			err)
+	}
+}
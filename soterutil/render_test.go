@@ -0,0 +1,73 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package soterutil_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/soteria-dag/soterd/soterutil"
+)
+
+// pngMagic is the signature that PNG files start with.
+var pngMagic = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+// TestDotToPng ensures that DOT file contents are rendered as a PNG image.
+func TestDotToPng(t *testing.T) {
+	if _, found := soterutil.Which("dot"); !found {
+		t.Skip("graphviz dot command not found")
+	}
+
+	dot := []byte("digraph dag {\n\"a\" -> \"b\";\n\"a\" -> \"c\";\n}\n")
+	png, err := soterutil.DotToPng(dot)
+	if err != nil {
+		t.Fatalf("DotToPng: unexpected error: %v", err)
+	}
+
+	if !bytes.HasPrefix(png, pngMagic) {
+		t.Fatalf("DotToPng: output doesn't start with PNG signature %x",
+			pngMagic)
+	}
+}
+
+// TestRenderFramesHTML ensures that every frame is included in the rendered
+// HTML document, and that only the first frame is shown initially.
+func TestRenderFramesHTML(t *testing.T) {
+	const numFrames = 3
+
+	var frames [][]byte
+	for i := 0; i < numFrames; i++ {
+		svg := []byte(fmt.Sprintf("<svg>%d</svg>", i))
+		frame, err := soterutil.RenderSvgHTMLFrame(svg, i)
+		if err != nil {
+			t.Fatalf("RenderSvgHTMLFrame: unexpected error: %v", err)
+		}
+		frames = append(frames, frame)
+	}
+
+	h, err := soterutil.RenderFramesHTML(frames, "dag")
+	if err != nil {
+		t.Fatalf("RenderFramesHTML: unexpected error: %v", err)
+	}
+
+	if n := bytes.Count(h, []byte(`class="frame"`)); n != numFrames {
+		t.Fatalf("RenderFramesHTML: got %d frames, want %d", n, numFrames)
+	}
+	if n := bytes.Count(h, []byte(" hidden>")); n != numFrames-1 {
+		t.Fatalf("RenderFramesHTML: got %d hidden frames, want %d", n,
+			numFrames-1)
+	}
+	for i := 0; i < numFrames; i++ {
+		id := fmt.Sprintf(`id="frame_%d"`, i)
+		if !bytes.Contains(h, []byte(id)) {
+			t.Fatalf("RenderFramesHTML: frame %d missing from output", i)
+		}
+	}
+	if !bytes.Contains(h, []byte(fmt.Sprintf(`max="%d"`, numFrames-1))) {
+		t.Fatalf("RenderFramesHTML: slider doesn't cover %d frames",
+			numFrames)
+	}
+}
@@ -157,6 +157,32 @@ func isNullData(pops []parsedOpcode) bool {
 		len(pops[1].data) <= MaxDataCarrierSize
 }
 
+// NullDataSize returns the number of bytes of data carried by a script of the
+// null data form, which is an OP_RETURN optionally followed by a single data
+// push, and whether the script has that form.  Unlike GetScriptClass, which only
+// classifies scripts with up to MaxDataCarrierSize bytes of data as NullDataTy,
+// the size of the data isn't limited, so that relay policy can apply its own
+// limit.
+func NullDataSize(script []byte) (int, bool) {
+	pops, err := parseScript(script)
+	if err != nil {
+		return 0, false
+	}
+
+	l := len(pops)
+	if l == 1 && pops[0].opcode.value == OP_RETURN {
+		return 0, true
+	}
+	if l == 2 && pops[0].opcode.value == OP_RETURN &&
+		(isSmallInt(pops[1].opcode) || pops[1].opcode.value <=
+			OP_PUSHDATA4) {
+
+		return len(pops[1].data), true
+	}
+
+	return 0, false
+}
+
 // scriptType returns the type of the script being inspected from the known
 // standard types.
 func typeOfScript(pops []parsedOpcode) ScriptClass {
@@ -1214,3 +1214,64 @@ func TestNullDataScript(t *testing.T) {
 		}
 	}
 }
+
+// TestNullDataSize ensures NullDataSize reports the size of the data carried by
+// null data scripts, regardless of MaxDataCarrierSize, and rejects other
+// scripts.
+func TestNullDataSize(t *testing.T) {
+	tests := []struct {
+		name   string
+		script []byte
+		size   int
+		isNull bool
+	}{
+		{
+			name:   "just OP_RETURN",
+			script: mustParseShortForm("RETURN"),
+			size:   0,
+			isNull: true,
+		},
+		{
+			name:   "small int",
+			script: mustParseShortForm("RETURN 1"),
+			size:   0,
+			isNull: true,
+		},
+		{
+			name:   "data push",
+			script: mustParseShortForm("RETURN DATA_2 0x0102"),
+			size:   2,
+			isNull: true,
+		},
+		{
+			name: "more than MaxDataCarrierSize",
+			script: append(mustParseShortForm("RETURN PUSHDATA1 0x51"),
+				bytes.Repeat([]byte{0x01}, MaxDataCarrierSize+1)...),
+			size:   MaxDataCarrierSize + 1,
+			isNull: true,
+		},
+		{
+			name:   "two data pushes",
+			script: mustParseShortForm("RETURN DATA_1 0x01 DATA_1 0x02"),
+			isNull: false,
+		},
+		{
+			name:   "not a push after OP_RETURN",
+			script: mustParseShortForm("RETURN CHECKSIG"),
+			isNull: false,
+		},
+		{
+			name:   "no OP_RETURN",
+			script: mustParseShortForm("DATA_1 0x01"),
+			isNull: false,
+		},
+	}
+
+	for _, test := range tests {
+		size, isNull := NullDataSize(test.script)
+		if isNull != test.isNull || size != test.size {
+			t.Errorf("NullDataSize (%s): got %d, %v, want %d, %v",
+				test.name, size, isNull, test.size, test.isNull)
+		}
+	}
+}
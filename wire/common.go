@@ -603,6 +603,62 @@ func VarIntSerializeSize(val uint64) int {
 	return 9
 }
 
+// saturatingMul returns a * b, clamped to MaxMessagePayload.  It is used when
+// computing payload limits from element counts so that a product which can't
+// be represented as a uint32 saturates instead of wrapping around to a small
+// value and under-reporting the limit.
+func saturatingMul(a, b uint32) uint32 {
+	product := uint64(a) * uint64(b)
+	if product > MaxMessagePayload {
+		return MaxMessagePayload
+	}
+	return uint32(product)
+}
+
+// maxCountedPayload returns the maximum payload length of a message made up of
+// a variable length integer count followed by up to count elements of
+// elemSize bytes each.  The result is clamped to MaxMessagePayload.
+func maxCountedPayload(count, elemSize uint32) uint32 {
+	length := uint64(MaxVarIntPayload) + uint64(saturatingMul(count, elemSize))
+	if length > MaxMessagePayload {
+		return MaxMessagePayload
+	}
+	return uint32(length)
+}
+
+// remainingPayload returns the number of bytes that are left to be read from
+// r, and whether that's known.  It's known when r is limited to the length of
+// the payload being decoded, such as when a block message is decoded while
+// it's read, which lets decoders reject element counts that can't fit into the
+// rest of the payload before allocating room for the elements.
+func remainingPayload(r io.Reader) (int64, bool) {
+	lr, ok := r.(*io.LimitedReader)
+	if !ok {
+		return 0, false
+	}
+
+	return lr.N, true
+}
+
+// checkElementCount returns an error if count elements of at least minSize
+// bytes each can't fit into the rest of the payload being read from r.  No
+// error is returned when the size of the rest of the payload isn't known.
+func checkElementCount(r io.Reader, count uint64, minSize int64, funcName, elements string) error {
+	remaining, ok := remainingPayload(r)
+	if !ok {
+		return nil
+	}
+
+	if count > uint64(remaining/minSize) {
+		str := fmt.Sprintf("too many %s to fit into the rest of the "+
+			"payload [count %d, remaining bytes %d]", elements, count,
+			remaining)
+		return messageError(funcName, str)
+	}
+
+	return nil
+}
+
 // ReadVarString reads a variable length string from r and returns it as a Go
 // string.  A variable length string is encoded as a variable length integer
 // containing the length of the string followed by the bytes that represent the
@@ -446,6 +446,75 @@ func TestVarIntSerializeSize(t *testing.T) {
 	}
 }
 
+// TestSaturatingMul tests that payload length multiplication clamps to
+// MaxMessagePayload instead of wrapping around.
+func TestSaturatingMul(t *testing.T) {
+	tests := []struct {
+		a, b uint32 // Values to multiply
+		want uint32 // Expected product
+	}{
+		// Small values multiply normally.
+		{0, 0, 0},
+		{MaxInvPerMsg, maxInvVectPayload, MaxInvPerMsg * maxInvVectPayload},
+		// Exactly the limit.
+		{MaxMessagePayload, 1, MaxMessagePayload},
+		// Just over the limit.
+		{MaxMessagePayload/2 + 1, 2, MaxMessagePayload},
+		// 2^32 wraps to 0 in uint32.
+		{0x10000, 0x10000, MaxMessagePayload},
+		// (2^31 + 1) * 2 wraps to 2 in uint32.
+		{0x80000001, 2, MaxMessagePayload},
+		// 65537^2 wraps to 131073 in uint32.
+		{65537, 65537, MaxMessagePayload},
+		// Max uint32 squared wraps to 1 in uint32.
+		{0xffffffff, 0xffffffff, MaxMessagePayload},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		got := saturatingMul(test.a, test.b)
+		if got != test.want {
+			t.Errorf("saturatingMul #%d (%d * %d) got: %d, want: %d",
+				i, test.a, test.b, got, test.want)
+			continue
+		}
+	}
+}
+
+// TestMaxCountedPayload tests that the counted payload length includes the
+// count prefix and clamps to MaxMessagePayload instead of wrapping around.
+func TestMaxCountedPayload(t *testing.T) {
+	tests := []struct {
+		count    uint32 // Max number of elements
+		elemSize uint32 // Size of a single element
+		want     uint32 // Expected max payload length
+	}{
+		// No elements is just the count.
+		{0, maxInvVectPayload, MaxVarIntPayload},
+		// Max inventory vectors.
+		{MaxInvPerMsg, maxInvVectPayload,
+			MaxVarIntPayload + MaxInvPerMsg*maxInvVectPayload},
+		// Adding the count pushes the length over the limit.
+		{MaxMessagePayload, 1, MaxMessagePayload},
+		// 2^32 wraps to 0 in uint32.
+		{0x10000, 0x10000, MaxMessagePayload},
+		// (2^31 + 1) * 2 wraps to 2 in uint32.
+		{0x80000001, 2, MaxMessagePayload},
+		// Max uint32 squared wraps to 1 in uint32.
+		{0xffffffff, 0xffffffff, MaxMessagePayload},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		got := maxCountedPayload(test.count, test.elemSize)
+		if got != test.want {
+			t.Errorf("maxCountedPayload #%d (%d * %d) got: %d, want: %d",
+				i, test.count, test.elemSize, got, test.want)
+			continue
+		}
+	}
+}
+
 // TestVarStringWire tests wire encode and decode for variable length strings.
 func TestVarStringWire(t *testing.T) {
 	pver := ProtocolVersion
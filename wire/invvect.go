@@ -20,6 +20,10 @@ const (
 	// Maximum payload size for an inventory vector.
 	maxInvVectPayload = 4 + chainhash.HashSize
 
+	// invVectSize is the number of bytes of an encoded inventory vector:
+	// its type, hash and height.
+	invVectSize = 4 + chainhash.HashSize + 4
+
 	// InvWitnessFlag denotes that the inventory vector type is requesting,
 	// or sending a version which includes witness data.
 	InvWitnessFlag = 1 << 30
@@ -34,6 +38,8 @@ const (
 	InvTypeTx                   InvType = 1
 	InvTypeBlock                InvType = 2
 	InvTypeFilteredBlock        InvType = 3
+	InvTypeDagBlock             InvType = 4
+	InvTypeFilteredDagBlock     InvType = 5
 	InvTypeWitnessBlock         InvType = InvTypeBlock | InvWitnessFlag
 	InvTypeWitnessTx            InvType = InvTypeTx | InvWitnessFlag
 	InvTypeFilteredWitnessBlock InvType = InvTypeFilteredBlock | InvWitnessFlag
@@ -45,6 +51,8 @@ var ivStrings = map[InvType]string{
 	InvTypeTx:                   "MSG_TX",
 	InvTypeBlock:                "MSG_BLOCK",
 	InvTypeFilteredBlock:        "MSG_FILTERED_BLOCK",
+	InvTypeDagBlock:             "MSG_DAG_BLOCK",
+	InvTypeFilteredDagBlock:     "MSG_FILTERED_DAG_BLOCK",
 	InvTypeWitnessBlock:         "MSG_WITNESS_BLOCK",
 	InvTypeWitnessTx:            "MSG_WITNESS_TX",
 	InvTypeFilteredWitnessBlock: "MSG_FILTERED_WITNESS_BLOCK",
@@ -59,6 +67,26 @@ func (invtype InvType) String() string {
 	return fmt.Sprintf("Unknown InvType (%d)", uint32(invtype))
 }
 
+// ForVersion returns the InvType to use when encoding an inventory vector for
+// the given protocol version.  Peers negotiating a version older than
+// DagBlockInvVersion don't know the dag block types, so they're given the
+// equivalent legacy block types instead.  The witness flag is preserved.
+func (invtype InvType) ForVersion(pver uint32) InvType {
+	if pver >= DagBlockInvVersion {
+		return invtype
+	}
+
+	flag := invtype & InvWitnessFlag
+	switch invtype &^ InvWitnessFlag {
+	case InvTypeDagBlock:
+		return InvTypeBlock | flag
+	case InvTypeFilteredDagBlock:
+		return InvTypeFilteredBlock | flag
+	}
+
+	return invtype
+}
+
 // InvVect defines a soter inventory vector which is used to describe data,
 // as specified by the Type field, that a peer wants, has, or does not have to
 // another peer.
@@ -89,6 +117,8 @@ func readInvVect(r io.Reader, pver uint32, iv *InvVect) error {
 }
 
 // writeInvVect serializes an InvVect to w depending on the protocol version.
+// Dag block types are downgraded to the legacy block types for protocol
+// versions that don't support them (see InvType.ForVersion).
 func writeInvVect(w io.Writer, pver uint32, iv *InvVect) error {
-	return writeElements(w, iv.Type, &iv.Hash, &iv.Height)
+	return writeElements(w, iv.Type.ForVersion(pver), &iv.Hash, &iv.Height)
 }
@@ -23,6 +23,8 @@ func TestInvTypeStringer(t *testing.T) {
 		{InvTypeError, "ERROR"},
 		{InvTypeTx, "MSG_TX"},
 		{InvTypeBlock, "MSG_BLOCK"},
+		{InvTypeDagBlock, "MSG_DAG_BLOCK"},
+		{InvTypeFilteredDagBlock, "MSG_FILTERED_DAG_BLOCK"},
 		{0xffffffff, "Unknown InvType (4294967295)"},
 	}
 
@@ -279,3 +281,33 @@ func TestInvVectWire(t *testing.T) {
 		}
 	}
 }
+
+// TestInvTypeForVersion tests that dag block inventory vector types are
+// downgraded to the legacy block types for old protocol versions.
+func TestInvTypeForVersion(t *testing.T) {
+	oldVersion := DagBlockInvVersion - 1
+	tests := []struct {
+		in   InvType
+		pver uint32
+		want InvType
+	}{
+		{InvTypeDagBlock, ProtocolVersion, InvTypeDagBlock},
+		{InvTypeDagBlock, DagBlockInvVersion, InvTypeDagBlock},
+		{InvTypeDagBlock, oldVersion, InvTypeBlock},
+		{InvTypeFilteredDagBlock, ProtocolVersion, InvTypeFilteredDagBlock},
+		{InvTypeFilteredDagBlock, oldVersion, InvTypeFilteredBlock},
+		{InvTypeDagBlock | InvWitnessFlag, oldVersion, InvTypeWitnessBlock},
+		{InvTypeFilteredDagBlock | InvWitnessFlag, oldVersion,
+			InvTypeFilteredWitnessBlock},
+		{InvTypeBlock, oldVersion, InvTypeBlock},
+		{InvTypeTx, oldVersion, InvTypeTx},
+	}
+
+	for i, test := range tests {
+		result := test.in.ForVersion(test.pver)
+		if result != test.want {
+			t.Errorf("ForVersion #%d (%v, pver %d)\n got: %v want: %v",
+				i, test.in, test.pver, result, test.want)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"unicode/utf8"
 
 	"github.com/soteria-dag/soterd/chaincfg/chainhash"
@@ -29,39 +30,63 @@ const MaxMessagePayload = (1024 * 1024 * 32) // 32MB
 
 // Commands used in soter message headers which describe the type of message.
 const (
-	CmdVersion      = "version"
-	CmdVerAck       = "verack"
-	CmdGetAddr      = "getaddr"
-	CmdGetAddrCache = "getaddrcache"
-	CmdAddr         = "addr"
-	CmdAddrCache    = "addrcache"
-	CmdGetBlocks    = "getblocks"
-	CmdInv          = "inv"
-	CmdGetData      = "getdata"
-	CmdNotFound     = "notfound"
-	CmdBlock        = "block"
-	CmdTx           = "tx"
-	CmdGetHeaders   = "getheaders"
-	CmdHeaders      = "headers"
-	CmdPing         = "ping"
-	CmdPong         = "pong"
-	CmdAlert        = "alert"
-	CmdMemPool      = "mempool"
-	CmdFilterAdd    = "filteradd"
-	CmdFilterClear  = "filterclear"
-	CmdFilterLoad   = "filterload"
-	CmdMerkleBlock  = "merkleblock"
-	CmdReject       = "reject"
-	CmdSendHeaders  = "sendheaders"
-	CmdFeeFilter    = "feefilter"
-	CmdGetCFilters  = "getcfilters"
-	CmdGetCFHeaders = "getcfheaders"
-	CmdGetCFCheckpt = "getcfcheckpt"
-	CmdCFilter      = "cfilter"
-	CmdCFHeaders    = "cfheaders"
-	CmdCFCheckpt    = "cfcheckpt"
+	CmdVersion            = "version"
+	CmdVerAck             = "verack"
+	CmdGetAddr            = "getaddr"
+	CmdGetAddrCache       = "getaddrcache"
+	CmdAddr               = "addr"
+	CmdAddrCache          = "addrcache"
+	CmdGetBlocks          = "getblocks"
+	CmdInv                = "inv"
+	CmdGetData            = "getdata"
+	CmdNotFound           = "notfound"
+	CmdBlock              = "block"
+	CmdTx                 = "tx"
+	CmdGetHeaders         = "getheaders"
+	CmdHeaders            = "headers"
+	CmdPing               = "ping"
+	CmdPong               = "pong"
+	CmdAlert              = "alert"
+	CmdMemPool            = "mempool"
+	CmdFilterAdd          = "filteradd"
+	CmdFilterClear        = "filterclear"
+	CmdFilterLoad         = "filterload"
+	CmdMerkleBlock        = "merkleblock"
+	CmdReject             = "reject"
+	CmdSendHeaders        = "sendheaders"
+	CmdFeeFilter          = "feefilter"
+	CmdGetCFilters        = "getcfilters"
+	CmdGetCFHeaders       = "getcfheaders"
+	CmdGetCFCheckpt       = "getcfcheckpt"
+	CmdCFilter            = "cfilter"
+	CmdCFHeaders          = "cfheaders"
+	CmdCFCheckpt          = "cfcheckpt"
+	CmdGetDagTips         = "getdagtips"
+	CmdDagTips            = "dagtips"
+	CmdMerkleDagBlock     = "merkledagblk"
+	CmdGetDagBlockLocator = "getdagblkloc"
+	CmdDagBlockLocator    = "dagblkloc"
+	CmdSendDagHeaders     = "senddaghdrs"
+	CmdDagHeaders         = "daghdrs"
+	CmdSendCmpct          = "sendcmpct"
+	CmdCmpctDagBlock      = "cmpctdagblk"
+	CmdGetBlockTxns       = "getblocktxn"
+	CmdBlockTxns          = "blocktxn"
 )
 
+// IsRelayCommand returns whether the command is one of the messages used to
+// announce, request or deliver relayed blocks and transactions.  Peers can
+// trigger a lot of work with these messages, so they're the ones subject to
+// relay rate limiting.
+func IsRelayCommand(cmd string) bool {
+	switch cmd {
+	case CmdInv, CmdGetData, CmdBlock, CmdTx:
+		return true
+	}
+
+	return false
+}
+
 // MessageEncoding represents the wire message encoding format to be used.
 type MessageEncoding uint32
 
@@ -74,6 +99,12 @@ const (
 	// using the default Soter wire protocol specification. For transaction
 	// messages, the new encoding format detailed in BIP0144 will be used.
 	WitnessEncoding
+
+	// CompressedEncoding may be combined with the other encodings, to
+	// zlib-compress block messages on the wire.  Other messages are encoded
+	// as if it wasn't set.  It may only be used with peers that negotiated a
+	// protocol version of at least CompressedBlockVersion.
+	CompressedEncoding
 )
 
 // LatestEncoding is the most recently specified encoding for the Soter wire
@@ -189,6 +220,39 @@ func makeEmptyMessage(command string) (Message, error) {
 	case CmdCFCheckpt:
 		msg = &MsgCFCheckpt{}
 
+	case CmdGetDagTips:
+		msg = &MsgGetDagTips{}
+
+	case CmdDagTips:
+		msg = &MsgDagTips{}
+
+	case CmdMerkleDagBlock:
+		msg = &MsgMerkleDagBlock{}
+
+	case CmdGetDagBlockLocator:
+		msg = &MsgGetDagBlockLocator{}
+
+	case CmdDagBlockLocator:
+		msg = &MsgDagBlockLocator{}
+
+	case CmdSendDagHeaders:
+		msg = &MsgSendDagHeaders{}
+
+	case CmdDagHeaders:
+		msg = &MsgDagHeaders{}
+
+	case CmdSendCmpct:
+		msg = &MsgSendCmpct{}
+
+	case CmdCmpctDagBlock:
+		msg = &MsgCmpctDagBlock{}
+
+	case CmdGetBlockTxns:
+		msg = &MsgGetBlockTxns{}
+
+	case CmdBlockTxns:
+		msg = &MsgBlockTxns{}
+
 	default:
 		return nil, fmt.Errorf("unhandled command [%s]", command)
 	}
@@ -336,6 +400,91 @@ func WriteMessageWithEncodingN(w io.Writer, msg Message, pver uint32,
 	return totalBytes, err
 }
 
+// countWriter is an io.Writer which discards the bytes written to it, keeping
+// count of them.
+type countWriter struct {
+	n int
+}
+
+// Write counts the bytes of p.
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	return len(p), nil
+}
+
+// invListSize returns the number of bytes the passed inventory vectors take
+// when encoded as the payload of an inv, getdata or notfound message.  The
+// limit on the number of inventory vectors is enforced like it is when the
+// message is encoded, with the error naming the passed function.
+func invListSize(f string, invList []*InvVect) (int, error) {
+	count := len(invList)
+	if count > MaxInvPerMsg {
+		str := fmt.Sprintf("too many invvect in message [%v]", count)
+		return 0, messageError(f, str)
+	}
+
+	return VarIntSerializeSize(uint64(count)) + count*invVectSize, nil
+}
+
+// SerializedSize returns the number of bytes the payload of the message takes
+// when encoded with the passed protocol version and message encoding, which is
+// the number of bytes on the wire without the message header (see
+// MessageHeaderSize).  An error is returned when the message can't be encoded.
+//
+// The size of blocks, transactions, headers and inventory messages is summed
+// from the sizes of their fields and elements, without encoding them.  Other
+// messages are small, and are encoded without keeping the encoded bytes.
+// Compressed blocks are encoded too, since their size depends on how well they
+// compress.
+func SerializedSize(msg Message, pver uint32, enc MessageEncoding) (int, error) {
+	switch msg := msg.(type) {
+	case *MsgVerAck:
+		return 0, nil
+
+	case *MsgTx:
+		if enc&WitnessEncoding == WitnessEncoding {
+			return msg.SerializeSize(), nil
+		}
+		return msg.SerializeSizeStripped(), nil
+
+	case *MsgBlock:
+		if enc&CompressedEncoding == CompressedEncoding {
+			break
+		}
+		if enc&WitnessEncoding == WitnessEncoding {
+			return msg.SerializeSize(), nil
+		}
+		return msg.SerializeSizeStripped(), nil
+
+	case *MsgInv:
+		return invListSize("MsgInv.SotoEncode", msg.InvList)
+
+	case *MsgGetData:
+		return invListSize("MsgGetData.SotoEncode", msg.InvList)
+
+	case *MsgNotFound:
+		return invListSize("MsgNotFound.SotoEncode", msg.InvList)
+
+	case *MsgHeaders:
+		count := len(msg.Headers)
+		if count > MaxBlockHeadersPerMsg {
+			str := fmt.Sprintf("too many block headers for message "+
+				"[count %v, max %v]", count, MaxBlockHeadersPerMsg)
+			return 0, messageError("MsgHeaders.SotoEncode", str)
+		}
+
+		// Each header is followed by a transaction count of 0.
+		return VarIntSerializeSize(uint64(count)) +
+			count*(blockHeaderLen+1), nil
+	}
+
+	var w countWriter
+	if err := msg.SotoEncode(&w, pver, enc); err != nil {
+		return 0, err
+	}
+	return w.n, nil
+}
+
 // ReadMessageWithEncodingN reads, validates, and parses the next soter Message
 // from r for the provided protocol version and soter network.  It returns the
 // number of bytes read in addition to the parsed Message and raw bytes which
@@ -396,6 +545,17 @@ func ReadMessageWithEncodingN(r io.Reader, pver uint32, soternet SoterNet,
 		return totalBytes, nil, nil, messageError("ReadMessage", str)
 	}
 
+	// Block messages are decoded as their payload is read, rather than
+	// after reading all of the payload that the header claims there is.
+	if block, ok := msg.(*MsgBlock); ok {
+		n, payload, err := readBlockPayload(r, pver, enc, hdr, block)
+		totalBytes += n
+		if err != nil {
+			return totalBytes, nil, nil, err
+		}
+		return totalBytes, block, payload, nil
+	}
+
 	// Read payload.
 	payload := make([]byte, hdr.length)
 	n, err = io.ReadFull(r, payload)
@@ -424,6 +584,44 @@ func ReadMessageWithEncodingN(r io.Reader, pver uint32, soternet SoterNet,
 	return totalBytes, msg, payload, nil
 }
 
+// readBlockPayload decodes the payload of a block message from r into block
+// while it's read, and returns the number of bytes read along with the payload.
+// The payload is buffered as it's decoded, so that memory use is proportional
+// to the data actually received rather than the length claimed by the header,
+// and element counts are checked against the rest of the claimed length before
+// any room is allocated for the elements.  Any payload left after the block is
+// read and discarded, and the checksum is verified once all of the payload has
+// been read.
+func readBlockPayload(r io.Reader, pver uint32, enc MessageEncoding,
+	hdr *messageHeader, block *MsgBlock) (int, []byte, error) {
+
+	var payload bytes.Buffer
+	lr := &io.LimitedReader{R: io.TeeReader(r, &payload), N: int64(hdr.length)}
+	err := block.SotoDecode(lr, pver, enc)
+	if err != nil {
+		return payload.Len(), nil, err
+	}
+
+	// Read the rest of the payload, so that it's part of the checksum.
+	_, err = io.Copy(ioutil.Discard, lr)
+	if err != nil {
+		return payload.Len(), nil, err
+	}
+	if lr.N != 0 {
+		return payload.Len(), nil, io.ErrUnexpectedEOF
+	}
+
+	checksum := chainhash.DoubleHashB(payload.Bytes())[0:4]
+	if !bytes.Equal(checksum[:], hdr.checksum[:]) {
+		str := fmt.Sprintf("payload checksum failed - header "+
+			"indicates %v, but actual checksum is %v.",
+			hdr.checksum, checksum)
+		return payload.Len(), nil, messageError("ReadMessage", str)
+	}
+
+	return payload.Len(), payload.Bytes(), nil
+}
+
 // ReadMessageN reads, validates, and parses the next soter Message from r for
 // the provided protocol version and soter network.  It returns the number of
 // bytes read in addition to the parsed Message and raw bytes which comprise the
@@ -87,6 +87,17 @@ func TestMessage(t *testing.T) {
 		[]byte("payload"))
 	msgCFHeaders := NewMsgCFHeaders()
 	msgCFCheckpt := NewMsgCFCheckpt(GCSFilterRegular, &chainhash.Hash{}, 0)
+	msgGetDagTips := NewMsgGetDagTips()
+	msgDagTips := NewMsgDagTips()
+	msgMerkleDagBlock := NewMsgMerkleDagBlock(bh, &ParentSubHeader{})
+	msgGetDagBlockLocator := NewMsgGetDagBlockLocator()
+	msgDagBlockLocator := NewMsgDagBlockLocator()
+	msgSendDagHeaders := NewMsgSendDagHeaders()
+	msgDagHeaders := NewMsgDagHeaders()
+	msgSendCmpct := NewMsgSendCmpct(true, SendCmpctVersion)
+	msgCmpctDagBlock := NewMsgCmpctDagBlock(&blockOne, 123123)
+	msgGetBlockTxns := NewMsgGetBlockTxns(&chainhash.Hash{}, []uint32{1})
+	msgBlockTxns := NewMsgBlockTxns(&chainhash.Hash{})
 
 	tests := []struct {
 		in       Message  // Value to encode
@@ -122,6 +133,17 @@ func TestMessage(t *testing.T) {
 		{msgCFilter, msgCFilter, pver, MainNet, 65},
 		{msgCFHeaders, msgCFHeaders, pver, MainNet, 90},
 		{msgCFCheckpt, msgCFCheckpt, pver, MainNet, 58},
+		{msgGetDagTips, msgGetDagTips, pver, MainNet, 24},
+		{msgDagTips, msgDagTips, pver, MainNet, 25},
+		{msgMerkleDagBlock, msgMerkleDagBlock, pver, MainNet, 118},
+		{msgGetDagBlockLocator, msgGetDagBlockLocator, pver, MainNet, 25},
+		{msgDagBlockLocator, msgDagBlockLocator, pver, MainNet, 25},
+		{msgSendDagHeaders, msgSendDagHeaders, pver, MainNet, 24},
+		{msgDagHeaders, msgDagHeaders, pver, MainNet, 25},
+		{msgSendCmpct, msgSendCmpct, pver, MainNet, 33},
+		{msgCmpctDagBlock, msgCmpctDagBlock, pver, MainNet, 257},
+		{msgGetBlockTxns, msgGetBlockTxns, pver, MainNet, 58},
+		{msgBlockTxns, msgBlockTxns, pver, MainNet, 57},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -468,3 +490,129 @@ func TestWriteMessageWireErrors(t *testing.T) {
 		}
 	}
 }
+
+// TestIsRelayCommand tests that only the block and transaction relay commands
+// are classified as relay commands.
+func TestIsRelayCommand(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want bool
+	}{
+		{CmdInv, true},
+		{CmdGetData, true},
+		{CmdBlock, true},
+		{CmdTx, true},
+		{CmdVersion, false},
+		{CmdPing, false},
+		{CmdGetHeaders, false},
+		{CmdNotFound, false},
+		{CmdMerkleBlock, false},
+		{"", false},
+		{"bogus", false},
+	}
+
+	for i, test := range tests {
+		got := IsRelayCommand(test.cmd)
+		if got != test.want {
+			t.Errorf("IsRelayCommand #%d (%q): got %v, want %v", i,
+				test.cmd, got, test.want)
+		}
+	}
+}
+
+// TestSerializedSize tests that SerializedSize returns the length of the
+// encoded payload of various messages.
+func TestSerializedSize(t *testing.T) {
+	pver := ProtocolVersion
+
+	msgInv := NewMsgInv()
+	msgGetData := NewMsgGetData()
+	msgNotFound := NewMsgNotFound()
+	for i := int32(0); i < 3; i++ {
+		iv := NewInvVect(InvTypeTx, &chainhash.Hash{byte(i)}, i)
+		msgInv.AddInvVect(iv)
+		msgGetData.AddInvVect(iv)
+		msgNotFound.AddInvVect(iv)
+	}
+	msgHeaders := NewMsgHeaders()
+	msgHeaders.AddBlockHeader(&blockOne.Header)
+	msgHeaders.AddBlockHeader(&blockOne.Header)
+	witnessBlock := &MsgBlock{
+		Header:       blockOne.Header,
+		Parents:      blockOne.Parents,
+		Transactions: []*MsgTx{multiWitnessTx},
+	}
+
+	tests := []struct {
+		name string
+		in   Message
+		pver uint32
+		enc  MessageEncoding
+	}{
+		{"verack", NewMsgVerAck(), pver, BaseEncoding},
+		{"getaddr", NewMsgGetAddr(), pver, BaseEncoding},
+		{"ping", NewMsgPing(123123), pver, BaseEncoding},
+		{"ping without nonce", NewMsgPing(123123), BIP0031Version, BaseEncoding},
+		{"reject", NewMsgReject("block", RejectDuplicate, "duplicate block"), pver, BaseEncoding},
+		{"block", &blockOne, pver, BaseEncoding},
+		{"compressed block", &blockOne, pver, BaseEncoding | CompressedEncoding},
+		{"witness block", witnessBlock, pver, WitnessEncoding},
+		{"witness block stripped", witnessBlock, pver, BaseEncoding},
+		{"tx", multiTx, pver, BaseEncoding},
+		{"witness tx", multiWitnessTx, pver, WitnessEncoding},
+		{"witness tx stripped", multiWitnessTx, pver, BaseEncoding},
+		{"empty inv", NewMsgInv(), pver, BaseEncoding},
+		{"inv", msgInv, pver, BaseEncoding},
+		{"getdata", msgGetData, pver, BaseEncoding},
+		{"notfound", msgNotFound, pver, BaseEncoding},
+		{"headers", msgHeaders, pver, BaseEncoding},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := test.in.SotoEncode(&buf, test.pver, test.enc); err != nil {
+			t.Errorf("%s: SotoEncode: %v", test.name, err)
+			continue
+		}
+		size, err := SerializedSize(test.in, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("%s: SerializedSize: %v", test.name, err)
+			continue
+		}
+		if size != buf.Len() {
+			t.Errorf("%s: SerializedSize is %d, encoded payload is "+
+				"%d bytes", test.name, size, buf.Len())
+		}
+	}
+
+	// A verack message has no payload.
+	size, err := SerializedSize(NewMsgVerAck(), pver, BaseEncoding)
+	if err != nil || size != 0 {
+		t.Errorf("SerializedSize of verack is %d (%v), want 0", size, err)
+	}
+
+	// Messages that can't be encoded return the error of encoding them.
+	tooManyInvs := &MsgInv{InvList: make([]*InvVect, MaxInvPerMsg+1)}
+	errTests := []struct {
+		name string
+		in   Message
+		pver uint32
+	}{
+		{"too many invs", tooManyInvs, pver},
+		{"pong before BIP0031", NewMsgPong(123123), BIP0031Version},
+	}
+	for _, test := range errTests {
+		encodeErr := test.in.SotoEncode(&bytes.Buffer{}, test.pver,
+			BaseEncoding)
+		_, err := SerializedSize(test.in, test.pver, BaseEncoding)
+		if err == nil || encodeErr == nil {
+			t.Errorf("%s: SerializedSize returned %v, SotoEncode "+
+				"returned %v, want errors", test.name, err, encodeErr)
+			continue
+		}
+		if err.Error() != encodeErr.Error() {
+			t.Errorf("%s: SerializedSize returned %v, want %v",
+				test.name, err, encodeErr)
+		}
+	}
+}
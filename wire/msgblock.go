@@ -7,6 +7,7 @@ package wire
 
 import (
 	"bytes"
+	"compress/zlib"
 	"fmt"
 	"io"
 
@@ -27,10 +28,37 @@ const MaxBlocksPerMsg = 500
 // After Segregated Witness, the max block payload has been raised to 4MB.
 const MaxBlockPayload = 4000000
 
+const (
+	// blockUncompressed is the compression flag of a block message encoded
+	// with CompressedEncoding, whose block didn't compress any smaller.
+	// The block follows the flag uncompressed.
+	blockUncompressed = 0x00
+
+	// blockZlibCompressed is the compression flag of a block message
+	// encoded with CompressedEncoding, whose block follows the flag
+	// zlib-compressed.
+	blockZlibCompressed = 0x01
+
+	// compressedBlockOverhead is the number of bytes CompressedEncoding
+	// may add to the size of a block message.  A block is only compressed
+	// when that makes it smaller, so the overhead is the compression flag.
+	compressedBlockOverhead = 1
+)
+
 // maxTxPerBlock is the maximum number of transactions that could
 // possibly fit into a block.
 const maxTxPerBlock = (MaxBlockPayload / minTxPayload) + 1
 
+// txAlloc returns the number of transactions to allocate room for up front
+// when decoding a block that claims to have txCount transactions.  Room for
+// more transactions is only allocated as they're decoded.
+func txAlloc(txCount uint64) uint64 {
+	if txCount > defaultTransactionAlloc {
+		return defaultTransactionAlloc
+	}
+	return txCount
+}
+
 // TxLoc holds locator data for the offset and length of where a transaction is
 // located within a MsgBlock data buffer.
 type TxLoc struct {
@@ -64,6 +92,10 @@ func (msg *MsgBlock) ClearTransactions() {
 // See Deserialize for decoding blocks stored to disk, such as in a database, as
 // opposed to decoding blocks from the wire.
 func (msg *MsgBlock) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if enc&CompressedEncoding == CompressedEncoding {
+		return msg.decodeCompressed(r, pver, enc&^CompressedEncoding)
+	}
+
 	err := readBlockHeader(r, pver, &msg.Header)
 	if err != nil {
 		return err
@@ -88,7 +120,17 @@ func (msg *MsgBlock) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) e
 		return messageError("MsgBlock.SotoDecode", str)
 	}
 
-	msg.Transactions = make([]*MsgTx, 0, txCount)
+	// Make sure the transactions can fit into the rest of the payload when
+	// its size is known, and only allocate room for a bounded number of
+	// them up front, so that memory use is proportional to the
+	// transactions that are actually decoded rather than the claimed count.
+	err = checkElementCount(r, txCount, minTxPayload, "MsgBlock.SotoDecode",
+		"transactions")
+	if err != nil {
+		return err
+	}
+
+	msg.Transactions = make([]*MsgTx, 0, txAlloc(txCount))
 	for i := uint64(0); i < txCount; i++ {
 		tx := MsgTx{}
 		err := tx.SotoDecode(r, pver, enc)
@@ -101,6 +143,68 @@ func (msg *MsgBlock) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) e
 	return nil
 }
 
+// decodeCompressed decodes a block message encoded with CompressedEncoding from
+// r into the receiver.  The block is decoded with the given encoding once it
+// has been decompressed.
+func (msg *MsgBlock) decodeCompressed(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < CompressedBlockVersion {
+		str := fmt.Sprintf("compressed block messages are not supported "+
+			"by protocol version %d", pver)
+		return messageError("MsgBlock.SotoDecode", str)
+	}
+
+	var flag [1]byte
+	_, err := io.ReadFull(r, flag[:])
+	if err != nil {
+		return err
+	}
+
+	switch flag[0] {
+	case blockUncompressed:
+		return msg.SotoDecode(r, pver, enc)
+
+	case blockZlibCompressed:
+		zr, err := zlib.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+
+		// Limit the decompressed block to the max block payload, so that
+		// a small message can't decompress into an unbounded one.
+		lr := &io.LimitedReader{R: zr, N: MaxBlockPayload}
+		err = msg.SotoDecode(lr, pver, enc)
+		if err != nil {
+			if lr.N == 0 {
+				str := fmt.Sprintf("decompressed block is larger "+
+					"than max block payload [max %d]",
+					MaxBlockPayload)
+				return messageError("MsgBlock.SotoDecode", str)
+			}
+			return err
+		}
+
+		// Read to the end of the compressed stream, which also verifies
+		// its checksum.
+		var extra [1]byte
+		n, err := zr.Read(extra[:])
+		if n != 0 {
+			str := "unexpected data after compressed block"
+			return messageError("MsgBlock.SotoDecode", str)
+		}
+		if err != io.EOF {
+			return err
+		}
+
+		return nil
+
+	default:
+		str := fmt.Sprintf("unknown block compression flag %#x",
+			flag[0])
+		return messageError("MsgBlock.SotoDecode", str)
+	}
+}
+
 // Deserialize decodes a block from r into the receiver using a format that is
 // suitable for long-term storage such as a database while respecting the
 // Version field in the block.  This function differs from SotoDecode in that
@@ -165,7 +269,7 @@ func (msg *MsgBlock) DeserializeTxLoc(r *bytes.Buffer) ([]TxLoc, error) {
 
 	// Deserialize each transaction while keeping track of its location
 	// within the byte stream.
-	msg.Transactions = make([]*MsgTx, 0, txCount)
+	msg.Transactions = make([]*MsgTx, 0, txAlloc(txCount))
 	txLocs := make([]TxLoc, txCount)
 	for i := uint64(0); i < txCount; i++ {
 		txLocs[i].TxStart = fullLen - r.Len()
@@ -186,6 +290,10 @@ func (msg *MsgBlock) DeserializeTxLoc(r *bytes.Buffer) ([]TxLoc, error) {
 // See Serialize for encoding blocks to be stored to disk, such as in a
 // database, as opposed to encoding blocks for the wire.
 func (msg *MsgBlock) SotoEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if enc&CompressedEncoding == CompressedEncoding {
+		return msg.encodeCompressed(w, pver, enc&^CompressedEncoding)
+	}
+
 	err := writeBlockHeader(w, pver, &msg.Header)
 	if err != nil {
 		return err
@@ -211,6 +319,52 @@ func (msg *MsgBlock) SotoEncode(w io.Writer, pver uint32, enc MessageEncoding) e
 	return nil
 }
 
+// encodeCompressed encodes the receiver to w as a block message with
+// CompressedEncoding.  The block is encoded with the given encoding before it's
+// compressed.
+func (msg *MsgBlock) encodeCompressed(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < CompressedBlockVersion {
+		str := fmt.Sprintf("compressed block messages are not supported "+
+			"by protocol version %d", pver)
+		return messageError("MsgBlock.SotoEncode", str)
+	}
+
+	var raw bytes.Buffer
+	err := msg.SotoEncode(&raw, pver, enc)
+	if err != nil {
+		return err
+	}
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	_, err = zw.Write(raw.Bytes())
+	if err != nil {
+		return err
+	}
+	err = zw.Close()
+	if err != nil {
+		return err
+	}
+
+	// Send the block uncompressed if compressing it doesn't make it
+	// smaller, so that the message is at most one byte larger than it
+	// would be uncompressed.
+	flag := []byte{blockZlibCompressed}
+	payload := compressed.Bytes()
+	if len(payload) >= raw.Len() {
+		flag[0] = blockUncompressed
+		payload = raw.Bytes()
+	}
+
+	_, err = w.Write(flag)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(payload)
+	return err
+}
+
 // Serialize encodes the block to w using a format that suitable for long-term
 // storage such as a database while respecting the Version field in the block.
 // This function differs from SotoEncode in that SotoEncode encodes the block to
@@ -290,6 +444,10 @@ func (msg *MsgBlock) MaxPayloadLength(pver uint32) uint32 {
 	// Block header at 80 bytes + transaction count + max transactions
 	// which can vary up to the MaxBlockPayload (including the block header
 	// and transaction count).
+	if pver >= CompressedBlockVersion {
+		// The message may carry a compression flag.
+		return MaxBlockPayload + compressedBlockOverhead
+	}
 	return MaxBlockPayload
 }
 
@@ -7,13 +7,16 @@ package wire
 
 import (
 	"bytes"
+	"compress/zlib"
 	"io"
 	"reflect"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
-	"github.com/soteria-dag/soterd/chaincfg/chainhash"
 	"github.com/davecgh/go-spew/spew"
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
 )
 
 // TestBlock tests the MsgBlock API.
@@ -36,8 +39,8 @@ func TestBlock(t *testing.T) {
 	}
 
 	// Ensure max payload is expected value for latest protocol version.
-	// Num addresses (varInt) + max allowed addresses.
-	wantPayload := uint32(4000000)
+	// Max block payload + compression flag.
+	wantPayload := uint32(4000001)
 	maxPayload := msg.MaxPayloadLength(pver)
 	if maxPayload != wantPayload {
 		t.Errorf("MaxPayloadLength: wrong max payload length for "+
@@ -45,6 +48,17 @@ func TestBlock(t *testing.T) {
 			maxPayload, wantPayload)
 	}
 
+	// Ensure max payload is expected value for the protocol version before
+	// compressed blocks were added.
+	pverNoCompression := CompressedBlockVersion - 1
+	wantPayload = uint32(4000000)
+	maxPayload = msg.MaxPayloadLength(pverNoCompression)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v",
+			pverNoCompression, maxPayload, wantPayload)
+	}
+
 	// Ensure we get the same block header data back out.
 	if !reflect.DeepEqual(&msg.Header, bh) {
 		t.Errorf("NewMsgBlock: wrong block header - got %v, want %v",
@@ -465,6 +479,132 @@ func TestBlockOverflowErrors(t *testing.T) {
 	}
 }
 
+// TestBlockStreamingDecode ensures that reading block messages whose header
+// claims a large payload, but whose element counts lie about the data that's
+// actually sent, aborts early without allocating memory for the claimed
+// length or counts.
+func TestBlockStreamingDecode(t *testing.T) {
+	pver := ProtocolVersion
+	soternet := MainNet
+
+	// blockPrefix returns the encoded block header and parent sub-header
+	// of a block with the given parent count, followed by the parents.
+	blockPrefix := func(parentCount []byte, parents int) []byte {
+		var b bytes.Buffer
+		b.Write(blockOneBytes[:blockHeaderLen+ParentVersionSize])
+		b.Write(parentCount)
+		b.Write(make([]byte, parents*ParentSize))
+		return b.Bytes()
+	}
+	noParents := []byte{0x00, 0x00, 0x00, 0x00}
+	oneParent := []byte{0x01, 0x00, 0x00, 0x00}
+	maxTxCount := []byte{0xfe, 0x81, 0x1a, 0x06, 0x00} // maxTxPerBlock
+	fittingTxCount := []byte{0xfe, 0xe0, 0x93, 0x04, 0x00}
+
+	// oneTxPrefix returns a block with one transaction, up to its input
+	// count.
+	oneTxPrefix := func(inputCount []byte) []byte {
+		b := blockPrefix(oneParent, 1)
+		b = append(b, 0x01)                   // Varint for number of transactions
+		b = append(b, 0x01, 0x00, 0x00, 0x00) // Transaction version
+		return append(b, inputCount...)
+	}
+
+	// oneInputPrefix returns a block with one transaction, up to the length
+	// of the signature script of its input.
+	oneInputPrefix := func(scriptLen []byte) []byte {
+		b := oneTxPrefix([]byte{0x01})
+		b = append(b, make([]byte, 36)...) // Previous outpoint
+		return append(b, scriptLen...)
+	}
+
+	tests := []struct {
+		name    string
+		payload []byte // Payload that's actually sent
+		readErr error  // Expected read error
+	}{
+		{
+			name: "negative parent count",
+			payload: blockPrefix([]byte{0xff, 0xff, 0xff, 0xff},
+				0),
+			readErr: &MessageError{},
+		},
+		{
+			name: "parent count past claimed length",
+			payload: blockPrefix([]byte{0xff, 0xff, 0xff, 0x7f},
+				1),
+			readErr: &MessageError{},
+		},
+		{
+			name: "transaction count past claimed length",
+			payload: append(blockPrefix(noParents, 0),
+				maxTxCount...),
+			readErr: &MessageError{},
+		},
+		{
+			name: "transaction count past sent data",
+			payload: append(blockPrefix(noParents, 0),
+				fittingTxCount...),
+			readErr: io.EOF,
+		},
+		{
+			name:    "input count past claimed length",
+			payload: oneTxPrefix([]byte{0xfe, 0x40, 0x0d, 0x03, 0x00}),
+			readErr: &MessageError{},
+		},
+		{
+			name:    "script length past claimed length",
+			payload: oneInputPrefix([]byte{0xfe, 0x00, 0x00, 0x40, 0x00}),
+			readErr: &MessageError{},
+		},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for _, test := range tests {
+		// The header claims the max block payload, but only a little
+		// of it is sent.
+		var buf bytes.Buffer
+		buf.Write(makeHeader(soternet, CmdBlock, MaxBlockPayload, 0))
+		buf.Write(test.payload)
+		sent := buf.Len()
+
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		n, msg, _, err := ReadMessageN(&buf, pver, soternet)
+		runtime.ReadMemStats(&after)
+
+		if reflect.TypeOf(err) != reflect.TypeOf(test.readErr) {
+			t.Errorf("%s: wrong error got: %v, want: %v", test.name,
+				err, test.readErr)
+			continue
+		}
+		if _, ok := err.(*MessageError); !ok && err != test.readErr {
+			t.Errorf("%s: wrong error got: %v, want: %v", test.name,
+				err, test.readErr)
+			continue
+		}
+		if msg != nil {
+			t.Errorf("%s: got a message despite the error", test.name)
+			continue
+		}
+		if n > sent {
+			t.Errorf("%s: read %d bytes, but only %d were sent",
+				test.name, n, sent)
+			continue
+		}
+
+		// The memory allocated must be nowhere near the claimed
+		// payload length.
+		allocated := after.TotalAlloc - before.TotalAlloc
+		if allocated > MaxBlockPayload/4 {
+			t.Errorf("%s: allocated %d bytes decoding %d bytes",
+				test.name, allocated, sent)
+			continue
+		}
+	}
+}
+
 // TestBlockSerializeSize performs tests to ensure the serialize size for
 // various blocks is accurate.
 func TestBlockSerializeSize(t *testing.T) {
@@ -493,6 +633,261 @@ func TestBlockSerializeSize(t *testing.T) {
 	}
 }
 
+// compressibleBlock returns a copy of blockOne with the coinbase transaction
+// repeated enough times for the block to compress well.
+func compressibleBlock() *MsgBlock {
+	block := blockOne
+	block.Transactions = nil
+	for i := 0; i < 100; i++ {
+		block.AddTransaction(blockOne.Transactions[0])
+	}
+
+	return &block
+}
+
+// incompressibleBlock returns a copy of blockOne whose coinbase transaction has
+// a signature script of pseudo-random bytes, so that the block doesn't compress
+// any smaller.
+func incompressibleBlock() *MsgBlock {
+	var script []byte
+	seed := chainhash.DoubleHashB([]byte("incompressible"))
+	for len(script) < 4096 {
+		script = append(script, seed...)
+		seed = chainhash.DoubleHashB(seed)
+	}
+
+	tx := blockOne.Transactions[0].Copy()
+	tx.TxIn[0].SignatureScript = script
+
+	block := blockOne
+	block.Transactions = []*MsgTx{tx}
+
+	return &block
+}
+
+// TestBlockCompressedWire tests the MsgBlock wire encode and decode with
+// CompressedEncoding, to ensure that a compressed block decodes back to the
+// original block, and that its uncompressed encoding is unaffected.
+func TestBlockCompressedWire(t *testing.T) {
+	tests := []struct {
+		in         *MsgBlock       // Message to encode
+		enc        MessageEncoding // Message encoding format
+		compressed bool            // Whether the block should be compressed
+	}{
+		// Block that doesn't compress any smaller.
+		{incompressibleBlock(), BaseEncoding, false},
+		{incompressibleBlock(), WitnessEncoding, false},
+
+		// Small block.
+		{&blockOne, BaseEncoding, true},
+		{&blockOne, WitnessEncoding, true},
+
+		// Block with repetitive transactions.
+		{compressibleBlock(), BaseEncoding, true},
+		{compressibleBlock(), WitnessEncoding, true},
+	}
+
+	pver := CompressedBlockVersion
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message without compression, for comparison.
+		var raw bytes.Buffer
+		err := test.in.SotoEncode(&raw, pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoEncode #%d error %v", i, err)
+			continue
+		}
+
+		// Encode the message with compression.
+		var buf bytes.Buffer
+		err = test.in.SotoEncode(&buf, pver, test.enc|CompressedEncoding)
+		if err != nil {
+			t.Errorf("SotoEncode #%d compressed error %v", i, err)
+			continue
+		}
+
+		wantFlag := byte(blockUncompressed)
+		if test.compressed {
+			wantFlag = blockZlibCompressed
+		}
+		if buf.Bytes()[0] != wantFlag {
+			t.Errorf("SotoEncode #%d wrong compression flag got: "+
+				"%#x, want: %#x", i, buf.Bytes()[0], wantFlag)
+			continue
+		}
+		if test.compressed && buf.Len() >= raw.Len() {
+			t.Errorf("SotoEncode #%d compressed size %d isn't "+
+				"smaller than uncompressed size %d", i,
+				buf.Len(), raw.Len())
+			continue
+		}
+		if buf.Len() > raw.Len()+compressedBlockOverhead {
+			t.Errorf("SotoEncode #%d compressed size %d exceeds "+
+				"uncompressed size %d plus overhead", i,
+				buf.Len(), raw.Len())
+			continue
+		}
+
+		// Decode the compressed message.
+		var msg MsgBlock
+		rbuf := bytes.NewReader(buf.Bytes())
+		err = msg.SotoDecode(rbuf, pver, test.enc|CompressedEncoding)
+		if err != nil {
+			t.Errorf("SotoDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.in) {
+			t.Errorf("SotoDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.in))
+			continue
+		}
+
+		// The decoded block should encode to the original bytes.
+		var reencoded bytes.Buffer
+		err = msg.SotoEncode(&reencoded, pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoEncode #%d re-encode error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(reencoded.Bytes(), raw.Bytes()) {
+			t.Errorf("SotoEncode #%d re-encoded block doesn't match "+
+				"original\n got: %s want: %s", i,
+				spew.Sdump(reencoded.Bytes()), spew.Sdump(raw.Bytes()))
+			continue
+		}
+
+		// The compressed message should also round-trip as a full
+		// message between peers using the encoding.
+		var msgBuf bytes.Buffer
+		_, err = WriteMessageWithEncodingN(&msgBuf, test.in, pver,
+			MainNet, test.enc|CompressedEncoding)
+		if err != nil {
+			t.Errorf("WriteMessageWithEncodingN #%d error %v", i, err)
+			continue
+		}
+		_, readMsg, _, err := ReadMessageWithEncodingN(&msgBuf, pver,
+			MainNet, test.enc|CompressedEncoding)
+		if err != nil {
+			t.Errorf("ReadMessageWithEncodingN #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(readMsg, test.in) {
+			t.Errorf("ReadMessageWithEncodingN #%d\n got: %s "+
+				"want: %s", i, spew.Sdump(readMsg),
+				spew.Sdump(test.in))
+			continue
+		}
+	}
+}
+
+// TestBlockCompressedWireErrors performs negative tests against wire encode
+// and decode of MsgBlock with CompressedEncoding to confirm error paths work
+// correctly.
+func TestBlockCompressedWireErrors(t *testing.T) {
+	wireErr := &MessageError{}
+	enc := BaseEncoding | CompressedEncoding
+
+	var compressed bytes.Buffer
+	err := compressibleBlock().SotoEncode(&compressed,
+		CompressedBlockVersion, enc)
+	if err != nil {
+		t.Fatalf("SotoEncode error %v", err)
+	}
+
+	// Compressed stream that decompresses into more than the max block
+	// payload.  The transaction count fits into the max block payload at
+	// the minimum transaction size, but the transactions are larger than
+	// that, and fill the max block payload exactly before the count is
+	// reached.
+	var bomb bytes.Buffer
+	bomb.WriteByte(blockZlibCompressed)
+	zw := zlib.NewWriter(&bomb)
+	zw.Write(blockOneBytes[:blockHeaderLen+ParentVersionSize])
+	zw.Write([]byte{0x00, 0x00, 0x00, 0x00})       // Number of parents
+	zw.Write([]byte{0xfe, 0xe0, 0x93, 0x04, 0x00}) // Varint for number of transactions (300000)
+	txSpace := MaxBlockPayload - (blockHeaderLen + ParentVersionSize +
+		ParentCountSize + 5)
+	oneInputTx := make([]byte, 51) // Version, input, no outputs, lock time
+	oneInputTx[4] = 0x01           // Varint for number of inputs
+	numTxs := txSpace/len(oneInputTx) - 1
+	for i := 0; i < numTxs; i++ {
+		zw.Write(oneInputTx)
+	}
+	lastTx := make([]byte, txSpace-numTxs*len(oneInputTx))
+	lastTx[4] = 0x01                                 // Varint for number of inputs
+	lastTx[41] = byte(len(lastTx) - len(oneInputTx)) // Varint for signature script length
+	zw.Write(lastTx)
+	zw.Close()
+
+	// Compressed block with data after the end of the block.
+	var trailing bytes.Buffer
+	trailing.WriteByte(blockZlibCompressed)
+	zw = zlib.NewWriter(&trailing)
+	zw.Write(blockOneBytes)
+	zw.Write([]byte{0x00})
+	zw.Close()
+
+	tests := []struct {
+		buf      []byte // Wire encoding
+		pver     uint32 // Protocol version for wire encoding
+		writeErr error  // Expected write error
+		readErr  error  // Expected read error
+	}{
+		// Protocol version that doesn't support compressed blocks.
+		{compressed.Bytes(), CompressedBlockVersion - 1, wireErr, wireErr},
+		// Unknown compression flag.
+		{[]byte{0x02}, CompressedBlockVersion, nil, wireErr},
+		// Missing compression flag.
+		{[]byte{}, CompressedBlockVersion, nil, io.EOF},
+		// Decompressed block larger than the max block payload.
+		{bomb.Bytes(), CompressedBlockVersion, nil, wireErr},
+		// Data after the compressed block.
+		{trailing.Bytes(), CompressedBlockVersion, nil, wireErr},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		if test.writeErr != nil {
+			var buf bytes.Buffer
+			err := blockOne.SotoEncode(&buf, test.pver, enc)
+			if reflect.TypeOf(err) != reflect.TypeOf(test.writeErr) {
+				t.Errorf("SotoEncode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.writeErr)
+				continue
+			}
+		}
+
+		var msg MsgBlock
+		err := msg.SotoDecode(bytes.NewReader(test.buf), test.pver, enc)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.readErr) {
+			t.Errorf("SotoDecode #%d wrong error got: %v, want: %v",
+				i, err, test.readErr)
+			continue
+		}
+
+		// For errors which are not of type MessageError, check them for
+		// equality.
+		if _, ok := err.(*MessageError); !ok {
+			if err != test.readErr {
+				t.Errorf("SotoDecode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.readErr)
+				continue
+			}
+		}
+	}
+
+	// Ensure the decompressed block is stopped at the max block payload,
+	// rather than failing for another reason.
+	var msg MsgBlock
+	err = msg.SotoDecode(bytes.NewReader(bomb.Bytes()), CompressedBlockVersion,
+		enc)
+	if err == nil || !strings.Contains(err.Error(), "max block payload") {
+		t.Errorf("SotoDecode wrong error for oversized decompressed "+
+			"block got: %v", err)
+	}
+}
+
 // blockOne is the first block in the mainnet block chain.
 var blockOne = MsgBlock{
 	Header: BlockHeader{
@@ -516,7 +911,7 @@ var blockOne = MsgBlock{
 	},
 	Parents: ParentSubHeader{
 		Version: 1,
-		Size: 0,
+		Size:    0,
 		Parents: []*Parent{},
 	},
 	Transactions: []*MsgTx{
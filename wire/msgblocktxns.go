@@ -0,0 +1,166 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+)
+
+// MsgBlockTxns implements the Message interface and represents a soter
+// blocktxn message.  It is the reply to a getblocktxn message
+// (MsgGetBlockTxns), and carries the requested transactions of the block in
+// the order of the requested indexes.
+//
+// This message was not added until protocol versions starting with
+// CmpctDagBlockVersion.
+type MsgBlockTxns struct {
+	BlockHash    chainhash.Hash
+	Transactions []*MsgTx
+}
+
+// AddTransaction adds a transaction to the message.
+func (msg *MsgBlockTxns) AddTransaction(tx *MsgTx) error {
+	if len(msg.Transactions)+1 > maxTxPerBlock {
+		str := fmt.Sprintf("too many transactions for message [max %v]",
+			maxTxPerBlock)
+		return messageError("MsgBlockTxns.AddTransaction", str)
+	}
+
+	msg.Transactions = append(msg.Transactions, tx)
+	return nil
+}
+
+// FillBlock sets the transactions of the message in a block reconstructed
+// from a cmpctdagblk message, at the indexes which were missing from the
+// reconstruction (see MsgCmpctDagBlock.Reconstruct).
+//
+// The caller is responsible for checking the merkle root of the filled block,
+// since short transaction id collisions may have placed the wrong
+// transactions in it.
+func (msg *MsgBlockTxns) FillBlock(block *MsgBlock, missing []uint32) error {
+	if hash := block.BlockHash(); !hash.IsEqual(&msg.BlockHash) {
+		str := fmt.Sprintf("transactions are for block %v, not %v",
+			msg.BlockHash, hash)
+		return messageError("MsgBlockTxns.FillBlock", str)
+	}
+	if len(msg.Transactions) != len(missing) {
+		str := fmt.Sprintf("got %v transactions for %v missing "+
+			"transactions", len(msg.Transactions), len(missing))
+		return messageError("MsgBlockTxns.FillBlock", str)
+	}
+
+	for i, index := range missing {
+		if int(index) >= len(block.Transactions) {
+			str := fmt.Sprintf("missing transaction index %v is out "+
+				"of range [count %v]", index,
+				len(block.Transactions))
+			return messageError("MsgBlockTxns.FillBlock", str)
+		}
+		block.Transactions[index] = msg.Transactions[i]
+	}
+
+	return nil
+}
+
+// SotoDecode decodes r using the soter protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgBlockTxns) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < CmpctDagBlockVersion {
+		str := fmt.Sprintf("blocktxn message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgBlockTxns.SotoDecode", str)
+	}
+
+	err := readElement(r, &msg.BlockHash)
+	if err != nil {
+		return err
+	}
+
+	// Read num transactions and limit to max.
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count > maxTxPerBlock {
+		str := fmt.Sprintf("too many transactions for message "+
+			"[count %v, max %v]", count, maxTxPerBlock)
+		return messageError("MsgBlockTxns.SotoDecode", str)
+	}
+
+	msg.Transactions = make([]*MsgTx, 0, count)
+	for i := uint64(0); i < count; i++ {
+		tx := MsgTx{}
+		err := tx.SotoDecode(r, pver, enc)
+		if err != nil {
+			return err
+		}
+		msg.Transactions = append(msg.Transactions, &tx)
+	}
+
+	return nil
+}
+
+// SotoEncode encodes the receiver to w using the soter protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgBlockTxns) SotoEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < CmpctDagBlockVersion {
+		str := fmt.Sprintf("blocktxn message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgBlockTxns.SotoEncode", str)
+	}
+
+	// Limit to max transactions per block.
+	count := len(msg.Transactions)
+	if count > maxTxPerBlock {
+		str := fmt.Sprintf("too many transactions for message "+
+			"[count %v, max %v]", count, maxTxPerBlock)
+		return messageError("MsgBlockTxns.SotoEncode", str)
+	}
+
+	err := writeElement(w, &msg.BlockHash)
+	if err != nil {
+		return err
+	}
+
+	err = WriteVarInt(w, pver, uint64(count))
+	if err != nil {
+		return err
+	}
+
+	for _, tx := range msg.Transactions {
+		err = tx.SotoEncode(w, pver, enc)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgBlockTxns) Command() string {
+	return CmdBlockTxns
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgBlockTxns) MaxPayloadLength(pver uint32) uint32 {
+	// The transactions can't be larger than the block they're from.
+	return chainhash.HashSize + MaxBlockPayload
+}
+
+// NewMsgBlockTxns returns a new soter blocktxn message that conforms to the
+// Message interface, for transactions of the block with the hash.  See
+// MsgBlockTxns for details.
+func NewMsgBlockTxns(hash *chainhash.Hash) *MsgBlockTxns {
+	return &MsgBlockTxns{
+		BlockHash:    *hash,
+		Transactions: make([]*MsgTx, 0),
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestBlockTxns tests the MsgBlockTxns API.
+func TestBlockTxns(t *testing.T) {
+	pver := ProtocolVersion
+
+	// Ensure the command is expected value.
+	wantCmd := "blocktxn"
+	hash := mainNetGenesisHash
+	msg := NewMsgBlockTxns(&hash)
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgBlockTxns: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value for latest protocol version.
+	wantPayload := uint32(4000032)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Ensure transactions are added properly.
+	tx := blockOne.Transactions[0].Copy()
+	msg.AddTransaction(tx)
+	if !reflect.DeepEqual(msg.Transactions, []*MsgTx{tx}) {
+		t.Errorf("AddTransaction: wrong transactions - got %v, want %v",
+			spew.Sdump(msg.Transactions), spew.Sdump(tx))
+	}
+}
+
+// TestBlockTxnsWire tests the MsgBlockTxns wire encode and decode for various
+// numbers of transactions and protocol versions.
+func TestBlockTxnsWire(t *testing.T) {
+	hash := blockOne.BlockHash()
+
+	noTxns := NewMsgBlockTxns(&hash)
+
+	multiTxns := NewMsgBlockTxns(&hash)
+	multiTxns.AddTransaction(blockOne.Transactions[0])
+	multiTxns.AddTransaction(multiTx)
+
+	tests := []struct {
+		in   *MsgBlockTxns   // Message to encode
+		out  *MsgBlockTxns   // Expected decoded message
+		pver uint32          // Protocol version for wire encoding
+		enc  MessageEncoding // Message encoding format
+	}{
+		// Latest protocol version with no transactions.
+		{noTxns, noTxns, ProtocolVersion, BaseEncoding},
+
+		// Latest protocol version with multiple transactions.
+		{multiTxns, multiTxns, ProtocolVersion, BaseEncoding},
+
+		// Protocol version CmpctDagBlockVersion with multiple
+		// transactions.
+		{multiTxns, multiTxns, CmpctDagBlockVersion, WitnessEncoding},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.SotoEncode(&buf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoEncode #%d error %v", i, err)
+			continue
+		}
+
+		// Decode the message from wire format.
+		var msg MsgBlockTxns
+		rbuf := bytes.NewReader(buf.Bytes())
+		err = msg.SotoDecode(rbuf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.out) {
+			t.Errorf("SotoDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.out))
+			continue
+		}
+	}
+}
+
+// TestBlockTxnsWireErrors performs negative tests against wire encode and
+// decode of MsgBlockTxns to confirm error paths work correctly.
+func TestBlockTxnsWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+	wireErr := &MessageError{}
+
+	hash := blockOne.BlockHash()
+	msg := NewMsgBlockTxns(&hash)
+	msg.AddTransaction(blockOne.Transactions[0])
+	var buf bytes.Buffer
+	if err := msg.SotoEncode(&buf, pver, BaseEncoding); err != nil {
+		t.Fatalf("SotoEncode error %v", err)
+	}
+	msgEncoded := buf.Bytes()
+
+	noTxns := NewMsgBlockTxns(&hash)
+
+	// Message that forces an error by having more than the max allowed
+	// transactions.
+	maxTxnsEncoded := append(append([]byte{}, msgEncoded[:32]...),
+		0xfe, 0x82, 0x1a, 0x06, 0x00, // Varint for number of txns (400002)
+	)
+
+	tests := []struct {
+		in       *MsgBlockTxns   // Value to encode
+		buf      []byte          // Wire encoding
+		pver     uint32          // Protocol version for wire encoding
+		enc      MessageEncoding // Message encoding format
+		max      int             // Max size of fixed buffer to induce errors
+		writeErr error           // Expected write error
+		readErr  error           // Expected read error
+	}{
+		// Force error in block hash.
+		{msg, msgEncoded, pver, BaseEncoding, 0, io.ErrShortWrite, io.EOF},
+		// Force error in transaction count.
+		{msg, msgEncoded, pver, BaseEncoding, 32, io.ErrShortWrite, io.EOF},
+		// Force error in transaction.
+		{msg, msgEncoded, pver, BaseEncoding, 33, io.ErrShortWrite, io.EOF},
+		// Force error with greater than max transactions.
+		{noTxns, maxTxnsEncoded, pver, BaseEncoding, len(maxTxnsEncoded), nil, wireErr},
+		// Force error with a protocol version prior to
+		// CmpctDagBlockVersion.
+		{msg, msgEncoded, CmpctDagBlockVersion - 1, BaseEncoding, len(msgEncoded), wireErr, wireErr},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.SotoEncode(w, test.pver, test.enc)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.writeErr) {
+			t.Errorf("SotoEncode #%d wrong error got: %v, want: %v",
+				i, err, test.writeErr)
+			continue
+		}
+
+		// Decode from wire format.
+		var msg MsgBlockTxns
+		r := newFixedReader(test.max, test.buf)
+		err = msg.SotoDecode(r, test.pver, test.enc)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.readErr) {
+			t.Errorf("SotoDecode #%d wrong error got: %v, want: %v",
+				i, err, test.readErr)
+			continue
+		}
+	}
+}
@@ -27,6 +27,10 @@ const (
 // in response to a getcfheaders message (MsgGetCFHeaders). The maximum number
 // of committed filter headers per message is currently 2000. See
 // MsgGetCFHeaders for details on requesting the headers.
+//
+// The filter header of a dag block chains over the filter headers of all of the
+// block's parents, rather than over the header of the block before it in the
+// dag ordering, so checking a header takes the headers of all of its parents.
 type MsgCFHeaders struct {
 	FilterType       FilterType
 	StopHash         chainhash.Hash
@@ -0,0 +1,90 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+)
+
+// TestCFilterWire tests the MsgCFilter wire encode and decode.
+func TestCFilterWire(t *testing.T) {
+	blockHash := chainhash.Hash{0x01, 0x02, 0x03}
+	data := []byte{0x04, 0x05, 0x06, 0x07}
+	msg := NewMsgCFilter(GCSFilterRegular, &blockHash, data)
+	if cmd := msg.Command(); cmd != CmdCFilter {
+		t.Errorf("NewMsgCFilter: wrong command - got %v want %v",
+			cmd, CmdCFilter)
+	}
+
+	encoded := []byte{0x00} // Filter type
+	encoded = append(encoded, blockHash[:]...)
+	encoded = append(encoded, 0x04) // Varint for filter size
+	encoded = append(encoded, data...)
+
+	var buf bytes.Buffer
+	err := msg.SotoEncode(&buf, ProtocolVersion, BaseEncoding)
+	if err != nil {
+		t.Fatalf("SotoEncode error %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), encoded) {
+		t.Fatalf("SotoEncode\n got: %s want: %s",
+			spew.Sdump(buf.Bytes()), spew.Sdump(encoded))
+	}
+
+	var readMsg MsgCFilter
+	err = readMsg.SotoDecode(bytes.NewReader(encoded), ProtocolVersion,
+		BaseEncoding)
+	if err != nil {
+		t.Fatalf("SotoDecode error %v", err)
+	}
+	if !reflect.DeepEqual(&readMsg, msg) {
+		t.Errorf("SotoDecode\n got: %s want: %s", spew.Sdump(readMsg),
+			spew.Sdump(msg))
+	}
+
+	// Deserialize uses the same format as the wire encoding.
+	var storedMsg MsgCFilter
+	err = storedMsg.Deserialize(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("Deserialize error %v", err)
+	}
+	if !reflect.DeepEqual(&storedMsg, msg) {
+		t.Errorf("Deserialize\n got: %s want: %s",
+			spew.Sdump(storedMsg), spew.Sdump(msg))
+	}
+}
+
+// TestCFilterWireErrors tests that filters larger than MaxCFilterDataSize are
+// neither encoded nor decoded.
+func TestCFilterWireErrors(t *testing.T) {
+	blockHash := chainhash.Hash{0x01}
+	msg := NewMsgCFilter(GCSFilterRegular, &blockHash,
+		make([]byte, MaxCFilterDataSize+1))
+
+	var buf bytes.Buffer
+	err := msg.SotoEncode(&buf, ProtocolVersion, BaseEncoding)
+	if _, ok := err.(*MessageError); !ok {
+		t.Errorf("SotoEncode of oversized filter: got error %v, want "+
+			"MessageError", err)
+	}
+
+	// Claim an oversized filter in the varint of the filter size.
+	encoded := []byte{0x00}
+	encoded = append(encoded, blockHash[:]...)
+	encoded = append(encoded, 0xfe, 0x01, 0x00, 0x04, 0x00)
+
+	var readMsg MsgCFilter
+	err = readMsg.SotoDecode(bytes.NewReader(encoded), ProtocolVersion,
+		BaseEncoding)
+	if _, ok := err.(*MessageError); !ok {
+		t.Errorf("SotoDecode of oversized filter: got error %v, want "+
+			"MessageError", err)
+	}
+}
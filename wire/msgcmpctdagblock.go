@@ -0,0 +1,376 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/aead/siphash"
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+)
+
+const (
+	// ShortIDSize is the number of bytes of a short transaction id in a
+	// cmpctdagblk message.
+	ShortIDSize = 6
+
+	// shortIDMask masks a siphash output down to the ShortIDSize bytes of
+	// a short transaction id.
+	shortIDMask = (1 << (ShortIDSize * 8)) - 1
+)
+
+// PrefilledTx is a transaction included in full in a cmpctdagblk message,
+// along with its index in the block.
+type PrefilledTx struct {
+	Index uint32
+	Tx    *MsgTx
+}
+
+// MsgCmpctDagBlock implements the Message interface and represents a soter
+// cmpctdagblk message.  It is used to relay a block to a peer which is
+// expected to already have most of the block's transactions in its mempool.
+// In addition to the block header, the message carries the parent sub-header
+// of the block so that the peer can place the block in the dag.
+//
+// Transactions are identified by short ids, which are the siphash of their
+// transaction hash keyed by the block header and Nonce, truncated to
+// ShortIDSize bytes.  Salting the short ids with the nonce keeps collisions
+// from being reproducible across messages.  Transactions the peer is unlikely
+// to have, such as the coinbase, are included in full as prefilled
+// transactions.  The transactions of the block are the prefilled transactions
+// at their indexes, with the short ids filling the remaining indexes in order.
+//
+// A peer that can't reconstruct the block from its mempool requests the
+// missing transactions with a getblocktxn message (MsgGetBlockTxns).
+//
+// This message was not added until protocol versions starting with
+// CmpctDagBlockVersion.
+type MsgCmpctDagBlock struct {
+	Header        BlockHeader
+	Parents       ParentSubHeader
+	Nonce         uint64
+	ShortIDs      []uint64
+	PrefilledTxns []*PrefilledTx
+}
+
+// shortIDKey returns the siphash key of the short ids in the message, which
+// is derived from the block header and nonce.
+func (msg *MsgCmpctDagBlock) shortIDKey() [16]byte {
+	var buf bytes.Buffer
+	buf.Grow(MaxBlockHeaderPayload + 8)
+	writeBlockHeader(&buf, 0, &msg.Header)
+	writeElement(&buf, msg.Nonce)
+
+	hash := chainhash.HashB(buf.Bytes())
+	var key [16]byte
+	copy(key[:], hash)
+
+	return key
+}
+
+// ShortTxID returns the short id of the transaction with the hash, for the
+// header and nonce of the message.
+func (msg *MsgCmpctDagBlock) ShortTxID(hash *chainhash.Hash) uint64 {
+	key := msg.shortIDKey()
+	return siphash.Sum64(hash[:], &key) & shortIDMask
+}
+
+// TxCount returns the number of transactions in the block described by the
+// message.
+func (msg *MsgCmpctDagBlock) TxCount() int {
+	return len(msg.ShortIDs) + len(msg.PrefilledTxns)
+}
+
+// Reconstruct returns the block described by the message, with the
+// transactions identified by short ids looked up in txs, typically the
+// transactions of the mempool.  The indexes of the transactions which couldn't
+// be found are returned in increasing order, and are nil in the block.  They
+// can be requested with a getblocktxn message, and set in the block with
+// MsgBlockTxns.FillBlock.
+//
+// Transactions whose short ids collide with another transaction in txs, or
+// appear more than once in the message, are treated as missing.  Since a
+// collision with a transaction outside of txs can't be detected, the caller
+// is responsible for checking the merkle root of the reconstructed block.
+func (msg *MsgCmpctDagBlock) Reconstruct(txs []*MsgTx) (*MsgBlock, []uint32, error) {
+	count := msg.TxCount()
+	block := &MsgBlock{
+		Header:       msg.Header,
+		Transactions: make([]*MsgTx, count),
+	}
+	block.Parents.Version = msg.Parents.Version
+	block.Parents.Size = int32(len(msg.Parents.Parents))
+	block.Parents.Parents = make([]*Parent, 0, len(msg.Parents.Parents))
+	for _, parent := range msg.Parents.Parents {
+		p := *parent
+		block.Parents.Parents = append(block.Parents.Parents, &p)
+	}
+
+	// Place the prefilled transactions, tracking which indexes are left
+	// for the short ids.
+	prefilled := make([]bool, count)
+	for _, ptx := range msg.PrefilledTxns {
+		if int(ptx.Index) >= count || prefilled[ptx.Index] {
+			str := fmt.Sprintf("prefilled transaction index %v is "+
+				"invalid [count %v]", ptx.Index, count)
+			return nil, nil, messageError("MsgCmpctDagBlock.Reconstruct", str)
+		}
+		prefilled[ptx.Index] = true
+		block.Transactions[ptx.Index] = ptx.Tx
+	}
+
+	// Index the candidate transactions by short id.  A nil entry marks a
+	// short id shared by several candidates, which can't be told apart.
+	key := msg.shortIDKey()
+	candidates := make(map[uint64]*MsgTx, len(txs))
+	for _, tx := range txs {
+		hash := tx.TxHash()
+		id := siphash.Sum64(hash[:], &key) & shortIDMask
+		if other, ok := candidates[id]; ok {
+			if other != nil && other.TxHash() != hash {
+				candidates[id] = nil
+			}
+			continue
+		}
+		candidates[id] = tx
+	}
+
+	// Short ids which appear more than once in the message can't be
+	// placed either.
+	seen := make(map[uint64]int, len(msg.ShortIDs))
+	for _, id := range msg.ShortIDs {
+		seen[id]++
+	}
+
+	var missing []uint32
+	next := 0
+	for _, id := range msg.ShortIDs {
+		for prefilled[next] {
+			next++
+		}
+
+		tx := candidates[id]
+		if tx != nil && seen[id] == 1 {
+			block.Transactions[next] = tx
+		} else {
+			missing = append(missing, uint32(next))
+		}
+		next++
+	}
+
+	return block, missing, nil
+}
+
+// SotoDecode decodes r using the soter protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgCmpctDagBlock) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < CmpctDagBlockVersion {
+		str := fmt.Sprintf("cmpctdagblk message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgCmpctDagBlock.SotoDecode", str)
+	}
+
+	err := readBlockHeader(r, pver, &msg.Header)
+	if err != nil {
+		return err
+	}
+
+	err = readParentSubHeader(r, pver, &msg.Parents)
+	if err != nil {
+		return err
+	}
+
+	err = readElement(r, &msg.Nonce)
+	if err != nil {
+		return err
+	}
+
+	// Read num short ids and limit to max.
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count > maxTxPerBlock {
+		str := fmt.Sprintf("too many short ids for message "+
+			"[count %v, max %v]", count, maxTxPerBlock)
+		return messageError("MsgCmpctDagBlock.SotoDecode", str)
+	}
+
+	msg.ShortIDs = make([]uint64, 0, count)
+	var id [8]byte
+	for i := uint64(0); i < count; i++ {
+		_, err := io.ReadFull(r, id[:ShortIDSize])
+		if err != nil {
+			return err
+		}
+		msg.ShortIDs = append(msg.ShortIDs, littleEndian.Uint64(id[:]))
+	}
+
+	// Read num prefilled transactions, and limit the number of
+	// transactions in the block to max.
+	prefilledCount, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if prefilledCount > maxTxPerBlock-count {
+		str := fmt.Sprintf("too many transactions for message "+
+			"[count %v, max %v]", count+prefilledCount,
+			maxTxPerBlock)
+		return messageError("MsgCmpctDagBlock.SotoDecode", str)
+	}
+
+	// The prefilled transaction indexes must be within the block.
+	txCount := count + prefilledCount
+	msg.PrefilledTxns = make([]*PrefilledTx, 0, prefilledCount)
+	var next uint64
+	for i := uint64(0); i < prefilledCount; i++ {
+		index, err := readDiffIndex(r, pver, &next, txCount,
+			"prefilled transaction index")
+		if err != nil {
+			return err
+		}
+
+		tx := MsgTx{}
+		err = tx.SotoDecode(r, pver, enc)
+		if err != nil {
+			return err
+		}
+
+		msg.PrefilledTxns = append(msg.PrefilledTxns,
+			&PrefilledTx{Index: index, Tx: &tx})
+	}
+
+	return nil
+}
+
+// SotoEncode encodes the receiver to w using the soter protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgCmpctDagBlock) SotoEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < CmpctDagBlockVersion {
+		str := fmt.Sprintf("cmpctdagblk message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgCmpctDagBlock.SotoEncode", str)
+	}
+
+	// Limit the number of transactions to max.
+	txCount := msg.TxCount()
+	if txCount > maxTxPerBlock {
+		str := fmt.Sprintf("too many transactions for message "+
+			"[count %v, max %v]", txCount, maxTxPerBlock)
+		return messageError("MsgCmpctDagBlock.SotoEncode", str)
+	}
+
+	err := writeBlockHeader(w, pver, &msg.Header)
+	if err != nil {
+		return err
+	}
+
+	err = writeParentSubHeader(w, pver, &msg.Parents)
+	if err != nil {
+		return err
+	}
+
+	err = writeElement(w, msg.Nonce)
+	if err != nil {
+		return err
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(msg.ShortIDs)))
+	if err != nil {
+		return err
+	}
+	var id [8]byte
+	for _, shortID := range msg.ShortIDs {
+		if shortID > shortIDMask {
+			str := fmt.Sprintf("short id %x is larger than %v "+
+				"bytes", shortID, ShortIDSize)
+			return messageError("MsgCmpctDagBlock.SotoEncode", str)
+		}
+		littleEndian.PutUint64(id[:], shortID)
+		_, err = w.Write(id[:ShortIDSize])
+		if err != nil {
+			return err
+		}
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(msg.PrefilledTxns)))
+	if err != nil {
+		return err
+	}
+	var next uint64
+	for _, ptx := range msg.PrefilledTxns {
+		if int(ptx.Index) >= txCount {
+			str := fmt.Sprintf("prefilled transaction index %v is "+
+				"out of range [count %v]", ptx.Index, txCount)
+			return messageError("MsgCmpctDagBlock.SotoEncode", str)
+		}
+		err = writeDiffIndex(w, pver, ptx.Index, &next,
+			"prefilled transaction index")
+		if err != nil {
+			return err
+		}
+
+		err = ptx.Tx.SotoEncode(w, pver, enc)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgCmpctDagBlock) Command() string {
+	return CmdCmpctDagBlock
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgCmpctDagBlock) MaxPayloadLength(pver uint32) uint32 {
+	// Short ids are smaller than the transactions they stand for, so a
+	// compact block can't be larger than the block it describes, plus the
+	// max size of the parent sub-header and the nonce carried alongside
+	// it.
+	return MaxBlockPayload + MaxParentSubHeaderPayload + 8
+}
+
+// NewMsgCmpctDagBlock returns a new soter cmpctdagblk message that conforms
+// to the Message interface, describing the block with short ids salted with
+// the nonce.  The coinbase transaction is prefilled, since a peer can't have
+// it in its mempool.  See MsgCmpctDagBlock for details.
+func NewMsgCmpctDagBlock(block *MsgBlock, nonce uint64) *MsgCmpctDagBlock {
+	msg := &MsgCmpctDagBlock{
+		Header:        block.Header,
+		Nonce:         nonce,
+		ShortIDs:      make([]uint64, 0, len(block.Transactions)),
+		PrefilledTxns: make([]*PrefilledTx, 0, 1),
+	}
+
+	msg.Parents.Version = block.Parents.Version
+	msg.Parents.Size = int32(len(block.Parents.Parents))
+	msg.Parents.Parents = make([]*Parent, 0, len(block.Parents.Parents))
+	for _, parent := range block.Parents.Parents {
+		p := *parent
+		msg.Parents.Parents = append(msg.Parents.Parents, &p)
+	}
+
+	key := msg.shortIDKey()
+	for i, tx := range block.Transactions {
+		if i == 0 {
+			msg.PrefilledTxns = append(msg.PrefilledTxns,
+				&PrefilledTx{Index: 0, Tx: tx})
+			continue
+		}
+
+		hash := tx.TxHash()
+		id := siphash.Sum64(hash[:], &key) & shortIDMask
+		msg.ShortIDs = append(msg.ShortIDs, id)
+	}
+
+	return msg
+}
@@ -0,0 +1,402 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// testCmpctBlock returns a block with a coinbase, numTxs other distinct
+// transactions and two parents, for use in tests.
+func testCmpctBlock(numTxs int) *MsgBlock {
+	dh := testDagHeader()
+	block := &MsgBlock{
+		Header:  dh.Header,
+		Parents: dh.Parents,
+	}
+	block.AddTransaction(blockOne.Transactions[0].Copy())
+	for i := 0; i < numTxs; i++ {
+		tx := multiTx.Copy()
+		tx.LockTime = uint32(i + 1)
+		block.AddTransaction(tx)
+	}
+
+	return block
+}
+
+// TestCmpctDagBlock tests the MsgCmpctDagBlock API.
+func TestCmpctDagBlock(t *testing.T) {
+	pver := ProtocolVersion
+
+	// Ensure the command is expected value.
+	wantCmd := "cmpctdagblk"
+	block := testCmpctBlock(3)
+	msg := NewMsgCmpctDagBlock(block, 0x0102030405060708)
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgCmpctDagBlock: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value for latest protocol version.
+	// Max block payload + max parent sub-header payload + nonce.
+	wantPayload := uint32(4000528)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Ensure the coinbase is prefilled, and the other transactions are
+	// described by their short ids.
+	if len(msg.PrefilledTxns) != 1 || msg.PrefilledTxns[0].Index != 0 ||
+		msg.PrefilledTxns[0].Tx != block.Transactions[0] {
+		t.Errorf("NewMsgCmpctDagBlock: wrong prefilled transactions - "+
+			"got %v", spew.Sdump(msg.PrefilledTxns))
+	}
+	if len(msg.ShortIDs) != 3 {
+		t.Fatalf("NewMsgCmpctDagBlock: wrong number of short ids - "+
+			"got %v, want 3", len(msg.ShortIDs))
+	}
+	for i, tx := range block.Transactions[1:] {
+		hash := tx.TxHash()
+		id := msg.ShortTxID(&hash)
+		if msg.ShortIDs[i] != id {
+			t.Errorf("NewMsgCmpctDagBlock: wrong short id %d - "+
+				"got %x, want %x", i, msg.ShortIDs[i], id)
+		}
+		if id > shortIDMask {
+			t.Errorf("ShortTxID: short id %x is larger than %d "+
+				"bytes", id, ShortIDSize)
+		}
+	}
+	if msg.TxCount() != len(block.Transactions) {
+		t.Errorf("TxCount: got %v, want %v", msg.TxCount(),
+			len(block.Transactions))
+	}
+
+	// Ensure the short ids are salted with the nonce.
+	other := NewMsgCmpctDagBlock(block, 0)
+	if reflect.DeepEqual(msg.ShortIDs, other.ShortIDs) {
+		t.Errorf("NewMsgCmpctDagBlock: short ids don't depend on the " +
+			"nonce")
+	}
+}
+
+// TestCmpctDagBlockWire tests the MsgCmpctDagBlock wire encode and decode for
+// various messages and protocol versions.
+func TestCmpctDagBlockWire(t *testing.T) {
+	// Compact block with only the prefilled coinbase.
+	onlyCoinbase := NewMsgCmpctDagBlock(testCmpctBlock(0), 1)
+
+	// Compact block with short ids.
+	shortIDs := NewMsgCmpctDagBlock(testCmpctBlock(4), 2)
+
+	// Compact block with multiple prefilled transactions between the
+	// short ids.
+	block := testCmpctBlock(4)
+	prefilled := NewMsgCmpctDagBlock(block, 3)
+	prefilled.ShortIDs = []uint64{prefilled.ShortIDs[0],
+		prefilled.ShortIDs[2]}
+	prefilled.PrefilledTxns = append(prefilled.PrefilledTxns,
+		&PrefilledTx{Index: 2, Tx: block.Transactions[2]},
+		&PrefilledTx{Index: 4, Tx: block.Transactions[4]})
+
+	tests := []struct {
+		in   *MsgCmpctDagBlock // Message to encode
+		out  *MsgCmpctDagBlock // Expected decoded message
+		pver uint32            // Protocol version for wire encoding
+		enc  MessageEncoding   // Message encoding format
+	}{
+		// Latest protocol version with only the coinbase.
+		{onlyCoinbase, onlyCoinbase, ProtocolVersion, BaseEncoding},
+
+		// Latest protocol version with short ids.
+		{shortIDs, shortIDs, ProtocolVersion, BaseEncoding},
+
+		// Latest protocol version with multiple prefilled transactions.
+		{prefilled, prefilled, ProtocolVersion, WitnessEncoding},
+
+		// Protocol version CmpctDagBlockVersion with short ids.
+		{shortIDs, shortIDs, CmpctDagBlockVersion, BaseEncoding},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.SotoEncode(&buf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoEncode #%d error %v", i, err)
+			continue
+		}
+
+		// Decode the message from wire format.
+		var msg MsgCmpctDagBlock
+		rbuf := bytes.NewReader(buf.Bytes())
+		err = msg.SotoDecode(rbuf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.out) {
+			t.Errorf("SotoDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.out))
+			continue
+		}
+
+		// The parent references must survive the round trip.
+		if !reflect.DeepEqual(msg.Parents.ParentHashes(),
+			test.in.Parents.ParentHashes()) {
+			t.Errorf("SotoDecode #%d wrong parents got: %v, want: %v",
+				i, msg.Parents.ParentHashes(),
+				test.in.Parents.ParentHashes())
+		}
+	}
+}
+
+// TestCmpctDagBlockWireErrors performs negative tests against wire encode and
+// decode of MsgCmpctDagBlock to confirm error paths work correctly.
+func TestCmpctDagBlockWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+	wireErr := &MessageError{}
+
+	block := testCmpctBlock(1)
+	msg := NewMsgCmpctDagBlock(block, 1)
+	var buf bytes.Buffer
+	if err := msg.SotoEncode(&buf, pver, BaseEncoding); err != nil {
+		t.Fatalf("SotoEncode error %v", err)
+	}
+	msgEncoded := buf.Bytes()
+
+	// Offsets of the fields following the header (80 bytes) and the two
+	// parents of the test block.
+	nonceOffset := 80 + 8 + (2 * ParentSize)
+	shortIDsOffset := nonceOffset + 8
+	prefilledOffset := shortIDsOffset + 1 + ShortIDSize
+
+	// Message that forces an error by having more than the max allowed
+	// parents.
+	manyParents := NewMsgCmpctDagBlock(block, 1)
+	for i := 0; i < maxParents; i++ {
+		manyParents.Parents.Parents = append(manyParents.Parents.Parents,
+			&Parent{})
+	}
+	manyParentsEncoded := append([]byte{}, msgEncoded...)
+	manyParentsEncoded[84] = maxParents + 1 // Number of parents
+
+	// Message that forces an error by having a short id larger than
+	// ShortIDSize bytes.
+	largeShortID := NewMsgCmpctDagBlock(block, 1)
+	largeShortID.ShortIDs[0] = shortIDMask + 1
+
+	// Message that forces an error by having prefilled transactions out
+	// of order.
+	unordered := NewMsgCmpctDagBlock(testCmpctBlock(2), 1)
+	unordered.ShortIDs = unordered.ShortIDs[:1]
+	unordered.PrefilledTxns = []*PrefilledTx{
+		{Index: 2, Tx: block.Transactions[1]},
+		{Index: 0, Tx: block.Transactions[0]},
+	}
+
+	// Message that forces an error by having a prefilled transaction
+	// index past the transactions of the block.
+	outOfRange := NewMsgCmpctDagBlock(block, 1)
+	outOfRange.PrefilledTxns[0].Index = 2
+	outOfRangeEncoded := append([]byte{}, msgEncoded...)
+	outOfRangeEncoded[prefilledOffset+1] = 0x02 // Prefilled tx index
+
+	// Message that forces an error by having more than the max allowed
+	// transactions.
+	maxTxnsEncoded := append(append([]byte{}, msgEncoded[:shortIDsOffset]...),
+		0xfe, 0x82, 0x1a, 0x06, 0x00, // Varint for number of short ids (400002)
+	)
+
+	tests := []struct {
+		in       *MsgCmpctDagBlock // Value to encode
+		buf      []byte            // Wire encoding
+		pver     uint32            // Protocol version for wire encoding
+		enc      MessageEncoding   // Message encoding format
+		max      int               // Max size of fixed buffer to induce errors
+		writeErr error             // Expected write error
+		readErr  error             // Expected read error
+	}{
+		// Force error in block header.
+		{msg, msgEncoded, pver, BaseEncoding, 0, io.ErrShortWrite, io.EOF},
+		// Force error in parents version.
+		{msg, msgEncoded, pver, BaseEncoding, 80, io.ErrShortWrite, io.EOF},
+		// Force error in parent.
+		{msg, msgEncoded, pver, BaseEncoding, 88, io.ErrShortWrite, io.EOF},
+		// Force error in nonce.
+		{msg, msgEncoded, pver, BaseEncoding, nonceOffset, io.ErrShortWrite, io.EOF},
+		// Force error in short id count.
+		{msg, msgEncoded, pver, BaseEncoding, shortIDsOffset, io.ErrShortWrite, io.EOF},
+		// Force error in short id.
+		{msg, msgEncoded, pver, BaseEncoding, shortIDsOffset + 1, io.ErrShortWrite, io.ErrUnexpectedEOF},
+		// Force error in prefilled transaction count.
+		{msg, msgEncoded, pver, BaseEncoding, prefilledOffset, io.ErrShortWrite, io.EOF},
+		// Force error in prefilled transaction index.
+		{msg, msgEncoded, pver, BaseEncoding, prefilledOffset + 1, io.ErrShortWrite, io.EOF},
+		// Force error in prefilled transaction.
+		{msg, msgEncoded, pver, BaseEncoding, prefilledOffset + 2, io.ErrShortWrite, io.EOF},
+		// Force error with greater than max parents.
+		{manyParents, manyParentsEncoded, pver, BaseEncoding, len(manyParentsEncoded), wireErr, wireErr},
+		// Force error with a short id that's too large.
+		{largeShortID, msgEncoded, pver, BaseEncoding, len(msgEncoded), wireErr, nil},
+		// Force error with prefilled transactions out of order.
+		{unordered, msgEncoded, pver, BaseEncoding, len(msgEncoded) * 2, wireErr, nil},
+		// Force error with a prefilled transaction index out of range.
+		{outOfRange, outOfRangeEncoded, pver, BaseEncoding, len(outOfRangeEncoded), wireErr, wireErr},
+		// Force error with greater than max transactions.
+		{msg, maxTxnsEncoded, pver, BaseEncoding, len(maxTxnsEncoded), io.ErrShortWrite, wireErr},
+		// Force error with a protocol version prior to
+		// CmpctDagBlockVersion.
+		{msg, msgEncoded, CmpctDagBlockVersion - 1, BaseEncoding, len(msgEncoded), wireErr, wireErr},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.SotoEncode(w, test.pver, test.enc)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.writeErr) {
+			t.Errorf("SotoEncode #%d wrong error got: %v, want: %v",
+				i, err, test.writeErr)
+			continue
+		}
+
+		// Decode from wire format.
+		var msg MsgCmpctDagBlock
+		r := newFixedReader(test.max, test.buf)
+		err = msg.SotoDecode(r, test.pver, test.enc)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.readErr) {
+			t.Errorf("SotoDecode #%d wrong error got: %v, want: %v",
+				i, err, test.readErr)
+			continue
+		}
+	}
+}
+
+// TestCmpctDagBlockReconstruct tests reconstructing a block from a compact
+// block and the transactions of a mempool, requesting the missing
+// transactions from the peer.
+func TestCmpctDagBlockReconstruct(t *testing.T) {
+	pver := ProtocolVersion
+	block := testCmpctBlock(5)
+	blockHash := block.BlockHash()
+
+	// Relay the block as a compact block.
+	var buf bytes.Buffer
+	sent := NewMsgCmpctDagBlock(block, 0xdeadbeef)
+	if err := sent.SotoEncode(&buf, pver, BaseEncoding); err != nil {
+		t.Fatalf("SotoEncode error %v", err)
+	}
+	var msg MsgCmpctDagBlock
+	if err := msg.SotoDecode(&buf, pver, BaseEncoding); err != nil {
+		t.Fatalf("SotoDecode error %v", err)
+	}
+
+	// The mempool has some of the block's transactions, in a different
+	// order, along with a transaction which isn't in the block.
+	unrelated := multiTx.Copy()
+	unrelated.LockTime = 100
+	mempool := []*MsgTx{
+		block.Transactions[4].Copy(),
+		unrelated,
+		block.Transactions[1].Copy(),
+		block.Transactions[2].Copy(),
+	}
+
+	partial, missing, err := msg.Reconstruct(mempool)
+	if err != nil {
+		t.Fatalf("Reconstruct error %v", err)
+	}
+	wantMissing := []uint32{3, 5}
+	if !reflect.DeepEqual(missing, wantMissing) {
+		t.Fatalf("Reconstruct wrong missing transactions got: %v, "+
+			"want: %v", missing, wantMissing)
+	}
+	if hash := partial.BlockHash(); !hash.IsEqual(&blockHash) {
+		t.Fatalf("Reconstruct wrong block hash got: %v, want: %v",
+			hash, blockHash)
+	}
+	if !reflect.DeepEqual(partial.Parents, block.Parents) {
+		t.Fatalf("Reconstruct wrong parents got: %v, want: %v",
+			spew.Sdump(partial.Parents), spew.Sdump(block.Parents))
+	}
+
+	// Request the missing transactions, and have the peer reply with
+	// them.
+	buf.Reset()
+	getTxns := NewMsgGetBlockTxns(&blockHash, missing)
+	if err := getTxns.SotoEncode(&buf, pver, BaseEncoding); err != nil {
+		t.Fatalf("SotoEncode error %v", err)
+	}
+	var gotGetTxns MsgGetBlockTxns
+	if err := gotGetTxns.SotoDecode(&buf, pver, BaseEncoding); err != nil {
+		t.Fatalf("SotoDecode error %v", err)
+	}
+
+	buf.Reset()
+	txns := NewMsgBlockTxns(&gotGetTxns.BlockHash)
+	for _, index := range gotGetTxns.Indexes {
+		txns.AddTransaction(block.Transactions[index])
+	}
+	if err := txns.SotoEncode(&buf, pver, BaseEncoding); err != nil {
+		t.Fatalf("SotoEncode error %v", err)
+	}
+	var gotTxns MsgBlockTxns
+	if err := gotTxns.SotoDecode(&buf, pver, BaseEncoding); err != nil {
+		t.Fatalf("SotoDecode error %v", err)
+	}
+
+	// Filling in the missing transactions completes the block.
+	if err := gotTxns.FillBlock(partial, missing); err != nil {
+		t.Fatalf("FillBlock error %v", err)
+	}
+	if !reflect.DeepEqual(partial, block) {
+		t.Fatalf("reconstructed block got: %s want: %s",
+			spew.Sdump(partial), spew.Sdump(block))
+	}
+
+	// Transactions for another block, or with the wrong number of
+	// transactions, can't fill the block.
+	otherHash := mainNetGenesisHash
+	if err := NewMsgBlockTxns(&otherHash).FillBlock(partial, nil); err == nil {
+		t.Errorf("FillBlock accepted transactions for another block")
+	}
+	if err := gotTxns.FillBlock(partial, missing[:1]); err == nil {
+		t.Errorf("FillBlock accepted the wrong number of transactions")
+	}
+
+	// Transactions whose short ids appear more than once in the compact
+	// block can't be told apart, so they're treated as missing even when
+	// they're in the mempool.
+	duplicates := NewMsgCmpctDagBlock(block, 0xdeadbeef)
+	duplicates.ShortIDs[2] = duplicates.ShortIDs[1]
+	_, missing, err = duplicates.Reconstruct(block.Transactions[1:])
+	if err != nil {
+		t.Fatalf("Reconstruct error %v", err)
+	}
+	wantMissing = []uint32{2, 3}
+	if !reflect.DeepEqual(missing, wantMissing) {
+		t.Errorf("Reconstruct with duplicate short ids got missing: "+
+			"%v, want: %v", missing, wantMissing)
+	}
+
+	// A prefilled transaction index past the transactions of the block
+	// is rejected.
+	invalid := NewMsgCmpctDagBlock(block, 1)
+	invalid.PrefilledTxns[0].Index = uint32(invalid.TxCount())
+	if _, _, err := invalid.Reconstruct(mempool); err == nil {
+		t.Errorf("Reconstruct accepted an out of range prefilled " +
+			"transaction")
+	}
+}
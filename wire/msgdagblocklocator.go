@@ -0,0 +1,175 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+)
+
+const (
+	// MaxDagLocatorAnchorsPerMsg is the maximum number of anchors that can
+	// be in a single soter dagblkloc message.
+	MaxDagLocatorAnchorsPerMsg = 500
+
+	// maxDagLocatorAnchorPayload is the maximum payload size for a single
+	// dag locator anchor.
+	// Hash + height 4 bytes.
+	maxDagLocatorAnchorPayload = chainhash.HashSize + 4
+)
+
+// DagLocatorAnchor describes a block of the dag that a dag block locator is
+// anchored at.
+type DagLocatorAnchor struct {
+	Hash   chainhash.Hash
+	Height int32
+}
+
+// NewDagLocatorAnchor returns a new DagLocatorAnchor using the provided hash
+// and height.
+func NewDagLocatorAnchor(hash *chainhash.Hash, height int32) *DagLocatorAnchor {
+	return &DagLocatorAnchor{
+		Hash:   *hash,
+		Height: height,
+	}
+}
+
+// readDagLocatorAnchor reads an encoded DagLocatorAnchor from r depending on
+// the protocol version.
+func readDagLocatorAnchor(r io.Reader, pver uint32, anchor *DagLocatorAnchor) error {
+	return readElements(r, &anchor.Hash, &anchor.Height)
+}
+
+// writeDagLocatorAnchor serializes a DagLocatorAnchor to w depending on the
+// protocol version.
+func writeDagLocatorAnchor(w io.Writer, pver uint32, anchor *DagLocatorAnchor) error {
+	return writeElements(w, &anchor.Hash, anchor.Height)
+}
+
+// MsgDagBlockLocator implements the Message interface and represents a soter
+// dagblkloc message.  It is used to deliver a dag block locator in response to
+// a getdagblkloc message (MsgGetDagBlockLocator).
+//
+// A dag block locator is a sparse set of anchors, spanning the known tips of
+// the sending node's dag down to the genesis block.  It is ordered from the
+// highest tip down, so the height of each anchor is less than the height of
+// the anchor before it.  The receiving peer can use the anchors to find the
+// frontier of blocks that the sending node is missing.  Each message is limited
+// to a maximum number of anchors, which is currently
+// MaxDagLocatorAnchorsPerMsg.
+//
+// Use the AddAnchor function to build up the list of anchors when sending a
+// dagblkloc message to another peer.
+type MsgDagBlockLocator struct {
+	Anchors []*DagLocatorAnchor
+}
+
+// AddAnchor adds an anchor to the message.  The anchor's height must be less
+// than the height of the last anchor in the message.
+func (msg *MsgDagBlockLocator) AddAnchor(anchor *DagLocatorAnchor) error {
+	if len(msg.Anchors)+1 > MaxDagLocatorAnchorsPerMsg {
+		str := fmt.Sprintf("too many dag locator anchors in message "+
+			"[max %v]", MaxDagLocatorAnchorsPerMsg)
+		return messageError("MsgDagBlockLocator.AddAnchor", str)
+	}
+
+	if len(msg.Anchors) > 0 {
+		last := msg.Anchors[len(msg.Anchors)-1]
+		if anchor.Height >= last.Height {
+			str := fmt.Sprintf("dag locator anchor height %v is "+
+				"not less than previous anchor height %v",
+				anchor.Height, last.Height)
+			return messageError("MsgDagBlockLocator.AddAnchor", str)
+		}
+	}
+
+	msg.Anchors = append(msg.Anchors, anchor)
+	return nil
+}
+
+// SotoDecode decodes r using the soter protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgDagBlockLocator) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+
+	// Limit to max dag locator anchors per message.
+	if count > MaxDagLocatorAnchorsPerMsg {
+		str := fmt.Sprintf("too many dag locator anchors for message "+
+			"[count %v, max %v]", count, MaxDagLocatorAnchorsPerMsg)
+		return messageError("MsgDagBlockLocator.SotoDecode", str)
+	}
+
+	// Create a contiguous slice of anchors to deserialize into in order to
+	// reduce the number of allocations.
+	anchors := make([]DagLocatorAnchor, count)
+	msg.Anchors = make([]*DagLocatorAnchor, 0, count)
+	for i := uint64(0); i < count; i++ {
+		anchor := &anchors[i]
+		err := readDagLocatorAnchor(r, pver, anchor)
+		if err != nil {
+			return err
+		}
+
+		err = msg.AddAnchor(anchor)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SotoEncode encodes the receiver to w using the soter protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgDagBlockLocator) SotoEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	// Limit to max dag locator anchors per message.
+	count := len(msg.Anchors)
+	if count > MaxDagLocatorAnchorsPerMsg {
+		str := fmt.Sprintf("too many dag locator anchors for message "+
+			"[count %v, max %v]", count, MaxDagLocatorAnchorsPerMsg)
+		return messageError("MsgDagBlockLocator.SotoEncode", str)
+	}
+
+	err := WriteVarInt(w, pver, uint64(count))
+	if err != nil {
+		return err
+	}
+
+	for _, anchor := range msg.Anchors {
+		err := writeDagLocatorAnchor(w, pver, anchor)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgDagBlockLocator) Command() string {
+	return CmdDagBlockLocator
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgDagBlockLocator) MaxPayloadLength(pver uint32) uint32 {
+	// Num anchors (varInt) + max allowed anchors.
+	return maxCountedPayload(MaxDagLocatorAnchorsPerMsg,
+		maxDagLocatorAnchorPayload)
+}
+
+// NewMsgDagBlockLocator returns a new soter dagblkloc message that conforms to
+// the Message interface.  See MsgDagBlockLocator for details.
+func NewMsgDagBlockLocator() *MsgDagBlockLocator {
+	return &MsgDagBlockLocator{
+		Anchors: make([]*DagLocatorAnchor, 0),
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+)
+
+// DagHeader pairs a block header with the parent sub-header of the block.
+// The block header on its own only commits to the hash of the block's
+// parents, so the parent sub-header is needed to place the block in the dag.
+type DagHeader struct {
+	Header  BlockHeader
+	Parents ParentSubHeader
+}
+
+// NewDagHeader returns a new DagHeader using the provided block header and
+// parent sub-header.
+func NewDagHeader(bh *BlockHeader, parents *ParentSubHeader) *DagHeader {
+	return &DagHeader{
+		Header:  *bh,
+		Parents: *parents,
+	}
+}
+
+// MsgDagHeaders implements the Message interface and represents a soter
+// daghdrs message.  It is the dag equivalent of the headers message
+// (MsgHeaders), and is used to announce new blocks to peers that requested it
+// with a senddaghdrs message (MsgSendDagHeaders).  Each header is delivered
+// along with the parent sub-header of its block, since a block may have
+// multiple parents.  The maximum number of headers per message is
+// MaxBlockHeadersPerMsg.
+//
+// This message was not added until protocol versions starting with
+// DagHeadersVersion.
+type MsgDagHeaders struct {
+	Headers []*DagHeader
+}
+
+// AddDagHeader adds a new dag header to the message.
+func (msg *MsgDagHeaders) AddDagHeader(dh *DagHeader) error {
+	if len(msg.Headers)+1 > MaxBlockHeadersPerMsg {
+		str := fmt.Sprintf("too many dag headers in message [max %v]",
+			MaxBlockHeadersPerMsg)
+		return messageError("MsgDagHeaders.AddDagHeader", str)
+	}
+
+	msg.Headers = append(msg.Headers, dh)
+	return nil
+}
+
+// SotoDecode decodes r using the soter protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgDagHeaders) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < DagHeadersVersion {
+		str := fmt.Sprintf("daghdrs message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgDagHeaders.SotoDecode", str)
+	}
+
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+
+	// Limit to max block headers per message.
+	if count > MaxBlockHeadersPerMsg {
+		str := fmt.Sprintf("too many dag headers for message "+
+			"[count %v, max %v]", count, MaxBlockHeadersPerMsg)
+		return messageError("MsgDagHeaders.SotoDecode", str)
+	}
+
+	// Create a contiguous slice of headers to deserialize into in order to
+	// reduce the number of allocations.
+	headers := make([]DagHeader, count)
+	msg.Headers = make([]*DagHeader, 0, count)
+	for i := uint64(0); i < count; i++ {
+		dh := &headers[i]
+		err := readBlockHeader(r, pver, &dh.Header)
+		if err != nil {
+			return err
+		}
+
+		err = readParentSubHeader(r, pver, &dh.Parents)
+		if err != nil {
+			return err
+		}
+
+		msg.AddDagHeader(dh)
+	}
+
+	return nil
+}
+
+// SotoEncode encodes the receiver to w using the soter protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgDagHeaders) SotoEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < DagHeadersVersion {
+		str := fmt.Sprintf("daghdrs message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgDagHeaders.SotoEncode", str)
+	}
+
+	// Limit to max block headers per message.
+	count := len(msg.Headers)
+	if count > MaxBlockHeadersPerMsg {
+		str := fmt.Sprintf("too many dag headers for message "+
+			"[count %v, max %v]", count, MaxBlockHeadersPerMsg)
+		return messageError("MsgDagHeaders.SotoEncode", str)
+	}
+
+	err := WriteVarInt(w, pver, uint64(count))
+	if err != nil {
+		return err
+	}
+
+	for _, dh := range msg.Headers {
+		err := writeBlockHeader(w, pver, &dh.Header)
+		if err != nil {
+			return err
+		}
+
+		err = writeParentSubHeader(w, pver, &dh.Parents)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgDagHeaders) Command() string {
+	return CmdDagHeaders
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgDagHeaders) MaxPayloadLength(pver uint32) uint32 {
+	// Num headers (varInt) + max allowed headers (header length + max
+	// parent sub-header length).
+	return MaxVarIntPayload + ((MaxBlockHeaderPayload +
+		MaxParentSubHeaderPayload) * MaxBlockHeadersPerMsg)
+}
+
+// NewMsgDagHeaders returns a new soter daghdrs message that conforms to the
+// Message interface.  See MsgDagHeaders for details.
+func NewMsgDagHeaders() *MsgDagHeaders {
+	return &MsgDagHeaders{
+		Headers: make([]*DagHeader, 0, MaxBlockHeadersPerMsg),
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// testDagHeader returns a dag header with two parents for use in tests.
+func testDagHeader() *DagHeader {
+	hash := mainNetGenesisHash
+	merkleHash := blockOne.Header.MerkleRoot
+	bits := uint32(0x1d00ffff)
+	nonce := uint32(0x9962e301)
+	bh := NewBlockHeader(1, &hash, &merkleHash, bits, nonce)
+	bh.Version = blockOne.Header.Version
+	bh.Timestamp = blockOne.Header.Timestamp
+
+	parents := &ParentSubHeader{
+		Version: 1,
+		Size:    2,
+		Parents: []*Parent{
+			{Hash: mainNetGenesisHash},
+			{Hash: merkleHash},
+		},
+	}
+
+	return NewDagHeader(bh, parents)
+}
+
+// testDagHeaderEncoded is the wire encoding of the header returned by
+// testDagHeader.
+var testDagHeaderEncoded = []byte{
+	0x01, 0x00, 0x00, 0x00, // Version 1
+	0x6f, 0xe2, 0x8c, 0x0a, 0xb6, 0xf1, 0xb3, 0x72,
+	0xc1, 0xa6, 0xa2, 0x46, 0xae, 0x63, 0xf7, 0x4f,
+	0x93, 0x1e, 0x83, 0x65, 0xe1, 0x5a, 0x08, 0x9c,
+	0x68, 0xd6, 0x19, 0x00, 0x00, 0x00, 0x00, 0x00, // PrevBlock
+	0x98, 0x20, 0x51, 0xfd, 0x1e, 0x4b, 0xa7, 0x44,
+	0xbb, 0xbe, 0x68, 0x0e, 0x1f, 0xee, 0x14, 0x67,
+	0x7b, 0xa1, 0xa3, 0xc3, 0x54, 0x0b, 0xf7, 0xb1,
+	0xcd, 0xb6, 0x06, 0xe8, 0x57, 0x23, 0x3e, 0x0e, // MerkleRoot
+	0x61, 0xbc, 0x66, 0x49, // Timestamp
+	0xff, 0xff, 0x00, 0x1d, // Bits
+	0x01, 0xe3, 0x62, 0x99, // Nonce
+	0x01, 0x00, 0x00, 0x00, // Parents version 1
+	0x02, 0x00, 0x00, 0x00, // Number of parents
+	0x6f, 0xe2, 0x8c, 0x0a, 0xb6, 0xf1, 0xb3, 0x72,
+	0xc1, 0xa6, 0xa2, 0x46, 0xae, 0x63, 0xf7, 0x4f,
+	0x93, 0x1e, 0x83, 0x65, 0xe1, 0x5a, 0x08, 0x9c,
+	0x68, 0xd6, 0x19, 0x00, 0x00, 0x00, 0x00, 0x00, // Parent hash
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Parent data
+	0x98, 0x20, 0x51, 0xfd, 0x1e, 0x4b, 0xa7, 0x44,
+	0xbb, 0xbe, 0x68, 0x0e, 0x1f, 0xee, 0x14, 0x67,
+	0x7b, 0xa1, 0xa3, 0xc3, 0x54, 0x0b, 0xf7, 0xb1,
+	0xcd, 0xb6, 0x06, 0xe8, 0x57, 0x23, 0x3e, 0x0e, // Parent hash
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Parent data
+}
+
+// TestDagHeaders tests the MsgDagHeaders API.
+func TestDagHeaders(t *testing.T) {
+	pver := ProtocolVersion
+
+	// Ensure the command is expected value.
+	wantCmd := "daghdrs"
+	msg := NewMsgDagHeaders()
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgDagHeaders: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value for latest protocol version.
+	// Num headers (varInt) + max allowed headers (header length + max
+	// parent sub-header length).
+	wantPayload := uint32(1200009)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Ensure headers are added properly.
+	dh := testDagHeader()
+	msg.AddDagHeader(dh)
+	if !reflect.DeepEqual(msg.Headers[0], dh) {
+		t.Errorf("AddDagHeader: wrong header - got %v, want %v",
+			spew.Sdump(msg.Headers),
+			spew.Sdump(dh))
+	}
+
+	// Ensure adding more than the max allowed headers per message returns
+	// error.
+	var err error
+	for i := 0; i < MaxBlockHeadersPerMsg+1; i++ {
+		err = msg.AddDagHeader(dh)
+	}
+	if reflect.TypeOf(err) != reflect.TypeOf(&MessageError{}) {
+		t.Errorf("AddDagHeader: expected error on too many headers " +
+			"not received")
+	}
+}
+
+// TestDagHeadersWire tests the MsgDagHeaders wire encode and decode for
+// various numbers of headers and protocol versions.
+func TestDagHeadersWire(t *testing.T) {
+	// Empty headers message.
+	noHeaders := NewMsgDagHeaders()
+	noHeadersEncoded := []byte{
+		0x00, // Varint for number of headers
+	}
+
+	// Headers message with one header.
+	oneHeader := NewMsgDagHeaders()
+	oneHeader.AddDagHeader(testDagHeader())
+	oneHeaderEncoded := append([]byte{
+		0x01, // VarInt for number of headers.
+	}, testDagHeaderEncoded...)
+
+	tests := []struct {
+		in   *MsgDagHeaders  // Message to encode
+		out  *MsgDagHeaders  // Expected decoded message
+		buf  []byte          // Wire encoding
+		pver uint32          // Protocol version for wire encoding
+		enc  MessageEncoding // Message encoding format
+	}{
+		// Latest protocol version with no headers.
+		{
+			noHeaders,
+			noHeaders,
+			noHeadersEncoded,
+			ProtocolVersion,
+			BaseEncoding,
+		},
+
+		// Latest protocol version with one header.
+		{
+			oneHeader,
+			oneHeader,
+			oneHeaderEncoded,
+			ProtocolVersion,
+			BaseEncoding,
+		},
+
+		// Protocol version DagHeadersVersion with one header.
+		{
+			oneHeader,
+			oneHeader,
+			oneHeaderEncoded,
+			DagHeadersVersion,
+			BaseEncoding,
+		},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.SotoEncode(&buf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("SotoEncode #%d\n got: %s want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+
+		// Decode the message from wire format.
+		var msg MsgDagHeaders
+		rbuf := bytes.NewReader(test.buf)
+		err = msg.SotoDecode(rbuf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.out) {
+			t.Errorf("SotoDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.out))
+			continue
+		}
+	}
+}
+
+// TestDagHeadersWireErrors performs negative tests against wire encode and
+// decode of MsgDagHeaders to confirm error paths work correctly.
+func TestDagHeadersWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+	wireErr := &MessageError{}
+
+	// Headers message with one header.
+	oneHeader := NewMsgDagHeaders()
+	oneHeader.AddDagHeader(testDagHeader())
+	oneHeaderEncoded := append([]byte{
+		0x01, // VarInt for number of headers.
+	}, testDagHeaderEncoded...)
+
+	// Message that forces an error by having more than the max allowed
+	// headers.
+	maxHeaders := NewMsgDagHeaders()
+	for i := 0; i < MaxBlockHeadersPerMsg; i++ {
+		maxHeaders.AddDagHeader(testDagHeader())
+	}
+	maxHeaders.Headers = append(maxHeaders.Headers, testDagHeader())
+	maxHeadersEncoded := []byte{
+		0xfd, 0xd1, 0x07, // Varint for number of headers (2001)
+	}
+
+	// Message that forces an error by having a header with more than the
+	// max allowed parents.
+	manyParents := testDagHeader()
+	for i := 0; i < maxParents; i++ {
+		manyParents.Parents.Parents = append(manyParents.Parents.Parents,
+			&Parent{})
+	}
+	maxParentsHeader := NewMsgDagHeaders()
+	maxParentsHeader.AddDagHeader(manyParents)
+	maxParentsEncoded := make([]byte, 89)
+	copy(maxParentsEncoded, oneHeaderEncoded)
+	maxParentsEncoded[85] = maxParents + 3 // Number of parents
+
+	tests := []struct {
+		in       *MsgDagHeaders  // Value to encode
+		buf      []byte          // Wire encoding
+		pver     uint32          // Protocol version for wire encoding
+		enc      MessageEncoding // Message encoding format
+		max      int             // Max size of fixed buffer to induce errors
+		writeErr error           // Expected write error
+		readErr  error           // Expected read error
+	}{
+		// Latest protocol version with intentional read/write errors.
+		// Force error in header count.
+		{oneHeader, oneHeaderEncoded, pver, BaseEncoding, 0, io.ErrShortWrite, io.EOF},
+		// Force error in block header.
+		{oneHeader, oneHeaderEncoded, pver, BaseEncoding, 5, io.ErrShortWrite, io.EOF},
+		// Force error in parents version.
+		{oneHeader, oneHeaderEncoded, pver, BaseEncoding, 81, io.ErrShortWrite, io.EOF},
+		// Force error in number of parents.
+		{oneHeader, oneHeaderEncoded, pver, BaseEncoding, 85, io.ErrShortWrite, io.EOF},
+		// Force error in parent.
+		{oneHeader, oneHeaderEncoded, pver, BaseEncoding, 89, io.ErrShortWrite, io.EOF},
+		// Force error with greater than max headers.
+		{maxHeaders, maxHeadersEncoded, pver, BaseEncoding, 3, wireErr, wireErr},
+		// Force error with greater than max parents.
+		{maxParentsHeader, maxParentsEncoded, pver, BaseEncoding, 89, wireErr, wireErr},
+		// Force error with a protocol version prior to DagHeadersVersion.
+		{oneHeader, oneHeaderEncoded, DagHeadersVersion - 1, BaseEncoding, len(oneHeaderEncoded), wireErr, wireErr},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.SotoEncode(w, test.pver, test.enc)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.writeErr) {
+			t.Errorf("SotoEncode #%d wrong error got: %v, want: %v",
+				i, err, test.writeErr)
+			continue
+		}
+
+		// For errors which are not of type MessageError, check them for
+		// equality.
+		if _, ok := err.(*MessageError); !ok {
+			if err != test.writeErr {
+				t.Errorf("SotoEncode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.writeErr)
+				continue
+			}
+		}
+
+		// Decode from wire format.
+		var msg MsgDagHeaders
+		r := newFixedReader(test.max, test.buf)
+		err = msg.SotoDecode(r, test.pver, test.enc)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.readErr) {
+			t.Errorf("SotoDecode #%d wrong error got: %v, want: %v",
+				i, err, test.readErr)
+			continue
+		}
+
+		// For errors which are not of type MessageError, check them for
+		// equality.
+		if _, ok := err.(*MessageError); !ok {
+			if err != test.readErr {
+				t.Errorf("SotoDecode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.readErr)
+				continue
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+)
+
+const (
+	// MaxDagTipsPerMsg is the maximum number of dag tips that can be in a
+	// single soter dagtips message.
+	MaxDagTipsPerMsg = 1000
+
+	// maxDagTipPayload is the maximum payload size for a single dag tip.
+	// Hash + height 4 bytes.
+	maxDagTipPayload = chainhash.HashSize + 4
+)
+
+// DagTip describes a single tip of the dag, as carried by a dagtips message.
+type DagTip struct {
+	Hash   chainhash.Hash
+	Height int32
+}
+
+// NewDagTip returns a new DagTip using the provided hash and height.
+func NewDagTip(hash *chainhash.Hash, height int32) *DagTip {
+	return &DagTip{
+		Hash:   *hash,
+		Height: height,
+	}
+}
+
+// readDagTip reads an encoded DagTip from r depending on the protocol
+// version.
+func readDagTip(r io.Reader, pver uint32, tip *DagTip) error {
+	return readElements(r, &tip.Hash, &tip.Height)
+}
+
+// writeDagTip serializes a DagTip to w depending on the protocol version.
+func writeDagTip(w io.Writer, pver uint32, tip *DagTip) error {
+	return writeElements(w, &tip.Hash, tip.Height)
+}
+
+// MsgDagTips implements the Message interface and represents a soter dagtips
+// message.  It is used to deliver the current set of dag tips in response to
+// a getdagtips message (MsgGetDagTips).  Each message is limited to a maximum
+// number of tips, which is currently MaxDagTipsPerMsg.
+//
+// Use the AddDagTip function to build up the list of tips when sending a
+// dagtips message to another peer.
+type MsgDagTips struct {
+	Tips []*DagTip
+}
+
+// AddDagTip adds a dag tip to the message.
+func (msg *MsgDagTips) AddDagTip(tip *DagTip) error {
+	if len(msg.Tips)+1 > MaxDagTipsPerMsg {
+		str := fmt.Sprintf("too many dag tips in message [max %v]",
+			MaxDagTipsPerMsg)
+		return messageError("MsgDagTips.AddDagTip", str)
+	}
+
+	msg.Tips = append(msg.Tips, tip)
+	return nil
+}
+
+// SotoDecode decodes r using the soter protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgDagTips) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+
+	// Limit to max dag tips per message.
+	if count > MaxDagTipsPerMsg {
+		str := fmt.Sprintf("too many dag tips for message "+
+			"[count %v, max %v]", count, MaxDagTipsPerMsg)
+		return messageError("MsgDagTips.SotoDecode", str)
+	}
+
+	// Create a contiguous slice of tips to deserialize into in order to
+	// reduce the number of allocations.
+	tips := make([]DagTip, count)
+	msg.Tips = make([]*DagTip, 0, count)
+	for i := uint64(0); i < count; i++ {
+		tip := &tips[i]
+		err := readDagTip(r, pver, tip)
+		if err != nil {
+			return err
+		}
+		msg.AddDagTip(tip)
+	}
+
+	return nil
+}
+
+// SotoEncode encodes the receiver to w using the soter protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgDagTips) SotoEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	// Limit to max dag tips per message.
+	count := len(msg.Tips)
+	if count > MaxDagTipsPerMsg {
+		str := fmt.Sprintf("too many dag tips for message "+
+			"[count %v, max %v]", count, MaxDagTipsPerMsg)
+		return messageError("MsgDagTips.SotoEncode", str)
+	}
+
+	err := WriteVarInt(w, pver, uint64(count))
+	if err != nil {
+		return err
+	}
+
+	for _, tip := range msg.Tips {
+		err := writeDagTip(w, pver, tip)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgDagTips) Command() string {
+	return CmdDagTips
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgDagTips) MaxPayloadLength(pver uint32) uint32 {
+	// Num dag tips (varInt) + max allowed dag tips.
+	return maxCountedPayload(MaxDagTipsPerMsg, maxDagTipPayload)
+}
+
+// NewMsgDagTips returns a new soter dagtips message that conforms to the
+// Message interface.  See MsgDagTips for details.
+func NewMsgDagTips() *MsgDagTips {
+	return &MsgDagTips{
+		Tips: make([]*DagTip, 0),
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+)
+
+// TestDagTips tests the MsgDagTips API.
+func TestDagTips(t *testing.T) {
+	pver := ProtocolVersion
+
+	// Ensure the command is expected value.
+	wantCmd := "dagtips"
+	msg := NewMsgDagTips()
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgDagTips: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value for latest protocol version.
+	// Num dag tips (varInt) + max allowed dag tips.
+	wantPayload := uint32(36009)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Ensure dag tips are added properly.
+	hash := chainhash.Hash{}
+	tip := NewDagTip(&hash, 1)
+	err := msg.AddDagTip(tip)
+	if err != nil {
+		t.Errorf("AddDagTip: %v", err)
+	}
+	if msg.Tips[0] != tip {
+		t.Errorf("AddDagTip: wrong tip added - got %v, want %v",
+			spew.Sprint(msg.Tips[0]), spew.Sprint(tip))
+	}
+
+	// Ensure adding more than the max allowed dag tips per message
+	// returns an error.
+	for i := 0; i < MaxDagTipsPerMsg; i++ {
+		err = msg.AddDagTip(tip)
+	}
+	if err == nil {
+		t.Errorf("AddDagTip: expected error on too many dag tips " +
+			"not received")
+	}
+}
+
+// TestDagTipsWire tests the MsgDagTips wire encode and decode for various
+// numbers of dag tips and protocol versions.
+func TestDagTipsWire(t *testing.T) {
+	// Block 203707 hash.
+	hashStr := "3264bc2ac36a60840790ba1d475d01367e7c723da941069e9dc"
+	blockHash, err := chainhash.NewHashFromStr(hashStr)
+	if err != nil {
+		t.Errorf("NewHashFromStr: %v", err)
+	}
+
+	// Empty dagtips message.
+	noTips := NewMsgDagTips()
+	noTipsEncoded := []byte{
+		0x00, // Varint for number of dag tips
+	}
+
+	// Dagtips message with multiple tips.
+	multiTips := NewMsgDagTips()
+	multiTips.AddDagTip(NewDagTip(blockHash, 203707))
+	multiTips.AddDagTip(NewDagTip(&chainhash.Hash{}, 1))
+	multiTipsEncoded := []byte{
+		0x02, // Varint for number of dag tips
+		0xdc, 0xe9, 0x69, 0x10, 0x94, 0xda, 0x23, 0xc7,
+		0xe7, 0x67, 0x13, 0xd0, 0x75, 0xd4, 0xa1, 0x0b,
+		0x79, 0x40, 0x08, 0xa6, 0x36, 0xac, 0xc2, 0x4b,
+		0x26, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Block 203707 hash
+		0xbb, 0x1b, 0x03, 0x00, // Block 203707 height
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Zero hash
+		0x01, 0x00, 0x00, 0x00, // Height 1
+	}
+
+	tests := []struct {
+		in   *MsgDagTips     // Message to encode
+		out  *MsgDagTips     // Expected decoded message
+		buf  []byte          // Wire encoding
+		pver uint32          // Protocol version for wire encoding
+		enc  MessageEncoding // Message encoding format
+	}{
+		// Latest protocol version with no tips.
+		{
+			noTips,
+			noTips,
+			noTipsEncoded,
+			ProtocolVersion,
+			BaseEncoding,
+		},
+
+		// Latest protocol version with multiple tips.
+		{
+			multiTips,
+			multiTips,
+			multiTipsEncoded,
+			ProtocolVersion,
+			BaseEncoding,
+		},
+
+		// Protocol version BIP0035Version with multiple tips.
+		{
+			multiTips,
+			multiTips,
+			multiTipsEncoded,
+			BIP0035Version,
+			BaseEncoding,
+		},
+
+		// Protocol version NetAddressTimeVersion with multiple tips.
+		{
+			multiTips,
+			multiTips,
+			multiTipsEncoded,
+			NetAddressTimeVersion,
+			BaseEncoding,
+		},
+
+		// Protocol version MultipleAddressVersion with multiple tips.
+		{
+			multiTips,
+			multiTips,
+			multiTipsEncoded,
+			MultipleAddressVersion,
+			BaseEncoding,
+		},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.SotoEncode(&buf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("SotoEncode #%d\n got: %s want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+
+		// Decode the message from wire format.
+		var msg MsgDagTips
+		rbuf := bytes.NewReader(test.buf)
+		err = msg.SotoDecode(rbuf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.out) {
+			t.Errorf("SotoDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.out))
+			continue
+		}
+	}
+}
+
+// TestDagTipsWireErrors performs negative tests against wire encode and decode
+// of MsgDagTips to confirm error paths work correctly.
+func TestDagTipsWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+	wireErr := &MessageError{}
+
+	// Block 203707 hash.
+	hashStr := "3264bc2ac36a60840790ba1d475d01367e7c723da941069e9dc"
+	blockHash, err := chainhash.NewHashFromStr(hashStr)
+	if err != nil {
+		t.Errorf("NewHashFromStr: %v", err)
+	}
+
+	tip := NewDagTip(blockHash, 203707)
+
+	// Base dagtips message used to induce errors.
+	baseTips := NewMsgDagTips()
+	baseTips.AddDagTip(tip)
+	baseTipsEncoded := []byte{
+		0x01, // Varint for number of dag tips
+		0xdc, 0xe9, 0x69, 0x10, 0x94, 0xda, 0x23, 0xc7,
+		0xe7, 0x67, 0x13, 0xd0, 0x75, 0xd4, 0xa1, 0x0b,
+		0x79, 0x40, 0x08, 0xa6, 0x36, 0xac, 0xc2, 0x4b,
+		0x26, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Block 203707 hash
+		0xbb, 0x1b, 0x03, 0x00, // Block 203707 height
+	}
+
+	// Dagtips message that forces an error by having more than the max
+	// allowed dag tips.
+	maxTips := NewMsgDagTips()
+	for i := 0; i < MaxDagTipsPerMsg; i++ {
+		maxTips.AddDagTip(tip)
+	}
+	maxTips.Tips = append(maxTips.Tips, tip)
+	maxTipsEncoded := []byte{
+		0xfd, 0xe9, 0x03, // Varint for number of dag tips (1001)
+	}
+
+	tests := []struct {
+		in       *MsgDagTips     // Value to encode
+		buf      []byte          // Wire encoding
+		pver     uint32          // Protocol version for wire encoding
+		enc      MessageEncoding // Message encoding format
+		max      int             // Max size of fixed buffer to induce errors
+		writeErr error           // Expected write error
+		readErr  error           // Expected read error
+	}{
+		// Latest protocol version with intentional read/write errors.
+		// Force error in dag tip count.
+		{baseTips, baseTipsEncoded, pver, BaseEncoding, 0, io.ErrShortWrite, io.EOF},
+		// Force error in dag tip hash.
+		{baseTips, baseTipsEncoded, pver, BaseEncoding, 1, io.ErrShortWrite, io.EOF},
+		// Force error in dag tip height.
+		{baseTips, baseTipsEncoded, pver, BaseEncoding, 33, io.ErrShortWrite, io.EOF},
+		// Force error with greater than max dag tips.
+		{maxTips, maxTipsEncoded, pver, BaseEncoding, 3, wireErr, wireErr},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.SotoEncode(w, test.pver, test.enc)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.writeErr) {
+			t.Errorf("SotoEncode #%d wrong error got: %v, want: %v",
+				i, err, test.writeErr)
+			continue
+		}
+
+		// For errors which are not of type MessageError, check them for
+		// equality.
+		if _, ok := err.(*MessageError); !ok {
+			if err != test.writeErr {
+				t.Errorf("SotoEncode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.writeErr)
+				continue
+			}
+		}
+
+		// Decode from wire format.
+		var msg MsgDagTips
+		r := newFixedReader(test.max, test.buf)
+		err = msg.SotoDecode(r, test.pver, test.enc)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.readErr) {
+			t.Errorf("SotoDecode #%d wrong error got: %v, want: %v",
+				i, err, test.readErr)
+			continue
+		}
+
+		// For errors which are not of type MessageError, check them for
+		// equality.
+		if _, ok := err.(*MessageError); !ok {
+			if err != test.readErr {
+				t.Errorf("SotoDecode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.readErr)
+				continue
+			}
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+)
+
+// readDiffIndex reads a differentially encoded transaction index from r.  The
+// index is encoded as the difference from next, which is the index following
+// the previously read index, and is updated to follow the returned index.
+// Indexes at or above max are rejected.
+func readDiffIndex(r io.Reader, pver uint32, next *uint64, max uint64, field string) (uint32, error) {
+	diff, err := ReadVarInt(r, pver)
+	if err != nil {
+		return 0, err
+	}
+
+	index := *next + diff
+	if diff >= max || index >= max {
+		str := fmt.Sprintf("%s is out of range [index %v, max %v]",
+			field, index, max)
+		return 0, messageError("readDiffIndex", str)
+	}
+	*next = index + 1
+
+	return uint32(index), nil
+}
+
+// writeDiffIndex writes a transaction index to w, differentially encoded
+// against next, which is the index following the previously written index.
+// next is updated to follow the written index.  Indexes must be written in
+// increasing order.
+func writeDiffIndex(w io.Writer, pver uint32, index uint32, next *uint64, field string) error {
+	if uint64(index) < *next {
+		str := fmt.Sprintf("%s %v isn't greater than the previous "+
+			"index", field, index)
+		return messageError("writeDiffIndex", str)
+	}
+
+	err := WriteVarInt(w, pver, uint64(index)-*next)
+	if err != nil {
+		return err
+	}
+	*next = uint64(index) + 1
+
+	return nil
+}
+
+// MsgGetBlockTxns implements the Message interface and represents a soter
+// getblocktxn message.  It is used to request the transactions of a block
+// which couldn't be reconstructed from a cmpctdagblk message
+// (MsgCmpctDagBlock), by their index in the block.  The peer replies with a
+// blocktxn message (MsgBlockTxns).
+//
+// The indexes must be in increasing order, since they're differentially
+// encoded on the wire.
+//
+// This message was not added until protocol versions starting with
+// CmpctDagBlockVersion.
+type MsgGetBlockTxns struct {
+	BlockHash chainhash.Hash
+	Indexes   []uint32
+}
+
+// AddIndex adds a new transaction index to the message.
+func (msg *MsgGetBlockTxns) AddIndex(index uint32) error {
+	if len(msg.Indexes)+1 > maxTxPerBlock {
+		str := fmt.Sprintf("too many transaction indexes for message "+
+			"[max %v]", maxTxPerBlock)
+		return messageError("MsgGetBlockTxns.AddIndex", str)
+	}
+
+	msg.Indexes = append(msg.Indexes, index)
+	return nil
+}
+
+// SotoDecode decodes r using the soter protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgGetBlockTxns) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < CmpctDagBlockVersion {
+		str := fmt.Sprintf("getblocktxn message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgGetBlockTxns.SotoDecode", str)
+	}
+
+	err := readElement(r, &msg.BlockHash)
+	if err != nil {
+		return err
+	}
+
+	// Read num transaction indexes and limit to max.
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count > maxTxPerBlock {
+		str := fmt.Sprintf("too many transaction indexes for message "+
+			"[count %v, max %v]", count, maxTxPerBlock)
+		return messageError("MsgGetBlockTxns.SotoDecode", str)
+	}
+
+	msg.Indexes = make([]uint32, 0, count)
+	var next uint64
+	for i := uint64(0); i < count; i++ {
+		index, err := readDiffIndex(r, pver, &next, maxTxPerBlock,
+			"transaction index")
+		if err != nil {
+			return err
+		}
+		msg.Indexes = append(msg.Indexes, index)
+	}
+
+	return nil
+}
+
+// SotoEncode encodes the receiver to w using the soter protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgGetBlockTxns) SotoEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < CmpctDagBlockVersion {
+		str := fmt.Sprintf("getblocktxn message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgGetBlockTxns.SotoEncode", str)
+	}
+
+	// Limit to max transactions per block.
+	count := len(msg.Indexes)
+	if count > maxTxPerBlock {
+		str := fmt.Sprintf("too many transaction indexes for message "+
+			"[count %v, max %v]", count, maxTxPerBlock)
+		return messageError("MsgGetBlockTxns.SotoEncode", str)
+	}
+
+	err := writeElement(w, &msg.BlockHash)
+	if err != nil {
+		return err
+	}
+
+	err = WriteVarInt(w, pver, uint64(count))
+	if err != nil {
+		return err
+	}
+
+	var next uint64
+	for _, index := range msg.Indexes {
+		err = writeDiffIndex(w, pver, index, &next, "transaction index")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgGetBlockTxns) Command() string {
+	return CmdGetBlockTxns
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgGetBlockTxns) MaxPayloadLength(pver uint32) uint32 {
+	// Block hash + num indexes (varInt) + max allowed indexes (varInt
+	// each).
+	return chainhash.HashSize + MaxVarIntPayload +
+		(maxTxPerBlock * MaxVarIntPayload)
+}
+
+// NewMsgGetBlockTxns returns a new soter getblocktxn message that conforms to
+// the Message interface, requesting the transactions at the indexes of the
+// block with the hash.  See MsgGetBlockTxns for details.
+func NewMsgGetBlockTxns(hash *chainhash.Hash, indexes []uint32) *MsgGetBlockTxns {
+	return &MsgGetBlockTxns{
+		BlockHash: *hash,
+		Indexes:   indexes,
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestGetBlockTxns tests the MsgGetBlockTxns API.
+func TestGetBlockTxns(t *testing.T) {
+	pver := ProtocolVersion
+
+	// Ensure the command is expected value.
+	wantCmd := "getblocktxn"
+	hash := mainNetGenesisHash
+	msg := NewMsgGetBlockTxns(&hash, nil)
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgGetBlockTxns: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value for latest protocol version.
+	// Block hash + num indexes (varInt) + max allowed indexes.
+	wantPayload := uint32(3600050)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Ensure indexes are added properly.
+	msg.AddIndex(3)
+	if !reflect.DeepEqual(msg.Indexes, []uint32{3}) {
+		t.Errorf("AddIndex: wrong indexes - got %v, want %v",
+			msg.Indexes, []uint32{3})
+	}
+
+	// Ensure adding more than the max allowed indexes per message returns
+	// error.
+	var err error
+	for i := 0; i < maxTxPerBlock+1; i++ {
+		err = msg.AddIndex(uint32(i))
+	}
+	if reflect.TypeOf(err) != reflect.TypeOf(&MessageError{}) {
+		t.Errorf("AddIndex: expected error on too many indexes " +
+			"not received")
+	}
+}
+
+// TestGetBlockTxnsWire tests the MsgGetBlockTxns wire encode and decode for
+// various numbers of indexes and protocol versions.
+func TestGetBlockTxnsWire(t *testing.T) {
+	hash := mainNetGenesisHash
+	hashEncoded := []byte{
+		0x6f, 0xe2, 0x8c, 0x0a, 0xb6, 0xf1, 0xb3, 0x72,
+		0xc1, 0xa6, 0xa2, 0x46, 0xae, 0x63, 0xf7, 0x4f,
+		0x93, 0x1e, 0x83, 0x65, 0xe1, 0x5a, 0x08, 0x9c,
+		0x68, 0xd6, 0x19, 0x00, 0x00, 0x00, 0x00, 0x00, // Block hash
+	}
+
+	// Message without indexes.
+	noIndexes := NewMsgGetBlockTxns(&hash, []uint32{})
+	noIndexesEncoded := append(append([]byte{}, hashEncoded...),
+		0x00, // Varint for number of indexes
+	)
+
+	// Message with indexes, which are differentially encoded.
+	indexes := NewMsgGetBlockTxns(&hash, []uint32{1, 2, 5, 300})
+	indexesEncoded := append(append([]byte{}, hashEncoded...),
+		0x04,             // Varint for number of indexes
+		0x01,             // Index 1
+		0x00,             // Index 2
+		0x02,             // Index 5
+		0xfd, 0x26, 0x01, // Index 300
+	)
+
+	tests := []struct {
+		in   *MsgGetBlockTxns // Message to encode
+		out  *MsgGetBlockTxns // Expected decoded message
+		buf  []byte           // Wire encoding
+		pver uint32           // Protocol version for wire encoding
+		enc  MessageEncoding  // Message encoding format
+	}{
+		// Latest protocol version with no indexes.
+		{noIndexes, noIndexes, noIndexesEncoded, ProtocolVersion, BaseEncoding},
+
+		// Latest protocol version with multiple indexes.
+		{indexes, indexes, indexesEncoded, ProtocolVersion, BaseEncoding},
+
+		// Protocol version CmpctDagBlockVersion with multiple indexes.
+		{indexes, indexes, indexesEncoded, CmpctDagBlockVersion, BaseEncoding},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.SotoEncode(&buf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("SotoEncode #%d\n got: %s want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+
+		// Decode the message from wire format.
+		var msg MsgGetBlockTxns
+		rbuf := bytes.NewReader(test.buf)
+		err = msg.SotoDecode(rbuf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.out) {
+			t.Errorf("SotoDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.out))
+			continue
+		}
+	}
+}
+
+// TestGetBlockTxnsWireErrors performs negative tests against wire encode and
+// decode of MsgGetBlockTxns to confirm error paths work correctly.
+func TestGetBlockTxnsWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+	wireErr := &MessageError{}
+
+	hash := mainNetGenesisHash
+	msg := NewMsgGetBlockTxns(&hash, []uint32{1, 2})
+	msgEncoded := []byte{
+		0x6f, 0xe2, 0x8c, 0x0a, 0xb6, 0xf1, 0xb3, 0x72,
+		0xc1, 0xa6, 0xa2, 0x46, 0xae, 0x63, 0xf7, 0x4f,
+		0x93, 0x1e, 0x83, 0x65, 0xe1, 0x5a, 0x08, 0x9c,
+		0x68, 0xd6, 0x19, 0x00, 0x00, 0x00, 0x00, 0x00, // Block hash
+		0x02, // Varint for number of indexes
+		0x01, // Index 1
+		0x00, // Index 2
+	}
+
+	// Message that forces an error by having indexes out of order.
+	unordered := NewMsgGetBlockTxns(&hash, []uint32{2, 1})
+
+	// Message that forces an error by having more than the max allowed
+	// indexes.
+	maxIndexes := NewMsgGetBlockTxns(&hash, make([]uint32, maxTxPerBlock+1))
+	maxIndexesEncoded := append(append([]byte{}, msgEncoded[:32]...),
+		0xfe, 0x82, 0x1a, 0x06, 0x00, // Varint for number of indexes (400002)
+	)
+
+	// Message that forces an error by having an index past the max
+	// transactions in a block.
+	rangeEncoded := append(append([]byte{}, msgEncoded[:32]...),
+		0x01,                         // Varint for number of indexes
+		0xfe, 0x82, 0x1a, 0x06, 0x00, // Index 400002
+	)
+
+	tests := []struct {
+		in       *MsgGetBlockTxns // Value to encode
+		buf      []byte           // Wire encoding
+		pver     uint32           // Protocol version for wire encoding
+		enc      MessageEncoding  // Message encoding format
+		max      int              // Max size of fixed buffer to induce errors
+		writeErr error            // Expected write error
+		readErr  error            // Expected read error
+	}{
+		// Force error in block hash.
+		{msg, msgEncoded, pver, BaseEncoding, 0, io.ErrShortWrite, io.EOF},
+		// Force error in index count.
+		{msg, msgEncoded, pver, BaseEncoding, 32, io.ErrShortWrite, io.EOF},
+		// Force error in index.
+		{msg, msgEncoded, pver, BaseEncoding, 33, io.ErrShortWrite, io.EOF},
+		// Force error with out of order indexes.
+		{unordered, msgEncoded, pver, BaseEncoding, len(msgEncoded), wireErr, nil},
+		// Force error with greater than max indexes.
+		{maxIndexes, maxIndexesEncoded, pver, BaseEncoding, len(maxIndexesEncoded), wireErr, wireErr},
+		// Force error with an index out of range.
+		{msg, rangeEncoded, pver, BaseEncoding, len(rangeEncoded), nil, wireErr},
+		// Force error with a protocol version prior to
+		// CmpctDagBlockVersion.
+		{msg, msgEncoded, CmpctDagBlockVersion - 1, BaseEncoding, len(msgEncoded), wireErr, wireErr},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.SotoEncode(w, test.pver, test.enc)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.writeErr) {
+			t.Errorf("SotoEncode #%d wrong error got: %v, want: %v",
+				i, err, test.writeErr)
+			continue
+		}
+
+		// Decode from wire format.
+		var msg MsgGetBlockTxns
+		r := newFixedReader(test.max, test.buf)
+		err = msg.SotoDecode(r, test.pver, test.enc)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.readErr) {
+			t.Errorf("SotoDecode #%d wrong error got: %v, want: %v",
+				i, err, test.readErr)
+			continue
+		}
+	}
+}
@@ -17,7 +17,9 @@ const MaxGetCFiltersReqRange = 1000
 
 // MsgGetCFilters implements the Message interface and represents a soter
 // getcfilters message. It is used to request committed filters for a range of
-// blocks.
+// blocks.  Since a dag can have several blocks at a height, the range is every
+// block from StartHeight up to the height of the StopHash block, and a filter
+// is returned for each of them, keyed by its block hash.
 type MsgGetCFilters struct {
 	FilterType  FilterType
 	StartHeight uint32
@@ -0,0 +1,65 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+)
+
+// TestGetCFiltersWire tests the MsgGetCFilters wire encode and decode.
+func TestGetCFiltersWire(t *testing.T) {
+	stopHash := chainhash.Hash{0x01, 0x02, 0x03}
+	msg := NewMsgGetCFilters(GCSFilterRegular, 0x0a0b0c0d, &stopHash)
+	if cmd := msg.Command(); cmd != CmdGetCFilters {
+		t.Errorf("NewMsgGetCFilters: wrong command - got %v want %v",
+			cmd, CmdGetCFilters)
+	}
+
+	encoded := append([]byte{
+		0x00,                   // Filter type
+		0x0d, 0x0c, 0x0b, 0x0a, // Start height
+	}, stopHash[:]...) // Stop hash
+
+	var buf bytes.Buffer
+	err := msg.SotoEncode(&buf, ProtocolVersion, BaseEncoding)
+	if err != nil {
+		t.Fatalf("SotoEncode error %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), encoded) {
+		t.Fatalf("SotoEncode\n got: %s want: %s",
+			spew.Sdump(buf.Bytes()), spew.Sdump(encoded))
+	}
+	if uint32(buf.Len()) != msg.MaxPayloadLength(ProtocolVersion) {
+		t.Errorf("encoded %d bytes, but max payload is %d", buf.Len(),
+			msg.MaxPayloadLength(ProtocolVersion))
+	}
+
+	var readMsg MsgGetCFilters
+	err = readMsg.SotoDecode(bytes.NewReader(encoded), ProtocolVersion,
+		BaseEncoding)
+	if err != nil {
+		t.Fatalf("SotoDecode error %v", err)
+	}
+	if !reflect.DeepEqual(&readMsg, msg) {
+		t.Errorf("SotoDecode\n got: %s want: %s", spew.Sdump(readMsg),
+			spew.Sdump(msg))
+	}
+
+	// Truncated messages can't be decoded.
+	for _, size := range []int{0, 1, 5, len(encoded) - 1} {
+		err = readMsg.SotoDecode(bytes.NewReader(encoded[:size]),
+			ProtocolVersion, BaseEncoding)
+		if err != io.EOF && err != io.ErrUnexpectedEOF {
+			t.Errorf("SotoDecode of %d bytes: got error %v, want EOF",
+				size, err)
+		}
+	}
+}
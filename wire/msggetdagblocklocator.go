@@ -0,0 +1,135 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+)
+
+// MsgGetDagBlockLocator implements the Message interface and represents a
+// soter getdagblkloc message.  It is used to ask a peer for the blocks that the
+// requesting node is missing.  The response is a dagblkloc message
+// (MsgDagBlockLocator).
+//
+// The message carries the requesting node's dag block locator: a sparse set of
+// anchors spanning its known tips down to the genesis block.  It is ordered
+// from the tips down, with one anchor per height, so the height of each anchor
+// is strictly less than the height of the anchor before it.  The serving peer
+// uses the anchors to compute the frontier of blocks the requesting node is
+// missing.  Each message is limited to a maximum number of anchors, which is
+// currently MaxDagLocatorAnchorsPerMsg.
+//
+// Use the AddAnchor function to build up the list of anchors when sending a
+// getdagblkloc message to another peer.
+type MsgGetDagBlockLocator struct {
+	Anchors []*DagLocatorAnchor
+}
+
+// AddAnchor adds an anchor to the message.  The anchor's height must be less
+// than the height of the last anchor in the message.
+func (msg *MsgGetDagBlockLocator) AddAnchor(anchor *DagLocatorAnchor) error {
+	if len(msg.Anchors)+1 > MaxDagLocatorAnchorsPerMsg {
+		str := fmt.Sprintf("too many dag locator anchors in message "+
+			"[max %v]", MaxDagLocatorAnchorsPerMsg)
+		return messageError("MsgGetDagBlockLocator.AddAnchor", str)
+	}
+
+	if len(msg.Anchors) > 0 {
+		last := msg.Anchors[len(msg.Anchors)-1]
+		if anchor.Height >= last.Height {
+			str := fmt.Sprintf("dag locator anchor height %v is "+
+				"not less than previous anchor height %v",
+				anchor.Height, last.Height)
+			return messageError("MsgGetDagBlockLocator.AddAnchor", str)
+		}
+	}
+
+	msg.Anchors = append(msg.Anchors, anchor)
+	return nil
+}
+
+// SotoDecode decodes r using the soter protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgGetDagBlockLocator) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+
+	// Limit to max dag locator anchors per message.
+	if count > MaxDagLocatorAnchorsPerMsg {
+		str := fmt.Sprintf("too many dag locator anchors for message "+
+			"[count %v, max %v]", count, MaxDagLocatorAnchorsPerMsg)
+		return messageError("MsgGetDagBlockLocator.SotoDecode", str)
+	}
+
+	// Create a contiguous slice of anchors to deserialize into in order to
+	// reduce the number of allocations.
+	anchors := make([]DagLocatorAnchor, count)
+	msg.Anchors = make([]*DagLocatorAnchor, 0, count)
+	for i := uint64(0); i < count; i++ {
+		anchor := &anchors[i]
+		err := readDagLocatorAnchor(r, pver, anchor)
+		if err != nil {
+			return err
+		}
+
+		err = msg.AddAnchor(anchor)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SotoEncode encodes the receiver to w using the soter protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgGetDagBlockLocator) SotoEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	// Limit to max dag locator anchors per message.
+	count := len(msg.Anchors)
+	if count > MaxDagLocatorAnchorsPerMsg {
+		str := fmt.Sprintf("too many dag locator anchors for message "+
+			"[count %v, max %v]", count, MaxDagLocatorAnchorsPerMsg)
+		return messageError("MsgGetDagBlockLocator.SotoEncode", str)
+	}
+
+	err := WriteVarInt(w, pver, uint64(count))
+	if err != nil {
+		return err
+	}
+
+	for _, anchor := range msg.Anchors {
+		err := writeDagLocatorAnchor(w, pver, anchor)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgGetDagBlockLocator) Command() string {
+	return CmdGetDagBlockLocator
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgGetDagBlockLocator) MaxPayloadLength(pver uint32) uint32 {
+	// Num anchors (varInt) + max allowed anchors.
+	return maxCountedPayload(MaxDagLocatorAnchorsPerMsg,
+		maxDagLocatorAnchorPayload)
+}
+
+// NewMsgGetDagBlockLocator returns a new soter getdagblkloc message that
+// conforms to the Message interface.  See MsgGetDagBlockLocator for details.
+func NewMsgGetDagBlockLocator() *MsgGetDagBlockLocator {
+	return &MsgGetDagBlockLocator{
+		Anchors: make([]*DagLocatorAnchor, 0),
+	}
+}
@@ -0,0 +1,403 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+)
+
+// TestGetDagBlockLocator tests the MsgGetDagBlockLocator API.
+func TestGetDagBlockLocator(t *testing.T) {
+	pver := ProtocolVersion
+
+	// Ensure the command is expected value.
+	wantCmd := "getdagblkloc"
+	msg := NewMsgGetDagBlockLocator()
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgGetDagBlockLocator: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value for latest protocol version.
+	// Num anchors (varInt) + max allowed anchors.
+	wantPayload := uint32(18009)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Ensure dag locator anchors are added properly.
+	hash := chainhash.Hash{}
+	anchor := NewDagLocatorAnchor(&hash, MaxDagLocatorAnchorsPerMsg)
+	err := msg.AddAnchor(anchor)
+	if err != nil {
+		t.Errorf("AddAnchor: %v", err)
+	}
+	if msg.Anchors[0] != anchor {
+		t.Errorf("AddAnchor: wrong anchor added - got %v, want %v",
+			spew.Sprint(msg.Anchors[0]), spew.Sprint(anchor))
+	}
+
+	// Ensure adding an anchor at the same height as the previous anchor
+	// returns an error.
+	err = msg.AddAnchor(NewDagLocatorAnchor(&hash, anchor.Height))
+	if err == nil {
+		t.Errorf("AddAnchor: expected error on repeated anchor " +
+			"height not received")
+	}
+
+	// Ensure adding an anchor higher than the previous anchor returns an
+	// error.
+	err = msg.AddAnchor(NewDagLocatorAnchor(&hash, anchor.Height+1))
+	if err == nil {
+		t.Errorf("AddAnchor: expected error on increasing anchor " +
+			"height not received")
+	}
+
+	// Ensure adding more than the max allowed dag locator anchors per
+	// message returns an error.
+	for i := int32(1); i <= MaxDagLocatorAnchorsPerMsg; i++ {
+		err = msg.AddAnchor(NewDagLocatorAnchor(&hash, anchor.Height-i))
+	}
+	if err == nil {
+		t.Errorf("AddAnchor: expected error on too many dag locator " +
+			"anchors not received")
+	}
+}
+
+// TestGetDagBlockLocatorWire tests the MsgGetDagBlockLocator wire encode and
+// decode for various numbers of dag locator anchors and protocol versions.
+func TestGetDagBlockLocatorWire(t *testing.T) {
+	// Block 203707 hash.
+	hashStr := "3264bc2ac36a60840790ba1d475d01367e7c723da941069e9dc"
+	blockHash, err := chainhash.NewHashFromStr(hashStr)
+	if err != nil {
+		t.Errorf("NewHashFromStr: %v", err)
+	}
+
+	// Empty getdagblkloc message.
+	noAnchors := NewMsgGetDagBlockLocator()
+	noAnchorsEncoded := []byte{
+		0x00, // Varint for number of dag locator anchors
+	}
+
+	// Getdagblkloc message with multiple anchors.
+	multiAnchors := NewMsgGetDagBlockLocator()
+	multiAnchors.AddAnchor(NewDagLocatorAnchor(blockHash, 203707))
+	multiAnchors.AddAnchor(NewDagLocatorAnchor(&chainhash.Hash{}, 1))
+	multiAnchorsEncoded := []byte{
+		0x02, // Varint for number of dag locator anchors
+		0xdc, 0xe9, 0x69, 0x10, 0x94, 0xda, 0x23, 0xc7,
+		0xe7, 0x67, 0x13, 0xd0, 0x75, 0xd4, 0xa1, 0x0b,
+		0x79, 0x40, 0x08, 0xa6, 0x36, 0xac, 0xc2, 0x4b,
+		0x26, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Block 203707 hash
+		0xbb, 0x1b, 0x03, 0x00, // Block 203707 height
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Zero hash
+		0x01, 0x00, 0x00, 0x00, // Height 1
+	}
+
+	tests := []struct {
+		in   *MsgGetDagBlockLocator // Message to encode
+		out  *MsgGetDagBlockLocator // Expected decoded message
+		buf  []byte                 // Wire encoding
+		pver uint32                 // Protocol version for wire encoding
+		enc  MessageEncoding        // Message encoding format
+	}{
+		// Latest protocol version with no anchors.
+		{
+			noAnchors,
+			noAnchors,
+			noAnchorsEncoded,
+			ProtocolVersion,
+			BaseEncoding,
+		},
+
+		// Latest protocol version with multiple anchors.
+		{
+			multiAnchors,
+			multiAnchors,
+			multiAnchorsEncoded,
+			ProtocolVersion,
+			BaseEncoding,
+		},
+
+		// Protocol version BIP0035Version with multiple anchors.
+		{
+			multiAnchors,
+			multiAnchors,
+			multiAnchorsEncoded,
+			BIP0035Version,
+			BaseEncoding,
+		},
+
+		// Protocol version MultipleAddressVersion with multiple anchors.
+		{
+			multiAnchors,
+			multiAnchors,
+			multiAnchorsEncoded,
+			MultipleAddressVersion,
+			BaseEncoding,
+		},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.SotoEncode(&buf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("SotoEncode #%d\n got: %s want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+
+		// Decode the message from wire format.
+		var msg MsgGetDagBlockLocator
+		rbuf := bytes.NewReader(test.buf)
+		err = msg.SotoDecode(rbuf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.out) {
+			t.Errorf("SotoDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.out))
+			continue
+		}
+	}
+}
+
+// TestGetDagBlockLocatorWireErrors performs negative tests against wire encode
+// and decode of MsgGetDagBlockLocator to confirm error paths work correctly.
+func TestGetDagBlockLocatorWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+	wireErr := &MessageError{}
+
+	// Block 203707 hash.
+	hashStr := "3264bc2ac36a60840790ba1d475d01367e7c723da941069e9dc"
+	blockHash, err := chainhash.NewHashFromStr(hashStr)
+	if err != nil {
+		t.Errorf("NewHashFromStr: %v", err)
+	}
+
+	anchor := NewDagLocatorAnchor(blockHash, 203707)
+
+	// Base getdagblkloc message used to induce errors.
+	baseAnchors := NewMsgGetDagBlockLocator()
+	baseAnchors.AddAnchor(anchor)
+	baseAnchorsEncoded := []byte{
+		0x01, // Varint for number of dag locator anchors
+		0xdc, 0xe9, 0x69, 0x10, 0x94, 0xda, 0x23, 0xc7,
+		0xe7, 0x67, 0x13, 0xd0, 0x75, 0xd4, 0xa1, 0x0b,
+		0x79, 0x40, 0x08, 0xa6, 0x36, 0xac, 0xc2, 0x4b,
+		0x26, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Block 203707 hash
+		0xbb, 0x1b, 0x03, 0x00, // Block 203707 height
+	}
+
+	// Getdagblkloc message that forces an error by having more than the
+	// max allowed dag locator anchors.
+	maxAnchors := NewMsgGetDagBlockLocator()
+	for i := 0; i <= MaxDagLocatorAnchorsPerMsg; i++ {
+		maxAnchors.Anchors = append(maxAnchors.Anchors, anchor)
+	}
+	maxAnchorsEncoded := []byte{
+		0xfd, 0xf5, 0x01, // Varint for number of dag locator anchors (501)
+	}
+
+	// Getdagblkloc message that forces a decode error by having two
+	// anchors at the same height.
+	repeated := NewMsgGetDagBlockLocator()
+	repeated.Anchors = append(repeated.Anchors, anchor,
+		NewDagLocatorAnchor(&chainhash.Hash{}, anchor.Height))
+	repeatedEncoded := []byte{
+		0x02, // Varint for number of dag locator anchors
+		0xdc, 0xe9, 0x69, 0x10, 0x94, 0xda, 0x23, 0xc7,
+		0xe7, 0x67, 0x13, 0xd0, 0x75, 0xd4, 0xa1, 0x0b,
+		0x79, 0x40, 0x08, 0xa6, 0x36, 0xac, 0xc2, 0x4b,
+		0x26, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Block 203707 hash
+		0xbb, 0x1b, 0x03, 0x00, // Block 203707 height
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Zero hash
+		0xbb, 0x1b, 0x03, 0x00, // Block 203707 height
+	}
+
+	// Getdagblkloc message that forces a decode error by having an anchor
+	// higher than the anchor before it.
+	misordered := NewMsgGetDagBlockLocator()
+	misordered.Anchors = append(misordered.Anchors,
+		NewDagLocatorAnchor(&chainhash.Hash{}, 1), anchor)
+	misorderedEncoded := []byte{
+		0x02, // Varint for number of dag locator anchors
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Zero hash
+		0x01, 0x00, 0x00, 0x00, // Height 1
+		0xdc, 0xe9, 0x69, 0x10, 0x94, 0xda, 0x23, 0xc7,
+		0xe7, 0x67, 0x13, 0xd0, 0x75, 0xd4, 0xa1, 0x0b,
+		0x79, 0x40, 0x08, 0xa6, 0x36, 0xac, 0xc2, 0x4b,
+		0x26, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Block 203707 hash
+		0xbb, 0x1b, 0x03, 0x00, // Block 203707 height
+	}
+
+	tests := []struct {
+		in       *MsgGetDagBlockLocator // Value to encode
+		buf      []byte                 // Wire encoding
+		pver     uint32                 // Protocol version for wire encoding
+		enc      MessageEncoding        // Message encoding format
+		max      int                    // Max size of fixed buffer to induce errors
+		writeErr error                  // Expected write error
+		readErr  error                  // Expected read error
+	}{
+		// Latest protocol version with intentional read/write errors.
+		// Force error in dag locator anchor count.
+		{baseAnchors, baseAnchorsEncoded, pver, BaseEncoding, 0, io.ErrShortWrite, io.EOF},
+		// Force error in dag locator anchor hash.
+		{baseAnchors, baseAnchorsEncoded, pver, BaseEncoding, 1, io.ErrShortWrite, io.EOF},
+		// Force error in dag locator anchor height.
+		{baseAnchors, baseAnchorsEncoded, pver, BaseEncoding, 33, io.ErrShortWrite, io.EOF},
+		// Force error with greater than max dag locator anchors.
+		{maxAnchors, maxAnchorsEncoded, pver, BaseEncoding, 3, wireErr, wireErr},
+		// Force decode error with two anchors at the same height.
+		{repeated, repeatedEncoded, pver, BaseEncoding, 73, nil, wireErr},
+		// Force decode error with an anchor higher than the previous one.
+		{misordered, misorderedEncoded, pver, BaseEncoding, 73, nil, wireErr},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.SotoEncode(w, test.pver, test.enc)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.writeErr) {
+			t.Errorf("SotoEncode #%d wrong error got: %v, want: %v",
+				i, err, test.writeErr)
+			continue
+		}
+
+		// For errors which are not of type MessageError, check them for
+		// equality.
+		if _, ok := err.(*MessageError); !ok {
+			if err != test.writeErr {
+				t.Errorf("SotoEncode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.writeErr)
+				continue
+			}
+		}
+
+		// Decode from wire format.
+		var msg MsgGetDagBlockLocator
+		r := newFixedReader(test.max, test.buf)
+		err = msg.SotoDecode(r, test.pver, test.enc)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.readErr) {
+			t.Errorf("SotoDecode #%d wrong error got: %v, want: %v",
+				i, err, test.readErr)
+			continue
+		}
+
+		// For errors which are not of type MessageError, check them for
+		// equality.
+		if _, ok := err.(*MessageError); !ok {
+			if err != test.readErr {
+				t.Errorf("SotoDecode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.readErr)
+				continue
+			}
+		}
+	}
+}
+
+// TestGetDagBlockLocatorDeepDag ensures that the anchors of a requester's dag
+// block locator built from a deep dag round-trip through a getdagblkloc
+// message, with strictly decreasing heights.
+func TestGetDagBlockLocatorDeepDag(t *testing.T) {
+	// Build a dag with a varying number of blocks at each height.
+	depth := 100000
+	dag := make([][]chainhash.Hash, depth)
+	for height := range dag {
+		for i := 0; i < height%3+1; i++ {
+			hash := chainhash.DoubleHashH([]byte{byte(height),
+				byte(height >> 8), byte(height >> 16), byte(i)})
+			dag[height] = append(dag[height], hash)
+		}
+	}
+
+	// The locator carries a single anchor per height, so only one of the
+	// tips can be used.
+	locator, err := buildTestDagLocator(dag)
+	if err != nil {
+		t.Fatalf("buildTestDagLocator: %v", err)
+	}
+	msg := NewMsgGetDagBlockLocator()
+	for _, anchor := range locator.Anchors {
+		last := len(msg.Anchors) - 1
+		if last >= 0 && msg.Anchors[last].Height == anchor.Height {
+			continue
+		}
+		err := msg.AddAnchor(anchor)
+		if err != nil {
+			t.Fatalf("AddAnchor: %v", err)
+		}
+	}
+
+	// Encode the locator to wire format and decode it back.
+	var buf bytes.Buffer
+	err = msg.SotoEncode(&buf, ProtocolVersion, BaseEncoding)
+	if err != nil {
+		t.Fatalf("SotoEncode: %v", err)
+	}
+	if uint32(buf.Len()) > msg.MaxPayloadLength(ProtocolVersion) {
+		t.Fatalf("SotoEncode: payload of %v bytes exceeds max payload "+
+			"length %v", buf.Len(),
+			msg.MaxPayloadLength(ProtocolVersion))
+	}
+
+	var decoded MsgGetDagBlockLocator
+	err = decoded.SotoDecode(bytes.NewReader(buf.Bytes()), ProtocolVersion,
+		BaseEncoding)
+	if err != nil {
+		t.Fatalf("SotoDecode: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, msg) {
+		t.Fatalf("SotoDecode: decoded locator doesn't match\n got: %s "+
+			"want: %s", spew.Sdump(&decoded), spew.Sdump(msg))
+	}
+
+	// The locator should start at a tip and end at genesis, with anchors in
+	// strictly decreasing height.
+	if decoded.Anchors[0].Height != int32(depth-1) {
+		t.Errorf("first anchor at wrong height - got %v, want %v",
+			decoded.Anchors[0].Height, depth-1)
+	}
+	for i := 1; i < len(decoded.Anchors); i++ {
+		prev, anchor := decoded.Anchors[i-1], decoded.Anchors[i]
+		if anchor.Height >= prev.Height {
+			t.Errorf("anchor %d height %v isn't lower than previous "+
+				"anchor height %v", i, anchor.Height, prev.Height)
+		}
+	}
+	last := decoded.Anchors[len(decoded.Anchors)-1]
+	if last.Height != 0 || last.Hash != dag[0][0] {
+		t.Errorf("locator doesn't end at genesis - got %v",
+			spew.Sdump(last))
+	}
+}
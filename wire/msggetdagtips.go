@@ -0,0 +1,48 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"io"
+)
+
+// MsgGetDagTips implements the Message interface and represents a soter
+// getdagtips message.  It is used to request the current set of dag tips from
+// a peer, which is useful for a node that is reconnecting after being offline
+// and wants to learn the state of the dag without requesting a full inventory.
+// The tips are returned via a dagtips message (MsgDagTips).
+//
+// This message has no payload.
+type MsgGetDagTips struct{}
+
+// SotoDecode decodes r using the soter protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgGetDagTips) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	return nil
+}
+
+// SotoEncode encodes the receiver to w using the soter protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgGetDagTips) SotoEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgGetDagTips) Command() string {
+	return CmdGetDagTips
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgGetDagTips) MaxPayloadLength(pver uint32) uint32 {
+	return 0
+}
+
+// NewMsgGetDagTips returns a new soter getdagtips message that conforms to the
+// Message interface.  See MsgGetDagTips for details.
+func NewMsgGetDagTips() *MsgGetDagTips {
+	return &MsgGetDagTips{}
+}
@@ -343,3 +343,51 @@ func TestGetDataWireErrors(t *testing.T) {
 		}
 	}
 }
+
+// TestGetDataDagBlock tests that a getdata with dag block inventory vectors
+// round-trips at the latest protocol version, and that the vectors are
+// encoded as the legacy block types for older protocol versions.
+func TestGetDataDagBlock(t *testing.T) {
+	hash := chainhash.Hash{0x01}
+	msg := NewMsgGetData()
+	msg.AddInvVect(NewInvVect(InvTypeDagBlock, &hash, 1))
+	msg.AddInvVect(NewInvVect(InvTypeFilteredDagBlock, &hash, 1))
+
+	tests := []struct {
+		pver  uint32
+		types []InvType
+	}{
+		{ProtocolVersion, []InvType{InvTypeDagBlock, InvTypeFilteredDagBlock}},
+		{DagBlockInvVersion - 1, []InvType{InvTypeBlock, InvTypeFilteredBlock}},
+	}
+
+	for i, test := range tests {
+		var buf bytes.Buffer
+		err := msg.SotoEncode(&buf, test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("SotoEncode #%d error %v", i, err)
+			continue
+		}
+
+		var decoded MsgGetData
+		err = decoded.SotoDecode(&buf, test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("SotoDecode #%d error %v", i, err)
+			continue
+		}
+
+		if len(decoded.InvList) != len(test.types) {
+			t.Errorf("SotoDecode #%d wrong number of inventory "+
+				"vectors - got %d, want %d", i,
+				len(decoded.InvList), len(test.types))
+			continue
+		}
+		for j, iv := range decoded.InvList {
+			if iv.Type != test.types[j] {
+				t.Errorf("SotoDecode #%d wrong type for inventory "+
+					"vector %d - got %v, want %v", i, j, iv.Type,
+					test.types[j])
+			}
+		}
+	}
+}
@@ -109,7 +109,7 @@ func (msg *MsgInv) Command() string {
 // receiver.  This is part of the Message interface implementation.
 func (msg *MsgInv) MaxPayloadLength(pver uint32) uint32 {
 	// Num inventory vectors (varInt) + max allowed inventory vectors.
-	return MaxVarIntPayload + (MaxInvPerMsg * maxInvVectPayload)
+	return maxCountedPayload(MaxInvPerMsg, maxInvVectPayload)
 }
 
 // NewMsgInv returns a new soter inv message that conforms to the Message
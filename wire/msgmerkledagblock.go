@@ -0,0 +1,183 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+)
+
+// MsgMerkleDagBlock implements the Message interface and represents a soter
+// merkledagblk message.  It is the dag equivalent of the merkleblock message
+// (MsgMerkleBlock), and is used to deliver a block to light clients with only
+// the transactions they are interested in.  In addition to the block header,
+// the message carries the parent sub-header of the block so that the client
+// can place the block in the dag.
+//
+// The Hashes and Flags fields describe a partial merkle tree, which can be
+// used to prove that the matched transactions are part of the block.
+//
+// This message was not added until protocol version BIP0037Version.
+type MsgMerkleDagBlock struct {
+	Header       BlockHeader
+	Parents      ParentSubHeader
+	Transactions uint32
+	Hashes       []*chainhash.Hash
+	Flags        []byte
+}
+
+// AddTxHash adds a new transaction hash to the message.
+func (msg *MsgMerkleDagBlock) AddTxHash(hash *chainhash.Hash) error {
+	if len(msg.Hashes)+1 > maxTxPerBlock {
+		str := fmt.Sprintf("too many tx hashes for message [max %v]",
+			maxTxPerBlock)
+		return messageError("MsgMerkleDagBlock.AddTxHash", str)
+	}
+
+	msg.Hashes = append(msg.Hashes, hash)
+	return nil
+}
+
+// SotoDecode decodes r using the soter protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgMerkleDagBlock) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < BIP0037Version {
+		str := fmt.Sprintf("merkledagblk message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgMerkleDagBlock.SotoDecode", str)
+	}
+
+	err := readBlockHeader(r, pver, &msg.Header)
+	if err != nil {
+		return err
+	}
+
+	err = readParentSubHeader(r, pver, &msg.Parents)
+	if err != nil {
+		return err
+	}
+
+	err = readElement(r, &msg.Transactions)
+	if err != nil {
+		return err
+	}
+
+	// Read num transaction hashes and limit to max.
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count > maxTxPerBlock {
+		str := fmt.Sprintf("too many transaction hashes for message "+
+			"[count %v, max %v]", count, maxTxPerBlock)
+		return messageError("MsgMerkleDagBlock.SotoDecode", str)
+	}
+
+	// Create a contiguous slice of hashes to deserialize into in order to
+	// reduce the number of allocations.
+	hashes := make([]chainhash.Hash, count)
+	msg.Hashes = make([]*chainhash.Hash, 0, count)
+	for i := uint64(0); i < count; i++ {
+		hash := &hashes[i]
+		err := readElement(r, hash)
+		if err != nil {
+			return err
+		}
+		msg.AddTxHash(hash)
+	}
+
+	msg.Flags, err = ReadVarBytes(r, pver, maxFlagsPerMerkleBlock,
+		"merkle dag block flags size")
+	return err
+}
+
+// SotoEncode encodes the receiver to w using the soter protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgMerkleDagBlock) SotoEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < BIP0037Version {
+		str := fmt.Sprintf("merkledagblk message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgMerkleDagBlock.SotoEncode", str)
+	}
+
+	// Limit the number of transaction hashes and flag bytes to max.
+	numHashes := len(msg.Hashes)
+	if numHashes > maxTxPerBlock {
+		str := fmt.Sprintf("too many transaction hashes for message "+
+			"[count %v, max %v]", numHashes, maxTxPerBlock)
+		return messageError("MsgMerkleDagBlock.SotoEncode", str)
+	}
+	numFlagBytes := len(msg.Flags)
+	if numFlagBytes > maxFlagsPerMerkleBlock {
+		str := fmt.Sprintf("too many flag bytes for message [count %v, "+
+			"max %v]", numFlagBytes, maxFlagsPerMerkleBlock)
+		return messageError("MsgMerkleDagBlock.SotoEncode", str)
+	}
+
+	err := writeBlockHeader(w, pver, &msg.Header)
+	if err != nil {
+		return err
+	}
+
+	err = writeParentSubHeader(w, pver, &msg.Parents)
+	if err != nil {
+		return err
+	}
+
+	err = writeElement(w, msg.Transactions)
+	if err != nil {
+		return err
+	}
+
+	err = WriteVarInt(w, pver, uint64(numHashes))
+	if err != nil {
+		return err
+	}
+	for _, hash := range msg.Hashes {
+		err = writeElement(w, hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return WriteVarBytes(w, pver, msg.Flags)
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgMerkleDagBlock) Command() string {
+	return CmdMerkleDagBlock
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgMerkleDagBlock) MaxPayloadLength(pver uint32) uint32 {
+	// A merkle dag block can't be larger than the block it describes, plus
+	// the max size of the parent sub-header carried alongside it.
+	return MaxBlockPayload + MaxParentSubHeaderPayload
+}
+
+// NewMsgMerkleDagBlock returns a new soter merkledagblk message that conforms
+// to the Message interface.  See MsgMerkleDagBlock for details.
+func NewMsgMerkleDagBlock(bh *BlockHeader, parents *ParentSubHeader) *MsgMerkleDagBlock {
+	msg := &MsgMerkleDagBlock{
+		Header:       *bh,
+		Transactions: 0,
+		Hashes:       make([]*chainhash.Hash, 0),
+		Flags:        make([]byte, 0),
+	}
+
+	msg.Parents.Version = parents.Version
+	msg.Parents.Size = int32(len(parents.Parents))
+	msg.Parents.Parents = make([]*Parent, 0, len(parents.Parents))
+	for _, parent := range parents.Parents {
+		p := *parent
+		msg.Parents.Parents = append(msg.Parents.Parents, &p)
+	}
+
+	return msg
+}
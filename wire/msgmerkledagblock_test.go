@@ -0,0 +1,408 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+)
+
+// testPartialMerkleTree is used to build and traverse partial merkle trees in
+// the merkle dag block tests, the same way a full node and light client would.
+type testPartialMerkleTree struct {
+	numTx       uint32
+	allHashes   []*chainhash.Hash
+	matched     []bool
+	finalHashes []*chainhash.Hash
+	bits        []byte
+
+	// Traversal state used when extracting matches.
+	hashesUsed int
+	bitsUsed   int
+	matches    []*chainhash.Hash
+}
+
+// hashTestMerkleBranches returns the hash of the concatenation of the two
+// passed merkle tree nodes.
+func hashTestMerkleBranches(left, right *chainhash.Hash) *chainhash.Hash {
+	var hash [chainhash.HashSize * 2]byte
+	copy(hash[:chainhash.HashSize], left[:])
+	copy(hash[chainhash.HashSize:], right[:])
+
+	newHash := chainhash.DoubleHashH(hash[:])
+	return &newHash
+}
+
+// treeWidth returns the number of nodes of the tree at the given height.
+func (m *testPartialMerkleTree) treeWidth(height uint32) uint32 {
+	return (m.numTx + (1 << height) - 1) >> height
+}
+
+// treeHeight returns the height of the tree.
+func (m *testPartialMerkleTree) treeHeight() uint32 {
+	height := uint32(0)
+	for m.treeWidth(height) > 1 {
+		height++
+	}
+	return height
+}
+
+// calcHash returns the hash of the sub-tree at the given height and position,
+// using all the transaction hashes.
+func (m *testPartialMerkleTree) calcHash(height, pos uint32) *chainhash.Hash {
+	if height == 0 {
+		return m.allHashes[pos]
+	}
+
+	left := m.calcHash(height-1, pos*2)
+	right := left
+	if pos*2+1 < m.treeWidth(height-1) {
+		right = m.calcHash(height-1, pos*2+1)
+	}
+	return hashTestMerkleBranches(left, right)
+}
+
+// build populates the final hashes and flag bits of the partial tree using a
+// depth-first traversal.
+func (m *testPartialMerkleTree) build(height, pos uint32) {
+	var isParent byte
+	for i := pos << height; i < (pos+1)<<height && i < m.numTx; i++ {
+		if m.matched[i] {
+			isParent = 1
+		}
+	}
+	m.bits = append(m.bits, isParent)
+
+	if height == 0 || isParent == 0 {
+		m.finalHashes = append(m.finalHashes, m.calcHash(height, pos))
+		return
+	}
+
+	m.build(height-1, pos*2)
+	if pos*2+1 < m.treeWidth(height-1) {
+		m.build(height-1, pos*2+1)
+	}
+}
+
+// extract walks the partial tree described by the final hashes and flag bits
+// and returns the root of the sub-tree at the given height and position.
+func (m *testPartialMerkleTree) extract(height, pos uint32) (*chainhash.Hash, error) {
+	if m.bitsUsed >= len(m.bits) {
+		return nil, errors.New("partial merkle tree overflowed flag bits")
+	}
+	isParent := m.bits[m.bitsUsed]
+	m.bitsUsed++
+
+	if height == 0 || isParent == 0 {
+		if m.hashesUsed >= len(m.finalHashes) {
+			return nil, errors.New("partial merkle tree overflowed " +
+				"hashes")
+		}
+		hash := m.finalHashes[m.hashesUsed]
+		m.hashesUsed++
+		if height == 0 && isParent == 1 {
+			m.matches = append(m.matches, hash)
+		}
+		return hash, nil
+	}
+
+	left, err := m.extract(height-1, pos*2)
+	if err != nil {
+		return nil, err
+	}
+	right := left
+	if pos*2+1 < m.treeWidth(height-1) {
+		right, err = m.extract(height-1, pos*2+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hashTestMerkleBranches(left, right), nil
+}
+
+// newTestMerkleDagBlock returns a merkle dag block for the passed block, which
+// includes the transactions at the matched indices.
+func newTestMerkleDagBlock(block *MsgBlock, matched []bool) *MsgMerkleDagBlock {
+	m := testPartialMerkleTree{
+		numTx:   uint32(len(block.Transactions)),
+		matched: matched,
+	}
+	for _, tx := range block.Transactions {
+		hash := tx.TxHash()
+		m.allHashes = append(m.allHashes, &hash)
+	}
+	m.build(m.treeHeight(), 0)
+
+	msg := NewMsgMerkleDagBlock(&block.Header, &block.Parents)
+	msg.Transactions = m.numTx
+	for _, hash := range m.finalHashes {
+		msg.AddTxHash(hash)
+	}
+	msg.Flags = make([]byte, (len(m.bits)+7)/8)
+	for i := uint32(0); i < uint32(len(m.bits)); i++ {
+		msg.Flags[i/8] |= m.bits[i] << (i % 8)
+	}
+	return msg
+}
+
+// extractTestMerkleDagBlock reconstructs the partial merkle tree of the passed
+// merkle dag block, returning the merkle root and the matched transaction
+// hashes.
+func extractTestMerkleDagBlock(msg *MsgMerkleDagBlock) (*chainhash.Hash, []*chainhash.Hash, error) {
+	m := testPartialMerkleTree{
+		numTx:       msg.Transactions,
+		finalHashes: msg.Hashes,
+	}
+	for i := 0; i < len(msg.Flags)*8; i++ {
+		m.bits = append(m.bits, (msg.Flags[i/8]>>(uint(i)%8))&0x01)
+	}
+
+	root, err := m.extract(m.treeHeight(), 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if m.hashesUsed != len(m.finalHashes) {
+		return nil, nil, errors.New("partial merkle tree has unused " +
+			"hashes")
+	}
+	return root, m.matches, nil
+}
+
+// newTestMerkleDagBlockSource returns a block with the given number of unique
+// transactions and two parents, along with its merkle root set in the header.
+func newTestMerkleDagBlockSource(numTx int) *MsgBlock {
+	block := NewMsgBlock(&blockOne.Header)
+	block.Parents = ParentSubHeader{
+		Version: 1,
+		Size:    2,
+		Parents: []*Parent{
+			{Hash: blockOne.Header.PrevBlock},
+			{Hash: blockOne.Header.MerkleRoot},
+		},
+	}
+	var hashes []*chainhash.Hash
+	for i := 0; i < numTx; i++ {
+		tx := blockOne.Transactions[0].Copy()
+		tx.LockTime = uint32(i)
+		block.AddTransaction(tx)
+		hash := tx.TxHash()
+		hashes = append(hashes, &hash)
+	}
+
+	// Compute the merkle root of the full block.
+	for len(hashes) > 1 {
+		if len(hashes)%2 != 0 {
+			hashes = append(hashes, hashes[len(hashes)-1])
+		}
+		var next []*chainhash.Hash
+		for i := 0; i < len(hashes); i += 2 {
+			next = append(next, hashTestMerkleBranches(hashes[i],
+				hashes[i+1]))
+		}
+		hashes = next
+	}
+	block.Header.MerkleRoot = *hashes[0]
+
+	return block
+}
+
+// TestMerkleDagBlock tests the MsgMerkleDagBlock API.
+func TestMerkleDagBlock(t *testing.T) {
+	pver := ProtocolVersion
+	enc := BaseEncoding
+
+	// Ensure the command is expected value.
+	wantCmd := "merkledagblk"
+	msg := NewMsgMerkleDagBlock(&blockOne.Header, &blockOne.Parents)
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgMerkleDagBlock: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value for latest protocol version.
+	// Max block payload + max parent sub-header payload.
+	wantPayload := uint32(4000520)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Ensure encode and decode fail with an old protocol version.
+	var buf bytes.Buffer
+	err := msg.SotoEncode(&buf, BIP0037Version-1, enc)
+	if err == nil {
+		t.Errorf("encode of MsgMerkleDagBlock succeeded when it " +
+			"should have failed")
+	}
+	var readmsg MsgMerkleDagBlock
+	err = readmsg.SotoDecode(bytes.NewReader([]byte{}),
+		BIP0037Version-1, enc)
+	if err == nil {
+		t.Errorf("decode of MsgMerkleDagBlock succeeded when it " +
+			"should have failed")
+	}
+
+	// Ensure encode fails with too many parents.
+	for i := 0; i <= maxParents; i++ {
+		msg.Parents.Parents = append(msg.Parents.Parents, &Parent{})
+	}
+	err = msg.SotoEncode(&buf, pver, enc)
+	if _, ok := err.(*MessageError); !ok {
+		t.Errorf("encode of MsgMerkleDagBlock with too many parents "+
+			"wrong error got: %v, want: %T", err, &MessageError{})
+	}
+}
+
+// TestMerkleDagBlockWire tests the MsgMerkleDagBlock wire encode and decode
+// for various protocol versions, and that the partial merkle tree carried by
+// the message reconstructs to the merkle root of the full block.
+func TestMerkleDagBlockWire(t *testing.T) {
+	tests := []struct {
+		numTx   int          // Number of transactions in the block
+		matched map[int]bool // Indices of the matched transactions
+		pver    uint32       // Protocol version for wire encoding
+	}{
+		// Single transaction which matches.
+		{1, map[int]bool{0: true}, ProtocolVersion},
+		// No matched transactions.
+		{5, map[int]bool{}, ProtocolVersion},
+		// All transactions matched.
+		{4, map[int]bool{0: true, 1: true, 2: true, 3: true}, ProtocolVersion},
+		// Odd number of transactions with some matched.
+		{7, map[int]bool{1: true, 6: true}, ProtocolVersion},
+		// Larger block with a single match.
+		{33, map[int]bool{17: true}, FeeFilterVersion},
+		// Protocol version BIP0037Version.
+		{12, map[int]bool{0: true, 11: true}, BIP0037Version},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		block := newTestMerkleDagBlockSource(test.numTx)
+		matched := make([]bool, test.numTx)
+		var wantMatches []*chainhash.Hash
+		for j := 0; j < test.numTx; j++ {
+			if test.matched[j] {
+				matched[j] = true
+				hash := block.Transactions[j].TxHash()
+				wantMatches = append(wantMatches, &hash)
+			}
+		}
+		msg := newTestMerkleDagBlock(block, matched)
+
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := msg.SotoEncode(&buf, test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("SotoEncode #%d error %v", i, err)
+			continue
+		}
+
+		// Decode the message from wire format.
+		var readmsg MsgMerkleDagBlock
+		err = readmsg.SotoDecode(bytes.NewReader(buf.Bytes()),
+			test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("SotoDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&readmsg, msg) {
+			t.Errorf("SotoDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(&readmsg), spew.Sdump(msg))
+			continue
+		}
+
+		// Ensure the dag parents survived the round trip.
+		if !readmsg.Parents.IsParent(&block.Parents.Parents[1].Hash) {
+			t.Errorf("SotoDecode #%d parent %v missing", i,
+				block.Parents.Parents[1].Hash)
+			continue
+		}
+
+		// Ensure the partial merkle tree reconstructs to the merkle
+		// root of the full block, and yields the matched transactions.
+		root, matches, err := extractTestMerkleDagBlock(&readmsg)
+		if err != nil {
+			t.Errorf("extract #%d error %v", i, err)
+			continue
+		}
+		if !root.IsEqual(&block.Header.MerkleRoot) {
+			t.Errorf("extract #%d wrong merkle root got: %v, "+
+				"want: %v", i, root, block.Header.MerkleRoot)
+			continue
+		}
+		if !reflect.DeepEqual(matches, wantMatches) {
+			t.Errorf("extract #%d wrong matches\n got: %s want: %s",
+				i, spew.Sdump(matches), spew.Sdump(wantMatches))
+			continue
+		}
+	}
+}
+
+// TestMerkleDagBlockOverflowErrors performs tests to ensure decoding
+// MsgMerkleDagBlock messages with an excessive number of parents or hashes is
+// handled properly.
+func TestMerkleDagBlockOverflowErrors(t *testing.T) {
+	pver := ProtocolVersion
+	wireErr := &MessageError{}
+
+	var header bytes.Buffer
+	err := writeBlockHeader(&header, pver, &blockOne.Header)
+	if err != nil {
+		t.Fatalf("writeBlockHeader: %v", err)
+	}
+
+	// Message that claims more parents than a block may have.
+	tooManyParents := append([]byte{}, header.Bytes()...)
+	tooManyParents = append(tooManyParents,
+		0x01, 0x00, 0x00, 0x00, // Parents version
+		0x09, 0x00, 0x00, 0x00, // Parents count
+	)
+
+	// Message that claims a negative number of parents.
+	negativeParents := append([]byte{}, header.Bytes()...)
+	negativeParents = append(negativeParents,
+		0x01, 0x00, 0x00, 0x00, // Parents version
+		0xff, 0xff, 0xff, 0xff, // Parents count
+	)
+
+	// Message that claims more hashes than a block may have.
+	tooManyHashes := append([]byte{}, header.Bytes()...)
+	tooManyHashes = append(tooManyHashes,
+		0x01, 0x00, 0x00, 0x00, // Parents version
+		0x00, 0x00, 0x00, 0x00, // Parents count
+		0x01, 0x00, 0x00, 0x00, // Transactions count
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, // Hash count
+	)
+
+	tests := []struct {
+		buf  []byte // Wire encoding
+		pver uint32 // Protocol version for wire encoding
+		err  error  // Expected error
+	}{
+		{tooManyParents, pver, wireErr},
+		{negativeParents, pver, wireErr},
+		{tooManyHashes, pver, wireErr},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		var msg MsgMerkleDagBlock
+		r := bytes.NewReader(test.buf)
+		err := msg.SotoDecode(r, test.pver, BaseEncoding)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.err) {
+			t.Errorf("SotoDecode #%d wrong error got: %v, want: %v",
+				i, err, reflect.TypeOf(test.err))
+			continue
+		}
+	}
+}
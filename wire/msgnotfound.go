@@ -99,7 +99,7 @@ func (msg *MsgNotFound) Command() string {
 func (msg *MsgNotFound) MaxPayloadLength(pver uint32) uint32 {
 	// Max var int 9 bytes + max InvVects at 36 bytes each.
 	// Num inventory vectors (varInt) + max allowed inventory vectors.
-	return MaxVarIntPayload + (MaxInvPerMsg * maxInvVectPayload)
+	return maxCountedPayload(MaxInvPerMsg, maxInvVectPayload)
 }
 
 // NewMsgNotFound returns a new soter notfound message that conforms to the
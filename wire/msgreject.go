@@ -8,10 +8,16 @@ package wire
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
+	"unicode/utf8"
 
 	"github.com/soteria-dag/soterd/chaincfg/chainhash"
 )
 
+// MaxRejectReasonLength is the maximum number of bytes allowed in the reason
+// of a reject message.
+const MaxRejectReasonLength = 256
+
 // RejectCode represents a numeric value by which a remote peer indicates
 // why a message was rejected.
 type RejectCode uint8
@@ -26,6 +32,7 @@ const (
 	RejectDust            RejectCode = 0x41
 	RejectInsufficientFee RejectCode = 0x42
 	RejectCheckpoint      RejectCode = 0x43
+	RejectOrphan          RejectCode = 0x44
 )
 
 // Map of reject codes back strings for pretty printing.
@@ -38,6 +45,7 @@ var rejectCodeStrings = map[RejectCode]string{
 	RejectDust:            "REJECT_DUST",
 	RejectInsufficientFee: "REJECT_INSUFFICIENTFEE",
 	RejectCheckpoint:      "REJECT_CHECKPOINT",
+	RejectOrphan:          "REJECT_ORPHAN",
 }
 
 // String returns the RejectCode in human-readable form.
@@ -64,7 +72,8 @@ type MsgReject struct {
 	Code RejectCode
 
 	// Reason is a human-readable string with specific details (over and
-	// above the reject code) about why the command was rejected.
+	// above the reject code) about why the command was rejected.  It may
+	// be at most MaxRejectReasonLength bytes long.
 	Reason string
 
 	// Hash identifies a specific block or transaction that was rejected
@@ -82,11 +91,11 @@ func (msg *MsgReject) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding)
 	}
 
 	// Command that was rejected.
-	cmd, err := ReadVarString(r, pver)
+	cmd, err := ReadVarBytes(r, pver, CommandSize, "reject command")
 	if err != nil {
 		return err
 	}
-	msg.Cmd = cmd
+	msg.Cmd = string(cmd)
 
 	// Code indicating why the command was rejected.
 	err = readElement(r, &msg.Code)
@@ -95,12 +104,42 @@ func (msg *MsgReject) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding)
 	}
 
 	// Human readable string with specific details (over and above the
-	// reject code above) about why the command was rejected.
-	reason, err := ReadVarString(r, pver)
+	// reject code above) about why the command was rejected.  Reasons
+	// longer than MaxRejectReasonLength are truncated rather than treated
+	// as an error, since the reason is only informational.
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count > MaxMessagePayload {
+		str := fmt.Sprintf("reject reason is larger than the max "+
+			"allowed size [count %d, max %d]", count, MaxMessagePayload)
+		return messageError("MsgReject.SotoDecode", str)
+	}
+	err = checkElementCount(r, count, 1, "MsgReject.SotoDecode",
+		"reject reason bytes")
 	if err != nil {
 		return err
 	}
-	msg.Reason = reason
+
+	// Read one byte past the max, so that truncateRejectReason can tell
+	// whether the cut falls inside a character, then discard the rest.
+	keep := count
+	if keep > MaxRejectReasonLength+1 {
+		keep = MaxRejectReasonLength + 1
+	}
+	reason := make([]byte, keep)
+	_, err = io.ReadFull(r, reason)
+	if err != nil {
+		return err
+	}
+	if count > keep {
+		_, err = io.CopyN(ioutil.Discard, r, int64(count-keep))
+		if err != nil {
+			return err
+		}
+	}
+	msg.Reason = truncateRejectReason(string(reason))
 
 	// CmdBlock and CmdTx messages have an additional hash field that
 	// identifies the specific block or transaction.
@@ -123,6 +162,18 @@ func (msg *MsgReject) SotoEncode(w io.Writer, pver uint32, enc MessageEncoding)
 		return messageError("MsgReject.SotoEncode", str)
 	}
 
+	// Limit the command and reason to their max lengths.
+	if len(msg.Cmd) > CommandSize {
+		str := fmt.Sprintf("reject command is too long [len %v, max %v]",
+			len(msg.Cmd), CommandSize)
+		return messageError("MsgReject.SotoEncode", str)
+	}
+	if len(msg.Reason) > MaxRejectReasonLength {
+		str := fmt.Sprintf("reject reason is too long [len %v, max %v]",
+			len(msg.Reason), MaxRejectReasonLength)
+		return messageError("MsgReject.SotoEncode", str)
+	}
+
 	// Command that was rejected.
 	err := WriteVarString(w, pver, msg.Cmd)
 	if err != nil {
@@ -167,21 +218,39 @@ func (msg *MsgReject) MaxPayloadLength(pver uint32) uint32 {
 	// The reject message did not exist before protocol version
 	// RejectVersion.
 	if pver >= RejectVersion {
-		// Unfortunately the soter protocol does not enforce a sane
-		// limit on the length of the reason, so the max payload is the
-		// overall maximum message payload.
-		plen = MaxMessagePayload
+		// Command (varString) + code 1 byte + reason (varString) +
+		// hash.
+		plen = MaxVarIntPayload + CommandSize + 1 + MaxVarIntPayload +
+			MaxRejectReasonLength + chainhash.HashSize
 	}
 
 	return plen
 }
 
+// truncateRejectReason returns reason cut down to at most
+// MaxRejectReasonLength bytes.  The cut is made on a character boundary, so a
+// multi-byte UTF-8 encoded character is dropped rather than split.
+func truncateRejectReason(reason string) string {
+	if len(reason) <= MaxRejectReasonLength {
+		return reason
+	}
+
+	cut := MaxRejectReasonLength
+	for cut > MaxRejectReasonLength-utf8.UTFMax+1 &&
+		!utf8.RuneStart(reason[cut]) {
+		cut--
+	}
+
+	return reason[:cut]
+}
+
 // NewMsgReject returns a new soter reject message that conforms to the
-// Message interface.  See MsgReject for details.
+// Message interface.  Reasons longer than MaxRejectReasonLength are truncated.
+// See MsgReject for details.
 func NewMsgReject(command string, code RejectCode, reason string) *MsgReject {
 	return &MsgReject{
 		Cmd:    command,
 		Code:   code,
-		Reason: reason,
+		Reason: truncateRejectReason(reason),
 	}
 }
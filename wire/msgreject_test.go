@@ -9,7 +9,9 @@ import (
 	"bytes"
 	"io"
 	"reflect"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/davecgh/go-spew/spew"
 )
@@ -28,6 +30,7 @@ func TestRejectCodeStringer(t *testing.T) {
 		{RejectDust, "REJECT_DUST"},
 		{RejectInsufficientFee, "REJECT_INSUFFICIENTFEE"},
 		{RejectCheckpoint, "REJECT_CHECKPOINT"},
+		{RejectOrphan, "REJECT_ORPHAN"},
 		{0xff, "Unknown RejectCode (255)"},
 	}
 
@@ -78,7 +81,8 @@ func TestRejectLatest(t *testing.T) {
 	}
 
 	// Ensure max payload is expected value for latest protocol version.
-	wantPayload := uint32(MaxMessagePayload)
+	// Command (varString) + code 1 byte + reason (varString) + hash.
+	wantPayload := uint32(319)
 	maxPayload := msg.MaxPayloadLength(pver)
 	if maxPayload != wantPayload {
 		t.Errorf("MaxPayloadLength: wrong max payload length for "+
@@ -324,6 +328,26 @@ func TestRejectWireErrors(t *testing.T) {
 		0x68, 0xd6, 0x19, 0x00, 0x00, 0x00, 0x00, 0x00, // mainNetGenesisHash
 	}
 
+	// Message that forces an error by having a command longer than the max
+	// allowed.
+	longCmd := "commandtoolong"
+	longCmdReject := NewMsgReject(longCmd, RejectInvalid, "")
+	longCmdRejectEncoded := append([]byte{byte(len(longCmd))},
+		[]byte(longCmd)...)
+
+	// Message that forces an encode error by having a reason longer than
+	// the max allowed.  Decoding truncates the reason instead.
+	longReason := strings.Repeat("a", MaxRejectReasonLength+1)
+	longReasonReject := NewMsgReject("inv", RejectInvalid, "")
+	longReasonReject.Reason = longReason
+	longReasonRejectEncoded := []byte{
+		0x03, 0x69, 0x6e, 0x76, // "inv"
+		0x10,             // RejectInvalid
+		0xfd, 0x01, 0x01, // Varint for reason length (257)
+	}
+	longReasonRejectEncoded = append(longReasonRejectEncoded,
+		[]byte(longReason)...)
+
 	tests := []struct {
 		in       *MsgReject      // Value to encode
 		buf      []byte          // Wire encoding
@@ -344,6 +368,10 @@ func TestRejectWireErrors(t *testing.T) {
 		{baseReject, baseRejectEncoded, pver, BaseEncoding, 23, io.ErrShortWrite, io.EOF},
 		// Force error due to unsupported protocol version.
 		{baseReject, baseRejectEncoded, pverNoReject, BaseEncoding, 6, wireErr, wireErr},
+		// Force error with greater than max command length.
+		{longCmdReject, longCmdRejectEncoded, pver, BaseEncoding, len(longCmdRejectEncoded), wireErr, wireErr},
+		// Force encode error with greater than max reason length.
+		{longReasonReject, longReasonRejectEncoded, pver, BaseEncoding, len(longReasonRejectEncoded), wireErr, nil},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -388,3 +416,105 @@ func TestRejectWireErrors(t *testing.T) {
 		}
 	}
 }
+
+// TestRejectCodes tests that a reject message round trips for each reject
+// code, and that long reasons are truncated by NewMsgReject.
+func TestRejectCodes(t *testing.T) {
+	pver := ProtocolVersion
+	enc := BaseEncoding
+
+	for code := range rejectCodeStrings {
+		msg := NewMsgReject(CmdBlock, code, code.String())
+		msg.Hash = mainNetGenesisHash
+
+		var buf bytes.Buffer
+		if err := msg.SotoEncode(&buf, pver, enc); err != nil {
+			t.Errorf("encode of MsgReject with code %v failed: %v",
+				code, err)
+			continue
+		}
+
+		var readMsg MsgReject
+		if err := readMsg.SotoDecode(&buf, pver, enc); err != nil {
+			t.Errorf("decode of MsgReject with code %v failed: %v",
+				code, err)
+			continue
+		}
+		if !reflect.DeepEqual(&readMsg, msg) {
+			t.Errorf("MsgReject with code %v\n got: %s want: %s",
+				code, spew.Sdump(&readMsg), spew.Sdump(msg))
+		}
+	}
+
+	longReason := strings.Repeat("a", MaxRejectReasonLength+1)
+	msg := NewMsgReject(CmdTx, RejectInvalid, longReason)
+	if len(msg.Reason) != MaxRejectReasonLength {
+		t.Errorf("NewMsgReject: reason not truncated - got len %d, "+
+			"want %d", len(msg.Reason), MaxRejectReasonLength)
+	}
+	var buf bytes.Buffer
+	if err := msg.SotoEncode(&buf, pver, enc); err != nil {
+		t.Errorf("encode of MsgReject with truncated reason failed: %v",
+			err)
+	}
+}
+
+// TestRejectLongReason ensures that reasons longer than MaxRejectReasonLength
+// are truncated on a character boundary, both when decoding and by
+// NewMsgReject.
+func TestRejectLongReason(t *testing.T) {
+	pver := ProtocolVersion
+
+	tests := []struct {
+		name   string // Description of the reason
+		reason string // Reason sent by the peer
+		want   string // Expected reason after truncation
+	}{
+		{
+			name:   "ascii",
+			reason: strings.Repeat("a", MaxRejectReasonLength+100),
+			want:   strings.Repeat("a", MaxRejectReasonLength),
+		},
+		{
+			// Each "é" is two bytes, so the max falls in the
+			// middle of one and it's dropped.
+			name:   "multi-byte",
+			reason: "a" + strings.Repeat("é", MaxRejectReasonLength),
+			want:   "a" + strings.Repeat("é", MaxRejectReasonLength/2-1),
+		},
+	}
+
+	for _, test := range tests {
+		// Encode the over-long reason by hand, since SotoEncode
+		// refuses to.
+		var buf bytes.Buffer
+		WriteVarString(&buf, pver, "inv")
+		writeElement(&buf, RejectInvalid)
+		WriteVarString(&buf, pver, test.reason)
+
+		var msg MsgReject
+		err := msg.SotoDecode(&buf, pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("%s: SotoDecode error %v", test.name, err)
+			continue
+		}
+		if msg.Reason != test.want {
+			t.Errorf("%s: SotoDecode wrong reason - got %q, want %q",
+				test.name, msg.Reason, test.want)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%s: SotoDecode left %d bytes unread",
+				test.name, buf.Len())
+		}
+
+		msg = *NewMsgReject("inv", RejectInvalid, test.reason)
+		if msg.Reason != test.want {
+			t.Errorf("%s: NewMsgReject wrong reason - got %q, want %q",
+				test.name, msg.Reason, test.want)
+		}
+		if !utf8.ValidString(msg.Reason) {
+			t.Errorf("%s: NewMsgReject reason isn't valid UTF-8",
+				test.name)
+		}
+	}
+}
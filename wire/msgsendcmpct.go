@@ -0,0 +1,77 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+)
+
+// SendCmpctVersion is the version of compact dag block relay implemented by
+// the cmpctdagblk, getblocktxn and blocktxn messages of this package.
+const SendCmpctVersion uint64 = 1
+
+// MsgSendCmpct implements the Message interface and represents a soter
+// sendcmpct message.  It is used to negotiate compact dag block relay
+// (MsgCmpctDagBlock) with a peer.  Peers may send it multiple times, once for
+// each compact block version they support.
+//
+// When AnnounceUsingCmpct is set, the sender asks the peer to announce new
+// blocks by sending a cmpctdagblk message rather than an inv or daghdrs
+// message.  Otherwise compact blocks are only sent when the sender asks for
+// them.
+//
+// This message was not added until protocol versions starting with
+// CmpctDagBlockVersion.
+type MsgSendCmpct struct {
+	AnnounceUsingCmpct bool
+	Version            uint64
+}
+
+// SotoDecode decodes r using the soter protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgSendCmpct) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < CmpctDagBlockVersion {
+		str := fmt.Sprintf("sendcmpct message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgSendCmpct.SotoDecode", str)
+	}
+
+	return readElements(r, &msg.AnnounceUsingCmpct, &msg.Version)
+}
+
+// SotoEncode encodes the receiver to w using the soter protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgSendCmpct) SotoEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < CmpctDagBlockVersion {
+		str := fmt.Sprintf("sendcmpct message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgSendCmpct.SotoEncode", str)
+	}
+
+	return writeElements(w, msg.AnnounceUsingCmpct, msg.Version)
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgSendCmpct) Command() string {
+	return CmdSendCmpct
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgSendCmpct) MaxPayloadLength(pver uint32) uint32 {
+	// Announce flag 1 byte + version 8 bytes.
+	return 9
+}
+
+// NewMsgSendCmpct returns a new soter sendcmpct message that conforms to the
+// Message interface.  See MsgSendCmpct for details.
+func NewMsgSendCmpct(announce bool, version uint64) *MsgSendCmpct {
+	return &MsgSendCmpct{
+		AnnounceUsingCmpct: announce,
+		Version:            version,
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestSendCmpct tests the MsgSendCmpct API.
+func TestSendCmpct(t *testing.T) {
+	pver := ProtocolVersion
+
+	// Ensure the command is expected value.
+	wantCmd := "sendcmpct"
+	msg := NewMsgSendCmpct(true, SendCmpctVersion)
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgSendCmpct: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value for latest protocol version.
+	wantPayload := uint32(9)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+}
+
+// TestSendCmpctWire tests the MsgSendCmpct wire encode and decode for various
+// protocol versions.
+func TestSendCmpctWire(t *testing.T) {
+	announce := NewMsgSendCmpct(true, SendCmpctVersion)
+	announceEncoded := []byte{
+		0x01,                                           // Announce
+		0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Version
+	}
+
+	noAnnounce := NewMsgSendCmpct(false, 2)
+	noAnnounceEncoded := []byte{
+		0x00,                                           // Announce
+		0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Version
+	}
+
+	tests := []struct {
+		in   *MsgSendCmpct   // Message to encode
+		out  *MsgSendCmpct   // Expected decoded message
+		buf  []byte          // Wire encoding
+		pver uint32          // Protocol version for wire encoding
+		enc  MessageEncoding // Message encoding format
+	}{
+		// Latest protocol version announcing with compact blocks.
+		{announce, announce, announceEncoded, ProtocolVersion, BaseEncoding},
+
+		// Latest protocol version without announcing.
+		{noAnnounce, noAnnounce, noAnnounceEncoded, ProtocolVersion, BaseEncoding},
+
+		// Protocol version CmpctDagBlockVersion.
+		{announce, announce, announceEncoded, CmpctDagBlockVersion, BaseEncoding},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.SotoEncode(&buf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("SotoEncode #%d\n got: %s want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+
+		// Decode the message from wire format.
+		var msg MsgSendCmpct
+		rbuf := bytes.NewReader(test.buf)
+		err = msg.SotoDecode(rbuf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.out) {
+			t.Errorf("SotoDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.out))
+			continue
+		}
+	}
+}
+
+// TestSendCmpctWireErrors performs negative tests against wire encode and
+// decode of MsgSendCmpct to confirm error paths work correctly.
+func TestSendCmpctWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+	wireErr := &MessageError{}
+
+	msg := NewMsgSendCmpct(true, SendCmpctVersion)
+	msgEncoded := []byte{
+		0x01,                                           // Announce
+		0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Version
+	}
+
+	tests := []struct {
+		in       *MsgSendCmpct   // Value to encode
+		buf      []byte          // Wire encoding
+		pver     uint32          // Protocol version for wire encoding
+		enc      MessageEncoding // Message encoding format
+		max      int             // Max size of fixed buffer to induce errors
+		writeErr error           // Expected write error
+		readErr  error           // Expected read error
+	}{
+		// Force error in announce flag.
+		{msg, msgEncoded, pver, BaseEncoding, 0, io.ErrShortWrite, io.EOF},
+		// Force error in version.
+		{msg, msgEncoded, pver, BaseEncoding, 1, io.ErrShortWrite, io.EOF},
+		// Force error with a protocol version prior to
+		// CmpctDagBlockVersion.
+		{msg, msgEncoded, CmpctDagBlockVersion - 1, BaseEncoding, len(msgEncoded), wireErr, wireErr},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.SotoEncode(w, test.pver, test.enc)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.writeErr) {
+			t.Errorf("SotoEncode #%d wrong error got: %v, want: %v",
+				i, err, test.writeErr)
+			continue
+		}
+
+		// Decode from wire format.
+		var msg MsgSendCmpct
+		r := newFixedReader(test.max, test.buf)
+		err = msg.SotoDecode(r, test.pver, test.enc)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.readErr) {
+			t.Errorf("SotoDecode #%d wrong error got: %v, want: %v",
+				i, err, test.readErr)
+			continue
+		}
+	}
+}
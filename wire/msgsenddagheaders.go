@@ -0,0 +1,61 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+)
+
+// MsgSendDagHeaders implements the Message interface and represents a soter
+// senddaghdrs message.  It is the dag equivalent of the sendheaders message
+// (MsgSendHeaders), and is used to request the peer announce new blocks with
+// a daghdrs message (MsgDagHeaders) rather than inventory vectors.
+//
+// This message has no payload and was not added until protocol versions
+// starting with DagHeadersVersion.
+type MsgSendDagHeaders struct{}
+
+// SotoDecode decodes r using the soter protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgSendDagHeaders) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < DagHeadersVersion {
+		str := fmt.Sprintf("senddaghdrs message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgSendDagHeaders.SotoDecode", str)
+	}
+
+	return nil
+}
+
+// SotoEncode encodes the receiver to w using the soter protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgSendDagHeaders) SotoEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < DagHeadersVersion {
+		str := fmt.Sprintf("senddaghdrs message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgSendDagHeaders.SotoEncode", str)
+	}
+
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgSendDagHeaders) Command() string {
+	return CmdSendDagHeaders
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgSendDagHeaders) MaxPayloadLength(pver uint32) uint32 {
+	return 0
+}
+
+// NewMsgSendDagHeaders returns a new soter senddaghdrs message that conforms
+// to the Message interface.  See MsgSendDagHeaders for details.
+func NewMsgSendDagHeaders() *MsgSendDagHeaders {
+	return &MsgSendDagHeaders{}
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2018-2019 The Soteria Engineering developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestSendDagHeaders tests the MsgSendDagHeaders API against the latest
+// protocol version.
+func TestSendDagHeaders(t *testing.T) {
+	pver := ProtocolVersion
+	enc := BaseEncoding
+
+	// Ensure the command is expected value.
+	wantCmd := "senddaghdrs"
+	msg := NewMsgSendDagHeaders()
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgSendDagHeaders: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value.
+	wantPayload := uint32(0)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Test encode with latest protocol version.
+	var buf bytes.Buffer
+	err := msg.SotoEncode(&buf, pver, enc)
+	if err != nil {
+		t.Errorf("encode of MsgSendDagHeaders failed %v err <%v>", msg,
+			err)
+	}
+
+	// Older protocol versions should fail encode since message didn't
+	// exist yet.
+	oldPver := DagHeadersVersion - 1
+	err = msg.SotoEncode(&buf, oldPver, enc)
+	if err == nil {
+		s := "encode of MsgSendDagHeaders passed for old protocol " +
+			"version %v err <%v>"
+		t.Errorf(s, msg, err)
+	}
+
+	// Test decode with latest protocol version.
+	readmsg := NewMsgSendDagHeaders()
+	err = readmsg.SotoDecode(&buf, pver, enc)
+	if err != nil {
+		t.Errorf("decode of MsgSendDagHeaders failed [%v] err <%v>", buf,
+			err)
+	}
+
+	// Older protocol versions should fail decode since message didn't
+	// exist yet.
+	err = readmsg.SotoDecode(&buf, oldPver, enc)
+	if err == nil {
+		s := "decode of MsgSendDagHeaders passed for old protocol " +
+			"version %v err <%v>"
+		t.Errorf(s, msg, err)
+	}
+}
+
+// TestSendDagHeadersPreDagHeaders tests the MsgSendDagHeaders API against the
+// protocol prior to version DagHeadersVersion.
+func TestSendDagHeadersPreDagHeaders(t *testing.T) {
+	// Use the protocol version just prior to DagHeadersVersion.
+	pver := DagHeadersVersion - 1
+	enc := BaseEncoding
+
+	msg := NewMsgSendDagHeaders()
+
+	// Test encode with old protocol version.
+	var buf bytes.Buffer
+	err := msg.SotoEncode(&buf, pver, enc)
+	if err == nil {
+		t.Errorf("encode of MsgSendDagHeaders succeeded when it should " +
+			"have failed")
+	}
+
+	// Test decode with old protocol version.
+	readmsg := NewMsgSendDagHeaders()
+	err = readmsg.SotoDecode(&buf, pver, enc)
+	if err == nil {
+		t.Errorf("decode of MsgSendDagHeaders succeeded when it should " +
+			"have failed")
+	}
+}
+
+// TestSendDagHeadersCrossProtocol tests the MsgSendDagHeaders API when encoding
+// with the latest protocol version and decoding with DagHeadersVersion.
+func TestSendDagHeadersCrossProtocol(t *testing.T) {
+	enc := BaseEncoding
+	msg := NewMsgSendDagHeaders()
+
+	// Encode with latest protocol version.
+	var buf bytes.Buffer
+	err := msg.SotoEncode(&buf, ProtocolVersion, enc)
+	if err != nil {
+		t.Errorf("encode of MsgSendDagHeaders failed %v err <%v>", msg,
+			err)
+	}
+
+	// Decode with old protocol version.
+	readmsg := NewMsgSendDagHeaders()
+	err = readmsg.SotoDecode(&buf, DagHeadersVersion, enc)
+	if err != nil {
+		t.Errorf("decode of MsgSendDagHeaders failed [%v] err <%v>", buf,
+			err)
+	}
+}
+
+// TestSendDagHeadersWire tests the MsgSendDagHeaders wire encode and decode
+// for various protocol versions.
+func TestSendDagHeadersWire(t *testing.T) {
+	msgSendDagHeaders := NewMsgSendDagHeaders()
+	msgSendDagHeadersEncoded := []byte{}
+
+	tests := []struct {
+		in   *MsgSendDagHeaders // Message to encode
+		out  *MsgSendDagHeaders // Expected decoded message
+		buf  []byte             // Wire encoding
+		pver uint32             // Protocol version for wire encoding
+		enc  MessageEncoding    // Message encoding format
+	}{
+		// Latest protocol version.
+		{
+			msgSendDagHeaders,
+			msgSendDagHeaders,
+			msgSendDagHeadersEncoded,
+			ProtocolVersion,
+			BaseEncoding,
+		},
+
+		// Protocol version DagHeadersVersion+1
+		{
+			msgSendDagHeaders,
+			msgSendDagHeaders,
+			msgSendDagHeadersEncoded,
+			DagHeadersVersion + 1,
+			BaseEncoding,
+		},
+
+		// Protocol version DagHeadersVersion
+		{
+			msgSendDagHeaders,
+			msgSendDagHeaders,
+			msgSendDagHeadersEncoded,
+			DagHeadersVersion,
+			BaseEncoding,
+		},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.SotoEncode(&buf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("SotoEncode #%d\n got: %s want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+
+		// Decode the message from wire format.
+		var msg MsgSendDagHeaders
+		rbuf := bytes.NewReader(test.buf)
+		err = msg.SotoDecode(rbuf, test.pver, test.enc)
+		if err != nil {
+			t.Errorf("SotoDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.out) {
+			t.Errorf("SotoDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(msg), spew.Sdump(test.out))
+			continue
+		}
+	}
+}
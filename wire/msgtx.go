@@ -424,7 +424,7 @@ func (msg *MsgTx) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) erro
 	// A count of zero (meaning no TxIn's to the uninitiated) indicates
 	// this is a transaction with witness data.
 	var flag [1]byte
-	if count == 0 && enc == WitnessEncoding {
+	if count == 0 && enc&WitnessEncoding == WitnessEncoding {
 		// Next, we need to read the flag, which is a single byte.
 		if _, err = io.ReadFull(r, flag[:]); err != nil {
 			return err
@@ -454,6 +454,11 @@ func (msg *MsgTx) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) erro
 			maxTxInPerMessage)
 		return messageError("MsgTx.SotoDecode", str)
 	}
+	err = checkElementCount(r, count, minTxInPayload, "MsgTx.SotoDecode",
+		"input transactions")
+	if err != nil {
+		return err
+	}
 
 	// returnScriptBuffers is a closure that returns any script buffers that
 	// were borrowed from the pool when there are any deserialization
@@ -517,6 +522,12 @@ func (msg *MsgTx) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) erro
 			maxTxOutPerMessage)
 		return messageError("MsgTx.SotoDecode", str)
 	}
+	err = checkElementCount(r, count, MinTxOutPayload, "MsgTx.SotoDecode",
+		"output transactions")
+	if err != nil {
+		returnScriptBuffers()
+		return err
+	}
 
 	// Deserialize the outputs.
 	txOuts := make([]TxOut, count)
@@ -536,7 +547,7 @@ func (msg *MsgTx) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) erro
 
 	// If the transaction's flag byte isn't 0x00 at this point, then one or
 	// more of its inputs has accompanying witness data.
-	if flag[0] != 0 && enc == WitnessEncoding {
+	if flag[0] != 0 && enc&WitnessEncoding == WitnessEncoding {
 		for _, txin := range msg.TxIn {
 			// For each input, the witness is encoded as a stack
 			// with one or more items. Therefore, we first read a
@@ -557,6 +568,15 @@ func (msg *MsgTx) SotoDecode(r io.Reader, pver uint32, enc MessageEncoding) erro
 				return messageError("MsgTx.SotoDecode", str)
 			}
 
+			// Each witness item takes at least the byte of its
+			// length prefix.
+			err = checkElementCount(r, witCount, 1,
+				"MsgTx.SotoDecode", "witness items")
+			if err != nil {
+				returnScriptBuffers()
+				return err
+			}
+
 			// Then for witCount number of stack items, each item
 			// has a varint length prefix, followed by the witness
 			// item itself.
@@ -689,7 +709,7 @@ func (msg *MsgTx) SotoEncode(w io.Writer, pver uint32, enc MessageEncoding) erro
 	// field for the MsgTx aren't 0x00, then this indicates the transaction
 	// is to be encoded using the new witness inclusionary structure
 	// defined in BIP0144.
-	doWitness := enc == WitnessEncoding && msg.HasWitness()
+	doWitness := enc&WitnessEncoding == WitnessEncoding && msg.HasWitness()
 	if doWitness {
 		// After the txn's Version field, we include two additional
 		// bytes specific to the witness encoding. The first byte is an
@@ -941,6 +961,10 @@ func readScript(r io.Reader, pver uint32, maxAllowed uint32, fieldName string) (
 			"[count %d, max %d]", fieldName, count, maxAllowed)
 		return nil, messageError("readScript", str)
 	}
+	err = checkElementCount(r, count, 1, "readScript", fieldName+" bytes")
+	if err != nil {
+		return nil, err
+	}
 
 	b := scriptPool.Borrow(count)
 	_, err = io.ReadFull(r, b)
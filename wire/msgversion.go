@@ -77,6 +77,23 @@ func (msg *MsgVersion) AddService(service ServiceFlag) {
 	msg.Services |= service
 }
 
+// AdvertisedVersion returns the protocol version advertised by the peer that
+// generated the message.  A negative advertised version is invalid, so it is
+// reported as 0 rather than wrapping around to a very large version.
+func (msg *MsgVersion) AdvertisedVersion() uint32 {
+	if msg.ProtocolVersion < 0 {
+		return 0
+	}
+	return uint32(msg.ProtocolVersion)
+}
+
+// NegotiatedVersion returns the protocol version to use when communicating with
+// the peer that generated the message, given the local protocol version.  See
+// the package level NegotiatedVersion function for details.
+func (msg *MsgVersion) NegotiatedVersion(local uint32) uint32 {
+	return NegotiatedVersion(local, msg.AdvertisedVersion())
+}
+
 // SotoDecode decodes r using the soter protocol encoding into the receiver.
 // The version message is special in that the protocol version hasn't been
 // negotiated yet.  As a result, the pver field is ignored and any fields which
@@ -125,6 +125,28 @@ func TestVersion(t *testing.T) {
 	if !msg.HasService(SFNodeNetwork) {
 		t.Errorf("HasService: SFNodeNetwork service not set")
 	}
+
+	// Ensure the advertised and negotiated versions are reported properly.
+	if v := msg.AdvertisedVersion(); v != ProtocolVersion {
+		t.Errorf("AdvertisedVersion: wrong version - got %v, want %v",
+			v, ProtocolVersion)
+	}
+	if v := msg.NegotiatedVersion(SendHeadersVersion); v != SendHeadersVersion {
+		t.Errorf("NegotiatedVersion: wrong version - got %v, want %v",
+			v, SendHeadersVersion)
+	}
+	msg.ProtocolVersion = int32(ProtocolVersion + 1)
+	if v := msg.NegotiatedVersion(ProtocolVersion); v != ProtocolVersion {
+		t.Errorf("NegotiatedVersion: wrong version for newer remote - "+
+			"got %v, want %v", v, ProtocolVersion)
+	}
+
+	// Ensure a negative advertised version isn't treated as a huge one.
+	msg.ProtocolVersion = -1
+	if v := msg.AdvertisedVersion(); v != 0 {
+		t.Errorf("AdvertisedVersion: wrong version for negative "+
+			"version - got %v, want %v", v, 0)
+	}
 }
 
 // TestVersionWire tests the MsgVersion wire encode and decode for various
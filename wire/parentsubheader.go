@@ -5,8 +5,10 @@
 package wire
 
 import (
-	"github.com/soteria-dag/soterd/chaincfg/chainhash"
+	"fmt"
 	"io"
+
+	"github.com/soteria-dag/soterd/chaincfg/chainhash"
 )
 
 const (
@@ -94,10 +96,29 @@ func readParentSubHeader(r io.Reader, pver uint32, psh *ParentSubHeader) error {
 		return err
 	}
 
+	// Prevent a negative number of parents, more parents than a block may
+	// reference, or more parents than could fit into the rest of the
+	// payload.  Otherwise a lying count could be used to exhaust memory.
+	if psh.Size < 0 {
+		str := fmt.Sprintf("negative number of parents [count %d]",
+			psh.Size)
+		return messageError("readParentSubHeader", str)
+	}
+	if psh.Size > maxParents {
+		str := fmt.Sprintf("too many parents [count %d, max %d]",
+			psh.Size, maxParents)
+		return messageError("readParentSubHeader", str)
+	}
+	err = checkElementCount(r, uint64(psh.Size), ParentSize,
+		"readParentSubHeader", "parents")
+	if err != nil {
+		return err
+	}
+
 	// readElement and writeElement deals mostly with primitive types, so
 	// we'll build needed complex types for fields that use them, then populate them in psh.
 	// At time of writing this is just the Parents field.
-	p := []*Parent{}
+	p := make([]*Parent, 0, psh.Size)
 
 	// Attempt to read psh.Size Parent data-structures from r
 	for i := int32(1); i <= psh.Size; i++ {
@@ -116,6 +137,13 @@ func readParentSubHeader(r io.Reader, pver uint32, psh *ParentSubHeader) error {
 
 // writeParentSubHeader writes a block's parent sub-header to w
 func writeParentSubHeader(w io.Writer, pver uint32, psh *ParentSubHeader) error {
+	// Refuse to write more parents than readParentSubHeader would accept.
+	if len(psh.Parents) > maxParents {
+		str := fmt.Sprintf("too many parents [count %d, max %d]",
+			len(psh.Parents), maxParents)
+		return messageError("writeParentSubHeader", str)
+	}
+
 	// Write version info
 	err := writeElement(w, psh.Version)
 	if err != nil {
@@ -107,3 +107,35 @@ func TestParentsSubHeaderSerialize(t *testing.T) {
 		t.Errorf("Serialize error %v", err)
 	}
 }
+
+// TestParentsSubHeaderTooMany ensures that a parent sub-header with more than
+// maxParents parents is rejected in both directions.
+func TestParentsSubHeaderTooMany(t *testing.T) {
+	psh := ParentSubHeader{}
+	for i := 0; i <= maxParents; i++ {
+		psh.Parents = append(psh.Parents, &Parent{})
+	}
+	psh.Size = int32(len(psh.Parents))
+
+	var buf bytes.Buffer
+	err := psh.Serialize(&buf)
+	if _, ok := err.(*MessageError); !ok {
+		t.Errorf("Serialize with too many parents wrong error "+
+			"got: %v, want: %T", err, &MessageError{})
+	}
+
+	// Encode a count one above the max, followed by enough parents that
+	// only the count check can reject it.
+	encoded := []byte{
+		0x00, 0x00, 0x00, 0x00, // Version
+		maxParents + 1, 0x00, 0x00, 0x00, // Size
+	}
+	encoded = append(encoded, make([]byte, ParentSize*(maxParents+1))...)
+
+	var h ParentSubHeader
+	err = h.Deserialize(bytes.NewReader(encoded))
+	if _, ok := err.(*MessageError); !ok {
+		t.Errorf("Deserialize with too many parents wrong error "+
+			"got: %v, want: %T", err, &MessageError{})
+	}
+}
@@ -14,7 +14,7 @@ import (
 // XXX pedro: we will probably need to bump this.
 const (
 	// ProtocolVersion is the latest protocol version this package supports.
-	ProtocolVersion uint32 = 70013
+	ProtocolVersion uint32 = 70017
 
 	// MultipleAddressVersion is the protocol version which added multiple
 	// addresses per message (pver >= MultipleAddressVersion).
@@ -52,8 +52,43 @@ const (
 	// FeeFilterVersion is the protocol version which added a new
 	// feefilter message.
 	FeeFilterVersion uint32 = 70013
+
+	// CompressedBlockVersion is the protocol version which added the
+	// CompressedEncoding of block messages.
+	CompressedBlockVersion uint32 = 70014
+
+	// DagHeadersVersion is the protocol version which added the
+	// senddaghdrs and daghdrs messages.
+	DagHeadersVersion uint32 = 70015
+
+	// DagBlockInvVersion is the protocol version which added the
+	// InvTypeDagBlock and InvTypeFilteredDagBlock inventory vector types
+	// (pver >= DagBlockInvVersion).
+	DagBlockInvVersion uint32 = 70016
+
+	// CmpctDagBlockVersion is the protocol version which added the
+	// sendcmpct, cmpctdagblk, getblocktxn and blocktxn messages for compact
+	// dag block relay (pver >= CmpctDagBlockVersion).
+	CmpctDagBlockVersion uint32 = 70017
 )
 
+// NegotiatedVersion returns the protocol version that should be used when
+// communicating with a remote peer, given the local and remote advertised
+// protocol versions.  The result is the lower of the two, further capped at
+// ProtocolVersion so that a remote peer advertising a version newer than this
+// package understands never causes messages to be encoded with fields we don't
+// know about.
+func NegotiatedVersion(local, remote uint32) uint32 {
+	pver := local
+	if remote < pver {
+		pver = remote
+	}
+	if pver > ProtocolVersion {
+		pver = ProtocolVersion
+	}
+	return pver
+}
+
 // ServiceFlag identifies services supported by a soter peer.
 type ServiceFlag uint64
 
@@ -87,6 +122,10 @@ const (
 	// SFNode2X is a flag used to indicate a peer is running the Segwit2X
 	// software.
 	SFNode2X
+
+	// SFNodeDag is a flag used to indicate a peer supports the dag sync
+	// messages, such as getdagtips and getdagblkloc.
+	SFNodeDag
 )
 
 // Map of service flags back to their constant names for pretty printing.
@@ -99,6 +138,7 @@ var sfStrings = map[ServiceFlag]string{
 	SFNodeBit5:    "SFNodeBit5",
 	SFNodeCF:      "SFNodeCF",
 	SFNode2X:      "SFNode2X",
+	SFNodeDag:     "SFNodeDag",
 }
 
 // orderedSFStrings is an ordered list of service flags from highest to
@@ -112,6 +152,7 @@ var orderedSFStrings = []ServiceFlag{
 	SFNodeBit5,
 	SFNodeCF,
 	SFNode2X,
+	SFNodeDag,
 }
 
 // String returns the ServiceFlag in human-readable form.
@@ -5,7 +5,12 @@
 
 package wire
 
-import "testing"
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
 
 // TestServiceFlagStringer tests the stringized output for service flag types.
 func TestServiceFlagStringer(t *testing.T) {
@@ -22,7 +27,8 @@ func TestServiceFlagStringer(t *testing.T) {
 		{SFNodeBit5, "SFNodeBit5"},
 		{SFNodeCF, "SFNodeCF"},
 		{SFNode2X, "SFNode2X"},
-		{0xffffffff, "SFNodeNetwork|SFNodeGetUTXO|SFNodeBloom|SFNodeWitness|SFNodeXthin|SFNodeBit5|SFNodeCF|SFNode2X|0xffffff00"},
+		{SFNodeDag, "SFNodeDag"},
+		{0xffffffff, "SFNodeNetwork|SFNodeGetUTXO|SFNodeBloom|SFNodeWitness|SFNodeXthin|SFNodeBit5|SFNodeCF|SFNode2X|SFNodeDag|0xfffffe00"},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -36,6 +42,77 @@ func TestServiceFlagStringer(t *testing.T) {
 	}
 }
 
+// TestServiceFlagWire tests that the services of version and addr messages
+// round-trip, including SFNodeDag and bits which aren't known to this version,
+// the way SFNodeDag is unknown to older peers.
+func TestServiceFlagWire(t *testing.T) {
+	tests := []struct {
+		name     string
+		services ServiceFlag
+	}{
+		{"dag", SFNodeNetwork | SFNodeDag},
+		{"unknown bit", SFNodeNetwork | 1<<40},
+	}
+
+	for _, test := range tests {
+		na := NewNetAddressIPPort(net.ParseIP("127.0.0.1"), 8333,
+			test.services)
+		na.Timestamp = time.Unix(0x495fab29, 0)
+
+		// Version message.
+		version := NewMsgVersion(na, na, 123123, 0, &simNetGenHash)
+		version.Services = test.services
+		var buf bytes.Buffer
+		err := version.SotoEncode(&buf, ProtocolVersion, BaseEncoding)
+		if err != nil {
+			t.Errorf("%s: MsgVersion.SotoEncode: %v", test.name, err)
+			continue
+		}
+		var decodedVersion MsgVersion
+		err = decodedVersion.SotoDecode(&buf, ProtocolVersion, BaseEncoding)
+		if err != nil {
+			t.Errorf("%s: MsgVersion.SotoDecode: %v", test.name, err)
+			continue
+		}
+		if decodedVersion.Services != test.services {
+			t.Errorf("%s: version services got %v, want %v", test.name,
+				decodedVersion.Services, test.services)
+		}
+		if !decodedVersion.HasService(SFNodeNetwork) {
+			t.Errorf("%s: version lost SFNodeNetwork", test.name)
+		}
+
+		// Address message.
+		addr := NewMsgAddr()
+		addr.AddAddress(na)
+		buf.Reset()
+		err = addr.SotoEncode(&buf, ProtocolVersion, BaseEncoding)
+		if err != nil {
+			t.Errorf("%s: MsgAddr.SotoEncode: %v", test.name, err)
+			continue
+		}
+		var decodedAddr MsgAddr
+		err = decodedAddr.SotoDecode(&buf, ProtocolVersion, BaseEncoding)
+		if err != nil {
+			t.Errorf("%s: MsgAddr.SotoDecode: %v", test.name, err)
+			continue
+		}
+		if len(decodedAddr.AddrList) != 1 {
+			t.Errorf("%s: got %d addresses, want 1", test.name,
+				len(decodedAddr.AddrList))
+			continue
+		}
+		decodedNa := decodedAddr.AddrList[0]
+		if decodedNa.Services != test.services {
+			t.Errorf("%s: address services got %v, want %v",
+				test.name, decodedNa.Services, test.services)
+		}
+		if !decodedNa.HasService(SFNodeNetwork) {
+			t.Errorf("%s: address lost SFNodeNetwork", test.name)
+		}
+	}
+}
+
 // TestSoterNetStringer tests the stringized output for soter net types.
 func TestSoterNetStringer(t *testing.T) {
 	tests := []struct {
@@ -59,3 +136,43 @@ func TestSoterNetStringer(t *testing.T) {
 		}
 	}
 }
+
+// TestNegotiatedVersion tests that the negotiated protocol version is the
+// minimum of the local and remote versions, capped at ProtocolVersion.
+func TestNegotiatedVersion(t *testing.T) {
+	tests := []struct {
+		local  uint32
+		remote uint32
+		want   uint32
+	}{
+		// Both sides speak the latest version.
+		{ProtocolVersion, ProtocolVersion, ProtocolVersion},
+		// Remote is one version behind.
+		{ProtocolVersion, ProtocolVersion - 1, ProtocolVersion - 1},
+		// Local is one version behind.
+		{ProtocolVersion - 1, ProtocolVersion, ProtocolVersion - 1},
+		// Remote advertises a version newer than we understand.
+		{ProtocolVersion, ProtocolVersion + 1, ProtocolVersion},
+		{ProtocolVersion, 0xffffffff, ProtocolVersion},
+		// Both sides advertise a version newer than we understand.
+		{ProtocolVersion + 1, ProtocolVersion + 2, ProtocolVersion},
+		// Boundary versions.
+		{ProtocolVersion, SendHeadersVersion, SendHeadersVersion},
+		{ProtocolVersion, FeeFilterVersion - 1, FeeFilterVersion - 1},
+		{ProtocolVersion, MultipleAddressVersion, MultipleAddressVersion},
+		{MultipleAddressVersion - 1, ProtocolVersion, MultipleAddressVersion - 1},
+		{ProtocolVersion, 0, 0},
+		{0, 0, 0},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		result := NegotiatedVersion(test.local, test.remote)
+		if result != test.want {
+			t.Errorf("NegotiatedVersion #%d (local %d, remote %d)\n"+
+				" got: %d want: %d", i, test.local, test.remote,
+				result, test.want)
+			continue
+		}
+	}
+}